@@ -1,6 +1,10 @@
 package geko
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
 
 // Any is a wrapper for an any value. But when unmarshal, it uses our
 // [Object]/[ObjectItems] and [Array] when meet JSON object and array.
@@ -29,12 +33,19 @@ func (v Any) MarshalJSON() ([]byte, error) {
 	return json.Marshal(v.Value)
 }
 
+func (v Any) encodeJSON(e *encodeState) error {
+	return e.value(v.Value)
+}
+
 // UnmarshalJSON implements [json.Unmarshaler] interface.
 //
 // You shouldn't call this directly, use [json.Unmarshal]/[JSONUnmarshal]
 // instead.
 func (v *Any) UnmarshalJSON(data []byte) error {
-	value, err := newDecoder(data, v.Opts).decode()
+	d := acquireDecoder(data, v.Opts)
+	defer releaseDecoder(d)
+
+	value, err := d.decode()
 	if err == nil {
 		v.Value = value
 	}
@@ -44,7 +55,119 @@ func (v *Any) UnmarshalJSON(data []byte) error {
 // JSONUnmarshal is A convenience function for unmarshal JSON data into an
 // [Any] and get the inner any value, with provided option applied.
 func JSONUnmarshal(data []byte, option ...DecodeOption) (any, error) {
-	a := Any{Opts: CreateDecodeOptions(option...)}
-	err := json.Unmarshal(data, &a)
-	return a.Value, err
+	return unmarshalTopLevel(data, CreateDecodeOptions(option...))
+}
+
+// NewObjectFromJSON decodes data like [JSONUnmarshal], but the top-level value
+// must be a JSON object, which is returned as an [Object].
+//
+// [UseObject] is applied before option, so nested objects are [Object] too,
+// unless [UseObjectItems] is in option. In this case, the top-level
+// [ObjectItems] is converted by [Pairs.ToMap], with the strategy set by
+// [ObjectOnDuplicatedKey], nested values are kept as decoded.
+//
+// If the top-level value is not an object, including null, a
+// [*json.UnmarshalTypeError] is returned.
+func NewObjectFromJSON(data []byte, option ...DecodeOption) (Object, error) {
+	opts := CreateDecodeOptions(UseObject())
+	opts.Apply(option...)
+
+	value, err := unmarshalTopLevel(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := value.(type) {
+	case Object:
+		return x, nil
+	case ObjectItems:
+		return x.ToMap(opts.duplicatedKeyStrategy), nil
+	default:
+		return nil, &json.UnmarshalTypeError{
+			Value: "non-object value",
+			Type:  reflect.TypeOf(Object(nil)),
+		}
+	}
+}
+
+// NewArrayFromJSON decodes data like [JSONUnmarshal], but the top-level value
+// must be a JSON array, which is returned as an [Array].
+//
+// If the top-level value is not an array, including null, a
+// [*json.UnmarshalTypeError] is returned.
+func NewArrayFromJSON(data []byte, option ...DecodeOption) (Array, error) {
+	value, err := JSONUnmarshal(data, option...)
+	if err != nil {
+		return nil, err
+	}
+
+	array, isArray := value.(Array)
+	if !isArray {
+		return nil, &json.UnmarshalTypeError{
+			Value: "non-array value",
+			Type:  reflect.TypeOf(Array(nil)),
+		}
+	}
+
+	return array, nil
+}
+
+func unmarshalTopLevel(data []byte, opts DecodeOptions) (any, error) {
+	d := acquireDecoder(data, opts)
+	defer releaseDecoder(d)
+
+	return d.decode()
+}
+
+// JSONUnmarshalAll decodes all JSON values in data, which can be a stream of
+// JSON values, like newline delimited JSON or concatenated JSON. Values are
+// stored in a list, in the order they appear.
+//
+// Each value is decoded like [JSONUnmarshal], with provided option applied.
+func JSONUnmarshalAll(data []byte, option ...DecodeOption) (*List[any], error) {
+	d := acquireDecoder(data, CreateDecodeOptions(option...))
+	defer releaseDecoder(d)
+
+	l := NewList[any]()
+
+	for d.More() {
+		value, err := d.Decode()
+		if err != nil {
+			// More reports true, so the input must not end here.
+			return nil, d.unexpectedEOF(err)
+		}
+		l.Append(value)
+	}
+
+	// More returns false when meet a unexpected ] or }, make sure we are at
+	// the end.
+	if _, err := d.decoder.Token(); err != io.EOF {
+		return nil, &SyntaxError{Msg: "invalid character after top-level value", Offset: d.InputOffset()}
+	}
+
+	return l, nil
+}
+
+// JSONUnmarshalPrefix likes [JSONUnmarshal], but only decodes the first JSON
+// value in data, and returns the rest data after it, with whitespace right
+// after the value skipped.
+//
+// It always allows trailing data, regardless of the [AllowTrailingData]
+// option.
+func JSONUnmarshalPrefix(data []byte, option ...DecodeOption) (value any, rest []byte, err error) {
+	d := acquireDecoder(data, CreateDecodeOptions(option...))
+	defer releaseDecoder(d)
+
+	d.reset()
+
+	if value, err = d.next(); err != nil {
+		return nil, nil, d.unexpectedEOF(err)
+	}
+
+	rest = data[d.InputOffset():]
+	for len(rest) > 0 && isSpace(rest[0]) {
+		rest = rest[1:]
+	}
+
+	return value, rest, nil
 }
@@ -1,9 +1,16 @@
 package geko_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/7sDream/geko"
 )
@@ -191,3 +198,869 @@ func TestJSONUnmarshal_OnDuplicatedKey(t *testing.T) {
 		}
 	}
 }
+
+func TestJSONUnmarshal_NullInArray(t *testing.T) {
+	result, err := geko.JSONUnmarshal([]byte(`{"a": [null]}`))
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	arr, ok := result.(geko.ObjectItems).GetFirstOrZeroValue("a").(geko.Array)
+	if !ok {
+		t.Fatalf("Value is not Array type: %#v", result)
+	}
+
+	if arr.Len() != 1 || arr.Get(0) != nil {
+		t.Fatalf("Array with null item not correct: %#v", arr.List)
+	}
+}
+
+func TestJSONUnmarshal_MaxDepth(t *testing.T) {
+	check := func(data string, maxDepth int, shouldFail bool) {
+		checkLimit(t, data, geko.MaxDepth(maxDepth), geko.DepthLimit, shouldFail)
+	}
+
+	check(`1`, 1, false)
+	check(`[[1]]`, 2, false)
+	check(`[[1]]`, 1, true)
+	check(`{"a":{"b":1}}`, 2, false)
+	check(`{"a":{"b":1}}`, 1, true)
+	check(`[{"a":[]}]`, 3, false)
+	check(`[{"a":[]}]`, 2, true)
+	check(`[[], [], {"a": 1}]`, 2, false)
+	check(`[[[[[[1]]]]]]`, 0, false)
+
+	_, err := geko.JSONUnmarshal([]byte(`[[1, [2]]]`), geko.MaxDepth(2))
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 2 || limitErr.Offset != 6 {
+		t.Fatalf("Unmarshal error offset not correct: %#v", err)
+	}
+
+	deep := strings.Repeat("[", 100_000) + strings.Repeat("]", 100_000)
+	check(deep, 100, true)
+}
+
+func checkLimit(t *testing.T, data string, option geko.DecodeOption, exceptedKind geko.LimitKind, shouldFail bool) {
+	t.Helper()
+
+	_, err := geko.JSONUnmarshal([]byte(data), option, geko.UseObject())
+	if !shouldFail {
+		if err != nil {
+			t.Fatalf("Unmarshal %s error: %s", data, err.Error())
+		}
+		return
+	}
+
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Unmarshal %s should fail with limit error, got %#v", data, err)
+	}
+
+	if limitErr.Kind != exceptedKind {
+		t.Fatalf("Unmarshal %s failed with wrong limit kind: %s", data, limitErr.Kind)
+	}
+}
+
+func TestJSONUnmarshal_MaxItems(t *testing.T) {
+	// 3 items in one container
+	checkLimit(t, `[1, 2, 3]`, geko.MaxItems(3), geko.ItemsLimit, false)
+	checkLimit(t, `[1, 2, 3, 4]`, geko.MaxItems(3), geko.ItemsLimit, true)
+
+	// duplicated key are counted
+	checkLimit(t, `{"a": 1, "a": 2, "a": 3}`, geko.MaxItems(3), geko.ItemsLimit, false)
+	checkLimit(t, `{"a": 1, "a": 2, "a": 3, "a": 4}`, geko.MaxItems(3), geko.ItemsLimit, true)
+
+	// count is cumulative, each container has less than 3 items, but 4 total
+	checkLimit(t, `[[1], {"a": []}]`, geko.MaxItems(4), geko.ItemsLimit, false)
+	checkLimit(t, `[[1], {"a": [2]}]`, geko.MaxItems(4), geko.ItemsLimit, true)
+
+	checkLimit(t, `[1, 2, 3, 4]`, geko.MaxItems(0), geko.ItemsLimit, false)
+
+	_, err := geko.JSONUnmarshal([]byte(`[1, 2, 3]`), geko.MaxItems(2))
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 2 || limitErr.Offset != 8 {
+		t.Fatalf("Limit error not correct: %#v", err)
+	}
+}
+
+func TestJSONUnmarshal_MaxBytes(t *testing.T) {
+	checkLimit(t, `[1, 2, 3]`, geko.MaxBytes(9), geko.BytesLimit, false)
+	checkLimit(t, `[1, 2, 3] `, geko.MaxBytes(9), geko.BytesLimit, false)
+	checkLimit(t, `[1, 2, 3]`, geko.MaxBytes(8), geko.BytesLimit, true)
+	checkLimit(t, `{"a": {"b": "c"}}`, geko.MaxBytes(17), geko.BytesLimit, false)
+	checkLimit(t, `{"a": {"b": "c"}}`, geko.MaxBytes(16), geko.BytesLimit, true)
+	checkLimit(t, `"a long string"`, geko.MaxBytes(5), geko.BytesLimit, true)
+	checkLimit(t, `"a long string"`, geko.MaxBytes(0), geko.BytesLimit, false)
+
+	_, err := geko.JSONUnmarshal([]byte(`[1, 2, 3]`), geko.MaxBytes(4))
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != 4 || limitErr.Offset != 5 {
+		t.Fatalf("Limit error not correct: %#v", err)
+	}
+}
+
+func TestJSONUnmarshal_UseInt64(t *testing.T) {
+	data := []byte(`[` +
+		`9007199254740992, 9007199254740993, 9223372036854775807, -9223372036854775808,` +
+		`9223372036854775808, 1.0, 1e2, {"id": 42}` +
+		`]`)
+
+	result, err := geko.JSONUnmarshal(data, geko.UseInt64(true))
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	arr := result.(geko.Array)
+	excepted := []any{
+		int64(9007199254740992), int64(9007199254740993),
+		int64(9223372036854775807), int64(-9223372036854775808),
+		float64(9223372036854775808), 1.0, 100.0,
+	}
+
+	if !reflect.DeepEqual(arr.List[:len(excepted)], excepted) {
+		t.Fatalf("Unmarshal result not correct: %#v", arr.List)
+	}
+
+	if id := arr.Get(7).(geko.ObjectItems).GetFirstOrZeroValue("id"); id != int64(42) {
+		t.Fatalf("Nested value is not int64: %#v", id)
+	}
+
+	result, err = geko.JSONUnmarshal(data, geko.UseInt64(true), geko.UseNumber(true))
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	arr = result.(geko.Array)
+	excepted = []any{
+		int64(9007199254740992), int64(9007199254740993),
+		int64(9223372036854775807), int64(-9223372036854775808),
+		json.Number("9223372036854775808"), json.Number("1.0"), json.Number("1e2"),
+	}
+
+	if !reflect.DeepEqual(arr.List[:len(excepted)], excepted) {
+		t.Fatalf("Unmarshal result not correct: %#v", arr.List)
+	}
+}
+
+func TestJSONUnmarshal_UseInt64_OutOfRange(t *testing.T) {
+	_, err := geko.JSONUnmarshal([]byte(`[1e400]`), geko.UseInt64(true))
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Unmarshal out of range float should fail with type error, got %#v", err)
+	}
+}
+
+func TestJSONUnmarshal_UseBigNumber(t *testing.T) {
+	data := `[` +
+		`1234567890123456789012345678901234567890,` +
+		`-1234567890123456789012345678901234567890,` +
+		`3.1415926535897932384626433832795028841971693993751,` +
+		`-1e-39,` +
+		`0.1,` +
+		`{"price":12345678901234567890.1234567890123456789}` +
+		`]`
+
+	result, err := geko.JSONUnmarshal([]byte(data), geko.UseBigNumber(true))
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	arr := result.(geko.Array)
+	if i, ok := arr.Get(0).(*big.Int); !ok || i.String() != "1234567890123456789012345678901234567890" {
+		t.Fatalf("Integer is not correct big.Int: %#v", arr.Get(0))
+	}
+
+	if _, ok := arr.Get(2).(*big.Float); !ok {
+		t.Fatalf("Decimal is not big.Float: %#v", arr.Get(2))
+	}
+
+	output, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal error: %s", err.Error())
+	}
+
+	if string(output) != data {
+		t.Fatalf("Round trip result not correct: %s", string(output))
+	}
+
+	result, err = geko.JSONUnmarshal([]byte(`[1e2, 12]`), geko.UseBigNumber(true), geko.UseInt64(true))
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	arr = result.(geko.Array)
+	if arr.Get(1) != int64(12) {
+		t.Fatalf("UseInt64 should take precedence for small integer: %#v", arr.Get(1))
+	}
+
+	output, _ = json.Marshal(result)
+	if string(output) != `[100,12]` {
+		t.Fatalf("Marshal big.Float with exponent not correct: %s", string(output))
+	}
+
+	// large exponents are not expanded
+	result, _ = geko.JSONUnmarshal(
+		[]byte(`[1e600, -1.5e-600, 1e21, 0.000001, 0, 999999999999999999999.5, 0.0000001]`),
+		geko.UseBigNumber(true),
+	)
+	output, _ = geko.JSONMarshal(result)
+	if string(output) != `[1e+600,-1.5e-600,1e+21,0.000001,0,999999999999999999999.5,1e-07]` {
+		t.Fatalf("Marshal big.Float with large exponent not correct: %s", string(output))
+	}
+
+	for _, literal := range []string{`1e1000000`, `1e-1000000`, `-1e100000000`} {
+		_, err = geko.JSONUnmarshal([]byte(literal), geko.UseBigNumber(true))
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("Unmarshal big float with huge exponent should fail with type error, got %#v", err)
+		}
+	}
+
+	_, err = geko.JSONUnmarshal([]byte(`1e9999999999`), geko.UseBigNumber(true))
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Unmarshal out of range big float should fail with type error, got %#v", err)
+	}
+}
+
+// cents is a fixed-point type for money, stores value * 100.
+type cents int64
+
+var errInvalidCents = errors.New("invalid cents")
+
+func parseCents(literal string) (any, error) {
+	integer, fraction, _ := strings.Cut(literal, ".")
+	if len(fraction) > 2 || strings.ContainsAny(literal, "eE") {
+		return nil, errInvalidCents
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	v, err := strconv.ParseInt(integer+fraction, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return cents(v), nil
+}
+
+func (c cents) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", c/100, c%100)), nil
+}
+
+func TestJSONUnmarshal_NumberFunc(t *testing.T) {
+	data := `{"price":12.34,"items":[{"price":0.50},{"price":100}]}`
+
+	result, err := geko.JSONUnmarshal(
+		[]byte(data),
+		geko.NumberFunc(parseCents),
+		geko.UseNumber(true),
+		geko.UseInt64(true),
+		geko.UseObject(),
+	)
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	obj := result.(geko.Object)
+	if obj.GetOrZeroValue("price") != cents(1234) {
+		t.Fatalf("Number is not converted by NumberFunc: %#v", obj.GetOrZeroValue("price"))
+	}
+
+	items := obj.GetOrZeroValue("items").(geko.Array)
+	if items.Get(1).(geko.Object).GetOrZeroValue("price") != cents(10000) {
+		t.Fatalf("Nested number is not converted by NumberFunc: %#v", items.Get(1))
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"price":12.34,"items":[{"price":0.50},{"price":100.00}]}` {
+		t.Fatalf("Marshal result not correct: %s", string(output))
+	}
+
+	_, err = geko.JSONUnmarshal([]byte(`[1.5, 1.234]`), geko.NumberFunc(parseCents))
+
+	var numberErr *geko.NumberFuncError
+	if !errors.As(err, &numberErr) {
+		t.Fatalf("Unmarshal should fail with NumberFuncError, got %#v", err)
+	}
+
+	if numberErr.Literal != "1.234" || numberErr.Offset != 11 || !errors.Is(err, errInvalidCents) {
+		t.Fatalf("NumberFuncError not correct: %#v", numberErr)
+	}
+
+	if err.Error() != "geko: convert number 1.234 at offset 11: invalid cents" {
+		t.Fatalf("NumberFuncError message not correct: %s", err.Error())
+	}
+}
+
+func TestJSONUnmarshal_KeyTransform(t *testing.T) {
+	data := []byte(`{"userId": 1, "name": "a", "UserID": 2, "nested": [{"A": 1, "a": 2}], "userid": 3}`)
+
+	result, err := geko.JSONUnmarshal(data, geko.KeyTransform(strings.ToLower), geko.UseObject())
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"userid":3,"name":"a","nested":[{"a":2}]}` {
+		t.Fatalf("Unmarshal result not correct: %s", string(output))
+	}
+
+	result, err = geko.JSONUnmarshal(
+		data, geko.KeyTransform(strings.ToLower), geko.UseObject(),
+		geko.ObjectOnDuplicatedKey(geko.Ignore),
+	)
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	output, _ = json.Marshal(result)
+	if string(output) != `{"userid":1,"name":"a","nested":[{"a":1}]}` {
+		t.Fatalf("Unmarshal result not correct: %s", string(output))
+	}
+
+	result, err = geko.JSONUnmarshal(data, geko.KeyTransform(strings.ToLower))
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	keys := result.(geko.ObjectItems).Keys()
+	if !reflect.DeepEqual(keys, []string{"userid", "name", "userid", "nested", "userid"}) {
+		t.Fatalf("Unmarshal into ObjectItems keys not correct: %#v", keys)
+	}
+}
+
+func TestJSONUnmarshal_EmptyInput(t *testing.T) {
+	_, err := geko.JSONUnmarshal([]byte(" "))
+
+	var syntaxErr *geko.SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Msg != "unexpected end of JSON input" {
+		t.Fatalf("Unmarshal empty input should fail with syntax error, got %#v", err)
+	}
+}
+
+func TestJSONUnmarshal_AllowTrailingData(t *testing.T) {
+	check := func(data string, shouldFail bool) {
+		_, err := geko.JSONUnmarshal([]byte(data))
+		if (err != nil) != shouldFail {
+			t.Fatalf("Unmarshal %q without AllowTrailingData, excepted fail %v, got %#v", data, shouldFail, err)
+		}
+
+		result, err := geko.JSONUnmarshal([]byte(data), geko.AllowTrailingData(true))
+		if err != nil {
+			t.Fatalf("Unmarshal %q with AllowTrailingData error: %s", data, err.Error())
+		}
+
+		if result.(geko.Array).Get(0) != 1.0 {
+			t.Fatalf("Unmarshal %q with AllowTrailingData result not correct: %#v", data, result)
+		}
+	}
+
+	check(`[1]  `, false)
+	check(`[1] [2]`, true)
+	check(`[1]garbage`, true)
+	check("[1]\n\x00\x01\x02", true)
+}
+
+func TestJSONUnmarshalPrefix(t *testing.T) {
+	check := func(data string, exceptedRest string) {
+		result, rest, err := geko.JSONUnmarshalPrefix([]byte(data))
+		if err != nil {
+			t.Fatalf("Unmarshal %q error: %s", data, err.Error())
+		}
+
+		if string(rest) != exceptedRest {
+			t.Fatalf("Unmarshal %q rest excepted %q, got %q", data, exceptedRest, string(rest))
+		}
+
+		if obj, ok := result.(geko.ObjectItems); !ok || obj.GetFirstOrZeroValue("a") != 1.0 {
+			t.Fatalf("Unmarshal %q result not correct: %#v", data, result)
+		}
+	}
+
+	check(`{"a": 1}`, ``)
+	check("{\"a\": 1} \t\r\n", ``)
+	check(`{"a": 1} {"b": 2}`, `{"b": 2}`)
+	check("{\"a\": 1}\n\x00\x01binary \n", "\x00\x01binary \n")
+
+	result, rest, err := geko.JSONUnmarshalPrefix([]byte(`12 34`), geko.UseInt64(true))
+	if err != nil || result != int64(12) || string(rest) != `34` {
+		t.Fatalf("Unmarshal scalar prefix result not correct: %#v, %q, %#v", result, rest, err)
+	}
+
+	if _, _, err = geko.JSONUnmarshalPrefix([]byte(`{"a": 1`)); err == nil {
+		t.Fatalf("Unmarshal invalid data should report error")
+	}
+
+	var syntaxErr *geko.SyntaxError
+	for _, data := range []string{`[1,`, ``} {
+		if _, _, err = geko.JSONUnmarshalPrefix([]byte(data)); !errors.As(err, &syntaxErr) {
+			t.Fatalf("Unmarshal truncated prefix %q should fail with syntax error, got %#v", data, err)
+		}
+	}
+}
+
+func TestJSONUnmarshalAll(t *testing.T) {
+	data := `{"b": 1, "a": 2}
+{
+	"b": 3,
+	"a": [
+		4
+	]
+}
+[5, 6]"seven" 8 true null{"c":9}
+`
+
+	result, err := geko.JSONUnmarshalAll([]byte(data), geko.UseObject())
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	excepted := `[{"b":1,"a":2},{"b":3,"a":[4]},[5,6],"seven",8,true,null,{"c":9}]`
+	if string(output) != excepted {
+		t.Fatalf("Unmarshal result not correct: %s", string(output))
+	}
+
+	if _, ok := result.Get(1).(geko.Object); !ok {
+		t.Fatalf("Object is not decoded with options: %#v", result.Get(1))
+	}
+
+	empty, err := geko.JSONUnmarshalAll([]byte(" \n "))
+	if err != nil || empty.Len() != 0 {
+		t.Fatalf("Unmarshal empty input result not correct: %#v, %#v", empty, err)
+	}
+
+	invalid := func(data string) {
+		if _, err := geko.JSONUnmarshalAll([]byte(data)); err == nil {
+			t.Fatalf("Unmarshal %q should report error", data)
+		}
+	}
+
+	invalid(`{"a": 1} {"b"}`)
+	invalid(`{"a": 1} ]`)
+	invalid(`1 }`)
+
+	var syntaxErr *geko.SyntaxError
+	if _, err = geko.JSONUnmarshalAll([]byte(`{"a": 1} {"b": 2`)); !errors.As(err, &syntaxErr) {
+		t.Fatalf("Unmarshal truncated final value should fail with syntax error, got %#v", err)
+	}
+}
+
+func rawValuesFixture() []byte {
+	var buf strings.Builder
+	_, _ = buf.WriteString(`{"type": "event", "id": 1, `)
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&buf,
+			`"k%d": {"n": %d, "f": %d.5, "s": "vé\"%d", "a": [1, {"x": null}], "e": {}}, `,
+			i%100, i, i, i,
+		)
+	}
+	_, _ = buf.WriteString(`"type": "override", "tail": [true, false]}`)
+	return []byte(buf.String())
+}
+
+func TestJSONUnmarshal_UseRawValues(t *testing.T) {
+	data := rawValuesFixture()
+
+	result, err := geko.JSONUnmarshal(data, geko.UseRawValues())
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	ps, ok := result.(*geko.Pairs[string, json.RawMessage])
+	if !ok {
+		t.Fatalf("Result type is not Pairs of raw message: %#v", result)
+	}
+
+	if ps.Len() != 1004 || string(ps.GetFirstOrZeroValue("type")) != `"event"` {
+		t.Fatalf("Result not correct: len %d", ps.Len())
+	}
+
+	output, err := json.Marshal(ps)
+	if err != nil {
+		t.Fatalf("Marshal error: %s", err.Error())
+	}
+
+	var compacted bytes.Buffer
+	_ = json.Compact(&compacted, data)
+	if !bytes.Equal(output, compacted.Bytes()) {
+		t.Fatalf("Round trip output not same as input")
+	}
+
+	nested, err := geko.JSONUnmarshal(ps.GetLastOrZeroValue("k1"), geko.UseObject())
+	if err != nil || nested.(geko.Object).GetOrZeroValue("n") != 901.0 {
+		t.Fatalf("Decode raw value later not correct: %#v, %#v", nested, err)
+	}
+}
+
+func TestJSONUnmarshal_UseRawValues_UseObject(t *testing.T) {
+	data := []byte(`[{"b": [1, 2], "a": {"x": 1}, "b": "new"}, 3]`)
+
+	result, err := geko.JSONUnmarshal(data, geko.UseRawValues(), geko.UseObject())
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	arr := result.(geko.Array)
+	m, ok := arr.Get(0).(*geko.Map[string, json.RawMessage])
+	if !ok {
+		t.Fatalf("Object in array is not Map of raw message: %#v", arr.Get(0))
+	}
+
+	if string(m.GetOrZeroValue("a")) != `{"x": 1}` || string(m.GetOrZeroValue("b")) != `"new"` {
+		t.Fatalf("Raw value not correct: %#v", m)
+	}
+
+	if arr.Get(1) != 3.0 {
+		t.Fatalf("Array item not correct: %#v", arr.Get(1))
+	}
+
+	if _, err = geko.JSONUnmarshal([]byte(`{"a": [1, }`), geko.UseRawValues()); err == nil {
+		t.Fatalf("Unmarshal invalid data should report error")
+	}
+}
+
+func TestJSONUnmarshal_DisallowDuplicateKeys(t *testing.T) {
+	data := `{"outer": {"a": 1, "b": 2, "a": 3}}`
+
+	check := func(err error) {
+		t.Helper()
+
+		var dupErr *geko.DuplicateKeyError
+		if !errors.As(err, &dupErr) {
+			t.Fatalf("Unmarshal should fail with DuplicateKeyError, got %#v", err)
+		}
+
+		if dupErr.Key != "a" || dupErr.Index != 2 || dupErr.Offset != int64(strings.LastIndex(data, `"a"`)+3) {
+			t.Fatalf("DuplicateKeyError not correct: %#v", dupErr)
+		}
+	}
+
+	_, err := geko.JSONUnmarshal([]byte(data), geko.DisallowDuplicateKeys(true))
+	check(err)
+
+	_, err = geko.JSONUnmarshal([]byte(data), geko.DisallowDuplicateKeys(true), geko.UseObject())
+	check(err)
+
+	v := geko.Any{Opts: geko.CreateDecodeOptions(geko.DisallowDuplicateKeys(true))}
+	check(json.Unmarshal([]byte(data), &v))
+
+	_, err = geko.JSONUnmarshal(
+		[]byte(`{"A": 1, "a": 2}`),
+		geko.DisallowDuplicateKeys(true), geko.KeyTransform(strings.ToLower),
+	)
+	var dupErr *geko.DuplicateKeyError
+	if !errors.As(err, &dupErr) || dupErr.Key != "a" || dupErr.Index != 1 {
+		t.Fatalf("Unmarshal should fail with DuplicateKeyError after key transform, got %#v", err)
+	}
+
+	if _, err = geko.JSONUnmarshal([]byte(`{"a": {"a": 1}, "b": [{"a": 1}, {"a": 2}]}`),
+		geko.DisallowDuplicateKeys(true)); err != nil {
+		t.Fatalf("Unmarshal without duplicated key error: %s", err.Error())
+	}
+
+	if _, err = geko.JSONUnmarshal([]byte(data)); err != nil {
+		t.Fatalf("Unmarshal without DisallowDuplicateKeys error: %s", err.Error())
+	}
+}
+
+func TestJSONUnmarshal_ReuseDecoderState(t *testing.T) {
+	// decoders are pooled, states of a failed decoding should not leak
+	for i := 0; i < 100; i++ {
+		if _, err := geko.JSONUnmarshal([]byte(`[[[1, 2`), geko.MaxItems(1), geko.UseNumber(true)); err == nil {
+			t.Fatalf("Unmarshal invalid data without error")
+		}
+
+		v, err := geko.JSONUnmarshal([]byte(`[[1, 2]]`))
+		if err != nil {
+			t.Fatalf("Unmarshal with error: %s", err.Error())
+		}
+
+		if v.(geko.Array).Get(0).(geko.Array).Get(0) != 1.0 {
+			t.Fatalf("Unmarshal result not correct: %#v", v)
+		}
+	}
+}
+
+func BenchmarkJSONUnmarshal_Small(b *testing.B) {
+	data := []byte(`{"id": 1, "name": "geko", "ok": true}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = geko.JSONUnmarshal(data)
+	}
+}
+
+func BenchmarkJSONUnmarshal_WideObject(b *testing.B) {
+	var buf strings.Builder
+	_, _ = buf.WriteString("{")
+	for i := 0; i < 10_000; i++ {
+		if i > 0 {
+			_, _ = buf.WriteString(",")
+		}
+		_, _ = buf.WriteString(`"key` + strconv.Itoa(i) + `":` + strconv.Itoa(i))
+	}
+	_, _ = buf.WriteString("}")
+	data := []byte(buf.String())
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = geko.JSONUnmarshal(data)
+	}
+}
+
+func TestJSONUnmarshal_SizeHint(t *testing.T) {
+	data := `{"a": [1, 2], "b": {"c": 3}}`
+
+	for _, option := range []geko.DecodeOption{geko.UseObject(), geko.UseObjectItems()} {
+		excepted, _ := geko.JSONUnmarshal([]byte(data), option)
+		v, err := geko.JSONUnmarshal([]byte(data), option, geko.SizeHint(100, 100))
+		if err != nil {
+			t.Fatalf("Unmarshal with error: %s", err.Error())
+		}
+
+		output, _ := json.Marshal(v)
+		exceptedOutput, _ := json.Marshal(excepted)
+		if string(output) != string(exceptedOutput) {
+			t.Fatalf("Unmarshal with size hint result not same: %s", string(output))
+		}
+	}
+
+	v, _ := geko.JSONUnmarshal([]byte(`[[1], {}]`), geko.SizeHint(100, 100))
+	l := v.(geko.Array)
+	if cap(l.List) != 100 || cap(l.Get(0).(geko.Array).List) == 100 {
+		t.Fatalf("Size hint should only apply to top-level container: %d", cap(l.List))
+	}
+}
+
+func sizeHintBenchmarkData(n int, object bool) []byte {
+	var buf strings.Builder
+	if object {
+		_, _ = buf.WriteString("{")
+	} else {
+		_, _ = buf.WriteString("[")
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			_, _ = buf.WriteString(",")
+		}
+		if object {
+			_, _ = buf.WriteString(`"k` + strconv.Itoa(i) + `":`)
+		}
+		_, _ = buf.WriteString(strconv.Itoa(i))
+	}
+	if object {
+		_, _ = buf.WriteString("}")
+	} else {
+		_, _ = buf.WriteString("]")
+	}
+	return []byte(buf.String())
+}
+
+func BenchmarkJSONUnmarshal_SizeHint(b *testing.B) {
+	array := sizeHintBenchmarkData(1_000_000, false)
+	object := sizeHintBenchmarkData(100_000, true)
+
+	cases := []struct {
+		name   string
+		data   []byte
+		option []geko.DecodeOption
+	}{
+		{"Array1M", array, nil},
+		{"Array1M_Hint", array, []geko.DecodeOption{geko.SizeHint(0, 1_000_000)}},
+		{"Object100K", object, []geko.DecodeOption{geko.UseObject()}},
+		{"Object100K_Hint", object, []geko.DecodeOption{geko.UseObject(), geko.SizeHint(100_000, 0)}},
+		{"ObjectItems100K", object, nil},
+		{"ObjectItems100K_Hint", object, []geko.DecodeOption{geko.SizeHint(100_000, 0)}},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = geko.JSONUnmarshal(c.data, c.option...)
+			}
+		})
+	}
+}
+
+func parseTimeValue(raw json.RawMessage) (any, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func TestJSONUnmarshal_WithValueDecoder(t *testing.T) {
+	data := `{
+		"created_at": "2024-01-02T03:04:05Z",
+		"items": [{"id": 1, "created_at": "2024-05-06T07:08:09Z"}],
+		"note": "created_at"
+	}`
+
+	v, err := geko.JSONUnmarshal([]byte(data), geko.UseObject(), geko.WithValueDecoder("created_at", parseTimeValue))
+	if err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	object := v.(geko.Object)
+	if object.GetOrZeroValue("created_at") != time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) {
+		t.Fatalf("Value decoded by hook not correct: %#v", object.GetOrZeroValue("created_at"))
+	}
+
+	inner := object.GetOrZeroValue("items").(geko.Array).Get(0).(geko.Object)
+	if inner.GetOrZeroValue("created_at") != time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC) {
+		t.Fatalf("Nested value decoded by hook not correct: %#v", inner.GetOrZeroValue("created_at"))
+	}
+
+	if inner.GetOrZeroValue("id") != 1.0 || object.GetOrZeroValue("note") != "created_at" {
+		t.Fatalf("Other values should not be affected: %#v", object)
+	}
+
+	v, _ = geko.JSONUnmarshal(
+		[]byte(`{"CREATED_AT": "2024-01-02T03:04:05Z"}`),
+		geko.KeyTransform(strings.ToLower), geko.WithValueDecoder("created_at", parseTimeValue),
+	)
+	if _, ok := v.(geko.ObjectItems).Get("created_at")[0].(time.Time); !ok {
+		t.Fatalf("Hook should match transformed key: %#v", v)
+	}
+
+	v, _ = geko.JSONUnmarshal(
+		[]byte(`{"created_at": "now"}`),
+		geko.WithValueDecoder("created_at", parseTimeValue), geko.WithValueDecoder("created_at", nil),
+	)
+	if v.(geko.ObjectItems).Get("created_at")[0] != "now" {
+		t.Fatalf("Hook should be removed by nil function: %#v", v)
+	}
+}
+
+func TestJSONUnmarshal_WithValueDecoder_Error(t *testing.T) {
+	data := `{"a": {"created_at": "yesterday"}}`
+
+	_, err := geko.JSONUnmarshal([]byte(data), geko.WithValueDecoder("created_at", parseTimeValue))
+
+	var hookErr *geko.ValueDecoderError
+	if !errors.As(err, &hookErr) || hookErr.Key != "created_at" || hookErr.Offset != int64(strings.Index(data, "}")) {
+		t.Fatalf("Unmarshal should fail with ValueDecoderError, got %#v", err)
+	}
+
+	var timeErr *time.ParseError
+	if !errors.As(err, &timeErr) {
+		t.Fatalf("ValueDecoderError should wrap hook error, got %#v", hookErr.Err)
+	}
+
+	_, err = geko.JSONUnmarshal([]byte(`{"created_at": [1, }`), geko.WithValueDecoder("created_at", parseTimeValue))
+	if err == nil {
+		t.Fatalf("Unmarshal invalid value should fail")
+	}
+
+	var limitErr *geko.LimitExceededError
+	_, err = geko.JSONUnmarshal(
+		[]byte(`{"created_at": "2024-01-02T03:04:05Z"}`),
+		geko.WithValueDecoder("created_at", parseTimeValue), geko.MaxBytes(20),
+	)
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Unmarshal should fail when exceeds max bytes, got %#v", err)
+	}
+}
+
+func TestNewObjectFromJSON(t *testing.T) {
+	object, err := geko.NewObjectFromJSON([]byte(`{"b": 1, "a": {"y": 2.50, "x": [3]}, "b": 4}`), geko.UseNumber(true))
+	if err != nil {
+		t.Fatalf("NewObjectFromJSON with error: %s", err.Error())
+	}
+
+	if n, _ := object.GetOrZeroValue("b").(json.Number); n != "4" {
+		t.Fatalf("NewObjectFromJSON should apply UseNumber: %#v", object.GetOrZeroValue("b"))
+	}
+
+	if _, isObject := object.GetOrZeroValue("a").(geko.Object); !isObject {
+		t.Fatalf("NewObjectFromJSON should use Object for nested object: %#v", object.GetOrZeroValue("a"))
+	}
+
+	output, _ := json.Marshal(object)
+	if string(output) != `{"b":4,"a":{"y":2.50,"x":[3]}}` {
+		t.Fatalf("NewObjectFromJSON result not correct: %s", string(output))
+	}
+}
+
+func TestNewObjectFromJSON_ObjectItems(t *testing.T) {
+	data := []byte(`{"b": 1, "a": {"y": 2, "y": 3}, "b": 4}`)
+
+	for _, c := range []struct {
+		strategy geko.DuplicatedKeyStrategy
+		excepted string
+	}{
+		{geko.UpdateValueKeepOrder, `{"b":4,"a":{"y":2,"y":3}}`},
+		{geko.KeepValueUpdateOrder, `{"a":{"y":2,"y":3},"b":1}`},
+	} {
+		object, err := geko.NewObjectFromJSON(data, geko.UseObjectItems(), geko.ObjectOnDuplicatedKey(c.strategy))
+		if err != nil {
+			t.Fatalf("NewObjectFromJSON with error: %s", err.Error())
+		}
+
+		if _, isObjectItems := object.GetOrZeroValue("a").(geko.ObjectItems); !isObjectItems {
+			t.Fatalf("Nested object should be ObjectItems: %#v", object.GetOrZeroValue("a"))
+		}
+
+		output, _ := json.Marshal(object)
+		if string(output) != c.excepted {
+			t.Fatalf("NewObjectFromJSON with strategy %d result not correct: %s", c.strategy, string(output))
+		}
+	}
+}
+
+func TestNewArrayFromJSON(t *testing.T) {
+	array, err := geko.NewArrayFromJSON([]byte(`[1, {"b": 2, "a": 3}, [4.0]]`), geko.UseObject(), geko.UseNumber(true))
+	if err != nil {
+		t.Fatalf("NewArrayFromJSON with error: %s", err.Error())
+	}
+
+	if n, _ := array.Get(0).(json.Number); n != "1" {
+		t.Fatalf("NewArrayFromJSON should apply UseNumber: %#v", array.Get(0))
+	}
+
+	if _, isObject := array.Get(1).(geko.Object); !isObject {
+		t.Fatalf("NewArrayFromJSON should apply UseObject: %#v", array.Get(1))
+	}
+
+	output, _ := json.Marshal(array)
+	if string(output) != `[1,{"b":2,"a":3},[4.0]]` {
+		t.Fatalf("NewArrayFromJSON result not correct: %s", string(output))
+	}
+}
+
+func TestNewFromJSON_WrongType(t *testing.T) {
+	for _, data := range []string{`[1]`, `"s"`, `1`, `true`, `null`} {
+		object, err := geko.NewObjectFromJSON([]byte(data))
+
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) || object != nil {
+			t.Fatalf("NewObjectFromJSON should fail on %s: %#v", data, err)
+		}
+
+		excepted := "json: cannot unmarshal non-object value into Go value of type *geko.Map[string,interface {}]"
+		if err.Error() != excepted {
+			t.Fatalf("NewObjectFromJSON error message not correct: %s", err.Error())
+		}
+	}
+
+	for _, data := range []string{`{}`, `"s"`, `1`, `false`, `null`} {
+		array, err := geko.NewArrayFromJSON([]byte(data))
+
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) || array != nil {
+			t.Fatalf("NewArrayFromJSON should fail on %s: %#v", data, err)
+		}
+	}
+
+	if _, err := geko.NewObjectFromJSON([]byte(`{"a": 1`)); err == nil {
+		t.Fatalf("NewObjectFromJSON should fail on invalid JSON")
+	}
+
+	if _, err := geko.NewArrayFromJSON([]byte(`[1,`)); err == nil {
+		t.Fatalf("NewArrayFromJSON should fail on invalid JSON")
+	}
+}
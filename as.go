@@ -0,0 +1,58 @@
+package geko
+
+// AsObject asserts v is a JSON object, and returns it as an [Object]. The
+// second return value is false if v is not an object.
+//
+// An [Object] is returned as is. An [ObjectItems] is converted by
+// [Pairs.ToMap] into a new [Object], with strategy if provided, or
+// [UpdateValueKeepOrder] by default. Nested values are not converted, they are
+// shared by the result.
+//
+// A nil [Object] or [ObjectItems] results a nil [Object].
+func AsObject(v any, strategy ...DuplicatedKeyStrategy) (Object, bool) {
+	switch x := v.(type) {
+	case Object:
+		return x, true
+	case ObjectItems:
+		if x == nil {
+			return nil, true
+		}
+
+		s := UpdateValueKeepOrder
+		if len(strategy) > 0 {
+			s = strategy[0]
+		}
+		return x.ToMap(s), true
+	default:
+		return nil, false
+	}
+}
+
+// AsObjectItems asserts v is a JSON object, and returns it as an
+// [ObjectItems]. The second return value is false if v is not an object.
+//
+// An [ObjectItems] is returned as is. An [Object] is converted by [Map.Pairs]
+// into a new [ObjectItems]. Nested values are not converted, they are shared
+// by the result.
+//
+// A nil [Object] or [ObjectItems] results a nil [ObjectItems].
+func AsObjectItems(v any) (ObjectItems, bool) {
+	switch x := v.(type) {
+	case ObjectItems:
+		return x, true
+	case Object:
+		if x == nil {
+			return nil, true
+		}
+		return x.Pairs(), true
+	default:
+		return nil, false
+	}
+}
+
+// AsArray asserts v is an [Array], and returns it. The second return value is
+// false if v is not an [Array], including a []any.
+func AsArray(v any) (Array, bool) {
+	array, ok := v.(Array)
+	return array, ok
+}
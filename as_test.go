@@ -0,0 +1,110 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const asTestJSON = `{"a": 1, "b": {"c": 2}, "a": 3}`
+
+func TestAsObject(t *testing.T) {
+	object, _ := geko.JSONUnmarshal([]byte(asTestJSON), geko.UseObject())
+	items, _ := geko.JSONUnmarshal([]byte(asTestJSON))
+
+	for _, c := range []struct {
+		value    any
+		strategy []geko.DuplicatedKeyStrategy
+		excepted string
+	}{
+		{object, nil, `{"a":3,"b":{"c":2}}`},
+		{items, nil, `{"a":3,"b":{"c":2}}`},
+		{items, []geko.DuplicatedKeyStrategy{geko.KeepValueUpdateOrder}, `{"b":{"c":2},"a":1}`},
+	} {
+		result, ok := geko.AsObject(c.value, c.strategy...)
+		if !ok {
+			t.Fatalf("AsObject of %T should success", c.value)
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != c.excepted {
+			t.Fatalf("AsObject result not correct, excepted %s, got %s", c.excepted, string(output))
+		}
+	}
+
+	if result, _ := geko.AsObject(object); result != object {
+		t.Fatalf("AsObject should return Object as is")
+	}
+}
+
+func TestAsObjectItems(t *testing.T) {
+	object, _ := geko.JSONUnmarshal([]byte(asTestJSON), geko.UseObject())
+	items, _ := geko.JSONUnmarshal([]byte(asTestJSON))
+
+	for _, c := range []struct {
+		value    any
+		excepted string
+	}{
+		{object, `{"a":3,"b":{"c":2}}`},
+		{items, `{"a":1,"b":{"c":2},"a":3}`},
+	} {
+		result, ok := geko.AsObjectItems(c.value)
+		if !ok {
+			t.Fatalf("AsObjectItems of %T should success", c.value)
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != c.excepted {
+			t.Fatalf("AsObjectItems result not correct, excepted %s, got %s", c.excepted, string(output))
+		}
+	}
+
+	if result, _ := geko.AsObjectItems(items); result != items {
+		t.Fatalf("AsObjectItems should return ObjectItems as is")
+	}
+}
+
+func TestAsArray(t *testing.T) {
+	array, _ := geko.JSONUnmarshal([]byte(`[1, 2]`))
+
+	if result, ok := geko.AsArray(array); !ok || result != array {
+		t.Fatalf("AsArray should return Array as is")
+	}
+}
+
+func TestAs_Nil(t *testing.T) {
+	var object geko.Object
+	var items geko.ObjectItems
+
+	for _, v := range []any{object, items} {
+		if result, ok := geko.AsObject(v); !ok || result != nil {
+			t.Fatalf("AsObject of nil %T should be nil", v)
+		}
+		if result, ok := geko.AsObjectItems(v); !ok || result != nil {
+			t.Fatalf("AsObjectItems of nil %T should be nil", v)
+		}
+	}
+}
+
+func TestAs_NotContainer(t *testing.T) {
+	array, _ := geko.JSONUnmarshal([]byte(`[1]`))
+
+	for _, v := range []any{
+		nil, true, 1.0, "s", json.Number("1"), map[string]any{}, []any{},
+		geko.NewMap[string, int](), array,
+	} {
+		if _, ok := geko.AsObject(v); ok {
+			t.Fatalf("AsObject of %T should fail", v)
+		}
+		if _, ok := geko.AsObjectItems(v); ok {
+			t.Fatalf("AsObjectItems of %T should fail", v)
+		}
+	}
+
+	for _, v := range []any{nil, 1.0, []any{}, geko.NewList[int](), geko.NewMap[string, any]()} {
+		if _, ok := geko.AsArray(v); ok {
+			t.Fatalf("AsArray of %T should fail", v)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package geko
+
+import "fmt"
+
+// normalizeIndex converts a negative index into a position counted from the
+// end, and checks if it is in range [0, length).
+func normalizeIndex(index, length int) (int, bool) {
+	normalized := index
+	if index < 0 {
+		normalized += length
+	}
+	return normalized, normalized >= 0 && normalized < length
+}
+
+func mustNormalizeIndex(index, length int) int {
+	normalized, ok := normalizeIndex(index, length)
+	if !ok {
+		panic(fmt.Errorf(
+			"geko: At: index %d (normalized to %d) out of range of length %d", index, normalized, length,
+		))
+	}
+	return normalized
+}
+
+// At gets the kv pair at index of current order, like [Map.GetByIndex], but
+// a negative index counts from the end, -1 means the last one.
+//
+// It panics if index is out of range, the message contains both index and its
+// normalized value.
+func (m *Map[K, V]) At(index int) Pair[K, V] {
+	return m.GetByIndex(mustNormalizeIndex(index, m.Len()))
+}
+
+// AtOK is like [Map.At], but returns false instead of panic if index is out of
+// range.
+func (m *Map[K, V]) AtOK(index int) (Pair[K, V], bool) {
+	normalized, ok := normalizeIndex(index, m.Len())
+	if !ok {
+		return Pair[K, V]{}, false
+	}
+	return m.GetByIndex(normalized), true
+}
+
+// At gets the kv pair at index, like [Pairs.GetByIndex], but a negative index
+// counts from the end, -1 means the last one.
+//
+// It panics if index is out of range, the message contains both index and its
+// normalized value.
+func (ps *Pairs[K, V]) At(index int) Pair[K, V] {
+	return ps.GetByIndex(mustNormalizeIndex(index, ps.Len()))
+}
+
+// AtOK is like [Pairs.At], but returns false instead of panic if index is out
+// of range.
+func (ps *Pairs[K, V]) AtOK(index int) (Pair[K, V], bool) {
+	normalized, ok := normalizeIndex(index, ps.Len())
+	if !ok {
+		return Pair[K, V]{}, false
+	}
+	return ps.GetByIndex(normalized), true
+}
+
+// At gets the item at index, like [List.Get], but a negative index counts from
+// the end, -1 means the last one.
+//
+// It panics if index is out of range, the message contains both index and its
+// normalized value.
+func (l *List[T]) At(index int) T {
+	return l.Get(mustNormalizeIndex(index, l.Len()))
+}
+
+// AtOK is like [List.At], but returns false instead of panic if index is out
+// of range.
+func (l *List[T]) AtOK(index int) (T, bool) {
+	normalized, ok := normalizeIndex(index, l.Len())
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return l.Get(normalized), true
+}
@@ -0,0 +1,61 @@
+package geko_test
+
+import (
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestAt(t *testing.T) {
+	m := geko.NewMapOf(geko.P("a", 1), geko.P("b", 2), geko.P("c", 3))
+	ps := m.Pairs()
+	l := geko.NewListOf("a", "b", "c")
+
+	for _, c := range []struct {
+		index    int
+		excepted string
+	}{
+		{0, "a"},
+		{2, "c"},
+		{-1, "c"},
+		{-2, "b"},
+		{-3, "a"},
+	} {
+		mp, mok := m.AtOK(c.index)
+		pp, pok := ps.AtOK(c.index)
+		item, lok := l.AtOK(c.index)
+		if m.At(c.index).Key != c.excepted || ps.At(c.index).Key != c.excepted || l.At(c.index) != c.excepted ||
+			!mok || !pok || !lok || mp.Key != c.excepted || pp.Key != c.excepted || item != c.excepted {
+			t.Fatalf("At %d result not correct", c.index)
+		}
+	}
+}
+
+func TestAt_OutOfRange(t *testing.T) {
+	m := geko.NewMapOf(geko.P("a", 1), geko.P("b", 2), geko.P("c", 3))
+	ps := m.Pairs()
+	l := geko.NewListOf("a", "b", "c")
+
+	for _, c := range []struct {
+		index    int
+		excepted string
+	}{
+		{3, "geko: At: index 3 (normalized to 3) out of range of length 3"},
+		{-4, "geko: At: index -4 (normalized to -1) out of range of length 3"},
+	} {
+		assertPanicMessage(t, c.excepted, func() { m.At(c.index) })
+		assertPanicMessage(t, c.excepted, func() { ps.At(c.index) })
+		assertPanicMessage(t, c.excepted, func() { l.At(c.index) })
+
+		mp, mok := m.AtOK(c.index)
+		pp, pok := ps.AtOK(c.index)
+		item, lok := l.AtOK(c.index)
+		if mok || pok || lok || mp.Key != "" || pp.Key != "" || item != "" {
+			t.Fatalf("AtOK %d should fail", c.index)
+		}
+	}
+
+	assertPanicMessage(t, "index -1 (normalized to -1) out of range of length 0", func() {
+		geko.NewList[int]().At(-1)
+	})
+}
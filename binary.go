@@ -0,0 +1,561 @@
+package geko
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// binaryVersion is the current version of the binary format.
+const binaryVersion byte = 1
+
+// binaryMaxDepth limits nesting of decoded containers, to avoid stack
+// overflow on malicious input, like the std lib JSON decoder does.
+const binaryMaxDepth = 10000
+
+// Value tags of the binary format.
+const (
+	binaryNull byte = iota
+	binaryFalse
+	binaryTrue
+	binaryInteger
+	binaryFloat
+	binaryNumber
+	binaryString
+	binaryArray
+	binaryObject
+	binaryObjectItems
+)
+
+// binaryEncoder writes values in the binary format, see [Map.MarshalBinary].
+type binaryEncoder struct {
+	buf []byte
+}
+
+// binaryEncodable is implemented by containers in this package, to encode
+// themselves in the binary format directly.
+type binaryEncodable interface {
+	binaryEncode(e *binaryEncoder) error
+}
+
+func (e *binaryEncoder) uvarint(n uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, b[:binary.PutUvarint(b[:], n)]...)
+}
+
+func (e *binaryEncoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *binaryEncoder) integer(n int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf, binaryInteger)
+	e.buf = append(e.buf, b[:binary.PutVarint(b[:], n)]...)
+}
+
+func (e *binaryEncoder) float(f float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	e.buf = append(e.buf, binaryFloat)
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *binaryEncoder) members(tag byte, n int, member func(i int) (string, any)) error {
+	e.buf = append(e.buf, tag)
+	e.uvarint(uint64(n))
+
+	for i := 0; i < n; i++ {
+		key, value := member(i)
+		e.string(key)
+		if err := e.value(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *binaryEncoder) items(items []any) error {
+	e.buf = append(e.buf, binaryArray)
+	e.uvarint(uint64(len(items)))
+
+	for _, item := range items {
+		if err := e.value(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *binaryEncoder) value(v any) error {
+	switch x := v.(type) {
+	case binaryEncodable:
+		return x.binaryEncode(e)
+	case nil:
+		e.buf = append(e.buf, binaryNull)
+	case bool:
+		if x {
+			e.buf = append(e.buf, binaryTrue)
+		} else {
+			e.buf = append(e.buf, binaryFalse)
+		}
+	case string:
+		e.buf = append(e.buf, binaryString)
+		e.string(x)
+	case json.Number:
+		if !isJSONNumber(string(x)) {
+			return fmt.Errorf("geko: invalid number literal %q", string(x))
+		}
+		e.buf = append(e.buf, binaryNumber)
+		e.string(string(x))
+	case float64:
+		e.float(x)
+	case float32:
+		e.float(float64(x))
+	case map[string]any:
+		members, _ := objectMembers(x)
+		return e.members(binaryObject, len(members), func(i int) (string, any) {
+			return members[i].Key, members[i].Value
+		})
+	case []any:
+		if x == nil {
+			e.buf = append(e.buf, binaryNull)
+			return nil
+		}
+		return e.items(x)
+	default:
+		return e.reflectValue(v)
+	}
+
+	return nil
+}
+
+func (e *binaryEncoder) reflectValue(v any) error {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.integer(rv.Int())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := rv.Uint(); n <= math.MaxInt64 {
+			e.integer(int64(n))
+			return nil
+		}
+	}
+
+	converted, err := fromReflectValue(rv)
+	if err != nil {
+		return err
+	}
+
+	return e.value(converted)
+}
+
+// isJSONNumber checks s is a valid JSON number literal.
+func isJSONNumber(s string) bool {
+	return s != "" && (s[0] == '-' || ('0' <= s[0] && s[0] <= '9')) && json.Valid([]byte(s))
+}
+
+// binaryMarshal encodes v in the binary format, with the version byte.
+func binaryMarshal(v binaryEncodable) ([]byte, error) {
+	e := binaryEncoder{buf: []byte{binaryVersion}}
+	if err := v.binaryEncode(&e); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+type binaryDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *binaryDecoder) error(msg string) error {
+	return &BinaryError{Offset: d.pos, Msg: msg}
+}
+
+func (d *binaryDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, d.error("unexpected end of data")
+	}
+
+	c := d.data[d.pos]
+	d.pos++
+	return c, nil
+}
+
+// length reads a length or count. Every byte, item or member takes at least
+// one byte, so it can't be larger than size of remaining data.
+func (d *binaryDecoder) length() (int, error) {
+	n, size := binary.Uvarint(d.data[d.pos:])
+	if size <= 0 {
+		return 0, d.error("invalid length")
+	}
+
+	if n > uint64(len(d.data)-d.pos-size) {
+		return 0, d.error("length out of range")
+	}
+
+	d.pos += size
+	return int(n), nil
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	n, err := d.length()
+	if err != nil {
+		return "", err
+	}
+
+	s := string(d.data[d.pos : d.pos+n])
+	d.pos += n
+	return s, nil
+}
+
+// header reads the version byte.
+func (d *binaryDecoder) header() error {
+	version, err := d.byte()
+	if err != nil {
+		return err
+	}
+
+	if version != binaryVersion {
+		d.pos--
+		return d.error("unsupported version")
+	}
+
+	return nil
+}
+
+// container reads tag and count of a container, which must be one of tags.
+func (d *binaryDecoder) container(tags ...byte) (byte, int, error) {
+	tag, err := d.byte()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, t := range tags {
+		if tag == t {
+			n, err := d.length()
+			return tag, n, err
+		}
+	}
+
+	d.pos--
+	return 0, 0, d.error("unexpected value type")
+}
+
+// end checks there is no trailing data.
+func (d *binaryDecoder) end() error {
+	if d.pos != len(d.data) {
+		return d.error("trailing data")
+	}
+	return nil
+}
+
+func (d *binaryDecoder) value() (any, error) {
+	start := d.pos
+
+	tag, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case binaryNull:
+		return nil, nil
+	case binaryFalse, binaryTrue:
+		return tag == binaryTrue, nil
+	case binaryInteger:
+		n, size := binary.Varint(d.data[d.pos:])
+		if size <= 0 {
+			return nil, d.error("invalid integer")
+		}
+		d.pos += size
+		return n, nil
+	case binaryFloat:
+		if len(d.data)-d.pos < 8 {
+			return nil, d.error("unexpected end of data")
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+		d.pos += 8
+		return f, nil
+	case binaryNumber:
+		s, err := d.string()
+		if err == nil && !isJSONNumber(s) {
+			d.pos = start
+			err = d.error("invalid number")
+		}
+		return json.Number(s), err
+	case binaryString:
+		return d.string()
+	case binaryArray, binaryObject, binaryObjectItems:
+		d.pos = start
+		return d.nested()
+	default:
+		d.pos = start
+		return nil, d.error("unknown value type")
+	}
+}
+
+func (d *binaryDecoder) nested() (any, error) {
+	if d.depth >= binaryMaxDepth {
+		return nil, d.error("exceeded max depth")
+	}
+
+	d.depth++
+	defer func() { d.depth-- }()
+
+	tag, n, err := d.container(binaryArray, binaryObject, binaryObjectItems)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case tag == binaryArray:
+		array := NewListWithCapacity[any](n)
+		return array, decodeBinaryItems[any](d, n, array)
+	case tag == binaryObject:
+		object := NewMapWithCapacity[string, any](n)
+		return object, decodeBinaryMembers[string, any](d, n, object)
+	default:
+		items := NewPairsWithCapacity[string, any](n)
+		return items, decodeBinaryMembers[string, any](d, n, items)
+	}
+}
+
+// decodeBinaryMembers decodes n members into object, keys and values are
+// converted like [ToStruct] does.
+func decodeBinaryMembers[K comparable, V any](d *binaryDecoder, n int, object memberAdder[K, V]) error {
+	for i := 0; i < n; i++ {
+		start := d.pos
+
+		key, err := d.string()
+		if err != nil {
+			return err
+		}
+
+		k, err := bindKey[K](key)
+		if err != nil {
+			d.pos = start
+			return d.error(err.Error())
+		}
+
+		v, err := decodeBinaryItem[V](d)
+		if err != nil {
+			return err
+		}
+
+		object.Add(k, v)
+	}
+
+	return nil
+}
+
+func decodeBinaryItems[T any](d *binaryDecoder, n int, l *List[T]) error {
+	for i := 0; i < n; i++ {
+		item, err := decodeBinaryItem[T](d)
+		if err != nil {
+			return err
+		}
+		l.Append(item)
+	}
+
+	return nil
+}
+
+func decodeBinaryItem[T any](d *binaryDecoder) (T, error) {
+	start := d.pos
+
+	value, err := d.value()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	item, err := bindValue[T](value)
+	if err != nil {
+		d.pos = start
+		return item, d.error(err.Error())
+	}
+
+	return item, nil
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] interface. It encodes
+// the map into a compact binary form, which is faster than JSON, and can be
+// decoded by [Map.UnmarshalBinary].
+//
+// The format starts with a version byte, which is 1 now, followed by the map
+// as a value. A value is a tag byte, followed by its content:
+//
+//   - 0x00 null, 0x01 false and 0x02 true, with no content.
+//   - 0x03 integer, zig-zag encoded varint, like [binary.PutVarint].
+//   - 0x04 float, 8 bytes IEEE 754 binary64 in little endian.
+//   - 0x05 number and 0x06 string, a string content.
+//   - 0x07 array, an uvarint count, then items as values.
+//   - 0x08 object and 0x09 object items, an uvarint count, then members as
+//     string content of key followed by a value.
+//
+// A string content is an uvarint byte length followed by the bytes, which
+// should be UTF-8. Integers are from Go integer types, and numbers are
+// json.Number. Keys of other types are formatted like [ToStdTypes] does, and
+// values of other types are converted like [FromStruct] does.
+//
+// An error is returned if a value can't be converted.
+func (m *Map[K, V]) MarshalBinary() ([]byte, error) {
+	return binaryMarshal(m)
+}
+
+func (m *Map[K, V]) binaryEncode(e *binaryEncoder) error {
+	if m == nil {
+		return e.value(nil)
+	}
+
+	return e.members(binaryObject, m.Len(), func(i int) (string, any) {
+		pair := m.GetByIndex(i)
+		return stdKey(pair.Key), pair.Value
+	})
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler] interface. The
+// input must be an object or object items made by [Map.MarshalBinary] or
+// [Pairs.MarshalBinary], its members are added in order, and duplicated keys
+// are dealt with the [DuplicatedKeyStrategy] of the map.
+//
+// If the value type is any, integers are int64, floats are float64, numbers
+// are json.Number, nested values keep their types, objects are [Object] and
+// object items are [ObjectItems]. Otherwise, values are decoded like that,
+// then assigned like [ToStruct] does. Keys are converted like map keys in
+// [ToStruct].
+//
+// A [*BinaryError] is returned for invalid data.
+func (m *Map[K, V]) UnmarshalBinary(data []byte) error {
+	if err := m.checkFrozen("UnmarshalBinary"); err != nil {
+		return err
+	}
+
+	d := binaryDecoder{data: data}
+
+	if err := d.header(); err != nil {
+		return err
+	}
+
+	_, n, err := d.container(binaryObject, binaryObjectItems)
+	if err != nil {
+		return err
+	}
+
+	if err = decodeBinaryMembers[K, V](&d, n, m); err != nil {
+		return err
+	}
+
+	return d.end()
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] interface. The pairs
+// are encoded as object items, duplicated keys are all kept. See
+// [Map.MarshalBinary] for the format.
+func (ps *Pairs[K, V]) MarshalBinary() ([]byte, error) {
+	return binaryMarshal(ps)
+}
+
+func (ps *Pairs[K, V]) binaryEncode(e *binaryEncoder) error {
+	if ps == nil {
+		return e.value(nil)
+	}
+
+	return e.members(binaryObjectItems, len(ps.List), func(i int) (string, any) {
+		return stdKey(ps.List[i].Key), ps.List[i].Value
+	})
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler] interface. The
+// input must be an object or object items, its members are added in order,
+// all values of duplicated keys are kept. See [Map.UnmarshalBinary] for how
+// values are decoded.
+func (ps *Pairs[K, V]) UnmarshalBinary(data []byte) error {
+	if err := ps.checkFrozen("UnmarshalBinary"); err != nil {
+		return err
+	}
+
+	d := binaryDecoder{data: data}
+
+	if err := d.header(); err != nil {
+		return err
+	}
+
+	_, n, err := d.container(binaryObject, binaryObjectItems)
+	if err != nil {
+		return err
+	}
+
+	if err = decodeBinaryMembers[K, V](&d, n, ps); err != nil {
+		return err
+	}
+
+	return d.end()
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] interface. The list is
+// encoded as an array, see [Map.MarshalBinary] for the format.
+func (l *List[T]) MarshalBinary() ([]byte, error) {
+	return binaryMarshal(l)
+}
+
+func (l *List[T]) binaryEncode(e *binaryEncoder) error {
+	if l == nil {
+		return e.value(nil)
+	}
+
+	e.buf = append(e.buf, binaryArray)
+	e.uvarint(uint64(len(l.List)))
+
+	for _, item := range l.List {
+		if err := e.value(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler] interface. The
+// input must be an array, see [Map.UnmarshalBinary] for how values are
+// decoded.
+//
+// The inner slice is replaced, unless [List.AppendOnUnmarshal] is enabled.
+func (l *List[T]) UnmarshalBinary(data []byte) error {
+	if err := l.checkFrozen("UnmarshalBinary"); err != nil {
+		return err
+	}
+
+	d := binaryDecoder{data: data}
+
+	if err := d.header(); err != nil {
+		return err
+	}
+
+	_, n, err := d.container(binaryArray)
+	if err != nil {
+		return err
+	}
+
+	items := NewListWithCapacity[T](n)
+	if err = decodeBinaryItems[T](&d, n, items); err != nil {
+		return err
+	}
+
+	if err = d.end(); err != nil {
+		return err
+	}
+
+	l.storeDecoded(items.List)
+
+	return nil
+}
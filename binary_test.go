@@ -0,0 +1,324 @@
+package geko_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const binaryTestData = `{"z": 1, "a": [1.5, "x", {"n": null, "m": true, "f": false}, []], "k": {"y": {}, "x": -2}}`
+
+func TestBinary_Format(t *testing.T) {
+	object := geko.NewMap[string, any]()
+	object.Set("a", -1)
+	object.Set("b", []any{nil, true, 0.5, json.Number("1e2")})
+	object.Set("c", geko.NewPairs[string, any]())
+
+	data, err := object.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary with error: %s", err.Error())
+	}
+
+	excepted := "01" + "0803" +
+		"0161" + "0301" +
+		"0162" + "0704" + "00" + "02" + "04000000000000e03f" + "0503316532" +
+		"0163" + "0900"
+	if hex.EncodeToString(data) != excepted {
+		t.Fatalf("MarshalBinary result not correct: %x", data)
+	}
+}
+
+func TestBinary_RoundTrip(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(binaryTestData), geko.UseObject())
+	object := v.(geko.Object)
+	object.Set("i", []any{int8(-3), uint(7), uint64(math.MaxUint64), float32(0.25), map[string]any{"s": "t"}})
+	object.Set("items", geko.NewPairsFrom([]geko.Pair[string, any]{{Key: "d", Value: 1}, {Key: "d", Value: 2}}))
+
+	data, err := object.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary with error: %s", err.Error())
+	}
+
+	decoded := geko.NewMap[string, any]()
+	if err = decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+	}
+
+	before, _ := json.Marshal(object)
+	after, _ := json.Marshal(decoded)
+	if string(before) != string(after) {
+		t.Fatalf("Binary round trip result not correct: %s", string(after))
+	}
+
+	i := decoded.GetOrZeroValue("i").(geko.Array)
+	if i.Get(0) != int64(-3) || i.Get(2) != json.Number("18446744073709551615") || i.Get(3) != 0.25 {
+		t.Fatalf("Binary round trip value types not correct: %#v", i.List)
+	}
+
+	if _, ok := decoded.GetOrZeroValue("items").(geko.ObjectItems); !ok {
+		t.Fatalf("Binary round trip should keep object items: %#v", decoded.GetOrZeroValue("items"))
+	}
+
+	items := geko.NewPairs[string, any]()
+	if err = items.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+	}
+
+	after, _ = json.Marshal(items)
+	if string(before) != string(after) {
+		t.Fatalf("Binary round trip result not correct: %s", string(after))
+	}
+
+	array := geko.NewListFrom([]any{object, nil, "s"})
+	data, _ = array.MarshalBinary()
+
+	decodedArray := geko.NewList[any]()
+	if err = decodedArray.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+	}
+
+	before, _ = json.Marshal(array)
+	after, _ = json.Marshal(decodedArray)
+	if string(before) != string(after) {
+		t.Fatalf("Binary round trip result not correct: %s", string(after))
+	}
+}
+
+func TestBinary_Typed(t *testing.T) {
+	m := geko.NewMap[int, string]()
+	m.Set(2, "b")
+	m.Set(1, "a")
+
+	data, _ := m.MarshalBinary()
+
+	decoded := geko.NewMap[int, string]()
+	decoded.SetDuplicatedKeyStrategy(geko.Ignore)
+	decoded.Set(1, "x")
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+	}
+
+	if decoded.GetKeyByIndex(1) != 2 || decoded.GetOrZeroValue(1) != "x" {
+		t.Fatalf("Typed map result not correct: %#v", decoded)
+	}
+
+	ps := geko.NewPairs[string, float64]()
+	items, _ := geko.NewPairsFrom([]geko.Pair[string, any]{
+		{Key: "a", Value: 1}, {Key: "a", Value: 2.5},
+	}).MarshalBinary()
+	if err := ps.UnmarshalBinary(items); err != nil {
+		t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+	}
+
+	if ps.Len() != 2 || ps.GetLastOrZeroValue("a") != 2.5 {
+		t.Fatalf("Typed pairs result not correct: %#v", ps)
+	}
+
+	l := geko.NewListFrom([]uint8{1, 2})
+	data, _ = l.MarshalBinary()
+
+	decodedList := geko.NewListFrom([]int{0})
+	decodedList.SetAppendOnUnmarshal(true)
+	if err := decodedList.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+	}
+
+	if len(decodedList.List) != 3 || decodedList.Get(2) != 2 {
+		t.Fatalf("Typed list result not correct: %#v", decodedList)
+	}
+
+	decodedList.SetAppendOnUnmarshal(false)
+	if err := decodedList.UnmarshalBinary(data); err != nil || len(decodedList.List) != 2 {
+		t.Fatalf("Typed list result not correct: %#v, %#v", decodedList, err)
+	}
+}
+
+func TestBinary_Nil(t *testing.T) {
+	var object geko.Object
+	var items geko.ObjectItems
+	var array geko.Array
+
+	for _, v := range []interface{ MarshalBinary() ([]byte, error) }{object, items, array} {
+		data, err := v.MarshalBinary()
+		if err != nil || hex.EncodeToString(data) != "0100" {
+			t.Fatalf("MarshalBinary of nil should be null: %x, %#v", data, err)
+		}
+	}
+
+	data, _ := geko.NewListFrom([]any{[]any(nil), object}).MarshalBinary()
+	if hex.EncodeToString(data) != "01070200"+"00" {
+		t.Fatalf("MarshalBinary of nil items not correct: %x", data)
+	}
+}
+
+func TestBinary_MarshalError(t *testing.T) {
+	for _, v := range []any{make(chan int), json.Number("x"), []any{make(chan int)}} {
+		m := geko.NewMap[string, any]()
+		m.Set("a", v)
+		if data, err := m.MarshalBinary(); err == nil {
+			t.Fatalf("MarshalBinary should fail: %x", data)
+		}
+
+		if data, err := geko.NewPairsFrom([]geko.Pair[string, any]{{Key: "a", Value: v}}).MarshalBinary(); err == nil {
+			t.Fatalf("MarshalBinary should fail: %x", data)
+		}
+
+		if data, err := geko.NewListFrom([]any{v}).MarshalBinary(); err == nil {
+			t.Fatalf("MarshalBinary should fail: %x", data)
+		}
+	}
+}
+
+func TestBinary_UnmarshalError(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		target interface{ UnmarshalBinary(data []byte) error }
+	}{
+		{"", geko.NewMap[string, any]()},
+		{"02", geko.NewMap[string, any]()},
+		{"01", geko.NewMap[string, any]()},
+		{"0107", geko.NewMap[string, any]()},
+		{"0108", geko.NewMap[string, any]()},
+		{"010880", geko.NewMap[string, any]()},
+		{"010801", geko.NewMap[string, any]()},
+		{"01080102", geko.NewMap[string, any]()},
+		{"0108010161", geko.NewMap[string, any]()},
+		{"01080101610a", geko.NewMap[string, any]()},
+		{"0108010161038080", geko.NewMap[string, any]()},
+		{"01080101610400", geko.NewMap[string, any]()},
+		{"010801016105017a", geko.NewMap[string, any]()},
+		{"01080101610601", geko.NewMap[string, any]()},
+		{"0108000000", geko.NewMap[string, any]()},
+		{"01080101610705", geko.NewMap[string, any]()},
+		{"0108010161060178", geko.NewMap[string, int]()},
+		{"01080101610300", geko.NewMap[int, int]()},
+		{"0107", geko.NewPairs[string, any]()},
+		{"01080101610a", geko.NewPairs[string, any]()},
+		{"0108000000", geko.NewPairs[string, any]()},
+		{"0108", geko.NewList[any]()},
+		{"010701060178", geko.NewList[int]()},
+		{"", geko.NewPairs[string, any]()},
+		{"", geko.NewList[any]()},
+		{"01070000", geko.NewList[any]()},
+		{"0107010a", geko.NewList[any]()},
+		{"010700ff", geko.NewList[any]()},
+	} {
+		data, _ := hex.DecodeString(c.data)
+		err := c.target.UnmarshalBinary(data)
+		if err == nil {
+			t.Fatalf("UnmarshalBinary %s should fail: %#v", c.data, c.target)
+		}
+
+		var binaryErr *geko.BinaryError
+		if !errors.As(err, &binaryErr) {
+			t.Fatalf("UnmarshalBinary should return BinaryError: %#v", err)
+		}
+	}
+}
+
+func TestBinary_MaxDepth(t *testing.T) {
+	data := []byte{1}
+	for i := 0; i < 20_000; i++ {
+		data = append(data, 7, 1)
+	}
+	data = append(data, 0)
+
+	err := geko.NewList[any]().UnmarshalBinary(data)
+	if err == nil || !strings.Contains(err.Error(), "exceeded max depth") {
+		t.Fatalf("UnmarshalBinary should fail on deep data: %#v", err)
+	}
+}
+
+func TestBinaryError(t *testing.T) {
+	err := geko.NewList[any]().UnmarshalBinary([]byte{1, 7, 1, 0x0a})
+	if err == nil || err.Error() != "geko: invalid binary data: unknown value type at offset 3" {
+		t.Fatalf("BinaryError message not correct: %#v", err)
+	}
+}
+
+func FuzzBinary_Unmarshal(f *testing.F) {
+	v, _ := geko.JSONUnmarshal([]byte(binaryTestData), geko.UseObject(), geko.UseNumber(true))
+	data, _ := v.(geko.Object).MarshalBinary()
+	f.Add(data)
+	f.Add([]byte{1, 7, 2, 3, 1, 4, 0, 0, 0, 0, 0, 0, 0, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		object := geko.NewMap[string, any]()
+		if object.UnmarshalBinary(data) != nil {
+			return
+		}
+
+		// valid data must round trip
+		output, err := object.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary with error: %s", err.Error())
+		}
+
+		decoded := geko.NewMap[string, any]()
+		if err = decoded.UnmarshalBinary(output); err != nil {
+			t.Fatalf("UnmarshalBinary with error: %s", err.Error())
+		}
+	})
+}
+
+func binaryBenchmarkData(b *testing.B) geko.Object {
+	var sb strings.Builder
+	_, _ = sb.WriteString(`{"users": [`)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			_, _ = sb.WriteString(",")
+		}
+		_, _ = sb.WriteString(`{"id": 12345, "name": "geko", "score": 98.5, "active": true, "tags": ["a", "b"]}`)
+	}
+	_, _ = sb.WriteString(`]}`)
+
+	v, err := geko.JSONUnmarshal([]byte(sb.String()), geko.UseObject())
+	if err != nil {
+		b.Fatalf("JSONUnmarshal with error: %s", err.Error())
+	}
+
+	return v.(geko.Object)
+}
+
+func BenchmarkBinary_Marshal(b *testing.B) {
+	object := binaryBenchmarkData(b)
+
+	b.Run("Binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = object.MarshalBinary()
+		}
+	})
+
+	b.Run("JSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = json.Marshal(object)
+		}
+	})
+}
+
+func BenchmarkBinary_Unmarshal(b *testing.B) {
+	object := binaryBenchmarkData(b)
+	binaryData, _ := object.MarshalBinary()
+	jsonData, _ := json.Marshal(object)
+
+	b.Run("Binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = geko.NewMap[string, any]().UnmarshalBinary(binaryData)
+		}
+	})
+
+	b.Run("JSON", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = geko.NewMap[string, any]().UnmarshalJSON(jsonData)
+		}
+	})
+}
@@ -0,0 +1,566 @@
+package geko
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BindOptions are options for controlling the behavior of [ToStruct].
+//
+// Default value (created by [CreateBindOptions]) of it is:
+//
+//   - String is parsed into time.Time with [time.RFC3339] layout.
+//   - Unknown members are allowed.
+//
+// See also: [CreateBindOptions], [TimeLayout], [DisallowUnknownFields].
+type BindOptions struct {
+	timeLayout            string
+	disallowUnknownFields bool
+}
+
+// BindOption is atom/modifier of [BindOptions].
+type BindOption func(opts *BindOptions)
+
+// CreateBindOptions creates a [BindOptions] by apply all option to the
+// default bind option.
+func CreateBindOptions(option ...BindOption) BindOptions {
+	opts := BindOptions{
+		timeLayout: time.RFC3339,
+	}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *BindOptions) Apply(option ...BindOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// TimeLayout specifies the layout used by [ToStruct] to parse a string into
+// time.Time, see [time.Parse] for detail.
+func TimeLayout(layout string) BindOption {
+	return func(opts *BindOptions) {
+		opts.timeLayout = layout
+	}
+}
+
+// DisallowUnknownFields makes [ToStruct] fail when an object member has no
+// matched struct field, like [json.Decoder.DisallowUnknownFields]. All such
+// members are collected, and reported by an [*UnknownFieldsError].
+//
+// Members stored into an extras field, see [UnmarshalStruct], are not
+// unknown. Members assigned to maps are not checked.
+func DisallowUnknownFields() BindOption {
+	return func(opts *BindOptions) {
+		opts.disallowUnknownFields = true
+	}
+}
+
+// ToStruct assigns obj, usually an [Object] decoded and modified before, into
+// target, which must be a non-nil pointer, usually to a struct. It's like
+// unmarshal obj into target, but without the marshal and unmarshal round
+// trip.
+//
+// Values are assigned like [json.Unmarshal] does:
+//
+//   - Objects, including [Object], [ObjectItems] and map[string]any, can be
+//     assigned to structs and maps. Members are matched to struct fields by
+//     json tags, like [FromStruct], an exact match is preferred over a
+//     case-insensitive one. Members without a matched field are stored into
+//     the extras field if exists, see [UnmarshalStruct], otherwise ignored.
+//   - Arrays, including [Array] and []any, can be assigned to slices and
+//     arrays. A base64 string, or a []byte, can be assigned to []byte.
+//   - Numbers, including float64, int32, int64 and json.Number, can be
+//     assigned to all numeric types, if it fits.
+//   - For fields with the "string" tag option, the value must be a string
+//     of the JSON encoding of a bool, number or string, like "5" for an int.
+//   - String can be assigned to time.Time, parsed with the layout set by
+//     [TimeLayout]. A time.Time value is assigned as is.
+//   - Values are marshaled and passed to [json.Unmarshaler], and strings are
+//     passed to [encoding.TextUnmarshaler], if target implements them.
+//   - Any value can be assigned to an interface, if it's assignable. Values
+//     are deep copied like [ApplyPatch] does.
+//   - Null sets pointers, interfaces, maps and slices to nil, and leaves other
+//     targets unchanged.
+//
+// Nil pointers are allocated when needed. Existing maps are reused, with new
+// members added.
+//
+// A [*BindError] is returned if a value can't be assigned, whose path is the
+// location of the value in obj. With [DisallowUnknownFields] option, an
+// [*UnknownFieldsError] is returned if there are unknown members, after all
+// values are assigned.
+func ToStruct(obj any, target any, option ...BindOption) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("geko: ToStruct into non-pointer or nil target %T", target)
+	}
+
+	b := binder{opts: CreateBindOptions(option...)}
+	if err := b.bind(make([]any, 0, 8), obj, rv.Elem()); err != nil {
+		return err
+	}
+
+	if len(b.unknown) > 0 {
+		return &UnknownFieldsError{Paths: b.unknown}
+	}
+
+	return nil
+}
+
+// UnmarshalStruct decodes JSON data into v, which must be a non-nil pointer,
+// usually to a struct. The data is decoded by [JSONUnmarshal] with [UseObject]
+// and [UseNumber] options, then assigned by [ToStruct].
+//
+// Unlike [json.Unmarshal], unknown members, which have no matched field, can
+// be kept: if the struct has an exported field of type [Object] tagged by
+// `geko:"extras"`, unknown members are stored into it, in order of
+// appearance. [MarshalStruct] and [FromStruct] emit them again, so they
+// survive an unmarshal, modify and marshal round trip:
+//
+//	type Config struct {
+//		Name   string      `json:"name"`
+//		Extras geko.Object `json:"-" geko:"extras"`
+//	}
+//
+// Extras fields in nested structs work too. An extras field can be promoted
+// from an embedded struct, the shallowest one is used.
+func UnmarshalStruct(data []byte, v any, option ...BindOption) error {
+	obj, err := JSONUnmarshal(data, UseObject(), UseNumber(true))
+	if err != nil {
+		return err
+	}
+
+	return ToStruct(obj, v, option...)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type binder struct {
+	opts BindOptions
+	// unknown are paths of unknown members, if they are disallowed.
+	unknown []string
+}
+
+// bindValue assigns a decoded value to a T, like [ToStruct] does.
+func bindValue[T any](value any) (T, error) {
+	var item T
+
+	if x, ok := value.(T); ok {
+		return x, nil
+	}
+
+	b := binder{opts: CreateBindOptions()}
+	return item, b.bind(nil, value, reflect.ValueOf(&item).Elem())
+}
+
+// bindKey converts a decoded object key to K, like [ToStruct] does for keys
+// of map.
+func bindKey[K comparable](key string) (K, error) {
+	if k, ok := any(key).(K); ok {
+		return k, nil
+	}
+
+	var k K
+	t := reflect.TypeOf(&k).Elem()
+
+	rk, err := mapKeyValue(t, key)
+	if err != nil {
+		return k, fmt.Errorf("invalid key %q for %s: %s", key, t, err.Error())
+	}
+
+	k, _ = rk.Interface().(K)
+	return k, nil
+}
+
+func (b *binder) error(path []any, t reflect.Type, value any, err error) error {
+	return &BindError{Path: formatPointer(path), Type: t, Value: value, Err: err}
+}
+
+func (b *binder) bind(path []any, value any, v reflect.Value) error {
+	if isNull(value) {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	if isUnmarshaled, err := b.bindUnmarshaler(path, value, v); isUnmarshaled {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return b.bind(path, value, v.Elem())
+	case reflect.Interface:
+		copied := cloneValue(value)
+		if !reflect.TypeOf(copied).AssignableTo(v.Type()) {
+			return b.error(path, v.Type(), value, nil)
+		}
+		v.Set(reflect.ValueOf(copied))
+		return nil
+	case reflect.Struct:
+		return b.bindStruct(path, value, v)
+	case reflect.Map:
+		return b.bindMap(path, value, v)
+	case reflect.Slice, reflect.Array:
+		return b.bindArray(path, value, v)
+	default:
+		isSet, err := setScalar(value, v)
+		if !isSet || err != nil {
+			return b.error(path, v.Type(), value, err)
+		}
+		return nil
+	}
+}
+
+func (b *binder) bindUnmarshaler(path []any, value any, v reflect.Value) (bool, error) {
+	if v.Type() == timeType {
+		if t, isTime := value.(time.Time); isTime {
+			v.Set(reflect.ValueOf(t))
+			return true, nil
+		}
+
+		s, isString := value.(string)
+		if !isString {
+			return true, b.error(path, v.Type(), value, nil)
+		}
+
+		t, err := time.Parse(b.opts.timeLayout, s)
+		if err != nil {
+			return true, b.error(path, v.Type(), value, err)
+		}
+
+		v.Set(reflect.ValueOf(t))
+		return true, nil
+	}
+
+	if v.Kind() == reflect.Pointer || !v.CanAddr() {
+		return false, nil
+	}
+
+	var err error
+
+	switch u := v.Addr().Interface().(type) {
+	case json.Unmarshaler:
+		var data []byte
+		if data, err = json.Marshal(value); err == nil {
+			err = u.UnmarshalJSON(data)
+		}
+	case encoding.TextUnmarshaler:
+		s, isString := value.(string)
+		if !isString {
+			return true, b.error(path, v.Type(), value, nil)
+		}
+		err = u.UnmarshalText([]byte(s))
+	default:
+		return false, nil
+	}
+
+	if err != nil {
+		return true, b.error(path, v.Type(), value, err)
+	}
+
+	return true, nil
+}
+
+func (b *binder) bindStruct(path []any, value any, v reflect.Value) error {
+	members, isObject := objectMembers(value)
+	if !isObject {
+		return b.error(path, v.Type(), value, nil)
+	}
+
+	info := cachedStructInfo(v.Type())
+
+	for _, pair := range members {
+		memberPath := append(path, pair.Key)
+
+		field := findStructField(info.fields, pair.Key)
+		if field == nil {
+			if err := b.bindExtra(memberPath, info, v, pair); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fv, err := settableFieldByIndex(v, field.index)
+		if err != nil {
+			return b.error(memberPath, v.Type(), pair.Value, err)
+		}
+
+		bind := b.bind
+		if field.quoted {
+			bind = b.bindQuoted
+		}
+
+		if err = bind(memberPath, pair.Value, fv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bindQuoted binds value into v of a field with the "string" tag option,
+// value must be a string of JSON encoding of a bool, number or string, or
+// null.
+func (b *binder) bindQuoted(path []any, value any, v reflect.Value) error {
+	if isNull(value) {
+		return b.bind(path, value, v)
+	}
+
+	s, isString := value.(string)
+	if !isString {
+		return b.error(path, v.Type(), value, errors.New("invalid use of ,string struct tag, value is not a string"))
+	}
+
+	literal, err := JSONUnmarshal([]byte(s), UseNumber(true))
+	if err != nil {
+		return b.error(path, v.Type(), value, err)
+	}
+
+	switch literal.(type) {
+	case nil, bool, json.Number, string:
+		return b.bind(path, literal, v)
+	default:
+		return b.error(path, v.Type(), value, errors.New("invalid use of ,string struct tag, value is not a literal"))
+	}
+}
+
+// bindExtra stores an unknown member into the extras field of struct v, if
+// it exists. Otherwise, records it if unknown members are disallowed.
+func (b *binder) bindExtra(path []any, info *structInfo, v reflect.Value, pair Pair[string, any]) error {
+	if info.extras == nil {
+		if b.opts.disallowUnknownFields {
+			b.unknown = append(b.unknown, formatPointer(path))
+		}
+		return nil
+	}
+
+	fv, err := settableFieldByIndex(v, info.extras)
+	if err != nil {
+		return b.error(path, v.Type(), pair.Value, err)
+	}
+
+	if fv.IsNil() {
+		fv.Set(reflect.ValueOf(NewMap[string, any]()))
+	}
+
+	extras, _ := fv.Interface().(Object)
+	extras.Set(pair.Key, cloneValue(pair.Value))
+
+	return nil
+}
+
+// findStructField finds the field for a member key, an exact match is
+// preferred over a case-insensitive one.
+func findStructField(fields []structField, key string) *structField {
+	var folded *structField
+
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+		if folded == nil && strings.EqualFold(fields[i].name, key) {
+			folded = &fields[i]
+		}
+	}
+
+	return folded
+}
+
+// settableFieldByIndex is like [reflect.Value.FieldByIndex], but allocates
+// nil embedded pointers.
+func settableFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	field := v
+
+	for i, x := range index {
+		if i > 0 && field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				if !field.CanSet() {
+					return field, errors.New("can't set embedded pointer to unexported struct")
+				}
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		field = field.Field(x)
+	}
+
+	return field, nil
+}
+
+func (b *binder) bindMap(path []any, value any, v reflect.Value) error {
+	members, isObject := objectMembers(value)
+	if !isObject {
+		return b.error(path, v.Type(), value, nil)
+	}
+
+	t := v.Type()
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(t, len(members)))
+	}
+
+	for _, pair := range members {
+		memberPath := append(path, pair.Key)
+
+		key, err := mapKeyValue(t.Key(), pair.Key)
+		if err != nil {
+			return b.error(memberPath, t.Key(), pair.Key, err)
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err = b.bind(memberPath, pair.Value, elem); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, elem)
+	}
+
+	return nil
+}
+
+func mapKeyValue(t reflect.Type, key string) (reflect.Value, error) {
+	k := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.String:
+		k.SetString(key)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, t.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, t.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetUint(n)
+	default:
+		u, isUnmarshaler := k.Addr().Interface().(encoding.TextUnmarshaler)
+		if !isUnmarshaler {
+			return k, &UnsupportedTypeError{Type: t}
+		}
+		if err := u.UnmarshalText([]byte(key)); err != nil {
+			return k, err
+		}
+	}
+
+	return k, nil
+}
+
+func (b *binder) bindArray(path []any, value any, v reflect.Value) error {
+	isBytes := v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+
+	if data, isData := value.([]byte); isData && isBytes {
+		v.SetBytes(append([]byte(nil), data...))
+		return nil
+	}
+
+	s, isString := value.(string)
+	if isString && isBytes {
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return b.error(path, v.Type(), value, err)
+		}
+		v.SetBytes(data)
+		return nil
+	}
+
+	items, isArray := arrayItems(value)
+	if !isArray {
+		return b.error(path, v.Type(), value, nil)
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if i >= len(items) {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			continue
+		}
+
+		if err := b.bind(append(path, i), items[i], v.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setScalar sets a bool, string or number value into v. Returns false if
+// types mismatch.
+func setScalar(value any, v reflect.Value) (bool, error) {
+	if x, isBool := value.(bool); isBool && v.Kind() == reflect.Bool {
+		v.SetBool(x)
+		return true, nil
+	}
+
+	if s, isString := value.(string); isString && v.Kind() == reflect.String {
+		v.SetString(s)
+		return true, nil
+	}
+
+	text, isNumber := numberText(value)
+	if !isNumber {
+		return false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err == nil {
+			v.SetInt(n)
+		}
+		return true, err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err == nil {
+			v.SetUint(n)
+		}
+		return true, err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err == nil {
+			v.SetFloat(f)
+		}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func numberText(v any) (string, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		return string(x), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case int32:
+		return strconv.FormatInt(int64(x), 10), true
+	default:
+		return "", false
+	}
+}
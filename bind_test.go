@@ -0,0 +1,363 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+type bindTestItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+	Count uint8   `json:"count"`
+}
+
+type BindTestMeta struct {
+	Version int `json:"version"`
+}
+
+type bindTestOrder struct {
+	*BindTestMeta
+	ID       int64             `json:"id"`
+	Customer *string           `json:"customer"`
+	Paid     bool              `json:"paid"`
+	Created  time.Time         `json:"created"`
+	Items    []bindTestItem    `json:"items"`
+	Labels   map[string]string `json:"labels"`
+	Extra    any               `json:"extra"`
+	Ignored  string            `json:"-"`
+}
+
+const bindTestData = `{
+	"version": 2,
+	"id": 9007199254740993,
+	"CUSTOMER": "alice",
+	"paid": true,
+	"created": "2024-01-02T03:04:05Z",
+	"items": [{"name": "apple", "price": 1.5, "count": 3}, {"name": "pen", "price": 2, "count": 1}],
+	"labels": {"b": "2", "a": "1"},
+	"extra": {"x": [1, 2]},
+	"unknown": 1
+}`
+
+func TestToStruct(t *testing.T) {
+	obj, _ := geko.JSONUnmarshal([]byte(bindTestData), geko.UseObject(), geko.UseNumber(true))
+
+	var order bindTestOrder
+	if err := geko.ToStruct(obj, &order); err != nil {
+		t.Fatalf("ToStruct with error: %s", err.Error())
+	}
+
+	var excepted bindTestOrder
+	_ = json.Unmarshal([]byte(bindTestData), &excepted)
+	excepted.Extra = order.Extra
+
+	if !reflect.DeepEqual(order, excepted) {
+		t.Fatalf("ToStruct result not correct: %#v", order)
+	}
+
+	output, _ := json.Marshal(order.Extra)
+	if _, isObject := order.Extra.(geko.Object); !isObject || string(output) != `{"x":[1,2]}` {
+		t.Fatalf("ToStruct result of interface not correct: %#v", order.Extra)
+	}
+
+	// value is copied
+	obj.(geko.Object).GetOrZeroValue("extra").(geko.Object).Set("y", 1)
+	if order.Extra.(geko.Object).Has("y") {
+		t.Fatalf("ToStruct should copy value into interface")
+	}
+}
+
+type bindTestText struct {
+	s string
+}
+
+func (b *bindTestText) UnmarshalText(text []byte) error {
+	if string(text) == "bad" {
+		return errors.New("bad text")
+	}
+	b.s = string(text)
+	return nil
+}
+
+type bindTestTypes struct {
+	Int8      int8
+	Uint      uint
+	Float32   float32
+	Bytes     []byte
+	Array     [3]int
+	Long      [1]int
+	Pointer   **int
+	Any       any
+	Null      []int
+	Kept      int
+	Time      time.Time
+	IP        net.IP
+	Raw       json.RawMessage
+	Text      bindTestText
+	IntMap    map[int8]bool
+	UintMap   map[uint]bool
+	TextMap   map[bindTestText]int
+	Existing  map[string]int
+	StdObject map[string]int
+	StdArray  []string
+}
+
+func TestToStruct_Types(t *testing.T) {
+	obj, _ := geko.JSONUnmarshal([]byte(`{
+		"Int8": -8, "Uint": 18446744073709551615, "Float32": 0.5,
+		"Bytes": "aGVsbG8=", "Array": [1, 2], "Long": [1, 2], "Pointer": 1, "Any": "s", "Null": null, "Kept": null,
+		"Time": "2024/01/02", "IP": "127.0.0.1", "Raw": {"b": 1, "a": [2]}, "Text": "t",
+		"IntMap": {"-1": true}, "UintMap": {"1": true}, "TextMap": {"k": 1}, "Existing": {"b": 2}
+	}`), geko.UseNumber(true))
+	obj.(geko.ObjectItems).Add("StdObject", map[string]any{"a": 1.0})
+	obj.(geko.ObjectItems).Add("StdArray", []any{"x"})
+
+	v := bindTestTypes{Array: [3]int{0, 0, 9}, Null: []int{1}, Kept: 1, Existing: map[string]int{"a": 1}}
+	if err := geko.ToStruct(obj, &v, geko.TimeLayout("2006/01/02")); err != nil {
+		t.Fatalf("ToStruct with error: %s", err.Error())
+	}
+
+	if v.Int8 != -8 || v.Uint != 18446744073709551615 || v.Float32 != 0.5 || string(v.Bytes) != "hello" ||
+		v.Array != [3]int{1, 2, 0} || v.Long != [1]int{1} || **v.Pointer != 1 || v.Any != "s" ||
+		v.Null != nil || v.Kept != 1 ||
+		!v.Time.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) || v.IP.String() != "127.0.0.1" ||
+		string(v.Raw) != `{"b":1,"a":[2]}` || v.Text.s != "t" ||
+		!reflect.DeepEqual(v.IntMap, map[int8]bool{-1: true}) ||
+		!reflect.DeepEqual(v.UintMap, map[uint]bool{1: true}) ||
+		!reflect.DeepEqual(v.TextMap, map[bindTestText]int{{"k"}: 1}) ||
+		!reflect.DeepEqual(v.Existing, map[string]int{"a": 1, "b": 2}) ||
+		!reflect.DeepEqual(v.StdObject, map[string]int{"a": 1}) ||
+		!reflect.DeepEqual(v.StdArray, []string{"x"}) {
+		t.Fatalf("ToStruct result not correct: %#v", v)
+	}
+
+	// non-struct target and int64 number
+	var m map[string][]int
+	if err := geko.ToStruct(map[string]any{"a": []any{int64(1)}}, &m); err != nil || m["a"][0] != 1 {
+		t.Fatalf("ToStruct result not correct: %#v, %#v", m, err)
+	}
+
+	// time.Time and []byte values, like decoded from CBOR
+	now := time.Now()
+	values := map[string]any{"Time": now, "Bytes": []byte("hello")}
+	v = bindTestTypes{}
+	if err := geko.ToStruct(values, &v); err != nil || !v.Time.Equal(now) || string(v.Bytes) != "hello" {
+		t.Fatalf("ToStruct result not correct: %#v, %#v", v, err)
+	}
+}
+
+type bindTestUnexported struct {
+	Field int
+}
+
+func TestToStruct_Error(t *testing.T) {
+	type target struct {
+		A struct {
+			B []struct {
+				C int `json:"c"`
+			}
+		}
+		N    int8
+		M    map[string]bool
+		I    fmt.Stringer
+		F    func()
+		K    map[[2]int]bool
+		IK   map[int]bool
+		UK   map[uint]bool
+		TK   map[bindTestText]bool
+		T    time.Time
+		X    bindTestText
+		R    json.RawMessage
+		S    string
+		U    uint
+		Arr  []int
+		Byte []byte
+		Q    int `json:"q,string"`
+		*bindTestUnexported
+	}
+
+	for _, tc := range []struct {
+		data     string
+		excepted string
+	}{
+		{`{"A": {"B": [{"c": 1}, {"c": "1"}]}}`, `geko: bind "/A/B/1/c" into int: wrong type string`},
+		{`{"N": 300}`, `geko: bind "/N" into int8: strconv.ParseInt: parsing "300": value out of range`},
+		{`{"N": 1.5}`, `geko: bind "/N" into int8: strconv.ParseInt: parsing "1.5": invalid syntax`},
+		{`{"U": -1}`, `geko: bind "/U" into uint: strconv.ParseUint: parsing "-1": invalid syntax`},
+		{`{"M": {"a/b": 1}}`, `geko: bind "/M/a~1b" into bool: wrong type float64`},
+		{`{"M": []}`, `geko: bind "/M" into map[string]bool: wrong type *geko.List[interface {}]`},
+		{`{"A": 1}`, `geko: bind "/A" into struct { B []struct { C int "json:\"c\"" } }: wrong type float64`},
+		{`{"I": 1}`, `geko: bind "/I" into fmt.Stringer: wrong type float64`},
+		{`{"F": 1}`, `geko: bind "/F" into func(): wrong type float64`},
+		{`{"K": {"a": true}}`, `geko: bind "/K/a" into [2]int: geko: unsupported type [2]int`},
+		{`{"IK": {"a": true}}`, `geko: bind "/IK/a" into int: strconv.ParseInt: parsing "a": invalid syntax`},
+		{`{"UK": {"-1": true}}`, `geko: bind "/UK/-1" into uint: strconv.ParseUint: parsing "-1": invalid syntax`},
+		{`{"TK": {"bad": true}}`, `geko: bind "/TK/bad" into geko_test.bindTestText: bad text`},
+		{`{"T": 1}`, `geko: bind "/T" into time.Time: wrong type float64`},
+		{`{"T": "x"}`, `geko: bind "/T" into time.Time: parsing time "x" as "2006-01-02T15:04:05Z07:00": ` +
+			`cannot parse "x" as "2006"`},
+		{`{"X": 1}`, `geko: bind "/X" into geko_test.bindTestText: wrong type float64`},
+		{`{"X": "bad"}`, `geko: bind "/X" into geko_test.bindTestText: bad text`},
+		{`{"S": true}`, `geko: bind "/S" into string: wrong type bool`},
+		{`{"Arr": {}}`, `geko: bind "/Arr" into []int: wrong type *geko.Pairs[string,interface {}]`},
+		{`{"Byte": "!"}`, `geko: bind "/Byte" into []uint8: illegal base64 data at input byte 0`},
+		{`{"q": 1}`, `geko: bind "/q" into int: invalid use of ,string struct tag, value is not a string`},
+		{`{"q": "[1]"}`, `geko: bind "/q" into int: invalid use of ,string struct tag, value is not a literal`},
+		{`{"q": "\"1\""}`, `geko: bind "/q" into int: wrong type string`},
+		{`{"q": "x"}`, `geko: bind "/q" into int: invalid character 'x' looking for beginning of value`},
+		{`{"Field": 1}`, `geko: bind "/Field" into geko_test.target: can't set embedded pointer to unexported struct`},
+	} {
+		obj, _ := geko.JSONUnmarshal([]byte(tc.data))
+
+		var v target
+		err := geko.ToStruct(obj, &v)
+
+		var bindError *geko.BindError
+		if !errors.As(err, &bindError) || err.Error() != tc.excepted {
+			t.Fatalf("ToStruct of %s error not correct: %#v", tc.data, err)
+		}
+	}
+
+	// error of json.Unmarshaler
+	var v struct{ R json.RawMessage }
+	err := geko.ToStruct(map[string]any{"R": func() {}}, &v)
+	var unsupported *json.UnsupportedTypeError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("ToStruct should fail on json.Unmarshaler error: %#v", err)
+	}
+
+	for _, target := range []any{nil, 1, (*int)(nil)} {
+		if err := geko.ToStruct(1, target); err == nil {
+			t.Fatalf("ToStruct into %#v should fail", target)
+		}
+	}
+}
+
+type bindTestQuoted struct {
+	Int     int64   `json:"int,string"`
+	Float   float32 `json:"float,string"`
+	Bool    bool    `json:"bool,string"`
+	String  string  `json:"string,string"`
+	Pointer *uint   `json:"pointer,string"`
+	Null    *int    `json:"null,string"`
+	Slice   []int   `json:"slice,string"`
+}
+
+func TestToStruct_Quoted(t *testing.T) {
+	data := `{"int": "-5", "float": "1.5", "bool": "true", "string": "\"a\"", "pointer": "5", "null": null, ` +
+		`"slice": [1]}`
+
+	var v bindTestQuoted
+	if err := geko.UnmarshalStruct([]byte(data), &v); err != nil {
+		t.Fatalf("UnmarshalStruct with error: %s", err.Error())
+	}
+
+	var excepted bindTestQuoted
+	if err := json.Unmarshal([]byte(data), &excepted); err != nil {
+		t.Fatalf("json.Unmarshal with error: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(v, excepted) {
+		t.Fatalf("UnmarshalStruct result not same as json.Unmarshal: %#v, %#v", v, excepted)
+	}
+
+	output, _ := geko.MarshalStruct(&v)
+	if exceptedOutput, _ := json.Marshal(&v); string(output) != string(exceptedOutput) {
+		t.Fatalf("MarshalStruct result not same as json.Marshal: %s", string(output))
+	}
+}
+
+func BenchmarkToStruct(b *testing.B) {
+	obj, _ := geko.JSONUnmarshal([]byte(bindTestData), geko.UseObject(), geko.UseNumber(true))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var order bindTestOrder
+		_ = geko.ToStruct(obj, &order)
+	}
+}
+
+func BenchmarkToStruct_Remarshal(b *testing.B) {
+	obj, _ := geko.JSONUnmarshal([]byte(bindTestData), geko.UseObject(), geko.UseNumber(true))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var order bindTestOrder
+		data, _ := json.Marshal(obj)
+		_ = json.Unmarshal(data, &order)
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	type target struct {
+		T      time.Time
+		Extras geko.Object `geko:"extras"`
+	}
+
+	var v target
+	err := geko.UnmarshalStruct([]byte(`{"T": "2024/01/02", "x": 1}`), &v, geko.TimeLayout("2006/01/02"))
+	if err != nil || v.T.Year() != 2024 || v.Extras.GetOrZeroValue("x") != json.Number("1") {
+		t.Fatalf("UnmarshalStruct result not correct: %#v, %#v", v, err)
+	}
+
+	if err = geko.UnmarshalStruct([]byte(`{`), &v); err == nil {
+		t.Fatalf("UnmarshalStruct of invalid JSON should fail")
+	}
+
+	type unexported struct {
+		Extras geko.Object `geko:"extras"`
+	}
+	var u struct{ *unexported }
+	if err = geko.UnmarshalStruct([]byte(`{"x": 1}`), &u); err == nil {
+		t.Fatalf("UnmarshalStruct into unexported embedded pointer should fail")
+	}
+}
+
+func TestToStruct_DisallowUnknownFields(t *testing.T) {
+	obj, _ := geko.JSONUnmarshal([]byte(bindTestData), geko.UseObject(), geko.UseNumber(true))
+	obj.(geko.Object).GetOrZeroValue("items").(geko.Array).Get(1).(geko.Object).Set("sku/id", 1)
+
+	// version is promoted from embedded struct, it's not unknown
+	var order bindTestOrder
+	err := geko.ToStruct(obj, &order, geko.DisallowUnknownFields())
+
+	var unknownError *geko.UnknownFieldsError
+	excepted := []string{"/items/1/sku~1id", "/unknown"}
+	if !errors.As(err, &unknownError) || !reflect.DeepEqual(unknownError.Paths, excepted) {
+		t.Fatalf("ToStruct error not correct: %#v", err)
+	}
+
+	if err.Error() != `geko: unknown fields "/items/1/sku~1id", "/unknown"` {
+		t.Fatalf("UnknownFieldsError message not correct: %s", err.Error())
+	}
+
+	// values are still assigned
+	if order.Version != 2 || order.Items[1].Name != "pen" {
+		t.Fatalf("ToStruct result not correct: %#v", order)
+	}
+
+	// members of map and extras are not unknown
+	type target struct {
+		M      map[string]int
+		Extras geko.Object `geko:"extras"`
+	}
+
+	var v target
+	err = geko.UnmarshalStruct([]byte(`{"M": {"a": 1}, "x": 1}`), &v, geko.DisallowUnknownFields())
+	if err != nil || v.Extras.Len() != 1 {
+		t.Fatalf("ToStruct result not correct: %#v, %#v", v, err)
+	}
+
+	var m BindTestMeta
+	err = geko.UnmarshalStruct([]byte(`{"version": 1, "Version": 2, "x": 1}`), &m, geko.DisallowUnknownFields())
+	if !errors.As(err, &unknownError) || !reflect.DeepEqual(unknownError.Paths, []string{"/x"}) || m.Version != 2 {
+		t.Fatalf("ToStruct error not correct: %#v", err)
+	}
+}
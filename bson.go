@@ -0,0 +1,708 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BSON element types.
+const (
+	bsonDouble        byte = 0x01
+	bsonString        byte = 0x02
+	bsonDocument      byte = 0x03
+	bsonArray         byte = 0x04
+	bsonBinary        byte = 0x05
+	bsonUndefined     byte = 0x06
+	bsonObjectID      byte = 0x07
+	bsonBool          byte = 0x08
+	bsonDateTime      byte = 0x09
+	bsonNull          byte = 0x0a
+	bsonRegex         byte = 0x0b
+	bsonDBPointer     byte = 0x0c
+	bsonJavaScript    byte = 0x0d
+	bsonSymbol        byte = 0x0e
+	bsonCodeWithScope byte = 0x0f
+	bsonInt32         byte = 0x10
+	bsonTimestamp     byte = 0x11
+	bsonInt64         byte = 0x12
+	bsonDecimal128    byte = 0x13
+	bsonMaxKey        byte = 0x7f
+	bsonMinKey        byte = 0xff
+)
+
+// BSONObjectID is a BSON ObjectId, the 12 bytes identifier of MongoDB
+// documents.
+type BSONObjectID [12]byte
+
+// String returns the hex encoding of the id.
+func (id BSONObjectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, so it's a hex
+// string in JSON.
+func (id BSONObjectID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// BSONRawValue is a BSON value whose type has no natural Go representation,
+// like regular expression, timestamp, JavaScript code, min/max key, binary
+// data of non-generic subtype and deprecated types. It's kept as is, so it can
+// be encoded back.
+type BSONRawValue struct {
+	// Type is the element type, like 0x0B for regular expression.
+	Type byte
+	// Data is the value bytes of the element, without type and key.
+	Data []byte
+}
+
+// BSONMarshal encodes v into a BSON document, v must be an object, which can
+// be [Map], [Pairs], map[string]any or a struct. Members are written in their
+// order, members of a map[string]any are sorted:
+//
+//   - [Map] and [Pairs] are documents, keys of other types are formatted like
+//     [ToStdTypes] does. [List] and []any are arrays.
+//   - Go integers are int32 if they fit, except int64 and uint64, which are
+//     always int64. Integer json.Number are the same as int, or decimal128 if
+//     out of int64 range. Other numbers are double.
+//   - time.Time is UTC datetime, in milliseconds. []byte is binary data of
+//     generic subtype. [BSONObjectID], [BSONDecimal128] and [BSONRawValue] are
+//     written as is.
+//   - Other values are converted like [FromStruct] does, so structs are
+//     documents.
+//
+// An error is returned for keys contain null byte, and values can't be
+// encoded.
+func BSONMarshal(v any) ([]byte, error) {
+	e := bsonEncoder{}
+
+	t, err := e.value(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if t != bsonDocument {
+		return nil, fmt.Errorf("geko: bson document must be an object, got %T", v)
+	}
+
+	return e.buf, nil
+}
+
+// BSONUnmarshal decodes a BSON document into [Object] or [ObjectItems], like
+// [JSONUnmarshal]:
+//
+//   - Embedded documents are [Object] or [ObjectItems] too, arrays are
+//     [Array].
+//   - Numbers keep their types: double is float64, int32 is int32, int64 is
+//     int64, and decimal128 is [BSONDecimal128].
+//   - UTC datetime is time.Time, binary data of generic subtype is []byte,
+//     ObjectId is [BSONObjectID], and null is nil. Values of other types are
+//     [BSONRawValue].
+//
+// Supported options are [UseObject], [UseObjectItems],
+// [ObjectOnDuplicatedKey], [RecordDuplicates] and [MaxDepth], others are
+// ignored. A [*BSONError] is returned for invalid or trailing data. The root
+// document has depth 1, nesting deeper than MaxDepth, or 10000 levels anyway,
+// fails with a [LimitExceededError].
+func BSONUnmarshal(data []byte, option ...DecodeOption) (any, error) {
+	d := bsonDecoder{data: data, opts: CreateDecodeOptions(option...)}
+
+	end, err := d.root()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.object(0, end)
+}
+
+// bsonEncodable is implemented by types in this package, to encode themselves
+// into BSON directly, like [jsonEncodable] does for JSON. It returns the
+// element type.
+type bsonEncodable interface {
+	bsonValue(e *bsonEncoder) (byte, error)
+}
+
+type bsonEncoder struct {
+	buf []byte
+}
+
+func (e *bsonEncoder) int32(n int32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(n))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *bsonEncoder) int64(n int64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(n))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *bsonEncoder) string(s string) {
+	e.int32(int32(len(s) + 1))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// document writes a document of n elements, element i is get by member.
+func (e *bsonEncoder) document(n int, member func(i int) (string, any)) error {
+	start := len(e.buf)
+	e.int32(0)
+
+	for i := 0; i < n; i++ {
+		key, value := member(i)
+		if err := e.element(key, value); err != nil {
+			return err
+		}
+	}
+
+	e.buf = append(e.buf, 0)
+	binary.LittleEndian.PutUint32(e.buf[start:], uint32(len(e.buf)-start))
+
+	return nil
+}
+
+func (e *bsonEncoder) members(members []Pair[string, any]) error {
+	return e.document(len(members), func(i int) (string, any) {
+		return members[i].Key, members[i].Value
+	})
+}
+
+func (e *bsonEncoder) array(items []any) error {
+	return e.document(len(items), func(i int) (string, any) {
+		return strconv.Itoa(i), items[i]
+	})
+}
+
+func (e *bsonEncoder) element(key string, value any) error {
+	if strings.IndexByte(key, 0) >= 0 {
+		return fmt.Errorf("geko: bson key %q contains null byte", key)
+	}
+
+	typeIndex := len(e.buf)
+	e.buf = append(e.buf, 0)
+	e.buf = append(e.buf, key...)
+	e.buf = append(e.buf, 0)
+
+	t, err := e.value(value)
+	e.buf[typeIndex] = t
+	return err
+}
+
+func (e *bsonEncoder) value(v any) (byte, error) {
+	switch x := v.(type) {
+	case bsonEncodable:
+		return x.bsonValue(e)
+	case nil:
+		return bsonNull, nil
+	case bool:
+		if x {
+			e.buf = append(e.buf, 1)
+		} else {
+			e.buf = append(e.buf, 0)
+		}
+		return bsonBool, nil
+	case string:
+		e.string(x)
+		return bsonString, nil
+	case float64:
+		e.int64(int64(math.Float64bits(x)))
+		return bsonDouble, nil
+	case float32:
+		return e.value(float64(x))
+	case json.Number:
+		return e.number(x)
+	case time.Time:
+		e.int64(x.UnixMilli())
+		return bsonDateTime, nil
+	case []byte:
+		e.int32(int32(len(x)))
+		e.buf = append(e.buf, 0)
+		e.buf = append(e.buf, x...)
+		return bsonBinary, nil
+	case BSONObjectID:
+		e.buf = append(e.buf, x[:]...)
+		return bsonObjectID, nil
+	case BSONDecimal128:
+		e.int64(int64(x.Low))
+		e.int64(int64(x.High))
+		return bsonDecimal128, nil
+	case BSONRawValue:
+		e.buf = append(e.buf, x.Data...)
+		return x.Type, nil
+	case map[string]any:
+		members, _ := objectMembers(x)
+		return bsonDocument, e.members(members)
+	case []any:
+		return bsonArray, e.array(x)
+	default:
+		return e.reflectValue(v)
+	}
+}
+
+func (e *bsonEncoder) int(n int64, kind reflect.Kind) byte {
+	if kind != reflect.Int64 && n >= math.MinInt32 && n <= math.MaxInt32 {
+		e.int32(int32(n))
+		return bsonInt32
+	}
+
+	e.int64(n)
+	return bsonInt64
+}
+
+func (e *bsonEncoder) number(n json.Number) (byte, error) {
+	s := string(n)
+
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return e.int(i, reflect.Int), nil
+		}
+
+		if d, err := ParseBSONDecimal128(s); err == nil {
+			return e.value(d)
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("geko: invalid number literal %q", s)
+	}
+
+	return e.value(f)
+}
+
+func (e *bsonEncoder) reflectValue(v any) (byte, error) {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.int(rv.Int(), rv.Kind()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		if n > math.MaxInt64 {
+			return 0, fmt.Errorf("geko: integer %d overflows bson int64", n)
+		}
+		kind := reflect.Int
+		if rv.Kind() == reflect.Uint64 {
+			kind = reflect.Int64
+		}
+		return e.int(int64(n), kind), nil
+	default:
+		converted, err := fromReflectValue(rv)
+		if err != nil {
+			return 0, err
+		}
+		return e.value(converted)
+	}
+}
+
+type bsonDecoder struct {
+	data []byte
+	opts DecodeOptions
+	// depth is the nesting depth of documents and arrays being decoded.
+	depth int
+}
+
+func (d *bsonDecoder) error(offset int, format string, args ...any) error {
+	return &BSONError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// root checks the top level document, returns its end offset.
+func (d *bsonDecoder) root() (int, error) {
+	d.depth = 1
+
+	end, err := d.document(0, len(d.data))
+	if err == nil && end != len(d.data) {
+		err = d.error(end, "trailing data")
+	}
+	return end, err
+}
+
+// document checks the document at offset, which must end before limit, and
+// returns its end offset.
+func (d *bsonDecoder) document(offset, limit int) (int, error) {
+	if offset+5 > limit {
+		return 0, d.error(offset, "unexpected end of data")
+	}
+
+	size := int(int32(binary.LittleEndian.Uint32(d.data[offset:])))
+	if size < 5 || size > limit-offset {
+		return 0, d.error(offset, "invalid document size %d", size)
+	}
+
+	end := offset + size
+	if d.data[end-1] != 0 {
+		return 0, d.error(end-1, "missing document terminator")
+	}
+
+	return end, nil
+}
+
+// elements calls f with each element in the document at [offset, end).
+func (d *bsonDecoder) elements(offset, end int, f func(offset int, key string, value any) error) error {
+	for i := offset + 4; i < end-1; {
+		keyEnd := bytes.IndexByte(d.data[i+1:end-1], 0)
+		if keyEnd < 0 {
+			return d.error(i+1, "unterminated key")
+		}
+
+		key := string(d.data[i+1 : i+1+keyEnd])
+
+		value, next, err := d.value(d.data[i], i+2+keyEnd, end-1)
+		if err != nil {
+			return err
+		}
+
+		if err = f(i, key, value); err != nil {
+			return err
+		}
+
+		i = next
+	}
+
+	return nil
+}
+
+// size checks value of n bytes at offset ends before limit.
+func (d *bsonDecoder) size(t byte, offset, n, limit int) (int, error) {
+	if n < 0 || n > limit-offset {
+		return 0, d.error(offset, "unexpected end of element type 0x%02x", t)
+	}
+	return offset + n, nil
+}
+
+// value decodes value of type t at offset, which must end before limit, and
+// returns it with the end offset.
+func (d *bsonDecoder) value(t byte, offset, limit int) (any, int, error) {
+	if t == bsonDocument || t == bsonArray {
+		end, err := d.document(offset, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if limit := d.opts.formatDepthLimit(); d.depth >= limit {
+			return nil, 0, &LimitExceededError{Kind: DepthLimit, Limit: int64(limit), Offset: int64(offset)}
+		}
+		d.depth++
+		defer func() { d.depth-- }()
+
+		var v any
+		if t == bsonDocument {
+			v, err = d.object(offset, end)
+		} else {
+			v, err = d.array(offset, end)
+		}
+		return v, end, err
+	}
+
+	n, err := d.valueSize(t, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	end, err := d.size(t, offset, n, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data := d.data[offset:end]
+
+	switch t {
+	case bsonDouble:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data)), end, nil
+	case bsonString:
+		if data[n-1] != 0 {
+			return nil, 0, d.error(offset, "unterminated string")
+		}
+		return string(data[4 : n-1]), end, nil
+	case bsonBinary:
+		if data[4] != 0 {
+			break
+		}
+		return append([]byte{}, data[5:]...), end, nil
+	case bsonObjectID:
+		var id BSONObjectID
+		copy(id[:], data)
+		return id, end, nil
+	case bsonBool:
+		return data[0] != 0, end, nil
+	case bsonDateTime:
+		return time.UnixMilli(int64(binary.LittleEndian.Uint64(data))).UTC(), end, nil
+	case bsonNull:
+		return nil, end, nil
+	case bsonInt32:
+		return int32(binary.LittleEndian.Uint32(data)), end, nil
+	case bsonInt64:
+		return int64(binary.LittleEndian.Uint64(data)), end, nil
+	case bsonDecimal128:
+		return BSONDecimal128{
+			Low:  binary.LittleEndian.Uint64(data),
+			High: binary.LittleEndian.Uint64(data[8:]),
+		}, end, nil
+	}
+
+	return BSONRawValue{Type: t, Data: append([]byte{}, data...)}, end, nil
+}
+
+// valueSize returns byte size of value of type t at offset.
+func (d *bsonDecoder) valueSize(t byte, offset, limit int) (int, error) {
+	// prefixed reads the int32 size prefix, which must be at least minSize
+	prefixed := func(extra, minSize int) (int, error) {
+		if _, err := d.size(t, offset, 4, limit); err != nil {
+			return 0, err
+		}
+
+		size := int(int32(binary.LittleEndian.Uint32(d.data[offset:])))
+		if size < minSize {
+			return 0, d.error(offset, "invalid size %d of element type 0x%02x", size, t)
+		}
+
+		return size + extra, nil
+	}
+
+	switch t {
+	case bsonNull, bsonUndefined, bsonMinKey, bsonMaxKey:
+		return 0, nil
+	case bsonBool:
+		return 1, nil
+	case bsonInt32:
+		return 4, nil
+	case bsonDouble, bsonDateTime, bsonInt64, bsonTimestamp:
+		return 8, nil
+	case bsonObjectID:
+		return 12, nil
+	case bsonDecimal128:
+		return 16, nil
+	case bsonString, bsonJavaScript, bsonSymbol:
+		// size of string includes the terminator
+		return prefixed(4, 1)
+	case bsonBinary:
+		return prefixed(5, 0)
+	case bsonDBPointer:
+		return prefixed(4+12, 1)
+	case bsonCodeWithScope:
+		// size of itself, a string and an empty document at least
+		return prefixed(0, 4+5+5)
+	case bsonRegex:
+		// pattern and options, both are cstring
+		pattern := bytes.IndexByte(d.data[offset:limit], 0)
+		if pattern >= 0 {
+			if options := bytes.IndexByte(d.data[offset+pattern+1:limit], 0); options >= 0 {
+				return pattern + options + 2, nil
+			}
+		}
+		return 0, d.error(offset, "unterminated regular expression")
+	default:
+		return 0, d.error(offset, "unknown element type 0x%02x", t)
+	}
+}
+
+func (d *bsonDecoder) object(offset, end int) (any, error) {
+	if d.opts.useObject {
+		object := NewMap[string, any]()
+		object.SetDuplicatedKeyStrategy(d.opts.duplicatedKeyStrategy)
+		object.SetRecordDuplicates(d.opts.recordDuplicates)
+		return object, decodeBSONMembers[string, any](d, offset, end, object)
+	}
+
+	items := NewPairs[string, any]()
+	return items, decodeBSONMembers[string, any](d, offset, end, items)
+}
+
+func (d *bsonDecoder) array(offset, end int) (Array, error) {
+	array := NewList[any]()
+	return array, d.elements(offset, end, func(_ int, _ string, value any) error {
+		array.Append(value)
+		return nil
+	})
+}
+
+// decodeBSONMembers decodes elements in document at [offset, end) into object.
+// Keys are converted into K like [ToStruct] does for map keys, values are
+// assigned to V by [ToStruct].
+func decodeBSONMembers[K comparable, V any](d *bsonDecoder, offset, end int, object memberAdder[K, V]) error {
+	return d.elements(offset, end, func(elementOffset int, key string, value any) error {
+		k, err := bindKey[K](key)
+		if err != nil {
+			return d.error(elementOffset, "%s", err.Error())
+		}
+
+		v, err := bindValue[V](value)
+		if err != nil {
+			return err
+		}
+
+		object.Add(k, v)
+		return nil
+	})
+}
+
+// MarshalBSON implements Marshaler interface of [go.mongodb.org/mongo-driver],
+// see [BSONMarshal] for how members are encoded. The map is encoded as a BSON
+// document, in the insertion order.
+//
+// [go.mongodb.org/mongo-driver]: https://pkg.go.dev/go.mongodb.org/mongo-driver/bson
+func (m *Map[K, V]) MarshalBSON() ([]byte, error) {
+	return BSONMarshal(m)
+}
+
+func (m *Map[K, V]) bsonValue(e *bsonEncoder) (byte, error) {
+	if m == nil {
+		return bsonNull, nil
+	}
+
+	return bsonDocument, e.document(m.Len(), func(i int) (string, any) {
+		pair := m.GetByIndex(i)
+		return stdKey(pair.Key), pair.Value
+	})
+}
+
+// UnmarshalBSON implements Unmarshaler interface of
+// [go.mongodb.org/mongo-driver]. The input must be a BSON document, its
+// members are added in order, and duplicated keys are dealt with the
+// [DuplicatedKeyStrategy] of the map.
+//
+// If the value type is any, embedded documents and arrays are decoded into
+// [Object] and [Array], see [BSONUnmarshal] for other values. Otherwise,
+// values are decoded like that, then assigned like [ToStruct] does. Keys are
+// converted like map keys in [ToStruct].
+//
+// [go.mongodb.org/mongo-driver]: https://pkg.go.dev/go.mongodb.org/mongo-driver/bson
+func (m *Map[K, V]) UnmarshalBSON(data []byte) error {
+	if err := m.checkFrozen("UnmarshalBSON"); err != nil {
+		return err
+	}
+
+	d := bsonDecoder{data: data, opts: CreateDecodeOptions(
+		UseObject(),
+		ObjectOnDuplicatedKey(m.duplicatedKeyStrategy),
+		RecordDuplicates(m.RecordDuplicates()),
+	)}
+
+	end, err := d.root()
+	if err != nil {
+		return err
+	}
+
+	return decodeBSONMembers[K, V](&d, 0, end, m)
+}
+
+// MarshalBSON implements Marshaler interface of [go.mongodb.org/mongo-driver].
+// The pairs are encoded as a BSON document, in their order, duplicated keys
+// are all kept.
+//
+// [go.mongodb.org/mongo-driver]: https://pkg.go.dev/go.mongodb.org/mongo-driver/bson
+func (ps *Pairs[K, V]) MarshalBSON() ([]byte, error) {
+	return BSONMarshal(ps)
+}
+
+func (ps *Pairs[K, V]) bsonValue(e *bsonEncoder) (byte, error) {
+	if ps == nil {
+		return bsonNull, nil
+	}
+
+	return bsonDocument, e.document(len(ps.List), func(i int) (string, any) {
+		return stdKey(ps.List[i].Key), ps.List[i].Value
+	})
+}
+
+// UnmarshalBSON implements Unmarshaler interface of
+// [go.mongodb.org/mongo-driver]. The input must be a BSON document, its
+// members are added in order, all values of duplicated keys are kept.
+//
+// If the value type is any, embedded documents and arrays are decoded into
+// [ObjectItems] and [Array]. See [Map.UnmarshalBSON] for other values.
+//
+// [go.mongodb.org/mongo-driver]: https://pkg.go.dev/go.mongodb.org/mongo-driver/bson
+func (ps *Pairs[K, V]) UnmarshalBSON(data []byte) error {
+	if err := ps.checkFrozen("UnmarshalBSON"); err != nil {
+		return err
+	}
+
+	d := bsonDecoder{data: data, opts: CreateDecodeOptions(UseObjectItems())}
+
+	end, err := d.root()
+	if err != nil {
+		return err
+	}
+
+	return decodeBSONMembers[K, V](&d, 0, end, ps)
+}
+
+// MarshalBSON implements Marshaler interface of [go.mongodb.org/mongo-driver].
+// A BSON array is a document whose keys are "0", "1", "2" and so on, the list
+// is encoded as that.
+//
+// Note that the MongoDB driver stores the result as an embedded document, not
+// an array, because array is only supported by its ValueMarshaler interface.
+// Lists inside [Map] and [Pairs] are arrays.
+//
+// [go.mongodb.org/mongo-driver]: https://pkg.go.dev/go.mongodb.org/mongo-driver/bson
+func (l *List[T]) MarshalBSON() ([]byte, error) {
+	if l == nil {
+		return nil, fmt.Errorf("geko: bson document must be an object, got %T", l)
+	}
+
+	e := bsonEncoder{}
+	if _, err := l.bsonValue(&e); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+func (l *List[T]) bsonValue(e *bsonEncoder) (byte, error) {
+	if l == nil {
+		return bsonNull, nil
+	}
+
+	return bsonArray, e.document(len(l.List), func(i int) (string, any) {
+		return strconv.Itoa(i), l.List[i]
+	})
+}
+
+// UnmarshalBSON implements Unmarshaler interface of
+// [go.mongodb.org/mongo-driver]. The input must be a BSON document, usually
+// an array, values are added in order, keys are ignored.
+//
+// If the item type is any, embedded documents and arrays are decoded with the
+// decode options of the list, like [List.UnmarshalJSON]. See [BSONUnmarshal]
+// for supported options, and [Map.UnmarshalBSON] for other item types.
+//
+// The inner slice is replaced, unless [List.AppendOnUnmarshal] is enabled.
+//
+// [go.mongodb.org/mongo-driver]: https://pkg.go.dev/go.mongodb.org/mongo-driver/bson
+func (l *List[T]) UnmarshalBSON(data []byte) error {
+	if err := l.checkFrozen("UnmarshalBSON"); err != nil {
+		return err
+	}
+
+	d := bsonDecoder{data: data, opts: l.decodeOptions}
+
+	end, err := d.root()
+	if err != nil {
+		return err
+	}
+
+	var items []T
+	err = d.elements(0, end, func(_ int, _ string, value any) error {
+		item, err := bindValue[T](value)
+		items = append(items, item)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	l.storeDecoded(items)
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	decimal128Bias        = 6176
+	decimal128MinExponent = -6176
+	decimal128MaxExponent = 6111
+	decimal128MaxDigits   = 34
+)
+
+// ErrInvalidDecimal128 means a string can't be parsed into [BSONDecimal128]
+// exactly.
+var ErrInvalidDecimal128 = errors.New("geko: invalid decimal128")
+
+// BSONDecimal128 is a BSON decimal128 value, an IEEE 754-2008 128-bit decimal
+// floating point number in BID encoding. High and Low are the high and low 64
+// bits of it, like the Decimal128 type of the MongoDB driver.
+//
+// Use [ParseBSONDecimal128] to create one from string.
+type BSONDecimal128 struct {
+	High uint64
+	Low  uint64
+}
+
+// ParseBSONDecimal128 parses a decimal string, like "1.23", "-4E+5", "NaN"
+// and "Infinity", into [BSONDecimal128]. The precision is kept, so "1.20" is
+// different from "1.2".
+//
+// An error wrapping [ErrInvalidDecimal128] is returned if s is malformed, or
+// can't be represented exactly.
+func ParseBSONDecimal128(s string) (BSONDecimal128, error) {
+	var d BSONDecimal128
+
+	body := strings.TrimPrefix(strings.TrimPrefix(s, "+"), "-")
+	if strings.HasPrefix(s, "-") {
+		d.High = 1 << 63
+	}
+
+	switch strings.ToLower(body) {
+	case "nan":
+		return BSONDecimal128{High: 0x1f << 58}, nil
+	case "inf", "infinity":
+		d.High |= 0x1e << 58
+		return d, nil
+	}
+
+	mantissa, exponentPart, hasExponent := strings.Cut(strings.ToLower(body), "e")
+
+	exponent := 0
+	if hasExponent {
+		var err error
+		if exponent, err = strconv.Atoi(exponentPart); err != nil {
+			return d, invalidDecimal128(s)
+		}
+	}
+
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	digits := integer + fraction
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return d, invalidDecimal128(s)
+	}
+	exponent -= len(fraction)
+
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		digits = "0"
+	}
+
+	// remove trailing zeros if there are too many digits, or exponent is too
+	// small, both keep the value exactly
+	for len(digits) > 1 && strings.HasSuffix(digits, "0") &&
+		(len(digits) > decimal128MaxDigits || exponent < decimal128MinExponent) {
+		digits = digits[:len(digits)-1]
+		exponent++
+	}
+
+	// add trailing zeros if exponent is too large
+	for exponent > decimal128MaxExponent && len(digits) < decimal128MaxDigits && digits != "0" {
+		digits += "0"
+		exponent--
+	}
+
+	if digits == "0" {
+		exponent = clampInt(exponent, decimal128MinExponent, decimal128MaxExponent)
+	}
+
+	if len(digits) > decimal128MaxDigits || exponent < decimal128MinExponent || exponent > decimal128MaxExponent {
+		return d, invalidDecimal128(s)
+	}
+
+	coefficient, _ := new(big.Int).SetString(digits, 10)
+	d.High |= uint64(exponent+decimal128Bias)<<49 | new(big.Int).Rsh(coefficient, 64).Uint64()
+	d.Low = coefficient.Uint64()
+
+	return d, nil
+}
+
+func invalidDecimal128(s string) error {
+	return fmt.Errorf("%w %q", ErrInvalidDecimal128, s)
+}
+
+func clampInt(v, lower, upper int) int {
+	if v < lower {
+		return lower
+	}
+	if v > upper {
+		return upper
+	}
+	return v
+}
+
+// String formats the decimal like the MongoDB driver does, it's the reverse
+// of [ParseBSONDecimal128].
+func (d BSONDecimal128) String() string {
+	sign := ""
+	if d.High>>63 == 1 {
+		sign = "-"
+	}
+
+	var exponent int
+	coefficient := new(big.Int)
+
+	switch {
+	case d.High>>58&0x1f == 0x1f:
+		return "NaN"
+	case d.High>>58&0x1f == 0x1e:
+		return sign + "Infinity"
+	case d.High>>61&3 == 3:
+		// coefficient of this form is always larger than the max value, so
+		// it's non-canonical and treated as zero
+		exponent = int(d.High>>47&0x3fff) - decimal128Bias
+	default:
+		exponent = int(d.High>>49&0x3fff) - decimal128Bias
+		coefficient.SetUint64(d.High & (1<<49 - 1))
+		coefficient.Lsh(coefficient, 64).Or(coefficient, new(big.Int).SetUint64(d.Low))
+	}
+
+	digits := coefficient.String()
+	if len(digits) > decimal128MaxDigits {
+		digits = "0"
+	}
+
+	adjusted := exponent + len(digits) - 1
+
+	var sb strings.Builder
+	_, _ = sb.WriteString(sign)
+
+	switch {
+	case exponent > 0 || adjusted < -6:
+		_ = sb.WriteByte(digits[0])
+		if len(digits) > 1 {
+			_ = sb.WriteByte('.')
+			_, _ = sb.WriteString(digits[1:])
+		}
+		_ = sb.WriteByte('E')
+		if adjusted >= 0 {
+			_ = sb.WriteByte('+')
+		}
+		_, _ = sb.WriteString(strconv.Itoa(adjusted))
+	case exponent == 0:
+		_, _ = sb.WriteString(digits)
+	case len(digits) > -exponent:
+		_, _ = sb.WriteString(digits[:len(digits)+exponent])
+		_ = sb.WriteByte('.')
+		_, _ = sb.WriteString(digits[len(digits)+exponent:])
+	default:
+		_, _ = sb.WriteString("0.")
+		_, _ = sb.WriteString(strings.Repeat("0", -exponent-len(digits)))
+		_, _ = sb.WriteString(digits)
+	}
+
+	return sb.String()
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, so it's a
+// string in JSON.
+func (d BSONDecimal128) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
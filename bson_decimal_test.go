@@ -0,0 +1,107 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestParseBSONDecimal128(t *testing.T) {
+	for _, c := range []struct {
+		input     string
+		high, low uint64
+	}{
+		{"0", 0x3040000000000000, 0},
+		{"-0", 0xB040000000000000, 0},
+		{"1", 0x3040000000000000, 1},
+		{"-1", 0xB040000000000000, 1},
+		{"0.1", 0x303E000000000000, 1},
+		{"1E+3", 0x3046000000000000, 1},
+		{"0.1234567890123456789012345678901234", 0x2FFC3CDE6FFF9732, 0xDE825CD07E96AFF2},
+		{"9.999999999999999999999999999999999E+6144", 0x5FFFED09BEAD87C0, 0x378D8E63FFFFFFFF},
+		{"NaN", 0x7C00000000000000, 0},
+		{"Infinity", 0x7800000000000000, 0},
+		{"-Infinity", 0xF800000000000000, 0},
+	} {
+		d, err := geko.ParseBSONDecimal128(c.input)
+		if err != nil {
+			t.Fatalf("ParseBSONDecimal128 %q with error: %s", c.input, err.Error())
+		}
+		if d.High != c.high || d.Low != c.low {
+			t.Fatalf("ParseBSONDecimal128 %q result not correct: %#x %#x", c.input, d.High, d.Low)
+		}
+	}
+}
+
+func TestBSONDecimal128_String(t *testing.T) {
+	for _, c := range []struct {
+		input    string
+		excepted string
+	}{
+		{"0", "0"},
+		{"-0.00", "-0.00"},
+		{"+1.20", "1.20"},
+		{"1000", "1000"},
+		{"1E+3", "1E+3"},
+		{"1.5e3", "1.5E+3"},
+		{"0.001", "0.001"},
+		{"123.456", "123.456"},
+		{"0.0000001", "1E-7"},
+		{"-1.23E-8", "-1.23E-8"},
+		{"1E+6112", "1.0E+6112"},
+		{"10E-6177", "1E-6176"},
+		{"0E+7000", "0E+6111"},
+		{"0E-7000", "0E-6176"},
+		{"00012345678901234567890123456789012340", "1.234567890123456789012345678901234E+34"},
+		{"1234567890123456789012345678901234", "1234567890123456789012345678901234"},
+		{"nan", "NaN"},
+		{"+Inf", "Infinity"},
+		{"-inf", "-Infinity"},
+	} {
+		d, err := geko.ParseBSONDecimal128(c.input)
+		if err != nil {
+			t.Fatalf("ParseBSONDecimal128 %q with error: %s", c.input, err.Error())
+		}
+		if d.String() != c.excepted {
+			t.Fatalf("BSONDecimal128 %q String not correct: %s", c.input, d.String())
+		}
+	}
+
+	// non-canonical coefficients are zero
+	for _, d := range []geko.BSONDecimal128{
+		{High: 0x3041ED09BEAD87C0, Low: 0x378D8E6400000000},
+		{High: 0x6C10000000000000, Low: 0},
+	} {
+		if d.String() != "0" {
+			t.Fatalf("BSONDecimal128 %#v String not correct: %s", d, d.String())
+		}
+	}
+}
+
+func TestParseBSONDecimal128_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"", "-", "abc", "1e", "E5", "1.2.3", "1x",
+		"12345678901234567890123456789012345",
+		"1E-6177",
+		"1E+6145",
+	} {
+		if d, err := geko.ParseBSONDecimal128(input); !errors.Is(err, geko.ErrInvalidDecimal128) {
+			t.Fatalf("ParseBSONDecimal128 %q should fail: %#v, %#v", input, d, err)
+		}
+	}
+}
+
+func TestBSONDecimal128_MarshalText(t *testing.T) {
+	d, _ := geko.ParseBSONDecimal128("-12.50")
+
+	output, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal with error: %s", err.Error())
+	}
+
+	if string(output) != `"-12.50"` {
+		t.Fatalf("BSONDecimal128 marshal result not correct: %s", string(output))
+	}
+}
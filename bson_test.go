@@ -0,0 +1,552 @@
+package geko_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+func bsonTestInt32(n int) string {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(n))
+	return string(b[:])
+}
+
+func bsonTestString(s string) string {
+	return bsonTestInt32(len(s)+1) + s + "\x00"
+}
+
+func bsonTestElement(t byte, key, value string) string {
+	return string([]byte{t}) + key + "\x00" + value
+}
+
+func bsonTestDocument(elements ...string) string {
+	body := strings.Join(elements, "")
+	return bsonTestInt32(len(body)+5) + body + "\x00"
+}
+
+// bsonTestKeys returns keys of top level elements in a BSON document, like
+// iterating bson.Raw elements.
+func bsonTestKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	root := geko.NewPairs[string, any]()
+	if err := root.UnmarshalBSON(data); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	return root.Keys()
+}
+
+var bsonTestAllTypes = bsonTestDocument(
+	bsonTestElement(0x01, "double", "\x00\x00\x00\x00\x00\x00\xf8\x3f"),
+	bsonTestElement(0x02, "string", bsonTestString("hello")),
+	bsonTestElement(0x03, "document", bsonTestDocument(
+		bsonTestElement(0x10, "z", bsonTestInt32(1)),
+		bsonTestElement(0x10, "a", bsonTestInt32(2)),
+	)),
+	bsonTestElement(0x04, "array", bsonTestDocument(
+		bsonTestElement(0x08, "0", "\x01"),
+		bsonTestElement(0x0a, "1", ""),
+	)),
+	bsonTestElement(0x05, "binary", bsonTestInt32(2)+"\x00\x01\x02"),
+	bsonTestElement(0x05, "uuid", bsonTestInt32(2)+"\x04\x01\x02"),
+	bsonTestElement(0x06, "undefined", ""),
+	bsonTestElement(0x07, "id", "\x50\x7f\x1f\x77\xbc\xf8\x6c\xd7\x99\x43\x90\x11"),
+	bsonTestElement(0x08, "false", "\x00"),
+	bsonTestElement(0x09, "date", "\x80\x07\x8d\x8e\x3d\x01\x00\x00"),
+	bsonTestElement(0x0a, "null", ""),
+	bsonTestElement(0x0b, "regex", "^a\x00i\x00"),
+	bsonTestElement(0x0c, "dbpointer", bsonTestString("c")+"\x50\x7f\x1f\x77\xbc\xf8\x6c\xd7\x99\x43\x90\x11"),
+	bsonTestElement(0x0d, "js", bsonTestString("f()")),
+	bsonTestElement(0x0e, "symbol", bsonTestString("s")),
+	bsonTestElement(0x0f, "scope", bsonTestInt32(15)+bsonTestString("x")+bsonTestDocument()),
+	bsonTestElement(0x10, "int32", bsonTestInt32(-2)),
+	bsonTestElement(0x11, "timestamp", "\x01\x00\x00\x00\x02\x00\x00\x00"),
+	bsonTestElement(0x12, "int64", "\x00\x00\x00\x00\x01\x00\x00\x00"),
+	bsonTestElement(0x13, "decimal", "\x0c\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x3c\x30"),
+	bsonTestElement(0x7f, "max", ""),
+	bsonTestElement(0xff, "min", ""),
+)
+
+func TestBSONUnmarshal(t *testing.T) {
+	v, err := geko.BSONUnmarshal([]byte(bsonTestAllTypes), geko.UseObject())
+	if err != nil {
+		t.Fatalf("BSONUnmarshal with error: %s", err.Error())
+	}
+
+	object := v.(geko.Object)
+
+	output, _ := json.Marshal(object)
+	excepted := `{"double":1.5,"string":"hello","document":{"z":1,"a":2},"array":[true,null],` +
+		`"binary":"AQI=","uuid":{"Type":5,"Data":"AgAAAAQBAg=="},"undefined":{"Type":6,"Data":""},` +
+		`"id":"507f1f77bcf86cd799439011","false":false,"date":"2013-03-21T20:04:00Z","null":null,` +
+		`"regex":{"Type":11,"Data":"XmEAaQA="},"dbpointer":{"Type":12,"Data":"AgAAAGMAUH8fd7z4bNeZQ5AR"},` +
+		`"js":{"Type":13,"Data":"BAAAAGYoKQA="},"symbol":{"Type":14,"Data":"AgAAAHMA"},` +
+		`"scope":{"Type":15,"Data":"DwAAAAIAAAB4AAUAAAAA"},"int32":-2,` +
+		`"timestamp":{"Type":17,"Data":"AQAAAAIAAAA="},"int64":4294967296,"decimal":"0.12",` +
+		`"max":{"Type":127,"Data":""},"min":{"Type":255,"Data":""}}`
+	if string(output) != excepted {
+		t.Fatalf("BSONUnmarshal result not correct: %s", string(output))
+	}
+
+	for key, excepted := range map[string]any{
+		"double":  1.5,
+		"int32":   int32(-2),
+		"int64":   int64(1 << 32),
+		"date":    time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC),
+		"id":      geko.BSONObjectID{0x50, 0x7f, 0x1f, 0x77, 0xbc, 0xf8, 0x6c, 0xd7, 0x99, 0x43, 0x90, 0x11},
+		"decimal": geko.BSONDecimal128{High: 0x303c000000000000, Low: 12},
+	} {
+		if value := object.GetOrZeroValue(key); value != excepted {
+			t.Fatalf("BSONUnmarshal value of %s not correct: %#v", key, value)
+		}
+	}
+
+	items, _ := geko.BSONUnmarshal([]byte(bsonTestAllTypes))
+	if _, ok := items.(geko.ObjectItems); !ok {
+		t.Fatalf("BSONUnmarshal should return ObjectItems by default: %#v", items)
+	}
+}
+
+func TestBSON_RoundTrip(t *testing.T) {
+	v, _ := geko.BSONUnmarshal([]byte(bsonTestAllTypes), geko.UseObject())
+	object := v.(geko.Object)
+
+	data, err := object.MarshalBSON()
+	if err != nil {
+		t.Fatalf("MarshalBSON with error: %s", err.Error())
+	}
+
+	if string(data) != bsonTestAllTypes {
+		t.Fatalf("BSON round trip result not correct: %x", data)
+	}
+
+	items := geko.NewPairs[string, any]()
+	if err = items.UnmarshalBSON(data); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	data, _ = items.MarshalBSON()
+	if string(data) != bsonTestAllTypes {
+		t.Fatalf("BSON round trip of Pairs not correct: %x", data)
+	}
+}
+
+func TestBSON_KeyOrder(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"z": 1, "a": {"y": 2, "b": 3}, "m": [], "c": null}`), geko.UseObject())
+	object := v.(geko.Object)
+
+	data, err := object.MarshalBSON()
+	if err != nil {
+		t.Fatalf("MarshalBSON with error: %s", err.Error())
+	}
+
+	if keys := bsonTestKeys(t, data); strings.Join(keys, ",") != "z,a,m,c" {
+		t.Fatalf("BSON key order not correct: %v", keys)
+	}
+
+	decoded := geko.NewMap[string, any]()
+	if err = decoded.UnmarshalBSON(data); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	inner := decoded.GetOrZeroValue("a").(geko.Object)
+	if strings.Join(decoded.Keys(), ",") != "z,a,m,c" || strings.Join(inner.Keys(), ",") != "y,b" {
+		t.Fatalf("BSON decode key order not correct: %v, %v", decoded.Keys(), inner.Keys())
+	}
+}
+
+type bsonTestStruct struct {
+	Name string `json:"name"`
+	Size uint8  `json:"size"`
+}
+
+func TestBSONMarshal(t *testing.T) {
+	date := time.Date(2013, 3, 21, 20, 4, 0, 123456789, time.UTC)
+
+	data, err := geko.BSONMarshal(map[string]any{
+		"a": int8(-1),
+		"b": 1 << 40,
+		"c": int64(1),
+		"d": uint16(2),
+		"e": uint64(3),
+		"f": float32(0.5),
+		"g": json.Number("10"),
+		"h": json.Number("12345678901234567890"),
+		"i": json.Number("1e2"),
+		"j": bsonTestStruct{Name: "x", Size: 1},
+		"k": []any{"v"},
+		"l": date,
+		"m": []byte("\x01"),
+		"n": uintptr(1 << 33),
+		"o": true,
+		"p": geko.NewList[any](),
+	})
+	if err != nil {
+		t.Fatalf("BSONMarshal with error: %s", err.Error())
+	}
+
+	excepted := bsonTestDocument(
+		bsonTestElement(0x10, "a", bsonTestInt32(-1)),
+		bsonTestElement(0x12, "b", "\x00\x00\x00\x00\x00\x01\x00\x00"),
+		bsonTestElement(0x12, "c", "\x01\x00\x00\x00\x00\x00\x00\x00"),
+		bsonTestElement(0x10, "d", bsonTestInt32(2)),
+		bsonTestElement(0x12, "e", "\x03\x00\x00\x00\x00\x00\x00\x00"),
+		bsonTestElement(0x01, "f", "\x00\x00\x00\x00\x00\x00\xe0\x3f"),
+		bsonTestElement(0x10, "g", bsonTestInt32(10)),
+		bsonTestElement(0x13, "h", "\xd2\x0a\x1f\xeb\x8c\xa9\x54\xab\x00\x00\x00\x00\x00\x00\x40\x30"),
+		bsonTestElement(0x01, "i", "\x00\x00\x00\x00\x00\x00\x59\x40"),
+		bsonTestElement(0x03, "j", bsonTestDocument(
+			bsonTestElement(0x02, "name", bsonTestString("x")),
+			bsonTestElement(0x10, "size", bsonTestInt32(1)),
+		)),
+		bsonTestElement(0x04, "k", bsonTestDocument(bsonTestElement(0x02, "0", bsonTestString("v")))),
+		bsonTestElement(0x09, "l", "\xfb\x07\x8d\x8e\x3d\x01\x00\x00"),
+		bsonTestElement(0x05, "m", bsonTestInt32(1)+"\x00\x01"),
+		bsonTestElement(0x12, "n", "\x00\x00\x00\x00\x02\x00\x00\x00"),
+		bsonTestElement(0x08, "o", "\x01"),
+		bsonTestElement(0x04, "p", bsonTestDocument()),
+	)
+	if string(data) != excepted {
+		t.Fatalf("BSONMarshal result not correct: %s", hex.EncodeToString(data))
+	}
+}
+
+func TestBSONMarshal_Error(t *testing.T) {
+	var nilObject geko.Object
+
+	for _, v := range []any{
+		nil,
+		1,
+		nilObject,
+		geko.NewList[any](),
+		map[string]any{"a\x00": 1},
+		map[string]any{"a": []any{uint64(math.MaxUint64)}},
+		map[string]any{"a": json.Number("x")},
+		map[string]any{"a": make(chan int)},
+	} {
+		if data, err := geko.BSONMarshal(v); err == nil {
+			t.Fatalf("BSONMarshal %#v should fail: %x", v, data)
+		}
+	}
+
+	var nilArray geko.Array
+	if data, err := nilArray.MarshalBSON(); err == nil {
+		t.Fatalf("MarshalBSON of nil list should fail: %x", data)
+	}
+
+	array := geko.NewListFrom([]any{make(chan int)})
+	if data, err := array.MarshalBSON(); err == nil {
+		t.Fatalf("MarshalBSON of invalid list should fail: %x", data)
+	}
+}
+
+func TestBSONMarshal_Nil(t *testing.T) {
+	var object geko.Object
+	var items geko.ObjectItems
+	var array geko.Array
+
+	data, err := geko.BSONMarshal(map[string]any{"a": object, "b": items, "c": array})
+	if err != nil {
+		t.Fatalf("BSONMarshal with error: %s", err.Error())
+	}
+
+	excepted := bsonTestDocument(
+		bsonTestElement(0x0a, "a", ""),
+		bsonTestElement(0x0a, "b", ""),
+		bsonTestElement(0x0a, "c", ""),
+	)
+	if string(data) != excepted {
+		t.Fatalf("BSONMarshal result not correct: %x", data)
+	}
+}
+
+func TestBSONUnmarshal_DuplicatedKeys(t *testing.T) {
+	data := []byte(bsonTestDocument(
+		bsonTestElement(0x10, "a", bsonTestInt32(1)),
+		bsonTestElement(0x10, "b", bsonTestInt32(2)),
+		bsonTestElement(0x10, "a", bsonTestInt32(3)),
+	))
+
+	v, _ := geko.BSONUnmarshal(data)
+	output, _ := json.Marshal(v)
+	if string(output) != `{"a":1,"b":2,"a":3}` {
+		t.Fatalf("BSONUnmarshal result not correct: %s", string(output))
+	}
+
+	v, _ = geko.BSONUnmarshal(data, geko.UseObject(), geko.ObjectOnDuplicatedKey(geko.UpdateValueUpdateOrder))
+	output, _ = json.Marshal(v)
+	if string(output) != `{"b":2,"a":3}` {
+		t.Fatalf("BSONUnmarshal result not correct: %s", string(output))
+	}
+
+	m := geko.NewMap[string, int32]()
+	m.SetDuplicatedKeyStrategy(geko.Ignore)
+	m.SetRecordDuplicates(true)
+	if err := m.UnmarshalBSON(data); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	if m.GetOrZeroValue("a") != 1 || m.Duplicates().Len() != 1 {
+		t.Fatalf("UnmarshalBSON result not correct: %#v", m)
+	}
+}
+
+func TestBSON_Typed(t *testing.T) {
+	m := geko.NewMap[int, string]()
+	m.Set(2, "b")
+	m.Set(1, "a")
+
+	data, err := m.MarshalBSON()
+	if err != nil {
+		t.Fatalf("MarshalBSON with error: %s", err.Error())
+	}
+
+	decoded := geko.NewMap[int, string]()
+	if err = decoded.UnmarshalBSON(data); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	if strings.Join(bsonTestKeys(t, data), ",") != "2,1" || decoded.GetOrZeroValue(1) != "a" {
+		t.Fatalf("Typed map round trip result not correct: %#v", decoded)
+	}
+
+	ps := geko.NewPairs[string, int]()
+	if err = ps.UnmarshalBSON([]byte(bsonTestDocument(
+		bsonTestElement(0x10, "a", bsonTestInt32(1)),
+		bsonTestElement(0x12, "a", "\x02\x00\x00\x00\x00\x00\x00\x00"),
+		bsonTestElement(0x01, "b", "\x00\x00\x00\x00\x00\x00\x08\x40"),
+	))); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(ps)
+	if string(output) != `{"a":1,"a":2,"b":3}` {
+		t.Fatalf("Typed pairs result not correct: %s", string(output))
+	}
+
+	structs := geko.NewMap[string, bsonTestStruct]()
+	if err = structs.UnmarshalBSON([]byte(bsonTestDocument(bsonTestElement(0x03, "s", bsonTestDocument(
+		bsonTestElement(0x02, "name", bsonTestString("x")),
+		bsonTestElement(0x10, "size", bsonTestInt32(1)),
+	))))); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	if structs.GetOrZeroValue("s") != (bsonTestStruct{Name: "x", Size: 1}) {
+		t.Fatalf("Struct map result not correct: %#v", structs)
+	}
+}
+
+func TestBSON_List(t *testing.T) {
+	l := geko.NewListFrom([]time.Time{time.Unix(1363896240, 0)})
+
+	data, err := l.MarshalBSON()
+	if err != nil {
+		t.Fatalf("MarshalBSON with error: %s", err.Error())
+	}
+
+	excepted := bsonTestDocument(bsonTestElement(0x09, "0", "\x80\x07\x8d\x8e\x3d\x01\x00\x00"))
+	if string(data) != excepted {
+		t.Fatalf("MarshalBSON of list not correct: %x", data)
+	}
+
+	times := geko.NewList[time.Time]()
+	times.SetAppendOnUnmarshal(true)
+	for i := 0; i < 2; i++ {
+		if err = times.UnmarshalBSON(data); err != nil {
+			t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+		}
+	}
+
+	if times.Len() != 2 || !times.Get(1).Equal(l.Get(0)) {
+		t.Fatalf("Time list result not correct: %#v", times)
+	}
+
+	anys := geko.NewList[any]()
+	anys.SetDecodeOptions(geko.UseObject())
+	if err = anys.UnmarshalBSON([]byte(bsonTestDocument(
+		bsonTestElement(0x03, "x", bsonTestDocument()),
+		bsonTestElement(0x0a, "y", ""),
+	))); err != nil {
+		t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+	}
+
+	if _, ok := anys.Get(0).(geko.Object); !ok || anys.Len() != 2 || anys.Get(1) != nil {
+		t.Fatalf("List decode options not used: %#v", anys)
+	}
+}
+
+func TestBSONUnmarshal_Invalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"\x05\x00\x00",
+		"\x04\x00\x00\x00\x00",
+		"\x06\x00\x00\x00\x00",
+		"\x05\x00\x00\x00\x01",
+		bsonTestDocument() + "\x00",
+		bsonTestDocument("\x10a"),
+		bsonTestDocument(bsonTestElement(0x10, "a", "\x01")),
+		bsonTestDocument(bsonTestElement(0x01, "a", "")),
+		bsonTestDocument(bsonTestElement(0x02, "a", bsonTestInt32(2)+"ab")),
+		bsonTestDocument(bsonTestElement(0x02, "a", bsonTestInt32(0))),
+		bsonTestDocument(bsonTestElement(0x02, "a", "\x01")),
+		bsonTestDocument(bsonTestElement(0x02, "a", bsonTestInt32(100)+"a\x00")),
+		bsonTestDocument(bsonTestElement(0x05, "a", bsonTestInt32(-10)+"\x00")),
+		bsonTestDocument(bsonTestElement(0x05, "0", bsonTestInt32(-1)+"0000000000000")),
+		bsonTestDocument(bsonTestElement(0x0c, "a", bsonTestInt32(-1)+"\x00")),
+		bsonTestDocument(bsonTestElement(0x0c, "a", bsonTestInt32(0)+"000000000000")),
+		bsonTestDocument(bsonTestElement(0x0d, "a", bsonTestInt32(-1))),
+		bsonTestDocument(bsonTestElement(0x0f, "a", bsonTestInt32(-1)+"0000")),
+		bsonTestDocument(bsonTestElement(0x0f, "a", bsonTestInt32(13)+"000000000")),
+		bsonTestDocument(bsonTestElement(0x03, "a", bsonTestInt32(100)+"\x00")),
+		bsonTestDocument(bsonTestElement(0x04, "a", bsonTestDocument("\x10a"))),
+		bsonTestDocument(bsonTestElement(0x03, "a", bsonTestDocument(bsonTestElement(0x20, "b", "")))),
+		bsonTestDocument(bsonTestElement(0x0b, "a", "x")),
+		bsonTestDocument(bsonTestElement(0x0b, "a", "x\x00i")),
+		bsonTestDocument(bsonTestElement(0x20, "a", "")),
+	} {
+		v, err := geko.BSONUnmarshal([]byte(data))
+		if err == nil {
+			t.Fatalf("BSONUnmarshal %x should fail: %#v", data, v)
+		}
+
+		var bsonErr *geko.BSONError
+		if !errors.As(err, &bsonErr) {
+			t.Fatalf("BSONUnmarshal should return BSONError: %#v", err)
+		}
+	}
+}
+
+// bsonTestNested returns n nested documents, each of them but the innermost
+// has an element of type t with empty key.
+func bsonTestNested(n int, t byte) []byte {
+	var data []byte
+	for i := 0; i < n-1; i++ {
+		data = append(data, bsonTestInt32(5+7*(n-1-i))...)
+		data = append(data, t, 0)
+	}
+	data = append(data, bsonTestDocument()...)
+	return append(data, make([]byte, n-1)...)
+}
+
+func checkBSONDepthLimit(t *testing.T, err error, limit, offset int64) {
+	t.Helper()
+
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Kind != geko.DepthLimit || limitErr.Limit != limit ||
+		limitErr.Offset != offset {
+		t.Fatalf("BSONUnmarshal should fail with depth limit %d at %d, got %#v", limit, offset, err)
+	}
+}
+
+func TestBSONUnmarshal_MaxDepth(t *testing.T) {
+	for _, typ := range []byte{0x03, 0x04} {
+		if _, err := geko.BSONUnmarshal(bsonTestNested(10000, typ)); err != nil {
+			t.Fatalf("BSONUnmarshal with error: %s", err.Error())
+		}
+
+		_, err := geko.BSONUnmarshal(bsonTestNested(20000, typ))
+		checkBSONDepthLimit(t, err, 10000, 60000)
+	}
+
+	if _, err := geko.BSONUnmarshal(bsonTestNested(2, 0x03), geko.MaxDepth(2)); err != nil {
+		t.Fatalf("BSONUnmarshal with error: %s", err.Error())
+	}
+
+	_, err := geko.BSONUnmarshal(bsonTestNested(3, 0x03), geko.MaxDepth(2))
+	checkBSONDepthLimit(t, err, 2, 12)
+
+	l := geko.NewList[any]()
+	l.SetDecodeOptions(geko.MaxDepth(1))
+	checkBSONDepthLimit(t, l.UnmarshalBSON(bsonTestNested(2, 0x04)), 1, 6)
+
+	checkBSONDepthLimit(t, geko.NewMap[string, []any]().UnmarshalBSON(bsonTestNested(20000, 0x04)), 10000, 60000)
+}
+
+func TestBSONError(t *testing.T) {
+	_, err := geko.BSONUnmarshal([]byte(bsonTestDocument(bsonTestElement(0x20, "a", ""))))
+	if err == nil || err.Error() != "geko: invalid bson: unknown element type 0x20 at offset 7" {
+		t.Fatalf("BSONError message not correct: %#v", err)
+	}
+}
+
+func TestBSON_TypedError(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		target interface{ UnmarshalBSON(data []byte) error }
+	}{
+		{bsonTestDocument(bsonTestElement(0x10, "a", bsonTestInt32(1))), geko.NewMap[int, int]()},
+		{bsonTestDocument(bsonTestElement(0x02, "a", bsonTestString("x"))), geko.NewMap[string, int]()},
+		{bsonTestDocument() + "\x00", geko.NewMap[string, int]()},
+		{"", geko.NewPairs[string, int]()},
+		{bsonTestDocument(bsonTestElement(0x02, "0", bsonTestString("x"))), geko.NewList[int]()},
+		{"", geko.NewList[int]()},
+	} {
+		if err := c.target.UnmarshalBSON([]byte(c.data)); err == nil {
+			t.Fatalf("UnmarshalBSON %x should fail: %#v", c.data, c.target)
+		}
+	}
+}
+
+func TestBSONObjectID(t *testing.T) {
+	id := geko.BSONObjectID{0x50, 0x7f, 0x1f, 0x77, 0xbc, 0xf8, 0x6c, 0xd7, 0x99, 0x43, 0x90, 0x11}
+
+	output, _ := json.Marshal(id)
+	if id.String() != "507f1f77bcf86cd799439011" || string(output) != `"507f1f77bcf86cd799439011"` {
+		t.Fatalf("BSONObjectID format not correct: %s, %s", id.String(), string(output))
+	}
+}
+
+// bsonTestMarshaler and bsonTestUnmarshaler are Marshaler and Unmarshaler
+// interfaces of go.mongodb.org/mongo-driver/bson, which is not a dependency.
+type (
+	bsonTestMarshaler interface {
+		MarshalBSON() ([]byte, error)
+	}
+	bsonTestUnmarshaler interface {
+		UnmarshalBSON([]byte) error
+	}
+)
+
+func TestBSON_DriverInterfaces(t *testing.T) {
+	for _, v := range []any{geko.NewMap[string, any](), geko.NewPairs[string, any](), geko.NewList[any]()} {
+		if _, ok := v.(bsonTestMarshaler); !ok {
+			t.Fatalf("%T should implement bson.Marshaler", v)
+		}
+		if _, ok := v.(bsonTestUnmarshaler); !ok {
+			t.Fatalf("%T should implement bson.Unmarshaler", v)
+		}
+	}
+}
+
+func FuzzBSON_Unmarshal(f *testing.F) {
+	f.Add([]byte(bsonTestAllTypes))
+	f.Add([]byte("\x19\x00\x00\x00\x050\x00\xff\xff\xff\xff0000000000000\x00"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		object := geko.NewMap[string, any]()
+		if object.UnmarshalBSON(data) != nil {
+			return
+		}
+
+		// valid data must round trip
+		output, err := object.MarshalBSON()
+		if err != nil {
+			t.Fatalf("MarshalBSON with error: %s", err.Error())
+		}
+
+		decoded := geko.NewMap[string, any]()
+		if err = decoded.UnmarshalBSON(output); err != nil {
+			t.Fatalf("UnmarshalBSON with error: %s", err.Error())
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package geko
+
+import "fmt"
+
+// ObjectBuilder builds an [Object] fluently, so the structure of a nested JSON
+// body is visible in code:
+//
+//	data, err := geko.NewObjectBuilder().
+//		Set("id", 1).
+//		SetObject("meta", func(b *geko.ObjectBuilder) {
+//			b.Set("k", "v")
+//		}).
+//		SetArray("tags", "a", "b").
+//		BuildJSON()
+//	// {"id":1,"meta":{"k":"v"},"tags":["a","b"]}
+//
+// Members are kept in the order they are first set, setting an existing key
+// replaces its value, and keeps its position.
+//
+// Methods return the builder itself for chaining, and never panic. If a value
+// can't be marshaled into JSON, the error is recorded, and returned by
+// [ObjectBuilder.Build] or [ObjectBuilder.BuildJSON]. Only the first error is
+// kept.
+type ObjectBuilder struct {
+	object Object
+	err    error
+}
+
+// NewObjectBuilder creates a builder of an empty object.
+func NewObjectBuilder() *ObjectBuilder {
+	return &ObjectBuilder{object: NewMap[string, any]()}
+}
+
+// Set sets value of key. value is stored as is, so it can be any value which
+// can be marshaled into JSON, including containers of this package.
+func (b *ObjectBuilder) Set(key string, value any) *ObjectBuilder {
+	if err := checkBuilderValue(value); err != nil {
+		b.fail(key, err)
+	}
+
+	b.object.Set(key, value)
+	return b
+}
+
+// SetObject sets value of key to a nested object built by fn.
+func (b *ObjectBuilder) SetObject(key string, fn func(b *ObjectBuilder)) *ObjectBuilder {
+	nested := NewObjectBuilder()
+	fn(nested)
+	b.fail(key, nested.err)
+
+	b.object.Set(key, nested.object)
+	return b
+}
+
+// SetArray sets value of key to an array contains items.
+func (b *ObjectBuilder) SetArray(key string, items ...any) *ObjectBuilder {
+	return b.SetArrayFunc(key, func(a *ArrayBuilder) {
+		a.Append(items...)
+	})
+}
+
+// SetArrayFunc sets value of key to a nested array built by fn.
+func (b *ObjectBuilder) SetArrayFunc(key string, fn func(a *ArrayBuilder)) *ObjectBuilder {
+	nested := NewArrayBuilder()
+	fn(nested)
+	b.fail(key, nested.err)
+
+	b.object.Set(key, nested.array)
+	return b
+}
+
+// Build returns the built object, or the first error recorded.
+//
+// The builder should not be used after this call, because the returned object
+// is not copied.
+func (b *ObjectBuilder) Build() (Object, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.object, nil
+}
+
+// BuildJSON is a shortcut of [ObjectBuilder.Build] and [JSONMarshal] with
+// option.
+func (b *ObjectBuilder) BuildJSON(option ...EncodeOption) ([]byte, error) {
+	object, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return JSONMarshal(object, option...)
+}
+
+func (b *ObjectBuilder) fail(key string, err error) {
+	if err != nil && b.err == nil {
+		b.err = prependBuilderPath(key, err)
+	}
+}
+
+// ArrayBuilder builds an [Array] fluently, it's used by
+// [ObjectBuilder.SetArrayFunc] to build nested arrays, see [ObjectBuilder]
+// for detail.
+type ArrayBuilder struct {
+	array Array
+	err   error
+}
+
+// NewArrayBuilder creates a builder of an empty array.
+func NewArrayBuilder() *ArrayBuilder {
+	return &ArrayBuilder{array: NewList[any]()}
+}
+
+// Append values to the end of the array, see [ObjectBuilder.Set] for what
+// values can be.
+func (a *ArrayBuilder) Append(values ...any) *ArrayBuilder {
+	for _, value := range values {
+		if err := checkBuilderValue(value); err != nil {
+			a.fail(a.array.Len(), err)
+		}
+		a.array.Append(value)
+	}
+	return a
+}
+
+// AppendObject appends a nested object built by fn.
+func (a *ArrayBuilder) AppendObject(fn func(b *ObjectBuilder)) *ArrayBuilder {
+	nested := NewObjectBuilder()
+	fn(nested)
+	a.fail(a.array.Len(), nested.err)
+
+	a.array.Append(nested.object)
+	return a
+}
+
+// AppendArray appends a nested array built by fn.
+func (a *ArrayBuilder) AppendArray(fn func(a *ArrayBuilder)) *ArrayBuilder {
+	nested := NewArrayBuilder()
+	fn(nested)
+	a.fail(a.array.Len(), nested.err)
+
+	a.array.Append(nested.array)
+	return a
+}
+
+// Build returns the built array, or the first error recorded.
+func (a *ArrayBuilder) Build() (Array, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return a.array, nil
+}
+
+func (a *ArrayBuilder) fail(index int, err error) {
+	if err != nil && a.err == nil {
+		a.err = prependBuilderPath(index, err)
+	}
+}
+
+// BuilderError is recorded by [ObjectBuilder] and [ArrayBuilder] when a value
+// can't be marshaled into JSON.
+type BuilderError struct {
+	// Path is the location of the value from the root, see [WalkFunc].
+	Path []any
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *BuilderError) Error() string {
+	return fmt.Sprintf("geko: build value at %v: %s", e.Path, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *BuilderError) Unwrap() error {
+	return e.Err
+}
+
+func prependBuilderPath(key any, err error) error {
+	if e, ok := err.(*BuilderError); ok {
+		return &BuilderError{Path: append([]any{key}, e.Path...), Err: e.Err}
+	}
+	return &BuilderError{Path: []any{key}, Err: err}
+}
+
+func checkBuilderValue(value any) error {
+	_, err := JSONMarshal(value)
+	return err
+}
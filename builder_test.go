@@ -0,0 +1,110 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestObjectBuilder(t *testing.T) {
+	data, err := geko.NewObjectBuilder().
+		Set("id", 1).
+		SetObject("meta", func(b *geko.ObjectBuilder) {
+			b.Set("k", "v").Set("a", nil).SetObject("empty", func(_ *geko.ObjectBuilder) {})
+		}).
+		SetArray("tags", "a", "b").
+		SetArrayFunc("items", func(a *geko.ArrayBuilder) {
+			a.Append(1, "<x>").
+				AppendObject(func(b *geko.ObjectBuilder) {
+					b.Set("z", true).SetArray("empty")
+				}).
+				AppendArray(func(a *geko.ArrayBuilder) {
+					a.Append(geko.NewMapOf(geko.P("y", 2), geko.P("x", 3)))
+				})
+		}).
+		Set("id", 2).
+		BuildJSON(geko.EscapeHTML(false))
+	if err != nil {
+		t.Fatalf("BuildJSON with error: %s", err.Error())
+	}
+
+	excepted := `{"id":2,"meta":{"k":"v","a":null,"empty":{}},"tags":["a","b"],` +
+		`"items":[1,"<x>",{"z":true,"empty":[]},[{"y":2,"x":3}]]}`
+	if string(data) != excepted {
+		t.Fatalf("BuildJSON result not correct: %s", string(data))
+	}
+}
+
+func TestObjectBuilder_Build(t *testing.T) {
+	object, err := geko.NewObjectBuilder().Set("b", 1).SetArray("a").Build()
+	if err != nil {
+		t.Fatalf("Build with error: %s", err.Error())
+	}
+
+	if keys := object.Keys(); len(keys) != 2 || keys[0] != "b" || keys[1] != "a" {
+		t.Fatalf("Build result keys not correct: %v", keys)
+	}
+
+	if array, _ := object.GetOrZeroValue("a").(geko.Array); array == nil || array.Len() != 0 {
+		t.Fatalf("SetArray without items should be an empty array: %#v", object.GetOrZeroValue("a"))
+	}
+
+	array, err := geko.NewArrayBuilder().Append(1).AppendArray(func(_ *geko.ArrayBuilder) {}).Build()
+	output, _ := json.Marshal(array)
+	if err != nil || string(output) != `[1,[]]` {
+		t.Fatalf("ArrayBuilder result not correct: %s, %#v", string(output), err)
+	}
+}
+
+func TestObjectBuilder_Error(t *testing.T) {
+	for _, c := range []struct {
+		builder  *geko.ObjectBuilder
+		excepted string
+	}{
+		{
+			geko.NewObjectBuilder().Set("a", 1).Set("b", math.NaN()).Set("c", make(chan int)),
+			"geko: build value at [b]: json: unsupported value: NaN",
+		},
+		{
+			geko.NewObjectBuilder().SetObject("a", func(b *geko.ObjectBuilder) {
+				b.SetArrayFunc("b", func(a *geko.ArrayBuilder) {
+					a.Append(1, 2).AppendObject(func(b *geko.ObjectBuilder) {
+						b.Set("c", math.Inf(1))
+					})
+				})
+			}),
+			"geko: build value at [a b 2 c]: json: unsupported value: +Inf",
+		},
+		{
+			geko.NewObjectBuilder().SetArray("a", 1, make(chan int)),
+			"geko: build value at [a 1]: json: unsupported type: chan int",
+		},
+		{
+			geko.NewObjectBuilder().SetArrayFunc("a", func(a *geko.ArrayBuilder) {
+				a.AppendArray(func(a *geko.ArrayBuilder) { a.Append(math.NaN()) }).Append(math.NaN())
+			}),
+			"geko: build value at [a 0 0]: json: unsupported value: NaN",
+		},
+	} {
+		object, err := c.builder.Build()
+		if err == nil || err.Error() != c.excepted || object != nil {
+			t.Fatalf("Build should fail with %q: %#v", c.excepted, err)
+		}
+
+		var builderErr *geko.BuilderError
+		if !errors.As(err, &builderErr) || builderErr.Unwrap() == nil {
+			t.Fatalf("Build error should be a BuilderError: %#v", err)
+		}
+
+		if data, err := c.builder.BuildJSON(); err == nil || data != nil {
+			t.Fatalf("BuildJSON should fail: %s", string(data))
+		}
+	}
+
+	if array, err := geko.NewArrayBuilder().Append(math.NaN()).Build(); err == nil || array != nil {
+		t.Fatalf("ArrayBuilder Build should fail: %#v", array)
+	}
+}
@@ -0,0 +1,264 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MarshalCanonical returns the canonical JSON encoding of v, defined by
+// [RFC 8785] (JSON Canonicalization Scheme, JCS). The output is suitable for
+// hashing and signing:
+//
+//   - No whitespace.
+//   - Keys of object are sorted by their UTF-16 code units.
+//   - Numbers are formatted like ES6 Number.prototype.toString, as IEEE 754
+//     double values. NaN and infinity are not allowed.
+//   - Strings only escape necessary characters.
+//
+// Duplicated keys are not allowed in JCS, a [*DuplicateKeyError] is returned
+// when meet them in [Pairs]. Values which are not types in this package,
+// json.Number, map[string]any or []any are encoded by [json.Marshal] first.
+//
+// [RFC 8785]: https://www.rfc-editor.org/rfc/rfc8785
+func MarshalCanonical(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch x := v.(type) {
+	case nil:
+		_, _ = buf.WriteString("null")
+	case bool:
+		_, _ = buf.WriteString(strconv.FormatBool(x))
+	case string:
+		return writeCanonicalString(buf, x)
+	case float64:
+		return writeCanonicalNumber(buf, x)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(x), 64)
+		if err != nil {
+			return fmt.Errorf("geko: canonical JSON number %s: %w", x, err)
+		}
+		return writeCanonicalNumber(buf, f)
+	case ObjectItems:
+		return writeCanonicalObject(buf, x)
+	case Object:
+		if x == nil {
+			_, _ = buf.WriteString("null")
+			return nil
+		}
+		return writeCanonicalObject(buf, x.Pairs())
+	case Array:
+		if x == nil {
+			_, _ = buf.WriteString("null")
+			return nil
+		}
+		if x.List == nil {
+			// a decoded empty array has no inner slice, it's still an array
+			_, _ = buf.WriteString("[]")
+			return nil
+		}
+		return writeCanonicalArray(buf, x.List)
+	case map[string]any:
+		if x == nil {
+			_, _ = buf.WriteString("null")
+			return nil
+		}
+		ps := NewPairsWithCapacity[string, any](len(x))
+		for key, value := range x {
+			ps.Add(key, value)
+		}
+		return writeCanonicalObject(buf, ps)
+	case []any:
+		return writeCanonicalArray(buf, x)
+	default:
+		return writeCanonicalOther(buf, v)
+	}
+
+	return nil
+}
+
+// writeCanonicalOther encodes v by std lib, then decodes it back to types
+// can be handled by writeCanonical.
+func writeCanonicalOther(buf *bytes.Buffer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// std lib always produces valid JSON
+	value, _ := JSONUnmarshal(data, UseNumber(true))
+
+	return writeCanonical(buf, value)
+}
+
+func writeCanonicalArray(buf *bytes.Buffer, array []any) error {
+	if array == nil {
+		_, _ = buf.WriteString("null")
+		return nil
+	}
+
+	_ = buf.WriteByte('[')
+	for i, item := range array {
+		if i > 0 {
+			_ = buf.WriteByte(',')
+		}
+		if err := writeCanonical(buf, item); err != nil {
+			return err
+		}
+	}
+	_ = buf.WriteByte(']')
+
+	return nil
+}
+
+func writeCanonicalObject(buf *bytes.Buffer, object ObjectItems) error {
+	if object == nil {
+		_, _ = buf.WriteString("null")
+		return nil
+	}
+
+	keys := make([][]uint16, object.Len())
+	order := make([]int, object.Len())
+	for i := range order {
+		order[i] = i
+		keys[i] = utf16.Encode([]rune(object.GetKeyByIndex(i)))
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return compareUTF16(keys[order[i]], keys[order[j]]) < 0
+	})
+
+	_ = buf.WriteByte('{')
+	for i, index := range order {
+		if i > 0 {
+			if compareUTF16(keys[order[i-1]], keys[index]) == 0 {
+				return &DuplicateKeyError{Key: object.GetKeyByIndex(index), Index: index, Offset: -1}
+			}
+			_ = buf.WriteByte(',')
+		}
+
+		pair := object.GetByIndex(index)
+
+		if err := writeCanonicalString(buf, pair.Key); err != nil {
+			return err
+		}
+
+		_ = buf.WriteByte(':')
+
+		if err := writeCanonical(buf, pair.Value); err != nil {
+			return err
+		}
+	}
+	_ = buf.WriteByte('}')
+
+	return nil
+}
+
+func compareUTF16(a, b []uint16) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+	return len(a) - len(b)
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("geko: canonical JSON string %q is not valid UTF-8", s)
+	}
+
+	_ = buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			_, _ = buf.WriteString(`\"`)
+		case '\\':
+			_, _ = buf.WriteString(`\\`)
+		case '\b':
+			_, _ = buf.WriteString(`\b`)
+		case '\f':
+			_, _ = buf.WriteString(`\f`)
+		case '\n':
+			_, _ = buf.WriteString(`\n`)
+		case '\r':
+			_, _ = buf.WriteString(`\r`)
+		case '\t':
+			_, _ = buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				_, _ = fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				_, _ = buf.WriteRune(r)
+			}
+		}
+	}
+	_ = buf.WriteByte('"')
+
+	return nil
+}
+
+// writeCanonicalNumber writes f like ES6 Number.prototype.toString.
+func writeCanonicalNumber(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("geko: canonical JSON does not support number %v", f)
+	}
+
+	if f == 0 { // also -0
+		_ = buf.WriteByte('0')
+		return nil
+	}
+
+	if f < 0 {
+		_ = buf.WriteByte('-')
+		f = -f
+	}
+
+	// shortest digits which round trip, in d.ddddde±xx format
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+
+	// value = 0.digits * 10^n
+	n, k := e+1, len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		_, _ = buf.WriteString(digits)
+		_, _ = buf.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		_, _ = buf.WriteString(digits[:n])
+		_ = buf.WriteByte('.')
+		_, _ = buf.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		_, _ = buf.WriteString("0.")
+		_, _ = buf.WriteString(strings.Repeat("0", -n))
+		_, _ = buf.WriteString(digits)
+	default:
+		_ = buf.WriteByte(digits[0])
+		if k > 1 {
+			_ = buf.WriteByte('.')
+			_, _ = buf.WriteString(digits[1:])
+		}
+		_ = buf.WriteByte('e')
+		if n-1 > 0 {
+			_ = buf.WriteByte('+')
+		}
+		_, _ = buf.WriteString(strconv.Itoa(n - 1))
+	}
+
+	return nil
+}
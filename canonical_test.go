@@ -0,0 +1,208 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+// Test vectors from RFC 8785, Appendix B.
+var canonicalNumberTests = []struct {
+	bits     uint64
+	excepted string
+}{
+	{0x0000000000000000, "0"},
+	{0x8000000000000000, "0"},
+	{0x0000000000000001, "5e-324"},
+	{0x8000000000000001, "-5e-324"},
+	{0x7fefffffffffffff, "1.7976931348623157e+308"},
+	{0xffefffffffffffff, "-1.7976931348623157e+308"},
+	{0x4340000000000000, "9007199254740992"},
+	{0xc340000000000000, "-9007199254740992"},
+	{0x4430000000000000, "295147905179352830000"},
+	{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+	{0x44b52d02c7e14af6, "1e+23"},
+	{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+	{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+	{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+	{0x444b1ae4d6e2ef50, "1e+21"},
+	{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+	{0x3eb0c6f7a0b5ed8d, "0.000001"},
+	{0x41b3de4355555553, "333333333.3333332"},
+	{0x41b3de4355555554, "333333333.33333325"},
+	{0x41b3de4355555555, "333333333.3333333"},
+	{0x41b3de4355555556, "333333333.3333334"},
+	{0x41b3de4355555557, "333333333.33333343"},
+	{0xbecbf647612f3696, "-0.0000033333333333333333"},
+	{0x43143ff3c1cb0959, "1424953923781206.2"},
+}
+
+func TestMarshalCanonical_Numbers(t *testing.T) {
+	for _, test := range canonicalNumberTests {
+		output, err := geko.MarshalCanonical(math.Float64frombits(test.bits))
+		if err != nil {
+			t.Fatalf("MarshalCanonical %016x with error: %s", test.bits, err.Error())
+		}
+		if string(output) != test.excepted {
+			t.Fatalf("MarshalCanonical %016x result not correct: %s, excepted %s", test.bits, output, test.excepted)
+		}
+	}
+
+	for _, bits := range []uint64{0x7fffffffffffffff, 0x7ff0000000000000, 0xfff0000000000000} {
+		if _, err := geko.MarshalCanonical(math.Float64frombits(bits)); err == nil {
+			t.Fatalf("MarshalCanonical %016x should fail", bits)
+		}
+	}
+}
+
+func TestMarshalCanonical_JSONNumber(t *testing.T) {
+	output, err := geko.MarshalCanonical([]any{json.Number("1E30"), json.Number("4.50"), json.Number("-0.0")})
+	if err != nil || string(output) != `[1e+30,4.5,0]` {
+		t.Fatalf("MarshalCanonical result not correct: %s, %#v", output, err)
+	}
+
+	if _, err := geko.MarshalCanonical(json.Number("1e400")); err == nil {
+		t.Fatalf("MarshalCanonical should fail on out of range number")
+	}
+}
+
+// Example from RFC 8785, Section 3.2.2.
+func TestMarshalCanonical_Example(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{
+		"numbers": [333333333.33333329, 1E30, 4.50,
+					2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`))
+
+	output, err := geko.MarshalCanonical(v)
+	if err != nil {
+		t.Fatalf("MarshalCanonical with error: %s", err.Error())
+	}
+
+	excepted := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],` +
+		`"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	if string(output) != excepted {
+		t.Fatalf("MarshalCanonical result not correct: %s", output)
+	}
+}
+
+// Example from RFC 8785, Section 3.2.3.
+func TestMarshalCanonical_Sorting(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{
+		"\u20ac": "Euro Sign",
+		"\r": "Carriage Return",
+		"\ufb33": "Hebrew Letter Dalet With Dagesh",
+		"1": "One",
+		"\ud83d\ude00": "Emoji: Grinning Face",
+		"\u0080": "Control",
+		"\u00f6": "Latin Small Letter O With Diaeresis"
+	}`), geko.UseObject())
+
+	output, err := geko.MarshalCanonical(v)
+	if err != nil {
+		t.Fatalf("MarshalCanonical with error: %s", err.Error())
+	}
+
+	excepted := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\"," +
+		"\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\"," +
+		"\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	if string(output) != excepted {
+		t.Fatalf("MarshalCanonical result not correct: %s", output)
+	}
+}
+
+func TestMarshalCanonical_StdTypes(t *testing.T) {
+	v := map[string]any{
+		"b": []any{"\b\f\t\u001f<>&\u2028"},
+		"a": nil,
+		"c": 1.5,
+	}
+
+	output, err := geko.MarshalCanonical(v)
+	if err != nil || string(output) != `{"a":null,"b":["\b\f\t\u001f<>&`+"\u2028"+`"],"c":1.5}` {
+		t.Fatalf("MarshalCanonical result not correct: %s, %#v", output, err)
+	}
+}
+
+func TestMarshalCanonical_OtherTypes(t *testing.T) {
+	m := geko.NewMap[string, int]()
+	m.Set("b", 1)
+	m.Set("a", 2)
+
+	type S struct {
+		Z int
+		Y *big.Int
+	}
+
+	output, err := geko.MarshalCanonical([]any{m, S{Z: 1, Y: big.NewInt(100)}, int64(1) << 60, true})
+	if err != nil || string(output) != `[{"a":2,"b":1},{"Y":100,"Z":1},1152921504606847000,true]` {
+		t.Fatalf("MarshalCanonical result not correct: %s, %#v", output, err)
+	}
+
+	if _, err = geko.MarshalCanonical(make(chan int)); err == nil {
+		t.Fatalf("MarshalCanonical should fail on unsupported type")
+	}
+}
+
+func TestMarshalCanonical_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+	var stdMap map[string]any
+	var stdSlice []any
+
+	output, err := geko.MarshalCanonical([]any{m, ps, l, stdMap, stdSlice})
+	if err != nil || string(output) != `[null,null,null,null,null]` {
+		t.Fatalf("MarshalCanonical result not correct: %s, %#v", output, err)
+	}
+}
+
+func TestMarshalCanonical_EmptyArray(t *testing.T) {
+	value, err := geko.JSONUnmarshal([]byte(`{"a":[],"b":[[]]}`))
+	if err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	output, err := geko.MarshalCanonical([]any{value, geko.NewList[any](), geko.NewListFrom([]any{})})
+	if err != nil || string(output) != `[{"a":[],"b":[[]]},[],[]]` {
+		t.Fatalf("MarshalCanonical result not correct: %s, %#v", output, err)
+	}
+}
+
+func TestMarshalCanonical_DuplicateKey(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"b": 1, "a": 2, "b": 3}`))
+
+	_, err := geko.MarshalCanonical(geko.NewListFrom([]any{v}))
+
+	var dupErr *geko.DuplicateKeyError
+	if !errors.As(err, &dupErr) || dupErr.Key != "b" || dupErr.Index != 2 || dupErr.Offset != -1 {
+		t.Fatalf("MarshalCanonical should fail on duplicated key: %#v", err)
+	}
+
+	if err.Error() != `geko: duplicated key "b" at index 2` {
+		t.Fatalf("DuplicateKeyError message not correct: %s", err.Error())
+	}
+}
+
+func TestMarshalCanonical_Error(t *testing.T) {
+	if _, err := geko.MarshalCanonical("\xff"); err == nil {
+		t.Fatalf("MarshalCanonical should fail on invalid UTF-8 string")
+	}
+
+	ps := geko.NewPairs[string, any]()
+	ps.Add("a", math.NaN())
+	if _, err := geko.MarshalCanonical(ps); err == nil {
+		t.Fatalf("MarshalCanonical should fail on invalid value")
+	}
+
+	ps = geko.NewPairs[string, any]()
+	ps.Add("\xff", 1)
+	if _, err := geko.MarshalCanonical(ps); err == nil {
+		t.Fatalf("MarshalCanonical should fail on invalid key")
+	}
+}
@@ -0,0 +1,931 @@
+package geko
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Major types of CBOR data items.
+const (
+	cborUnsigned byte = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+const (
+	cborFalse      = 0xf4
+	cborTrue       = 0xf5
+	cborNull       = 0xf6
+	cborBreak      = 0xff
+	cborIndefinite = 31
+)
+
+// Tag numbers with special meaning.
+const (
+	cborTagDateTime       = 0
+	cborTagEpochDateTime  = 1
+	cborTagPositiveBignum = 2
+	cborTagNegativeBignum = 3
+)
+
+var cborMajorNames = [...]string{
+	"unsigned integer", "negative integer", "byte string", "text string",
+	"array", "map", "tag", "simple value",
+}
+
+// CBORTag is a CBOR tagged data item, whose tag number has no special meaning
+// in this package.
+type CBORTag struct {
+	// Number is the tag number.
+	Number uint64
+	// Content is the decoded tag content.
+	Content any
+}
+
+// CBORMarshal encodes v into [RFC 8949] CBOR data item:
+//
+//   - [Map], [Pairs] and map[string]any are maps, members are written in their
+//     order, members of a map[string]any are sorted. [List] and []any are
+//     arrays.
+//   - Numbers can be float64, float32, json.Number, *big.Int and Go integer
+//     types. Integers are written as integers, or bignums if they don't fit,
+//     floats are written in the shortest form which keeps their value.
+//   - []byte is byte string, time.Time is a standard date/time string (tag 0),
+//     and [CBORTag] is written as is.
+//   - Other values are converted like [FromStruct] does, so structs are maps.
+//
+// [RFC 8949]: https://www.rfc-editor.org/rfc/rfc8949
+func CBORMarshal(v any) ([]byte, error) {
+	e := cborEncoder{}
+	if err := e.value(v); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// CBORUnmarshal decodes a CBOR data item, like [JSONUnmarshal]:
+//
+//   - Maps are decoded into [Object] or [ObjectItems], keys must be text
+//     strings. Arrays are decoded into [Array].
+//   - Integers and floats are numbers, converted by options like JSON.
+//   - Byte strings are []byte, standard and epoch date/time (tag 0 and 1) are
+//     time.Time, bignums (tag 2 and 3) are *big.Int, other tags are [CBORTag].
+//   - Null and undefined are nil.
+//
+// Supported options are [UseNumber], [UseInt64], [UseObject],
+// [UseObjectItems], [ObjectOnDuplicatedKey], [RecordDuplicates] and
+// [MaxDepth], others are ignored. A [*CBORError] is returned for invalid or
+// trailing data. Arrays, maps and tags count as nesting levels, nesting deeper
+// than MaxDepth, or 10000 levels anyway, fails with a [LimitExceededError].
+func CBORUnmarshal(data []byte, option ...DecodeOption) (any, error) {
+	d := cborDecoder{data: data, opts: CreateDecodeOptions(option...)}
+
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	return v, d.end()
+}
+
+// cborEncodable is implemented by types in this package, to encode themselves
+// into CBOR directly, like [jsonEncodable] does for JSON.
+type cborEncodable interface {
+	cborEncode(e *cborEncoder) error
+}
+
+type cborEncoder struct {
+	buf []byte
+}
+
+// head writes the initial bytes of a data item.
+func (e *cborEncoder) head(major byte, n uint64) {
+	switch {
+	case n < 24:
+		e.buf = append(e.buf, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, major<<5|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, major<<5|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		e.buf = append(e.buf, major<<5|27)
+		for shift := 56; shift >= 0; shift -= 8 {
+			e.buf = append(e.buf, byte(n>>shift))
+		}
+	}
+}
+
+func (e *cborEncoder) value(v any) error {
+	switch x := v.(type) {
+	case cborEncodable:
+		return x.cborEncode(e)
+	case nil:
+		e.buf = append(e.buf, cborNull)
+	case bool:
+		if x {
+			e.buf = append(e.buf, cborTrue)
+		} else {
+			e.buf = append(e.buf, cborFalse)
+		}
+	case string:
+		e.head(cborText, uint64(len(x)))
+		e.buf = append(e.buf, x...)
+	case []byte:
+		e.bytes(x)
+	case float64:
+		e.float(x)
+	case float32:
+		e.float(float64(x))
+	case json.Number:
+		return e.number(x)
+	case *big.Int:
+		e.bigInt(x)
+	case time.Time:
+		e.head(cborTag, cborTagDateTime)
+		return e.value(x.Format(time.RFC3339Nano))
+	case CBORTag:
+		e.head(cborTag, x.Number)
+		return e.value(x.Content)
+	case map[string]any:
+		members, _ := objectMembers(x)
+		e.head(cborMap, uint64(len(members)))
+		for _, pair := range members {
+			if err := e.member(pair.Key, pair.Value); err != nil {
+				return err
+			}
+		}
+	case []any:
+		return e.array(x)
+	default:
+		return e.reflectValue(v)
+	}
+
+	return nil
+}
+
+func (e *cborEncoder) bytes(data []byte) {
+	e.head(cborBytes, uint64(len(data)))
+	e.buf = append(e.buf, data...)
+}
+
+func (e *cborEncoder) member(key, value any) error {
+	if err := e.value(key); err != nil {
+		return err
+	}
+	return e.value(value)
+}
+
+func (e *cborEncoder) array(items []any) error {
+	e.head(cborArray, uint64(len(items)))
+	for _, item := range items {
+		if err := e.value(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *cborEncoder) reflectValue(v any) error {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.head(cborUnsigned, rv.Uint())
+	default:
+		converted, err := fromReflectValue(rv)
+		if err != nil {
+			return err
+		}
+		return e.value(converted)
+	}
+
+	return nil
+}
+
+func (e *cborEncoder) int(n int64) {
+	if n >= 0 {
+		e.head(cborUnsigned, uint64(n))
+	} else {
+		e.head(cborNegative, uint64(-1-n))
+	}
+}
+
+func (e *cborEncoder) number(n json.Number) error {
+	s := string(n)
+
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			e.int(i)
+			return nil
+		}
+
+		if i, ok := new(big.Int).SetString(s, 10); ok {
+			e.bigInt(i)
+			return nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("geko: invalid number literal %q", s)
+	}
+
+	e.float(f)
+	return nil
+}
+
+func (e *cborEncoder) bigInt(i *big.Int) {
+	if i.IsUint64() {
+		e.head(cborUnsigned, i.Uint64())
+		return
+	}
+
+	if i.Sign() >= 0 {
+		e.head(cborTag, cborTagPositiveBignum)
+		e.bytes(i.Bytes())
+		return
+	}
+
+	// negative integer n is encoded as -1-n
+	n := new(big.Int).Sub(big.NewInt(-1), i)
+	if n.IsUint64() {
+		e.head(cborNegative, n.Uint64())
+		return
+	}
+
+	e.head(cborTag, cborTagNegativeBignum)
+	e.bytes(n.Bytes())
+}
+
+// float writes f in the shortest form which keeps its value.
+func (e *cborEncoder) float(f float64) {
+	if h, ok := float16Bits(f); ok {
+		e.buf = append(e.buf, cborSimple<<5|25, byte(h>>8), byte(h))
+		return
+	}
+
+	if float64(float32(f)) == f {
+		bits := math.Float32bits(float32(f))
+		e.buf = append(e.buf, cborSimple<<5|26, byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+		return
+	}
+
+	bits := math.Float64bits(f)
+	e.buf = append(e.buf, cborSimple<<5|27)
+	for shift := 56; shift >= 0; shift -= 8 {
+		e.buf = append(e.buf, byte(bits>>shift))
+	}
+}
+
+// float16Bits returns the half-precision bits of f, if no precision is lost.
+func float16Bits(f float64) (uint16, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0x7e00, true
+	case math.IsInf(f, 1):
+		return 0x7c00, true
+	case math.IsInf(f, -1):
+		return 0xfc00, true
+	}
+
+	f32 := float32(f)
+	if float64(f32) != f {
+		return 0, false
+	}
+
+	bits := math.Float32bits(f32)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mantissa := bits & 0x7fffff
+
+	switch {
+	case f == 0:
+		return sign, true
+	case exp >= -14 && exp <= 15:
+		if mantissa&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(exp+15)<<10 | uint16(mantissa>>13), true
+	case exp >= -24 && exp < -14:
+		// subnormal, value is m * 2^-24
+		full := mantissa | 0x800000
+		shift := uint(-exp - 1)
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	default:
+		return 0, false
+	}
+}
+
+func float16ToFloat64(h uint16) float64 {
+	exp := int(h >> 10 & 0x1f)
+	mantissa := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mantissa+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		f = -f
+	}
+
+	return f
+}
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+	opts   DecodeOptions
+	// depth is the nesting depth of arrays, maps and tags being decoded.
+	depth int
+}
+
+func (d *cborDecoder) error(offset int, format string, args ...any) error {
+	return &CBORError{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
+
+// end checks there is no trailing data.
+func (d *cborDecoder) end() error {
+	if d.offset < len(d.data) {
+		return d.error(d.offset, "trailing data")
+	}
+	return nil
+}
+
+// head reads the initial bytes of a data item. n is the argument, info is the
+// additional information, which is 31 for indefinite length.
+func (d *cborDecoder) head() (major, info byte, n uint64, err error) {
+	if d.offset >= len(d.data) {
+		return 0, 0, 0, d.error(d.offset, "unexpected end of data")
+	}
+
+	start := d.offset
+	major, info = d.data[start]>>5, d.data[start]&0x1f
+	d.offset++
+
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info < 28:
+		size := 1 << (info - 24)
+		if d.offset+size > len(d.data) {
+			return 0, 0, 0, d.error(start, "unexpected end of data")
+		}
+		for _, b := range d.data[d.offset : d.offset+size] {
+			n = n<<8 | uint64(b)
+		}
+		d.offset += size
+	case info == cborIndefinite && major != cborUnsigned && major != cborNegative && major != cborTag:
+	default:
+		return 0, 0, 0, d.error(start, "invalid additional information %d", info)
+	}
+
+	return major, info, n, nil
+}
+
+// enter should be called when meet the start of an array, map or tag, it
+// checks the nesting depth.
+func (d *cborDecoder) enter(start int) error {
+	if limit := d.opts.formatDepthLimit(); d.depth >= limit {
+		return &LimitExceededError{Kind: DepthLimit, Limit: int64(limit), Offset: int64(start)}
+	}
+	d.depth++
+	return nil
+}
+
+// isBreak consumes the break code of indefinite length items, if any.
+func (d *cborDecoder) isBreak() bool {
+	if d.offset < len(d.data) && d.data[d.offset] == cborBreak {
+		d.offset++
+		return true
+	}
+	return false
+}
+
+// length checks the count of following data items or bytes, each of them
+// takes at least one byte, so it can't exceed the rest of data.
+func (d *cborDecoder) length(start int, n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.offset) {
+		return 0, d.error(start, "unexpected end of data")
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) value() (any, error) {
+	start := d.offset
+
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		return d.unsigned(n), nil
+	case cborNegative:
+		return d.negative(n), nil
+	case cborBytes:
+		return d.bytes(start, major, info, n)
+	case cborText:
+		data, err := d.bytes(start, major, info, n)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(data) {
+			return nil, d.error(start, "invalid UTF-8 in text string")
+		}
+		return string(data), nil
+	case cborArray, cborMap, cborTag:
+		return d.nested(start, major, info, n)
+	default:
+		return d.simple(start, info, n)
+	}
+}
+
+func (d *cborDecoder) nested(start int, major, info byte, n uint64) (any, error) {
+	if err := d.enter(start); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	switch major {
+	case cborArray:
+		return d.array(start, info, n)
+	case cborMap:
+		return d.object(start, info, n)
+	default:
+		return d.tag(start, n)
+	}
+}
+
+func (d *cborDecoder) unsigned(n uint64) any {
+	switch {
+	case d.opts.useNumber:
+		return json.Number(strconv.FormatUint(n, 10))
+	case d.opts.useInt64 && n <= math.MaxInt64:
+		return int64(n)
+	default:
+		return float64(n)
+	}
+}
+
+// negative converts the argument n of a negative integer, which is -1-n.
+func (d *cborDecoder) negative(n uint64) any {
+	if n > math.MaxInt64 {
+		if d.opts.useNumber {
+			i := new(big.Int).SetUint64(n)
+			return json.Number(i.Sub(big.NewInt(-1), i).String())
+		}
+		return -1 - float64(n)
+	}
+
+	i := -1 - int64(n)
+	switch {
+	case d.opts.useNumber:
+		return json.Number(strconv.FormatInt(i, 10))
+	case d.opts.useInt64:
+		return i
+	default:
+		return float64(i)
+	}
+}
+
+func (d *cborDecoder) float(f float64, bitSize int) any {
+	if d.opts.useNumber && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return json.Number(strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+	return f
+}
+
+// bytes reads content of byte string and text string, indefinite length ones
+// are concatenated from chunks.
+func (d *cborDecoder) bytes(start int, major, info byte, n uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		size, err := d.length(start, n)
+		if err != nil {
+			return nil, err
+		}
+		data := append([]byte{}, d.data[d.offset:d.offset+size]...)
+		d.offset += size
+		return data, nil
+	}
+
+	data := []byte{}
+	for !d.isBreak() {
+		chunkStart := d.offset
+		chunkMajor, chunkInfo, chunkSize, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == cborIndefinite {
+			return nil, d.error(chunkStart, "invalid chunk of indefinite length %s", cborMajorNames[major])
+		}
+		chunk, err := d.bytes(chunkStart, chunkMajor, chunkInfo, chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+
+	return data, nil
+}
+
+func (d *cborDecoder) array(start int, info byte, n uint64) (Array, error) {
+	// n is 0 for indefinite length
+	size, err := d.length(start, n)
+	if err != nil {
+		return nil, err
+	}
+
+	array := NewListWithCapacity[any](size)
+	for i := 0; info == cborIndefinite || i < size; i++ {
+		if info == cborIndefinite && d.isBreak() {
+			break
+		}
+
+		item, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		array.Append(item)
+	}
+
+	return array, nil
+}
+
+func (d *cborDecoder) object(start int, info byte, n uint64) (any, error) {
+	if d.opts.useObject {
+		object := NewMap[string, any]()
+		object.SetDuplicatedKeyStrategy(d.opts.duplicatedKeyStrategy)
+		object.SetRecordDuplicates(d.opts.recordDuplicates)
+		return object, decodeCBORMembers[string, any](d, start, info, n, object)
+	}
+
+	items := NewPairs[string, any]()
+	return items, decodeCBORMembers[string, any](d, start, info, n, items)
+}
+
+func (d *cborDecoder) tag(start int, number uint64) (any, error) {
+	content, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	switch number {
+	case cborTagDateTime:
+		if s, isString := content.(string); isString {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t, nil
+			}
+		}
+	case cborTagEpochDateTime:
+		if text, isNumber := numberText(content); isNumber {
+			seconds, err := strconv.ParseFloat(text, 64)
+			if err == nil && !math.IsInf(seconds, 0) && !math.IsNaN(seconds) {
+				integer, fraction := math.Modf(seconds)
+				return time.Unix(int64(integer), int64(fraction*1e9)).UTC(), nil
+			}
+		}
+	case cborTagPositiveBignum, cborTagNegativeBignum:
+		if data, isBytes := content.([]byte); isBytes {
+			i := new(big.Int).SetBytes(data)
+			if number == cborTagNegativeBignum {
+				i.Sub(big.NewInt(-1), i)
+			}
+			return i, nil
+		}
+	default:
+		return CBORTag{Number: number, Content: content}, nil
+	}
+
+	return nil, d.error(start, "invalid content of tag %d", number)
+}
+
+func (d *cborDecoder) simple(start int, info byte, n uint64) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null and undefined
+		return nil, nil
+	case 25: // half-precision float
+		return d.float(float16ToFloat64(uint16(n)), 32), nil
+	case 26: // single-precision float
+		return d.float(float64(math.Float32frombits(uint32(n))), 32), nil
+	case 27: // double-precision float
+		return d.float(math.Float64frombits(n), 64), nil
+	case cborIndefinite:
+		return nil, d.error(start, "unexpected break")
+	default:
+		return nil, d.error(start, "unsupported simple value")
+	}
+}
+
+// decodeCBORMap decodes a map into object.
+func decodeCBORMap[K comparable, V any](d *cborDecoder, object memberAdder[K, V]) error {
+	start := d.offset
+
+	major, info, n, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	if major != cborMap {
+		return d.error(start, "cannot unmarshal %s into %T", cborMajorNames[major], object)
+	}
+
+	d.depth++ // the top-level container
+
+	return decodeCBORMembers[K, V](d, start, info, n, object)
+}
+
+func decodeCBORMembers[K comparable, V any](
+	d *cborDecoder, start int, info byte, n uint64, object memberAdder[K, V],
+) error {
+	size, err := d.length(start, n)
+	if err != nil {
+		return err
+	}
+
+	keyIsString := isString[K]()
+
+	for i := 0; info == cborIndefinite || i < size; i++ {
+		if info == cborIndefinite && d.isBreak() {
+			break
+		}
+
+		if keyIsString && d.offset < len(d.data) && d.data[d.offset]>>5 != cborText {
+			return d.error(d.offset, "map key must be text string")
+		}
+
+		key, err := decodeCBORItem[K](d)
+		if err != nil {
+			return err
+		}
+
+		value, err := decodeCBORItem[V](d)
+		if err != nil {
+			return err
+		}
+
+		object.Add(key, value)
+	}
+
+	return nil
+}
+
+// decodeCBORItem decodes a data item into T. If T is not any, the item is
+// decoded with numbers kept as json.Number, and assigned to T like
+// [ToStruct] does.
+func decodeCBORItem[T any](d *cborDecoder) (T, error) {
+	var item T
+
+	if isEmptyInterface[T]() {
+		v, err := d.value()
+		item, _ = v.(T)
+		return item, err
+	}
+
+	typed := cborDecoder{data: d.data, offset: d.offset, opts: CreateDecodeOptions(UseObject(), UseNumber(true))}
+	typed.opts.maxDepth = d.opts.maxDepth
+	typed.depth = d.depth
+	v, err := typed.value()
+	d.offset = typed.offset
+	if err != nil {
+		return item, err
+	}
+
+	return bindValue[T](v)
+}
+
+// MarshalCBOR implements Marshaler interface of [github.com/fxamacker/cbor],
+// see [CBORMarshal] for how members are encoded. The map is encoded as a CBOR
+// map, in the insertion order.
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (m *Map[K, V]) MarshalCBOR() ([]byte, error) {
+	return CBORMarshal(m)
+}
+
+func (m *Map[K, V]) cborEncode(e *cborEncoder) error {
+	if m == nil {
+		return e.value(nil)
+	}
+
+	e.head(cborMap, uint64(m.Len()))
+	for i, length := 0, m.Len(); i < length; i++ {
+		pair := m.GetByIndex(i)
+		if err := e.member(pair.Key, pair.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCBOR implements Unmarshaler interface of
+// [github.com/fxamacker/cbor]. The input must be a CBOR map, its members are
+// added in order, and duplicated keys are dealt with the
+// [DuplicatedKeyStrategy] of the map.
+//
+// If the value type is any, nested maps and arrays are decoded into [Object]
+// and [Array], see [CBORUnmarshal] for other values. Otherwise, values are
+// decoded like that, then assigned like [ToStruct] does, so does the key.
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (m *Map[K, V]) UnmarshalCBOR(data []byte) error {
+	if err := m.checkFrozen("UnmarshalCBOR"); err != nil {
+		return err
+	}
+
+	d := cborDecoder{data: data, opts: CreateDecodeOptions(
+		UseObject(),
+		ObjectOnDuplicatedKey(m.duplicatedKeyStrategy),
+		RecordDuplicates(m.RecordDuplicates()),
+	)}
+
+	if err := decodeCBORMap[K, V](&d, m); err != nil {
+		return err
+	}
+
+	return d.end()
+}
+
+// MarshalCBOR implements Marshaler interface of [github.com/fxamacker/cbor].
+// The pairs are encoded as a CBOR map, in their order, duplicated keys are all
+// kept.
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (ps *Pairs[K, V]) MarshalCBOR() ([]byte, error) {
+	return CBORMarshal(ps)
+}
+
+func (ps *Pairs[K, V]) cborEncode(e *cborEncoder) error {
+	if ps == nil {
+		return e.value(nil)
+	}
+
+	e.head(cborMap, uint64(len(ps.List)))
+	for _, pair := range ps.List {
+		if err := e.member(pair.Key, pair.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCBOR implements Unmarshaler interface of
+// [github.com/fxamacker/cbor]. The input must be a CBOR map, its members are
+// added in order, all values of duplicated keys are kept.
+//
+// If the value type is any, nested maps and arrays are decoded into
+// [ObjectItems] and [Array]. See [Map.UnmarshalCBOR] for other values.
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (ps *Pairs[K, V]) UnmarshalCBOR(data []byte) error {
+	if err := ps.checkFrozen("UnmarshalCBOR"); err != nil {
+		return err
+	}
+
+	d := cborDecoder{data: data, opts: CreateDecodeOptions(UseObjectItems())}
+
+	if err := decodeCBORMap[K, V](&d, ps); err != nil {
+		return err
+	}
+
+	return d.end()
+}
+
+// MarshalCBOR implements Marshaler interface of [github.com/fxamacker/cbor].
+// The list is encoded as a CBOR array.
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (l *List[T]) MarshalCBOR() ([]byte, error) {
+	return CBORMarshal(l)
+}
+
+func (l *List[T]) cborEncode(e *cborEncoder) error {
+	if l == nil {
+		return e.value(nil)
+	}
+
+	e.head(cborArray, uint64(len(l.List)))
+	for _, item := range l.List {
+		if err := e.value(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalCBOR implements Unmarshaler interface of
+// [github.com/fxamacker/cbor]. The input must be a CBOR array.
+//
+// If the item type is any, nested maps and arrays are decoded with the decode
+// options of the list, like [List.UnmarshalJSON]. See [CBORUnmarshal] for
+// supported options, and [Map.UnmarshalCBOR] for other item types.
+//
+// The inner slice is replaced, unless [List.AppendOnUnmarshal] is enabled.
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (l *List[T]) UnmarshalCBOR(data []byte) error {
+	if err := l.checkFrozen("UnmarshalCBOR"); err != nil {
+		return err
+	}
+
+	d := cborDecoder{data: data, opts: l.decodeOptions}
+
+	major, info, n, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	if major != cborArray {
+		return d.error(0, "cannot unmarshal %s into %T", cborMajorNames[major], l)
+	}
+
+	d.depth++ // the top-level container
+
+	size, err := d.length(0, n)
+	if err != nil {
+		return err
+	}
+
+	items := make([]T, 0, size)
+	for i := 0; info == cborIndefinite || i < size; i++ {
+		if info == cborIndefinite && d.isBreak() {
+			break
+		}
+
+		item, err := decodeCBORItem[T](&d)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	if err = d.end(); err != nil {
+		return err
+	}
+
+	l.storeDecoded(items)
+
+	return nil
+}
+
+// MarshalCBOR implements Marshaler interface of [github.com/fxamacker/cbor].
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (v Any) MarshalCBOR() ([]byte, error) {
+	return CBORMarshal(v.Value)
+}
+
+// UnmarshalCBOR implements Unmarshaler interface of
+// [github.com/fxamacker/cbor]. Maps and arrays are decoded with Any.Opts, like
+// [CBORUnmarshal].
+//
+// [github.com/fxamacker/cbor]: https://pkg.go.dev/github.com/fxamacker/cbor/v2
+func (v *Any) UnmarshalCBOR(data []byte) error {
+	d := cborDecoder{data: data, opts: v.Opts}
+
+	value, err := d.value()
+	if err == nil {
+		err = d.end()
+	}
+
+	if err == nil {
+		v.Value = value
+	}
+	return err
+}
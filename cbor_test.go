@@ -0,0 +1,484 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Invalid hex %q: %s", s, err.Error())
+	}
+
+	return data
+}
+
+// examples in Appendix A of RFC 8949
+var cborTestExamples = []struct {
+	hex      string
+	excepted string
+}{
+	{"00", `0`},
+	{"17", `23`},
+	{"1818", `24`},
+	{"1903e8", `1000`},
+	{"1b000000e8d4a51000", `1000000000000`},
+	{"1bffffffffffffffff", `18446744073709551615`},
+	{"20", `-1`},
+	{"3903e7", `-1000`},
+	{"3bffffffffffffffff", `-18446744073709551616`},
+	{"c249010000000000000000", `18446744073709551616`},
+	{"c349010000000000000000", `-18446744073709551617`},
+	{"f90000", `0`},
+	{"f93c00", `1`},
+	{"f97bff", `65504`},
+	{"fa47c35000", `100000`},
+	{"fb3ff199999999999a", `1.1`},
+	{"f90001", `5.9604645e-08`},
+	{"f9c400", `-4`},
+	{"fb7e37e43c8800759c", `1e+300`},
+	{"f4", `false`},
+	{"f5", `true`},
+	{"f6", `null`},
+	{"f7", `null`},
+	{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+	{"c11a514b67b0", `"2013-03-21T20:04:00Z"`},
+	{"c1fb41d452d9ec200000", `"2013-03-21T20:04:00.5Z"`},
+	{"d74401020304", `{"Number":23,"Content":"AQIDBA=="}`},
+	{"4401020304", `"AQIDBA=="`},
+	{"60", `""`},
+	{"6449455446", `"IETF"`},
+	{"62225c", `"\"\\"`},
+	{"63e6b0b4", `"水"`},
+	{"80", `[]`},
+	{"8301820203820405", `[1,[2,3],[4,5]]`},
+	{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+	{"826161a161626163", `["a",{"b":"c"}]`},
+	{"5f42010243030405ff", `"AQIDBAU="`},
+	{"7f657374726561646d696e67ff", `"streaming"`},
+	{"9fff", `[]`},
+	{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+	{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+}
+
+func TestCBORUnmarshal(t *testing.T) {
+	for _, example := range cborTestExamples {
+		v, err := geko.CBORUnmarshal(mustHex(t, example.hex), geko.UseObject(), geko.UseNumber(true))
+		if err != nil {
+			t.Fatalf("CBORUnmarshal %s with error: %s", example.hex, err.Error())
+		}
+
+		output, _ := json.Marshal(v)
+		if string(output) != example.excepted {
+			t.Fatalf("CBORUnmarshal %s result not correct: %s", example.hex, string(output))
+		}
+	}
+}
+
+func TestCBORUnmarshal_Numbers(t *testing.T) {
+	data := mustHex(t, "8601201bffffffffffffffff3bfffffffffffffffffa3fc00000f97e00")
+
+	v, _ := geko.CBORUnmarshal(data)
+	output, _ := json.Marshal(v.(geko.Array).List[:5])
+	if string(output) != `[1,-1,18446744073709552000,-18446744073709552000,1.5]` {
+		t.Fatalf("CBORUnmarshal result not correct: %s", string(output))
+	}
+
+	v, _ = geko.CBORUnmarshal(data, geko.UseInt64(true))
+	items := v.(geko.Array).List
+	if items[0] != int64(1) || items[1] != int64(-1) || items[2] != float64(math.MaxUint64) {
+		t.Fatalf("CBORUnmarshal with UseInt64 result not correct: %#v", items)
+	}
+
+	v, _ = geko.CBORUnmarshal(data, geko.UseNumber(true))
+	items = v.(geko.Array).List
+	if items[4] != json.Number("1.5") || !math.IsNaN(items[5].(float64)) {
+		t.Fatalf("CBORUnmarshal with UseNumber result not correct: %#v", items)
+	}
+
+	v, _ = geko.CBORUnmarshal(mustHex(t, "f9fc00"))
+	if f, ok := v.(float64); !ok || !math.IsInf(f, -1) {
+		t.Fatalf("Half-precision infinity not correct: %#v", v)
+	}
+
+	v, _ = geko.CBORUnmarshal(mustHex(t, "c24101"))
+	if i, ok := v.(*big.Int); !ok || i.Int64() != 1 {
+		t.Fatalf("Bignum should be decoded into *big.Int: %#v", v)
+	}
+
+	v, _ = geko.CBORUnmarshal(mustHex(t, "c11a514b67b0"))
+	if tm, ok := v.(time.Time); !ok || !tm.Equal(time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)) {
+		t.Fatalf("Epoch date/time should be decoded into time.Time: %#v", v)
+	}
+}
+
+func TestCBORUnmarshal_DuplicatedKeys(t *testing.T) {
+	// {"a": 1, "b": 2, "a": 3}
+	data := mustHex(t, "a3616101616202616103")
+
+	v, err := geko.CBORUnmarshal(data)
+	if err != nil {
+		t.Fatalf("CBORUnmarshal with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(v)
+	if string(output) != `{"a":1,"b":2,"a":3}` {
+		t.Fatalf("CBORUnmarshal into ObjectItems should keep all keys: %s", string(output))
+	}
+
+	for strategy, excepted := range map[geko.DuplicatedKeyStrategy]string{
+		geko.UpdateValueKeepOrder:   `{"a":3,"b":2}`,
+		geko.UpdateValueUpdateOrder: `{"b":2,"a":3}`,
+		geko.KeepValueUpdateOrder:   `{"b":2,"a":1}`,
+		geko.Ignore:                 `{"a":1,"b":2}`,
+	} {
+		v, err = geko.CBORUnmarshal(data, geko.UseObject(), geko.ObjectOnDuplicatedKey(strategy))
+		if err != nil {
+			t.Fatalf("CBORUnmarshal with error: %s", err.Error())
+		}
+
+		output, _ = json.Marshal(v)
+		if string(output) != excepted {
+			t.Fatalf("CBORUnmarshal with strategy %d result not correct: %s", strategy, string(output))
+		}
+	}
+
+	object := geko.NewMap[string, int]()
+	object.SetDuplicatedKeyStrategy(geko.KeepValueUpdateOrder)
+	object.SetRecordDuplicates(true)
+	if err = object.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	output, _ = json.Marshal(object)
+	if string(output) != `{"b":2,"a":1}` || object.Duplicates().Len() != 1 {
+		t.Fatalf("UnmarshalCBOR result not correct: %s", string(output))
+	}
+
+	items := geko.NewPairs[string, any]()
+	if err = items.UnmarshalCBOR(data); err != nil || items.Len() != 3 {
+		t.Fatalf("UnmarshalCBOR should keep all keys: %#v, %#v", items, err)
+	}
+}
+
+func TestCBORUnmarshal_Invalid(t *testing.T) {
+	for _, data := range []string{
+		"", "18", "1c", "1f", "d8", "5f01ff", "5f5fffff", "5f41", "5f", "62ff", "61ff", "82", "8201", "9f01",
+		"a1", "a10101", "a161ff", "a16161", "bf6161", "c001", "c06161", "c16161", "c1fb7ff0000000000000", "c201", "c0",
+		"ff", "f820", "f0", "0000",
+	} {
+		v, err := geko.CBORUnmarshal(mustHex(t, data))
+		if err == nil {
+			t.Fatalf("CBORUnmarshal should fail on %s: %#v", data, v)
+		}
+
+		var cborErr *geko.CBORError
+		if !errors.As(err, &cborErr) {
+			t.Fatalf("CBORUnmarshal should return CBORError: %#v", err)
+		}
+	}
+}
+
+func checkCBORDepthLimit(t *testing.T, err error, limit, offset int64) {
+	t.Helper()
+
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Kind != geko.DepthLimit || limitErr.Limit != limit ||
+		limitErr.Offset != offset {
+		t.Fatalf("CBORUnmarshal should fail with depth limit %d at %d, got %#v", limit, offset, err)
+	}
+}
+
+func TestCBORUnmarshal_MaxDepth(t *testing.T) {
+	// 20M nested arrays and tags, the default limit avoids stack overflow
+	for _, head := range []byte{0x81, 0xc6} {
+		_, err := geko.CBORUnmarshal(append(bytes.Repeat([]byte{head}, 20_000_000), 0x00))
+		checkCBORDepthLimit(t, err, 10000, 10000)
+	}
+
+	if _, err := geko.CBORUnmarshal(mustHex(t, "81a1616181c600"), geko.MaxDepth(4)); err != nil {
+		t.Fatalf("CBORUnmarshal with error: %s", err.Error())
+	}
+
+	_, err := geko.CBORUnmarshal(mustHex(t, "81a1616181c600"), geko.MaxDepth(3))
+	checkCBORDepthLimit(t, err, 3, 5)
+
+	_, err = geko.CBORUnmarshal(mustHex(t, "8181818100"), geko.MaxDepth(20_000))
+	if err != nil {
+		t.Fatalf("CBORUnmarshal with error: %s", err.Error())
+	}
+
+	l := geko.NewList[any]()
+	l.SetDecodeOptions(geko.MaxDepth(2))
+	checkCBORDepthLimit(t, l.UnmarshalCBOR(mustHex(t, "81818100")), 2, 2)
+
+	typed := geko.NewList[[]any]()
+	typed.SetDecodeOptions(geko.MaxDepth(2))
+	checkCBORDepthLimit(t, typed.UnmarshalCBOR(mustHex(t, "81818100")), 2, 2)
+
+	deep := append([]byte{0xa1, 0x61, 0x61}, bytes.Repeat([]byte{0x81}, 20_000)...)
+	checkCBORDepthLimit(t, geko.NewMap[string, []any]().UnmarshalCBOR(append(deep, 0x00)), 10000, 10002)
+}
+
+func TestCBORError(t *testing.T) {
+	_, err := geko.CBORUnmarshal([]byte{0x82, 0x01, 0xff})
+	if err == nil || err.Error() != "geko: invalid cbor: unexpected break at offset 2" {
+		t.Fatalf("CBORError message not correct: %#v", err)
+	}
+}
+
+type cborTestStruct struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+func TestCBORMarshal(t *testing.T) {
+	bigPositive, _ := new(big.Int).SetString("18446744073709551616", 10)
+	bigNegative, _ := new(big.Int).SetString("-18446744073709551617", 10)
+
+	for _, c := range []struct {
+		value    any
+		excepted string
+	}{
+		{0, "00"},
+		{uint8(23), "17"},
+		{int64(24), "1818"},
+		{uint16(1000), "1903e8"},
+		{1000000, "1a000f4240"},
+		{uint64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{int8(-1), "20"},
+		{-1000, "3903e7"},
+		{big.NewInt(-1000), "3903e7"},
+		{big.NewInt(1000), "1903e8"},
+		{new(big.Int).SetUint64(math.MaxUint64), "1bffffffffffffffff"},
+		{new(big.Int).Sub(big.NewInt(-1), new(big.Int).SetUint64(math.MaxUint64)), "3bffffffffffffffff"},
+		{bigPositive, "c249010000000000000000"},
+		{bigNegative, "c349010000000000000000"},
+		{json.Number("-1"), "20"},
+		{json.Number("18446744073709551616"), "c249010000000000000000"},
+		{json.Number("1.5"), "f93e00"},
+		{json.Number("1e2"), "f95640"},
+		{0.0, "f90000"},
+		{math.Copysign(0, -1), "f98000"},
+		{1.0, "f93c00"},
+		{1.1, "fb3ff199999999999a"},
+		{65504.0, "f97bff"},
+		{100000.0, "fa47c35000"},
+		{3.4028234663852886e+38, "fa7f7fffff"},
+		{5.960464477539063e-8, "f90001"},
+		{0.00006103515625, "f90400"},
+		{1e-40, "fb37a16c262777579c"},
+		{float64(float32(1.0000001)), "fa3f800001"},
+		{8.940696716308594e-08, "fa33c00000"},
+		{-4.0, "f9c400"},
+		{float32(1.5), "f93e00"},
+		{math.Inf(1), "f97c00"},
+		{math.NaN(), "f97e00"},
+		{math.Inf(-1), "f9fc00"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"IETF", "6449455446"},
+		{time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c074323031332d30332d32315432303a30343a30305a"},
+		{geko.CBORTag{Number: 23, Content: []byte{1, 2, 3, 4}}, "d74401020304"},
+		{[]any{1, []any{2, 3}}, "8201820203"},
+		{map[string]any{"b": []any{2, 3}, "a": 1}, "a26161016162820203"},
+		{cborTestStruct{Name: "x", Size: 1}, "a2646e616d6561786473697a6501"},
+	} {
+		data, err := geko.CBORMarshal(c.value)
+		if err != nil {
+			t.Fatalf("CBORMarshal %#v with error: %s", c.value, err.Error())
+		}
+
+		if hex.EncodeToString(data) != c.excepted {
+			t.Fatalf("CBORMarshal %#v result not correct: %x", c.value, data)
+		}
+	}
+}
+
+type cborTestBadKey struct {
+	C chan int `json:"c"`
+}
+
+func TestCBORMarshal_Error(t *testing.T) {
+	object := geko.NewMap[string, any]()
+	object.Set("a", func() {})
+
+	items := geko.NewPairs[string, any]()
+	items.Add("a", func() {})
+
+	badKey := geko.NewMap[cborTestBadKey, int]()
+	badKey.Set(cborTestBadKey{}, 1)
+
+	for _, v := range []any{
+		func() {},
+		json.Number("x"),
+		[]any{func() {}},
+		map[string]any{"a": func() {}},
+		geko.CBORTag{Number: 1, Content: func() {}},
+		object,
+		items,
+		geko.NewListFrom([]any{func() {}}),
+		badKey,
+	} {
+		if data, err := geko.CBORMarshal(v); err == nil {
+			t.Fatalf("CBORMarshal should fail: %x", data)
+		}
+	}
+}
+
+func TestCBOR_RoundTrip(t *testing.T) {
+	v, _ := geko.JSONUnmarshal(
+		[]byte(`{"z": 1, "a": [1.5, "x", {"n": null, "m": true, "b": {}}], "k": {"y": [], "x": -2}}`),
+		geko.UseObject(),
+	)
+	object := v.(geko.Object)
+
+	data, err := object.MarshalCBOR()
+	if err != nil {
+		t.Fatalf("MarshalCBOR with error: %s", err.Error())
+	}
+
+	decoded := geko.NewMap[string, any]()
+	if err = decoded.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	before, _ := json.Marshal(object)
+	after, _ := json.Marshal(decoded)
+	if string(before) != string(after) {
+		t.Fatalf("CBOR round trip result not correct: %s", string(after))
+	}
+
+	items := geko.NewPairs[string, any]()
+	if err = items.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	after, _ = json.Marshal(items)
+	if string(before) != string(after) {
+		t.Fatalf("CBOR round trip result not correct: %s", string(after))
+	}
+
+	itemsData, _ := items.MarshalCBOR()
+	if string(itemsData) != string(data) {
+		t.Fatalf("MarshalCBOR of Pairs not correct: %x", itemsData)
+	}
+
+	var a geko.Any
+	if err = a.UnmarshalCBOR(data); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	anyData, _ := a.MarshalCBOR()
+	if string(anyData) != string(data) {
+		t.Fatalf("MarshalCBOR of Any not correct: %x", anyData)
+	}
+}
+
+func TestCBOR_Typed(t *testing.T) {
+	m := geko.NewMap[int, string]()
+	if err := m.UnmarshalCBOR(mustHex(t, "a2016161026162")); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	data, _ := m.MarshalCBOR()
+	if hex.EncodeToString(data) != "a2016161026162" || m.GetOrZeroValue(2) != "b" {
+		t.Fatalf("Typed map round trip result not correct: %x", data)
+	}
+
+	l := geko.NewList[[]byte]()
+	if err := l.UnmarshalCBOR(mustHex(t, "9f42010241ffff")); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	data, _ = l.MarshalCBOR()
+	if hex.EncodeToString(data) != "8242010241ff" {
+		t.Fatalf("Typed list round trip result not correct: %x", data)
+	}
+
+	times := geko.NewList[time.Time]()
+	times.SetAppendOnUnmarshal(true)
+	for i := 0; i < 2; i++ {
+		if err := times.UnmarshalCBOR(mustHex(t, "81c11a514b67b0")); err != nil {
+			t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+		}
+	}
+
+	if times.Len() != 2 || times.Get(1).Unix() != 1363896240 {
+		t.Fatalf("Time list result not correct: %#v", times)
+	}
+
+	structs := geko.NewList[cborTestStruct]()
+	if err := structs.UnmarshalCBOR(mustHex(t, "81a2646e616d6561786473697a6501")); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	if structs.Get(0) != (cborTestStruct{Name: "x", Size: 1}) {
+		t.Fatalf("Struct list result not correct: %#v", structs)
+	}
+
+	anys := geko.NewList[any]()
+	anys.SetDecodeOptions(geko.UseObject(), geko.UseInt64(true))
+	if err := anys.UnmarshalCBOR(mustHex(t, "82a1616101f6")); err != nil {
+		t.Fatalf("UnmarshalCBOR with error: %s", err.Error())
+	}
+
+	if object, ok := anys.Get(0).(geko.Object); !ok || object.GetOrZeroValue("a") != int64(1) || anys.Get(1) != nil {
+		t.Fatalf("List decode options not used: %#v", anys)
+	}
+}
+
+func TestCBOR_TypedError(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		target interface{ UnmarshalCBOR(data []byte) error }
+	}{
+		{"a10101", geko.NewMap[string, int]()},
+		{"a1616161", geko.NewMap[string, int]()},
+		{"a16161", geko.NewMap[string, int]()},
+		{"a1616101", geko.NewMap[string, bool]()},
+		{"a161610100", geko.NewMap[string, int]()},
+		{"80", geko.NewMap[string, int]()},
+		{"", geko.NewPairs[string, int]()},
+		{"a0ff", geko.NewPairs[string, int]()},
+		{"a0", geko.NewList[int]()},
+		{"", geko.NewList[int]()},
+		{"82", geko.NewList[int]()},
+		{"8161", geko.NewList[int]()},
+		{"816161", geko.NewList[int]()},
+		{"8001", geko.NewList[int]()},
+		{"ff", &geko.Any{}},
+		{"0000", &geko.Any{}},
+	} {
+		if err := c.target.UnmarshalCBOR(mustHex(t, c.data)); err == nil {
+			t.Fatalf("UnmarshalCBOR %s should fail: %#v", c.data, c.target)
+		}
+	}
+}
+
+func TestCBOR_Nil(t *testing.T) {
+	var object geko.Object
+	var items geko.ObjectItems
+	var array geko.Array
+
+	for _, v := range []interface{ MarshalCBOR() ([]byte, error) }{object, items, array} {
+		data, err := v.MarshalCBOR()
+		if err != nil || hex.EncodeToString(data) != "f6" {
+			t.Fatalf("MarshalCBOR of nil should be null: %x, %#v", data, err)
+		}
+	}
+}
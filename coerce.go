@@ -0,0 +1,260 @@
+package geko
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CoerceOptions are options for controlling the behavior of value coercion
+// helpers, like [ToInt64].
+//
+// Default value (created by [CreateCoerceOptions]) of it is:
+//
+//   - Strings are not converted into other types.
+//
+// See also: [CreateCoerceOptions], [LenientCoercion].
+type CoerceOptions struct {
+	lenient bool
+}
+
+// CoerceOption is atom/modifier of [CoerceOptions].
+type CoerceOption func(opts *CoerceOptions)
+
+// CreateCoerceOptions creates a [CoerceOptions] by apply all option to the
+// default coerce option.
+func CreateCoerceOptions(option ...CoerceOption) CoerceOptions {
+	opts := CoerceOptions{}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *CoerceOptions) Apply(option ...CoerceOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// LenientCoercion specifies whether values can be converted from and into
+// strings, for data whose producer quotes scalars, like "42" or "true":
+//
+//   - [ToInt64] and [ToFloat64] accept a string which is a valid JSON number
+//     literal.
+//   - [ToBool] accepts a string accepted by [strconv.ParseBool].
+//   - [ToString] accepts numbers and bools, and returns their JSON text.
+func LenientCoercion(v bool) CoerceOption {
+	return func(opts *CoerceOptions) {
+		opts.lenient = v
+	}
+}
+
+var (
+	errNotConvertible = errors.New("type not convertible")
+	errNotInteger     = errors.New("not an integer")
+)
+
+// ToInt64 converts v into an int64. v can be any number type the decoder
+// produces: float64, [json.Number], int64, [*big.Int] and [*big.Float], or
+// other Go integer and float types.
+//
+// If v is not a number, or not an integer, or out of range of int64, a
+// [*CoerceError] is returned. For a number out of range, the error wraps
+// [strconv.ErrRange].
+func ToInt64(v any, option ...CoerceOption) (int64, error) {
+	number, err := lenientNumber(v, option)
+	if err == nil {
+		var result int64
+		if result, err = toInt64(number); err == nil {
+			return result, nil
+		}
+	}
+	return 0, &CoerceError{Value: v, Want: "int64", Err: err}
+}
+
+// ToFloat64 converts v into a float64, v can be any number type like
+// [ToInt64]. Precision may be lost, like an int64 larger than 2^53.
+//
+// If v is not a number, or out of range of float64, a [*CoerceError] is
+// returned. For a number out of range, the error wraps [strconv.ErrRange].
+func ToFloat64(v any, option ...CoerceOption) (float64, error) {
+	number, err := lenientNumber(v, option)
+	if err == nil {
+		var result float64
+		if result, err = toFloat64(number); err == nil {
+			return result, nil
+		}
+	}
+	return 0, &CoerceError{Value: v, Want: "float64", Err: err}
+}
+
+// ToBool converts v into a bool. Only a bool is accepted, unless
+// [LenientCoercion] is enabled.
+//
+// If v can't be converted, a [*CoerceError] is returned.
+func ToBool(v any, option ...CoerceOption) (bool, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+
+	var err error = errNotConvertible
+	if s, ok := v.(string); ok && CreateCoerceOptions(option...).lenient {
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+
+	return false, &CoerceError{Value: v, Want: "bool", Err: err}
+}
+
+// ToString converts v into a string. Only a string is accepted, unless
+// [LenientCoercion] is enabled, numbers accepted by [ToFloat64] and bools are
+// converted into their JSON text then.
+//
+// If v can't be converted, a [*CoerceError] is returned.
+func ToString(v any, option ...CoerceOption) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	if CreateCoerceOptions(option...).lenient {
+		_, isBool := v.(bool)
+		_, notNumber := ToFloat64(v)
+		if isBool || notNumber == nil {
+			// fails on number can't be encoded, like NaN
+			if data, err := JSONMarshal(v); err == nil {
+				return string(data), nil
+			}
+		}
+	}
+
+	return "", &CoerceError{Value: v, Want: "string", Err: errNotConvertible}
+}
+
+// lenientNumber converts a string into a [json.Number] if lenient coercion is
+// enabled, it must be a valid JSON number literal.
+func lenientNumber(v any, option []CoerceOption) (any, error) {
+	s, ok := v.(string)
+	if !ok || !CreateCoerceOptions(option...).lenient {
+		return v, nil
+	}
+
+	if !isJSONNumber(s) {
+		return nil, strconv.ErrSyntax
+	}
+
+	return json.Number(s), nil
+}
+
+func toInt64(v any) (int64, error) {
+	switch x := v.(type) {
+	case int, int8, int16, int32, int64:
+		return reflect.ValueOf(x).Int(), nil
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		if u := reflect.ValueOf(x).Uint(); u <= math.MaxInt64 {
+			return int64(u), nil
+		}
+		return 0, strconv.ErrRange
+	case json.Number:
+		return decimalToInt64(string(x))
+	case *big.Int:
+		if x != nil {
+			if !x.IsInt64() {
+				return 0, strconv.ErrRange
+			}
+			return x.Int64(), nil
+		}
+	case *big.Float:
+		if x != nil {
+			return bigFloatToInt64(x)
+		}
+	}
+
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if f != math.Trunc(f) || math.IsNaN(f) {
+		return 0, errNotInteger
+	}
+
+	// float64(math.MaxInt64) is 2^63, which is out of range
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, strconv.ErrRange
+	}
+
+	return int64(f), nil
+}
+
+// decimalToInt64 converts a decimal number text into int64 exactly, instead
+// of through a float64, which may round off a small fraction part.
+func decimalToInt64(s string) (int64, error) {
+	normalized, err := normalizeDecimal(s)
+	if err != nil {
+		return 0, strconv.ErrSyntax
+	}
+
+	digits, exp, _ := strings.Cut(normalized, "e")
+	e, _ := strconv.Atoi(exp)
+	if e < 0 {
+		return 0, errNotInteger
+	}
+	// int64 has at most 19 digits
+	if len(strings.TrimPrefix(digits, "-"))+e > 19 {
+		return 0, strconv.ErrRange
+	}
+
+	return strconv.ParseInt(digits+strings.Repeat("0", e), 10, 64)
+}
+
+func bigFloatToInt64(f *big.Float) (int64, error) {
+	if !f.IsInt() {
+		return 0, errNotInteger
+	}
+
+	i, accuracy := f.Int64()
+	if accuracy != big.Exact {
+		return 0, strconv.ErrRange
+	}
+
+	return i, nil
+}
+
+func toFloat64(v any) (float64, error) {
+	var f float64
+
+	switch x := v.(type) {
+	case float32, float64:
+		return reflect.ValueOf(x).Float(), nil
+	case int, int8, int16, int32, int64:
+		return float64(reflect.ValueOf(x).Int()), nil
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		return float64(reflect.ValueOf(x).Uint()), nil
+	case json.Number:
+		return strconv.ParseFloat(string(x), 64)
+	case *big.Int:
+		if x == nil {
+			return 0, errNotConvertible
+		}
+		f, _ = new(big.Float).SetInt(x).Float64()
+	case *big.Float:
+		if x == nil {
+			return 0, errNotConvertible
+		}
+		f, _ = x.Float64()
+	default:
+		return 0, errNotConvertible
+	}
+
+	if math.IsInf(f, 0) {
+		return 0, strconv.ErrRange
+	}
+
+	return f, nil
+}
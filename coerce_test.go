@@ -0,0 +1,258 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+var (
+	errCoerceNotConvertible = errors.New("not convertible")
+	errCoerceNotInteger     = errors.New("not integer")
+)
+
+func bigFloat(s string) *big.Float {
+	f, _, _ := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	return f
+}
+
+func bigInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 10)
+	return i
+}
+
+// checkCoerceError checks err is nil if excepted is nil, or a *CoerceError,
+// which wraps strconv.ErrRange if excepted is it.
+func checkCoerceError(t *testing.T, v any, err, excepted error) {
+	t.Helper()
+
+	if excepted == nil {
+		if err != nil {
+			t.Fatalf("Convert %T %v with error: %s", v, v, err.Error())
+		}
+		return
+	}
+
+	var coerceErr *geko.CoerceError
+	if !errors.As(err, &coerceErr) || fmt.Sprint(coerceErr.Value) != fmt.Sprint(v) {
+		t.Fatalf("Convert %T %v should fail with CoerceError: %#v", v, v, err)
+	}
+
+	if errors.Is(err, strconv.ErrRange) != (excepted == strconv.ErrRange) {
+		t.Fatalf("Convert %T %v error not correct: %s", v, v, err.Error())
+	}
+}
+
+func TestToInt64(t *testing.T) {
+	var nilBigInt *big.Int
+
+	for _, c := range []struct {
+		value    any
+		excepted int64
+		err      error
+	}{
+		{1.0, 1, nil},
+		{-1e18, -1e18, nil},
+		{1.5, 0, errCoerceNotInteger},
+		{math.NaN(), 0, errCoerceNotInteger},
+		{math.Inf(1), 0, strconv.ErrRange},
+		{1e19, 0, strconv.ErrRange},
+		{float64(math.MaxInt64), 0, strconv.ErrRange},
+		{float32(2), 2, nil},
+		{json.Number("42"), 42, nil},
+		{json.Number("-9223372036854775808"), math.MinInt64, nil},
+		{json.Number("9223372036854775808"), 0, strconv.ErrRange},
+		{json.Number("1.5e3"), 1500, nil},
+		{json.Number("1e19"), 0, strconv.ErrRange},
+		{json.Number("1152921504606846976.5"), 0, errCoerceNotInteger},
+		{json.Number("1.5"), 0, errCoerceNotInteger},
+		{json.Number("x"), 0, errCoerceNotConvertible},
+		{int64(math.MaxInt64), math.MaxInt64, nil},
+		{int(-3), -3, nil},
+		{int8(-3), -3, nil},
+		{int16(-3), -3, nil},
+		{int32(-3), -3, nil},
+		{uint(3), 3, nil},
+		{uint8(3), 3, nil},
+		{uint16(3), 3, nil},
+		{uint32(3), 3, nil},
+		{uint64(math.MaxUint64), 0, strconv.ErrRange},
+		{uintptr(3), 3, nil},
+		{bigInt("-12345678901234567"), -12345678901234567, nil},
+		{bigInt("12345678901234567890"), 0, strconv.ErrRange},
+		{nilBigInt, 0, errCoerceNotConvertible},
+		{bigFloat("1152921504606846977"), 1152921504606846977, nil},
+		{bigFloat("1152921504606846976.5"), 0, errCoerceNotInteger},
+		{bigFloat("1e30"), 0, strconv.ErrRange},
+		{"1", 0, errCoerceNotConvertible},
+		{true, 0, errCoerceNotConvertible},
+		{nil, 0, errCoerceNotConvertible},
+	} {
+		result, err := geko.ToInt64(c.value)
+		checkCoerceError(t, c.value, err, c.err)
+		if result != c.excepted {
+			t.Fatalf("ToInt64 %T %v excepted %d, got %d", c.value, c.value, c.excepted, result)
+		}
+	}
+}
+
+func TestToFloat64(t *testing.T) {
+	var nilBigFloat *big.Float
+
+	for _, c := range []struct {
+		value    any
+		excepted float64
+		err      error
+	}{
+		{1.5, 1.5, nil},
+		{float32(0.5), 0.5, nil},
+		{json.Number("1.5e3"), 1500, nil},
+		{json.Number("1e400"), 0, strconv.ErrRange},
+		{json.Number("x"), 0, errCoerceNotConvertible},
+		{int64(-2), -2, nil},
+		{int(2), 2, nil},
+		{uint64(2), 2, nil},
+		{bigInt("12345678901234567890"), 12345678901234567890, nil},
+		{new(big.Int).Lsh(big.NewInt(1), 1100), 0, strconv.ErrRange},
+		{bigFloat("0.25"), 0.25, nil},
+		{bigFloat("1e400"), 0, strconv.ErrRange},
+		{nilBigFloat, 0, errCoerceNotConvertible},
+		{"1.5", 0, errCoerceNotConvertible},
+		{false, 0, errCoerceNotConvertible},
+		{nil, 0, errCoerceNotConvertible},
+	} {
+		result, err := geko.ToFloat64(c.value)
+		checkCoerceError(t, c.value, err, c.err)
+		if result != c.excepted {
+			t.Fatalf("ToFloat64 %T %v excepted %v, got %v", c.value, c.value, c.excepted, result)
+		}
+	}
+}
+
+func TestToNumber_Lenient(t *testing.T) {
+	lenient := geko.LenientCoercion(true)
+
+	for _, c := range []struct {
+		value         string
+		exceptedInt   int64
+		exceptedFloat float64
+		intErr        error
+		floatErr      error
+	}{
+		{"42", 42, 42, nil, nil},
+		{"-1.25e1", 0, -12.5, errCoerceNotInteger, nil},
+		{"1e400", 0, 0, strconv.ErrRange, strconv.ErrRange},
+		{"0x10", 0, 0, errCoerceNotConvertible, errCoerceNotConvertible},
+		{"NaN", 0, 0, errCoerceNotConvertible, errCoerceNotConvertible},
+		{" 1", 0, 0, errCoerceNotConvertible, errCoerceNotConvertible},
+		{"", 0, 0, errCoerceNotConvertible, errCoerceNotConvertible},
+	} {
+		i, err := geko.ToInt64(c.value, lenient)
+		checkCoerceError(t, c.value, err, c.intErr)
+		f, err := geko.ToFloat64(c.value, lenient)
+		checkCoerceError(t, c.value, err, c.floatErr)
+
+		if i != c.exceptedInt || f != c.exceptedFloat {
+			t.Fatalf("Lenient convert %q not correct: %d, %v", c.value, i, f)
+		}
+	}
+}
+
+func TestToBool(t *testing.T) {
+	for _, c := range []struct {
+		value    any
+		lenient  bool
+		excepted bool
+		err      error
+	}{
+		{true, false, true, nil},
+		{false, true, false, nil},
+		{"true", false, false, errCoerceNotConvertible},
+		{"true", true, true, nil},
+		{"0", true, false, nil},
+		{"yes", true, false, errCoerceNotConvertible},
+		{1.0, true, false, errCoerceNotConvertible},
+		{nil, false, false, errCoerceNotConvertible},
+	} {
+		result, err := geko.ToBool(c.value, geko.LenientCoercion(c.lenient))
+		checkCoerceError(t, c.value, err, c.err)
+		if result != c.excepted {
+			t.Fatalf("ToBool %T %v excepted %v, got %v", c.value, c.value, c.excepted, result)
+		}
+	}
+}
+
+func TestToString(t *testing.T) {
+	for _, c := range []struct {
+		value    any
+		lenient  bool
+		excepted string
+		err      error
+	}{
+		{"s", false, "s", nil},
+		{"s", true, "s", nil},
+		{1.5, false, "", errCoerceNotConvertible},
+		{1.5, true, "1.5", nil},
+		{1e21, true, "1e+21", nil},
+		{json.Number("1.50"), true, "1.50", nil},
+		{int64(-3), true, "-3", nil},
+		{bigInt("12345678901234567890"), true, "12345678901234567890", nil},
+		{bigFloat("0.5"), true, "0.5", nil},
+		{true, true, "true", nil},
+		{true, false, "", errCoerceNotConvertible},
+		{nil, true, "", errCoerceNotConvertible},
+		{math.NaN(), true, "", errCoerceNotConvertible},
+		{[]any{}, true, "", errCoerceNotConvertible},
+	} {
+		result, err := geko.ToString(c.value, geko.LenientCoercion(c.lenient))
+		checkCoerceError(t, c.value, err, c.err)
+		if result != c.excepted {
+			t.Fatalf("ToString %T %v excepted %q, got %q", c.value, c.value, c.excepted, result)
+		}
+	}
+}
+
+func TestCoerceError(t *testing.T) {
+	_, err := geko.ToInt64(json.Number("1e19"))
+	if err.Error() != "geko: can't convert json.Number value 1e19 to int64: value out of range" {
+		t.Fatalf("CoerceError message not correct: %s", err.Error())
+	}
+
+	_, err = geko.ToBool("x")
+	if err.Error() != "geko: can't convert string value x to bool: type not convertible" {
+		t.Fatalf("CoerceError message not correct: %s", err.Error())
+	}
+}
+
+func TestGetInt64_DecodeOptions(t *testing.T) {
+	data := []byte(`{"a": 9007199254740993, "b": 1.5e3}`)
+
+	for _, option := range []geko.DecodeOption{
+		geko.UseNumber(false), geko.UseNumber(true), geko.UseInt64(true), geko.UseBigNumber(true),
+	} {
+		o, _ := geko.NewObjectFromJSON(data, option)
+
+		b, err := geko.GetInt64(o, "b")
+		if err != nil || b != 1500 {
+			t.Fatalf("GetInt64 result not correct: %d, %v", b, err)
+		}
+
+		f, err := geko.GetFloat64(o, "b")
+		if err != nil || f != 1500 {
+			t.Fatalf("GetFloat64 result not correct: %v, %v", f, err)
+		}
+	}
+
+	for _, option := range []geko.DecodeOption{geko.UseNumber(true), geko.UseInt64(true), geko.UseBigNumber(true)} {
+		o, _ := geko.NewObjectFromJSON(data, option)
+		if a, err := geko.GetInt64(o, "a"); err != nil || a != 9007199254740993 {
+			t.Fatalf("GetInt64 should keep precision: %d, %v", a, err)
+		}
+	}
+}
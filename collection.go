@@ -0,0 +1,37 @@
+package geko
+
+// OrderedCollection is the read-only view of an ordered collection of kv
+// pairs, implemented by [*Map] and [*Pairs]. Helpers that only read an
+// object can accept it, so they work on both [Object] and [ObjectItems].
+//
+// Indexes are positions in current order, and must be in range [0, Len()).
+type OrderedCollection[K comparable, V any] interface {
+	// Len returns count of kv pairs.
+	Len() int
+	// GetByIndex gets the kv pair at index.
+	GetByIndex(index int) Pair[K, V]
+	// GetKeyByIndex gets the key at index.
+	GetKeyByIndex(index int) K
+	// GetValueByIndex gets the value at index.
+	GetValueByIndex(index int) V
+	// Keys returns a copy of all keys, in order.
+	Keys() []K
+	// Values returns a copy of all values, in order.
+	Values() []V
+}
+
+// OrderedMutable is an [OrderedCollection] which can be added kv pairs into,
+// implemented by [*Map] and [*Pairs].
+//
+// How a duplicated key is handled by Add depends on the implementation, see
+// [Map.Add] and [Pairs.Add].
+type OrderedMutable[K comparable, V any] interface {
+	OrderedCollection[K, V]
+	// Add adds a kv pair.
+	Add(key K, value V)
+}
+
+var (
+	_ OrderedMutable[string, any] = (*Map[string, any])(nil)
+	_ OrderedMutable[string, any] = (*Pairs[string, any])(nil)
+)
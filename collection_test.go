@@ -0,0 +1,63 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func collectionSummary(c geko.OrderedCollection[string, any]) []any {
+	summary := make([]any, 0, c.Len()*2)
+	for i := 0; i < c.Len(); i++ {
+		pair := c.GetByIndex(i)
+		if pair.Key != c.GetKeyByIndex(i) || pair.Value != c.GetValueByIndex(i) {
+			return nil
+		}
+		summary = append(summary, pair.Key, pair.Value)
+	}
+	return summary
+}
+
+func TestOrderedCollection(t *testing.T) {
+	data := []byte(`{"b": 1, "a": "x", "b": 2}`)
+
+	object, _ := geko.JSONUnmarshal(data, geko.UseObject())
+	items, _ := geko.JSONUnmarshal(data)
+
+	for _, c := range []struct {
+		collection geko.OrderedCollection[string, any]
+		excepted   []any
+	}{
+		{object.(geko.Object), []any{"b", 2.0, "a", "x"}},
+		{items.(geko.ObjectItems), []any{"b", 1.0, "a", "x", "b", 2.0}},
+	} {
+		if summary := collectionSummary(c.collection); !reflect.DeepEqual(summary, c.excepted) {
+			t.Fatalf("OrderedCollection summary of %T not correct: %v", c.collection, summary)
+		}
+
+		if len(c.collection.Keys()) != c.collection.Len() || len(c.collection.Values()) != c.collection.Len() {
+			t.Fatalf("OrderedCollection Keys and Values of %T not correct", c.collection)
+		}
+	}
+}
+
+func TestOrderedMutable(t *testing.T) {
+	for _, c := range []struct {
+		mutable  geko.OrderedMutable[string, int]
+		excepted string
+	}{
+		{geko.NewMap[string, int](), `{"a":3,"b":2}`},
+		{geko.NewPairs[string, int](), `{"a":1,"b":2,"a":3}`},
+	} {
+		c.mutable.Add("a", 1)
+		c.mutable.Add("b", 2)
+		c.mutable.Add("a", 3)
+
+		output, _ := json.Marshal(c.mutable)
+		if string(output) != c.excepted {
+			t.Fatalf("OrderedMutable Add of %T not correct: %s", c.mutable, string(output))
+		}
+	}
+}
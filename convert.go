@@ -0,0 +1,101 @@
+package geko
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ToStdTypes deep converts v into types used by std lib for JSON:
+//
+//   - [Map] and [Pairs] are converted into map[string]any. For duplicated keys
+//     in [Pairs], the last value wins, like [json.Unmarshal] does.
+//   - [List] is converted into []any.
+//   - map[string]any and []any are copied, with their values converted.
+//   - Other values are returned as is.
+//
+// Nil containers in this package are converted into nil. Keys which are not
+// string are formatted by [fmt.Sprint]. v is never modified.
+func ToStdTypes(v any) any {
+	switch x := v.(type) {
+	case container:
+		return x.toStdTypes()
+	case map[string]any:
+		if x == nil {
+			return x
+		}
+		result := make(map[string]any, len(x))
+		for key, value := range x {
+			result[key] = ToStdTypes(value)
+		}
+		return result
+	case []any:
+		if x == nil {
+			return x
+		}
+		result := make([]any, len(x))
+		for i, value := range x {
+			result[i] = ToStdTypes(value)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func stdKey(key any) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// FromStdTypes deep converts std containers in v into types in this package,
+// it's the reverse of [ToStdTypes]:
+//
+//   - map[string]any is converted into [Object], keys are ordered by keyOrder,
+//     which should reorder keys in place. If keyOrder is nil, keys are sorted,
+//     so the result is deterministic.
+//   - []any is converted into [Array].
+//   - Other values, including containers in this package, are returned as is.
+//
+// Nil map and slice are converted into nil [Object] and [Array]. v is never
+// modified.
+func FromStdTypes(v any, keyOrder func(keys []string)) any {
+	if keyOrder == nil {
+		keyOrder = sort.Strings
+	}
+	return fromStdTypes(v, keyOrder)
+}
+
+func fromStdTypes(v any, keyOrder func(keys []string)) any {
+	switch x := v.(type) {
+	case map[string]any:
+		if x == nil {
+			return Object(nil)
+		}
+
+		keys := make([]string, 0, len(x))
+		for key := range x {
+			keys = append(keys, key)
+		}
+		keyOrder(keys)
+
+		result := NewMapWithCapacity[string, any](len(keys))
+		for _, key := range keys {
+			result.Set(key, fromStdTypes(x[key], keyOrder))
+		}
+		return result
+	case []any:
+		if x == nil {
+			return Array(nil)
+		}
+
+		result := NewListWithCapacity[any](len(x))
+		for _, item := range x {
+			result.Append(fromStdTypes(item, keyOrder))
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,141 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestToStdTypes(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{
+		"a": 1,
+		"b": [{"c": [true, null]}, "s", []],
+		"a": {"d": {}},
+		"e": null
+	}`))
+
+	object := geko.NewMap[string, any]()
+	object.Set("f", []any{geko.NewListFrom([]int{1, 2})})
+	object.Set("g", map[string]any{"h": geko.NewPairs[string, any]()})
+	v.(geko.ObjectItems).Add("i", object)
+
+	excepted := map[string]any{
+		"a": map[string]any{"d": map[string]any{}},
+		"b": []any{map[string]any{"c": []any{true, nil}}, "s", []any{}},
+		"e": nil,
+		"i": map[string]any{
+			"f": []any{[]any{1, 2}},
+			"g": map[string]any{"h": map[string]any{}},
+		},
+	}
+
+	if result := geko.ToStdTypes(v); !reflect.DeepEqual(result, excepted) {
+		t.Fatalf("ToStdTypes result not correct: %#v", result)
+	}
+
+	// source is not modified
+	if _, ok := object.GetOrZeroValue("g").(map[string]any)["h"].(geko.ObjectItems); !ok {
+		t.Fatalf("ToStdTypes should not modify source")
+	}
+}
+
+func TestToStdTypes_Duplicates(t *testing.T) {
+	ps := geko.NewPairs[string, any]()
+	ps.Add("a", 1)
+	ps.Add("b", 2)
+	ps.Add("a", 3)
+
+	if result := geko.ToStdTypes(ps); !reflect.DeepEqual(result, map[string]any{"a": 3, "b": 2}) {
+		t.Fatalf("ToStdTypes should keep last value of duplicated key: %#v", result)
+	}
+}
+
+func TestToStdTypes_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+	var stdMap map[string]any
+	var stdSlice []any
+
+	excepted := []any{nil, nil, nil, map[string]any(nil), []any(nil), 1}
+	result := geko.ToStdTypes([]any{m, ps, l, stdMap, stdSlice, 1})
+	if !reflect.DeepEqual(result, excepted) {
+		t.Fatalf("ToStdTypes result not correct: %#v", result)
+	}
+}
+
+func TestToStdTypes_NonStringKey(t *testing.T) {
+	m := geko.NewMap[int, string]()
+	m.Set(1, "a")
+
+	if result := geko.ToStdTypes(m); !reflect.DeepEqual(result, map[string]any{"1": "a"}) {
+		t.Fatalf("ToStdTypes result not correct: %#v", result)
+	}
+}
+
+func TestFromStdTypes(t *testing.T) {
+	object := geko.NewMap[string, any]()
+	object.Set("z", 1)
+	object.Set("y", 2)
+
+	source := map[string]any{
+		"b": []any{map[string]any{"d": 1, "c": nil, "e": []any{}}, "s"},
+		"a": map[string]any{},
+		"f": object,
+		"c": true,
+	}
+
+	first, _ := json.Marshal(geko.FromStdTypes(source, nil))
+	for i := 0; i < 10; i++ {
+		output, _ := json.Marshal(geko.FromStdTypes(source, nil))
+		if !bytes.Equal(output, first) {
+			t.Fatalf("FromStdTypes result is not deterministic:\n%s\n%s", string(first), string(output))
+		}
+	}
+
+	if string(first) != `{"a":{},"b":[{"c":null,"d":1,"e":[]},"s"],"c":true,"f":{"z":1,"y":2}}` {
+		t.Fatalf("FromStdTypes result not correct: %s", string(first))
+	}
+
+	if _, ok := source["a"].(map[string]any); !ok {
+		t.Fatalf("FromStdTypes should not modify source")
+	}
+}
+
+func TestFromStdTypes_KeyOrder(t *testing.T) {
+	source := map[string]any{"a": 1, "bb": map[string]any{"ccc": 1, "d": 2}}
+
+	byLength := func(keys []string) {
+		sort.Slice(keys, func(i, j int) bool {
+			return len(keys[i]) > len(keys[j])
+		})
+	}
+
+	output, _ := json.Marshal(geko.FromStdTypes(source, byLength))
+	if string(output) != `{"bb":{"ccc":1,"d":2},"a":1}` {
+		t.Fatalf("FromStdTypes result not correct: %s", string(output))
+	}
+}
+
+func TestFromStdTypes_Nil(t *testing.T) {
+	var m map[string]any
+	var s []any
+
+	result := geko.FromStdTypes([]any{m, s, nil}, nil).(geko.Array)
+	if result.Get(0).(geko.Object) != nil || result.Get(1).(geko.Array) != nil || result.Get(2) != nil {
+		t.Fatalf("FromStdTypes result not correct: %#v", result.List)
+	}
+}
+
+func TestFromStdTypes_RoundTrip(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"a": [1, {"c": 2, "b": null}], "d": "s"}`), geko.UseObject())
+
+	output, _ := json.Marshal(geko.FromStdTypes(geko.ToStdTypes(v), nil))
+	if string(output) != `{"a":[1,{"b":null,"c":2}],"d":"s"}` {
+		t.Fatalf("Round trip result not correct: %s", string(output))
+	}
+}
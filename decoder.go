@@ -0,0 +1,619 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Decoder reads and decodes JSON values from an input stream, using types in
+// this package to store JSON object and array.
+//
+// It's the streaming version of [JSONUnmarshal], the input is read
+// incrementally, so you do not need to load all data into memory first.
+type Decoder struct {
+	decoder *json.Decoder
+	opts    DecodeOptions
+	depth   int
+
+	// input of a pooled decoder, see acquireDecoder
+	src bytes.Reader
+
+	// for limits, reset for every top-level value
+	start int64
+	items int
+}
+
+// NewDecoder creates a new [Decoder] reads from r, with provided option
+// applied.
+//
+// The decoder introduces its own buffering and may read data from r beyond
+// the JSON values requested.
+func NewDecoder(r io.Reader, option ...DecodeOption) *Decoder {
+	return newDecoder(r, CreateDecodeOptions(option...))
+}
+
+func newDecoder(r io.Reader, opts DecodeOptions) *Decoder {
+	d := &Decoder{}
+	d.init(r, opts)
+	return d
+}
+
+func (d *Decoder) init(r io.Reader, opts DecodeOptions) {
+	if opts.allowComments || opts.allowTrailingCommas {
+		r = newJSONCReader(r, opts.allowComments, opts.allowTrailingCommas)
+	}
+
+	d.decoder = json.NewDecoder(r)
+	d.opts = opts
+	d.depth = 0
+	d.start = 0
+	d.items = 0
+
+	// always get the raw literal, if we need to do conversion by ourself
+	if opts.useNumber || opts.useInt64 || opts.useBigNumber || opts.numberFunc != nil {
+		d.decoder.UseNumber()
+	}
+}
+
+// decoderPool reduces allocations when decoding lots of small inputs. The std
+// json.Decoder can't be reset, so only the states of our own are reused.
+var decoderPool = sync.Pool{
+	New: func() any {
+		return &Decoder{}
+	},
+}
+
+// acquireDecoder gets a decoder reads from data from pool. It must be put back
+// by releaseDecoder, and can't be used after that.
+func acquireDecoder(data []byte, opts DecodeOptions) *Decoder {
+	d, _ := decoderPool.Get().(*Decoder)
+	d.src.Reset(data)
+	d.init(&d.src, opts)
+	return d
+}
+
+func releaseDecoder(d *Decoder) {
+	// do not hold references of input and options in pool
+	d.src.Reset(nil)
+	d.decoder = nil
+	d.opts = DecodeOptions{}
+	decoderPool.Put(d)
+}
+
+// Decode reads the next JSON value from its input and returns it.
+//
+// The type of returned value is same as [Any.Value] after a [json.Unmarshal].
+//
+// Like [json.Decoder.Decode], it can be called multiple times to decode a
+// stream of JSON values, and returns [io.EOF] when there is no more value.
+// If the input ends in the middle of a value, a [SyntaxError] is returned
+// instead.
+func (d *Decoder) Decode() (any, error) {
+	d.reset()
+
+	token, err := d.token()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := d.nextAfterToken(token)
+	if err != nil {
+		return nil, d.unexpectedEOF(err)
+	}
+
+	return value, nil
+}
+
+// More reports whether there is another JSON value in the input stream.
+//
+// It's useful to decode a stream of JSON values, like newline delimited JSON:
+//
+//	for d.More() {
+//		value, err := d.Decode()
+//		// ...
+//	}
+func (d *Decoder) More() bool {
+	return d.decoder.More()
+}
+
+// InputOffset returns the input stream byte offset of the current decoder
+// position. The offset gives the location of the end of the most recently
+// returned value and the beginning of the next one.
+func (d *Decoder) InputOffset() int64 {
+	return d.decoder.InputOffset()
+}
+
+// decode reads one JSON value, and make sure there is no data after it.
+func (d *Decoder) decode() (any, error) {
+	d.reset()
+
+	item, err := d.next()
+	if err != nil {
+		return nil, d.unexpectedEOF(err)
+	}
+
+	if err = d.end(); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// DecodeArrayElements decodes a JSON array from r, and calls fn for every
+// element of it in order, without holding the whole array in memory.
+//
+// Elements are decoded like [JSONUnmarshal], with provided option applied.
+// Each element is treated as a top-level value by limits, so [MaxItems] and
+// [MaxBytes] limit the size of a single element.
+//
+// If the input is not an array, a [json.UnmarshalTypeError] is returned. If fn
+// returns an error, decoding stops and the error is returned.
+func DecodeArrayElements(r io.Reader, fn func(index int, elem any) error, option ...DecodeOption) error {
+	d := newDecoder(r, CreateDecodeOptions(option...))
+
+	token, err := d.token()
+	if err != nil {
+		return d.unexpectedEOF(err)
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return &json.UnmarshalTypeError{
+			Value:  "non-array value",
+			Type:   reflect.TypeOf([]any(nil)),
+			Offset: d.decoder.InputOffset(),
+		}
+	}
+
+	// depth of top-level container is 1, it never exceeds a valid limit
+	_ = d.enter()
+
+	for index := 0; ; index++ {
+		d.reset()
+
+		if token, err = d.token(); err != nil {
+			return d.unexpectedEOF(err)
+		}
+
+		// if meet ], the array ends
+		if delim, ok := token.(json.Delim); ok && delim == ']' {
+			break
+		}
+
+		elem, err := d.nextAfterToken(token)
+		if err != nil {
+			return d.unexpectedEOF(err)
+		}
+
+		if err = fn(index, elem); err != nil {
+			return err
+		}
+	}
+
+	return d.end()
+}
+
+// unexpectedEOF converts [io.EOF] into a [SyntaxError], for places where the
+// input should not end.
+func (d *Decoder) unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return &SyntaxError{Msg: "unexpected end of JSON input", Offset: d.decoder.InputOffset()}
+	}
+	return err
+}
+
+// end makes sure there is no data after the top-level value, unless
+// [AllowTrailingData] is applied.
+func (d *Decoder) end() error {
+	if d.opts.allowTrailingData {
+		return nil
+	}
+
+	if _, err := d.decoder.Token(); err != io.EOF {
+		return &SyntaxError{
+			Msg:    "invalid character after top-level value",
+			Offset: d.decoder.InputOffset(),
+		}
+	}
+
+	return nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// reset states for limits, should be called before decode a top-level value.
+func (d *Decoder) reset() {
+	d.start = d.decoder.InputOffset()
+	d.items = 0
+}
+
+// token reads next token, and checks the bytes limit.
+func (d *Decoder) token() (json.Token, error) {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return token, d.checkBytes()
+}
+
+func (d *Decoder) checkBytes() error {
+	offset := d.decoder.InputOffset()
+	if d.opts.maxBytes > 0 && offset-d.start > d.opts.maxBytes {
+		return &LimitExceededError{
+			Kind:   BytesLimit,
+			Limit:  d.opts.maxBytes,
+			Offset: offset,
+		}
+	}
+	return nil
+}
+
+// addItem should be called when meet a object member or array element, it
+// checks the items limit.
+func (d *Decoder) addItem() error {
+	d.items++
+	if d.opts.maxItems > 0 && d.items > d.opts.maxItems {
+		return &LimitExceededError{
+			Kind:   ItemsLimit,
+			Limit:  int64(d.opts.maxItems),
+			Offset: d.decoder.InputOffset(),
+		}
+	}
+	return nil
+}
+
+// skipValue appends a [DecodeValueError] of the value at path into skipped,
+// if decoding is lenient and err does not break the input stream. Otherwise
+// err is returned as is.
+func (d *Decoder) skipValue(skipped []*DecodeValueError, path string, err error) ([]*DecodeValueError, error) {
+	var syntaxErr *json.SyntaxError
+	if !d.opts.lenient || errors.As(err, &syntaxErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return skipped, err
+	}
+
+	return append(skipped, &DecodeValueError{
+		Path:   path,
+		Offset: d.decoder.InputOffset(),
+		Err:    err,
+	}), nil
+}
+
+func skippedError(skipped []*DecodeValueError) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	return &MultiDecodeError{Errors: skipped}
+}
+
+// number converts a number literal into value by options.
+func (d *Decoder) number(n json.Number) (any, error) {
+	literal := n.String()
+
+	if d.opts.numberFunc != nil {
+		v, err := d.opts.numberFunc(literal)
+		if err != nil {
+			return nil, &NumberFuncError{
+				Literal: literal,
+				Offset:  d.decoder.InputOffset(),
+				Err:     err,
+			}
+		}
+		return v, nil
+	}
+
+	if !strings.ContainsAny(literal, ".eE") { // integer
+		if d.opts.useInt64 {
+			if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+				return i, nil
+			}
+		}
+
+		if d.opts.useBigNumber {
+			i, _ := new(big.Int).SetString(literal, 10) // never fails for a valid JSON integer
+			return i, nil
+		}
+	} else if d.opts.useBigNumber {
+		// 4 bits is enough for every decimal digit
+		prec := uint(len(literal) * 4)
+		if prec < 64 {
+			prec = 64
+		}
+
+		f, _, err := big.ParseFloat(literal, 10, prec, big.ToNearestEven)
+		if err != nil {
+			return nil, d.numberError(literal, f)
+		}
+
+		if exp := f.MantExp(nil); exp > bigFloatMaxExp || exp < -bigFloatMaxExp {
+			return nil, d.numberError(literal, f)
+		}
+
+		return f, nil
+	}
+
+	if d.opts.useNumber {
+		return n, nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return nil, d.numberError(literal, f)
+	}
+
+	return f, nil
+}
+
+// convertsNumber reports whether numbers are converted by options, so the std
+// decoder is set to give json.Number, see [Decoder.number].
+func (opts *DecodeOptions) convertsNumber() bool {
+	return opts.useInt64 || opts.useBigNumber || opts.numberFunc != nil
+}
+
+// decodeStd decodes the next value into v by std lib. Numbers decoded into
+// interfaces inside v are converted like other values, instead of leaking
+// json.Number.
+func (d *Decoder) decodeStd(v any) error {
+	if err := d.decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if !d.opts.convertsNumber() {
+		return nil
+	}
+
+	return d.convertNumbers(reflect.ValueOf(v).Elem())
+}
+
+// convertNumbers replaces json.Number in empty interfaces inside v, where std
+// lib puts numbers, by [Decoder.number].
+func (d *Decoder) convertNumbers(v reflect.Value) error {
+	if !containsAny(v.Type()) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		n, isNumber := v.Interface().(json.Number)
+		if !isNumber {
+			return d.convertNumbers(v.Elem())
+		}
+
+		value, err := d.number(n)
+		if err != nil {
+			return err
+		}
+
+		if value == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(value))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return d.convertNumbers(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := d.convertNumbers(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map values are not addressable, convert a copy and store it back
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			if err := d.convertNumbers(value); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), value)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := d.convertNumbers(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+	containsAnyCache sync.Map // map[reflect.Type]bool
+)
+
+// containsAny reports whether a value of type t may have empty interfaces
+// filled by std lib. Types decode themselves, like [json.Unmarshaler], are
+// not looked into.
+func containsAny(t reflect.Type) bool {
+	if cached, exist := containsAnyCache.Load(t); exist {
+		result, _ := cached.(bool)
+		return result
+	}
+
+	result := typeContainsAny(t, map[reflect.Type]bool{})
+	containsAnyCache.Store(t, result)
+	return result
+}
+
+func typeContainsAny(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] || reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return typeContainsAny(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if typeContainsAny(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// bigFloatMaxExp bounds the binary exponent of decoded [*big.Float], about
+// 1e±616, see [UseBigNumber].
+const bigFloatMaxExp = 1 << 11
+
+func (d *Decoder) numberError(literal string, target any) error {
+	return &json.UnmarshalTypeError{
+		Value:  "number " + literal,
+		Type:   reflect.TypeOf(target),
+		Offset: d.decoder.InputOffset(),
+	}
+}
+
+// decodeWith decodes the value of object member key by fn, set by
+// [WithValueDecoder].
+func (d *Decoder) decodeWith(key string, fn func(raw json.RawMessage) (any, error)) (any, error) {
+	var raw json.RawMessage
+	if err := d.decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	if err := d.checkBytes(); err != nil {
+		return nil, err
+	}
+
+	v, err := fn(raw)
+	if err != nil {
+		return nil, &ValueDecoderError{
+			Key:    key,
+			Offset: d.decoder.InputOffset(),
+			Err:    err,
+		}
+	}
+
+	return v, nil
+}
+
+// decodeObject decodes a JSON object into [Map] or [Pairs] by options, after
+// the start token is read.
+func decodeObject[V any](d *Decoder, valueIsAny bool) (any, error) {
+	hint := d.sizeHint(d.opts.objectSizeHint)
+
+	var object OrderedMutable[string, V]
+	if d.opts.useObject {
+		m := NewMap[string, V]()
+		if hint > 0 {
+			m = NewMapWithCapacity[string, V](hint)
+		}
+		m.SetDuplicatedKeyStrategy(d.opts.duplicatedKeyStrategy)
+		m.SetRecordDuplicates(d.opts.recordDuplicates)
+		object = m
+	} else if hint > 0 {
+		object = NewPairsWithCapacity[string, V](hint)
+	} else {
+		object = NewPairs[string, V]()
+	}
+
+	if err := parseIntoObject[string, V](d, object, valueIsAny); err != nil {
+		return nil, err
+	}
+
+	return object, nil
+}
+
+// sizeHint returns the capacity for a new container, hint only applies to the
+// top-level container.
+func (d *Decoder) sizeHint(hint int) int {
+	if d.depth == 1 {
+		return hint
+	}
+	return 0
+}
+
+// enter should be called when meet the start of a object or array.
+func (d *Decoder) enter() error {
+	d.depth++
+	if d.opts.maxDepth > 0 && d.depth > d.opts.maxDepth {
+		return &LimitExceededError{
+			Kind:   DepthLimit,
+			Limit:  int64(d.opts.maxDepth),
+			Offset: d.decoder.InputOffset(),
+		}
+	}
+	return nil
+}
+
+// leave should be called when a object or array is finished.
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+func (d *Decoder) next() (any, error) {
+	var token json.Token
+	var err error
+
+	if token, err = d.token(); err != nil {
+		return nil, err
+	}
+
+	return d.nextAfterToken(token)
+}
+
+func (d *Decoder) nextAfterToken(token json.Token) (any, error) {
+	var value any
+
+	switch v := token.(type) {
+	case bool, float64, string, nil:
+		value = v
+	case json.Number:
+		return d.number(v)
+	case json.Delim:
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+
+		switch v {
+		case '{':
+			if d.opts.useRawValues {
+				return decodeObject[json.RawMessage](d, false)
+			}
+			return decodeObject[any](d, true)
+		case '[':
+			{
+				l := NewList[any]()
+				if hint := d.sizeHint(d.opts.arraySizeHint); hint > 0 {
+					l = NewListWithCapacity[any](hint)
+				}
+				l.decodeOptions = d.opts
+				if err := parseIntoArray[any](d, l); err != nil {
+					return nil, err
+				}
+				value = l
+			}
+		}
+	}
+
+	return value, nil
+}
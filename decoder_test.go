@@ -0,0 +1,293 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/7sDream/geko"
+)
+
+// endlessReader generates JSON objects {"i": n} forever.
+type endlessReader struct {
+	n       int
+	pending []byte
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		r.pending = []byte(`{"i": ` + strconv.Itoa(r.n) + `, "z": [null]}` + "\n")
+		r.n++
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	data := `{"b": 1, "a": [true, {"b": 2, "b": 3}], "b": "4"}`
+	reader := iotest.OneByteReader(strings.NewReader(data))
+
+	d := geko.NewDecoder(reader, geko.UseNumber(true))
+
+	result, err := d.Decode()
+	if err != nil {
+		t.Fatalf("Decode with error: %s", err.Error())
+	}
+
+	excepted, _ := geko.JSONUnmarshal([]byte(data), geko.UseNumber(true))
+
+	output, _ := json.Marshal(result)
+	exceptedOutput, _ := json.Marshal(excepted)
+	if string(output) != string(exceptedOutput) {
+		t.Fatalf("Decode result %s not same as JSONUnmarshal %s", string(output), string(exceptedOutput))
+	}
+
+	if d.InputOffset() != int64(len(data)) {
+		t.Fatalf("InputOffset excepted %d, got %d", len(data), d.InputOffset())
+	}
+
+	if _, err = d.Decode(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Decode at the end of input should return io.EOF, got %#v", err)
+	}
+}
+
+func TestDecoder_Decode_EndlessReader(t *testing.T) {
+	d := geko.NewDecoder(&endlessReader{}, geko.UseObject())
+
+	for i := 0; i < 100; i++ {
+		result, err := d.Decode()
+		if err != nil {
+			t.Fatalf("Decode with error: %s", err.Error())
+		}
+
+		object, ok := result.(geko.Object)
+		if !ok {
+			t.Fatalf("Decode result is not Object: %#v", result)
+		}
+
+		if !object.Has("z") || object.GetOrZeroValue("i") != float64(i) {
+			t.Fatalf("Decode result not correct: %#v", object)
+		}
+	}
+}
+
+func TestDecoder_Decode_InvalidData(t *testing.T) {
+	d := geko.NewDecoder(strings.NewReader(`[1, 2`))
+
+	if _, err := d.Decode(); err == nil {
+		t.Fatalf("Decode invalid data should report error")
+	}
+}
+
+func TestDecoder_Decode_TruncatedData(t *testing.T) {
+	d := geko.NewDecoder(strings.NewReader(`[1] {"a":1`))
+
+	if _, err := d.Decode(); err != nil {
+		t.Fatalf("Decode with error: %s", err.Error())
+	}
+
+	var syntaxErr *geko.SyntaxError
+	if _, err := d.Decode(); !errors.As(err, &syntaxErr) {
+		t.Fatalf("Decode truncated value should fail with syntax error, got %#v", err)
+	}
+}
+
+func TestDecoder_Decode_LimitsPerValue(t *testing.T) {
+	d := geko.NewDecoder(
+		strings.NewReader(`[1, 2] [3, 4] [5,6,7]`),
+		geko.MaxItems(2), geko.MaxBytes(8),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.Decode(); err != nil {
+			t.Fatalf("Decode value %d with error: %s", i, err.Error())
+		}
+	}
+
+	_, err := d.Decode()
+	var limitErr *geko.LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Kind != geko.ItemsLimit {
+		t.Fatalf("Decode should fail with items limit, got %#v", err)
+	}
+
+	// whitespace before the second value is counted
+	d = geko.NewDecoder(strings.NewReader(`[1, 2] [3,   4]`), geko.MaxBytes(8))
+
+	if _, err = d.Decode(); err != nil {
+		t.Fatalf("Decode with error: %s", err.Error())
+	}
+
+	_, err = d.Decode()
+	if !errors.As(err, &limitErr) || limitErr.Kind != geko.BytesLimit {
+		t.Fatalf("Decode should fail with bytes limit, got %#v", err)
+	}
+}
+
+func TestDecoder_More(t *testing.T) {
+	d := geko.NewDecoder(strings.NewReader("{\"a\": 1}\n[\n  2\n]\n\"three\"\n"))
+
+	var values []any
+	for d.More() {
+		value, err := d.Decode()
+		if err != nil {
+			t.Fatalf("Decode with error: %s", err.Error())
+		}
+		values = append(values, value)
+	}
+
+	if len(values) != 3 || values[2] != "three" {
+		t.Fatalf("Decode result not correct: %#v", values)
+	}
+
+	if _, ok := values[1].(geko.Array); !ok {
+		t.Fatalf("Decode result not correct: %#v", values)
+	}
+}
+
+// arrayReader generates a JSON array of count objects {"i": n, "pad": "..."}.
+type arrayReader struct {
+	count   int
+	n       int
+	started bool
+	pending []byte
+}
+
+var arrayReaderPad = strings.Repeat("x", 1024)
+
+func (r *arrayReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		switch {
+		case !r.started:
+			r.started = true
+			r.pending = []byte("[")
+		case r.n < r.count:
+			r.pending = []byte(`{"i": ` + strconv.Itoa(r.n) + `, "pad": "` + arrayReaderPad + `"}`)
+			if r.n++; r.n < r.count {
+				r.pending = append(r.pending, ',')
+			} else {
+				r.pending = append(r.pending, ']')
+			}
+		default:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func heapAlloc() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+func TestDecodeArrayElements(t *testing.T) {
+	data := `[1, {"b": 1, "a": 2}, [null], "s"]`
+
+	var elems []any
+	err := geko.DecodeArrayElements(strings.NewReader(data), func(index int, elem any) error {
+		if index != len(elems) {
+			t.Fatalf("Element index not correct: %d", index)
+		}
+		elems = append(elems, elem)
+		return nil
+	}, geko.UseObject())
+	if err != nil {
+		t.Fatalf("DecodeArrayElements with error: %s", err.Error())
+	}
+
+	if len(elems) != 4 || elems[0] != 1.0 || elems[3] != "s" ||
+		elems[1].(geko.Object).Keys()[0] != "b" || elems[2].(geko.Array).Len() != 1 {
+		t.Fatalf("Elements not correct: %#v", elems)
+	}
+
+	count := 0
+	if err = geko.DecodeArrayElements(strings.NewReader(" [ ] "), func(int, any) error {
+		count++
+		return nil
+	}); err != nil || count != 0 {
+		t.Fatalf("DecodeArrayElements empty array not correct: %d, %#v", count, err)
+	}
+}
+
+func TestDecodeArrayElements_Error(t *testing.T) {
+	nop := func(int, any) error { return nil }
+
+	var typeErr *json.UnmarshalTypeError
+	if err := geko.DecodeArrayElements(strings.NewReader(`{"a": 1}`), nop); !errors.As(err, &typeErr) {
+		t.Fatalf("DecodeArrayElements non-array should fail with type error, got %#v", err)
+	}
+
+	var syntaxErr *geko.SyntaxError
+	for _, data := range []string{``, `[1, 2`, `[1, {"a": `, `[1] 2`} {
+		if err := geko.DecodeArrayElements(strings.NewReader(data), nop); !errors.As(err, &syntaxErr) {
+			t.Fatalf("DecodeArrayElements %q should fail with syntax error, got %#v", data, err)
+		}
+	}
+
+	if err := geko.DecodeArrayElements(strings.NewReader(`[1] 2`), nop, geko.AllowTrailingData(true)); err != nil {
+		t.Fatalf("DecodeArrayElements with trailing data allowed error: %s", err.Error())
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err := geko.DecodeArrayElements(strings.NewReader(`[1, 2, 3]`), func(index int, _ any) error {
+		count++
+		if index == 1 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || count != 2 {
+		t.Fatalf("DecodeArrayElements should stop with callback error: %d, %#v", count, err)
+	}
+}
+
+func TestDecodeArrayElements_LimitsPerElement(t *testing.T) {
+	nop := func(int, any) error { return nil }
+
+	data := `[[1, 2], [3, 4], {"a": 5, "b": 6}]`
+	if err := geko.DecodeArrayElements(strings.NewReader(data), nop, geko.MaxItems(2), geko.MaxBytes(18)); err != nil {
+		t.Fatalf("DecodeArrayElements with error: %s", err.Error())
+	}
+
+	var limitErr *geko.LimitExceededError
+	err := geko.DecodeArrayElements(strings.NewReader(`[[1], [2, 3, 4]]`), nop, geko.MaxItems(2))
+	if !errors.As(err, &limitErr) || limitErr.Kind != geko.ItemsLimit {
+		t.Fatalf("DecodeArrayElements should fail with items limit, got %#v", err)
+	}
+}
+
+func TestDecodeArrayElements_BoundedMemory(t *testing.T) {
+	const count = 20_000 // about 20MB of input
+
+	base := heapAlloc()
+	var peak uint64
+
+	err := geko.DecodeArrayElements(&arrayReader{count: count}, func(index int, elem any) error {
+		if i := elem.(geko.ObjectItems).Get("i"); i[0] != float64(index) {
+			t.Fatalf("Element %d not correct: %#v", index, elem)
+		}
+		if index%1000 == 0 {
+			if alloc := heapAlloc(); alloc > peak {
+				peak = alloc
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeArrayElements with error: %s", err.Error())
+	}
+
+	if peak > base && peak-base > 4<<20 {
+		t.Fatalf("Memory usage is not bounded, heap grows %d bytes", peak-base)
+	}
+}
@@ -0,0 +1,96 @@
+package geko
+
+// PushBack appends value to the end of list, it's same as [List.Append] with
+// one value.
+func (l *List[T]) PushBack(value T) {
+	mustNotFrozen(l.checkFrozen("PushBack"))
+
+	l.List = append(l.List, value)
+	_ = l.frontRoom() // drops deque buffer if reallocated
+	l.mods++
+}
+
+// PushFront inserts value at the start of list, in amortized O(1) time.
+//
+// Free slots before the list are kept in its backing array, they come from
+// [List.PopFront] or a reallocation made by PushFront, which reserves as many
+// slots as the length of list. If List field is replaced or reallocated by
+// other operations, the slots are dropped, which makes the next PushFront
+// reallocate.
+func (l *List[T]) PushFront(value T) {
+	mustNotFrozen(l.checkFrozen("PushFront"))
+
+	if l.frontRoom() == 0 {
+		room := len(l.List)
+		if room < 4 {
+			room = 4
+		}
+
+		buffer := make([]T, room+len(l.List), room+cap(l.List))
+		copy(buffer[room:], l.List)
+		l.buffer, l.head = buffer, room
+	}
+
+	l.head--
+	l.buffer[l.head] = value
+	l.List = l.buffer[l.head : l.head+len(l.List)+1]
+	l.mods++
+}
+
+// PopBack removes the last value of list, and returns it. The second return
+// value is false if list is empty.
+func (l *List[T]) PopBack() (T, bool) {
+	mustNotFrozen(l.checkFrozen("PopBack"))
+
+	var zero T
+	if len(l.List) == 0 {
+		return zero, false
+	}
+
+	last := len(l.List) - 1
+	value := l.List[last]
+	l.List[last] = zero
+	l.List = l.List[:last]
+	l.mods++
+
+	return value, true
+}
+
+// PopFront removes the first value of list, and returns it, in O(1) time. The
+// second return value is false if list is empty.
+//
+// The removed slot is cleared and kept for [List.PushFront]. Slots before the
+// list are released when the list is reallocated, for example by an
+// [List.Append] which exceeds its capacity, so a list used as FIFO queue with
+// PushBack and PopFront does not grow without bound.
+func (l *List[T]) PopFront() (T, bool) {
+	mustNotFrozen(l.checkFrozen("PopFront"))
+
+	var zero T
+	if len(l.List) == 0 {
+		return zero, false
+	}
+
+	if l.frontRoom() == 0 {
+		l.buffer, l.head = l.List[:cap(l.List)], 0
+	}
+
+	value := l.List[0]
+	l.List[0] = zero
+	l.List = l.List[1:]
+	l.head++
+	l.mods++
+
+	return value, true
+}
+
+// frontRoom returns count of free slots before List in its backing array. If
+// there is none, or List is not backed by buffer anymore, buffer is dropped so
+// it doesn't keep an old backing array alive.
+func (l *List[T]) frontRoom() int {
+	if l.head == 0 || cap(l.List) == 0 || cap(l.buffer) != l.head+cap(l.List) ||
+		&l.buffer[l.head:cap(l.buffer)][0] != &l.List[:1][0] {
+		l.buffer, l.head = nil, 0
+	}
+	return l.head
+}
@@ -0,0 +1,141 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestList_Deque(t *testing.T) {
+	l := geko.NewListOf(2, 3)
+
+	l.PushFront(1)
+	l.PushFront(0)
+	l.PushBack(4)
+
+	output, _ := json.Marshal(l)
+	if string(output) != `[0,1,2,3,4]` || l.Get(0) != 0 || l.Len() != 5 {
+		t.Fatalf("Deque push result not correct: %s", string(output))
+	}
+
+	if v, ok := l.PopFront(); !ok || v != 0 {
+		t.Fatalf("PopFront result not correct: %d, %v", v, ok)
+	}
+	if v, ok := l.PopBack(); !ok || v != 4 {
+		t.Fatalf("PopBack result not correct: %d, %v", v, ok)
+	}
+
+	// reuse slot freed by PopFront
+	l.PushFront(-1)
+	l.Set(0, 0)
+
+	output, _ = json.Marshal(l)
+	if string(output) != `[0,1,2,3]` {
+		t.Fatalf("Deque result not correct: %s", string(output))
+	}
+}
+
+func TestList_Deque_Empty(t *testing.T) {
+	l := geko.NewList[int]()
+
+	if v, ok := l.PopFront(); ok || v != 0 {
+		t.Fatalf("PopFront of empty list should fail: %d", v)
+	}
+	if v, ok := l.PopBack(); ok || v != 0 {
+		t.Fatalf("PopBack of empty list should fail: %d", v)
+	}
+
+	l.PushFront(1)
+	if v, ok := l.PopFront(); !ok || v != 1 || l.Len() != 0 {
+		t.Fatalf("PopFront result not correct: %d, %v", v, ok)
+	}
+	l.PushFront(2)
+	if v, ok := l.PopBack(); !ok || v != 2 || l.Len() != 0 {
+		t.Fatalf("PopBack result not correct: %d, %v", v, ok)
+	}
+}
+
+func TestList_Deque_ReplacedList(t *testing.T) {
+	l := geko.NewListOf(1, 2, 3)
+	_, _ = l.PopFront()
+
+	old := l.List
+	l.List = []int{4, 5}
+	l.PushFront(3)
+	l.Append(6, 7, 8, 9)
+	l.PushFront(2)
+	_, _ = l.PopFront()
+
+	output, _ := json.Marshal(l)
+	if string(output) != `[3,4,5,6,7,8,9]` || old[0] != 2 {
+		t.Fatalf("Deque result not correct after List replaced: %s, %v", string(output), old)
+	}
+}
+
+func TestList_Deque_Queue(t *testing.T) {
+	l := geko.NewList[int]()
+
+	next := 0
+	for i := 0; i < 1000; i++ {
+		l.PushBack(i)
+		l.PushBack(i)
+		if i%3 == 0 {
+			l.PushFront(-1)
+		}
+
+		for l.Len() > 10 {
+			v, _ := l.PopFront()
+			if v == -1 {
+				continue
+			}
+			if v != next/2 {
+				t.Fatalf("Queue order not correct, excepted %d, got %d", next/2, v)
+			}
+			next++
+		}
+	}
+
+	if cap(l.List) > 64 {
+		t.Fatalf("Queue backing array should not grow without bound: %d", cap(l.List))
+	}
+}
+
+func TestList_Deque_Frozen(t *testing.T) {
+	l := geko.NewListOf(1)
+	l.Freeze()
+
+	for _, fn := range []func(){
+		func() { l.PushBack(1) },
+		func() { l.PushFront(1) },
+		func() { _, _ = l.PopBack() },
+		func() { _, _ = l.PopFront() },
+	} {
+		assertPanicMessage(t, "geko: container is frozen", fn)
+	}
+}
+
+func BenchmarkList_PopFront(b *testing.B) {
+	for _, size := range []int{1_000, 10_000, 100_000} {
+		b.Run("Delete/"+strconv.Itoa(size), func(b *testing.B) {
+			l := geko.NewListWithCapacity[int](size)
+			for i := 0; i < b.N; i++ {
+				for l.Len() < size {
+					l.Append(i)
+				}
+				l.Delete(0)
+			}
+		})
+
+		b.Run("PopFront/"+strconv.Itoa(size), func(b *testing.B) {
+			l := geko.NewListWithCapacity[int](size)
+			for i := 0; i < b.N; i++ {
+				for l.Len() < size {
+					l.PushBack(i)
+				}
+				_, _ = l.PopFront()
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package geko
+
+import "strconv"
+
+// DiffOptions are options for controlling the behavior of [Diff].
+//
+// Default value (created by [CreateDiffOptions]) of it is:
+//
+//   - Order of object members is ignored.
+//
+// See also: [CreateDiffOptions], [OrderSensitive].
+type DiffOptions struct {
+	orderSensitive bool
+}
+
+// DiffOption is atom/modifier of [DiffOptions].
+type DiffOption func(opts *DiffOptions)
+
+// CreateDiffOptions creates a [DiffOptions] by apply all option to the
+// default diff option.
+func CreateDiffOptions(option ...DiffOption) DiffOptions {
+	opts := DiffOptions{}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *DiffOptions) Apply(option ...DiffOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// OrderSensitive specifies whether order of object members should be
+// considered by [Diff]. If enabled, members whose position changes are
+// removed and added again, so the patched object has the same order as the
+// target.
+func OrderSensitive(v bool) DiffOption {
+	return func(opts *DiffOptions) {
+		opts.orderSensitive = v
+	}
+}
+
+// Diff computes an [RFC 6902] JSON patch which transforms before into after,
+// so ApplyPatch(before, patch) is equal to after.
+//
+// The patch only contains "add", "remove" and "replace" operations, each of
+// them is an [Object] with members in order "op", "path" and "value". Values
+// in the patch are deep copies from after.
+//
+// [Object] and [ObjectItems] in before are compared member by member, an
+// [ObjectItems] with duplicated keys is replaced as a whole if changed.
+// [Array] in before is compared by position, no move detection is made. They
+// can be compared with map[string]any and []any in after. Other values,
+// including map[string]any and []any in before, are compared as a whole.
+// Numbers are compared by their decimal value, like [Hash].
+//
+// An error is returned if a number can't be compared, like NaN.
+//
+// [RFC 6902]: https://www.rfc-editor.org/rfc/rfc6902
+func Diff(before, after any, option ...DiffOption) (*List[any], error) {
+	d := differ{
+		opts:  CreateDiffOptions(option...),
+		patch: NewList[any](),
+	}
+
+	if err := d.diff("", before, after); err != nil {
+		return nil, err
+	}
+
+	if d.patch.List == nil {
+		d.patch.List = []any{}
+	}
+
+	return d.patch, nil
+}
+
+type differ struct {
+	opts  DiffOptions
+	patch Array
+}
+
+func (d *differ) add(op, path string, value any, hasValue bool) {
+	operation := NewMapWithCapacity[string, any](3)
+	operation.Set("op", op)
+	operation.Set("path", path)
+	if hasValue {
+		operation.Set("value", cloneValue(value))
+	}
+	d.patch.Append(operation)
+}
+
+func (d *differ) diff(path string, before, after any) error {
+	// only containers which JSON pointer can step into are compared inside
+	if !isNull(before) && !isNull(after) {
+		if x, isObject := objectMembers(before); isObject && isPointerObject(before) {
+			if y, bothObject := objectMembers(after); bothObject && !hasDuplicateKeys(x) && !hasDuplicateKeys(y) {
+				return d.diffObject(path, x, y)
+			}
+		}
+
+		if array, isArray := before.(Array); isArray {
+			if y, bothArray := arrayItems(after); bothArray {
+				return d.diffArray(path, array.List, y)
+			}
+		}
+	}
+
+	for _, v := range []any{before, after} {
+		if _, isNumber, err := hashNumber(v); isNumber && err != nil {
+			return err
+		}
+	}
+
+	if !jsonEqual(before, after, d.opts.orderSensitive) {
+		d.add("replace", path, after, true)
+	}
+
+	return nil
+}
+
+func (d *differ) diffObject(path string, before, after []Pair[string, any]) error {
+	afterIndex := make(map[string]int, len(after))
+	for i, pair := range after {
+		afterIndex[pair.Key] = i
+	}
+
+	// keys in both, in order of before
+	common := make([]string, 0, len(before))
+	beforeIndex := make(map[string]int, len(before))
+	for i, pair := range before {
+		beforeIndex[pair.Key] = i
+		if _, exist := afterIndex[pair.Key]; exist {
+			common = append(common, pair.Key)
+		} else {
+			d.add("remove", path+"/"+escapePointerToken(pair.Key), nil, false)
+		}
+	}
+
+	// after[:keep] are common keys already in their final position
+	keep := 0
+	if d.opts.orderSensitive {
+		for keep < len(common) && after[keep].Key == common[keep] {
+			keep++
+		}
+	}
+
+	for i, pair := range after {
+		memberPath := path + "/" + escapePointerToken(pair.Key)
+		index, exist := beforeIndex[pair.Key]
+
+		switch {
+		case !exist:
+			d.add("add", memberPath, pair.Value, true)
+		case d.opts.orderSensitive && i >= keep:
+			d.add("remove", memberPath, nil, false)
+			d.add("add", memberPath, pair.Value, true)
+		default:
+			if err := d.diff(memberPath, before[index].Value, pair.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) diffArray(path string, before, after []any) error {
+	n := len(before)
+	if len(after) < n {
+		n = len(after)
+	}
+
+	for i := 0; i < n; i++ {
+		if err := d.diff(path+"/"+strconv.Itoa(i), before[i], after[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := len(before) - 1; i >= n; i-- {
+		d.add("remove", path+"/"+strconv.Itoa(i), nil, false)
+	}
+
+	for i := n; i < len(after); i++ {
+		d.add("add", path+"/"+strconv.Itoa(i), after[i], true)
+	}
+
+	return nil
+}
+
+func hasDuplicateKeys(members []Pair[string, any]) bool {
+	seen := make(map[string]struct{}, len(members))
+	for _, pair := range members {
+		if _, exist := seen[pair.Key]; exist {
+			return true
+		}
+		seen[pair.Key] = struct{}{}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+var diffKeys = []string{"a", "b", "c", "a/b", "~1", ""}
+
+func randomDiffValue(r *rand.Rand, depth int) any {
+	kind := r.Intn(7)
+	if depth <= 0 {
+		kind %= 4
+	}
+
+	switch kind {
+	case 0:
+		return nil
+	case 1:
+		return r.Intn(2) == 0
+	case 2:
+		return float64(r.Intn(3))
+	case 3:
+		return diffKeys[r.Intn(len(diffKeys))]
+	case 4, 5:
+		object := geko.NewMap[string, any]()
+		for i := r.Intn(4); i > 0; i-- {
+			object.Set(diffKeys[r.Intn(len(diffKeys))], randomDiffValue(r, depth-1))
+		}
+		return object
+	default:
+		array := geko.NewList[any]()
+		for i := r.Intn(4); i > 0; i-- {
+			array.Append(randomDiffValue(r, depth-1))
+		}
+		return array
+	}
+}
+
+// mutateDiffValue returns a modified copy of v.
+func mutateDiffValue(r *rand.Rand, v any, depth int) any {
+	if r.Intn(8) == 0 {
+		return randomDiffValue(r, depth)
+	}
+
+	switch x := v.(type) {
+	case geko.Object:
+		object := geko.NewMap[string, any]()
+		for _, i := range r.Perm(x.Len()) {
+			if r.Intn(4) != 0 {
+				pair := x.GetByIndex(i)
+				object.Set(pair.Key, mutateDiffValue(r, pair.Value, depth-1))
+			}
+		}
+		if r.Intn(2) == 0 {
+			object.Set(diffKeys[r.Intn(len(diffKeys))], randomDiffValue(r, depth-1))
+		}
+		return object
+	case geko.Array:
+		array := geko.NewList[any]()
+		for _, item := range x.List {
+			if r.Intn(4) != 0 {
+				array.Append(mutateDiffValue(r, item, depth-1))
+			}
+		}
+		for i := r.Intn(3); i > 0; i-- {
+			array.Append(randomDiffValue(r, depth-1))
+		}
+		return array
+	default:
+		return randomDiffValue(r, 0)
+	}
+}
+
+func TestDiff_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		before := randomDiffValue(r, 4)
+		after := mutateDiffValue(r, before, 4)
+
+		patch, err := geko.Diff(before, after)
+		if err != nil {
+			t.Fatalf("Diff with error: %s", err.Error())
+		}
+
+		result, err := geko.ApplyPatch(before, patch)
+		if err != nil {
+			t.Fatalf("ApplyPatch with error: %s", err.Error())
+		}
+
+		if mustSum64(t, result, geko.IgnoreKeyOrder(true)) != mustSum64(t, after, geko.IgnoreKeyOrder(true)) {
+			output, _ := json.Marshal([]any{before, after, patch, result})
+			t.Fatalf("Diff round trip failed: %s", string(output))
+		}
+
+		patch, err = geko.Diff(before, after, geko.OrderSensitive(true))
+		if err != nil {
+			t.Fatalf("Diff with error: %s", err.Error())
+		}
+
+		result, err = geko.ApplyPatch(before, patch)
+		if err != nil {
+			t.Fatalf("ApplyPatch with error: %s", err.Error())
+		}
+
+		// exactly same JSON output, including member order
+		resultOutput, _ := json.Marshal(result)
+		afterOutput, _ := json.Marshal(after)
+		if string(resultOutput) != string(afterOutput) {
+			output, _ := json.Marshal([]any{before, after, patch, result})
+			t.Fatalf("Diff order sensitive round trip failed: %s", string(output))
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before, _ := geko.JSONUnmarshal(
+		[]byte(`{"a": 1, "b": [1, 2, 3], "c": {"d": 1, "x/y": 2}, "g": [], "h": "s"}`),
+		geko.UseObject(),
+	)
+	after, _ := geko.JSONUnmarshal(
+		[]byte(`{"a": 2, "b": [1, 3], "c": {"e": 1, "x/y": 2.0}, "f": "x", "g": [1, {}], "h": ["s"]}`),
+		geko.UseObject(),
+	)
+
+	patch, err := geko.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(patch)
+	excepted := `[{"op":"replace","path":"/a","value":2},` +
+		`{"op":"replace","path":"/b/1","value":3},{"op":"remove","path":"/b/2"},` +
+		`{"op":"remove","path":"/c/d"},{"op":"add","path":"/c/e","value":1},` +
+		`{"op":"add","path":"/f","value":"x"},` +
+		`{"op":"add","path":"/g/0","value":1},{"op":"add","path":"/g/1","value":{}},` +
+		`{"op":"replace","path":"/h","value":["s"]}]`
+	if string(output) != excepted {
+		t.Fatalf("Diff result not correct: %s", string(output))
+	}
+}
+
+func TestDiff_KeyOrder(t *testing.T) {
+	before, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "b": {"x": 1, "y": 2}, "c": 3}`), geko.UseObject())
+	after, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "c": 3, "b": {"y": 2, "x": 1}}`), geko.UseObject())
+
+	patch, err := geko.Diff(before, after)
+	if err != nil || patch.Len() != 0 {
+		t.Fatalf("Diff should ignore key order by default: %#v, %#v", patch, err)
+	}
+
+	patch, err = geko.Diff(before, after, geko.OrderSensitive(true))
+	if err != nil {
+		t.Fatalf("Diff with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(patch)
+	excepted := `[{"op":"remove","path":"/c"},{"op":"add","path":"/c","value":3},` +
+		`{"op":"remove","path":"/b"},{"op":"add","path":"/b","value":{"y":2,"x":1}}]`
+	if string(output) != excepted {
+		t.Fatalf("Diff result not correct: %s", string(output))
+	}
+
+	result, _ := geko.ApplyPatch(before, patch)
+	output, _ = json.Marshal(result)
+	if string(output) != `{"a":1,"c":3,"b":{"y":2,"x":1}}` {
+		t.Fatalf("Diff round trip result not correct: %s", string(output))
+	}
+}
+
+func TestDiff_ObjectItems(t *testing.T) {
+	before, _ := geko.JSONUnmarshal([]byte(`{"a": {"x": 1, "x": 2}, "b": {"y": 1}, "c": {"z": 1, "z": 1}}`))
+	after, _ := geko.JSONUnmarshal([]byte(`{"a": {"x": 1, "x": 3}, "b": {"y": 2}, "c": {"z": 1, "z": 1}}`))
+
+	patch, err := geko.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(patch)
+	excepted := `[{"op":"replace","path":"/a","value":{"x":1,"x":3}},{"op":"replace","path":"/b/y","value":2}]`
+	if string(output) != excepted {
+		t.Fatalf("Diff result not correct: %s", string(output))
+	}
+}
+
+func TestDiff_StdTypes(t *testing.T) {
+	before, _ := geko.JSONUnmarshal([]byte(`{"a": {"x": 1}, "b": [1, 2]}`), geko.UseObject())
+	before.(geko.Object).Set("c", map[string]any{"y": 1})
+
+	after := map[string]any{
+		"a": map[string]any{"x": 2},
+		"b": []any{1, 3},
+		"c": map[string]any{"y": 2},
+	}
+
+	patch, err := geko.Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(patch)
+	excepted := `[{"op":"replace","path":"/a/x","value":2},{"op":"replace","path":"/b/1","value":3},` +
+		`{"op":"replace","path":"/c","value":{"y":2}}]`
+	if string(output) != excepted {
+		t.Fatalf("Diff result not correct: %s", string(output))
+	}
+}
+
+func TestDiff_Null(t *testing.T) {
+	var m geko.Object
+
+	patch, err := geko.Diff(geko.NewListFrom([]any{m, nil, 1}), geko.NewListFrom([]any{nil, m, nil}))
+	if err != nil {
+		t.Fatalf("Diff with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(patch)
+	if string(output) != `[{"op":"replace","path":"/2","value":null}]` {
+		t.Fatalf("Diff result not correct: %s", string(output))
+	}
+
+	patch, _ = geko.Diff(nil, nil)
+	output, _ = json.Marshal(patch)
+	if string(output) != `[]` {
+		t.Fatalf("Diff of equal values should be empty: %s", string(output))
+	}
+}
+
+func TestDiff_Error(t *testing.T) {
+	before, _ := geko.JSONUnmarshal([]byte(`{"a": [1], "b": 2}`), geko.UseObject())
+
+	for _, after := range []any{
+		map[string]any{"a": []any{math.NaN()}, "b": 2},
+		map[string]any{"a": []any{1}, "b": math.Inf(1)},
+	} {
+		if patch, err := geko.Diff(before, after); err == nil {
+			t.Fatalf("Diff should fail on invalid number: %#v", patch)
+		}
+	}
+}
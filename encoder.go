@@ -0,0 +1,438 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// EncodeOptions are options for controlling the behavior of JSON encoding.
+//
+// Default value (created by [CreateEncodeOptions]) of it is:
+//
+//   - No indent.
+//   - Escape HTML characters in strings, like [json.Marshal].
+//   - Keep the original order of keys.
+//   - Keep members with null value or empty container value.
+//   - Keys are not transformed.
+//
+// See also: [CreateEncodeOptions], [Indent], [EscapeHTML], [SortKeys],
+// [DropNullValues], [DropEmptyContainers], [MarshalKeyTransform].
+type EncodeOptions struct {
+	prefix              string
+	indent              string
+	escapeHTML          bool
+	sortKeys            bool
+	dropNullValues      bool
+	dropEmptyContainers bool
+	keyTransform        func(key string) string
+}
+
+// EncodeOption is atom/modifier of [EncodeOptions].
+type EncodeOption func(opts *EncodeOptions)
+
+// CreateEncodeOptions creates a [EncodeOptions] by apply all option to the
+// default encode option.
+func CreateEncodeOptions(option ...EncodeOption) EncodeOptions {
+	opts := EncodeOptions{escapeHTML: true}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *EncodeOptions) Apply(option ...EncodeOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// Indent makes output indented, like [json.MarshalIndent]. Calling
+// Indent("", "") disables indentation.
+func Indent(prefix, indent string) EncodeOption {
+	return func(opts *EncodeOptions) {
+		opts.prefix = prefix
+		opts.indent = indent
+	}
+}
+
+// EscapeHTML specifies whether problematic HTML characters should be escaped
+// inside JSON quoted strings.
+//
+// See [json.Encoder.SetEscapeHTML] for detail.
+func EscapeHTML(v bool) EncodeOption {
+	return func(opts *EncodeOptions) {
+		opts.escapeHTML = v
+	}
+}
+
+// SortKeys specifies whether keys of [Map] and [Pairs] should be sorted in
+// output. Values of duplicated key in [Pairs] keep their relative order.
+//
+// Notice: it has no effect on containers not from this package, like a
+// map[string]any, or any geko container nested inside them.
+func SortKeys(v bool) EncodeOption {
+	return func(opts *EncodeOptions) {
+		opts.sortKeys = v
+	}
+}
+
+// DropNullValues specifies whether members of [Map] and [Pairs] whose value
+// encodes to JSON null should be omitted from output.
+//
+// Items of [List] are never dropped, because their position matters.
+// Like [SortKeys], it has no effect on containers not from this package.
+func DropNullValues(v bool) EncodeOption {
+	return func(opts *EncodeOptions) {
+		opts.dropNullValues = v
+	}
+}
+
+// DropEmptyContainers specifies whether members of [Map] and [Pairs] whose
+// value encodes to an empty JSON object or array should be omitted from
+// output. It works after [DropNullValues], so an object becomes empty because
+// all its members are dropped is also omitted.
+//
+// Like [DropNullValues], items of [List] are never dropped, and it has no
+// effect on containers not from this package, but it checks the output of
+// them, so an empty map[string]any member is also omitted.
+func DropEmptyContainers(v bool) EncodeOption {
+	return func(opts *EncodeOptions) {
+		opts.dropEmptyContainers = v
+	}
+}
+
+// MarshalKeyTransform sets a function to transform every key of [Map] and
+// [Pairs] before it is written, at all nesting levels. The source containers
+// are not modified. Set it to nil to disable it.
+//
+// If [SortKeys] is also applied, the transformed keys are sorted. Like
+// [SortKeys], it has no effect on containers not from this package.
+//
+// See [SnakeToCamel] and [ToLower] for some ready-made transforms.
+func MarshalKeyTransform(f func(key string) string) EncodeOption {
+	return func(opts *EncodeOptions) {
+		opts.keyTransform = f
+	}
+}
+
+// SnakeToCamel converts a snake_case key into camelCase, for
+// [MarshalKeyTransform] and [KeyTransform]. Leading underscores are kept.
+//
+//	user_id => userId
+//	_private_key => _privateKey
+func SnakeToCamel(key string) string {
+	var b strings.Builder
+	b.Grow(len(key))
+
+	i := 0
+	for i < len(key) && key[i] == '_' {
+		_ = b.WriteByte('_')
+		i++
+	}
+
+	upper := false
+	for _, r := range key[i:] {
+		if r == '_' {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		_, _ = b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// ToLower converts a key into lower case, for [MarshalKeyTransform] and
+// [KeyTransform].
+func ToLower(key string) string {
+	return strings.ToLower(key)
+}
+
+// Encoder writes JSON values to an output stream.
+//
+// It's the mirror of [Decoder]. It understands types in this package
+// natively, so formatting settings, like indent and key sorting, also apply to
+// [Map], [Pairs] and [List] nested inside them. Other values are encoded by
+// [json.Encoder] with the same settings.
+type Encoder struct {
+	w    io.Writer
+	opts EncodeOptions
+}
+
+// NewEncoder creates a new [Encoder] that writes to w, with encode options.
+//
+// Like [json.Encoder], HTML characters in strings are escaped by default.
+func NewEncoder(w io.Writer, option ...EncodeOption) *Encoder {
+	return &Encoder{
+		w:    w,
+		opts: CreateEncodeOptions(option...),
+	}
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded value as
+// if indented by [json.Indent]. Calling SetIndent("", "") disables
+// indentation.
+func (enc *Encoder) SetIndent(prefix, indent string) {
+	enc.opts.Apply(Indent(prefix, indent))
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be
+// escaped inside JSON quoted strings.
+//
+// See [json.Encoder.SetEscapeHTML] for detail.
+func (enc *Encoder) SetEscapeHTML(on bool) {
+	enc.opts.Apply(EscapeHTML(on))
+}
+
+// SetSortKeys specifies whether keys of [Map] and [Pairs] should be sorted in
+// output. Values of duplicated key in [Pairs] keep their relative order.
+//
+// Notice: it has no effect on containers not from this package, like a
+// map[string]any, or any geko container nested inside them.
+func (enc *Encoder) SetSortKeys(on bool) {
+	enc.opts.Apply(SortKeys(on))
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline
+// character.
+func (enc *Encoder) Encode(v any) error {
+	e := acquireEncodeState(enc.opts)
+	defer releaseEncodeState(e)
+
+	if err := e.value(v); err != nil {
+		return err
+	}
+
+	_ = e.WriteByte('\n')
+
+	_, err := enc.w.Write(e.Bytes())
+	return err
+}
+
+// JSONMarshal returns the JSON encoding of v, with provided option applied.
+//
+// Options apply recursively to [Map], [Pairs] and [List] nested in v, other
+// values are encoded by [json.Encoder] with the same indent and escape
+// settings. Unlike [Encoder.Encode], no trailing newline is added.
+func JSONMarshal(v any, option ...EncodeOption) ([]byte, error) {
+	e := acquireEncodeState(CreateEncodeOptions(option...))
+	defer releaseEncodeState(e)
+
+	if err := e.value(v); err != nil {
+		return nil, err
+	}
+	return e.copyBytes(), nil
+}
+
+// jsonEncodable is implemented by types in this package, to encode themselves
+// with options, instead of using their MarshalJSON method.
+type jsonEncodable interface {
+	encodeJSON(e *encodeState) error
+}
+
+type encodeState struct {
+	bytes.Buffer
+
+	opts  EncodeOptions
+	depth int
+
+	std *json.Encoder
+}
+
+// maxPooledEncodeBuffer is the max capacity of buffer an encodeState can have
+// to be put back into pool, so a rare huge output does not stay in memory.
+const maxPooledEncodeBuffer = 64 << 10
+
+// encodeStatePool reduces allocations when encoding lots of small values, by
+// reusing the buffer and the std json.Encoder writes into it.
+var encodeStatePool = sync.Pool{
+	New: func() any {
+		e := &encodeState{}
+		e.std = json.NewEncoder(&e.Buffer)
+		return e
+	},
+}
+
+// acquireEncodeState gets an empty encodeState from pool. It must be put back
+// by releaseEncodeState, and its bytes can't be used after that, use
+// copyBytes if they need to be returned.
+func acquireEncodeState(opts EncodeOptions) *encodeState {
+	e, _ := encodeStatePool.Get().(*encodeState)
+	e.opts = opts
+	e.depth = 0
+	e.std.SetEscapeHTML(opts.escapeHTML)
+	e.std.SetIndent("", "")
+	return e
+}
+
+func releaseEncodeState(e *encodeState) {
+	if e.Cap() > maxPooledEncodeBuffer {
+		return
+	}
+
+	e.Reset()
+	// do not hold references of options in pool
+	e.opts = EncodeOptions{}
+	encodeStatePool.Put(e)
+}
+
+// copyBytes returns a copy of the output, which is safe to use after e is
+// released.
+func (e *encodeState) copyBytes() []byte {
+	return append([]byte(nil), e.Bytes()...)
+}
+
+func (e *encodeState) indenting() bool {
+	return e.opts.prefix != "" || e.opts.indent != ""
+}
+
+// newline starts a new line with indent of current depth, if indent enabled.
+func (e *encodeState) newline() {
+	if !e.indenting() {
+		return
+	}
+
+	_ = e.WriteByte('\n')
+	_, _ = e.WriteString(e.opts.prefix)
+	for i := 0; i < e.depth; i++ {
+		_, _ = e.WriteString(e.opts.indent)
+	}
+}
+
+func (e *encodeState) colon() {
+	_ = e.WriteByte(':')
+	if e.indenting() {
+		_ = e.WriteByte(' ')
+	}
+}
+
+// droppable reports whether a object member should be dropped, by its value
+// output written since start.
+func (e *encodeState) droppable(start int) bool {
+	value := e.Bytes()[start:]
+	if len(value) > len("null") {
+		return false
+	}
+
+	switch string(value) {
+	case "null":
+		return e.opts.dropNullValues
+	case "{}", "[]":
+		return e.opts.dropEmptyContainers
+	default:
+		return false
+	}
+}
+
+// key writes an object key. Most keys are plain ASCII, which need no escaping,
+// so they are written directly, without the cost of the std encoder. Others
+// still go through it, so the output is always the same as std lib.
+func (e *encodeState) key(s string) {
+	if !isPlainString(s, e.opts.escapeHTML) {
+		// string encoding never fail
+		_ = e.value(s)
+		return
+	}
+
+	_ = e.WriteByte('"')
+	_, _ = e.WriteString(s)
+	_ = e.WriteByte('"')
+}
+
+// isPlainString reports whether s contains only printable ASCII characters
+// which need no escaping in a JSON string.
+func isPlainString(s string, escapeHTML bool) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c < 0x20 || c > 0x7e || c == '"' || c == '\\':
+			return false
+		case escapeHTML && (c == '<' || c == '>' || c == '&'):
+			return false
+		}
+	}
+	return true
+}
+
+// withMarshalPath adds segment, a key or index, to the front of the path of
+// err if it comes from a nested container, otherwise wraps err with it.
+//
+// Errors from values encoded by std lib, like a custom Marshaler which
+// encodes a container of this package inside, are not looked into, so their
+// context is not lost.
+func withMarshalPath(err error, segment any) error {
+	if pathErr, ok := err.(*MarshalError); ok {
+		pathErr.Path = append([]any{segment}, pathErr.Path...)
+		return pathErr
+	}
+	return &MarshalError{Path: []any{segment}, Err: err}
+}
+
+// Like std lib does for float64, big floats out of this range are written in
+// exponent notation, otherwise a short input like 1e1000000 would expand into
+// a huge output.
+var (
+	bigFloatPlainMin = big.NewFloat(1e-6)
+	bigFloatPlainMax = big.NewFloat(1e21)
+)
+
+// bigFloat writes f as a number, because std lib encodes it as a string.
+func (e *encodeState) bigFloat(f *big.Float) error {
+	if f == nil {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+
+	if f.IsInf() {
+		return &json.UnsupportedValueError{
+			Value: reflect.ValueOf(f),
+			Str:   f.String(),
+		}
+	}
+
+	format := byte('f')
+	abs := new(big.Float).Abs(f)
+	if abs.Sign() != 0 && (abs.Cmp(bigFloatPlainMin) < 0 || abs.Cmp(bigFloatPlainMax) >= 0) {
+		format = 'e'
+	}
+
+	_, _ = e.WriteString(f.Text(format, -1))
+	return nil
+}
+
+func (e *encodeState) value(v any) error {
+	switch x := v.(type) {
+	case jsonEncodable:
+		return x.encodeJSON(e)
+	case *big.Float:
+		return e.bigFloat(x)
+	}
+
+	return e.stdValue(v)
+}
+
+// stdValue encodes v by std encoder, without special cases of value.
+func (e *encodeState) stdValue(v any) error {
+	if e.indenting() {
+		e.std.SetIndent(e.opts.prefix+strings.Repeat(e.opts.indent, e.depth), e.opts.indent)
+	}
+
+	if err := e.std.Encode(v); err != nil {
+		return err
+	}
+
+	// remove the newline added by std encoder
+	e.Truncate(e.Len() - 1)
+
+	return nil
+}
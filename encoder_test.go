@@ -0,0 +1,450 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const encoderTestInput = `{
+	"z": 1,
+	"html": "<a&b>",
+	"obj": {"b": [], "a": {}, "c": [1, {"y": null, "x": true}]},
+	"z": "dup",
+	"std": [{"b": 1, "a": 2}],
+	"empty": []
+}`
+
+func encoderTestValue(t *testing.T) any {
+	t.Helper()
+
+	v, err := geko.JSONUnmarshal([]byte(encoderTestInput))
+	if err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	// replace with a std map, to check fallback
+	v.(geko.ObjectItems).SetValueByIndex(4, []any{map[string]any{"b": 1, "a": 2}})
+
+	return v
+}
+
+func encodeToString(t *testing.T, v any, setup func(enc *geko.Encoder)) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := geko.NewEncoder(&buf)
+	setup(enc)
+
+	if err := enc.Encode(v); err != nil {
+		t.Fatalf("Encode with error: %s", err.Error())
+	}
+
+	return buf.String()
+}
+
+func TestEncoder_Default(t *testing.T) {
+	output := encodeToString(t, encoderTestValue(t), func(_ *geko.Encoder) {})
+
+	excepted := `{"z":1,"html":"\u003ca\u0026b\u003e",` +
+		`"obj":{"b":[],"a":{},"c":[1,{"y":null,"x":true}]},` +
+		`"z":"dup","std":[{"a":2,"b":1}],"empty":[]}` + "\n"
+
+	if output != excepted {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+}
+
+func TestEncoder_SetIndent(t *testing.T) {
+	output := encodeToString(t, encoderTestValue(t), func(enc *geko.Encoder) {
+		enc.SetIndent(">", "  ")
+		enc.SetEscapeHTML(false)
+	})
+
+	excepted := `{
+>  "z": 1,
+>  "html": "<a&b>",
+>  "obj": {
+>    "b": [],
+>    "a": {},
+>    "c": [
+>      1,
+>      {
+>        "y": null,
+>        "x": true
+>      }
+>    ]
+>  },
+>  "z": "dup",
+>  "std": [
+>    {
+>      "a": 2,
+>      "b": 1
+>    }
+>  ],
+>  "empty": []
+>}
+`
+
+	if output != excepted {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+}
+
+func TestEncoder_SetIndent_SameAsStd(t *testing.T) {
+	data := []byte(`{"a": [1, {"b": [], "c": {}}, "s"], "d": {"e": null}}`)
+
+	v, _ := geko.JSONUnmarshal(data)
+	output := encodeToString(t, v, func(enc *geko.Encoder) {
+		enc.SetIndent("", "\t")
+	})
+
+	var std any
+	_ = json.Unmarshal(data, &std)
+	excepted, _ := json.MarshalIndent(std, "", "\t")
+
+	if output != string(excepted)+"\n" {
+		t.Fatalf("Encode result not same as std:\n%s\n%s", output, string(excepted))
+	}
+}
+
+func TestEncoder_SetSortKeys(t *testing.T) {
+	output := encodeToString(t, encoderTestValue(t), func(enc *geko.Encoder) {
+		enc.SetSortKeys(true)
+	})
+
+	excepted := `{"empty":[],"html":"\u003ca\u0026b\u003e",` +
+		`"obj":{"a":{},"b":[],"c":[1,{"x":true,"y":null}]},` +
+		`"std":[{"a":2,"b":1}],"z":1,"z":"dup"}` + "\n"
+
+	if output != excepted {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+
+	m := geko.NewMap[string, int]()
+	m.Set("b", 1)
+	m.Set("a", 2)
+
+	output = encodeToString(t, m, func(enc *geko.Encoder) {
+		enc.SetSortKeys(true)
+	})
+
+	if output != `{"a":2,"b":1}`+"\n" {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+}
+
+func TestEncoder_NilAndAny(t *testing.T) {
+	var m *geko.Map[string, int]
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	output := encodeToString(t, []any{m, ps, l, geko.Any{Value: geko.NewList[any]()}}, func(_ *geko.Encoder) {})
+	if output != "[null,null,null,[]]\n" {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+
+	output = encodeToString(t, geko.NewListFrom([]any{m, ps, l, geko.Any{}}), func(_ *geko.Encoder) {})
+	if output != "[null,null,null,null]\n" {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+}
+
+func TestEncoder_Error(t *testing.T) {
+	var buf bytes.Buffer
+	enc := geko.NewEncoder(&buf)
+
+	if err := enc.Encode(geko.NewMap[int, int]()); err == nil {
+		t.Fatalf("Encode Map with non-string key should report error")
+	}
+
+	obj := geko.NewMap[string, any]()
+	obj.Set("chan", make(chan int))
+	if err := enc.Encode(obj); err == nil {
+		t.Fatalf("Encode unsupported value should report error")
+	}
+
+	if err := enc.Encode(geko.NewListFrom([]any{make(chan int)})); err == nil {
+		t.Fatalf("Encode unsupported value should report error")
+	}
+
+	if buf.Len() != 0 {
+		t.Fatalf("Encode should not write anything when error: %s", buf.String())
+	}
+
+	if err := geko.NewEncoder(&limitedWriter{}).Encode(1); err == nil {
+		t.Fatalf("Encode should report write error")
+	}
+}
+
+func TestEncoder_BigFloat(t *testing.T) {
+	var f *big.Float
+	output := encodeToString(t, geko.NewListFrom([]any{f, big.NewFloat(0.5)}), func(_ *geko.Encoder) {})
+	if output != "[null,0.5]\n" {
+		t.Fatalf("Encode result not correct:\n%s", output)
+	}
+
+	if err := geko.NewEncoder(io.Discard).Encode(new(big.Float).SetInf(false)); err == nil {
+		t.Fatalf("Encode infinity big.Float should report error")
+	}
+}
+
+func jsonMarshalToString(t *testing.T, v any, option ...geko.EncodeOption) string {
+	t.Helper()
+
+	data, err := geko.JSONMarshal(v, option...)
+	if err != nil {
+		t.Fatalf("JSONMarshal with error: %s", err.Error())
+	}
+
+	return string(data)
+}
+
+func TestJSONMarshal_Default(t *testing.T) {
+	output := jsonMarshalToString(t, encoderTestValue(t))
+
+	excepted := `{"z":1,"html":"\u003ca\u0026b\u003e",` +
+		`"obj":{"b":[],"a":{},"c":[1,{"y":null,"x":true}]},` +
+		`"z":"dup","std":[{"a":2,"b":1}],"empty":[]}`
+
+	if output != excepted {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	m := geko.NewMap[string, any]()
+	m.Set("html", "<a&b>")
+	std, _ := json.Marshal(map[string]any{"html": "<a&b>"})
+	if output := jsonMarshalToString(t, m); output != string(std) {
+		t.Fatalf("JSONMarshal result not same as std: %s, %s", output, string(std))
+	}
+}
+
+func TestJSONMarshal_Indent(t *testing.T) {
+	output := jsonMarshalToString(t, encoderTestValue(t), geko.Indent("", " "))
+
+	excepted := `{
+ "z": 1,
+ "html": "\u003ca\u0026b\u003e",
+ "obj": {
+  "b": [],
+  "a": {},
+  "c": [
+   1,
+   {
+    "y": null,
+    "x": true
+   }
+  ]
+ },
+ "z": "dup",
+ "std": [
+  {
+   "a": 2,
+   "b": 1
+  }
+ ],
+ "empty": []
+}`
+
+	if output != excepted {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+}
+
+func TestJSONMarshal_EscapeHTML(t *testing.T) {
+	output := jsonMarshalToString(t, encoderTestValue(t), geko.EscapeHTML(false))
+
+	excepted := `{"z":1,"html":"<a&b>",` +
+		`"obj":{"b":[],"a":{},"c":[1,{"y":null,"x":true}]},` +
+		`"z":"dup","std":[{"a":2,"b":1}],"empty":[]}`
+
+	if output != excepted {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+}
+
+func TestJSONMarshal_SortKeys(t *testing.T) {
+	output := jsonMarshalToString(t, encoderTestValue(t), geko.SortKeys(true))
+
+	excepted := `{"empty":[],"html":"\u003ca\u0026b\u003e",` +
+		`"obj":{"a":{},"b":[],"c":[1,{"x":true,"y":null}]},` +
+		`"std":[{"a":2,"b":1}],"z":1,"z":"dup"}`
+
+	if output != excepted {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+}
+
+func TestJSONMarshal_DropNullValues(t *testing.T) {
+	var m *geko.Map[string, any]
+
+	v, _ := geko.JSONUnmarshal([]byte(`{"a": null, "b": [null, {"c": null}], "d": 1, "e": null}`))
+	v.(geko.ObjectItems).Add("f", m)
+	v.(geko.ObjectItems).Add("g", json.RawMessage("null"))
+
+	output := jsonMarshalToString(t, v, geko.DropNullValues(true))
+	if output != `{"b":[null,{}],"d":1}` {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	output = jsonMarshalToString(t, v, geko.DropNullValues(true), geko.Indent("", "  "))
+	excepted := `{
+  "b": [
+    null,
+    {}
+  ],
+  "d": 1
+}`
+	if output != excepted {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	output = jsonMarshalToString(t, v)
+	if output != `{"a":null,"b":[null,{"c":null}],"d":1,"e":null,"f":null,"g":null}` {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+}
+
+func TestJSONMarshal_Error(t *testing.T) {
+	if _, err := geko.JSONMarshal(geko.NewMap[int, int]()); err == nil {
+		t.Fatalf("JSONMarshal Map with non-string key should report error")
+	}
+
+	if _, err := geko.JSONMarshal(make(chan int)); err == nil {
+		t.Fatalf("JSONMarshal unsupported value should report error")
+	}
+}
+
+func TestJSONMarshal_KeyEscaping(t *testing.T) {
+	keys := []string{
+		"", "plain", "with space", "~!@#$%^*()_+-=[]{}|;':,./?`",
+		`"quoted"`, `back\slash`, "\n\r\t\b\f", "\x00\x01\x1f", "\x7f",
+		"<a&b>", "caf\u00e9", "\u4e2d\u6587", "\u2028\u2029", "\U0001f600", "invalid\xff\xfe",
+	}
+
+	for _, key := range keys {
+		m := geko.NewMap[string, int]()
+		m.Set(key, 1)
+
+		for _, escapeHTML := range []bool{false, true} {
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(escapeHTML)
+			_ = enc.Encode(map[string]int{key: 1})
+			excepted := string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+
+			output, err := geko.JSONMarshal(m, geko.EscapeHTML(escapeHTML))
+			if err != nil || string(output) != excepted {
+				t.Fatalf("Key %q with escape html %v encoded as %s, excepted %s", key, escapeHTML, output, excepted)
+			}
+		}
+
+		output, _ := json.Marshal(m)
+		excepted, _ := json.Marshal(map[string]int{key: 1})
+		if string(output) != string(excepted) {
+			t.Fatalf("Key %q marshaled as %s, excepted %s", key, output, excepted)
+		}
+	}
+}
+
+func TestNewEncoder_Options(t *testing.T) {
+	var buf bytes.Buffer
+	enc := geko.NewEncoder(&buf, geko.SortKeys(true), geko.DropNullValues(true))
+
+	v, _ := geko.JSONUnmarshal([]byte(`{"b": null, "a": 1}`))
+	if err := enc.Encode(v); err != nil {
+		t.Fatalf("Encode with error: %s", err.Error())
+	}
+
+	if buf.String() != `{"a":1}`+"\n" {
+		t.Fatalf("Encode result not correct:\n%s", buf.String())
+	}
+}
+
+func TestJSONMarshal_MarshalKeyTransform(t *testing.T) {
+	v, _ := geko.JSONUnmarshal(
+		[]byte(`{"user_id": 1, "Full_Name": "a", "tags": [{"tag_name": "x"}], "_private_key": null}`),
+		geko.UseObject(),
+	)
+	object := v.(geko.Object)
+
+	output := jsonMarshalToString(t, object, geko.MarshalKeyTransform(geko.SnakeToCamel))
+	if output != `{"userId":1,"FullName":"a","tags":[{"tagName":"x"}],"_privateKey":null}` {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	output = jsonMarshalToString(t, object, geko.MarshalKeyTransform(geko.ToLower), geko.SortKeys(true))
+	if output != `{"_private_key":null,"full_name":"a","tags":[{"tag_name":"x"}],"user_id":1}` {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	if !reflect.DeepEqual(object.Keys(), []string{"user_id", "Full_Name", "tags", "_private_key"}) ||
+		object.GetOrZeroValue("tags").(geko.Array).Get(0).(geko.Object).Keys()[0] != "tag_name" {
+		t.Fatalf("Source keys should not be changed: %#v", object.Keys())
+	}
+}
+
+func TestSnakeToCamel(t *testing.T) {
+	cases := map[string]string{
+		"":         "",
+		"id":       "id",
+		"user_id":  "userId",
+		"a__b_":    "aB",
+		"__init__": "__init",
+		"名字_中文_id": "名字中文Id",
+	}
+
+	for input, excepted := range cases {
+		if output := geko.SnakeToCamel(input); output != excepted {
+			t.Fatalf("SnakeToCamel(%q) = %q, excepted %q", input, output, excepted)
+		}
+	}
+}
+
+func TestJSONMarshal_DropEmptyContainers(t *testing.T) {
+	data := `{"a": null, "b": {"c": null, "d": {"e": null}}, "f": [null, {"g": null}], "h": 1, "i": [], "j": {}}`
+	v, _ := geko.JSONUnmarshal([]byte(data), geko.UseObject())
+	v.(geko.Object).Set("k", map[string]any{})
+
+	output := jsonMarshalToString(t, v, geko.DropNullValues(true))
+	if output != `{"b":{"d":{}},"f":[null,{}],"h":1,"i":[],"j":{},"k":{}}` {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	output = jsonMarshalToString(t, v, geko.DropNullValues(true), geko.DropEmptyContainers(true))
+	if output != `{"f":[null,{}],"h":1}` {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	output = jsonMarshalToString(t, v, geko.DropEmptyContainers(true), geko.Indent("", "  "))
+	excepted := `{
+  "a": null,
+  "b": {
+    "c": null,
+    "d": {
+      "e": null
+    }
+  },
+  "f": [
+    null,
+    {
+      "g": null
+    }
+  ],
+  "h": 1
+}`
+	if output != excepted {
+		t.Fatalf("JSONMarshal result not correct:\n%s", output)
+	}
+
+	// source is not modified
+	output = jsonMarshalToString(t, v)
+	if output != `{"a":null,"b":{"c":null,"d":{"e":null}},"f":[null,{"g":null}],"h":1,"i":[],"j":{},"k":{}}` {
+		t.Fatalf("Source should not be modified:\n%s", output)
+	}
+}
@@ -0,0 +1,563 @@
+package geko
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// LimitKind tells which limit is exceeded in a [LimitExceededError].
+type LimitKind uint8
+
+const (
+	// ItemsLimit is the limit set by [MaxItems].
+	ItemsLimit LimitKind = iota
+	// BytesLimit is the limit set by [MaxBytes].
+	BytesLimit
+	// DepthLimit is the limit set by [MaxDepth].
+	DepthLimit
+)
+
+// String implements [fmt.Stringer] interface.
+func (k LimitKind) String() string {
+	switch k {
+	case ItemsLimit:
+		return "max items"
+	case BytesLimit:
+		return "max bytes"
+	case DepthLimit:
+		return "max depth"
+	default:
+		return fmt.Sprintf("LimitKind(%d)", uint8(k))
+	}
+}
+
+// LimitExceededError is returned when decoding input exceeds a limit set by
+// decode options, like [MaxDepth], [MaxItems] and [MaxBytes].
+type LimitExceededError struct {
+	// Kind tells which limit is exceeded.
+	Kind LimitKind
+	// Limit is the configured value of the limit.
+	Limit int64
+	// Offset is the input offset where the limit is found exceeded.
+	Offset int64
+}
+
+// Error implements [error] interface.
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("geko: exceeded %s limit %d at offset %d", e.Kind, e.Limit, e.Offset)
+}
+
+// NumberFuncError is returned when the function set by [NumberFunc] fails.
+type NumberFuncError struct {
+	// Literal is the number literal passed to the function.
+	Literal string
+	// Offset is the input offset of the end of the number.
+	Offset int64
+	// Err is the error returned by the function.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *NumberFuncError) Error() string {
+	return fmt.Sprintf("geko: convert number %s at offset %d: %s", e.Literal, e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the function.
+func (e *NumberFuncError) Unwrap() error {
+	return e.Err
+}
+
+// ValueDecoderError is returned when the function set by [WithValueDecoder]
+// fails.
+type ValueDecoderError struct {
+	// Key is the key of object member whose value fails to decode.
+	Key string
+	// Offset is the input offset of the end of the value.
+	Offset int64
+	// Err is the error returned by the function.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *ValueDecoderError) Error() string {
+	return fmt.Sprintf("geko: decode value of key %q at offset %d: %s", e.Key, e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the function.
+func (e *ValueDecoderError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeValueError records a value skipped by [Lenient] decoding, it's
+// collected in a [MultiDecodeError].
+type DecodeValueError struct {
+	// Path is the location of the value in its container, as a JSON pointer,
+	// like "/key" or "/0".
+	Path string
+	// Offset is the input offset of the end of the value.
+	Offset int64
+	// Err is the error returned by the std lib.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *DecodeValueError) Error() string {
+	return fmt.Sprintf("geko: decode value at %q, offset %d: %s", e.Path, e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the std lib.
+func (e *DecodeValueError) Unwrap() error {
+	return e.Err
+}
+
+// MultiDecodeError is returned by [Lenient] decoding when some values are
+// skipped, after the container is populated with the others.
+type MultiDecodeError struct {
+	// Errors are the skipped values, in order of appearance.
+	Errors []*DecodeValueError
+}
+
+// Error implements [error] interface.
+func (e *MultiDecodeError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("geko: %d values skipped: [%s]", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns all the skipped errors, so [errors.Is] and [errors.As]
+// can match any of them, since Go 1.20.
+func (e *MultiDecodeError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// MarshalError is returned when a value nested in a [Map], [Pairs] or [List]
+// fails to encode, it tells where the value is.
+type MarshalError struct {
+	// Path is the keys and indexes from the marshaled container to the failed
+	// value, keys are string and indexes are int.
+	Path []any
+	// Err is the error of encoding the value.
+	Err error
+}
+
+// Error implements [error] interface. Path is written like "items"[2]."price".
+func (e *MarshalError) Error() string {
+	var sb strings.Builder
+	for i, segment := range e.Path {
+		switch x := segment.(type) {
+		case int:
+			_, _ = fmt.Fprintf(&sb, "[%d]", x)
+		case string:
+			if i > 0 {
+				_ = sb.WriteByte('.')
+			}
+			_, _ = sb.WriteString(strconv.Quote(x))
+		}
+	}
+	return fmt.Sprintf("geko: marshal failed at %s: %s", sb.String(), e.Err.Error())
+}
+
+// Unwrap returns the error of encoding the value.
+func (e *MarshalError) Unwrap() error {
+	return e.Err
+}
+
+// SyntaxError is returned when the input is not valid JSON, and the error is
+// found by this package instead of the std lib, like unexpected end of input,
+// or trailing data after top-level value.
+//
+// Errors found by the std lib are still returned as [json.SyntaxError].
+type SyntaxError struct {
+	// Msg is the description of the error.
+	Msg string
+	// Offset is the input offset where the error is found.
+	Offset int64
+}
+
+// Error implements [error] interface. Like [json.SyntaxError], it returns the
+// message only.
+func (e *SyntaxError) Error() string {
+	return e.Msg
+}
+
+// As makes errors.As(err, &target) works when target is a *[json.SyntaxError],
+// for compatibility. Only the Offset field of target is meaningful, because
+// the message of a json.SyntaxError can't be set outside the std lib.
+func (e *SyntaxError) As(target any) bool {
+	if t, ok := target.(**json.SyntaxError); ok {
+		*t = &json.SyntaxError{Offset: e.Offset}
+		return true
+	}
+	return false
+}
+
+// DuplicateKeyError is returned when a JSON object contains a duplicated key,
+// and [DisallowDuplicateKeys] is applied. It's also returned by
+// [MarshalCanonical], which does not allow duplicated keys.
+type DuplicateKeyError struct {
+	// Key is the duplicated key.
+	Key string
+	// Index is the position of the duplicated member in its object, starts
+	// from 0.
+	Index int
+	// Offset is the input offset of the end of the duplicated key, or -1 if
+	// the error does not come from decoding.
+	Offset int64
+}
+
+// Error implements [error] interface.
+func (e *DuplicateKeyError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("geko: duplicated key %q at index %d", e.Key, e.Index)
+	}
+	return fmt.Sprintf("geko: duplicated key %q at index %d, offset %d", e.Key, e.Index, e.Offset)
+}
+
+// PointerErrorKind tells why a JSON pointer can't be resolved in a
+// [PointerError].
+type PointerErrorKind uint8
+
+const (
+	// PointerInvalid means the pointer is not a valid RFC 6901 JSON pointer.
+	PointerInvalid PointerErrorKind = iota
+	// PointerNotFound means the object member or array item referenced by a
+	// segment does not exist.
+	PointerNotFound
+	// PointerWrongType means the value a segment applies to is not an
+	// [Object], [ObjectItems] or [Array], or is a nil one when modifying.
+	PointerWrongType
+	// PointerOutOfRange means an array index is out of range when modifying.
+	PointerOutOfRange
+	// PointerRoot means the pointer references root, which can't be deleted.
+	PointerRoot
+)
+
+// String implements [fmt.Stringer] interface.
+func (k PointerErrorKind) String() string {
+	switch k {
+	case PointerInvalid:
+		return "invalid pointer"
+	case PointerNotFound:
+		return "not found"
+	case PointerWrongType:
+		return "wrong container type"
+	case PointerOutOfRange:
+		return "index out of range"
+	case PointerRoot:
+		return "root can't be deleted"
+	default:
+		return fmt.Sprintf("PointerErrorKind(%d)", uint8(k))
+	}
+}
+
+// PointerError is returned when a JSON pointer can't be resolved, by
+// functions like [PointerGet], [PointerSet] and [PointerDelete].
+type PointerError struct {
+	// Pointer is the JSON pointer.
+	Pointer string
+	// Segment is the index of the segment which fails, starts from 0.
+	Segment int
+	// Kind tells why it fails.
+	Kind PointerErrorKind
+}
+
+// Error implements [error] interface.
+func (e *PointerError) Error() string {
+	if e.Kind == PointerRoot {
+		return fmt.Sprintf("geko: json pointer %q: %s", e.Pointer, e.Kind)
+	}
+	return fmt.Sprintf("geko: json pointer %q: %s at segment %d", e.Pointer, e.Kind, e.Segment)
+}
+
+// PatchError is returned by [ApplyPatch] when an operation fails.
+type PatchError struct {
+	// Index is the index of the failed operation in patch, starts from 0.
+	Index int
+	// Op is the op member of the failed operation, it's empty if the
+	// operation does not have a valid one.
+	Op string
+	// Err is the reason of failure, like a [*PointerError],
+	// [ErrInvalidPatchOperation] or [ErrPatchTestFailed].
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("geko: apply patch operation %d (%s): %s", e.Index, e.Op, e.Err.Error())
+}
+
+// Unwrap returns the reason of failure.
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// AccessErrorKind tells why a value can't be got in an [AccessError].
+type AccessErrorKind uint8
+
+const (
+	// AccessNotFound means the key does not exist.
+	AccessNotFound AccessErrorKind = iota
+	// AccessWrongType means the value can't be converted into wanted type.
+	AccessWrongType
+	// AccessOutOfRange means the array index is out of range.
+	AccessOutOfRange
+)
+
+// String implements [fmt.Stringer] interface.
+func (k AccessErrorKind) String() string {
+	switch k {
+	case AccessNotFound:
+		return "not found"
+	case AccessWrongType:
+		return "wrong type"
+	case AccessOutOfRange:
+		return "index out of range"
+	default:
+		return fmt.Sprintf("AccessErrorKind(%d)", uint8(k))
+	}
+}
+
+// AccessError is returned by typed getters, like [GetString] and
+// [ArrayString], when the value can't be got.
+type AccessError struct {
+	// Key is the string key of the value in an object, or the int index of it
+	// in an array.
+	Key any
+	// Kind tells why it fails.
+	Kind AccessErrorKind
+	// Want is the wanted type, like "string" or "Object".
+	Want string
+	// Value is the actual value, only meaningful when Kind is
+	// [AccessWrongType].
+	Value any
+}
+
+// Error implements [error] interface.
+func (e *AccessError) Error() string {
+	where := fmt.Sprintf("key %q", e.Key)
+	if index, ok := e.Key.(int); ok {
+		where = fmt.Sprintf("index %d", index)
+	}
+
+	if e.Kind == AccessWrongType {
+		return fmt.Sprintf("geko: get %s of %s: %s %T", e.Want, where, e.Kind, e.Value)
+	}
+	return fmt.Sprintf("geko: get %s of %s: %s", e.Want, where, e.Kind)
+}
+
+// QueryError is returned by [Query] when the JSONPath expression is not valid.
+type QueryError struct {
+	// Query is the JSONPath expression.
+	Query string
+	// Offset is the position in query where the error is found.
+	Offset int
+	// Msg is the description of the error.
+	Msg string
+}
+
+// Error implements [error] interface.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("geko: invalid query %q: %s at offset %d", e.Query, e.Msg, e.Offset)
+}
+
+// UnsupportedTypeError is returned by [FromStruct] when a value can't be
+// represented in JSON, like a chan or func. It's also used by [ToStruct] for
+// a map key type which can't be decoded from string.
+type UnsupportedTypeError struct {
+	// Type is the unsupported type.
+	Type reflect.Type
+}
+
+// Error implements [error] interface.
+func (e *UnsupportedTypeError) Error() string {
+	return "geko: unsupported type " + e.Type.String()
+}
+
+// BindError is returned by [ToStruct] when a value can't be assigned to the
+// target.
+type BindError struct {
+	// Path is the location of the value in the source, as a JSON pointer.
+	Path string
+	// Type is the type of the target.
+	Type reflect.Type
+	// Value is the value which can't be assigned.
+	Value any
+	// Err is the underlying error, it's nil if the value has a wrong type.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *BindError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("geko: bind %q into %s: wrong type %T", e.Path, e.Type, e.Value)
+	}
+	return fmt.Sprintf("geko: bind %q into %s: %s", e.Path, e.Type, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownFieldsError is returned by [ToStruct] with [DisallowUnknownFields]
+// option, when there are object members without a matched struct field.
+type UnknownFieldsError struct {
+	// Paths are locations of all unknown members, as JSON pointers, in order
+	// of appearance.
+	Paths []string
+}
+
+// Error implements [error] interface.
+func (e *UnknownFieldsError) Error() string {
+	quoted := make([]string, len(e.Paths))
+	for i, path := range e.Paths {
+		quoted[i] = strconv.Quote(path)
+	}
+	return "geko: unknown fields " + strings.Join(quoted, ", ")
+}
+
+// HTTPBodyErrorKind tells why a HTTP body fails to decode in an
+// [HTTPBodyError].
+type HTTPBodyErrorKind uint8
+
+const (
+	// BodyMalformed means the body is not valid JSON, or is rejected by
+	// decode options, like [MaxDepth] or [DisallowDuplicateKeys].
+	BodyMalformed HTTPBodyErrorKind = iota
+	// BodyTooLarge means the body is larger than the byte limit.
+	BodyTooLarge
+	// BodyWrongType means the body is valid JSON, but the top-level value is
+	// not the wanted type.
+	BodyWrongType
+	// BodyReadFailed means the body can't be read, like the connection is
+	// closed.
+	BodyReadFailed
+)
+
+// String implements [fmt.Stringer] interface.
+func (k HTTPBodyErrorKind) String() string {
+	switch k {
+	case BodyMalformed:
+		return "malformed"
+	case BodyTooLarge:
+		return "too large"
+	case BodyWrongType:
+		return "wrong top-level type"
+	case BodyReadFailed:
+		return "read failed"
+	default:
+		return fmt.Sprintf("HTTPBodyErrorKind(%d)", uint8(k))
+	}
+}
+
+// HTTPBodyError is returned by [DecodeHTTPBody] and [DecodeHTTPObject].
+type HTTPBodyError struct {
+	// Kind tells why it fails.
+	Kind HTTPBodyErrorKind
+	// Offset is the input offset where the error is found. It's the limit for
+	// [BodyTooLarge], and not used for [BodyWrongType].
+	Offset int64
+	// Err is the underlying error, like a [SyntaxError], or a
+	// [LimitExceededError] for [BodyTooLarge].
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *HTTPBodyError) Error() string {
+	if e.Kind == BodyWrongType {
+		return fmt.Sprintf("geko: http body %s: %s", e.Kind, e.Err.Error())
+	}
+	return fmt.Sprintf("geko: http body %s at offset %d: %s", e.Kind, e.Offset, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *HTTPBodyError) Unwrap() error {
+	return e.Err
+}
+
+// TOMLError is returned by [TOMLUnmarshal] when the input is not valid TOML.
+type TOMLError struct {
+	// Line is the line number where the error is found, starts from 1.
+	Line int
+	// Column is the byte offset in the line where the error is found,
+	// starts from 1.
+	Column int
+	// Msg is the description of the error.
+	Msg string
+}
+
+// Error implements [error] interface.
+func (e *TOMLError) Error() string {
+	return fmt.Sprintf("geko: invalid toml: %s at line %d, column %d", e.Msg, e.Line, e.Column)
+}
+
+// CBORError is returned when decoding invalid CBOR data.
+type CBORError struct {
+	// Offset is the byte offset of the data item where the error is found.
+	Offset int
+	// Msg is the description of the error.
+	Msg string
+}
+
+// Error implements [error] interface.
+func (e *CBORError) Error() string {
+	return fmt.Sprintf("geko: invalid cbor: %s at offset %d", e.Msg, e.Offset)
+}
+
+// BSONError is returned when decoding invalid BSON data.
+type BSONError struct {
+	// Offset is the byte offset where the error is found.
+	Offset int
+	// Msg is the description of the error.
+	Msg string
+}
+
+// Error implements [error] interface.
+func (e *BSONError) Error() string {
+	return fmt.Sprintf("geko: invalid bson: %s at offset %d", e.Msg, e.Offset)
+}
+
+// BinaryError is returned when decoding invalid data in the binary format of
+// this package, see [Map.MarshalBinary].
+type BinaryError struct {
+	// Offset is the byte offset where the error is found.
+	Offset int
+	// Msg is the description of the error.
+	Msg string
+}
+
+// Error implements [error] interface.
+func (e *BinaryError) Error() string {
+	return fmt.Sprintf("geko: invalid binary data: %s at offset %d", e.Msg, e.Offset)
+}
+
+// CoerceError is returned by value coercion helpers, like [ToInt64], when a
+// value can't be converted into the wanted type.
+type CoerceError struct {
+	// Value is the value to be converted.
+	Value any
+	// Want is the wanted type, like "int64".
+	Want string
+	// Err is the reason, it's [strconv.ErrRange] if the value is a number out
+	// of range of the wanted type.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *CoerceError) Error() string {
+	return fmt.Sprintf("geko: can't convert %T value %v to %s: %s", e.Value, e.Value, e.Want, e.Err.Error())
+}
+
+// Unwrap returns the reason.
+func (e *CoerceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,151 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestLimitKind_String(t *testing.T) {
+	if geko.ItemsLimit.String() != "max items" {
+		t.Fatalf("ItemsLimit string not correct: %s", geko.ItemsLimit.String())
+	}
+
+	if geko.BytesLimit.String() != "max bytes" {
+		t.Fatalf("BytesLimit string not correct: %s", geko.BytesLimit.String())
+	}
+
+	if geko.DepthLimit.String() != "max depth" {
+		t.Fatalf("DepthLimit string not correct: %s", geko.DepthLimit.String())
+	}
+
+	if geko.LimitKind(100).String() != "LimitKind(100)" {
+		t.Fatalf("Unknown LimitKind string not correct: %s", geko.LimitKind(100).String())
+	}
+}
+
+func TestHTTPBodyError(t *testing.T) {
+	for _, c := range []struct {
+		kind     geko.HTTPBodyErrorKind
+		excepted string
+	}{
+		{geko.BodyMalformed, "geko: http body malformed at offset 3: bad"},
+		{geko.BodyTooLarge, "geko: http body too large at offset 3: bad"},
+		{geko.BodyWrongType, "geko: http body wrong top-level type: bad"},
+		{geko.BodyReadFailed, "geko: http body read failed at offset 3: bad"},
+	} {
+		err := &geko.HTTPBodyError{Kind: c.kind, Offset: 3, Err: errors.New("bad")}
+		if err.Error() != c.excepted {
+			t.Fatalf("HTTPBodyError message not correct: %s", err.Error())
+		}
+	}
+
+	if geko.HTTPBodyErrorKind(100).String() != "HTTPBodyErrorKind(100)" {
+		t.Fatalf("Unknown HTTPBodyErrorKind string not correct: %s", geko.HTTPBodyErrorKind(100).String())
+	}
+}
+
+func TestLimitExceededError(t *testing.T) {
+	err := &geko.LimitExceededError{Kind: geko.BytesLimit, Limit: 10, Offset: 12}
+
+	if err.Error() != "geko: exceeded max bytes limit 10 at offset 12" {
+		t.Fatalf("Error message not correct: %s", err.Error())
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	_, err := geko.JSONUnmarshal([]byte(`[1] 2`))
+
+	var syntaxErr *geko.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Unmarshal should fail with geko.SyntaxError, got %#v", err)
+	}
+
+	if syntaxErr.Error() != "invalid character after top-level value" || syntaxErr.Offset != 5 {
+		t.Fatalf("SyntaxError not correct: %#v", syntaxErr)
+	}
+
+	var stdErr *json.SyntaxError
+	if !errors.As(err, &stdErr) || stdErr.Offset != syntaxErr.Offset {
+		t.Fatalf("SyntaxError should be compatible with json.SyntaxError, got %#v", stdErr)
+	}
+
+	var limitErr *geko.LimitExceededError
+	if errors.As(err, &limitErr) {
+		t.Fatalf("SyntaxError should not be a LimitExceededError")
+	}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	err := &geko.DuplicateKeyError{Key: "a", Index: 2, Offset: 30}
+	if err.Error() != `geko: duplicated key "a" at index 2, offset 30` {
+		t.Fatalf("DuplicateKeyError message not correct: %s", err.Error())
+	}
+}
+
+func TestValueDecoderError(t *testing.T) {
+	err := &geko.ValueDecoderError{Key: "a", Offset: 10, Err: errors.New("bad")}
+	if err.Error() != `geko: decode value of key "a" at offset 10: bad` {
+		t.Fatalf("ValueDecoderError message not correct: %s", err.Error())
+	}
+}
+
+func TestMultiDecodeError(t *testing.T) {
+	bad := errors.New("bad")
+	err := &geko.MultiDecodeError{Errors: []*geko.DecodeValueError{
+		{Path: "/a", Offset: 10, Err: bad},
+		{Path: "/1", Offset: 20, Err: bad},
+	}}
+
+	excepted := `geko: 2 values skipped: [geko: decode value at "/a", offset 10: bad; ` +
+		`geko: decode value at "/1", offset 20: bad]`
+	if err.Error() != excepted {
+		t.Fatalf("MultiDecodeError message not correct: %s", err.Error())
+	}
+
+	errs := err.Unwrap()
+	if len(errs) != 2 || !errors.Is(errs[1], bad) {
+		t.Fatalf("MultiDecodeError unwrap not correct: %#v", errs)
+	}
+}
+
+func TestMarshalError(t *testing.T) {
+	err := &geko.MarshalError{Path: []any{1, "a.b", 0, "c"}, Err: errors.New("bad")}
+	if err.Error() != `geko: marshal failed at [1]."a.b"[0]."c": bad` {
+		t.Fatalf("MarshalError message not correct: %s", err.Error())
+	}
+}
+
+func TestPointerErrorKind_String(t *testing.T) {
+	kinds := map[geko.PointerErrorKind]string{
+		geko.PointerInvalid:        "invalid pointer",
+		geko.PointerNotFound:       "not found",
+		geko.PointerWrongType:      "wrong container type",
+		geko.PointerOutOfRange:     "index out of range",
+		geko.PointerRoot:           "root can't be deleted",
+		geko.PointerErrorKind(100): "PointerErrorKind(100)",
+	}
+
+	for kind, excepted := range kinds {
+		if kind.String() != excepted {
+			t.Fatalf("PointerErrorKind string not correct: %s", kind.String())
+		}
+	}
+}
+
+func TestAccessErrorKind_String(t *testing.T) {
+	kinds := map[geko.AccessErrorKind]string{
+		geko.AccessNotFound:       "not found",
+		geko.AccessWrongType:      "wrong type",
+		geko.AccessOutOfRange:     "index out of range",
+		geko.AccessErrorKind(100): "AccessErrorKind(100)",
+	}
+
+	for kind, excepted := range kinds {
+		if kind.String() != excepted {
+			t.Fatalf("AccessErrorKind string not correct: %s", kind.String())
+		}
+	}
+}
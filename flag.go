@@ -0,0 +1,101 @@
+package geko
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObjectFlag is a [flag.Value] which parses command line arguments into an
+// [Object], keeping the order of keys as typed by user:
+//
+//	labels := geko.NewObjectFlag(geko.UpdateValueKeepOrder)
+//	flag.Var(labels, "labels", "labels of the resource")
+//
+// Both of these forms are accepted:
+//
+//	--labels '{"env": "prod", "team": "core"}'
+//	--labels env=prod,team=core
+//
+// The first is a JSON object, decoded by [JSONUnmarshal] with [UseObject], and
+// numbers are kept as [json.Number]. The second is a shorthand for objects
+// whose values are all strings, a value can't contain comma in this form.
+//
+// If the flag is given multiple times, all members are merged into one object
+// in order. Duplicated keys, in one argument or between them, are handled by
+// the strategy given to [NewObjectFlag].
+//
+// The zero value is an empty flag with [UpdateValueKeepOrder] strategy.
+type ObjectFlag struct {
+	object   Object
+	strategy DuplicatedKeyStrategy
+}
+
+// NewObjectFlag creates an empty [ObjectFlag], which handles duplicated keys by
+// strategy.
+func NewObjectFlag(strategy DuplicatedKeyStrategy) *ObjectFlag {
+	return &ObjectFlag{strategy: strategy}
+}
+
+// Object returns the merged result of all parsed arguments. It's nil if the
+// flag is never set.
+func (f *ObjectFlag) Object() Object {
+	return f.object
+}
+
+// Set implements [flag.Value] interface, it parses s and merges the members
+// into current object.
+func (f *ObjectFlag) Set(s string) error {
+	members, err := parseObjectFlag(s, f.strategy)
+	if err != nil {
+		return err
+	}
+
+	if f.object == nil {
+		f.object = NewMapWithCapacity[string, any](members.Len())
+		f.object.SetDuplicatedKeyStrategy(f.strategy)
+	}
+
+	for i := 0; i < members.Len(); i++ {
+		pair := members.GetByIndex(i)
+		f.object.Add(pair.Key, pair.Value)
+	}
+
+	return nil
+}
+
+// String implements [flag.Value] interface, it returns current object in
+// compact JSON.
+func (f *ObjectFlag) String() string {
+	if f == nil || f.object == nil {
+		return "{}"
+	}
+
+	data, _ := JSONMarshal(f.object, EscapeHTML(false))
+	return string(data)
+}
+
+func parseObjectFlag(s string, strategy DuplicatedKeyStrategy) (Object, error) {
+	if strings.HasPrefix(strings.TrimSpace(s), "{") {
+		result, err := JSONUnmarshal(
+			[]byte(s), UseObject(), ObjectOnDuplicatedKey(strategy), UseNumber(true),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("geko: invalid object flag %q: %w", s, err)
+		}
+		object, _ := result.(Object)
+		return object, nil
+	}
+
+	members := NewMap[string, any]()
+	members.SetDuplicatedKeyStrategy(strategy)
+
+	for _, item := range strings.Split(s, ",") {
+		key, value, found := strings.Cut(item, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("geko: invalid object flag %q: %q is not in key=value form", s, item)
+		}
+		members.Add(key, value)
+	}
+
+	return members, nil
+}
@@ -0,0 +1,149 @@
+package geko_test
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func parseObjectFlag(t *testing.T, labels *geko.ObjectFlag, args ...string) error {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(labels, "labels", "labels of the resource")
+
+	return fs.Parse(args)
+}
+
+func TestObjectFlag_JSON(t *testing.T) {
+	labels := geko.NewObjectFlag(geko.UpdateValueKeepOrder)
+
+	arg := `{"env": "prod", "team": "core", "n": 1.50, "x": {"b": [1], "a": null}}`
+	if err := parseObjectFlag(t, labels, "--labels", arg); err != nil {
+		t.Fatalf("Parse flag with error: %s", err.Error())
+	}
+
+	excepted := `{"env":"prod","team":"core","n":1.50,"x":{"b":[1],"a":null}}`
+	if output := labels.String(); output != excepted {
+		t.Fatalf("ObjectFlag result not correct: %s", output)
+	}
+
+	if keys := labels.Object().Keys(); strings.Join(keys, ",") != "env,team,n,x" {
+		t.Fatalf("ObjectFlag keys not correct: %v", keys)
+	}
+}
+
+func TestObjectFlag_Shorthand(t *testing.T) {
+	labels := geko.NewObjectFlag(geko.UpdateValueKeepOrder)
+
+	if err := parseObjectFlag(t, labels, "--labels", "team=core,env=prod,url=a=b&c,empty="); err != nil {
+		t.Fatalf("Parse flag with error: %s", err.Error())
+	}
+
+	excepted := `{"team":"core","env":"prod","url":"a=b&c","empty":""}`
+	if output := labels.String(); output != excepted {
+		t.Fatalf("ObjectFlag result not correct: %s", output)
+	}
+}
+
+func TestObjectFlag_Merge(t *testing.T) {
+	for _, c := range []struct {
+		strategy geko.DuplicatedKeyStrategy
+		excepted string
+	}{
+		{geko.UpdateValueKeepOrder, `{"b":"3","a":"2","c":"4"}`},
+		{geko.UpdateValueUpdateOrder, `{"a":"2","b":"3","c":"4"}`},
+		{geko.KeepValueUpdateOrder, `{"a":"2","b":"1","c":"4"}`},
+		{geko.Ignore, `{"b":"1","a":"2","c":"4"}`},
+	} {
+		labels := geko.NewObjectFlag(c.strategy)
+
+		err := parseObjectFlag(t, labels, "--labels", "b=1,a=2", "--labels", `{"b": "3", "c": "4"}`)
+		if err != nil {
+			t.Fatalf("Parse flag with error: %s", err.Error())
+		}
+
+		if output := labels.String(); output != c.excepted {
+			t.Fatalf("ObjectFlag merge result with strategy %d not correct: %s", c.strategy, output)
+		}
+	}
+
+	labels := geko.NewObjectFlag(geko.Ignore)
+	if err := parseObjectFlag(t, labels, "--labels", "a=1,a=2", "--labels", `{"b": 1, "b": 2}`); err != nil {
+		t.Fatalf("Parse flag with error: %s", err.Error())
+	}
+
+	if output := labels.String(); output != `{"a":"1","b":1}` {
+		t.Fatalf("ObjectFlag duplicated key in argument not correct: %s", output)
+	}
+}
+
+func TestObjectFlag_Zero(t *testing.T) {
+	var labels geko.ObjectFlag
+
+	if output := labels.String(); output != "{}" {
+		t.Fatalf("String of zero ObjectFlag should be empty object: %s", output)
+	}
+
+	if labels.Object() != nil {
+		t.Fatalf("Object of zero ObjectFlag should be nil: %#v", labels.Object())
+	}
+
+	if output := (*geko.ObjectFlag)(nil).String(); output != "{}" {
+		t.Fatalf("String of nil ObjectFlag should be empty object: %s", output)
+	}
+
+	if err := labels.Set("a=1"); err != nil || labels.String() != `{"a":"1"}` {
+		t.Fatalf("Set zero ObjectFlag result not correct: %s, %#v", labels.String(), err)
+	}
+}
+
+func TestObjectFlag_Invalid(t *testing.T) {
+	for _, c := range []struct {
+		arg      string
+		excepted string
+	}{
+		{
+			`{"a": 1`,
+			`invalid value "{\"a\": 1" for flag -labels: geko: invalid object flag "{\"a\": 1": ` +
+				`unexpected end of JSON input`,
+		},
+		{
+			`{"a": 1} {}`,
+			`invalid value "{\"a\": 1} {}" for flag -labels: geko: invalid object flag "{\"a\": 1} {}": ` +
+				`invalid character after top-level value`,
+		},
+		{
+			`a=1,b`,
+			`invalid value "a=1,b" for flag -labels: geko: invalid object flag "a=1,b": ` +
+				`"b" is not in key=value form`,
+		},
+		{
+			`=1`,
+			`invalid value "=1" for flag -labels: geko: invalid object flag "=1": "=1" is not in key=value form`,
+		},
+		{
+			``,
+			`invalid value "" for flag -labels: geko: invalid object flag "": "" is not in key=value form`,
+		},
+		{
+			`[1]`,
+			`invalid value "[1]" for flag -labels: geko: invalid object flag "[1]": "[1]" is not in key=value form`,
+		},
+	} {
+		labels := geko.NewObjectFlag(geko.UpdateValueKeepOrder)
+
+		err := parseObjectFlag(t, labels, "--labels", c.arg)
+		if err == nil || err.Error() != c.excepted {
+			t.Fatalf("ObjectFlag should fail on %q: %#v", c.arg, err)
+		}
+
+		if labels.Object() != nil {
+			t.Fatalf("ObjectFlag should not be changed on invalid input: %s", labels.String())
+		}
+	}
+}
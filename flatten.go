@@ -0,0 +1,199 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidFlatKey means a key given to [Unflatten] can't be turned into a
+// path, or its path conflicts with another key.
+var ErrInvalidFlatKey = errors.New("geko: invalid flat key")
+
+// Flatten turns root into a flat list of leaf values, keyed by their path from
+// root, in document order. Path segments, which are object keys and array
+// indexes, are joined by sep. For example, {"server": {"ports": [8080]}} is
+// flattened into "server.ports.0" = 8080, with "." as sep.
+//
+// Containers are descended into like [Walk], other values are leaves. Empty
+// containers are leaves too, so they are kept. The result is a [Pairs], so
+// duplicated keys of an [ObjectItems] are all kept.
+//
+// A backslash or sep inside a path segment is escaped by a backslash, so
+// "a.b" key is written as "a\.b" with "." as sep. Keys without them, which is
+// the most common case, are written as is.
+//
+// A root which is not a container, is a leaf with an empty path. An empty root
+// container results an empty list.
+//
+// sep should not be empty or contain a backslash, otherwise the result can't
+// be unflattened.
+func Flatten(root any, sep string) *Pairs[string, any] {
+	result := NewPairs[string, any]()
+	flattenValue(result, sep, nil, root)
+	return result
+}
+
+func flattenValue(result *Pairs[string, any], sep string, path []string, value any) {
+	empty := true
+
+	c, isContainer := value.(container)
+	if isContainer {
+		_ = c.rangeChildren(func(key, child any) error {
+			empty = false
+			flattenValue(result, sep, append(path, escapeFlatKey(stdKey(key), sep)), child)
+			return nil
+		})
+	}
+
+	if empty && (len(path) > 0 || !isContainer) {
+		result.Add(strings.Join(path, sep), value)
+	}
+}
+
+func escapeFlatKey(key, sep string) string {
+	if !strings.Contains(key, `\`) && (sep == "" || !strings.Contains(key, sep)) {
+		return key
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '\\':
+			_, _ = sb.WriteString(`\\`)
+			i++
+		case sep != "" && strings.HasPrefix(key[i:], sep):
+			_ = sb.WriteByte('\\')
+			_, _ = sb.WriteString(sep)
+			i += len(sep)
+		default:
+			_ = sb.WriteByte(key[i])
+			i++
+		}
+	}
+
+	return sb.String()
+}
+
+// Unflatten is the reverse of [Flatten], it builds nested containers from a
+// flat list of leaf values, in order of ps.
+//
+// Each key is split into path segments by sep, and escapes made by [Flatten]
+// are decoded. A missing container is created when first needed, it is an
+// [Array] if the segment is an array index, like "0", otherwise an [Object].
+// So an object whose first key looks like an array index can't round trip.
+//
+// Array items must be given in order, an index can be at most the current
+// length of the array. A later value with the same path replaces the former
+// one, and keeps its position.
+//
+// Leaf values are deep copied like [ApplyPatch] does. An empty or nil ps
+// results an empty [Object].
+//
+// If a key is malformed, or its path steps into a leaf value, or sep is empty,
+// an error wrapping [ErrInvalidFlatKey] is returned.
+func Unflatten(ps *Pairs[string, any], sep string) (any, error) {
+	if sep == "" {
+		return nil, fmt.Errorf("%w: empty separator", ErrInvalidFlatKey)
+	}
+
+	var root any
+
+	for i := 0; ps != nil && i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+
+		segments, err := splitFlatKey(pair.Key, sep)
+		if err == nil {
+			root, err = unflattenValue(root, pair.Key, segments, pair.Value)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if root == nil {
+		root = NewMap[string, any]()
+	}
+
+	return root, nil
+}
+
+func splitFlatKey(key, sep string) ([]string, error) {
+	var segments []string
+	var sb strings.Builder
+
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '\\':
+			rest := key[i+1:]
+			switch {
+			case strings.HasPrefix(rest, `\`):
+				_ = sb.WriteByte('\\')
+				i += 2
+			case strings.HasPrefix(rest, sep):
+				_, _ = sb.WriteString(sep)
+				i += 1 + len(sep)
+			default:
+				return nil, fmt.Errorf("%w %q: invalid escape at offset %d", ErrInvalidFlatKey, key, i)
+			}
+		case strings.HasPrefix(key[i:], sep):
+			segments = append(segments, sb.String())
+			sb.Reset()
+			i += len(sep)
+		default:
+			_ = sb.WriteByte(key[i])
+			i++
+		}
+	}
+
+	return append(segments, sb.String()), nil
+}
+
+func unflattenValue(node any, key string, segments []string, value any) (any, error) {
+	if len(segments) == 0 {
+		return cloneValue(value), nil
+	}
+
+	segment := segments[0]
+	index, isIndex := parseArrayIndex(segment)
+
+	if isNull(node) {
+		if isIndex {
+			node = NewList[any]()
+		} else {
+			node = NewMap[string, any]()
+		}
+	}
+
+	var child any
+	var err error
+
+	switch x := node.(type) {
+	case Object:
+		child, _ = x.Get(segment)
+		if child, err = unflattenValue(child, key, segments[1:], value); err != nil {
+			return nil, err
+		}
+		x.Set(segment, child)
+	case Array:
+		if !isIndex || index > x.Len() {
+			return nil, fmt.Errorf("%w %q: invalid array index %q", ErrInvalidFlatKey, key, segment)
+		}
+		if index < x.Len() {
+			child = x.Get(index)
+		}
+		if child, err = unflattenValue(child, key, segments[1:], value); err != nil {
+			return nil, err
+		}
+		if index < x.Len() {
+			x.Set(index, child)
+		} else {
+			x.Append(child)
+		}
+	default:
+		return nil, fmt.Errorf("%w %q: segment %q steps into a leaf value", ErrInvalidFlatKey, key, segment)
+	}
+
+	return node, nil
+}
@@ -0,0 +1,143 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const flattenDocument = `{
+	"server": {"host": "localhost", "ports": [8080, 8081]},
+	"tags": [],
+	"meta": {},
+	"a.b": {"c\\d": 1},
+	"n": null,
+	"list": [[1, {"x": true}], "s"]
+}`
+
+func TestFlatten(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(flattenDocument), geko.UseObject())
+
+	flat := geko.Flatten(doc, ".")
+
+	output, _ := json.Marshal(flat)
+	excepted := `{"server.host":"localhost","server.ports.0":8080,"server.ports.1":8081,"tags":[],"meta":{},` +
+		`"a\\.b.c\\\\d":1,"n":null,"list.0.0":1,"list.0.1.x":true,"list.1":"s"}`
+	if string(output) != excepted {
+		t.Fatalf("Flatten result not correct: %s", string(output))
+	}
+
+	result, err := geko.Unflatten(flat, ".")
+	if err != nil {
+		t.Fatalf("Unflatten with error: %s", err.Error())
+	}
+
+	output, _ = json.Marshal(result)
+	origin, _ := json.Marshal(doc)
+	if string(output) != string(origin) {
+		t.Fatalf("Unflatten result not correct: %s", string(output))
+	}
+}
+
+func TestFlatten_Separator(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a::b": {"c:d": [1]}, "e": {"::": 2}}`), geko.UseObject())
+
+	flat := geko.Flatten(doc, "::")
+
+	output, _ := json.Marshal(flat)
+	if string(output) != `{"a\\::b::c:d::0":1,"e::\\::":2}` {
+		t.Fatalf("Flatten result not correct: %s", string(output))
+	}
+
+	result, _ := geko.Unflatten(flat, "::")
+	output, _ = json.Marshal(result)
+	if string(output) != `{"a::b":{"c:d":[1]},"e":{"::":2}}` {
+		t.Fatalf("Unflatten result not correct: %s", string(output))
+	}
+
+	output, _ = json.Marshal(geko.Flatten(doc, ""))
+	if string(output) != `{"a::bc:d0":1,"e::":2}` {
+		t.Fatalf("Flatten with empty separator result not correct: %s", string(output))
+	}
+}
+
+func TestFlatten_ObjectItems(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": {"x": 1, "y": 2, "x": 3}}`))
+
+	flat := geko.Flatten(doc, ".")
+	if flat.Len() != 3 || flat.GetKeyByIndex(2) != "a.x" || flat.GetValueByIndex(2) != 3.0 {
+		t.Fatalf("Flatten should keep duplicated keys: %#v", flat)
+	}
+
+	result, _ := geko.Unflatten(flat, ".")
+	output, _ := json.Marshal(result)
+	if string(output) != `{"a":{"x":3,"y":2}}` {
+		t.Fatalf("Unflatten result not correct: %s", string(output))
+	}
+}
+
+func TestFlatten_Root(t *testing.T) {
+	flat := geko.Flatten(1, ".")
+	if flat.Len() != 1 || flat.GetKeyByIndex(0) != "" || flat.GetValueByIndex(0) != 1 {
+		t.Fatalf("Flatten of scalar root not correct: %#v", flat)
+	}
+
+	for _, root := range []any{geko.NewMap[string, any](), geko.NewList[any](), geko.Object(nil)} {
+		if flat = geko.Flatten(root, "."); flat.Len() != 0 {
+			t.Fatalf("Flatten of empty root should be empty: %#v", flat)
+		}
+	}
+
+	m := geko.NewMap[int, string]()
+	m.Set(1, "a")
+	if flat = geko.Flatten(m, "."); flat.GetKeyByIndex(0) != "1" {
+		t.Fatalf("Flatten of non-string key not correct: %#v", flat)
+	}
+
+	for _, ps := range []*geko.Pairs[string, any]{nil, geko.NewPairs[string, any]()} {
+		result, err := geko.Unflatten(ps, ".")
+		if object, ok := result.(geko.Object); err != nil || !ok || object.Len() != 0 {
+			t.Fatalf("Unflatten of empty pairs should be empty object: %#v, %#v", result, err)
+		}
+	}
+
+	ps := geko.NewPairs[string, any]()
+	ps.Add("0", 1)
+	ps.Add("1.a", nil)
+	ps.Add("1.a.b", 2)
+	ps.Add("0", 3)
+
+	result, _ := geko.Unflatten(ps, ".")
+	output, _ := json.Marshal(result)
+	if string(output) != `[3,{"a":{"b":2}}]` {
+		t.Fatalf("Unflatten result not correct: %s", string(output))
+	}
+}
+
+func TestUnflatten_Error(t *testing.T) {
+	tests := [][]string{
+		{`a\b`},
+		{`a\`},
+		{"a.1"},
+		{"a.0", "a.x"},
+		{"a", "a.b"},
+		{"a.0", "a.0.x"},
+	}
+
+	for _, keys := range tests {
+		ps := geko.NewPairs[string, any]()
+		for _, key := range keys {
+			ps.Add(key, 1)
+		}
+
+		if result, err := geko.Unflatten(ps, "."); !errors.Is(err, geko.ErrInvalidFlatKey) {
+			t.Fatalf("Unflatten %v should fail: %#v", keys, result)
+		}
+	}
+
+	if result, err := geko.Unflatten(geko.NewPairs[string, any](), ""); !errors.Is(err, geko.ErrInvalidFlatKey) {
+		t.Fatalf("Unflatten with empty separator should fail: %#v", result)
+	}
+}
@@ -0,0 +1,45 @@
+package geko
+
+import "strings"
+
+// GetFold gets value of the first key in obj which equals to key under
+// Unicode case-folding, like [strings.EqualFold]. It also returns index of the
+// matched key, and whether such key is found.
+//
+// If obj has keys only differ in case, the first one in current order wins.
+//
+// Performance: O(n), keys are compared one by one. If you need lookup many
+// times, maybe normalizing keys when decoding, by [KeyTransform], is a better
+// choice.
+func GetFold(obj Object, key string) (any, int, bool) {
+	for i := 0; obj != nil && i < obj.Len(); i++ {
+		if strings.EqualFold(obj.GetKeyByIndex(i), key) {
+			return obj.GetValueByIndex(i), i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// HasFold checks if obj has a key which equals to key under Unicode
+// case-folding, like [GetFold].
+//
+// Performance: O(n)
+func HasFold(obj Object, key string) bool {
+	_, _, found := GetFold(obj, key)
+	return found
+}
+
+// GetAllFold gets values of all keys in items which equal to key under
+// Unicode case-folding, like [strings.EqualFold], and their indexes, in order
+// of items. Both results are nil if no key matches.
+//
+// Performance: O(n)
+func GetAllFold(items ObjectItems, key string) (values []any, indexes []int) {
+	for i := 0; items != nil && i < items.Len(); i++ {
+		if pair := &items.List[i]; strings.EqualFold(pair.Key, key) {
+			values = append(values, pair.Value)
+			indexes = append(indexes, i)
+		}
+	}
+	return
+}
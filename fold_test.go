@@ -0,0 +1,55 @@
+package geko_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestGetFold(t *testing.T) {
+	obj, _ := geko.NewObjectFromJSON([]byte(`{"Content-Type": "a", "content-type": "b", "X-ID": 1}`))
+
+	for _, c := range []struct {
+		key           string
+		excepted      any
+		exceptedIndex int
+	}{
+		{"content-type", "a", 0},
+		{"CONTENT-TYPE", "a", 0},
+		{"Content-Type", "a", 0},
+		{"x-id", 1.0, 2},
+	} {
+		value, index, found := geko.GetFold(obj, c.key)
+		if !found || value != c.excepted || index != c.exceptedIndex {
+			t.Fatalf("GetFold of %s not correct: %v, %d, %v", c.key, value, index, found)
+		}
+		if !geko.HasFold(obj, c.key) {
+			t.Fatalf("HasFold of %s should be true", c.key)
+		}
+	}
+
+	for _, o := range []geko.Object{obj, nil} {
+		if value, index, found := geko.GetFold(o, "x-idx"); found || value != nil || index != -1 {
+			t.Fatalf("GetFold of not exist key should fail: %v, %d", value, index)
+		}
+		if geko.HasFold(o, "content") {
+			t.Fatalf("HasFold of not exist key should be false")
+		}
+	}
+}
+
+func TestGetAllFold(t *testing.T) {
+	items, _ := geko.JSONUnmarshal([]byte(`{"Accept": "a", "x": 0, "ACCEPT": "b", "accept": "c", "accept": "d"}`))
+
+	values, indexes := geko.GetAllFold(items.(geko.ObjectItems), "aCCept")
+	if !reflect.DeepEqual(values, []any{"a", "b", "c", "d"}) || !reflect.DeepEqual(indexes, []int{0, 2, 3, 4}) {
+		t.Fatalf("GetAllFold result not correct: %v, %v", values, indexes)
+	}
+
+	for _, ps := range []geko.ObjectItems{items.(geko.ObjectItems), nil} {
+		if values, indexes = geko.GetAllFold(ps, "y"); values != nil || indexes != nil {
+			t.Fatalf("GetAllFold of not exist key should be nil: %v, %v", values, indexes)
+		}
+	}
+}
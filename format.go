@@ -0,0 +1,350 @@
+package geko
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// formatMaxEntries is the max count of members or items of a container
+// printed by String methods, the rest are omitted.
+const formatMaxEntries = 100
+
+// stringWritable is implemented by [Map], [Pairs] and [List], to write their
+// String form without knowing type parameters.
+type stringWritable interface {
+	writeString(w *stringWriter)
+}
+
+type stringWriter struct {
+	strings.Builder
+}
+
+// container writes a container of n entries, entry i is written by entry.
+func (w *stringWriter) container(start, end byte, n int, entry func(i int)) {
+	_ = w.WriteByte(start)
+
+	for i := 0; i < n && i < formatMaxEntries; i++ {
+		if i > 0 {
+			_ = w.WriteByte(',')
+		}
+		entry(i)
+	}
+
+	if n > formatMaxEntries {
+		_, _ = w.WriteString(",...(")
+		_, _ = w.WriteString(strconv.Itoa(n - formatMaxEntries))
+		_, _ = w.WriteString(" more)")
+	}
+
+	_ = w.WriteByte(end)
+}
+
+func (w *stringWriter) member(key, value any) {
+	w.value(stdKey(key))
+	_ = w.WriteByte(':')
+	w.value(value)
+}
+
+func (w *stringWriter) value(v any) {
+	if isNull(v) {
+		_, _ = w.WriteString("null")
+		return
+	}
+
+	switch x := v.(type) {
+	case stringWritable:
+		x.writeString(w)
+	case map[string]any:
+		members, _ := objectMembers(x)
+		w.container('{', '}', len(members), func(i int) {
+			w.member(members[i].Key, members[i].Value)
+		})
+	case []any:
+		w.container('[', ']', len(x), func(i int) {
+			w.value(x[i])
+		})
+	default:
+		if data, err := JSONMarshal(v, EscapeHTML(false)); err == nil {
+			_, _ = w.Write(data)
+		} else {
+			_, _ = fmt.Fprintf(w, "%v", v)
+		}
+	}
+}
+
+func (m *Map[K, V]) writeString(w *stringWriter) {
+	if m == nil {
+		w.value(nil)
+		return
+	}
+
+	w.container('{', '}', m.Len(), func(i int) {
+		key := m.order[i]
+		w.member(key, m.inner[key])
+	})
+}
+
+// String implements [fmt.Stringer] interface. It returns a compact JSON like
+// form of the map, in insertion order, for printing and logging.
+//
+// Values which can't be encoded into JSON are formatted by %v verb. Only the
+// first 100 members of every container are written, the rest are replaced by
+// "...(n more)".
+//
+// Notice: the output is not always valid JSON, use [JSONMarshal] to encode.
+func (m *Map[K, V]) String() string {
+	var w stringWriter
+	m.writeString(&w)
+	return w.String()
+}
+
+func (ps *Pairs[K, V]) writeString(w *stringWriter) {
+	if ps == nil {
+		w.value(nil)
+		return
+	}
+
+	w.container('{', '}', len(ps.List), func(i int) {
+		w.member(ps.List[i].Key, ps.List[i].Value)
+	})
+}
+
+// String implements [fmt.Stringer] interface, see [Map.String] for detail.
+// Duplicated keys are all written.
+func (ps *Pairs[K, V]) String() string {
+	var w stringWriter
+	ps.writeString(&w)
+	return w.String()
+}
+
+func (l *List[T]) writeString(w *stringWriter) {
+	if l == nil {
+		w.value(nil)
+		return
+	}
+
+	w.container('[', ']', len(l.List), func(i int) {
+		w.value(l.List[i])
+	})
+}
+
+// String implements [fmt.Stringer] interface, see [Map.String] for detail.
+func (l *List[T]) String() string {
+	var w stringWriter
+	l.writeString(&w)
+	return w.String()
+}
+
+// goTyped is implemented by [Map], [Pairs] and [List], to get their Go type
+// name with type parameters.
+type goTyped interface {
+	goTypeName() string
+}
+
+var goTypedType = reflect.TypeOf((*goTyped)(nil)).Elem()
+
+var duplicatedKeyStrategyNames = [...]string{
+	UpdateValueKeepOrder:   "geko.UpdateValueKeepOrder",
+	UpdateValueUpdateOrder: "geko.UpdateValueUpdateOrder",
+	KeepValueUpdateOrder:   "geko.KeepValueUpdateOrder",
+	Ignore:                 "geko.Ignore",
+}
+
+func goTypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// goTypeName returns name of t in Go source code.
+func goTypeName(t reflect.Type) string {
+	if reflect.PointerTo(t).Implements(goTypedType) {
+		x, _ := reflect.Zero(reflect.PointerTo(t)).Interface().(goTyped)
+		return x.goTypeName()
+	}
+
+	if t.Name() != "" {
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	case reflect.Pointer:
+		return "*" + goTypeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + goTypeName(t.Elem())
+	case reflect.Array:
+		return "[" + strconv.Itoa(t.Len()) + "]" + goTypeName(t.Elem())
+	case reflect.Map:
+		return "map[" + goTypeName(t.Key()) + "]" + goTypeName(t.Elem())
+	}
+
+	return t.String()
+}
+
+// goBasicLiteral returns literal of a boolean, number or string value, and
+// whether its type is the default type of the literal.
+func goBasicLiteral(v reflect.Value) (literal string, isDefault, ok bool) {
+	isDefault = v.Type().Name() == v.Kind().String() && v.Type().PkgPath() == ""
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), isDefault, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), isDefault && v.Kind() == reflect.Int, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), false, true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != f || f > math.MaxFloat64 || f < -math.MaxFloat64 {
+			return "", false, false
+		}
+		literal = strconv.FormatFloat(f, 'g', -1, v.Type().Bits())
+		isFloat := strings.ContainsAny(literal, ".e")
+		return literal, isDefault && v.Kind() == reflect.Float64 && isFloat, true
+	case reflect.String:
+		return strconv.Quote(v.String()), isDefault, true
+	default:
+		return "", false, false
+	}
+}
+
+// goLiteral returns a Go expression of v, which is used as a value of type t.
+func goLiteral(v any, t reflect.Type) string {
+	if v == nil {
+		return "nil"
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.Pointer, reflect.Slice:
+		if rv.IsNil() && t.Kind() != reflect.Interface {
+			return "nil"
+		}
+		if rv.IsNil() {
+			return "(" + goTypeName(rv.Type()) + ")(nil)"
+		}
+	}
+
+	if x, ok := v.(fmt.GoStringer); ok {
+		return x.GoString()
+	}
+
+	literal, isDefault, ok := goBasicLiteral(rv)
+	if !ok {
+		return fmt.Sprintf("%#v", v)
+	}
+
+	if t.Kind() != reflect.Interface || isDefault {
+		return literal
+	}
+
+	return goTypeName(rv.Type()) + "(" + literal + ")"
+}
+
+func (m *Map[K, V]) goTypeName() string {
+	return "geko.Map[" + goTypeName(goTypeOf[K]()) + ", " + goTypeName(goTypeOf[V]()) + "]"
+}
+
+// GoString implements [fmt.GoStringer] interface. It returns a Go expression
+// which creates the same map, like:
+//
+//	geko.NewPairsFrom([]geko.Pair[string, int]{
+//		{Key: "a", Value: 1}, {Key: "b", Value: 2},
+//	}).ToMap(geko.UpdateValueKeepOrder)
+//
+// but in one line, so %#v output of the map can be pasted into code. Nested
+// containers are written in the same way, other values are written by %#v
+// verb. The [DuplicatedKeyStrategy] is kept, other settings are not.
+func (m *Map[K, V]) GoString() string {
+	if m == nil {
+		return "(*" + m.goTypeName() + ")(nil)"
+	}
+
+	if m.Len() == 0 && m.duplicatedKeyStrategy == UpdateValueKeepOrder {
+		return strings.Replace(m.goTypeName(), "geko.Map", "geko.NewMap", 1) + "()"
+	}
+
+	return m.Pairs().GoString() + ".ToMap(" + duplicatedKeyStrategyNames[m.duplicatedKeyStrategy] + ")"
+}
+
+func (ps *Pairs[K, V]) goTypeName() string {
+	return "geko.Pairs[" + goTypeName(goTypeOf[K]()) + ", " + goTypeName(goTypeOf[V]()) + "]"
+}
+
+// GoString implements [fmt.GoStringer] interface. It returns a Go expression
+// which creates the same pairs, like:
+//
+//	geko.NewPairsFrom([]geko.Pair[string, int]{{Key: "a", Value: 1}})
+//
+// See [Map.GoString] for detail.
+func (ps *Pairs[K, V]) GoString() string {
+	if ps == nil {
+		return "(*" + ps.goTypeName() + ")(nil)"
+	}
+
+	if len(ps.List) == 0 {
+		return strings.Replace(ps.goTypeName(), "geko.Pairs", "geko.NewPairs", 1) + "()"
+	}
+
+	keyType, valueType := goTypeOf[K](), goTypeOf[V]()
+
+	var sb strings.Builder
+	_, _ = sb.WriteString("geko.NewPairsFrom([]")
+	_, _ = sb.WriteString(strings.Replace(ps.goTypeName(), "geko.Pairs", "geko.Pair", 1))
+	_ = sb.WriteByte('{')
+	for i, pair := range ps.List {
+		if i > 0 {
+			_, _ = sb.WriteString(", ")
+		}
+		_, _ = sb.WriteString("{Key: ")
+		_, _ = sb.WriteString(goLiteral(pair.Key, keyType))
+		_, _ = sb.WriteString(", Value: ")
+		_, _ = sb.WriteString(goLiteral(pair.Value, valueType))
+		_ = sb.WriteByte('}')
+	}
+	_, _ = sb.WriteString("})")
+
+	return sb.String()
+}
+
+func (l *List[T]) goTypeName() string {
+	return "geko.List[" + goTypeName(goTypeOf[T]()) + "]"
+}
+
+// GoString implements [fmt.GoStringer] interface. It returns a Go expression
+// which creates the same list, like:
+//
+//	geko.NewListFrom([]int{1, 2})
+//
+// See [Map.GoString] for detail.
+func (l *List[T]) GoString() string {
+	if l == nil {
+		return "(*" + l.goTypeName() + ")(nil)"
+	}
+
+	if len(l.List) == 0 {
+		return strings.Replace(l.goTypeName(), "geko.List", "geko.NewList", 1) + "()"
+	}
+
+	itemType := goTypeOf[T]()
+
+	var sb strings.Builder
+	_, _ = sb.WriteString("geko.NewListFrom([]")
+	_, _ = sb.WriteString(goTypeName(itemType))
+	_ = sb.WriteByte('{')
+	for i, item := range l.List {
+		if i > 0 {
+			_, _ = sb.WriteString(", ")
+		}
+		_, _ = sb.WriteString(goLiteral(item, itemType))
+	}
+	_, _ = sb.WriteString("})")
+
+	return sb.String()
+}
@@ -0,0 +1,204 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+func TestString(t *testing.T) {
+	v, _ := geko.JSONUnmarshal(
+		[]byte(`{"z": 1, "a": [1.5, "x<y", {"n": null, "m": true}, []], "k": {"y": {}, "x": -2}, "z": 3}`),
+		geko.UseObject(),
+	)
+	object := v.(geko.Object)
+	object.Set("std", map[string]any{"b": []any{math.NaN()}, "a": nil})
+
+	excepted := `{"z":3,"a":[1.5,"x<y",{"n":null,"m":true},[]],"k":{"y":{},"x":-2},"std":{"a":null,"b":[NaN]}}`
+	if object.String() != excepted {
+		t.Fatalf("String result not correct: %s", object.String())
+	}
+
+	if output := fmt.Sprint(object); output != excepted {
+		t.Fatalf("Print result not correct: %s", output)
+	}
+
+	items, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "a": [2]}`))
+	if output := fmt.Sprintf("%v", items); output != `{"a":1,"a":[2]}` {
+		t.Fatalf("String of pairs not correct: %s", output)
+	}
+
+	m := geko.NewMap[int, *geko.List[int]]()
+	m.Set(1, geko.NewListFrom([]int{1, 2}))
+	m.Set(2, nil)
+	if output := m.String(); output != `{"1":[1,2],"2":null}` {
+		t.Fatalf("String of typed map not correct: %s", output)
+	}
+}
+
+func TestString_Nil(t *testing.T) {
+	var object geko.Object
+	var items geko.ObjectItems
+	var array geko.Array
+
+	for _, v := range []fmt.Stringer{object, items, array} {
+		if v.String() != "null" {
+			t.Fatalf("String of nil should be null: %s", v.String())
+		}
+	}
+
+	output := geko.NewListFrom([]any{object, []any(nil), map[string]any(nil)}).String()
+	if output != "[null,null,null]" {
+		t.Fatalf("String of nil values not correct: %s", output)
+	}
+}
+
+func TestString_Truncate(t *testing.T) {
+	l := geko.NewList[int]()
+	for i := 0; i < 1000; i++ {
+		l.Append(i)
+	}
+
+	output := l.String()
+	if !strings.HasPrefix(output, "[0,1,2,") || !strings.HasSuffix(output, ",98,99,...(900 more)]") {
+		t.Fatalf("String of large list not correct: %s", output)
+	}
+
+	m := geko.NewMap[string, any]()
+	items := make([]any, 101)
+	for i := 0; i < 101; i++ {
+		m.Set(fmt.Sprint(i), i)
+	}
+	m.Set("items", items)
+
+	output = m.String()
+	if !strings.HasSuffix(output, `"99":99,...(2 more)}`) {
+		t.Fatalf("String of large map not correct: %s", output)
+	}
+
+	output = geko.NewListFrom([]any{items}).String()
+	if !strings.HasSuffix(output, `null,null,...(1 more)]]`) {
+		t.Fatalf("String of large nested list not correct: %s", output)
+	}
+
+	ps := geko.NewPairs[int, int]()
+	for i := 0; i < 101; i++ {
+		ps.Add(i, i)
+	}
+
+	if output = ps.String(); !strings.HasSuffix(output, `"99":99,...(1 more)}`) {
+		t.Fatalf("String of large pairs not correct: %s", output)
+	}
+}
+
+type goStringTestStruct struct {
+	Name string
+}
+
+func TestGoString(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "b": [true, "x", null, {"c": 2.5}], "d": {}}`), geko.UseObject())
+
+	m := geko.NewMap[int, *geko.List[uint8]]()
+	m.SetDuplicatedKeyStrategy(geko.Ignore)
+	m.Set(1, geko.NewListFrom([]uint8{1, 2}))
+	m.Set(2, nil)
+
+	var nilObject geko.Object
+
+	// excepted output is also written as code, to make sure it compiles
+	for _, c := range []struct {
+		v        any
+		code     any
+		excepted string
+	}{
+		{
+			v,
+			geko.NewPairsFrom([]geko.Pair[string, any]{
+				{Key: "a", Value: float64(1)},
+				{Key: "b", Value: geko.NewListFrom([]any{true, "x", nil, geko.NewPairsFrom([]geko.Pair[string, any]{
+					{Key: "c", Value: 2.5},
+				}).ToMap(geko.UpdateValueKeepOrder)})},
+				{Key: "d", Value: geko.NewMap[string, any]()},
+			}).ToMap(geko.UpdateValueKeepOrder),
+			`geko.NewPairsFrom([]geko.Pair[string, any]{` +
+				`{Key: "a", Value: float64(1)}, ` +
+				`{Key: "b", Value: geko.NewListFrom([]any{true, "x", nil, geko.NewPairsFrom([]geko.Pair[string, any]{` +
+				`{Key: "c", Value: 2.5}` +
+				`}).ToMap(geko.UpdateValueKeepOrder)})}, ` +
+				`{Key: "d", Value: geko.NewMap[string, any]()}` +
+				`}).ToMap(geko.UpdateValueKeepOrder)`,
+		},
+		{
+			m,
+			geko.NewPairsFrom([]geko.Pair[int, *geko.List[uint8]]{
+				{Key: 1, Value: geko.NewListFrom([]uint8{1, 2})}, {Key: 2, Value: nil},
+			}).ToMap(geko.Ignore),
+			`geko.NewPairsFrom([]geko.Pair[int, *geko.List[uint8]]{` +
+				`{Key: 1, Value: geko.NewListFrom([]uint8{1, 2})}, {Key: 2, Value: nil}` +
+				`}).ToMap(geko.Ignore)`,
+		},
+		{
+			geko.NewListFrom([]any{int64(1), 1, float32(2), json.Number("1"), []int(nil), geko.NewList[int]()}),
+			geko.NewListFrom([]any{int64(1), 1, float32(2), json.Number("1"), ([]int)(nil), geko.NewList[int]()}),
+			`geko.NewListFrom([]any{int64(1), 1, float32(2), json.Number("1"), ([]int)(nil), geko.NewList[int]()})`,
+		},
+		{
+			geko.NewListFrom([]map[string]geko.ObjectItems{nil, {"a": nil}}),
+			geko.NewListFrom([]map[string]*geko.Pairs[string, any]{nil, {"a": nil}}),
+			`geko.NewListFrom([]map[string]*geko.Pairs[string, any]{nil, ` +
+				`map[string]*geko.Pairs[string,interface {}]{"a":(*geko.Pairs[string, any])(nil)}})`,
+		},
+		{
+			geko.NewListFrom([]any{goStringTestStruct{Name: "x"}, time.Duration(1), [1]string{"s"}}),
+			geko.NewListFrom([]any{goStringTestStruct{Name: "x"}, time.Duration(1), [1]string{"s"}}),
+			`geko.NewListFrom([]any{geko_test.goStringTestStruct{Name:"x"}, time.Duration(1), [1]string{"s"}})`,
+		},
+		{
+			geko.NewListFrom([]any{math.Inf(1), uint(1), 'x', struct{}{}}),
+			nil,
+			`geko.NewListFrom([]any{+Inf, uint(1), int32(120), struct {}{}})`,
+		},
+		{
+			geko.NewPairsFrom([]geko.Pair[string, []int]{{Key: "a", Value: []int{1}}}),
+			geko.NewPairsFrom([]geko.Pair[string, []int]{{Key: "a", Value: []int{1}}}),
+			`geko.NewPairsFrom([]geko.Pair[string, []int]{{Key: "a", Value: []int{1}}})`,
+		},
+		{
+			geko.NewMap[string, [2]map[string]error](),
+			geko.NewMap[string, [2]map[string]error](),
+			`geko.NewMap[string, [2]map[string]error]()`,
+		},
+		{
+			geko.NewMap[string, any]().Pairs().ToMap(geko.UpdateValueUpdateOrder),
+			geko.NewPairs[string, any]().ToMap(geko.UpdateValueUpdateOrder),
+			`geko.NewPairs[string, any]().ToMap(geko.UpdateValueUpdateOrder)`,
+		},
+		{
+			geko.NewPairs[string, []int](),
+			geko.NewPairs[string, []int](),
+			`geko.NewPairs[string, []int]()`,
+		},
+		{nilObject, (*geko.Map[string, any])(nil), `(*geko.Map[string, any])(nil)`},
+		{geko.ObjectItems(nil), (*geko.Pairs[string, any])(nil), `(*geko.Pairs[string, any])(nil)`},
+		{geko.Array(nil), (*geko.List[any])(nil), `(*geko.List[any])(nil)`},
+		{
+			geko.NewListFrom([]geko.Map[string, chan<- int]{}),
+			geko.NewList[geko.Map[string, chan<- int]](),
+			`geko.NewList[geko.Map[string, chan<- int]]()`,
+		},
+	} {
+		output := fmt.Sprintf("%#v", c.v)
+		if output != c.excepted {
+			t.Fatalf("GoString result not correct: %s", output)
+		}
+
+		if c.code != nil && fmt.Sprintf("%#v", c.code) != output {
+			t.Fatalf("GoString result is not same as code: %s", output)
+		}
+	}
+}
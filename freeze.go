@@ -0,0 +1,127 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrFrozen means a frozen container is modified, see [Map.Freeze]. Mutating
+// methods panic with an error which wraps it, decoding methods like
+// [Map.UnmarshalJSON] return it.
+var ErrFrozen = errors.New("geko: container is frozen")
+
+func frozenError(typeName, method string) error {
+	return fmt.Errorf("%w: can't call %s.%s", ErrFrozen, typeName, method)
+}
+
+func mustNotFrozen(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// freezer is implemented by [Map], [Pairs] and [List], to freeze nested
+// containers without knowing type parameters.
+type freezer interface {
+	Freeze()
+}
+
+func freezeValue(v any) {
+	if f, ok := v.(freezer); ok && !isNull(v) {
+		f.Freeze()
+	}
+}
+
+// Freeze makes the map immutable, recursively, so it can be shared safely as
+// a read-only value, like a config loaded at startup.
+//
+// After it's called, all mutating methods, like [Map.Set], [Map.Delete] and
+// [Map.Sort], panic with an error wraps [ErrFrozen], and decoding methods, like
+// [Map.UnmarshalJSON], return such an error. Functions which modify containers
+// in place by calling these methods, like [PointerSet], panic too, and
+// [Transform] returns a [TransformError]. Read methods, iteration and
+// marshaling keep working.
+//
+// [Map], [Pairs] and [List] values in the map, and the pairs returned by
+// [Map.Duplicates], are frozen too. Copies, like the result of [Map.Pairs],
+// are not frozen.
+//
+// A frozen map can't be unfrozen, use a copy if it needs to be modified.
+// Freeze a nil map does nothing.
+func (m *Map[K, V]) Freeze() {
+	if m == nil {
+		return
+	}
+
+	m.frozen = true
+	freezeValue(m.duplicates)
+	for _, value := range m.inner {
+		freezeValue(value)
+	}
+}
+
+// Frozen reports whether the map is frozen by [Map.Freeze].
+func (m *Map[K, V]) Frozen() bool {
+	return m != nil && m.frozen
+}
+
+func (m *Map[K, V]) checkFrozen(method string) error {
+	if m.Frozen() {
+		return frozenError("Map", method)
+	}
+	return nil
+}
+
+// Freeze makes the pairs immutable, recursively, see [Map.Freeze] for detail.
+//
+// Notice: the exported List field can't be protected, modify it directly
+// still works.
+func (ps *Pairs[K, V]) Freeze() {
+	if ps == nil {
+		return
+	}
+
+	ps.frozen = true
+	for _, pair := range ps.List {
+		freezeValue(pair.Value)
+	}
+}
+
+// Frozen reports whether the pairs is frozen by [Pairs.Freeze].
+func (ps *Pairs[K, V]) Frozen() bool {
+	return ps != nil && ps.frozen
+}
+
+func (ps *Pairs[K, V]) checkFrozen(method string) error {
+	if ps.Frozen() {
+		return frozenError("Pairs", method)
+	}
+	return nil
+}
+
+// Freeze makes the list immutable, recursively, see [Map.Freeze] for detail.
+//
+// Notice: the exported List field can't be protected, modify it directly
+// still works.
+func (l *List[T]) Freeze() {
+	if l == nil {
+		return
+	}
+
+	l.frozen = true
+	for _, item := range l.List {
+		freezeValue(item)
+	}
+}
+
+// Frozen reports whether the list is frozen by [List.Freeze].
+func (l *List[T]) Frozen() bool {
+	return l != nil && l.frozen
+}
+
+func (l *List[T]) checkFrozen(method string) error {
+	if l.Frozen() {
+		return frozenError("List", method)
+	}
+	return nil
+}
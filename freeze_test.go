@@ -0,0 +1,288 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+	"gopkg.in/yaml.v3"
+)
+
+func assertFrozenPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		r := recover()
+		err, isError := r.(error)
+		if !isError || !errors.Is(err, geko.ErrFrozen) || !strings.HasSuffix(err.Error(), "."+name) {
+			t.Fatalf("%s on frozen container should panic with ErrFrozen: %#v", name, r)
+		}
+	}()
+
+	fn()
+}
+
+func frozenTestObject(t *testing.T) geko.Object {
+	t.Helper()
+
+	result, err := geko.JSONUnmarshal(
+		[]byte(`{"b": 1, "a": {"x": [1, {"y": 2}]}, "c": {"z": 3, "z": 4}}`),
+		geko.UseObject(),
+	)
+	if err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	object, _ := result.(geko.Object)
+	object.Set("d", geko.NewPairsFrom([]geko.Pair[string, any]{{Key: "k", Value: "v"}, {Key: "k", Value: "w"}}))
+	object.Freeze()
+
+	return object
+}
+
+func TestMap_Freeze(t *testing.T) {
+	m := frozenTestObject(t)
+
+	for name, fn := range map[string]func(){
+		"SetDuplicatedKeyStrategy": func() { m.SetDuplicatedKeyStrategy(geko.Ignore) },
+		"SetRecordDuplicates":      func() { m.SetRecordDuplicates(true) },
+		"SetLenient":               func() { m.SetLenient(true) },
+		"Set":                      func() { m.Set("e", 1) },
+		"Add":                      func() { m.Add("b", 2) },
+		"Append":                   func() { m.Append(geko.Pair[string, any]{Key: "e", Value: 1}) },
+		"InsertPairs":              func() { _ = m.InsertPairs(0, geko.Pair[string, any]{Key: "e", Value: 1}) },
+		"Delete":                   func() { m.Delete("b") },
+		"DeleteByIndex":            func() { m.DeleteByIndex(0) },
+		"Clear":                    func() { m.Clear() },
+		"Sort":                     func() { m.Sort(func(a, b *geko.Pair[string, any]) bool { return a.Key < b.Key }) },
+		"Filter":                   func() { m.Filter(func(_ *geko.Pair[string, any]) bool { return false }) },
+	} {
+		assertFrozenPanic(t, name, fn)
+	}
+
+	// reads still work
+	if !m.Frozen() || m.Len() != 4 || !m.Has("a") || m.GetOrZeroValue("b") != 1.0 ||
+		m.GetKeyByIndex(1) != "a" || m.GetValueByIndex(0) != 1.0 || len(m.Values()) != 4 {
+		t.Fatalf("Read frozen map not correct: %v", m.Keys())
+	}
+
+	output, err := json.Marshal(m)
+	excepted := `{"b":1,"a":{"x":[1,{"y":2}]},"c":{"z":4},"d":{"k":"v","k":"w"}}`
+	if err != nil || string(output) != excepted {
+		t.Fatalf("Marshal frozen map not correct: %s, %#v", string(output), err)
+	}
+
+	var keys []string
+	err = geko.Walk(m, func(path []any, _ any) (bool, error) {
+		keys = append(keys, fmt.Sprint(path))
+		return true, nil
+	})
+	if err != nil || strings.Join(keys, ",") != "[],[b],[a],[a x],[a x 0],[a x 1],[a x 1 y],[c],[c z],[d],[d k],[d k]" {
+		t.Fatalf("Walk frozen map not correct: %v, %#v", keys, err)
+	}
+
+	// a copy is not frozen
+	pairs := m.Pairs()
+	pairs.Add("e", 1)
+	if pairs.Frozen() || pairs.Len() != 5 {
+		t.Fatalf("Copy of frozen map should not be frozen")
+	}
+}
+
+func TestFreeze_Nested(t *testing.T) {
+	m := frozenTestObject(t)
+
+	a, _ := m.GetOrZeroValue("a").(geko.Object)
+	x, _ := a.GetOrZeroValue("x").(geko.Array)
+	y, _ := x.Get(1).(geko.Object)
+	d, _ := m.GetOrZeroValue("d").(geko.ObjectItems)
+
+	if !a.Frozen() || !x.Frozen() || !y.Frozen() || !d.Frozen() {
+		t.Fatalf("Nested containers should be frozen")
+	}
+
+	assertFrozenPanic(t, "Set", func() { y.Set("y", 3) })
+	assertFrozenPanic(t, "Append", func() { x.Append(2) })
+	assertFrozenPanic(t, "Add", func() { d.Add("k", "x") })
+
+	assertFrozenPanic(t, "Set", func() { _, _ = geko.PointerSet(m, "/a/x/1/y", 3, false) })
+}
+
+func TestFreeze_Duplicates(t *testing.T) {
+	m := geko.NewMap[string, int]()
+	m.SetRecordDuplicates(true)
+	m.Add("a", 1)
+	m.Add("a", 2)
+	m.Freeze()
+
+	if !m.Duplicates().Frozen() || m.Duplicates().Len() != 1 {
+		t.Fatalf("Duplicates of frozen map should be frozen")
+	}
+
+	if m.Frozen() != true || m.RecordDuplicates() != true || m.DuplicatedKeyStrategy() != geko.UpdateValueKeepOrder {
+		t.Fatalf("Read settings of frozen map not correct")
+	}
+}
+
+func TestPairs_Freeze(t *testing.T) {
+	ps := geko.NewPairs[string, any]()
+	ps.Add("a", 1)
+	ps.Add("b", geko.NewListFrom([]any{1}))
+	ps.Add("a", 2)
+	ps.Freeze()
+
+	for name, fn := range map[string]func(){
+		"SetLenient":      func() { ps.SetLenient(true) },
+		"SetKeyByIndex":   func() { ps.SetKeyByIndex(0, "c") },
+		"SetValueByIndex": func() { ps.SetValueByIndex(0, 3) },
+		"SetByIndex":      func() { ps.SetByIndex(0, "c", 3) },
+		"Add":             func() { ps.Add("c", 3) },
+		"Append":          func() { ps.Append(geko.Pair[string, any]{Key: "c", Value: 3}) },
+		"Delete":          func() { ps.Delete("a") },
+		"DeleteByIndex":   func() { ps.DeleteByIndex(0) },
+		"Clear":           func() { ps.Clear() },
+		"Dedup":           func() { ps.Dedup(geko.Ignore) },
+		"GroupAdjacent":   func() { ps.GroupAdjacent() },
+		"Sort":            func() { ps.Sort(func(a, b *geko.Pair[string, any]) bool { return a.Key < b.Key }) },
+		"Filter":          func() { ps.Filter(func(_ *geko.Pair[string, any]) bool { return false }) },
+	} {
+		assertFrozenPanic(t, name, fn)
+	}
+
+	if !ps.Frozen() || ps.Len() != 3 || ps.Count("a") != 2 || ps.GetLastOrZeroValue("a") != 2 ||
+		ps.ToMap(geko.Ignore).Frozen() || ps.ToMap(geko.Ignore).Len() != 2 {
+		t.Fatalf("Read frozen pairs not correct: %v", ps.Keys())
+	}
+
+	if list, _ := ps.GetFirstOrZeroValue("b").(geko.Array); !list.Frozen() {
+		t.Fatalf("Nested list of frozen pairs should be frozen")
+	}
+
+	output, err := json.Marshal(ps)
+	if err != nil || string(output) != `{"a":1,"b":[1],"a":2}` {
+		t.Fatalf("Marshal frozen pairs not correct: %s, %#v", string(output), err)
+	}
+}
+
+func TestList_Freeze(t *testing.T) {
+	l := geko.NewListFrom([]int{3, 1, 2})
+	l.Freeze()
+
+	for name, fn := range map[string]func(){
+		"SetDecodeOptions":     func() { l.SetDecodeOptions(geko.UseObject()) },
+		"SetAppendOnUnmarshal": func() { l.SetAppendOnUnmarshal(true) },
+		"SetMarshalNilAsNull":  func() { l.SetMarshalNilAsNull(true) },
+		"Set":                  func() { l.Set(0, 1) },
+		"Append":               func() { l.Append(4) },
+		"Delete":               func() { l.Delete(0) },
+		"Resize":               func() { l.Resize(1, 0) },
+		"ResizeWith":           func() { l.ResizeWith(1, func(int) int { return 0 }) },
+	} {
+		assertFrozenPanic(t, name, fn)
+	}
+
+	if _, index, found := l.Find(func(v int) bool { return v == 2 }); !found || index != 2 || l.Get(0) != 3 {
+		t.Fatalf("Read frozen list not correct: %v", l.List)
+	}
+
+	output, err := json.Marshal(l)
+	if err != nil || string(output) != `[3,1,2]` {
+		t.Fatalf("Marshal frozen list not correct: %s, %#v", string(output), err)
+	}
+}
+
+func TestFreeze_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	m.Freeze()
+	ps.Freeze()
+	l.Freeze()
+
+	if m.Frozen() || ps.Frozen() || l.Frozen() {
+		t.Fatalf("Nil container should not be frozen")
+	}
+}
+
+type frozenDecodable interface {
+	UnmarshalJSON(data []byte) error
+	UnmarshalText(text []byte) error
+	UnmarshalYAML(node *yaml.Node) error
+	GobDecode(data []byte) error
+	UnmarshalBinary(data []byte) error
+	UnmarshalCBOR(data []byte) error
+	UnmarshalBSON(data []byte) error
+	Scan(src any) error
+}
+
+func TestFreeze_Decode(t *testing.T) {
+	m := geko.NewMap[string, any]()
+	m.Set("a", 1)
+	ps := geko.NewPairs[string, any]()
+	ps.Add("a", 1)
+	l := geko.NewListFrom([]any{1})
+
+	for _, c := range []struct {
+		value    frozenDecodable
+		data     any
+		excepted string
+	}{
+		{m, geko.NewMap[string, any](), `{"a":1}`},
+		{ps, geko.NewPairs[string, any](), `{"a":1}`},
+		{l, geko.NewList[any](), `[1]`},
+	} {
+		c.value.(interface{ Freeze() }).Freeze()
+
+		jsonData, _ := json.Marshal(c.data)
+		var gobData bytes.Buffer
+		_ = gob.NewEncoder(&gobData).Encode(c.data)
+		binaryData, _ := c.data.(interface{ MarshalBinary() ([]byte, error) }).MarshalBinary()
+		cborData, _ := geko.CBORMarshal(c.data)
+		bsonData, _ := geko.BSONMarshal(geko.NewMap[string, any]())
+		var node yaml.Node
+		_ = yaml.Unmarshal(jsonData, &node)
+
+		for method, err := range map[string]error{
+			"UnmarshalJSON":   c.value.UnmarshalJSON(jsonData),
+			"UnmarshalText":   c.value.UnmarshalText(jsonData),
+			"UnmarshalYAML":   c.value.UnmarshalYAML(node.Content[0]),
+			"GobDecode":       c.value.GobDecode(gobData.Bytes()),
+			"UnmarshalBinary": c.value.UnmarshalBinary(binaryData),
+			"UnmarshalCBOR":   c.value.UnmarshalCBOR(cborData),
+			"UnmarshalBSON":   c.value.UnmarshalBSON(bsonData),
+			"Scan":            c.value.Scan(nil),
+			"json.Unmarshal":  json.Unmarshal(jsonData, c.value),
+		} {
+			if !errors.Is(err, geko.ErrFrozen) {
+				t.Fatalf("%s into frozen %T should fail: %#v", method, c.value, err)
+			}
+		}
+
+		if output, _ := json.Marshal(c.value); string(output) != c.excepted {
+			t.Fatalf("Frozen container should not be changed by decoding: %s", string(output))
+		}
+	}
+}
+
+func TestFreeze_Transform(t *testing.T) {
+	m := frozenTestObject(t)
+
+	_, err := geko.Transform(m, func(_ []any, value any) (any, error) { return value, nil })
+
+	var transformErr *geko.TransformError
+	if !errors.As(err, &transformErr) || !errors.Is(err, geko.ErrFrozen) || len(transformErr.Path) != 0 {
+		t.Fatalf("Transform frozen map should fail: %#v", err)
+	}
+
+	if err.Error() != "geko: transform value at []: geko: container is frozen" {
+		t.Fatalf("Transform frozen map error message not correct: %s", err.Error())
+	}
+}
@@ -0,0 +1,179 @@
+package geko
+
+// GetString gets a string value of key in o.
+//
+// If key does not exist, or its value is not a string, an [*AccessError] is
+// returned. A nil o is treated as empty.
+func GetString(o Object, key string) (string, error) {
+	return getTyped(o, key, "string", assertType[string])
+}
+
+// GetInt64 gets an integer value of key in o. The value can be any number type
+// the decoder produces, regardless of [UseNumber], [UseInt64] and
+// [UseBigNumber], as long as it's an integer in range of int64. See [ToInt64]
+// for detail.
+//
+// Errors are reported like [GetString].
+func GetInt64(o Object, key string) (int64, error) {
+	return getTyped(o, key, "int64", valueInt64)
+}
+
+// GetFloat64 gets a number value of key in o. The value can be any number type
+// the decoder produces, like [GetInt64]. See [ToFloat64] for detail.
+//
+// Errors are reported like [GetString].
+func GetFloat64(o Object, key string) (float64, error) {
+	return getTyped(o, key, "float64", valueFloat64)
+}
+
+// GetBool gets a bool value of key in o.
+//
+// Errors are reported like [GetString].
+func GetBool(o Object, key string) (bool, error) {
+	return getTyped(o, key, "bool", assertType[bool])
+}
+
+// GetObject gets an [Object] value of key in o.
+//
+// Errors are reported like [GetString], a null value is a wrong type.
+func GetObject(o Object, key string) (Object, error) {
+	return getTyped(o, key, "Object", assertType[Object])
+}
+
+// GetObjectItems gets an [ObjectItems] value of key in o.
+//
+// Errors are reported like [GetString], a null value is a wrong type.
+func GetObjectItems(o Object, key string) (ObjectItems, error) {
+	return getTyped(o, key, "ObjectItems", assertType[ObjectItems])
+}
+
+// GetArray gets an [Array] value of key in o.
+//
+// Errors are reported like [GetString], a null value is a wrong type.
+func GetArray(o Object, key string) (Array, error) {
+	return getTyped(o, key, "Array", assertType[Array])
+}
+
+// ArrayString gets a string item at index i of l.
+//
+// If i is out of range, or the item is not a string, an [*AccessError] is
+// returned. A nil l is treated as empty.
+func ArrayString(l Array, i int) (string, error) {
+	return arrayTyped(l, i, "string", assertType[string])
+}
+
+// ArrayInt64 gets an integer item at index i of l, numbers are converted like
+// [GetInt64].
+//
+// Errors are reported like [ArrayString].
+func ArrayInt64(l Array, i int) (int64, error) {
+	return arrayTyped(l, i, "int64", valueInt64)
+}
+
+// ArrayFloat64 gets a number item at index i of l, numbers are converted like
+// [GetFloat64].
+//
+// Errors are reported like [ArrayString].
+func ArrayFloat64(l Array, i int) (float64, error) {
+	return arrayTyped(l, i, "float64", valueFloat64)
+}
+
+// ArrayBool gets a bool item at index i of l.
+//
+// Errors are reported like [ArrayString].
+func ArrayBool(l Array, i int) (bool, error) {
+	return arrayTyped(l, i, "bool", assertType[bool])
+}
+
+// ArrayObject gets an [Object] item at index i of l.
+//
+// Errors are reported like [ArrayString], a null item is a wrong type.
+func ArrayObject(l Array, i int) (Object, error) {
+	return arrayTyped(l, i, "Object", assertType[Object])
+}
+
+// ArrayObjectItems gets an [ObjectItems] item at index i of l.
+//
+// Errors are reported like [ArrayString], a null item is a wrong type.
+func ArrayObjectItems(l Array, i int) (ObjectItems, error) {
+	return arrayTyped(l, i, "ObjectItems", assertType[ObjectItems])
+}
+
+// ArrayArray gets an [Array] item at index i of l.
+//
+// Errors are reported like [ArrayString], a null item is a wrong type.
+func ArrayArray(l Array, i int) (Array, error) {
+	return arrayTyped(l, i, "Array", assertType[Array])
+}
+
+// ArrayStrings converts all items of l into a []string. If any item is not a
+// string, an [*AccessError] of the first such item is returned.
+//
+// A nil or empty l results a nil slice.
+func ArrayStrings(l Array) ([]string, error) {
+	if l == nil || l.Len() == 0 {
+		return nil, nil
+	}
+
+	result := make([]string, l.Len())
+	for i := range result {
+		var err error
+		if result[i], err = ArrayString(l, i); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func getTyped[T any](o Object, key string, want string, convert func(v any) (T, bool)) (T, error) {
+	var zero T
+
+	var value any
+	exist := false
+	if o != nil {
+		value, exist = o.Get(key)
+	}
+
+	if !exist {
+		return zero, &AccessError{Key: key, Kind: AccessNotFound, Want: want}
+	}
+
+	result, ok := convert(value)
+	if !ok {
+		return zero, &AccessError{Key: key, Kind: AccessWrongType, Want: want, Value: value}
+	}
+
+	return result, nil
+}
+
+func arrayTyped[T any](l Array, i int, want string, convert func(v any) (T, bool)) (T, error) {
+	var zero T
+
+	if l == nil || i < 0 || i >= l.Len() {
+		return zero, &AccessError{Key: i, Kind: AccessOutOfRange, Want: want}
+	}
+
+	value := l.Get(i)
+	result, ok := convert(value)
+	if !ok {
+		return zero, &AccessError{Key: i, Kind: AccessWrongType, Want: want, Value: value}
+	}
+
+	return result, nil
+}
+
+func assertType[T any](v any) (T, bool) {
+	result, ok := v.(T)
+	return result, ok
+}
+
+func valueFloat64(v any) (float64, bool) {
+	f, err := ToFloat64(v)
+	return f, err == nil
+}
+
+func valueInt64(v any) (int64, bool) {
+	i, err := ToInt64(v)
+	return i, err == nil
+}
@@ -0,0 +1,223 @@
+package geko_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const getterDocument = `{
+	"s": "str", "i": 42, "f": 1.5, "b": true, "n": null, "big": 9223372036854775807,
+	"e": 1e2, "huge": 1e19, "o": {"x": 1}, "l": [1]
+}`
+
+func TestGetters(t *testing.T) {
+	for _, useNumber := range []bool{false, true} {
+		doc, _ := geko.JSONUnmarshal([]byte(getterDocument), geko.UseObject(), geko.UseNumber(useNumber))
+		o := doc.(geko.Object)
+
+		if s, err := geko.GetString(o, "s"); err != nil || s != "str" {
+			t.Fatalf("GetString result not correct: %#v, %#v", s, err)
+		}
+
+		if i, err := geko.GetInt64(o, "i"); err != nil || i != 42 {
+			t.Fatalf("GetInt64 result not correct: %#v, %#v", i, err)
+		}
+
+		if i, err := geko.GetInt64(o, "e"); err != nil || i != 100 {
+			t.Fatalf("GetInt64 result not correct: %#v, %#v", i, err)
+		}
+
+		if f, err := geko.GetFloat64(o, "f"); err != nil || f != 1.5 {
+			t.Fatalf("GetFloat64 result not correct: %#v, %#v", f, err)
+		}
+
+		if f, err := geko.GetFloat64(o, "i"); err != nil || f != 42 {
+			t.Fatalf("GetFloat64 result not correct: %#v, %#v", f, err)
+		}
+
+		if b, err := geko.GetBool(o, "b"); err != nil || !b {
+			t.Fatalf("GetBool result not correct: %#v, %#v", b, err)
+		}
+
+		if object, err := geko.GetObject(o, "o"); err != nil || object.GetOrZeroValue("x") == nil {
+			t.Fatalf("GetObject result not correct: %#v, %#v", object, err)
+		}
+
+		if array, err := geko.GetArray(o, "l"); err != nil || array.Len() != 1 {
+			t.Fatalf("GetArray result not correct: %#v, %#v", array, err)
+		}
+
+		for _, key := range []string{"f", "huge", "s", "n"} {
+			if i, err := geko.GetInt64(o, key); err == nil {
+				t.Fatalf("GetInt64 of key %s should fail: %#v", key, i)
+			}
+		}
+	}
+
+	doc, _ := geko.JSONUnmarshal([]byte(getterDocument), geko.UseObject(), geko.UseNumber(true))
+	if i, err := geko.GetInt64(doc.(geko.Object), "big"); err != nil || i != 9223372036854775807 {
+		t.Fatalf("GetInt64 with UseNumber should keep precision: %#v, %#v", i, err)
+	}
+}
+
+func TestGetters_ObjectItems(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"o": {"x": 1, "x": 2}}`), geko.UseObject())
+	o := doc.(geko.Object)
+
+	if ps, err := geko.GetObjectItems(o, "o"); err == nil {
+		t.Fatalf("GetObjectItems should fail on Object: %#v", ps)
+	}
+
+	o.Set("o", geko.NewPairs[string, any]())
+	if ps, err := geko.GetObjectItems(o, "o"); err != nil || ps.Len() != 0 {
+		t.Fatalf("GetObjectItems result not correct: %#v, %#v", ps, err)
+	}
+}
+
+func TestGetters_Error(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(getterDocument), geko.UseObject())
+	o := doc.(geko.Object)
+
+	_, err := geko.GetString(o, "missing")
+
+	var accessErr *geko.AccessError
+	if !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessNotFound || accessErr.Key != "missing" {
+		t.Fatalf("GetString of missing key should fail: %#v", err)
+	}
+
+	if err.Error() != `geko: get string of key "missing": not found` {
+		t.Fatalf("AccessError message not correct: %s", err.Error())
+	}
+
+	_, err = geko.GetObject(o, "n")
+	if !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessWrongType || accessErr.Value != nil {
+		t.Fatalf("GetObject of null should fail: %#v", err)
+	}
+
+	if err.Error() != `geko: get Object of key "n": wrong type <nil>` {
+		t.Fatalf("AccessError message not correct: %s", err.Error())
+	}
+
+	for _, get := range []func(o geko.Object, key string) error{
+		func(o geko.Object, key string) error { _, err := geko.GetFloat64(o, key); return err },
+		func(o geko.Object, key string) error { _, err := geko.GetBool(o, key); return err },
+		func(o geko.Object, key string) error { _, err := geko.GetArray(o, key); return err },
+	} {
+		if err = get(o, "s"); !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessWrongType {
+			t.Fatalf("Getter should fail on wrong type: %#v", err)
+		}
+
+		if err = get(nil, "s"); !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessNotFound {
+			t.Fatalf("Getter should fail on nil object: %#v", err)
+		}
+	}
+}
+
+func TestArrayGetters(t *testing.T) {
+	for _, useNumber := range []bool{false, true} {
+		doc, _ := geko.JSONUnmarshal(
+			[]byte(`["str", 42, 1.5, true, null, {"x": 1}, [1], 1e19]`),
+			geko.UseObject(), geko.UseNumber(useNumber),
+		)
+		l := doc.(geko.Array)
+
+		if s, err := geko.ArrayString(l, 0); err != nil || s != "str" {
+			t.Fatalf("ArrayString result not correct: %#v, %#v", s, err)
+		}
+
+		if i, err := geko.ArrayInt64(l, 1); err != nil || i != 42 {
+			t.Fatalf("ArrayInt64 result not correct: %#v, %#v", i, err)
+		}
+
+		if f, err := geko.ArrayFloat64(l, 2); err != nil || f != 1.5 {
+			t.Fatalf("ArrayFloat64 result not correct: %#v, %#v", f, err)
+		}
+
+		if b, err := geko.ArrayBool(l, 3); err != nil || !b {
+			t.Fatalf("ArrayBool result not correct: %#v, %#v", b, err)
+		}
+
+		if object, err := geko.ArrayObject(l, 5); err != nil || object.Len() != 1 {
+			t.Fatalf("ArrayObject result not correct: %#v, %#v", object, err)
+		}
+
+		if array, err := geko.ArrayArray(l, 6); err != nil || array.Len() != 1 {
+			t.Fatalf("ArrayArray result not correct: %#v, %#v", array, err)
+		}
+
+		for _, i := range []int{2, 4, 7} {
+			if value, err := geko.ArrayInt64(l, i); err == nil {
+				t.Fatalf("ArrayInt64 at index %d should fail: %#v", i, value)
+			}
+		}
+	}
+
+	l := geko.NewListFrom([]any{geko.NewPairs[string, any]()})
+	if ps, err := geko.ArrayObjectItems(l, 0); err != nil || ps.Len() != 0 {
+		t.Fatalf("ArrayObjectItems result not correct: %#v, %#v", ps, err)
+	}
+}
+
+func TestArrayGetters_Error(t *testing.T) {
+	l := geko.NewListFrom([]any{"s", nil})
+
+	var accessErr *geko.AccessError
+	for _, i := range []int{-1, 2} {
+		_, err := geko.ArrayString(l, i)
+		if !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessOutOfRange || accessErr.Key != i {
+			t.Fatalf("ArrayString out of range should fail: %#v", err)
+		}
+	}
+
+	_, err := geko.ArrayString(nil, 0)
+	if !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessOutOfRange {
+		t.Fatalf("ArrayString of nil array should fail: %#v", err)
+	}
+
+	if err.Error() != `geko: get string of index 0: index out of range` {
+		t.Fatalf("AccessError message not correct: %s", err.Error())
+	}
+
+	_, err = geko.ArrayObject(l, 1)
+	if !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessWrongType || accessErr.Key != 1 {
+		t.Fatalf("ArrayObject of null should fail: %#v", err)
+	}
+
+	if err.Error() != `geko: get Object of index 1: wrong type <nil>` {
+		t.Fatalf("AccessError message not correct: %s", err.Error())
+	}
+
+	for _, get := range []func(l geko.Array, i int) error{
+		func(l geko.Array, i int) error { _, err := geko.ArrayFloat64(l, i); return err },
+		func(l geko.Array, i int) error { _, err := geko.ArrayBool(l, i); return err },
+		func(l geko.Array, i int) error { _, err := geko.ArrayObjectItems(l, i); return err },
+		func(l geko.Array, i int) error { _, err := geko.ArrayArray(l, i); return err },
+	} {
+		if err = get(l, 0); !errors.As(err, &accessErr) || accessErr.Kind != geko.AccessWrongType {
+			t.Fatalf("Getter should fail on wrong type: %#v", err)
+		}
+	}
+}
+
+func TestArrayStrings(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`["a", "b", "c"]`))
+
+	if result, err := geko.ArrayStrings(doc.(geko.Array)); err != nil || len(result) != 3 || result[2] != "c" {
+		t.Fatalf("ArrayStrings result not correct: %#v, %#v", result, err)
+	}
+
+	for _, l := range []geko.Array{nil, geko.NewList[any]()} {
+		if result, err := geko.ArrayStrings(l); err != nil || result != nil {
+			t.Fatalf("ArrayStrings of empty array should be nil: %#v, %#v", result, err)
+		}
+	}
+
+	_, err := geko.ArrayStrings(geko.NewListFrom([]any{"a", 1.0}))
+
+	var accessErr *geko.AccessError
+	if !errors.As(err, &accessErr) || accessErr.Key != 1 || accessErr.Kind != geko.AccessWrongType {
+		t.Fatalf("ArrayStrings should fail on non-string item: %#v", err)
+	}
+}
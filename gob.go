@@ -0,0 +1,173 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	// dynamic values are stored in interfaces, gob needs their concrete types
+	gob.Register(NewMap[string, any]())
+	gob.Register(NewPairs[string, any]())
+	gob.Register(NewList[any]())
+	gob.Register(json.Number(""))
+}
+
+// gobMembers is the wire format of [Map] and [Pairs] in gob.
+type gobMembers[K comparable, V any] struct {
+	Strategy DuplicatedKeyStrategy
+	Keys     []K
+	Values   []V
+}
+
+// gobItems is the wire format of [List] in gob.
+type gobItems[T any] struct {
+	Items []T
+}
+
+func gobEncode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// gobValue replaces nil containers with a nil interface, because gob can't
+// encode nil pointers inside interfaces.
+func gobValue[T any](v T) T {
+	if isNull(v) {
+		var zero T
+		return zero
+	}
+	return v
+}
+
+func gobDecodeMembers[K comparable, V any](data []byte) (*gobMembers[K, V], error) {
+	var members gobMembers[K, V]
+	if err := gobDecode(data, &members); err != nil {
+		return nil, err
+	}
+
+	if len(members.Keys) != len(members.Values) {
+		return nil, fmt.Errorf(
+			"geko: gob data has %d keys but %d values", len(members.Keys), len(members.Values),
+		)
+	}
+
+	return &members, nil
+}
+
+// GobEncode implements [gob.GobEncoder] interface. Keys and values are
+// encoded by gob in order, with the [DuplicatedKeyStrategy] of the map.
+//
+// [Object], [ObjectItems], [Array] and json.Number are registered by this
+// package, so they can be used as values of any type. Other concrete types
+// stored in any should be registered by [gob.Register]. Nil [Object],
+// [ObjectItems] and [Array] values are encoded as nil.
+func (m *Map[K, V]) GobEncode() ([]byte, error) {
+	members := gobMembers[K, V]{
+		Strategy: m.duplicatedKeyStrategy,
+		Keys:     m.order,
+		Values:   make([]V, len(m.order)),
+	}
+
+	for i, key := range m.order {
+		members.Values[i] = gobValue(m.inner[key])
+	}
+
+	return gobEncode(&members)
+}
+
+// GobDecode implements [gob.GobDecoder] interface. Current content of the map
+// is replaced, and its [DuplicatedKeyStrategy] is restored.
+func (m *Map[K, V]) GobDecode(data []byte) error {
+	if err := m.checkFrozen("GobDecode"); err != nil {
+		return err
+	}
+
+	members, err := gobDecodeMembers[K, V](data)
+	if err != nil {
+		return err
+	}
+
+	m.Clear()
+	m.duplicatedKeyStrategy = members.Strategy
+	for i, key := range members.Keys {
+		m.Set(key, members.Values[i])
+	}
+
+	return nil
+}
+
+// GobEncode implements [gob.GobEncoder] interface. Keys and values are
+// encoded by gob in order, duplicated keys are all kept. See
+// [Map.GobEncode] for values of any type.
+func (ps *Pairs[K, V]) GobEncode() ([]byte, error) {
+	members := gobMembers[K, V]{
+		Keys:   make([]K, len(ps.List)),
+		Values: make([]V, len(ps.List)),
+	}
+
+	for i, pair := range ps.List {
+		members.Keys[i] = pair.Key
+		members.Values[i] = gobValue(pair.Value)
+	}
+
+	return gobEncode(&members)
+}
+
+// GobDecode implements [gob.GobDecoder] interface. Current content of the
+// pairs is replaced.
+func (ps *Pairs[K, V]) GobDecode(data []byte) error {
+	if err := ps.checkFrozen("GobDecode"); err != nil {
+		return err
+	}
+
+	members, err := gobDecodeMembers[K, V](data)
+	if err != nil {
+		return err
+	}
+
+	ps.Clear()
+	for i, key := range members.Keys {
+		ps.Add(key, members.Values[i])
+	}
+
+	return nil
+}
+
+// GobEncode implements [gob.GobEncoder] interface. Items are encoded by gob
+// in order, see [Map.GobEncode] for items of any type.
+func (l *List[T]) GobEncode() ([]byte, error) {
+	items := gobItems[T]{Items: make([]T, len(l.List))}
+
+	for i, item := range l.List {
+		items.Items[i] = gobValue(item)
+	}
+
+	return gobEncode(&items)
+}
+
+// GobDecode implements [gob.GobDecoder] interface. The inner slice is
+// replaced, unless [List.AppendOnUnmarshal] is enabled.
+func (l *List[T]) GobDecode(data []byte) error {
+	if err := l.checkFrozen("GobDecode"); err != nil {
+		return err
+	}
+
+	var items gobItems[T]
+	if err := gobDecode(data, &items); err != nil {
+		return err
+	}
+
+	l.storeDecoded(items.Items)
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func gobRoundTrip(t *testing.T, v, target any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatalf("gob Encode with error: %s", err.Error())
+	}
+
+	if err := gob.NewDecoder(&buf).Decode(target); err != nil {
+		t.Fatalf("gob Decode with error: %s", err.Error())
+	}
+}
+
+func TestGob_RoundTrip(t *testing.T) {
+	data := `{"z": 1, "a": [1.5, "x", {"n": null, "m": true, "b": {}}, []], "k": {"y": [], "x": -2}}`
+
+	v, _ := geko.JSONUnmarshal([]byte(data), geko.UseObject(), geko.UseNumber(true))
+	object := v.(geko.Object)
+	object.SetDuplicatedKeyStrategy(geko.Ignore)
+
+	decoded := geko.NewMap[string, any]()
+	decoded.Set("old", 1)
+	gobRoundTrip(t, object, decoded)
+
+	before, _ := json.Marshal(object)
+	after, _ := json.Marshal(decoded)
+	if string(before) != string(after) || decoded.DuplicatedKeyStrategy() != geko.Ignore {
+		t.Fatalf("gob round trip result not correct: %s", string(after))
+	}
+
+	if _, ok := decoded.GetOrZeroValue("z").(json.Number); !ok {
+		t.Fatalf("gob round trip value type not correct: %#v", decoded.GetOrZeroValue("z"))
+	}
+
+	v, _ = geko.JSONUnmarshal([]byte(`{"a": 1, "a": {"b": [2]}}`))
+	items := v.(geko.ObjectItems)
+
+	decodedItems := geko.NewPairs[string, any]()
+	gobRoundTrip(t, items, decodedItems)
+
+	after, _ = json.Marshal(decodedItems)
+	if string(after) != `{"a":1,"a":{"b":[2]}}` {
+		t.Fatalf("gob round trip result not correct: %s", string(after))
+	}
+}
+
+func TestGob_Typed(t *testing.T) {
+	m := geko.NewMap[int, []string]()
+	m.Set(2, []string{"b"})
+	m.Set(1, nil)
+
+	decoded := geko.NewMap[int, []string]()
+	gobRoundTrip(t, m, decoded)
+
+	if len(decoded.Keys()) != 2 || decoded.GetKeyByIndex(0) != 2 || decoded.GetOrZeroValue(2)[0] != "b" {
+		t.Fatalf("Typed map round trip result not correct: %#v", decoded)
+	}
+
+	l := geko.NewListFrom([]int{1, 2})
+
+	decodedList := geko.NewListFrom([]int{0})
+	decodedList.SetAppendOnUnmarshal(true)
+	gobRoundTrip(t, l, decodedList)
+
+	if len(decodedList.List) != 3 || decodedList.Get(2) != 2 {
+		t.Fatalf("Typed list round trip result not correct: %#v", decodedList)
+	}
+
+	decodedList.SetAppendOnUnmarshal(false)
+	gobRoundTrip(t, l, decodedList)
+
+	if len(decodedList.List) != 2 || decodedList.Get(0) != 1 {
+		t.Fatalf("Typed list round trip result not correct: %#v", decodedList)
+	}
+}
+
+type gobTestContainers struct {
+	Object geko.Object
+	Items  geko.ObjectItems
+	Array  geko.Array
+	Value  any
+}
+
+func TestGob_EmptyAndNil(t *testing.T) {
+	var nilObject geko.Object
+
+	empty := gobTestContainers{
+		Object: geko.NewMap[string, any](),
+		Items:  geko.NewPairs[string, any](),
+		Array:  geko.NewListFrom([]any{nilObject, nil}),
+		Value:  geko.NewList[any](),
+	}
+
+	var decoded gobTestContainers
+	gobRoundTrip(t, &empty, &decoded)
+
+	output, _ := json.Marshal(decoded)
+	if string(output) != `{"Object":{},"Items":{},"Array":[null,null],"Value":[]}` {
+		t.Fatalf("gob round trip of empty containers not correct: %s", string(output))
+	}
+
+	decoded = gobTestContainers{}
+	gobRoundTrip(t, &gobTestContainers{}, &decoded)
+
+	if decoded.Object != nil || decoded.Items != nil || decoded.Array != nil || decoded.Value != nil {
+		t.Fatalf("gob round trip of nil containers not correct: %#v", decoded)
+	}
+}
+
+func TestGob_Error(t *testing.T) {
+	for _, target := range []interface{ GobDecode(data []byte) error }{
+		geko.NewMap[string, any](),
+		geko.NewPairs[string, any](),
+		geko.NewList[any](),
+	} {
+		if err := target.GobDecode([]byte("x")); err == nil {
+			t.Fatalf("GobDecode should fail: %#v", target)
+		}
+	}
+
+	// keys and values are not paired
+	var buf bytes.Buffer
+	_ = gob.NewEncoder(&buf).Encode(&struct{ Keys []string }{Keys: []string{"a"}})
+
+	for _, target := range []interface{ GobDecode(data []byte) error }{
+		geko.NewMap[string, any](),
+		geko.NewPairs[string, any](),
+	} {
+		if err := target.GobDecode(buf.Bytes()); err == nil {
+			t.Fatalf("GobDecode should fail: %#v", target)
+		}
+	}
+
+	m := geko.NewMap[string, any]()
+	m.Set("a", make(chan int))
+	if _, err := m.GobEncode(); err == nil {
+		t.Fatalf("GobEncode should fail: %#v", m)
+	}
+}
@@ -0,0 +1,333 @@
+package geko
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// HashOptions are options for controlling the behavior of [Hash].
+//
+// Default value (created by [CreateHashOptions]) of it is:
+//
+//   - Order of object members affects the hash.
+//
+// See also: [CreateHashOptions], [IgnoreKeyOrder].
+type HashOptions struct {
+	ignoreKeyOrder bool
+}
+
+// HashOption is atom/modifier of [HashOptions].
+type HashOption func(opts *HashOptions)
+
+// CreateHashOptions creates a [HashOptions] by apply all option to the
+// default hash option.
+func CreateHashOptions(option ...HashOption) HashOptions {
+	opts := HashOptions{}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *HashOptions) Apply(option ...HashOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// IgnoreKeyOrder specifies whether order of object members should be ignored.
+// If enabled, members are hashed one by one and combined commutatively, so
+// objects with same members in different order have the same hash. Duplicated
+// members are still counted.
+//
+// Order of array items always matters.
+func IgnoreKeyOrder(v bool) HashOption {
+	return func(opts *HashOptions) {
+		opts.ignoreKeyOrder = v
+	}
+}
+
+// Hash writes a deterministic encoding of v into h, and returns h.Sum(nil).
+// h is not reset before writing.
+//
+// The encoding contains type tags, keys and canonicalized scalars, it's stable
+// across processes and independent of how the tree is built:
+//
+//   - Numbers are compared by their decimal value, so float64(1),
+//     json.Number("1.0"), int64(1) and a *big.Int 1 are the same. NaN and
+//     infinity are not allowed.
+//   - [Map], [Pairs] and map[string]any are all objects, [List] and []any are
+//     all arrays. A map[string]any is always hashed like [IgnoreKeyOrder] is
+//     applied, because it has no order.
+//   - Other values are encoded by [json.Marshal] first.
+func Hash(v any, h hash.Hash, option ...HashOption) ([]byte, error) {
+	s := hashState{opts: CreateHashOptions(option...)}
+	if err := s.value(h, v); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Sum64 is a convenience of [Hash] which uses 64-bit FNV-1a hash.
+func Sum64(v any, option ...HashOption) (uint64, error) {
+	h := fnv.New64a()
+	if _, err := Hash(v, h, option...); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
+
+const (
+	hashTagNull          = 'n'
+	hashTagTrue          = 't'
+	hashTagFalse         = 'f'
+	hashTagNumber        = 'd'
+	hashTagString        = 's'
+	hashTagArray         = 'a'
+	hashTagObject        = 'o'
+	hashTagUnorderObject = 'u'
+	hashTagEnd           = 'e'
+)
+
+type hashState struct {
+	opts HashOptions
+}
+
+type rangeFunc = func(fn func(key, value any) error) error
+
+func (s *hashState) value(w io.Writer, v any) error {
+	switch x := v.(type) {
+	case nil:
+		writeHashTag(w, hashTagNull)
+	case bool:
+		if x {
+			writeHashTag(w, hashTagTrue)
+		} else {
+			writeHashTag(w, hashTagFalse)
+		}
+	case string:
+		writeHashBytes(w, hashTagString, x)
+	case ObjectItems:
+		return s.object(w, x == nil, x.rangeChildren, s.opts.ignoreKeyOrder)
+	case Object:
+		return s.object(w, x == nil, x.rangeChildren, s.opts.ignoreKeyOrder)
+	case map[string]any:
+		return s.object(w, x == nil, rangeStdMap(x), true)
+	case Array:
+		return s.array(w, x == nil, x.rangeChildren)
+	case []any:
+		return s.array(w, x == nil, rangeStdSlice(x))
+	default:
+		return s.other(w, v)
+	}
+
+	return nil
+}
+
+func (s *hashState) other(w io.Writer, v any) error {
+	if n, ok, err := hashNumber(v); ok {
+		if err != nil {
+			return err
+		}
+		writeHashBytes(w, hashTagNumber, n)
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// std lib always produces valid JSON
+	value, _ := JSONUnmarshal(data, UseNumber(true))
+
+	return s.value(w, value)
+}
+
+func (s *hashState) array(w io.Writer, null bool, each rangeFunc) error {
+	if null {
+		writeHashTag(w, hashTagNull)
+		return nil
+	}
+
+	writeHashTag(w, hashTagArray)
+
+	err := each(func(_, value any) error {
+		return s.value(w, value)
+	})
+
+	writeHashTag(w, hashTagEnd)
+
+	return err
+}
+
+func (s *hashState) object(w io.Writer, null bool, each rangeFunc, unordered bool) error {
+	if null {
+		writeHashTag(w, hashTagNull)
+		return nil
+	}
+
+	if !unordered {
+		writeHashTag(w, hashTagObject)
+
+		err := each(func(key, value any) error {
+			k, _ := key.(string)
+			writeHashBytes(w, hashTagString, k)
+			return s.value(w, value)
+		})
+
+		writeHashTag(w, hashTagEnd)
+
+		return err
+	}
+
+	// Sum of member digests, modulo 2^256, which is commutative and does not
+	// cancel duplicated members like XOR.
+	var sum [sha256.Size]byte
+	var member bytes.Buffer
+	n := 0
+
+	err := each(func(key, value any) error {
+		n++
+		member.Reset()
+		k, _ := key.(string)
+		writeHashBytes(&member, hashTagString, k)
+		if err := s.value(&member, value); err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256(member.Bytes())
+		carry := 0
+		for i := len(sum) - 1; i >= 0; i-- {
+			carry += int(sum[i]) + int(digest[i])
+			sum[i] = byte(carry)
+			carry >>= 8
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	writeHashTag(w, hashTagUnorderObject)
+	writeHashLength(w, n)
+	_, _ = w.Write(sum[:])
+
+	return nil
+}
+
+func rangeStdMap(m map[string]any) rangeFunc {
+	return func(fn func(key, value any) error) error {
+		for k, v := range m {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func rangeStdSlice(s []any) rangeFunc {
+	return func(fn func(key, value any) error) error {
+		for i, v := range s {
+			if err := fn(i, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func writeHashTag(w io.Writer, tag byte) {
+	_, _ = w.Write([]byte{tag})
+}
+
+func writeHashLength(w io.Writer, n int) {
+	var buf [binary.MaxVarintLen64]byte
+	_, _ = w.Write(buf[:binary.PutUvarint(buf[:], uint64(n))])
+}
+
+func writeHashBytes(w io.Writer, tag byte, s string) {
+	writeHashTag(w, tag)
+	writeHashLength(w, len(s))
+	_, _ = io.WriteString(w, s)
+}
+
+// hashNumber returns the normalized decimal text of v, if it's a number.
+func hashNumber(v any) (string, bool, error) {
+	var text string
+
+	switch x := v.(type) {
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return "", true, fmt.Errorf("geko: can't hash number %v", x)
+		}
+		text = strconv.FormatFloat(x, 'e', -1, 64)
+	case float32:
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			return "", true, fmt.Errorf("geko: can't hash number %v", x)
+		}
+		text = strconv.FormatFloat(float64(x), 'e', -1, 32)
+	case json.Number:
+		text = string(x)
+	case *big.Int:
+		text = x.String()
+	case *big.Float:
+		text = x.Text('e', -1)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		text = fmt.Sprint(x)
+	default:
+		return "", false, nil
+	}
+
+	n, err := normalizeDecimal(text)
+	return n, true, err
+}
+
+// normalizeDecimal converts a decimal number text into form of
+// [-]<digits>e<exp>, digits has no leading or trailing zero. Zero is "0".
+func normalizeDecimal(s string) (string, error) {
+	negative := strings.HasPrefix(s, "-")
+	mantissa, exp := strings.TrimPrefix(s, "-"), "0"
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		mantissa, exp = mantissa[:i], mantissa[i+1:]
+	}
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+
+	e, err := strconv.Atoi(exp)
+	if err != nil || !isDecimalDigits(integer) || (fraction != "" && !isDecimalDigits(fraction)) {
+		return "", fmt.Errorf("geko: can't hash number %q", s)
+	}
+
+	digits := strings.TrimLeft(integer+fraction, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	if trimmed == "" {
+		return "0", nil
+	}
+	e = e - len(fraction) + len(digits) - len(trimmed)
+
+	if negative {
+		trimmed = "-" + trimmed
+	}
+
+	return trimmed + "e" + strconv.Itoa(e), nil
+}
+
+func isDecimalDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
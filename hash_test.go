@@ -0,0 +1,184 @@
+package geko_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func mustSum64(t *testing.T, v any, option ...geko.HashOption) uint64 {
+	t.Helper()
+
+	sum, err := geko.Sum64(v, option...)
+	if err != nil {
+		t.Fatalf("Sum64 with error: %s", err.Error())
+	}
+
+	return sum
+}
+
+func TestHash_BuiltDifferentWays(t *testing.T) {
+	decoded, _ := geko.JSONUnmarshal([]byte(`{"a": [1, "s", null], "b": {"c": true, "d": 2.50}}`), geko.UseObject())
+	numbers, _ := geko.JSONUnmarshal(
+		[]byte(`{"a": [1.0, "s", null], "b": {"c": true, "d": 25e-1}}`),
+		geko.UseNumber(true),
+	)
+
+	inner := geko.NewMap[string, any]()
+	inner.Set("c", true)
+	inner.Set("d", big.NewFloat(2.5))
+	built := geko.NewPairs[string, any]()
+	built.Add("a", []any{int64(1), "s", nil})
+	built.Add("b", inner)
+
+	typed := geko.NewMap[string, float64]()
+	typed.Set("c", 1)
+	std := map[string]any{"c": uint8(1)}
+
+	excepted := mustSum64(t, decoded)
+	for _, v := range []any{numbers, built} {
+		if sum := mustSum64(t, v); sum != excepted {
+			t.Fatalf("Hash of equal trees should be equal: %x, %x", sum, excepted)
+		}
+	}
+
+	if mustSum64(t, typed, geko.IgnoreKeyOrder(true)) != mustSum64(t, std) {
+		t.Fatalf("Hash of equal trees should be equal")
+	}
+}
+
+func TestHash_Order(t *testing.T) {
+	a, _ := geko.JSONUnmarshal([]byte(`{"x": 1, "y": {"p": [1, 2], "q": 3}}`))
+	b, _ := geko.JSONUnmarshal([]byte(`{"y": {"q": 3, "p": [1, 2]}, "x": 1}`))
+	c, _ := geko.JSONUnmarshal([]byte(`{"y": {"q": 3, "p": [2, 1]}, "x": 1}`))
+
+	if mustSum64(t, a) == mustSum64(t, b) {
+		t.Fatalf("Hash should be different when key order differs")
+	}
+
+	ignoreOrder := geko.IgnoreKeyOrder(true)
+	if mustSum64(t, a, ignoreOrder) != mustSum64(t, b, ignoreOrder) {
+		t.Fatalf("Hash should be equal when key order is ignored")
+	}
+
+	if mustSum64(t, b, ignoreOrder) == mustSum64(t, c, ignoreOrder) {
+		t.Fatalf("Hash should be different when array order differs")
+	}
+
+	std := map[string]any{"y": map[string]any{"q": 3, "p": []any{1, 2}}, "x": 1}
+	if mustSum64(t, std) != mustSum64(t, a, ignoreOrder) {
+		t.Fatalf("Hash of map[string]any should ignore key order")
+	}
+}
+
+func TestHash_Distinct(t *testing.T) {
+	dup1, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "a": 1}`))
+	dup2, _ := geko.JSONUnmarshal([]byte(`{"a": 1}`))
+
+	values := []any{
+		nil, true, false, "", "1", 1, 10, 0.1, -1, json.Number("12345678901234567891"),
+		json.Number("12345678901234567892"), []any{}, []any{nil}, []any{[]any{}},
+		map[string]any{}, map[string]any{"": nil}, []any{"a", "b"}, []any{"ab"},
+		dup1, dup2,
+	}
+
+	for _, option := range []geko.HashOption{geko.IgnoreKeyOrder(false), geko.IgnoreKeyOrder(true)} {
+		seen := make(map[uint64]int)
+		for i, v := range values {
+			sum := mustSum64(t, v, option)
+			if j, exist := seen[sum]; exist {
+				t.Fatalf("Hash of %#v and %#v should be different", values[j], v)
+			}
+			seen[sum] = i
+		}
+	}
+}
+
+func TestHash_Numbers(t *testing.T) {
+	values := []any{
+		json.Number("100"), json.Number("1E2"), json.Number("1.00e+2"), json.Number("100.0"),
+		float32(100), 100.0, uint(100), int8(100), big.NewInt(100), big.NewFloat(100),
+	}
+
+	excepted := mustSum64(t, values[0])
+	for _, v := range values[1:] {
+		if mustSum64(t, v) != excepted {
+			t.Fatalf("Hash of number %#v should be equal to 100", v)
+		}
+	}
+
+	if mustSum64(t, json.Number("-0.0")) != mustSum64(t, 0) || mustSum64(t, -0.5) == mustSum64(t, 0.5) {
+		t.Fatalf("Hash of number sign not correct")
+	}
+}
+
+func TestHash_OtherTypes(t *testing.T) {
+	type S struct {
+		A int    `json:"a"`
+		B string `json:"b"`
+	}
+
+	object := geko.NewMap[string, any]()
+	object.Set("a", 1)
+	object.Set("b", "s")
+
+	if mustSum64(t, S{A: 1, B: "s"}) != mustSum64(t, object) {
+		t.Fatalf("Hash of struct should be equal to its JSON object")
+	}
+}
+
+func TestHash_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+	var stdMap map[string]any
+	var stdSlice []any
+
+	excepted := mustSum64(t, nil)
+	for _, v := range []any{m, ps, l, stdMap, stdSlice} {
+		if mustSum64(t, v) != excepted {
+			t.Fatalf("Hash of nil container %#v should be equal to null", v)
+		}
+	}
+}
+
+func TestHash_HashFunc(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"a": [1, 2]}`))
+
+	sum1, err := geko.Hash(v, sha256.New())
+	if err != nil || len(sum1) != sha256.Size {
+		t.Fatalf("Hash result not correct: %x, %#v", sum1, err)
+	}
+
+	sum2, _ := geko.Hash(v, sha256.New())
+	if !bytes.Equal(sum1, sum2) {
+		t.Fatalf("Hash should be deterministic")
+	}
+}
+
+func TestHash_Error(t *testing.T) {
+	nan := geko.NewPairs[string, any]()
+	nan.Add("a", math.NaN())
+
+	values := []any{
+		math.Inf(1), float32(math.Inf(-1)), json.Number("1x"), json.Number("1e"), json.Number(".5"),
+		json.Number("1.x"), new(big.Float).SetInf(false), make(chan int),
+		[]any{math.NaN()}, nan, map[string]any{"a": math.NaN()},
+	}
+
+	for _, option := range []geko.HashOption{geko.IgnoreKeyOrder(false), geko.IgnoreKeyOrder(true)} {
+		for _, v := range values {
+			if _, err := geko.Hash(v, sha256.New(), option); err == nil {
+				t.Fatalf("Hash of %#v should fail", v)
+			}
+			if _, err := geko.Sum64(v, option); err == nil {
+				t.Fatalf("Sum64 of %#v should fail", v)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package geko
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HeaderOptions are options for controlling the behavior of
+// [WriteHeaderOrdered].
+//
+// Default value (created by [CreateHeaderOptions]) of it is:
+//
+//   - Keys are written as is.
+//
+// See also: [CreateHeaderOptions], [CanonicalHeaderKeys].
+type HeaderOptions struct {
+	canonicalKeys bool
+}
+
+// HeaderOption is atom/modifier of [HeaderOptions].
+type HeaderOption func(opts *HeaderOptions)
+
+// CreateHeaderOptions creates a [HeaderOptions] by apply all option to the
+// default header option.
+func CreateHeaderOptions(option ...HeaderOption) HeaderOptions {
+	opts := HeaderOptions{}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *HeaderOptions) Apply(option ...HeaderOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// CanonicalHeaderKeys specifies whether keys should be converted into
+// canonical form by [http.CanonicalHeaderKey] when written, like
+// "content-type" to "Content-Type".
+func CanonicalHeaderKeys(v bool) HeaderOption {
+	return func(opts *HeaderOptions) {
+		opts.canonicalKeys = v
+	}
+}
+
+// PairsFromHeader converts h into pairs. Because [http.Header] is a map, the
+// original order of header lines is already lost, so keys are sorted to make
+// the result stable, and values of a key are kept in their order.
+//
+// A nil h results an empty pairs.
+func PairsFromHeader(h http.Header) *Pairs[string, string] {
+	keys := make([]string, 0, len(h))
+	count := 0
+	for key, values := range h {
+		keys = append(keys, key)
+		count += len(values)
+	}
+	sort.Strings(keys)
+
+	ps := NewPairsWithCapacity[string, string](count)
+	for _, key := range keys {
+		for _, value := range h[key] {
+			ps.Add(key, value)
+		}
+	}
+
+	return ps
+}
+
+// ToHeader converts the pairs into a [http.Header], by [http.Header.Add], so
+// keys are converted into canonical form. Keys and values which are not
+// strings are formatted by [fmt.Sprint].
+//
+// Values of the same key are kept in order, like multiple Set-Cookie lines,
+// but order between different keys is lost, because [http.Header] is a map.
+// Use [WriteHeaderOrdered] if it matters.
+//
+// A nil pairs results a nil [http.Header].
+func (ps *Pairs[K, V]) ToHeader() http.Header {
+	if ps == nil {
+		return nil
+	}
+
+	h := make(http.Header, ps.Len())
+	for i := 0; i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+		h.Add(stdKey(pair.Key), stdKey(pair.Value))
+	}
+
+	return h
+}
+
+// headerNewlineToSpace replaces newlines, so a key or value can't start a new
+// header line.
+var headerNewlineToSpace = strings.NewReplacer("\r", " ", "\n", " ")
+
+// WriteHeaderOrdered writes ps into w in HTTP header format, a "Key: value"
+// line ended with "\r\n" for each pair, in order of ps, with provided option
+// applied. Duplicated keys are all written at their own position.
+//
+// Like [http.Header.Write], newlines in keys and values are replaced by
+// spaces, and leading and trailing spaces and tabs of values are trimmed. No blank
+// line is written after the header. A nil or empty ps writes nothing.
+func WriteHeaderOrdered(w io.Writer, ps *Pairs[string, string], option ...HeaderOption) error {
+	opts := CreateHeaderOptions(option...)
+
+	for i := 0; ps != nil && i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+
+		key := headerNewlineToSpace.Replace(pair.Key)
+		if opts.canonicalKeys {
+			key = http.CanonicalHeaderKey(key)
+		}
+		value := strings.Trim(headerNewlineToSpace.Replace(pair.Value), " \t")
+
+		if _, err := io.WriteString(w, key+": "+value+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
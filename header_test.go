@@ -0,0 +1,116 @@
+package geko_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestPairsFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Add("Set-Cookie", "b=2")
+	h.Add("Content-Type", "text/plain")
+	h.Add("Set-Cookie", "a=1")
+	h.Add("Accept", "*/*")
+
+	excepted := geko.NewPairsOf(
+		geko.P("Accept", "*/*"),
+		geko.P("Content-Type", "text/plain"),
+		geko.P("Set-Cookie", "b=2"),
+		geko.P("Set-Cookie", "a=1"),
+	)
+	if ps := geko.PairsFromHeader(h); !reflect.DeepEqual(ps.List, excepted.List) {
+		t.Fatalf("PairsFromHeader result not correct: %#v", ps.List)
+	}
+
+	if ps := geko.PairsFromHeader(nil); ps == nil || ps.Len() != 0 {
+		t.Fatalf("PairsFromHeader of nil should be empty: %#v", ps)
+	}
+}
+
+func TestPairs_ToHeader(t *testing.T) {
+	ps := geko.NewPairsOf(
+		geko.P("set-cookie", "session=1; HttpOnly"),
+		geko.P("Content-Type", "text/html"),
+		geko.P("Set-Cookie", "theme=dark"),
+		geko.P("X-Request-Id", "42"),
+		geko.P("SET-COOKIE", "lang=en"),
+	)
+
+	h := ps.ToHeader()
+
+	// duplicated keys in different cases are merged, in order
+	cookies := []string{"session=1; HttpOnly", "theme=dark", "lang=en"}
+	if !reflect.DeepEqual(h.Values("Set-Cookie"), cookies) || h.Get("Content-Type") != "text/html" || len(h) != 3 {
+		t.Fatalf("ToHeader result not correct: %#v", h)
+	}
+
+	// order between different keys is lost in round trip, keys become sorted
+	excepted := geko.NewPairsOf(
+		geko.P("Content-Type", "text/html"),
+		geko.P("Set-Cookie", "session=1; HttpOnly"),
+		geko.P("Set-Cookie", "theme=dark"),
+		geko.P("Set-Cookie", "lang=en"),
+		geko.P("X-Request-Id", "42"),
+	)
+	if back := geko.PairsFromHeader(h); !reflect.DeepEqual(back.List, excepted.List) {
+		t.Fatalf("ToHeader round trip result not correct: %#v", back.List)
+	}
+
+	numbers := geko.NewPairsOf(geko.P("x-count", 1), geko.P("x-count", 2))
+	if h = numbers.ToHeader(); !reflect.DeepEqual(h["X-Count"], []string{"1", "2"}) {
+		t.Fatalf("ToHeader of non-string values not correct: %#v", h)
+	}
+
+	var nilPairs *geko.Pairs[string, string]
+	if h = nilPairs.ToHeader(); h != nil {
+		t.Fatalf("ToHeader of nil pairs should be nil: %#v", h)
+	}
+}
+
+func TestWriteHeaderOrdered(t *testing.T) {
+	ps := geko.NewPairsOf(
+		geko.P("set-cookie", "session=1; HttpOnly"),
+		geko.P("Content-Type", " text/html\t"),
+		geko.P("Set-Cookie", "theme=dark"),
+		geko.P("x-injected\r\nEvil", "a\r\nEvil: b"),
+	)
+
+	var sb strings.Builder
+	if err := geko.WriteHeaderOrdered(&sb, ps); err != nil {
+		t.Fatalf("WriteHeaderOrdered with error: %s", err.Error())
+	}
+
+	excepted := "set-cookie: session=1; HttpOnly\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Set-Cookie: theme=dark\r\n" +
+		"x-injected  Evil: a  Evil: b\r\n"
+	if sb.String() != excepted {
+		t.Fatalf("WriteHeaderOrdered result not correct: %q", sb.String())
+	}
+
+	sb.Reset()
+	if err := geko.WriteHeaderOrdered(&sb, ps, geko.CanonicalHeaderKeys(true)); err != nil {
+		t.Fatalf("WriteHeaderOrdered with error: %s", err.Error())
+	}
+
+	excepted = "Set-Cookie: session=1; HttpOnly\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Set-Cookie: theme=dark\r\n" +
+		"x-injected  Evil: a  Evil: b\r\n"
+	if sb.String() != excepted {
+		t.Fatalf("WriteHeaderOrdered with canonical keys result not correct: %q", sb.String())
+	}
+
+	sb.Reset()
+	if err := geko.WriteHeaderOrdered(&sb, nil); err != nil || sb.Len() != 0 {
+		t.Fatalf("WriteHeaderOrdered of nil pairs should write nothing: %q, %#v", sb.String(), err)
+	}
+
+	if err := geko.WriteHeaderOrdered(&limitedWriter{limit: 2}, ps); err == nil {
+		t.Fatalf("WriteHeaderOrdered should report write error")
+	}
+}
@@ -0,0 +1,128 @@
+package geko
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// DecodeHTTPBody decodes a JSON value from r, which is usually the body of a
+// HTTP request, like [JSONUnmarshal] with provided option applied.
+//
+// At most maxBytes bytes are read from r, a body larger than it fails with
+// [BodyTooLarge], without reading the rest. If maxBytes <= 0, there is no
+// limit. Data after the value is not allowed, even if [AllowTrailingData] is
+// in option.
+//
+// All errors are returned as [HTTPBodyError], its Kind tells why it fails, so
+// a proper status code can be chosen without matching error message.
+//
+// It takes an [io.Reader] instead of a *http.Request, so it can be used with
+// any HTTP framework.
+func DecodeHTTPBody(r io.Reader, maxBytes int64, option ...DecodeOption) (any, error) {
+	opts := CreateDecodeOptions(option...)
+	opts.allowTrailingData = false
+
+	return decodeHTTPBody(r, maxBytes, opts)
+}
+
+// DecodeHTTPObject decodes a HTTP body like [DecodeHTTPBody], but the value
+// must be a JSON object, which is returned as an [Object], like
+// [NewObjectFromJSON].
+//
+// If the value is not an object, including null, an [HTTPBodyError] with
+// [BodyWrongType] is returned.
+func DecodeHTTPObject(r io.Reader, maxBytes int64, option ...DecodeOption) (Object, error) {
+	opts := CreateDecodeOptions(UseObject())
+	opts.Apply(option...)
+	opts.allowTrailingData = false
+
+	value, err := decodeHTTPBody(r, maxBytes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := value.(type) {
+	case Object:
+		return x, nil
+	case ObjectItems:
+		return x.ToMap(opts.duplicatedKeyStrategy), nil
+	default:
+		return nil, &HTTPBodyError{
+			Kind: BodyWrongType,
+			Err: &json.UnmarshalTypeError{
+				Value: "non-object value",
+				Type:  reflect.TypeOf(Object(nil)),
+			},
+		}
+	}
+}
+
+func decodeHTTPBody(r io.Reader, maxBytes int64, opts DecodeOptions) (any, error) {
+	body := &bodyReader{r: r, limit: maxBytes, remain: maxBytes}
+	d := newDecoder(body, opts)
+
+	value, err := d.decode()
+	if err == nil {
+		return value, nil
+	}
+
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case body.exceeded:
+		return nil, &HTTPBodyError{Kind: BodyTooLarge, Offset: maxBytes, Err: body.limitError()}
+	case body.err != nil:
+		return nil, &HTTPBodyError{Kind: BodyReadFailed, Offset: d.InputOffset(), Err: body.err}
+	case errors.As(err, &syntaxErr):
+		return nil, &HTTPBodyError{Kind: BodyMalformed, Offset: syntaxErr.Offset, Err: err}
+	default:
+		return nil, &HTTPBodyError{Kind: BodyMalformed, Offset: d.InputOffset(), Err: err}
+	}
+}
+
+// bodyReader reads at most limit bytes from r, if limit > 0. It records why
+// reading fails, so the error can be told apart from errors of decoding.
+type bodyReader struct {
+	r      io.Reader
+	limit  int64
+	remain int64
+
+	exceeded bool
+	err      error
+}
+
+func (b *bodyReader) limitError() error {
+	return &LimitExceededError{Kind: BytesLimit, Limit: b.limit, Offset: b.limit}
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	// the extra byte is dropped, so keep failing
+	if b.exceeded {
+		return 0, b.limitError()
+	}
+
+	// read one more byte than remain, to know if the body is too large
+	if b.limit > 0 && int64(len(p)) > b.remain+1 {
+		p = p[:b.remain+1]
+	}
+
+	n, err := b.r.Read(p)
+
+	if b.limit > 0 {
+		if int64(n) > b.remain {
+			n = int(b.remain)
+			b.remain = 0
+			b.exceeded = true
+			return n, b.limitError()
+		}
+		b.remain -= int64(n)
+	}
+
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+
+	return n, err
+}
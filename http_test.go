@@ -0,0 +1,161 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/7sDream/geko"
+)
+
+func httpBody(data string) io.Reader {
+	return httptest.NewRequest("POST", "/", strings.NewReader(data)).Body
+}
+
+func assertHTTPBodyError(t *testing.T, err error, kind geko.HTTPBodyErrorKind, offset int64) *geko.HTTPBodyError {
+	t.Helper()
+
+	var bodyErr *geko.HTTPBodyError
+	if !errors.As(err, &bodyErr) {
+		t.Fatalf("Decode should fail with HTTPBodyError, got %#v", err)
+	}
+
+	if bodyErr.Kind != kind || bodyErr.Offset != offset {
+		t.Fatalf("HTTPBodyError excepted %s at %d, got %s", kind, offset, err.Error())
+	}
+
+	return bodyErr
+}
+
+func TestDecodeHTTPBody_Limit(t *testing.T) {
+	data := `{"a": [1, 2], "b": "x"}`
+	limit := int64(len(data))
+
+	for _, body := range []io.Reader{
+		httpBody(data),
+		httpBody(data[:len(data)-1] + "}"),
+		iotest.OneByteReader(strings.NewReader(data)),
+		httpBody(" [1, 2] "),
+	} {
+		value, err := geko.DecodeHTTPBody(body, limit)
+		if err != nil {
+			t.Fatalf("Decode body within limit with error: %s", err.Error())
+		}
+
+		if _, ok := value.(geko.ObjectItems); !ok && value.(geko.Array).Len() != 2 {
+			t.Fatalf("Decode body result not correct: %#v", value)
+		}
+	}
+
+	for _, body := range []io.Reader{
+		httpBody(data + " "),
+		httpBody(`{"a": "` + strings.Repeat("x", 100) + `"}`),
+		iotest.OneByteReader(strings.NewReader(data + "\n")),
+	} {
+		_, err := geko.DecodeHTTPBody(body, limit)
+
+		bodyErr := assertHTTPBodyError(t, err, geko.BodyTooLarge, limit)
+
+		var limitErr *geko.LimitExceededError
+		if !errors.As(bodyErr, &limitErr) || limitErr.Kind != geko.BytesLimit || limitErr.Limit != limit {
+			t.Fatalf("Too large body should have a LimitExceededError: %#v", bodyErr.Err)
+		}
+	}
+
+	// no limit
+	if _, err := geko.DecodeHTTPBody(httpBody(data+strings.Repeat(" ", 100)), 0); err != nil {
+		t.Fatalf("Decode body without limit with error: %s", err.Error())
+	}
+}
+
+func TestDecodeHTTPBody_Malformed(t *testing.T) {
+	for _, c := range []struct {
+		data   string
+		offset int64
+	}{
+		{`{"a": 1,}`, 8},
+		{`{"a": 1} {}`, 10},
+		{`{"a": [`, 7},
+		{``, 0},
+	} {
+		_, err := geko.DecodeHTTPBody(httpBody(c.data), 1024, geko.AllowTrailingData(true))
+
+		bodyErr := assertHTTPBodyError(t, err, geko.BodyMalformed, c.offset)
+
+		var syntaxErr *json.SyntaxError
+		if !errors.As(bodyErr, &syntaxErr) {
+			t.Fatalf("Malformed body should have a syntax error: %#v", bodyErr.Err)
+		}
+	}
+
+	_, err := geko.DecodeHTTPBody(httpBody(`{"a": {"b": {}}}`), 1024, geko.MaxDepth(2))
+
+	bodyErr := assertHTTPBodyError(t, err, geko.BodyMalformed, 13)
+
+	var limitErr *geko.LimitExceededError
+	if !errors.As(bodyErr, &limitErr) || limitErr.Kind != geko.DepthLimit {
+		t.Fatalf("Body exceeds depth limit should have a depth limit error: %#v", bodyErr.Err)
+	}
+
+	_, err = geko.DecodeHTTPBody(httpBody(`{"a": 1, "a": 2}`), 1024, geko.DisallowDuplicateKeys(true))
+
+	bodyErr = assertHTTPBodyError(t, err, geko.BodyMalformed, 12)
+
+	var dupErr *geko.DuplicateKeyError
+	if !errors.As(bodyErr, &dupErr) {
+		t.Fatalf("Body with duplicated keys should have a DuplicateKeyError: %#v", bodyErr.Err)
+	}
+}
+
+func TestDecodeHTTPBody_ReadFailed(t *testing.T) {
+	body := io.MultiReader(strings.NewReader(`{"a": `), iotest.ErrReader(io.ErrClosedPipe))
+
+	_, err := geko.DecodeHTTPBody(body, 1024)
+
+	if bodyErr := assertHTTPBodyError(t, err, geko.BodyReadFailed, 4); !errors.Is(bodyErr, io.ErrClosedPipe) {
+		t.Fatalf("Read failed body should have the read error: %#v", bodyErr.Err)
+	}
+}
+
+func TestDecodeHTTPObject(t *testing.T) {
+	object, err := geko.DecodeHTTPObject(httpBody(`{"a": {"b": 1}, "a": 2, "c": 3}`), 1024)
+	if err != nil {
+		t.Fatalf("DecodeHTTPObject with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(object)
+	if string(output) != `{"a":2,"c":3}` {
+		t.Fatalf("DecodeHTTPObject result not correct: %s", string(output))
+	}
+
+	object, err = geko.DecodeHTTPObject(
+		httpBody(`{"a": {"b": 1}, "a": 2, "c": 3}`), 1024,
+		geko.UseObjectItems(), geko.ObjectOnDuplicatedKey(geko.Ignore),
+	)
+	if err != nil {
+		t.Fatalf("DecodeHTTPObject with error: %s", err.Error())
+	}
+
+	output, _ = json.Marshal(object)
+	if string(output) != `{"a":{"b":1},"c":3}` {
+		t.Fatalf("DecodeHTTPObject with UseObjectItems result not correct: %s", string(output))
+	}
+
+	for _, data := range []string{`[1]`, `null`, `"a"`} {
+		_, err = geko.DecodeHTTPObject(httpBody(data), 1024)
+
+		bodyErr := assertHTTPBodyError(t, err, geko.BodyWrongType, 0)
+
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(bodyErr, &typeErr) {
+			t.Fatalf("Wrong type body should have an UnmarshalTypeError: %#v", bodyErr.Err)
+		}
+	}
+
+	_, err = geko.DecodeHTTPObject(httpBody(`{"a": 1`), 1024)
+	assertHTTPBodyError(t, err, geko.BodyMalformed, 7)
+}
@@ -10,11 +10,16 @@
 //   - [Pairs], and it's type alias [ObjectItems], to replace map, when you need
 //     to keep all values of duplicated key.
 //   - [List], and it's type alias [Array] to replace slice.
+//   - [SyncMap], a [Map] which is safe for concurrent use.
+//   - [Set], a set of unique keys in insertion order, encoded as JSON array.
+//   - [MultiMap], an ordered map which can hold multiple values for a key.
 //   - [Any] type, to replace the interface{}, it will use types above to
 //     do JSON unmarshal.
 //
 // The [JSONUnmarshal] function is a shorthand for defined an [Any] and
-// unmarshal data into it.
+// unmarshal data into it. If your data comes from a stream, use [Decoder]
+// to decode it incrementally. [Encoder] is the mirror of it, and supports
+// indent and key sorting for all nested containers.
 //
 // # Example of JSON processing
 //
@@ -50,8 +55,9 @@
 //	object, _ := arr.Get(2).(geko.ObjectItems)
 //	object.GetFirstOrZeroValue("one") // => 1
 //
-// But you can't customize [DecodeOptions] when doing this, it will always use
-// default options.
+// When doing this, [Object] can only be customized with
+// [Map.SetDuplicatedKeyStrategy], and [Array] can be customized by
+// [List.SetDecodeOptions]. Otherwise, default options will be used.
 //
 // # Use container type directly
 //
@@ -76,22 +82,45 @@ import (
 	"encoding/json"
 	"io"
 	"reflect"
-	"unsafe"
+	"sort"
+	"strconv"
 )
 
 // DecodeOptions are options for controlling the behavior of [Any] unmarshaling.
 //
 // Zero value(default value) of it is:
 //
-//   - Do not use [json.Number] for JSON number
+//   - Do not use [json.Number] or int64 for JSON number, float64 is used.
 //   - Uses [ObjectItems] for JSON object.
+//   - No limit on nesting depth, item count and byte size.
+//   - A value which fails to decode fails the whole decoding.
 //
-// See also: [CreateDecodeOptions], [UseNumber], [UseObjectItems], [UseObject],
-// [ObjectOnDuplicatedKey].
+// See also: [CreateDecodeOptions], [UseNumber], [UseInt64], [UseBigNumber],
+// [NumberFunc], [UseObjectItems], [UseObject], [ObjectOnDuplicatedKey],
+// [RecordDuplicates], [DisallowDuplicateKeys], [UseRawValues], [KeyTransform],
+// [WithValueDecoder], [MaxDepth], [MaxItems], [MaxBytes], [SizeHint],
+// [AllowTrailingData], [AllowComments], [AllowTrailingCommas], [Lenient].
 type DecodeOptions struct {
 	useNumber             bool
+	useInt64              bool
+	useBigNumber          bool
+	numberFunc            func(literal string) (any, error)
 	useObject             bool
 	duplicatedKeyStrategy DuplicatedKeyStrategy
+	recordDuplicates      bool
+	disallowDuplicateKeys bool
+	useRawValues          bool
+	keyTransform          func(key string) string
+	valueDecoders         map[string]func(raw json.RawMessage) (any, error)
+	maxDepth              int
+	maxItems              int
+	maxBytes              int64
+	objectSizeHint        int
+	arraySizeHint         int
+	allowTrailingData     bool
+	allowComments         bool
+	allowTrailingCommas   bool
+	lenient               bool
 }
 
 // DecodeOption is atom/modifier of [DecodeOptions].
@@ -119,6 +148,54 @@ func UseNumber(v bool) DecodeOption {
 	}
 }
 
+// UseInt64 will enable or disable using int64 for JSON number which is an
+// integer, that is, has no fraction and exponent part, and fits in int64.
+//
+// Other numbers still use [json.Number] if [UseNumber] is enabled, or float64
+// if not. So when both options are enabled, UseInt64 takes precedence for
+// integers.
+func UseInt64(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.useInt64 = v
+	}
+}
+
+// UseBigNumber will enable or disable using [*big.Int] for JSON number which is
+// an integer, that is, has no fraction and exponent part, and [*big.Float] for
+// others.
+//
+// The precision of [*big.Float] is chosen to be enough to keep all digits in
+// the number literal. When encoding, it's written in plain decimal notation
+// if its absolute value is in [1e-6, 1e21), like float64, or in exponent
+// notation otherwise, so the value round-trips. Notice: this only works inside
+// types in this package, or with [Encoder]. The std lib encodes it as a string.
+//
+// Converting a [*big.Float] back into decimal takes time proportional to its
+// exponent, so numbers out of about 1e±616 fail with a
+// [json.UnmarshalTypeError], like numbers out of range of float64 do when this
+// option is not enabled.
+//
+// If [UseInt64] is also enabled, integers fit in int64 still use int64.
+// UseBigNumber takes precedence over [UseNumber].
+func UseBigNumber(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.useBigNumber = v
+	}
+}
+
+// NumberFunc sets a function to convert JSON number, it receives the raw number
+// literal, and the returned value will be stored as the decoded result. Set it
+// to nil to disable it.
+//
+// It takes precedence over all other number options: [UseNumber], [UseInt64]
+// and [UseBigNumber]. If it returns an error, decoding fails with a
+// [NumberFuncError] which wraps it.
+func NumberFunc(f func(literal string) (any, error)) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.numberFunc = f
+	}
+}
+
 // UseObject will change unmarshal behavior to using [Object] for JSON object.
 //
 // See also: [ObjectOnDuplicatedKey], [UseObjectItems].
@@ -148,99 +225,216 @@ func ObjectOnDuplicatedKey(strategy DuplicatedKeyStrategy) DecodeOption {
 	}
 }
 
-type decoder struct {
-	decoder *json.Decoder
-	opts    DecodeOptions
+// RecordDuplicates will make every [Object] created in decoding records pairs
+// discarded because of duplicated key. Only effect when [UseObject] is
+// applied.
+//
+// See [Map.SetRecordDuplicates] for detail.
+func RecordDuplicates(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.recordDuplicates = v
+	}
 }
 
-func newDecoder(data []byte, opts DecodeOptions) *decoder {
-	return &decoder{
-		decoder: json.NewDecoder(bytes.NewReader(data)),
-		opts:    opts,
+// DisallowDuplicateKeys will make decoding fail with a [DuplicateKeyError]
+// when a JSON object contains the same key more than once. It applies to both
+// [Map] and [Pairs], and to keys after [KeyTransform] is applied.
+func DisallowDuplicateKeys(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.disallowDuplicateKeys = v
 	}
 }
 
-func (d *decoder) decode() (any, error) {
-	if d.opts.useNumber {
-		d.decoder.UseNumber()
+// UseRawValues will change unmarshal behavior to keep values of JSON object
+// members as [json.RawMessage], instead of decoding them. So JSON object will
+// be decoded into a [*Pairs][string, [json.RawMessage]], or a
+// [*Map][string, [json.RawMessage]] if [UseObject] is applied.
+//
+// It's useful when you only need some of the values, the rest can be decoded
+// later on demand, without the cost of decoding all nested structures. Key
+// order and duplicated keys are handled as usual. Marshal of the result gives
+// the same output as original input, except for insignificant whitespace.
+//
+// Notice: JSON array is still decoded as usual, so objects in it are decoded
+// with raw values too.
+func UseRawValues() DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.useRawValues = true
 	}
+}
 
-	item, err := d.next()
-	if err != nil {
-		return nil, err
+// KeyTransform sets a function to transform every key of JSON object before it
+// is stored, at all nesting levels. Set it to nil to disable it.
+//
+// The stored keys are the transformed ones, so marshal output uses them too.
+// The transform runs before duplicated key detection, so if two keys are the
+// same after transform, they are considered duplicated, and will be processed
+// by the [DuplicatedKeyStrategy] when [UseObject] is applied.
+func KeyTransform(f func(key string) string) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.keyTransform = f
 	}
+}
 
-	if _, err := d.decoder.Token(); err != io.EOF {
-		return nil, newSyntaxError(
-			"invalid character after top-level value",
-			d.decoder.InputOffset(),
-		)
-	}
+// WithValueDecoder sets a function to decode values of JSON object member
+// whose key is key, at all nesting levels. The raw bytes of value are passed
+// to fn, and its return value is stored instead of the default decoded one.
+// Call it multiple times to set functions for different keys, a nil fn
+// removes the function of key.
+//
+// Keys are matched after [KeyTransform] is applied. It only effects objects
+// whose value type is any, so it has no effect with [UseRawValues].
+//
+// If fn fails, decoding stops with a [ValueDecoderError].
+func WithValueDecoder(key string, fn func(raw json.RawMessage) (any, error)) DecodeOption {
+	return func(opts *DecodeOptions) {
+		// copy on write, options may be shared
+		decoders := make(map[string]func(raw json.RawMessage) (any, error), len(opts.valueDecoders)+1)
+		for k, f := range opts.valueDecoders {
+			decoders[k] = f
+		}
+
+		if fn != nil {
+			decoders[key] = fn
+		} else {
+			delete(decoders, key)
+		}
 
-	return item, nil
+		opts.valueDecoders = decoders
+	}
 }
 
-// This is not "legal", but it seems there is no other way to set the msg of syntax error.
-func newSyntaxError(msg string, offset int64) *json.SyntaxError {
-	err := &json.SyntaxError{
-		Offset: offset,
+// MaxDepth limits the nesting depth of JSON object and array. A top-level
+// container has depth 1. If n <= 0, there is no limit, which is the default.
+//
+// When the depth exceeds the limit, decoding fails with a [LimitExceededError]
+// of [DepthLimit], whose offset points to the end of the container start that
+// exceeds it.
+//
+// Notice: only containers decoded by this package are counted. When decoding
+// into a container whose value type is concrete, like a Map[string, []any],
+// values are decoded by the std lib, which has its own depth limit.
+func MaxDepth(n int) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.maxDepth = n
 	}
+}
+
+// formatMaxDepth limits nesting of containers decoded from binary formats, like
+// CBOR and BSON, even if [MaxDepth] is not set, to avoid stack overflow on
+// malicious input, like the std lib JSON decoder does.
+const formatMaxDepth = 10000
 
-	msgField := reflect.ValueOf(err).Elem().Field(0 /* the msg field */)
-	if msgField.Kind() == reflect.String {
-		newMsgField := reflect.NewAt(msgField.Type(), unsafe.Pointer(msgField.UnsafeAddr())).Elem()
-		newMsgField.SetString(msg)
+// formatDepthLimit returns the nesting limit of containers decoded from binary
+// formats, which is [MaxDepth] if set, but never exceeds formatMaxDepth.
+func (opts *DecodeOptions) formatDepthLimit() int {
+	if opts.maxDepth > 0 && opts.maxDepth < formatMaxDepth {
+		return opts.maxDepth
 	}
+	return formatMaxDepth
+}
 
-	return err
+// MaxItems limits the total count of object members and array elements in a
+// top-level JSON value. If n <= 0, there is no limit, which is the default.
+//
+// The count is cumulative, items in all nested containers are counted
+// together, and members with duplicated key are counted even if they are
+// deduplicated when using [UseObject]. When decoding a stream with [Decoder],
+// the count restarts for each top-level value.
+//
+// When the limit exceeded, decoding fails with a [LimitExceededError].
+//
+// Notice: like [MaxDepth], only items decoded by this package are counted.
+func MaxItems(n int) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.maxItems = n
+	}
 }
 
-func (d *decoder) next() (any, error) {
-	var token json.Token
-	var err error
+// MaxBytes limits the byte size of a top-level JSON value. If n <= 0, there is
+// no limit, which is the default. When decoding a stream with [Decoder], the
+// size is counted for each top-level value separately, whitespace before the
+// value included.
+//
+// The size is checked every time a token is read, and decoding fails with a
+// [LimitExceededError] once it's exceeded, so no more containers will be
+// built. But a single huge token, like a long string, is still read
+// completely before the check. Wrap the input with [io.LimitReader] if you
+// need a hard limit on raw input.
+func MaxBytes(n int64) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.maxBytes = n
+	}
+}
 
-	if token, err = d.decoder.Token(); err != nil {
-		return nil, err
+// SizeHint sets the initial capacity of the top-level JSON object and array
+// created in decoding, to avoid re-allocations when decoding a large one.
+// Non-positive value means no hint, which is the default.
+//
+// Nested containers are not affected, they grow as usual.
+func SizeHint(objectHint, arrayHint int) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.objectSizeHint = objectHint
+		opts.arraySizeHint = arrayHint
 	}
+}
 
-	return d.nextAfterToken(token)
+// AllowTrailingData will enable or disable allowing data after the top-level
+// JSON value. If enabled, [JSONUnmarshal] stops after the first complete value,
+// and ignores anything after it. Use [JSONUnmarshalPrefix] if you want to know
+// where the value ends.
+//
+// Notice: it has no effect on [json.Unmarshal], which always validates the
+// whole input before calling [Any.UnmarshalJSON].
+func AllowTrailingData(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.allowTrailingData = v
+	}
 }
 
-func (d *decoder) nextAfterToken(token json.Token) (any, error) {
-	var value any
+// AllowComments will enable or disable allowing comments in input, both line
+// comment "// ..." and block comment "/* ... */" are supported, like JSONC.
+//
+// Comments are simply dropped, so they will not be kept in decoded result.
+// Offsets in errors are still based on the original input.
+//
+// Notice: like [AllowTrailingData], it has no effect on [json.Unmarshal], use
+// [JSONUnmarshal] or [Decoder] instead.
+func AllowComments(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.allowComments = v
+	}
+}
 
-	switch v := token.(type) {
-	case bool, float64, json.Number, string, nil:
-		value = v
-	case json.Delim:
-		switch v {
-		case '{':
-			{
-				var object jsonObject[string, any]
-				if d.opts.useObject {
-					m := NewMap[string, any]()
-					m.SetDuplicatedKeyStrategy(d.opts.duplicatedKeyStrategy)
-					object = m
-				} else {
-					object = NewPairs[string, any]()
-				}
-				if err := parseIntoObject[string, any](d, object, true); err != nil {
-					return nil, err
-				}
-				value = object
-			}
-		case '[':
-			{
-				l := NewList[any]()
-				if err := parseIntoArray[any](d, l); err != nil {
-					return nil, err
-				}
-				value = l
-			}
-		}
+// AllowTrailingCommas will enable or disable allowing a comma after the last
+// item of JSON object and array, like JSONC.
+//
+// Notice: like [AllowTrailingData], it has no effect on [json.Unmarshal], use
+// [JSONUnmarshal] or [Decoder] instead.
+func AllowTrailingCommas(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.allowTrailingCommas = v
 	}
+}
 
-	return value, nil
+// Lenient will enable or disable lenient decoding, which skips values that
+// fail to decode, instead of failing the whole decoding.
+//
+// It only takes effect on members of a [Map] or [Pairs], and items of a
+// [List], whose value type is not any, like Map[string, int]. A value which
+// does not match the type, or whose own UnmarshalJSON method fails, is not
+// added into the container. After all values are decoded, a
+// [MultiDecodeError] is returned if any is skipped, and the container is
+// still populated with the others.
+//
+// Syntax errors and errors like [LimitExceededError] still stop decoding
+// immediately.
+//
+// See also: [Map.SetLenient], [Pairs.SetLenient], [List.SetDecodeOptions].
+func Lenient(v bool) DecodeOption {
+	return func(opts *DecodeOptions) {
+		opts.lenient = v
+	}
 }
 
 // Array
@@ -249,26 +443,147 @@ type jsonArray[T any] interface {
 	innerSlice() *[]T
 }
 
-func marshalArray[T any, A jsonArray[T]](array A) ([]byte, error) {
+// flushEvery is the number of items [List.WriteJSON] writes between two
+// flushes of a buffered writer.
+const flushEvery = 1024
+
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
+func encodeArray[T any, A jsonArray[T]](e *encodeState, array A, nilAsNull bool) error {
 	slice := *array.innerSlice()
-	if slice == nil {
-		return []byte(`[]`), nil
+
+	if slice == nil && nilAsNull {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+
+	// std lib encodes a byte slice as a base64 string
+	if slice != nil && isByteSlice[T]() {
+		return e.value(slice)
+	}
+
+	if len(slice) == 0 {
+		_, _ = e.WriteString("[]")
+		return nil
 	}
 
-	var data bytes.Buffer
-	enc := json.NewEncoder(&data)
-	enc.SetEscapeHTML(false)
-	err := enc.Encode(slice)
-	return data.Bytes(), err
+	_ = e.WriteByte('[')
+	e.depth++
+
+	byAddress := marshalByAddress[T]()
+
+	for i := range slice {
+		if i > 0 {
+			_ = e.WriteByte(',')
+		}
+
+		e.newline()
+
+		if err := encodeArrayItem(e, &slice[i], byAddress); err != nil {
+			return withMarshalPath(err, i)
+		}
+	}
+
+	e.depth--
+	e.newline()
+	_ = e.WriteByte(']')
+
+	return nil
 }
 
-func parseIntoArray[T any, A jsonArray[T]](d *decoder, array A) error {
-	// The behavior of the standard library is to clear the list
-	// and we are consistent with it
-	*array.innerSlice() = nil
+func writeArray[T any, A jsonArray[T]](w io.Writer, array A, nilAsNull bool) error {
+	slice := *array.innerSlice()
+
+	if slice == nil && nilAsNull {
+		if _, err := io.WriteString(w, "null"); err != nil {
+			return err
+		}
+		return flush(w)
+	}
+
+	// Encode items one by one into a small reused buffer, so memory usage
+	// does not grow with the size of the list.
+	e := acquireEncodeState(EncodeOptions{})
+	defer releaseEncodeState(e)
+
+	// std lib encodes a byte slice as a base64 string, it's written as a whole
+	if slice != nil && isByteSlice[T]() {
+		_ = e.value(slice) // encoding a byte slice never fails
+		if _, err := w.Write(e.Bytes()); err != nil {
+			return err
+		}
+		return flush(w)
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	byAddress := marshalByAddress[T]()
+
+	for i := range slice {
+		e.Reset()
+		if i > 0 {
+			_ = e.WriteByte(',')
+		}
+
+		if err := encodeArrayItem(e, &slice[i], byAddress); err != nil {
+			return withMarshalPath(err, i)
+		}
+
+		if _, err := w.Write(e.Bytes()); err != nil {
+			return err
+		}
+
+		if (i+1)%flushEvery == 0 {
+			if err := flush(w); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, "]"); err != nil {
+		return err
+	}
+
+	return flush(w)
+}
+
+// encodeArrayItem encodes an item of a []T, by its address if byAddress, so
+// marshal methods with pointer receiver are called like std lib does.
+func encodeArrayItem[T any](e *encodeState, item *T, byAddress bool) error {
+	if byAddress {
+		return e.stdValue(item)
+	}
+
+	return e.value(*item)
+}
+
+func marshalArray[T any, A jsonArray[T]](array A, nilAsNull bool) ([]byte, error) {
+	e := acquireEncodeState(EncodeOptions{})
+	defer releaseEncodeState(e)
+
+	if err := encodeArray[T](e, array, nilAsNull); err != nil {
+		return nil, err
+	}
+	return e.copyBytes(), nil
+}
+
+func parseIntoArray[T any, A jsonArray[T]](d *Decoder, array A) error {
+	if !isEmptyInterface[T]() {
+		return parseIntoTypedArray[T](d, array)
+	}
 
 	for {
-		token, err := d.decoder.Token()
+		token, err := d.token()
 		if err != nil {
 			return err
 		}
@@ -279,27 +594,84 @@ func parseIntoArray[T any, A jsonArray[T]](d *decoder, array A) error {
 			return nil
 		}
 
+		if err = d.addItem(); err != nil {
+			return err
+		}
+
 		var value T
 
 		v, err := d.nextAfterToken(token)
 		if err != nil {
 			return err
+		} else if v != nil {
+			value, _ = v.(T) // never fails because we have checked T is any too
 		}
 
-		value, _ = v.(T) // never fails because we have checked T is any too
+		*array.innerSlice() = append(*array.innerSlice(), value)
+	}
+}
+
+// parseIntoTypedArray parses items into array whose T is a real type, by std
+// lib, so failed ones can be skipped in lenient mode.
+func parseIntoTypedArray[T any, A jsonArray[T]](d *Decoder, array A) error {
+	var skipped []*DecodeValueError
+
+	for index := 0; d.decoder.More(); index++ {
+		if err := d.addItem(); err != nil {
+			return err
+		}
+
+		var value T
+
+		if err := d.decodeStd(&value); err != nil {
+			if skipped, err = d.skipValue(skipped, "/"+strconv.Itoa(index), err); err != nil {
+				return err
+			}
+			continue
+		}
 
 		*array.innerSlice() = append(*array.innerSlice(), value)
 	}
+
+	// consume the ]
+	if _, err := d.token(); err != nil {
+		return err
+	}
+
+	return skippedError(skipped)
 }
 
-func unmarshalArray[T any, A jsonArray[T]](data []byte, array A, option ...DecodeOption) error {
-	if !isEmptyInterface[T]() {
-		return json.Unmarshal(data, array.innerSlice())
+func unmarshalArray[T any, A jsonArray[T]](
+	data []byte, array A, opts DecodeOptions, appendMode bool,
+) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		if !appendMode {
+			*array.innerSlice() = nil
+		}
+		return nil
+	}
+
+	// numbers in values of T are converted by the decoder, see decodeStd
+	convertsNumber := opts.convertsNumber() && containsAny(reflect.TypeOf((*T)(nil)).Elem())
+
+	if !isEmptyInterface[T]() && !opts.lenient && !convertsNumber {
+		if !appendMode {
+			return json.Unmarshal(data, array.innerSlice())
+		}
+
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+
+		*array.innerSlice() = append(*array.innerSlice(), items...)
+		return nil
 	}
 
-	d := newDecoder(data, CreateDecodeOptions(option...))
+	d := acquireDecoder(data, opts)
+	defer releaseDecoder(d)
 
-	token, err := d.decoder.Token()
+	token, err := d.token()
 	if err != nil {
 		return err
 	}
@@ -311,62 +683,120 @@ func unmarshalArray[T any, A jsonArray[T]](data []byte, array A, option ...Decod
 		}
 	}
 
+	// depth of top-level container is 1, it never exceeds a valid limit
+	_ = d.enter()
+
+	// The behavior of the standard library is to clear the list
+	// and we are consistent with it, unless in append mode.
+	if !appendMode {
+		*array.innerSlice() = nil
+	}
+
 	return parseIntoArray[T](d, array)
 }
 
 // Object
 
-type jsonObject[K comparable, V any] interface {
-	GetByIndex(int) Pair[K, V]
-	Add(K, V)
-	Len() int
-}
-
-func marshalObject[K comparable, V any, O jsonObject[K, V]](object O) ([]byte, error) {
+func encodeObject[K comparable, V any, O OrderedCollection[K, V]](e *encodeState, object O) error {
 	if !isString[K]() {
-		return nil, &json.UnsupportedTypeError{
+		return &json.UnsupportedTypeError{
 			Type: reflect.TypeOf(object),
 		}
 	}
 
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	enc.SetEscapeHTML(false)
+	length := object.Len()
+	if length == 0 {
+		_, _ = e.WriteString("{}")
+		return nil
+	}
 
-	_ = buf.WriteByte('{')
+	order := make([]int, length)
+	keys := make([]string, length)
+	for i := range order {
+		order[i] = i
+		keys[i] = any(object.GetKeyByIndex(i)).(string)
+		if e.opts.keyTransform != nil {
+			keys[i] = e.opts.keyTransform(keys[i])
+		}
+	}
 
-	for i, length := 0, object.Len(); i < length; i++ {
-		if i > 0 {
-			_ = buf.WriteByte(',')
+	if e.opts.sortKeys {
+		sort.SliceStable(order, func(i, j int) bool {
+			return keys[order[i]] < keys[order[j]]
+		})
+	}
+
+	_ = e.WriteByte('{')
+	e.depth++
+
+	written := 0
+	for _, index := range order {
+		memberStart := e.Len()
+
+		if written > 0 {
+			_ = e.WriteByte(',')
 		}
 
-		pair := object.GetByIndex(i)
+		e.newline()
+
+		e.key(keys[index])
 
-		// Key is string type, encoding never fail
-		_ = enc.Encode(pair.Key)
+		e.colon()
 
-		_ = buf.WriteByte(':')
+		valueStart := e.Len()
+		if err := e.value(object.GetByIndex(index).Value); err != nil {
+			return withMarshalPath(err, any(object.GetKeyByIndex(index)))
+		}
 
-		if err := enc.Encode(pair.Value); err != nil {
-			return nil, err
+		if e.droppable(valueStart) {
+			e.Truncate(memberStart)
+			continue
 		}
+
+		written++
 	}
 
-	_ = buf.WriteByte('}')
+	e.depth--
+	if written > 0 {
+		e.newline()
+	}
+	_ = e.WriteByte('}')
 
-	return buf.Bytes(), nil
+	return nil
+}
+
+func marshalObject[K comparable, V any, O OrderedCollection[K, V]](object O) ([]byte, error) {
+	e := acquireEncodeState(EncodeOptions{})
+	defer releaseEncodeState(e)
+
+	if err := encodeObject[K, V](e, object); err != nil {
+		return nil, err
+	}
+	return e.copyBytes(), nil
 }
 
-func parseIntoObject[K comparable, V any, O jsonObject[K, V]](
-	d *decoder, object O, valueIsAny bool,
+func parseIntoObject[K comparable, V any, O OrderedMutable[K, V]](
+	d *Decoder, object O, valueIsAny bool,
 ) error {
 	// The behavior of the standard library is **do not** clear the map
 	// and we are consistent with it.
 
 	valueIsAny = valueIsAny || isEmptyInterface[V]()
 
-	for {
-		token, err := d.decoder.Token()
+	// Resolve the string keyed version once, so keys do not need to be
+	// converted into K one by one. Never fails because callers have checked K
+	// is string.
+	stringKeyObject, _ := any(object).(OrderedMutable[string, V])
+
+	var skipped []*DecodeValueError
+
+	var seen map[string]struct{}
+	if d.opts.disallowDuplicateKeys {
+		seen = make(map[string]struct{})
+	}
+
+	for index := 0; ; index++ {
+		token, err := d.token()
 		if err != nil {
 			return err
 		}
@@ -374,33 +804,57 @@ func parseIntoObject[K comparable, V any, O jsonObject[K, V]](
 		// if meet }, the object parse ends
 		delim, ok := token.(json.Delim)
 		if ok && delim == '}' {
-			return nil
+			return skippedError(skipped)
 		}
 
 		// otherwise, we meet the key of a item
 		key, _ := token.(string)
 
+		if d.opts.keyTransform != nil {
+			key = d.opts.keyTransform(key)
+		}
+
+		if seen != nil {
+			if _, dup := seen[key]; dup {
+				return &DuplicateKeyError{Key: key, Index: index, Offset: d.decoder.InputOffset()}
+			}
+			seen[key] = struct{}{}
+		}
+
+		if err = d.addItem(); err != nil {
+			return err
+		}
+
 		var value V
 
 		if valueIsAny { // if v is any, we parse it into our json value types
 			var v any
 
-			if v, err = d.next(); err != nil {
+			if fn := d.opts.valueDecoders[key]; fn != nil {
+				v, err = d.decodeWith(key, fn)
+			} else {
+				v, err = d.next()
+			}
+
+			if err != nil {
 				return err
 			} else if v != nil {
 				value, _ = v.(V) // never fails because we have checked type V is any
 			}
 		} else { // otherwise V is a real type, we can let std lib parsing it for us
-			if err = d.decoder.Decode(&value); err != nil {
-				return err
+			if err = d.decodeStd(&value); err != nil {
+				if skipped, err = d.skipValue(skipped, "/"+escapePointerToken(key), err); err != nil {
+					return err
+				}
+				continue
 			}
 		}
 
-		object.Add(any(key).(K), value)
+		stringKeyObject.Add(key, value)
 	}
 }
 
-func unmarshalObject[K comparable, V any, O jsonObject[K, V]](
+func unmarshalObject[K comparable, V any, O OrderedMutable[K, V]](
 	data []byte, object O, option ...DecodeOption,
 ) error {
 	if !isString[K]() {
@@ -410,9 +864,10 @@ func unmarshalObject[K comparable, V any, O jsonObject[K, V]](
 		}
 	}
 
-	d := newDecoder(data, CreateDecodeOptions(option...))
+	d := acquireDecoder(data, CreateDecodeOptions(option...))
+	defer releaseDecoder(d)
 
-	token, err := d.decoder.Token()
+	token, err := d.token()
 	if err != nil {
 		return err
 	}
@@ -424,5 +879,8 @@ func unmarshalObject[K comparable, V any, O jsonObject[K, V]](
 		}
 	}
 
+	// depth of top-level container is 1, it never exceeds a valid limit
+	_ = d.enter()
+
 	return parseIntoObject[K, V](d, object, false)
 }
@@ -0,0 +1,173 @@
+package geko
+
+import "io"
+
+type jsoncState uint8
+
+const (
+	jsoncNormal jsoncState = iota
+	jsoncString
+	jsoncStringEscape
+	jsoncSlash
+	jsoncLineComment
+	jsoncBlockComment
+	jsoncBlockCommentStar
+)
+
+// jsoncReader filters JSONC input into valid JSON, by replacing comments and
+// trailing commas with spaces. Newlines in comments are kept, so byte offsets
+// and line numbers are the same as the original input.
+type jsoncReader struct {
+	r io.Reader
+
+	comments       bool
+	trailingCommas bool
+
+	state jsoncState
+	// last is the last significant byte written
+	last byte
+	// held is output after a comma, which is held back until we meet the
+	// next significant character, to know if the comma is trailing.
+	held []byte
+	out  []byte
+	buf  []byte
+	err  error
+}
+
+func newJSONCReader(r io.Reader, comments, trailingCommas bool) *jsoncReader {
+	return &jsoncReader{
+		r:              r,
+		comments:       comments,
+		trailingCommas: trailingCommas,
+		buf:            make([]byte, 4096),
+	}
+}
+
+func (r *jsoncReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 && r.err == nil {
+		n, err := r.r.Read(r.buf)
+		for _, c := range r.buf[:n] {
+			r.step(c)
+		}
+
+		if err != nil {
+			r.err = err
+			r.finish()
+		}
+	}
+
+	if len(r.out) > 0 {
+		n := copy(p, r.out)
+		r.out = r.out[n:]
+		return n, nil
+	}
+
+	return 0, r.err
+}
+
+// emit writes a non-significant byte.
+func (r *jsoncReader) emit(c byte) {
+	if r.held != nil {
+		r.held = append(r.held, c)
+	} else {
+		r.out = append(r.out, c)
+	}
+}
+
+// significant writes a significant byte, and release held output.
+func (r *jsoncReader) significant(c byte) {
+	if r.held != nil {
+		if c == ']' || c == '}' {
+			r.held[0] = ' ' // the held comma is a trailing one
+		}
+		r.out = append(r.out, r.held...)
+		r.held = nil
+	}
+	r.out = append(r.out, c)
+	r.last = c
+}
+
+// blank writes a space to replace c, but keeps newline.
+func (r *jsoncReader) blank(c byte) {
+	if c == '\n' {
+		r.emit('\n')
+	} else {
+		r.emit(' ')
+	}
+}
+
+func (r *jsoncReader) finish() {
+	if r.state == jsoncSlash {
+		r.significant('/')
+	}
+	r.out = append(r.out, r.held...)
+	r.held = nil
+}
+
+func (r *jsoncReader) step(c byte) {
+	switch r.state {
+	case jsoncString:
+		r.emit(c)
+		if c == '\\' {
+			r.state = jsoncStringEscape
+		} else if c == '"' {
+			r.state = jsoncNormal
+		}
+	case jsoncStringEscape:
+		r.emit(c)
+		r.state = jsoncString
+	case jsoncSlash:
+		switch c {
+		case '/':
+			r.emit(' ')
+			r.emit(' ')
+			r.state = jsoncLineComment
+		case '*':
+			r.emit(' ')
+			r.emit(' ')
+			r.state = jsoncBlockComment
+		default:
+			// not a comment, let json decoder report it
+			r.significant('/')
+			r.state = jsoncNormal
+			r.step(c)
+		}
+	case jsoncLineComment:
+		r.blank(c)
+		if c == '\n' {
+			r.state = jsoncNormal
+		}
+	case jsoncBlockComment:
+		r.blank(c)
+		if c == '*' {
+			r.state = jsoncBlockCommentStar
+		}
+	case jsoncBlockCommentStar:
+		r.blank(c)
+		if c == '/' {
+			r.state = jsoncNormal
+		} else if c != '*' {
+			r.state = jsoncBlockComment
+		}
+	default:
+		r.stepNormal(c)
+	}
+}
+
+func (r *jsoncReader) stepNormal(c byte) {
+	switch {
+	case isSpace(c):
+		r.emit(c)
+	case c == '/' && r.comments:
+		r.state = jsoncSlash
+	case c == ',' && r.trailingCommas && r.last != ',' && r.last != '[' && r.last != '{':
+		// the comma follows a value, it may be a trailing one
+		r.held = []byte{','}
+		r.last = ','
+	default:
+		r.significant(c)
+		if c == '"' {
+			r.state = jsoncString
+		}
+	}
+}
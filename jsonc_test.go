@@ -0,0 +1,132 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/7sDream/geko"
+)
+
+const jsoncConfig = `// tsconfig-like file
+{
+	/* compiler options */
+	"compilerOptions": {
+		"target": "es2020", // trailing comment
+		"paths": {
+			"@/*": ["src/*",], /* a block comment
+			with "quote", // and *star* */
+		},
+		"url": "http://example.com/*not comment*/",
+		"escaped": "quote \" // still string",
+	},
+	"include": [
+		"src", // trailing comma with comment after
+		/**/
+	],
+	"empty": [/* nothing */],
+}
+`
+
+func jsoncDecodeBoth(t *testing.T, data string, option ...geko.DecodeOption) (any, error) {
+	t.Helper()
+
+	result, err := geko.JSONUnmarshal([]byte(data), option...)
+
+	// decode again with one byte per read, to check state across reads
+	d := geko.NewDecoder(iotest.OneByteReader(strings.NewReader(data)), option...)
+	result2, err2 := d.Decode()
+	if err2 == nil {
+		if _, errEOF := d.Decode(); !errors.Is(errEOF, io.EOF) {
+			result2, err2 = nil, errEOF
+		}
+	}
+
+	output, _ := json.Marshal(result)
+	output2, _ := json.Marshal(result2)
+	if (err == nil) != (err2 == nil) || string(output) != string(output2) {
+		t.Fatalf("Decode with one byte reader result not same: %s, %s", string(output), string(output2))
+	}
+
+	return result, err
+}
+
+func TestJSONUnmarshal_JSONC(t *testing.T) {
+	result, err := jsoncDecodeBoth(
+		t, jsoncConfig,
+		geko.AllowComments(true), geko.AllowTrailingCommas(true), geko.UseObject(),
+	)
+	if err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	excepted := `{"compilerOptions":{"target":"es2020","paths":{"@/*":["src/*"]},` +
+		`"url":"http://example.com/*not comment*/","escaped":"quote \" // still string"},` +
+		`"include":["src"],"empty":[]}`
+
+	if string(output) != excepted {
+		t.Fatalf("Unmarshal result not correct: %s", string(output))
+	}
+
+	if keys := result.(geko.Object).Keys(); strings.Join(keys, ",") != "compilerOptions,include,empty" {
+		t.Fatalf("Key order not kept: %#v", keys)
+	}
+}
+
+func TestJSONUnmarshal_JSONC_Disabled(t *testing.T) {
+	invalid := func(data string, option ...geko.DecodeOption) {
+		if _, err := jsoncDecodeBoth(t, data, option...); err == nil {
+			t.Fatalf("Unmarshal %q should report error", data)
+		}
+	}
+
+	invalid(jsoncConfig)
+	invalid(jsoncConfig, geko.AllowComments(true))
+	invalid(jsoncConfig, geko.AllowTrailingCommas(true))
+	invalid(`[1,,]`, geko.AllowTrailingCommas(true))
+	invalid(`[,]`, geko.AllowTrailingCommas(true))
+	invalid(`[1, /]`, geko.AllowComments(true))
+	invalid(`[1] /`, geko.AllowComments(true))
+	invalid(`[1 /* unterminated`, geko.AllowComments(true))
+}
+
+func TestJSONUnmarshal_JSONC_Valid(t *testing.T) {
+	valid := func(data string, excepted string) {
+		result, err := jsoncDecodeBoth(t, data, geko.AllowComments(true), geko.AllowTrailingCommas(true))
+		if err != nil {
+			t.Fatalf("Unmarshal %q error: %s", data, err.Error())
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != excepted {
+			t.Fatalf("Unmarshal %q result not correct: %s", data, string(output))
+		}
+	}
+
+	valid(`[1, 2 /***/, /** / **/ ]`, `[1,2]`)
+	valid("1 // comment at end", `1`)
+	valid(`{"a": "\\", "b": [1,],}`, `{"a":"\\","b":[1]}`)
+	valid("[1,\n// comment\n\n]", `[1]`)
+}
+
+func TestJSONUnmarshal_JSONC_ErrorOffset(t *testing.T) {
+	data := "{\n  // comment\n  \"a\": 1,\n  \"b\": x\n}"
+
+	_, err := geko.JSONUnmarshal([]byte(data), geko.AllowComments(true))
+
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Unmarshal should fail with syntax error, got %#v", err)
+	}
+
+	_, errStd := geko.JSONUnmarshal([]byte(strings.Replace(data, "// comment", "          ", 1)))
+
+	var syntaxErrStd *json.SyntaxError
+	if !errors.As(errStd, &syntaxErrStd) || syntaxErr.Offset != syntaxErrStd.Offset {
+		t.Fatalf("Error offset %d not same as input without comment: %#v", syntaxErr.Offset, errStd)
+	}
+}
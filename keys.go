@@ -0,0 +1,120 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingKeys means some required keys do not exist, it's returned by
+// [Map.RequireKeys] and [Pairs.RequireKeys].
+var ErrMissingKeys = errors.New("geko: missing keys")
+
+// HasKeys checks if all keys exist in the map. It stops at the first key
+// which does not exist.
+func (m *Map[K, V]) HasKeys(keys ...K) bool {
+	for _, key := range keys {
+		if !m.Has(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// MissingKeys returns keys which do not exist in the map, in order of keys.
+// It returns nil if all keys exist.
+func (m *Map[K, V]) MissingKeys(keys ...K) []K {
+	return missingKeys(m.Has, keys)
+}
+
+// RequireKeys returns an error naming all keys which do not exist in the map,
+// in order of keys, or nil if all keys exist. The error wraps
+// [ErrMissingKeys].
+func (m *Map[K, V]) RequireKeys(keys ...K) error {
+	return missingKeysError(m.MissingKeys(keys...))
+}
+
+// GetMany gets values of keys which exist in the map, in order of keys, and
+// also returns keys which do not exist, in order of keys. A key requested
+// multiple times appears in result multiple times.
+//
+// Both results are nil if there is no such value or key.
+func (m *Map[K, V]) GetMany(keys ...K) (values []V, missing []K) {
+	for _, key := range keys {
+		if value, exist := m.Get(key); exist {
+			values = append(values, value)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return
+}
+
+// GetManyOrZero gets values of keys, a key which does not exist results a zero
+// value of type V, so the result aligns with keys.
+func (m *Map[K, V]) GetManyOrZero(keys ...K) []V {
+	values := make([]V, len(keys))
+	for i, key := range keys {
+		values[i] = m.GetOrZeroValue(key)
+	}
+	return values
+}
+
+// HasKeys checks if all keys appear at least once in the list. It stops at
+// the first key which does not appear.
+//
+// Performance: O(n) for each key.
+func (ps *Pairs[K, V]) HasKeys(keys ...K) bool {
+	for _, key := range keys {
+		if !ps.Has(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// MissingKeys returns keys which do not appear in the list, in order of keys.
+// It returns nil if all keys appear.
+//
+// Performance: O(n + len(keys)).
+func (ps *Pairs[K, V]) MissingKeys(keys ...K) []K {
+	present := make(map[K]struct{}, ps.Len())
+	for i := range ps.List {
+		present[ps.List[i].Key] = struct{}{}
+	}
+
+	return missingKeys(func(key K) bool {
+		_, exist := present[key]
+		return exist
+	}, keys)
+}
+
+// RequireKeys returns an error naming all keys which do not appear in the
+// list, in order of keys, or nil if all keys appear. The error wraps
+// [ErrMissingKeys].
+func (ps *Pairs[K, V]) RequireKeys(keys ...K) error {
+	return missingKeysError(ps.MissingKeys(keys...))
+}
+
+func missingKeys[K comparable](has func(key K) bool, keys []K) []K {
+	var missing []K
+	for _, key := range keys {
+		if !has(key) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+func missingKeysError[K comparable](missing []K) error {
+	if len(missing) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(missing))
+	for _, key := range missing {
+		names = append(names, fmt.Sprintf("%#v", key))
+	}
+
+	return fmt.Errorf("%w: %s", ErrMissingKeys, strings.Join(names, ", "))
+}
@@ -0,0 +1,87 @@
+package geko_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestMap_Keys_Required(t *testing.T) {
+	m := geko.NewMapOf[string, any](geko.P[string, any]("a", 1), geko.P[string, any]("b", nil))
+
+	if !m.HasKeys() || !m.HasKeys("b", "a") || m.HasKeys("a", "c") {
+		t.Fatalf("HasKeys result not correct")
+	}
+
+	if missing := m.MissingKeys("a", "b"); missing != nil {
+		t.Fatalf("MissingKeys should be nil if all keys exist: %v", missing)
+	}
+
+	missing := m.MissingKeys("d", "a", "c")
+	if !reflect.DeepEqual(missing, []string{"d", "c"}) {
+		t.Fatalf("MissingKeys result not correct: %v", missing)
+	}
+
+	if err := m.RequireKeys("a", "b"); err != nil {
+		t.Fatalf("RequireKeys should success: %s", err.Error())
+	}
+
+	err := m.RequireKeys("d", "a", "c")
+	if !errors.Is(err, geko.ErrMissingKeys) || err.Error() != `geko: missing keys: "d", "c"` {
+		t.Fatalf("RequireKeys error not correct: %v", err)
+	}
+}
+
+func TestMap_GetMany(t *testing.T) {
+	m := geko.NewMapOf(geko.P("a", 1), geko.P("b", 2), geko.P("c", 3))
+
+	for _, c := range []struct {
+		keys            []string
+		exceptedValues  []int
+		exceptedMissing []string
+		exceptedOrZero  []int
+	}{
+		{[]string{"c", "x", "a", "y"}, []int{3, 1}, []string{"x", "y"}, []int{3, 0, 1, 0}},
+		{[]string{"b", "x", "b", "x"}, []int{2, 2}, []string{"x", "x"}, []int{2, 0, 2, 0}},
+		{[]string{"a", "b"}, []int{1, 2}, nil, []int{1, 2}},
+		{[]string{"x"}, nil, []string{"x"}, []int{0}},
+		{nil, nil, nil, []int{}},
+	} {
+		values, missing := m.GetMany(c.keys...)
+		if !reflect.DeepEqual(values, c.exceptedValues) || !reflect.DeepEqual(missing, c.exceptedMissing) {
+			t.Fatalf("GetMany %v result not correct: %v, %v", c.keys, values, missing)
+		}
+
+		if values = m.GetManyOrZero(c.keys...); !reflect.DeepEqual(values, c.exceptedOrZero) {
+			t.Fatalf("GetManyOrZero %v result not correct: %v", c.keys, values)
+		}
+	}
+}
+
+func TestPairs_Keys_Required(t *testing.T) {
+	ps := geko.NewPairsOf(geko.P(1, "a"), geko.P(2, "b"), geko.P(1, "c"))
+
+	if !ps.HasKeys() || !ps.HasKeys(2, 1) || ps.HasKeys(1, 3) {
+		t.Fatalf("HasKeys result not correct")
+	}
+
+	if missing := ps.MissingKeys(1, 2, 1); missing != nil {
+		t.Fatalf("MissingKeys should be nil if all keys appear: %v", missing)
+	}
+
+	missing := ps.MissingKeys(4, 1, 3)
+	if !reflect.DeepEqual(missing, []int{4, 3}) {
+		t.Fatalf("MissingKeys result not correct: %v", missing)
+	}
+
+	if err := ps.RequireKeys(1); err != nil {
+		t.Fatalf("RequireKeys should success: %s", err.Error())
+	}
+
+	err := ps.RequireKeys(4, 1, 3)
+	if !errors.Is(err, geko.ErrMissingKeys) || err.Error() != `geko: missing keys: 4, 3` {
+		t.Fatalf("RequireKeys error not correct: %v", err)
+	}
+}
@@ -1,13 +1,29 @@
 package geko
 
+import "io"
+
 // List is wrapper type of a normal slice.
 //
 // If T is any, will use [ObjectItems] from this package to store JSON object,
 // use [Array] to store JSON array, instead of normal map[string]any and []any.
 //
 // If T is a concrete type, the behavior is same as a normal slice.
+//
+// You can use [List.SetDecodeOptions] before call [json.Unmarshal] to control
+// how JSON values nested in the array are decoded.
 type List[T any] struct {
 	List []T
+
+	decodeOptions     DecodeOptions
+	appendOnUnmarshal bool
+	marshalNilAsNull  bool
+	frozen            bool
+	// mods counts structural modifications, see [List.Range]
+	mods uint
+	// buffer is the backing array of List, with head free slots before it,
+	// used by [List.PushFront], see deque.go
+	buffer []T
+	head   int
 }
 
 // Array is a [List] whose type parameters are specialized as any, used to
@@ -26,12 +42,74 @@ func NewListFrom[T any](list []T) *List[T] {
 	}
 }
 
+// NewListOf create a List contains a copy of items.
+//
+//	geko.NewListOf[any](1, "two", geko.NewMapOf(geko.P("three", 3)))
+func NewListOf[T any](items ...T) *List[T] {
+	return NewListFrom(append([]T(nil), items...))
+}
+
 // NewListWithCapacity create a new empty List, but init with some capacity,
 // for optimize memory allocation.
 func NewListWithCapacity[T any](capacity int) *List[T] {
 	return NewListFrom[T](make([]T, 0, capacity))
 }
 
+// DecodeOptions get current options used when unmarshal JSON into this list.
+//
+// See document of [DecodeOptions] for detail.
+func (l *List[T]) DecodeOptions() DecodeOptions {
+	return l.decodeOptions
+}
+
+// SetDecodeOptions set options used when unmarshal JSON into this list, by
+// apply all option to the default decode options.
+//
+// It only takes effect when T is any, all JSON values nested in the array will
+// be decoded with these options. The exceptions are [Lenient], which only
+// takes effect when T is not any, and [UseInt64], [UseBigNumber] and
+// [NumberFunc], which also convert numbers decoded into interfaces in T, like
+// the elements of []any.
+func (l *List[T]) SetDecodeOptions(option ...DecodeOption) {
+	mustNotFrozen(l.checkFrozen("SetDecodeOptions"))
+
+	l.decodeOptions = CreateDecodeOptions(option...)
+}
+
+// AppendOnUnmarshal get if this list appends decoded items after existing ones
+// when unmarshal JSON into it.
+func (l *List[T]) AppendOnUnmarshal() bool {
+	return l.appendOnUnmarshal
+}
+
+// SetAppendOnUnmarshal set if this list appends decoded items after existing
+// ones when unmarshal JSON into it.
+//
+// By default it's false, the list will be cleared before decode, which is
+// consistent with the behavior of slice in standard library.
+func (l *List[T]) SetAppendOnUnmarshal(v bool) {
+	mustNotFrozen(l.checkFrozen("SetAppendOnUnmarshal"))
+
+	l.appendOnUnmarshal = v
+}
+
+// MarshalNilAsNull get if this list will be marshaled into JSON null when its
+// inner slice is nil.
+func (l *List[T]) MarshalNilAsNull() bool {
+	return l.marshalNilAsNull
+}
+
+// SetMarshalNilAsNull set if this list will be marshaled into JSON null when
+// its inner slice is nil, like how [json.Marshal] deals with a nil slice.
+//
+// By default it's false, and the list always be marshaled as a JSON array,
+// so a nil inner slice gives `[]`. A non-nil empty slice always gives `[]`.
+func (l *List[T]) SetMarshalNilAsNull(v bool) {
+	mustNotFrozen(l.checkFrozen("SetMarshalNilAsNull"))
+
+	l.marshalNilAsNull = v
+}
+
 // Get value at index.
 func (l *List[T]) Get(index int) T {
 	return l.List[index]
@@ -39,17 +117,66 @@ func (l *List[T]) Get(index int) T {
 
 // Set value at index.
 func (l *List[T]) Set(index int, value T) {
+	mustNotFrozen(l.checkFrozen("Set"))
+
 	l.List[index] = value
 }
 
 // Append values into list.
 func (l *List[T]) Append(value ...T) {
+	mustNotFrozen(l.checkFrozen("Append"))
+
 	l.List = append(l.List, value...)
+	_ = l.frontRoom() // drops deque buffer if reallocated
+	l.mods++
 }
 
 // Delete value at index.
 func (l *List[T]) Delete(index int) {
+	mustNotFrozen(l.checkFrozen("Delete"))
+
 	l.List = append(l.List[:index], l.List[index+1:]...)
+	l.mods++
+}
+
+// Resize makes the list exactly n items long. If n is less than current
+// length, the tail is dropped, and slots of dropped items are set to zero
+// value of T, so they can be garbage collected. If n is greater, fill is
+// appended until the length reaches n.
+//
+// It panics if n is negative, like [List.Get] with a negative index.
+func (l *List[T]) Resize(n int, fill T) {
+	l.resize("Resize", n, func(int) T { return fill })
+}
+
+// ResizeWith is like [List.Resize], but new items are generated by gen, with
+// their index in the list.
+func (l *List[T]) ResizeWith(n int, gen func(index int) T) {
+	l.resize("ResizeWith", n, gen)
+}
+
+func (l *List[T]) resize(method string, n int, gen func(index int) T) {
+	mustNotFrozen(l.checkFrozen(method))
+
+	length := len(l.List)
+	if n == length {
+		return
+	}
+
+	if n < length {
+		var zero T
+		for i := length - 1; i >= n; i-- {
+			l.List[i] = zero
+		}
+		l.List = l.List[:n]
+	} else {
+		for i := length; i < n; i++ {
+			l.List = append(l.List, gen(i))
+		}
+		_ = l.frontRoom() // drops deque buffer if reallocated
+	}
+
+	l.mods++
 }
 
 // Len give length of the list.
@@ -57,21 +184,156 @@ func (l *List[T]) Len() int {
 	return len(l.List)
 }
 
+// Find returns the first value which makes pred func return true, and its
+// index. The third return value tells if such value is found, if not, the
+// first return value will be zero value of type T, and index will be -1.
+func (l *List[T]) Find(pred func(T) bool) (T, int, bool) {
+	for i, v := range l.List {
+		if pred(v) {
+			return v, i, true
+		}
+	}
+
+	var zero T
+	return zero, -1, false
+}
+
+// Any checks if there is any value in the list makes pred func return true.
+//
+// It stops at the first match, and returns false for an empty list.
+func (l *List[T]) Any(pred func(T) bool) bool {
+	_, _, found := l.Find(pred)
+	return found
+}
+
+// All checks if all values in the list make pred func return true.
+//
+// It stops at the first mismatch, and returns true for an empty list.
+func (l *List[T]) All(pred func(T) bool) bool {
+	return !l.Any(func(v T) bool {
+		return !pred(v)
+	})
+}
+
 //nolint:unused // used in jsonArray interface
 func (l *List[T]) innerSlice() *[]T {
 	return &l.List
 }
 
+// rangeChildren calls fn for every item with its index, see [Walk].
+func (l *List[T]) rangeChildren(fn func(key, value any) error) error {
+	if l == nil {
+		return nil
+	}
+
+	for i := 0; i < l.Len(); i++ {
+		if err := fn(i, l.Get(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformChildren replaces every item by fn in order, or deletes it if fn
+// says so, see [Transform]. Index passed to fn is the one before any deletion.
+func (l *List[T]) transformChildren(fn func(key, value any) (any, bool, error)) error {
+	if l == nil {
+		return nil
+	}
+
+	kept := l.List[:0]
+	for i, item := range l.List {
+		v, remove, err := fn(i, item)
+		if err == nil && !remove {
+			var ok bool
+			if item, ok = assignable[T](v); !ok {
+				err = &assignError{key: i, value: v, container: l}
+			}
+		}
+
+		if err != nil {
+			l.List = append(kept, l.List[i:]...)
+			return err
+		}
+
+		if !remove {
+			kept = append(kept, item)
+		} else {
+			l.mods++
+		}
+	}
+	l.List = kept
+
+	return nil
+}
+
+// storeDecoded replaces the inner slice by decoded items, or appends them if
+// [List.AppendOnUnmarshal] is enabled.
+func (l *List[T]) storeDecoded(items []T) {
+	if l.appendOnUnmarshal {
+		l.List = append(l.List, items...)
+	} else {
+		l.List = items
+	}
+	l.mods++
+}
+
+// toStdTypes converts the list into a []any, see [ToStdTypes].
+func (l *List[T]) toStdTypes() any {
+	if l == nil {
+		return nil
+	}
+
+	result := make([]any, 0, l.Len())
+	for i := 0; i < l.Len(); i++ {
+		result = append(result, ToStdTypes(l.Get(i)))
+	}
+
+	return result
+}
+
+// WriteJSON writes the JSON encoding of this list into w.
+//
+// Differ from [List.MarshalJSON], items are encoded and written one by one,
+// so the whole output never need to be buffered in memory. It's useful when
+// the list is huge.
+//
+// If w has a Flush method (like [bufio.Writer]), it will be flushed
+// periodically and at the end.
+//
+// If an item failed to encode, the returned error contains its index, and
+// wraps the original error.
+func (l *List[T]) WriteJSON(w io.Writer) error {
+	return writeArray[T](w, l, l.marshalNilAsNull)
+}
+
+func (l *List[T]) encodeJSON(e *encodeState) error {
+	if l == nil {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+	return encodeArray[T](e, l, l.marshalNilAsNull)
+}
+
 // MarshalJSON implements [json.Marshaler] interface.
 //
 // You should not call this directly, use [json.Marshal] instead.
 func (l List[T]) MarshalJSON() ([]byte, error) {
-	return marshalArray[T](&l)
+	return marshalArray[T](&l, l.marshalNilAsNull)
 }
 
 // UnmarshalJSON implements [json.Unmarshaler] interface.
 //
+// A JSON null makes the inner slice nil, unless [List.AppendOnUnmarshal] is
+// enabled, in which case the list is not changed.
+//
 // You should not call this directly, use [json.Marshal] instead.
 func (l *List[T]) UnmarshalJSON(data []byte) error {
-	return unmarshalArray[T](data, l)
+	if err := l.checkFrozen("UnmarshalJSON"); err != nil {
+		return err
+	}
+
+	l.mods++
+	return unmarshalArray[T](data, l, l.decodeOptions, l.appendOnUnmarshal)
 }
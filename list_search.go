@@ -0,0 +1,31 @@
+//go:build go1.21
+
+package geko
+
+import (
+	"cmp"
+	"slices"
+)
+
+// BinarySearchList searches for target in a sorted [List] and returns the
+// index where target is found, or the index where it would be inserted to keep
+// the list sorted. The second return value tells if target is found.
+//
+// If there are multiple items equal to target, the index of the first one
+// is returned.
+//
+// The list must be sorted in increasing order, otherwise the result is
+// meaningless.
+func BinarySearchList[T cmp.Ordered](l *List[T], target T) (int, bool) {
+	return slices.BinarySearch(l.List, target)
+}
+
+// BinarySearchListFunc works like [BinarySearchList], but uses a custom
+// comparison function.
+//
+// The cmp func should return 0 if the two items are equal, a negative number
+// if a < b, and a positive number if a > b. The list must be sorted in
+// increasing order defined by cmp.
+func BinarySearchListFunc[T any](l *List[T], target T, cmp func(a, b T) int) (int, bool) {
+	return slices.BinarySearchFunc(l.List, target, cmp)
+}
@@ -0,0 +1,58 @@
+//go:build go1.21
+
+package geko_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestBinarySearchList(t *testing.T) {
+	l := geko.NewListFrom([]int64{10, 20, 20, 20, 30})
+
+	check := func(target int64, exceptedIndex int, exceptedFound bool) {
+		index, found := geko.BinarySearchList(l, target)
+		if index != exceptedIndex || found != exceptedFound {
+			t.Fatalf(
+				"BinarySearchList %d excepted (%d, %v), got (%d, %v)",
+				target, exceptedIndex, exceptedFound, index, found,
+			)
+		}
+	}
+
+	check(5, 0, false)
+	check(10, 0, true)
+	check(15, 1, false)
+	// index of the first occurrence is returned when there are duplicates
+	check(20, 1, true)
+	check(25, 4, false)
+	check(30, 4, true)
+	check(35, 5, false)
+
+	empty := geko.NewList[int64]()
+	if index, found := geko.BinarySearchList(empty, 1); index != 0 || found {
+		t.Fatalf("BinarySearchList in empty list should return (0, false), got (%d, %v)", index, found)
+	}
+}
+
+func TestBinarySearchListFunc(t *testing.T) {
+	l := geko.NewListFrom([]string{"a", "B", "b", "C"})
+
+	cmp := func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	if index, found := geko.BinarySearchListFunc(l, "b", cmp); index != 1 || !found {
+		t.Fatalf("BinarySearchListFunc excepted (1, true), got (%d, %v)", index, found)
+	}
+
+	if index, found := geko.BinarySearchListFunc(l, "bb", cmp); index != 3 || found {
+		t.Fatalf("BinarySearchListFunc excepted (3, false), got (%d, %v)", index, found)
+	}
+
+	if index, found := geko.BinarySearchListFunc(l, "D", cmp); index != 4 || found {
+		t.Fatalf("BinarySearchListFunc excepted (4, false), got (%d, %v)", index, found)
+	}
+}
@@ -1,9 +1,14 @@
 package geko_test
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"math/rand"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/7sDream/geko"
@@ -23,6 +28,26 @@ func TestList_New(t *testing.T) {
 	}
 }
 
+func TestList_NewOf(t *testing.T) {
+	l := geko.NewListOf[any](1, "two", geko.NewMapOf(geko.P("b", 3), geko.P("a", 4)), nil)
+
+	output, _ := json.Marshal(l)
+	if string(output) != `[1,"two",{"b":3,"a":4},null]` {
+		t.Fatalf("NewListOf result not correct: %s", string(output))
+	}
+
+	items := []int{1, 2}
+	ints := geko.NewListOf(items...)
+	ints.Set(0, 3)
+	if items[0] != 1 || ints.Len() != 2 {
+		t.Fatalf("NewListOf should copy items")
+	}
+
+	if ints = geko.NewListOf[int](); ints.List != nil {
+		t.Fatalf("NewListOf without items inner slice is not nil")
+	}
+}
+
 func TestList_NewWithCapacity(t *testing.T) {
 	l := geko.NewListWithCapacity[int](12)
 
@@ -105,6 +130,51 @@ func TestList_Delete(t *testing.T) {
 	}
 }
 
+func TestList_Resize(t *testing.T) {
+	a, b := 1, 2
+	l := geko.NewListFrom([]*int{&a, &b, &a})
+	backing := l.List
+
+	l.Resize(1, nil)
+	if !reflect.DeepEqual(l.List, []*int{&a}) || backing[1] != nil || backing[2] != nil {
+		t.Fatalf("Resize should truncate and zero dropped slots: %v", backing)
+	}
+
+	l.Resize(3, &b)
+	if !reflect.DeepEqual(l.List, []*int{&a, &b, &b}) {
+		t.Fatalf("Resize should append fill: %v", l.List)
+	}
+
+	l.Resize(3, nil)
+	l.Resize(0, nil)
+	if l.Len() != 0 {
+		t.Fatalf("Resize to zero not correct: %v", l.List)
+	}
+
+	if !willPanic(func() {
+		l.Resize(-1, nil)
+	}) {
+		t.Fatalf("Resize doesn't panic with negative length")
+	}
+}
+
+func TestList_ResizeWith(t *testing.T) {
+	l := geko.NewListOf("a", "b")
+
+	l.ResizeWith(5, strconv.Itoa)
+	if !reflect.DeepEqual(l.List, []string{"a", "b", "2", "3", "4"}) {
+		t.Fatalf("ResizeWith should append generated items: %v", l.List)
+	}
+
+	l.ResizeWith(1, func(int) string {
+		t.Fatalf("ResizeWith should not call gen when shrinking")
+		return ""
+	})
+	if !reflect.DeepEqual(l.List, []string{"a"}) {
+		t.Fatalf("ResizeWith should truncate: %v", l.List)
+	}
+}
+
 func TestList_Len(t *testing.T) {
 	for times := 0; times < 20; times++ {
 		l := geko.NewList[int]()
@@ -177,6 +247,85 @@ func TestList_MarshalJSON_AnyType(t *testing.T) {
 	}
 }
 
+type byteItem uint8
+
+type ptrJSONItem int
+
+func (p *ptrJSONItem) MarshalJSON() ([]byte, error) {
+	return []byte(`"j` + strconv.Itoa(int(*p)) + `"`), nil
+}
+
+type ptrTextItem int
+
+func (p *ptrTextItem) MarshalText() ([]byte, error) {
+	return []byte("t" + strconv.Itoa(int(*p))), nil
+}
+
+func listMarshalOutputs[T any](t *testing.T, l *geko.List[T]) []string {
+	t.Helper()
+
+	std, err := json.Marshal(l)
+	if err != nil {
+		t.Fatalf("Marshal list with error: %s", err.Error())
+	}
+
+	gk, err := geko.JSONMarshal(l)
+	if err != nil {
+		t.Fatalf("JSONMarshal list with error: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err = l.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON list with error: %s", err.Error())
+	}
+
+	return []string{string(std), string(gk), buf.String()}
+}
+
+func checkListMarshalOutputs[T any](t *testing.T, l *geko.List[T], excepted string) {
+	t.Helper()
+
+	for _, output := range listMarshalOutputs(t, l) {
+		if output != excepted {
+			t.Fatalf("Marshal result %s not correct, excepted %s", output, excepted)
+		}
+	}
+}
+
+func TestList_MarshalJSON_ByteSlice(t *testing.T) {
+	checkListMarshalOutputs(t, geko.NewListFrom([]byte{1, 2, 3}), `"AQID"`)
+	checkListMarshalOutputs(t, geko.NewListFrom([]byteItem{1, 2, 3}), `"AQID"`)
+	checkListMarshalOutputs(t, geko.NewListFrom([]byte{}), `""`)
+	checkListMarshalOutputs(t, geko.NewList[byte](), `[]`)
+}
+
+func TestList_MarshalJSON_PointerReceiverMarshaler(t *testing.T) {
+	checkListMarshalOutputs(t, geko.NewListFrom([]ptrJSONItem{1, 2}), `["j1","j2"]`)
+	checkListMarshalOutputs(t, geko.NewListFrom([]ptrTextItem{1, 2}), `["t1","t2"]`)
+}
+
+func checkListSameAsStd[T any](t *testing.T, slice []T) {
+	t.Helper()
+
+	excepted, err := json.Marshal(slice)
+	if err != nil {
+		t.Fatalf("Marshal slice with error: %s", err.Error())
+	}
+
+	// marshal twice, the second time uses encode states from pool
+	for i := 0; i < 2; i++ {
+		checkListMarshalOutputs(t, geko.NewListFrom(slice), string(excepted))
+	}
+}
+
+func TestList_MarshalJSON_SameAsStd(t *testing.T) {
+	checkListSameAsStd(t, []byte{1, 2, 3, 255})
+	checkListSameAsStd(t, []byteItem{})
+	checkListSameAsStd(t, []ptrJSONItem{1, 2})
+	checkListSameAsStd(t, []ptrTextItem{3})
+	checkListSameAsStd(t, []any{byte(1), []byte{2}, "s", nil})
+}
+
 func TestList_UnmarshalJSON_DirectlyCallWithInvalidData(t *testing.T) {
 	l := geko.NewList[any]()
 	if err := l.UnmarshalJSON([]byte("")); err == nil {
@@ -242,6 +391,131 @@ func TestList_UnmarshalJSON_ConcreteType(t *testing.T) {
 	}
 }
 
+func TestList_UnmarshalJSON_Lenient(t *testing.T) {
+	l := geko.NewList[int]()
+	l.SetDecodeOptions(geko.Lenient(true))
+
+	err := json.Unmarshal([]byte(`[1, "x", 3, null, {"a": 1}]`), &l)
+
+	if paths := skippedPaths(t, err); !reflect.DeepEqual(paths, []string{"/1", "/4"}) {
+		t.Fatalf("Skipped paths not correct: %#v", paths)
+	}
+
+	if !reflect.DeepEqual(l.List, []int{1, 3, 0}) {
+		t.Fatalf("Lenient unmarshal result not correct: %#v", l.List)
+	}
+
+	l.SetAppendOnUnmarshal(true)
+
+	err = json.Unmarshal([]byte(`[4, [5]]`), &l)
+
+	if paths := skippedPaths(t, err); !reflect.DeepEqual(paths, []string{"/1"}) {
+		t.Fatalf("Skipped paths not correct: %#v", paths)
+	}
+
+	if !reflect.DeepEqual(l.List, []int{1, 3, 0, 4}) {
+		t.Fatalf("Lenient unmarshal in append mode result not correct: %#v", l.List)
+	}
+
+	l.SetAppendOnUnmarshal(false)
+
+	if err = json.Unmarshal([]byte(`[6]`), &l); err != nil || !reflect.DeepEqual(l.List, []int{6}) {
+		t.Fatalf("Lenient unmarshal without skipped value not correct: %#v, %#v", l.List, err)
+	}
+}
+
+func TestList_UnmarshalJSON_LenientError(t *testing.T) {
+	for _, data := range []string{`[1, "x" 2]`, `[1, "x"}`, `[1, "x"`, `[1, "x", 2, 3]`} {
+		l := geko.NewList[int]()
+		l.SetDecodeOptions(geko.Lenient(true), geko.MaxItems(3))
+
+		err := l.UnmarshalJSON([]byte(data))
+		if err == nil {
+			t.Fatalf("Unmarshal %s should fail", data)
+		}
+
+		var multiErr *geko.MultiDecodeError
+		if errors.As(err, &multiErr) {
+			t.Fatalf("Unmarshal %s should not be skipped: %s", data, err.Error())
+		}
+	}
+}
+
+type listTestNumbers struct {
+	Any     any
+	Slice   []any
+	Array   [2]any
+	Map     map[string]any
+	Pointer *any
+	Next    *listTestNumbers
+	Object  geko.Object
+	Plain   struct{ N float64 }
+}
+
+func TestList_UnmarshalJSON_TypedNumbers(t *testing.T) {
+	data := []byte(`[{"Any": 1, "Slice": [2, {"a": 3}], "Array": [4, "s"], "Map": {"b": 5}, "Pointer": 6, ` +
+		`"Next": {"Any": [7]}, "Object": {"c": 8}, "Plain": {"N": 9}}, {}]`)
+
+	six := any(int64(6))
+	object := geko.NewMap[string, any]()
+	object.Set("c", 8.0) // decodes itself with default options
+	excepted := []listTestNumbers{{
+		Any:     int64(1),
+		Slice:   []any{int64(2), map[string]any{"a": int64(3)}},
+		Array:   [2]any{int64(4), "s"},
+		Map:     map[string]any{"b": int64(5)},
+		Pointer: &six,
+		Next:    &listTestNumbers{Any: []any{int64(7)}},
+		Object:  object,
+		Plain:   struct{ N float64 }{9},
+	}, {}}
+
+	for _, lenient := range []bool{false, true} {
+		l := geko.NewList[listTestNumbers]()
+		l.SetDecodeOptions(geko.UseInt64(true), geko.Lenient(lenient))
+
+		if err := json.Unmarshal(data, &l); err != nil {
+			t.Fatalf("Unmarshal with error: %s", err.Error())
+		}
+
+		if !reflect.DeepEqual(l.List, excepted) {
+			t.Fatalf("Numbers in typed list not converted: %#v", l.List)
+		}
+	}
+
+	l := geko.NewList[[]any]()
+	l.SetDecodeOptions(geko.NumberFunc(func(literal string) (any, error) {
+		if literal == "0" {
+			return nil, nil
+		}
+		return parseCents(literal)
+	}))
+
+	if err := json.Unmarshal([]byte(`[[0, 1.5]]`), &l); err != nil ||
+		!reflect.DeepEqual(l.List, [][]any{{nil, cents(150)}}) {
+		t.Fatalf("Numbers in typed list not converted by NumberFunc: %#v, %#v", l.List, err)
+	}
+
+	for _, data := range []string{
+		`[{"Slice": [1.234]}]`, `[{"Array": [1, 1.234]}]`, `[{"Map": {"m": 1.234}}]`, `[{"Any": {"m": [1.234]}}]`,
+	} {
+		l := geko.NewList[listTestNumbers]()
+		l.SetDecodeOptions(geko.NumberFunc(parseCents))
+
+		var numberErr *geko.NumberFuncError
+		if err := json.Unmarshal([]byte(data), &l); !errors.As(err, &numberErr) {
+			t.Fatalf("Unmarshal %s should fail with NumberFuncError, got %#v", data, err)
+		}
+	}
+
+	// without conversion options, std lib is used directly
+	plain := geko.NewList[[]any]()
+	plain.SetDecodeOptions(geko.UseNumber(true))
+	if err := json.Unmarshal([]byte(`[[1]]`), &plain); err != nil || !reflect.DeepEqual(plain.List, [][]any{{1.0}}) {
+		t.Fatalf("Unmarshal typed list without conversion not correct: %#v, %#v", plain.List, err)
+	}
+}
+
 func TestList_UnmarshalJSON_InitializedList(t *testing.T) {
 	l := geko.NewListFrom[int]([]int{7})
 
@@ -304,3 +578,503 @@ func TestList_UnmarshalJSON_InnerValueUseOurType(t *testing.T) {
 		t.Fatalf("Inner object -> array item not correct: %#v", lml)
 	}
 }
+
+func TestList_UnmarshalJSON_NullItem(t *testing.T) {
+	var l geko.Array
+	if err := json.Unmarshal([]byte(`[1, null, 2]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	excepted := []any{1.0, nil, 2.0}
+	if !reflect.DeepEqual(l.List, excepted) {
+		t.Fatalf("Excepted %#v, got %#v", excepted, l.List)
+	}
+
+	var l2 geko.Array
+	if err := json.Unmarshal([]byte(`[[null], {"a": [null]}]`), &l2); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	inner, ok := l2.Get(0).(geko.Array)
+	if !ok || !reflect.DeepEqual(inner.List, []any{nil}) {
+		t.Fatalf("Nested array with null not correct: %#v", l2.Get(0))
+	}
+
+	obj, ok := l2.Get(1).(geko.ObjectItems)
+	if !ok {
+		t.Fatalf("Nested object is not ObjectItems type: %#v", l2.Get(1))
+	}
+
+	objArr, ok := obj.GetFirstOrZeroValue("a").(geko.Array)
+	if !ok || !reflect.DeepEqual(objArr.List, []any{nil}) {
+		t.Fatalf("Array with null inside object not correct: %#v", obj)
+	}
+}
+
+func TestList_SetDecodeOptions(t *testing.T) {
+	l := geko.NewList[any]()
+	l.SetDecodeOptions(
+		geko.UseObject(),
+		geko.UseNumber(true),
+		geko.ObjectOnDuplicatedKey(geko.UpdateValueUpdateOrder),
+	)
+
+	if !reflect.DeepEqual(l.DecodeOptions(), geko.CreateDecodeOptions(
+		geko.UseObject(),
+		geko.UseNumber(true),
+		geko.ObjectOnDuplicatedKey(geko.UpdateValueUpdateOrder),
+	)) {
+		t.Fatalf("DecodeOptions not same as the set one")
+	}
+
+	if err := json.Unmarshal([]byte(`[{"a":1,"b":3,"a":2},[4]]`), &l); err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	obj, ok := l.Get(0).(geko.Object)
+	if !ok {
+		t.Fatalf("Inner object is not Object type: %#v", l.Get(0))
+	}
+
+	if obj.DuplicatedKeyStrategy() != geko.UpdateValueUpdateOrder {
+		t.Fatalf("Inner object does not use configured strategy")
+	}
+
+	if !reflect.DeepEqual(obj.Keys(), []string{"b", "a"}) {
+		t.Fatalf("Inner object keys not correct: %#v", obj.Keys())
+	}
+
+	if v, _ := obj.Get("a"); v != json.Number("2") {
+		t.Fatalf("Inner object value is not correct json.Number: %#v", v)
+	}
+
+	arr, ok := l.Get(1).(geko.Array)
+	if !ok {
+		t.Fatalf("Inner array is not Array type: %#v", l.Get(1))
+	}
+
+	if arr.Get(0) != json.Number("4") {
+		t.Fatalf("Inner array value is not correct json.Number: %#v", arr.Get(0))
+	}
+
+	if !reflect.DeepEqual(arr.DecodeOptions(), l.DecodeOptions()) {
+		t.Fatalf("Inner array does not inherit decode options")
+	}
+}
+
+func TestList_SetAppendOnUnmarshal(t *testing.T) {
+	l := geko.NewListFrom([]int{7})
+	l.SetAppendOnUnmarshal(true)
+
+	if !l.AppendOnUnmarshal() {
+		t.Fatalf("AppendOnUnmarshal not same as the set one")
+	}
+
+	if err := json.Unmarshal([]byte(`[1, 2]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+	if err := json.Unmarshal([]byte(`[3]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	excepted := []int{7, 1, 2, 3}
+	if !reflect.DeepEqual(l.List, excepted) {
+		t.Fatalf("Excepted %#v, got %#v", excepted, l.List)
+	}
+
+	if err := json.Unmarshal([]byte(`["str"]`), &l); err == nil {
+		t.Fatalf("Unmarshal mismatched type should report error")
+	}
+	if !reflect.DeepEqual(l.List, excepted) {
+		t.Fatalf("Failed unmarshal should not change list, got %#v", l.List)
+	}
+
+	l2 := geko.NewListFrom([]any{"old"})
+	l2.SetAppendOnUnmarshal(true)
+
+	if err := json.Unmarshal([]byte(`[1, {"a": 2}]`), &l2); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+	if err := json.Unmarshal([]byte(`[null]`), &l2); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	if l2.Len() != 4 || l2.Get(0) != "old" || l2.Get(1) != 1.0 || l2.Get(3) != nil {
+		t.Fatalf("Append mode result not correct: %#v", l2.List)
+	}
+	if _, ok := l2.Get(2).(geko.ObjectItems); !ok {
+		t.Fatalf("Appended object is not ObjectItems type: %#v", l2.Get(2))
+	}
+
+	l2.SetAppendOnUnmarshal(false)
+	if err := json.Unmarshal([]byte(`[1]`), &l2); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+	if !reflect.DeepEqual(l2.List, []any{1.0}) {
+		t.Fatalf("Disable append mode should clear the list, got %#v", l2.List)
+	}
+}
+
+type limitedWriter struct {
+	limit int
+	flush int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.limit <= 0 {
+		return 0, errors.New("write limit reached")
+	}
+	w.limit--
+	return len(p), nil
+}
+
+func (w *limitedWriter) Flush() error {
+	w.flush++
+	return nil
+}
+
+type failedFlushWriter struct {
+	bytes.Buffer
+}
+
+func (w *failedFlushWriter) Flush() error {
+	return errors.New("flush failed")
+}
+
+type noErrorFlushWriter struct {
+	bytes.Buffer
+	flush int
+}
+
+func (w *noErrorFlushWriter) Flush() {
+	w.flush++
+}
+
+func TestList_WriteJSON(t *testing.T) {
+	l := geko.NewListFrom([]any{1, "<s>", nil, geko.NewListFrom([]int{2})})
+
+	var buf bytes.Buffer
+	if err := l.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON with error: %s", err.Error())
+	}
+
+	if buf.String() != `[1,"<s>",null,[2]]` {
+		t.Fatalf("WriteJSON result %s not correct", buf.String())
+	}
+
+	buf.Reset()
+	if err := geko.NewList[int]().WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON with error: %s", err.Error())
+	}
+
+	if buf.String() != `[]` {
+		t.Fatalf("WriteJSON result %s not correct", buf.String())
+	}
+}
+
+func TestList_WriteJSON_Flush(t *testing.T) {
+	l := geko.NewListWithCapacity[int](3000)
+	for i := 0; i < 3000; i++ {
+		l.Append(i)
+	}
+
+	var output bytes.Buffer
+	w := bufio.NewWriterSize(&output, 16)
+	if err := l.WriteJSON(w); err != nil {
+		t.Fatalf("WriteJSON with error: %s", err.Error())
+	}
+
+	if w.Buffered() != 0 {
+		t.Fatalf("WriteJSON does not flush at the end")
+	}
+
+	var result []int
+	if err := json.Unmarshal(output.Bytes(), &result); err != nil || !reflect.DeepEqual(result, l.List) {
+		t.Fatalf("WriteJSON result not correct")
+	}
+
+	nw := &noErrorFlushWriter{}
+	if err := l.WriteJSON(nw); err != nil {
+		t.Fatalf("WriteJSON with error: %s", err.Error())
+	}
+
+	if nw.flush != 3 {
+		t.Fatalf("WriteJSON excepted flush 3 times, got %d", nw.flush)
+	}
+
+	if err := l.WriteJSON(&failedFlushWriter{}); err == nil {
+		t.Fatalf("WriteJSON should report flush error")
+	}
+}
+
+func TestList_WriteJSON_WriteError(t *testing.T) {
+	l := geko.NewListFrom([]int{1, 2})
+
+	// "[", "1", ",2", "]"
+	for limit := 0; limit < 4; limit++ {
+		if err := l.WriteJSON(&limitedWriter{limit: limit}); err == nil {
+			t.Fatalf("WriteJSON should report error when writer failed after %d writes", limit)
+		}
+	}
+
+	w := &limitedWriter{limit: 4}
+	if err := l.WriteJSON(w); err != nil {
+		t.Fatalf("WriteJSON with error: %s", err.Error())
+	}
+
+	if w.flush != 1 {
+		t.Fatalf("WriteJSON excepted flush 1 times, got %d", w.flush)
+	}
+
+	if err := geko.NewListFrom([]byte{1}).WriteJSON(&limitedWriter{}); err == nil {
+		t.Fatalf("WriteJSON should report error when writer failed for byte slice")
+	}
+}
+
+func TestList_WriteJSON_EncodeError(t *testing.T) {
+	l := geko.NewListFrom([]any{1, make(chan int)})
+
+	err := l.WriteJSON(io.Discard)
+	if err == nil {
+		t.Fatalf("WriteJSON should report encode error")
+	}
+
+	var pathErr *geko.MarshalError
+	if !errors.As(err, &pathErr) || !reflect.DeepEqual(pathErr.Path, []any{1}) {
+		t.Fatalf("WriteJSON error should have the item path: %#v", err)
+	}
+
+	var typeErr *json.UnsupportedTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("WriteJSON error does not wrap the origin error: %s", err.Error())
+	}
+
+	marshalWillReportError[*json.UnsupportedTypeError](t, l)
+}
+
+// WriteJSON never holds the whole output, its only buffer is reused for every
+// item, so the live memory is flat regardless of the list size. B/op reported
+// here only contains short-lived garbage created by the std encoder per item.
+// Compare with BenchmarkList_MarshalJSON, which buffers the entire output.
+func BenchmarkList_WriteJSON(b *testing.B) {
+	for _, size := range []int{1_000, 10_000, 100_000} {
+		l := geko.NewListWithCapacity[any](size)
+		for i := 0; i < size; i++ {
+			l.Append(i)
+		}
+
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = l.WriteJSON(io.Discard)
+			}
+		})
+	}
+}
+
+func BenchmarkList_MarshalJSON(b *testing.B) {
+	for _, size := range []int{1_000, 10_000, 100_000} {
+		l := geko.NewListWithCapacity[any](size)
+		for i := 0; i < size; i++ {
+			l.Append(i)
+		}
+
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = json.Marshal(l)
+			}
+		})
+	}
+}
+
+func TestList_SetMarshalNilAsNull(t *testing.T) {
+	roundTrip := func(l *geko.List[int], exceptedOutput string) {
+		l.SetMarshalNilAsNull(true)
+		if !l.MarshalNilAsNull() {
+			t.Fatalf("MarshalNilAsNull not same as the set one")
+		}
+
+		output, err := json.Marshal(l)
+		if err != nil {
+			t.Fatalf("Marshal with error: %s", err.Error())
+		}
+
+		if string(output) != exceptedOutput {
+			t.Fatalf("Marshal result %s not correct, excepted %s", string(output), exceptedOutput)
+		}
+
+		var buf bytes.Buffer
+		if err = l.WriteJSON(&buf); err != nil || buf.String() != exceptedOutput {
+			t.Fatalf("WriteJSON result %s not correct, excepted %s", buf.String(), exceptedOutput)
+		}
+
+		result := geko.NewListFrom([]int{7})
+		if err = result.UnmarshalJSON(output); err != nil {
+			t.Fatalf("Unmarshal with error: %s", err.Error())
+		}
+
+		if (result.List == nil) != (l.List == nil) || !reflect.DeepEqual(result.List, l.List) {
+			t.Fatalf("Round trip result %#v not same as origin %#v", result.List, l.List)
+		}
+	}
+
+	roundTrip(geko.NewList[int](), `null`)
+	roundTrip(geko.NewListFrom([]int{}), `[]`)
+	roundTrip(geko.NewListFrom([]int{1, 2}), `[1,2]`)
+
+	l := geko.NewList[int]()
+	l.SetMarshalNilAsNull(true)
+	if err := l.WriteJSON(&limitedWriter{}); err == nil {
+		t.Fatalf("WriteJSON should report write error")
+	}
+}
+
+func TestList_UnmarshalJSON_Null(t *testing.T) {
+	type wrapper struct {
+		L geko.List[any] `json:"l"`
+	}
+
+	var w wrapper
+	w.L.Append("old")
+
+	if err := json.Unmarshal([]byte(`{"l": null}`), &w); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	if w.L.List != nil {
+		t.Fatalf("Unmarshal null does not clear the list: %#v", w.L.List)
+	}
+
+	l := geko.NewListFrom([]any{"old"})
+	l.SetAppendOnUnmarshal(true)
+
+	if err := l.UnmarshalJSON([]byte(` null `)); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(l.List, []any{"old"}) {
+		t.Fatalf("Unmarshal null in append mode should not change the list: %#v", l.List)
+	}
+}
+
+func TestList_Find(t *testing.T) {
+	var l geko.Array
+	if err := json.Unmarshal([]byte(`[1, "two", {"three": 3}, [4], "five"]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	isString := func(v any) bool {
+		_, ok := v.(string)
+		return ok
+	}
+
+	v, index, found := l.Find(isString)
+	if !found || index != 1 || v != "two" {
+		t.Fatalf("Find result (%#v, %d, %v) not correct", v, index, found)
+	}
+
+	v, index, found = l.Find(func(v any) bool {
+		_, ok := v.(geko.Object)
+		return ok
+	})
+	if found || index != -1 || v != nil {
+		t.Fatalf("Find result (%#v, %d, %v) should be not found", v, index, found)
+	}
+
+	called := 0
+	l.Find(func(v any) bool {
+		called++
+		_, ok := v.(geko.ObjectItems)
+		return ok
+	})
+	if called != 3 {
+		t.Fatalf("Find does not stop at first match, pred called %d times", called)
+	}
+}
+
+func TestList_AnyAll(t *testing.T) {
+	var l geko.Array
+	if err := json.Unmarshal([]byte(`[1, 2.5, "three", null]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	isScalar := func(v any) bool {
+		switch v.(type) {
+		case geko.Array, geko.ObjectItems:
+			return false
+		default:
+			return true
+		}
+	}
+
+	isNumber := func(v any) bool {
+		_, ok := v.(float64)
+		return ok
+	}
+
+	if !l.Any(isNumber) {
+		t.Fatalf("Any should be true when there are numbers")
+	}
+
+	if l.All(isNumber) {
+		t.Fatalf("All should be false when there are non-numbers")
+	}
+
+	if !l.All(isScalar) {
+		t.Fatalf("All should be true when all items are scalar")
+	}
+
+	if l.Any(func(v any) bool { return !isScalar(v) }) {
+		t.Fatalf("Any should be false when no item is not scalar")
+	}
+
+	called := 0
+	l.All(func(v any) bool {
+		called++
+		return isNumber(v)
+	})
+	if called != 3 {
+		t.Fatalf("All does not stop at first mismatch, pred called %d times", called)
+	}
+
+	empty := geko.NewList[any]()
+	if empty.Any(isScalar) {
+		t.Fatalf("Any of empty list should be false")
+	}
+	if !empty.All(isNumber) {
+		t.Fatalf("All of empty list should be true")
+	}
+}
+
+func TestList_UnmarshalJSON_MaxDepth(t *testing.T) {
+	l := geko.NewList[any]()
+	l.SetDecodeOptions(geko.MaxDepth(2))
+
+	if err := json.Unmarshal([]byte(`[[1], {"a": 1}]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	unmarshalWillReportError[*geko.LimitExceededError](t, `[[[1]]]`, l)
+	unmarshalWillReportError[*geko.LimitExceededError](t, `[{"a": {}}]`, l)
+
+	l.SetDecodeOptions(geko.MaxDepth(1))
+	unmarshalWillReportError[*geko.LimitExceededError](t, `[[]]`, l)
+	if err := json.Unmarshal([]byte(`[1]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+}
+
+func TestList_UnmarshalJSON_Limits(t *testing.T) {
+	l := geko.NewList[any]()
+	l.SetDecodeOptions(geko.MaxItems(3), geko.UseObject())
+
+	if err := json.Unmarshal([]byte(`[1, {"a": 2}]`), &l); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	unmarshalWillReportError[*geko.LimitExceededError](t, `[1, {"a": 2, "a": 3}]`, l)
+
+	l.SetDecodeOptions(geko.MaxBytes(5))
+	unmarshalWillReportError[*geko.LimitExceededError](t, `[1, 2, 3]`, l)
+}
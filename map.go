@@ -1,5 +1,7 @@
 package geko
 
+import "fmt"
+
 // DuplicatedKeyStrategy controls the behavior of [Map.Add] when meet a
 // duplicate key. Default strategy is [UpdateValueKeepOrder].
 //
@@ -46,6 +48,11 @@ type Map[K comparable, V any] struct {
 	inner map[K]V
 
 	duplicatedKeyStrategy DuplicatedKeyStrategy
+	duplicates            *Pairs[K, V]
+	lenient               bool
+	frozen                bool
+	// mods counts structural modifications, see [Map.Range]
+	mods uint
 }
 
 // Object is a [Map], whose type parameters are specialized as
@@ -66,6 +73,23 @@ func NewMapWithCapacity[K comparable, V any](capacity int) *Map[K, V] {
 	return m
 }
 
+// NewMapOf creates a new map contains pairs, in their order. Duplicated keys
+// are dealt with the default [UpdateValueKeepOrder] strategy.
+//
+//	geko.NewMapOf(geko.P("b", 1), geko.P("a", 2)) // {"b": 1, "a": 2}
+func NewMapOf[K comparable, V any](pairs ...Pair[K, V]) *Map[K, V] {
+	return NewMapOfStrategy(UpdateValueKeepOrder, pairs...)
+}
+
+// NewMapOfStrategy likes [NewMapOf], but duplicated keys in pairs are dealt
+// with strategy, which is kept as [Map.DuplicatedKeyStrategy] of the result.
+func NewMapOfStrategy[K comparable, V any](strategy DuplicatedKeyStrategy, pairs ...Pair[K, V]) *Map[K, V] {
+	m := NewMapWithCapacity[K, V](len(pairs))
+	m.SetDuplicatedKeyStrategy(strategy)
+	m.Append(pairs...)
+	return m
+}
+
 // DuplicatedKeyStrategy get current strategy when [Map.Add] with a duplicated
 // key.
 //
@@ -78,9 +102,61 @@ func (m *Map[K, V]) DuplicatedKeyStrategy() DuplicatedKeyStrategy {
 //
 // See document of [DuplicatedKeyStrategy] and its enum value for detail.
 func (m *Map[K, V]) SetDuplicatedKeyStrategy(strategy DuplicatedKeyStrategy) {
+	mustNotFrozen(m.checkFrozen("SetDuplicatedKeyStrategy"))
+
 	m.duplicatedKeyStrategy = strategy
 }
 
+// RecordDuplicates reports whether [Map.Add] records discarded pairs when meet
+// a duplicated key.
+func (m *Map[K, V]) RecordDuplicates() bool {
+	return m.duplicates != nil
+}
+
+// SetRecordDuplicates enables or disables recording discarded pairs when
+// [Map.Add] meets a duplicated key, they can be got by [Map.Duplicates].
+//
+// Which pair is discarded is decided by [Map.DuplicatedKeyStrategy]: the old
+// one for [UpdateValueKeepOrder] and [UpdateValueUpdateOrder], the new one for
+// [KeepValueUpdateOrder] and [Ignore].
+//
+// Disable it will clear recorded pairs. When unmarshal into a map with it
+// enabled, all [Object] nested inside will also record their own duplicates,
+// see [RecordDuplicates].
+func (m *Map[K, V]) SetRecordDuplicates(on bool) {
+	mustNotFrozen(m.checkFrozen("SetRecordDuplicates"))
+
+	if !on {
+		m.duplicates = nil
+	} else if m.duplicates == nil {
+		m.duplicates = NewPairs[K, V]()
+	}
+}
+
+// Duplicates returns pairs discarded by [Map.Add] because of duplicated key,
+// in the order they are discarded. It returns nil if recording is not
+// enabled by [Map.SetRecordDuplicates].
+func (m *Map[K, V]) Duplicates() *Pairs[K, V] {
+	return m.duplicates
+}
+
+// Lenient reports whether unmarshal into this map skips values which fail to
+// decode.
+func (m *Map[K, V]) Lenient() bool {
+	return m.lenient
+}
+
+// SetLenient enables or disables skipping values which fail to decode, when
+// unmarshal JSON into this map. Successfully decoded members are still added,
+// and a [MultiDecodeError] is returned for the skipped ones.
+//
+// It only takes effect when V is not any, see [Lenient].
+func (m *Map[K, V]) SetLenient(on bool) {
+	mustNotFrozen(m.checkFrozen("SetLenient"))
+
+	m.lenient = on
+}
+
 // Get a value by key. The second return value tells if the key exists. If
 // not, first return value will be zero value of type V.
 func (m *Map[K, V]) Get(key K) (V, bool) {
@@ -130,6 +206,7 @@ func (m *Map[K, V]) set(key K, value V, alreadyExist bool) {
 
 	if !alreadyExist {
 		m.order = append(m.order, key)
+		m.mods++
 	}
 
 	m.inner[key] = value
@@ -141,6 +218,8 @@ func (m *Map[K, V]) set(key K, value V, alreadyExist bool) {
 // This operation is the same as [Map.Add] when duplicate key strategy is
 // [UpdateValueKeepOrder].
 func (m *Map[K, V]) Set(key K, value V) {
+	mustNotFrozen(m.checkFrozen("Set"))
+
 	m.set(key, value, m.Has(key))
 }
 
@@ -149,8 +228,14 @@ func (m *Map[K, V]) Set(key K, value V) {
 // If the key is already exist in map, the behavior is controlled by
 // [Map.DuplicatedKeyStrategy].
 func (m *Map[K, V]) Add(key K, value V) {
+	mustNotFrozen(m.checkFrozen("Add"))
+
 	var alreadyExist bool
 
+	if m.duplicates != nil {
+		m.recordDuplicate(key, value)
+	}
+
 	switch m.duplicatedKeyStrategy {
 	default:
 		fallthrough
@@ -184,19 +269,129 @@ func (m *Map[K, V]) Add(key K, value V) {
 	m.set(key, value, alreadyExist)
 }
 
+// recordDuplicate records the pair will be discarded if key is already exist.
+func (m *Map[K, V]) recordDuplicate(key K, value V) {
+	if oldValue, exist := m.Get(key); exist {
+		m.recordDiscarded(key, value, oldValue)
+	}
+}
+
+// recordDiscarded records the discarded one of value and oldValue of key,
+// which is decided by [Map.DuplicatedKeyStrategy].
+func (m *Map[K, V]) recordDiscarded(key K, value, oldValue V) {
+	switch m.duplicatedKeyStrategy {
+	case KeepValueUpdateOrder, Ignore:
+		m.duplicates.Add(key, value)
+	default:
+		m.duplicates.Add(key, oldValue)
+	}
+}
+
 // Append a series of kv pairs into map.
 //
 // The effect is consistent with calling [Map.Add](k, v) multi times.
 func (m *Map[K, V]) Append(pairs ...Pair[K, V]) {
+	mustNotFrozen(m.checkFrozen("Append"))
+
 	for _, pair := range pairs {
 		m.Add(pair.Key, pair.Value)
 	}
 }
 
+// InsertPairs inserts a series of kv pairs into map as a contiguous block,
+// placed before the key at index of current order. An index equal to
+// [Map.Len] places them at the end, like [Map.Append].
+//
+// Pairs are processed one by one, like calling [Map.Add] with them, but new
+// keys go into the block instead of the end of map. So if a key already
+// exists, in the map or earlier in pairs, the behavior is controlled by
+// [Map.DuplicatedKeyStrategy]:
+//
+//   - [UpdateValueKeepOrder]: the value is updated in its current position,
+//     which may be outside the block.
+//   - [UpdateValueUpdateOrder]: the key is moved to the end of block, with the
+//     new value.
+//   - [KeepValueUpdateOrder]: the key is moved to the end of block, with the
+//     old value.
+//   - [Ignore]: the pair is ignored.
+//
+// Discarded pairs are recorded if [Map.SetRecordDuplicates] is enabled.
+//
+// Performance: O(n + k*k) for k pairs, the map is shifted only once. An
+// error is returned if index is out of range [0, Len()], the map is not
+// changed in this case.
+func (m *Map[K, V]) InsertPairs(index int, pairs ...Pair[K, V]) error {
+	mustNotFrozen(m.checkFrozen("InsertPairs"))
+
+	if index < 0 || index > m.Len() {
+		return fmt.Errorf("geko: insert index %d out of range [0, %d]", index, m.Len())
+	}
+
+	block := NewMap[K, V]()
+	block.duplicatedKeyStrategy = m.duplicatedKeyStrategy
+	moved := make(map[K]struct{})
+
+	for _, pair := range pairs {
+		// moved keys are in block, so the map is only checked for others
+		oldValue, exist := block.Get(pair.Key)
+		if !exist {
+			oldValue, exist = m.Get(pair.Key)
+		}
+
+		if exist && m.duplicates != nil {
+			m.recordDiscarded(pair.Key, pair.Value, oldValue)
+		}
+
+		switch {
+		case !exist || block.Has(pair.Key):
+			block.Add(pair.Key, pair.Value)
+		case m.duplicatedKeyStrategy == UpdateValueUpdateOrder:
+			moved[pair.Key] = struct{}{}
+			block.Set(pair.Key, pair.Value)
+		case m.duplicatedKeyStrategy == KeepValueUpdateOrder:
+			moved[pair.Key] = struct{}{}
+			block.Set(pair.Key, oldValue)
+		case m.duplicatedKeyStrategy != Ignore:
+			m.inner[pair.Key] = pair.Value
+		}
+	}
+
+	if block.Len() == 0 {
+		return nil
+	}
+
+	order := make([]K, 0, len(m.order)-len(moved)+block.Len())
+	order = appendKeysExcept(order, m.order[:index], moved)
+	order = append(order, block.order...)
+	order = appendKeysExcept(order, m.order[index:], moved)
+	m.order = order
+
+	if m.inner == nil {
+		m.inner = make(map[K]V, block.Len())
+	}
+	for key, value := range block.inner {
+		m.inner[key] = value
+	}
+	m.mods++
+
+	return nil
+}
+
+func appendKeysExcept[K comparable](dst, keys []K, except map[K]struct{}) []K {
+	for _, key := range keys {
+		if _, skip := except[key]; !skip {
+			dst = append(dst, key)
+		}
+	}
+	return dst
+}
+
 // Delete a item by key.
 //
 // Performance: causes O(n) operation, avoid heavy use.
 func (m *Map[K, V]) Delete(key K) {
+	mustNotFrozen(m.checkFrozen("Delete"))
+
 	_, exist := m.inner[key]
 	if !exist {
 		return
@@ -210,6 +405,7 @@ func (m *Map[K, V]) Delete(key K) {
 	}
 
 	delete(m.inner, key)
+	m.mods++
 }
 
 // DeleteByIndex delete a item by it's index in order.
@@ -218,15 +414,21 @@ func (m *Map[K, V]) Delete(key K) {
 //
 // Performance: causes O(n) operation, avoid heavy use.
 func (m *Map[K, V]) DeleteByIndex(index int) {
+	mustNotFrozen(m.checkFrozen("DeleteByIndex"))
+
 	key := m.order[index]
 	m.order = append(m.order[:index], m.order[index+1:]...)
 	delete(m.inner, key)
+	m.mods++
 }
 
 // Clear this map.
 func (m *Map[K, V]) Clear() {
+	mustNotFrozen(m.checkFrozen("Clear"))
+
 	m.order = nil
 	m.inner = nil
+	m.mods++
 }
 
 // Len returns the size of map.
@@ -278,6 +480,8 @@ func (m *Map[K, V]) Pairs() *Pairs[K, V] {
 
 // Sort will reorder the map using the given less function.
 func (m *Map[K, V]) Sort(lessFunc PairLessFunc[K, V]) {
+	mustNotFrozen(m.checkFrozen("Sort"))
+
 	pairs := m.Pairs()
 
 	pairs.Sort(lessFunc)
@@ -285,6 +489,7 @@ func (m *Map[K, V]) Sort(lessFunc PairLessFunc[K, V]) {
 	for i, length := 0, m.Len(); i < length; i++ {
 		m.order[i] = pairs.List[i].Key
 	}
+	m.mods++
 }
 
 // Filter remove all item which make pred func return false.
@@ -292,6 +497,8 @@ func (m *Map[K, V]) Sort(lessFunc PairLessFunc[K, V]) {
 // Performance: O(n) operation. More efficient then [Map.GetByIndex] +
 // [Map.DeleteByIndex] in a loop, which is O(n^2).
 func (m *Map[K, V]) Filter(pred PairFilterFunc[K, V]) {
+	mustNotFrozen(m.checkFrozen("Filter"))
+
 	n := 0
 	for i, length := 0, m.Len(); i < length; i++ {
 		pair := m.GetByIndex(i)
@@ -300,11 +507,87 @@ func (m *Map[K, V]) Filter(pred PairFilterFunc[K, V]) {
 			n++
 		} else {
 			delete(m.inner, pair.Key)
+			m.mods++
 		}
 	}
 	m.order = m.order[:n]
 }
 
+// rangeChildren calls fn for every kv pair in current order, see [Walk].
+func (m *Map[K, V]) rangeChildren(fn func(key, value any) error) error {
+	if m == nil {
+		return nil
+	}
+
+	for i := 0; i < m.Len(); i++ {
+		pair := m.GetByIndex(i)
+		if err := fn(pair.Key, pair.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformChildren replaces every value by fn in current order, or deletes
+// it if fn says so, see [Transform].
+func (m *Map[K, V]) transformChildren(fn func(key, value any) (any, bool, error)) error {
+	if m == nil {
+		return nil
+	}
+
+	kept := m.order[:0]
+	for i, key := range m.order {
+		v, remove, err := fn(key, m.inner[key])
+		if err == nil && !remove {
+			if value, ok := assignable[V](v); ok {
+				m.inner[key] = value
+			} else {
+				err = &assignError{key: key, value: v, container: m}
+			}
+		}
+
+		if err != nil {
+			m.order = append(kept, m.order[i:]...)
+			return err
+		}
+
+		if remove {
+			delete(m.inner, key)
+			m.mods++
+			continue
+		}
+
+		kept = append(kept, key)
+	}
+	m.order = kept
+
+	return nil
+}
+
+// toStdTypes converts the map into a map[string]any, see [ToStdTypes].
+func (m *Map[K, V]) toStdTypes() any {
+	if m == nil {
+		return nil
+	}
+
+	result := make(map[string]any, m.Len())
+	for i := 0; i < m.Len(); i++ {
+		pair := m.GetByIndex(i)
+		result[stdKey(pair.Key)] = ToStdTypes(pair.Value)
+	}
+
+	return result
+}
+
+func (m *Map[K, V]) encodeJSON(e *encodeState) error {
+	if m == nil {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+	return encodeObject[K, V](e, m)
+}
+
 // MarshalJSON implements [json.Marshaler] interface.
 //
 // You should not call this directly, use [json.Marshal] instead.
@@ -317,9 +600,15 @@ func (m Map[K, V]) MarshalJSON() ([]byte, error) {
 // You shouldn't call this directly, use [json.Unmarshal]/[JSONUnmarshal]
 // instead.
 func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	if err := m.checkFrozen("UnmarshalJSON"); err != nil {
+		return err
+	}
+
 	return unmarshalObject[K, V](
 		data, m,
 		UseObject(),
 		ObjectOnDuplicatedKey(m.duplicatedKeyStrategy),
+		RecordDuplicates(m.RecordDuplicates()),
+		Lenient(m.lenient),
 	)
 }
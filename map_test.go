@@ -2,10 +2,12 @@ package geko_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/7sDream/geko"
@@ -45,6 +47,36 @@ func TestMap_NewWithCapacity(t *testing.T) {
 	}
 }
 
+func TestMap_NewOf(t *testing.T) {
+	m := geko.NewMapOf(geko.P("b", 1), geko.P("a", 2), geko.P("c", 3), geko.P("b", 4))
+
+	output, _ := json.Marshal(m)
+	if string(output) != `{"b":4,"a":2,"c":3}` || m.DuplicatedKeyStrategy() != geko.UpdateValueKeepOrder {
+		t.Fatalf("NewMapOf result not correct: %s", string(output))
+	}
+
+	for _, c := range []struct {
+		strategy geko.DuplicatedKeyStrategy
+		excepted string
+	}{
+		{geko.UpdateValueKeepOrder, `{"b":4,"a":2,"c":3}`},
+		{geko.UpdateValueUpdateOrder, `{"a":2,"c":3,"b":4}`},
+		{geko.KeepValueUpdateOrder, `{"a":2,"c":3,"b":1}`},
+		{geko.Ignore, `{"b":1,"a":2,"c":3}`},
+	} {
+		m = geko.NewMapOfStrategy(c.strategy, geko.P("b", 1), geko.P("a", 2), geko.P("c", 3), geko.P("b", 4))
+
+		output, _ = json.Marshal(m)
+		if string(output) != c.excepted || m.DuplicatedKeyStrategy() != c.strategy {
+			t.Fatalf("NewMapOfStrategy with strategy %d result not correct: %s", c.strategy, string(output))
+		}
+	}
+
+	if m = geko.NewMapOf[string, int](); m.Len() != 0 {
+		t.Fatalf("NewMapOf without pairs should be empty")
+	}
+}
+
 func TestMap_Get(t *testing.T) {
 	m := geko.NewMap[string, int]()
 	m.Set("one", 1)
@@ -310,6 +342,79 @@ func TestMap_Append(t *testing.T) {
 	}
 }
 
+func TestMap_InsertPairs(t *testing.T) {
+	for _, c := range []struct {
+		strategy   geko.DuplicatedKeyStrategy
+		excepted   string
+		duplicates string
+	}{
+		{geko.UpdateValueKeepOrder, `{"id":1,"x":30,"y":20,"a":10,"b":3}`, `{"a":2,"x":10}`},
+		{geko.UpdateValueUpdateOrder, `{"id":1,"a":10,"y":20,"x":30,"b":3}`, `{"a":2,"x":10}`},
+		{geko.KeepValueUpdateOrder, `{"id":1,"a":2,"y":20,"x":10,"b":3}`, `{"a":10,"x":30}`},
+		{geko.Ignore, `{"id":1,"x":10,"y":20,"a":2,"b":3}`, `{"a":10,"x":30}`},
+	} {
+		m := geko.NewMapOf(geko.P("id", 1), geko.P("a", 2), geko.P("b", 3))
+		m.SetDuplicatedKeyStrategy(c.strategy)
+		m.SetRecordDuplicates(true)
+
+		err := m.InsertPairs(1, geko.P("x", 10), geko.P("a", 10), geko.P("y", 20), geko.P("x", 30))
+		if err != nil {
+			t.Fatalf("InsertPairs with error: %s", err.Error())
+		}
+
+		output, _ := json.Marshal(m)
+		if string(output) != c.excepted {
+			t.Fatalf("InsertPairs with strategy %d result not correct: %s", c.strategy, string(output))
+		}
+
+		output, _ = json.Marshal(m.Duplicates())
+		if string(output) != c.duplicates {
+			t.Fatalf("InsertPairs with strategy %d duplicates not correct: %s", c.strategy, string(output))
+		}
+	}
+}
+
+func TestMap_InsertPairs_Index(t *testing.T) {
+	m := geko.NewMap[string, int]()
+
+	for _, c := range []struct {
+		index    int
+		pairs    []geko.Pair[string, int]
+		excepted string
+	}{
+		{0, []geko.Pair[string, int]{{"b", 2}}, `{"b":2}`},
+		{0, []geko.Pair[string, int]{{"a", 1}}, `{"a":1,"b":2}`},
+		{2, []geko.Pair[string, int]{{"d", 4}}, `{"a":1,"b":2,"d":4}`},
+		{2, []geko.Pair[string, int]{{"c", 3}, {"b", 5}}, `{"a":1,"b":5,"c":3,"d":4}`},
+		{1, nil, `{"a":1,"b":5,"c":3,"d":4}`},
+		{1, []geko.Pair[string, int]{{"a", 0}}, `{"a":0,"b":5,"c":3,"d":4}`},
+	} {
+		if err := m.InsertPairs(c.index, c.pairs...); err != nil {
+			t.Fatalf("InsertPairs with error: %s", err.Error())
+		}
+
+		output, _ := json.Marshal(m)
+		if string(output) != c.excepted {
+			t.Fatalf("InsertPairs at %d result not correct: %s", c.index, string(output))
+		}
+	}
+
+	for _, index := range []int{-1, 5} {
+		err := m.InsertPairs(index, geko.P("e", 5))
+		if err == nil || m.Len() != 4 {
+			t.Fatalf("InsertPairs at invalid index %d should fail", index)
+		}
+	}
+
+	m.SetDuplicatedKeyStrategy(geko.UpdateValueUpdateOrder)
+	_ = m.InsertPairs(4, geko.P("a", 1))
+	_ = m.InsertPairs(0, geko.P("d", 4))
+	output, _ := json.Marshal(m)
+	if string(output) != `{"d":4,"b":5,"c":3,"a":1}` {
+		t.Fatalf("InsertPairs move key result not correct: %s", string(output))
+	}
+}
+
 func TestMap_Delete(t *testing.T) {
 	m := geko.NewMap[string, int]()
 	m.Set("a", 1)
@@ -574,9 +679,70 @@ func TestMap_MarshalJSON_ValueError(t *testing.T) {
 	m := geko.NewMap[string, any]()
 	m.Add("invalid", json.Number("invalid"))
 
-	if _, err := json.Marshal(m); err == nil {
+	_, err := json.Marshal(m)
+	if err == nil {
 		t.Fatalf("Marshal invalid number do not error")
 	}
+
+	var pathErr *geko.MarshalError
+	if !errors.As(err, &pathErr) || !reflect.DeepEqual(pathErr.Path, []any{"invalid"}) {
+		t.Fatalf("Marshal error should have the key path: %#v", err)
+	}
+
+	if !strings.Contains(err.Error(), `geko: marshal failed at "invalid": `) {
+		t.Fatalf("Marshal error message should contain the key path: %s", err.Error())
+	}
+}
+
+func TestMap_MarshalJSON_NestedValueError(t *testing.T) {
+	item := geko.NewMap[string, any]()
+	item.Set("name", "apple")
+	item.Set("price", json.Number("1..5"))
+
+	m := geko.NewMap[string, any]()
+	m.Set("total", 3)
+	m.Set("items", geko.NewListFrom([]any{nil, "x", geko.NewPairsOf(geko.P("first", item))}))
+
+	_, err := geko.JSONMarshal(m, geko.SortKeys(true))
+
+	var pathErr *geko.MarshalError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("Marshal error should be a MarshalError: %#v", err)
+	}
+
+	// message of std lib error differs between Go versions
+	excepted := `geko: marshal failed at "items"[2]."first"."price": `
+	if !strings.HasPrefix(err.Error(), excepted) {
+		t.Fatalf("Marshal error message not correct: %s", err.Error())
+	}
+
+	if errors.Unwrap(err) != pathErr.Err || !strings.Contains(pathErr.Err.Error(), "1..5") {
+		t.Fatalf("Original error should be reachable: %#v", pathErr.Err)
+	}
+}
+
+func BenchmarkMap_MarshalJSON_Small(b *testing.B) {
+	m := geko.NewMapWithCapacity[string, any](10)
+	for i := 0; i < 10; i++ {
+		m.Set("key"+strconv.Itoa(i), i)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = m.MarshalJSON()
+	}
+}
+
+func BenchmarkMap_MarshalJSON_Wide(b *testing.B) {
+	m := geko.NewMapWithCapacity[string, any](10_000)
+	for i := 0; i < 10_000; i++ {
+		m.Set("key"+strconv.Itoa(i), i)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = m.MarshalJSON()
+	}
 }
 
 func TestMap_MarshalJSON_EmptyMap(t *testing.T) {
@@ -760,6 +926,147 @@ func TestMap_UnmarshalJSON_DuplicatedKey(t *testing.T) {
 	}
 }
 
+func skippedPaths(t *testing.T, err error) []string {
+	var multiErr *geko.MultiDecodeError
+	if !errors.As(err, &multiErr) {
+		t.Fatalf("Lenient unmarshal should fail with MultiDecodeError, got %#v", err)
+	}
+
+	paths := make([]string, 0, len(multiErr.Errors))
+	for _, valueErr := range multiErr.Errors {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(valueErr, &typeErr) {
+			t.Fatalf("Skipped value error is not from std lib: %#v", valueErr.Err)
+		}
+		paths = append(paths, valueErr.Path)
+	}
+
+	return paths
+}
+
+func TestMap_UnmarshalJSON_Lenient(t *testing.T) {
+	m := geko.NewMap[string, int]()
+	m.SetLenient(true)
+	if !m.Lenient() {
+		t.Fatalf("Lenient should be enabled")
+	}
+
+	err := json.Unmarshal([]byte(`{"a": 1, "b": "x", "c": 3, "d": [1], "e/f": {}}`), &m)
+
+	excepted := []string{"/b", "/d", "/e~1f"}
+	if paths := skippedPaths(t, err); !reflect.DeepEqual(paths, excepted) {
+		t.Fatalf("Skipped paths not correct: %#v", paths)
+	}
+
+	output, _ := json.Marshal(m)
+	if string(output) != `{"a":1,"c":3}` {
+		t.Fatalf("Lenient unmarshal result not correct: %s", string(output))
+	}
+
+	if err = json.Unmarshal([]byte(`{"g": 7}`), &m); err != nil {
+		t.Fatalf("Lenient unmarshal without skipped value should not fail: %s", err.Error())
+	}
+
+	// errors break the input are not skipped
+	for _, data := range []string{`{"x": "a", "y": [1 2]}`, `{"x": "a", "y": [1`} {
+		broken := geko.NewMap[string, int]()
+		broken.SetLenient(true)
+
+		if err = broken.UnmarshalJSON([]byte(data)); err == nil {
+			t.Fatalf("Unmarshal %s should fail", data)
+		}
+
+		var multiErr *geko.MultiDecodeError
+		if errors.As(err, &multiErr) {
+			t.Fatalf("Unmarshal %s should not be skipped: %s", data, err.Error())
+		}
+	}
+}
+
+func TestMap_UnmarshalJSON_Strict(t *testing.T) {
+	m := geko.NewMap[string, int]()
+
+	err := json.Unmarshal([]byte(`{"a": 1, "b": "x", "c": 3}`), &m)
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Strict unmarshal should fail with UnmarshalTypeError, got %#v", err)
+	}
+}
+
+func TestMap_UnmarshalJSON_RecordDuplicates(t *testing.T) {
+	cases := []struct {
+		strategy         geko.DuplicatedKeyStrategy
+		exceptedKeys     []string
+		exceptedValues   []int
+		exceptedDiscards []int
+	}{
+		{geko.UpdateValueKeepOrder, []string{"a", "b"}, []int{3, 2}, []int{1}},
+		{geko.UpdateValueUpdateOrder, []string{"b", "a"}, []int{2, 3}, []int{1}},
+		{geko.KeepValueUpdateOrder, []string{"b", "a"}, []int{2, 1}, []int{3}},
+		{geko.Ignore, []string{"a", "b"}, []int{1, 2}, []int{3}},
+	}
+
+	for _, tt := range cases {
+		m := geko.NewMap[string, int]()
+		m.SetDuplicatedKeyStrategy(tt.strategy)
+		m.SetRecordDuplicates(true)
+
+		if err := json.Unmarshal([]byte(`{"a":1,"b":2,"a":3}`), &m); err != nil {
+			t.Fatalf("Strategy %#v, unmarshal error: %s", tt.strategy, err.Error())
+		}
+
+		if !reflect.DeepEqual(m.Keys(), tt.exceptedKeys) || !reflect.DeepEqual(m.Values(), tt.exceptedValues) {
+			t.Fatalf("For strategy %#v, map not correct: %#v, %#v", tt.strategy, m.Keys(), m.Values())
+		}
+
+		duplicates := m.Duplicates()
+		if !reflect.DeepEqual(duplicates.Keys(), []string{"a"}) ||
+			!reflect.DeepEqual(duplicates.Values(), tt.exceptedDiscards) {
+			t.Fatalf("For strategy %#v, duplicates not correct: %#v", tt.strategy, duplicates.List)
+		}
+	}
+}
+
+func TestMap_UnmarshalJSON_RecordDuplicates_Nested(t *testing.T) {
+	m := geko.NewMap[string, any]()
+	m.SetRecordDuplicates(true)
+
+	data := `{"a": {"x": 1, "x": 2}, "b": [{"y": 1, "y": 2, "y": 3}], "a": {}}`
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("Unmarshal error: %s", err.Error())
+	}
+
+	if !m.RecordDuplicates() || m.Duplicates().Len() != 1 {
+		t.Fatalf("Duplicates of outer map not correct: %#v", m.Duplicates().List)
+	}
+
+	inner := m.Duplicates().GetByIndex(0).Value.(geko.Object)
+	if !reflect.DeepEqual(inner.Duplicates().Values(), []any{1.0}) {
+		t.Fatalf("Duplicates of discarded inner map not correct: %#v", inner.Duplicates().List)
+	}
+
+	nested := m.GetOrZeroValue("b").(geko.Array).Get(0).(geko.Object)
+	if !reflect.DeepEqual(nested.Duplicates().Values(), []any{1.0, 2.0}) {
+		t.Fatalf("Duplicates of nested map not correct: %#v", nested.Duplicates().List)
+	}
+
+	m.SetRecordDuplicates(false)
+	if m.RecordDuplicates() || m.Duplicates() != nil {
+		t.Fatalf("Disable record duplicates should clear recorded pairs")
+	}
+
+	m.Add("a", 1)
+	if m.Duplicates() != nil {
+		t.Fatalf("Should not record duplicates when disabled")
+	}
+
+	v, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "a": 2}`), geko.UseObject(), geko.RecordDuplicates(true))
+	if !reflect.DeepEqual(v.(geko.Object).Duplicates().Values(), []any{1.0}) {
+		t.Fatalf("Duplicates recorded by decode option not correct: %#v", v)
+	}
+}
+
 func TestMap_UnmarshalJSON_InnerValueUseOurType(t *testing.T) {
 	cases := []struct {
 		strategy       geko.DuplicatedKeyStrategy
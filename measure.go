@@ -0,0 +1,138 @@
+package geko
+
+import (
+	"encoding/json"
+	"fmt"
+	"unsafe"
+)
+
+// Stats is structural statistics of a value, returned by [Measure].
+type Stats struct {
+	// MaxDepth is the maximum nesting depth of containers. It's 0 for a
+	// scalar, 1 for an object or array without containers inside.
+	MaxDepth int
+	// Nodes is the number of all values, including containers and root.
+	Nodes int
+	// Members is the number of object members, every member of a duplicated
+	// key is counted.
+	Members int
+	// Items is the number of array items.
+	Items int
+	// DistinctKeys is the number of distinct object keys.
+	DistinctKeys int
+	// StringBytes is the total length in bytes of all strings, including
+	// object keys, counted per occurrence.
+	StringBytes int
+	// MemoryBytes is a rough estimate of memory used by the value, including
+	// container structures, boxed numbers and string data, but not unused
+	// capacity and allocator overhead.
+	MemoryBytes int
+}
+
+// String implements [fmt.Stringer] interface, for logs.
+func (s Stats) String() string {
+	return fmt.Sprintf(
+		"depth %d, nodes %d, members %d, items %d, distinct keys %d, string bytes %d, memory bytes %d",
+		s.MaxDepth, s.Nodes, s.Members, s.Items, s.DistinctKeys, s.StringBytes, s.MemoryBytes,
+	)
+}
+
+const (
+	measureInterfaceBytes = int(unsafe.Sizeof(any(nil)))
+	measureStringBytes    = int(unsafe.Sizeof(""))
+	measureFloatBytes     = int(unsafe.Sizeof(float64(0)))
+	measureMapBytes       = int(unsafe.Sizeof(Map[string, any]{}))
+	measurePairsBytes     = int(unsafe.Sizeof(Pairs[string, any]{}))
+	measurePairBytes      = int(unsafe.Sizeof(Pair[string, any]{}))
+	measureListBytes      = int(unsafe.Sizeof(List[any]{}))
+	measureSliceBytes     = int(unsafe.Sizeof([]any(nil)))
+	// key and value in map bucket, and key in order slice
+	measureMapEntryBytes = 2*measureStringBytes + measureInterfaceBytes
+	measureStdEntryBytes = measureStringBytes + measureInterfaceBytes
+)
+
+// Measure computes structural statistics of v in a single walk, see [Stats]
+// for detail.
+//
+// [Object], [ObjectItems], [Array], map[string]any and []any are descended
+// into. Other values are leaves.
+func Measure(v any) Stats {
+	m := measurer{keys: make(map[string]struct{})}
+	m.value(v, 0)
+	m.stats.DistinctKeys = len(m.keys)
+	return m.stats
+}
+
+type measurer struct {
+	stats Stats
+	keys  map[string]struct{}
+}
+
+// value measures v, depth is the number of containers v is inside.
+func (m *measurer) value(v any, depth int) {
+	m.stats.Nodes++
+
+	if isNull(v) {
+		return
+	}
+
+	switch x := v.(type) {
+	case string:
+		m.stats.StringBytes += len(x)
+		m.stats.MemoryBytes += len(x)
+	case json.Number:
+		m.stats.MemoryBytes += len(x)
+	case float64:
+		m.stats.MemoryBytes += measureFloatBytes
+	case Object:
+		m.enter(depth)
+		m.stats.MemoryBytes += measureMapBytes + x.Len()*measureMapEntryBytes
+		for i := 0; i < x.Len(); i++ {
+			pair := x.GetByIndex(i)
+			m.member(pair.Key, pair.Value, depth)
+		}
+	case ObjectItems:
+		m.enter(depth)
+		m.stats.MemoryBytes += measurePairsBytes + x.Len()*measurePairBytes
+		for _, pair := range x.List {
+			m.member(pair.Key, pair.Value, depth)
+		}
+	case map[string]any:
+		m.enter(depth)
+		m.stats.MemoryBytes += len(x) * measureStdEntryBytes
+		for key, value := range x {
+			m.member(key, value, depth)
+		}
+	case Array:
+		m.enter(depth)
+		m.stats.MemoryBytes += measureListBytes
+		m.items(x.List, depth)
+	case []any:
+		m.enter(depth)
+		m.stats.MemoryBytes += measureSliceBytes
+		m.items(x, depth)
+	}
+}
+
+// enter records a container inside depth containers.
+func (m *measurer) enter(depth int) {
+	if depth+1 > m.stats.MaxDepth {
+		m.stats.MaxDepth = depth + 1
+	}
+}
+
+func (m *measurer) member(key string, value any, depth int) {
+	m.stats.Members++
+	m.stats.StringBytes += len(key)
+	m.stats.MemoryBytes += len(key)
+	m.keys[key] = struct{}{}
+	m.value(value, depth+1)
+}
+
+func (m *measurer) items(items []any, depth int) {
+	m.stats.Items += len(items)
+	m.stats.MemoryBytes += len(items) * measureInterfaceBytes
+	for _, item := range items {
+		m.value(item, depth+1)
+	}
+}
@@ -0,0 +1,78 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestMeasure(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{
+		"name": "abc",
+		"list": [1, [true, null], {"name": "de"}],
+		"dup": {"k": 1, "k": "xy"},
+		"empty": {}
+	}`))
+
+	stats := geko.Measure(doc)
+	if stats.MaxDepth != 3 || stats.Nodes != 13 || stats.Members != 7 || stats.Items != 5 ||
+		stats.DistinctKeys != 5 || stats.StringBytes != 29 {
+		t.Fatalf("Measure result not correct: %s", stats)
+	}
+
+	// same structure in other types
+	object, _ := geko.JSONUnmarshal([]byte(`{"name": "abc", "list": [1, [true, null], {"name": "de"}]}`),
+		geko.UseObject(), geko.UseNumber(true))
+	std := map[string]any{"name": "abc", "list": []any{1.0, []any{true, nil}, map[string]any{"name": "de"}}}
+
+	for _, v := range []any{object, std} {
+		stats = geko.Measure(v)
+		if stats.MaxDepth != 3 || stats.Nodes != 9 || stats.Members != 3 || stats.Items != 5 ||
+			stats.DistinctKeys != 2 || stats.StringBytes != 17 {
+			t.Fatalf("Measure result not correct: %s", stats)
+		}
+	}
+}
+
+func TestMeasure_Memory(t *testing.T) {
+	if stats := geko.Measure("abc"); stats.MemoryBytes != 3 || stats.MaxDepth != 0 || stats.Nodes != 1 {
+		t.Fatalf("Measure of string not correct: %s", stats)
+	}
+
+	if stats := geko.Measure(1.0); stats.MemoryBytes != 8 {
+		t.Fatalf("Measure of number not correct: %s", stats)
+	}
+
+	if stats := geko.Measure(json.Number("123")); stats.MemoryBytes != 3 || stats.StringBytes != 0 {
+		t.Fatalf("Measure of json.Number not correct: %s", stats)
+	}
+
+	var m geko.Object
+	if stats := geko.Measure(m); stats.MemoryBytes != 0 || stats.MaxDepth != 0 || stats.Nodes != 1 {
+		t.Fatalf("Measure of nil object not correct: %s", stats)
+	}
+
+	small := geko.Measure(geko.NewListFrom([]any{"a"}))
+	large := geko.Measure(geko.NewListFrom([]any{"a", "b"}))
+	if small.MemoryBytes <= 1 || large.MemoryBytes <= small.MemoryBytes {
+		t.Fatalf("Measure of memory not correct: %s, %s", small, large)
+	}
+}
+
+func TestStats_String(t *testing.T) {
+	stats := geko.Stats{
+		MaxDepth:     1,
+		Nodes:        2,
+		Members:      3,
+		Items:        4,
+		DistinctKeys: 5,
+		StringBytes:  6,
+		MemoryBytes:  7,
+	}
+
+	excepted := "depth 1, nodes 2, members 3, items 4, distinct keys 5, string bytes 6, memory bytes 7"
+	if stats.String() != excepted {
+		t.Fatalf("Stats string not correct: %s", stats.String())
+	}
+}
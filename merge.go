@@ -0,0 +1,149 @@
+package geko
+
+import "fmt"
+
+// ArrayMergeStrategy controls how [DeepMerge] merges two arrays. Default
+// strategy is [ArrayReplace].
+type ArrayMergeStrategy uint8
+
+const (
+	// ArrayReplace uses the array in src, and discards the one in dst.
+	//
+	// [{"a": 1}, 2] + [{"b": 3}] => [{"b": 3}]
+	//
+	// This is the default strategy.
+	ArrayReplace ArrayMergeStrategy = iota
+	// ArrayConcat appends items in src to the array in dst.
+	//
+	// [{"a": 1}, 2] + [{"b": 3}] => [{"a": 1}, 2, {"b": 3}]
+	ArrayConcat
+	// ArrayMergeByIndex merges items at the same index, like [DeepMerge] does
+	// for values, extra items in src are appended.
+	//
+	// [{"a": 1}, 2] + [{"b": 3}] => [{"a": 1, "b": 3}, 2]
+	ArrayMergeByIndex
+)
+
+// MergeOptions are options for controlling the behavior of [DeepMerge].
+//
+// Default value (created by [CreateMergeOptions]) of it is:
+//
+//   - Arrays are merged by [ArrayReplace] strategy.
+//
+// See also: [CreateMergeOptions], [MergeArrays].
+type MergeOptions struct {
+	arrayStrategy ArrayMergeStrategy
+}
+
+// MergeOption is atom/modifier of [MergeOptions].
+type MergeOption func(opts *MergeOptions)
+
+// CreateMergeOptions creates a [MergeOptions] by apply all option to the
+// default merge option.
+func CreateMergeOptions(option ...MergeOption) MergeOptions {
+	opts := MergeOptions{}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *MergeOptions) Apply(option ...MergeOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// MergeArrays specifies the strategy used by [DeepMerge] when both values are
+// arrays. See document of [ArrayMergeStrategy] and its enum value for detail.
+func MergeArrays(strategy ArrayMergeStrategy) MergeOption {
+	return func(opts *MergeOptions) {
+		opts.arrayStrategy = strategy
+	}
+}
+
+// DeepMerge merges src into dst recursively, and returns the result, like
+// layering a config file over a base one:
+//
+//   - If both values are objects, members in src are merged into dst one by
+//     one. A key in both keeps its position in dst, and their values are
+//     merged recursively. A key only in src is added to the end, in order of
+//     src.
+//   - If both values are arrays, they are merged by the strategy set by
+//     [MergeArrays].
+//   - Otherwise, the value in src is used, including null.
+//
+// Objects in dst can be [Object] or [ObjectItems], and the result keeps its
+// type. For an [ObjectItems], a key in src is merged into the first member
+// with the same key. Arrays in dst must be [Array]. Objects and arrays in src
+// can also be map[string]any and []any, members of a map[string]any are
+// merged in sorted order. Other values in dst, including map[string]any and
+// []any, are replaced as a whole.
+//
+// Neither dst nor src is modified, the result is built on a deep copy of
+// dst, and values from src are deep copied like [ApplyPatch] does.
+//
+// An error is returned if the array merge strategy is unknown.
+func DeepMerge(dst, src any, option ...MergeOption) (any, error) {
+	opts := CreateMergeOptions(option...)
+
+	if opts.arrayStrategy > ArrayMergeByIndex {
+		return nil, fmt.Errorf("geko: unknown array merge strategy %d", opts.arrayStrategy)
+	}
+
+	m := merger{opts: opts}
+	return m.merge(cloneValue(dst), src), nil
+}
+
+type merger struct {
+	opts MergeOptions
+}
+
+// merge merges src into dst, which is a copy and can be modified in place.
+func (m *merger) merge(dst, src any) any {
+	if isNull(dst) || isNull(src) {
+		return cloneValue(src)
+	}
+
+	if members, isObject := objectMembers(src); isObject {
+		switch d := dst.(type) {
+		case Object:
+			for _, pair := range members {
+				if value, exist := d.Get(pair.Key); exist {
+					d.Set(pair.Key, m.merge(value, pair.Value))
+				} else {
+					d.Set(pair.Key, cloneValue(pair.Value))
+				}
+			}
+			return d
+		case ObjectItems:
+			for _, pair := range members {
+				if index := firstIndexOfKey(d, pair.Key); index >= 0 {
+					d.List[index].Value = m.merge(d.List[index].Value, pair.Value)
+				} else {
+					d.Add(pair.Key, cloneValue(pair.Value))
+				}
+			}
+			return d
+		}
+	}
+
+	if items, isArray := arrayItems(src); isArray && m.opts.arrayStrategy != ArrayReplace {
+		if d, ok := dst.(Array); ok {
+			return m.mergeArray(d, items)
+		}
+	}
+
+	return cloneValue(src)
+}
+
+func (m *merger) mergeArray(dst Array, src []any) Array {
+	for i, item := range src {
+		if m.opts.arrayStrategy == ArrayMergeByIndex && i < dst.Len() {
+			dst.Set(i, m.merge(dst.Get(i), item))
+		} else {
+			dst.Append(cloneValue(item))
+		}
+	}
+
+	return dst
+}
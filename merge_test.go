@@ -0,0 +1,133 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestDeepMerge(t *testing.T) {
+	layers := []string{
+		`{"name": "app", "server": {"host": "0.0.0.0", "port": 80, "tls": {"enabled": false}}, "tags": ["base"]}`,
+		`{"server": {"port": 8080, "tls": {"enabled": true, "cert": "env.pem"}}, "debug": false, "tags": ["env"]}`,
+		`{"debug": true, "server": {"host": "127.0.0.1", "tls": null}, "local": {"x": 1}}`,
+	}
+
+	var result any
+	for i, layer := range layers {
+		value, _ := geko.JSONUnmarshal([]byte(layer), geko.UseObject())
+		if i == 0 {
+			result = value
+			continue
+		}
+
+		var err error
+		if result, err = geko.DeepMerge(result, value); err != nil {
+			t.Fatalf("DeepMerge with error: %s", err.Error())
+		}
+	}
+
+	output, _ := json.Marshal(result)
+	excepted := `{"name":"app","server":{"host":"127.0.0.1","port":8080,"tls":null},"tags":["env"],` +
+		`"debug":true,"local":{"x":1}}`
+	if string(output) != excepted {
+		t.Fatalf("DeepMerge result not correct: %s", string(output))
+	}
+}
+
+func TestDeepMerge_Arrays(t *testing.T) {
+	dst, _ := geko.JSONUnmarshal([]byte(`{"l": [{"a": 1}, 2], "m": [1]}`), geko.UseObject())
+	src, _ := geko.JSONUnmarshal([]byte(`{"l": [{"b": 3}], "m": {"x": 1}}`), geko.UseObject())
+
+	tests := []struct {
+		strategy geko.ArrayMergeStrategy
+		excepted string
+	}{
+		{geko.ArrayReplace, `{"l":[{"b":3}],"m":{"x":1}}`},
+		{geko.ArrayConcat, `{"l":[{"a":1},2,{"b":3}],"m":{"x":1}}`},
+		{geko.ArrayMergeByIndex, `{"l":[{"a":1,"b":3},2],"m":{"x":1}}`},
+	}
+
+	for _, test := range tests {
+		result, err := geko.DeepMerge(dst, src, geko.MergeArrays(test.strategy))
+		if err != nil {
+			t.Fatalf("DeepMerge with error: %s", err.Error())
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != test.excepted {
+			t.Fatalf("DeepMerge with strategy %d result not correct: %s", test.strategy, string(output))
+		}
+	}
+
+	result, _ := geko.DeepMerge(
+		geko.NewListFrom([]any{1}), []any{2, 3},
+		geko.MergeArrays(geko.ArrayMergeByIndex),
+	)
+	output, _ := json.Marshal(result)
+	if string(output) != `[2,3]` {
+		t.Fatalf("DeepMerge result not correct: %s", string(output))
+	}
+
+	output, _ = json.Marshal(dst)
+	if string(output) != `{"l":[{"a":1},2],"m":[1]}` {
+		t.Fatalf("DeepMerge should not modify dst: %s", string(output))
+	}
+}
+
+func TestDeepMerge_ObjectItems(t *testing.T) {
+	dst, _ := geko.JSONUnmarshal([]byte(`{"a": {"x": 1}, "b": 2, "a": 3}`))
+	src := map[string]any{"c": 4, "a": map[string]any{"y": 5}}
+
+	result, _ := geko.DeepMerge(dst, src)
+	output, _ := json.Marshal(result)
+	if string(output) != `{"a":{"x":1,"y":5},"b":2,"a":3,"c":4}` {
+		t.Fatalf("DeepMerge result not correct: %s", string(output))
+	}
+
+	// duplicated keys in src are merged in turn
+	src2, _ := geko.JSONUnmarshal([]byte(`{"b": {"x": 1}, "b": {"y": 2}}`))
+	result, _ = geko.DeepMerge(result, src2)
+	output, _ = json.Marshal(result)
+	if string(output) != `{"a":{"x":1,"y":5},"b":{"x":1,"y":2},"a":3,"c":4}` {
+		t.Fatalf("DeepMerge result not correct: %s", string(output))
+	}
+
+	output, _ = json.Marshal(dst)
+	if string(output) != `{"a":{"x":1},"b":2,"a":3}` {
+		t.Fatalf("DeepMerge should not modify dst: %s", string(output))
+	}
+}
+
+func TestDeepMerge_Values(t *testing.T) {
+	var m geko.Object
+
+	tests := []struct {
+		dst, src any
+		excepted string
+	}{
+		{nil, map[string]any{"a": 1}, `{"a":1}`},
+		{m, geko.NewMap[string, any](), `{}`},
+		{geko.NewMap[string, any](), nil, `null`},
+		{map[string]any{"a": 1}, map[string]any{"b": 2}, `{"b":2}`},
+		{1, "s", `"s"`},
+		{geko.NewListFrom([]any{1}), map[string]any{"b": 2}, `{"b":2}`},
+	}
+
+	for _, test := range tests {
+		result, err := geko.DeepMerge(test.dst, test.src)
+		if err != nil {
+			t.Fatalf("DeepMerge with error: %s", err.Error())
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != test.excepted {
+			t.Fatalf("DeepMerge result not correct: %s", string(output))
+		}
+	}
+
+	if result, err := geko.DeepMerge(nil, nil, geko.MergeArrays(100)); err == nil {
+		t.Fatalf("DeepMerge with unknown strategy should fail: %#v", result)
+	}
+}
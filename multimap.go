@@ -0,0 +1,192 @@
+package geko
+
+import "sort"
+
+// MultiMap is an ordered map which can hold multiple values for a key, like
+// HTTP headers.
+//
+// Keys are kept in the order they first appear, like [Map]. Values of a key
+// are kept in the order they are added. Each value also remembers its global
+// insertion order, so [MultiMap.Pairs] can flatten the multimap back into the
+// exact order all values were added, like a [Pairs].
+//
+// The zero value is an empty multimap ready to use.
+type MultiMap[K comparable, V any] struct {
+	m     Map[K, []multiMapEntry[V]]
+	seq   uint64
+	total int
+}
+
+type multiMapEntry[V any] struct {
+	seq   uint64
+	value V
+}
+
+// NewMultiMap creates a new empty [MultiMap].
+func NewMultiMap[K comparable, V any]() *MultiMap[K, V] {
+	return &MultiMap[K, V]{}
+}
+
+// NewMultiMapFrom creates a [MultiMap] contains all pairs in ps, added in
+// order. A nil ps results an empty multimap.
+func NewMultiMapFrom[K comparable, V any](ps *Pairs[K, V]) *MultiMap[K, V] {
+	mm := NewMultiMap[K, V]()
+	for i := 0; ps != nil && i < ps.Len(); i++ {
+		mm.Add(ps.List[i].Key, ps.List[i].Value)
+	}
+	return mm
+}
+
+// Add a value to the end of values of key. If key is new, it's placed at the
+// end of keys.
+func (mm *MultiMap[K, V]) Add(key K, value V) {
+	entries, _ := mm.m.Get(key)
+	mm.m.Set(key, append(entries, multiMapEntry[V]{seq: mm.seq, value: value}))
+	mm.seq++
+	mm.total++
+}
+
+// Get a copy of all values of key, in the order they are added. It returns
+// nil if key does not exist.
+func (mm *MultiMap[K, V]) Get(key K) []V {
+	entries, exist := mm.m.Get(key)
+	if !exist {
+		return nil
+	}
+
+	values := make([]V, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, entry.value)
+	}
+	return values
+}
+
+// GetFirst gets the first value of key. The second return value tells if the
+// key exists.
+func (mm *MultiMap[K, V]) GetFirst(key K) (value V, exist bool) {
+	entries, exist := mm.m.Get(key)
+	if exist {
+		value = entries[0].value
+	}
+	return
+}
+
+// GetLast gets the last value of key. The second return value tells if the
+// key exists.
+func (mm *MultiMap[K, V]) GetLast(key K) (value V, exist bool) {
+	entries, exist := mm.m.Get(key)
+	if exist {
+		value = entries[len(entries)-1].value
+	}
+	return
+}
+
+// Has checks if key exist in the multimap.
+func (mm *MultiMap[K, V]) Has(key K) bool {
+	return mm.m.Has(key)
+}
+
+// Delete a key and all its values.
+//
+// Performance: O(n), like [Map.Delete].
+func (mm *MultiMap[K, V]) Delete(key K) {
+	entries, _ := mm.m.Get(key)
+	mm.total -= len(entries)
+	mm.m.Delete(key)
+}
+
+// DeleteValue deletes the value at index of values of key. If it's the only
+// value, the key is deleted too.
+//
+// You should make sure 0 <= index < len(Get(key)), panic if out of bound.
+func (mm *MultiMap[K, V]) DeleteValue(key K, index int) {
+	entries, _ := mm.m.Get(key)
+
+	if len(entries) == 1 && index == 0 {
+		mm.Delete(key)
+		return
+	}
+
+	mm.m.Set(key, append(entries[:index], entries[index+1:]...))
+	mm.total--
+}
+
+// Len returns count of distinct keys.
+func (mm *MultiMap[K, V]) Len() int {
+	return mm.m.Len()
+}
+
+// TotalLen returns count of all values of all keys.
+func (mm *MultiMap[K, V]) TotalLen() int {
+	return mm.total
+}
+
+// Keys returns a copy of all keys, in the order they first appear.
+func (mm *MultiMap[K, V]) Keys() []K {
+	return mm.m.Keys()
+}
+
+// Pairs flattens the multimap into a [Pairs], in the global order values are
+// added, regardless of their keys. So a [Pairs] can round trip through
+// [NewMultiMapFrom] and Pairs.
+//
+// Performance: O(n*log(n)), n is [MultiMap.TotalLen].
+func (mm *MultiMap[K, V]) Pairs() *Pairs[K, V] {
+	type item struct {
+		seq  uint64
+		pair Pair[K, V]
+	}
+
+	items := make([]item, 0, mm.total)
+	for _, key := range mm.m.order {
+		for _, entry := range mm.m.inner[key] {
+			items = append(items, item{seq: entry.seq, pair: Pair[K, V]{Key: key, Value: entry.value}})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].seq < items[j].seq
+	})
+
+	ps := NewPairsWithCapacity[K, V](len(items))
+	for _, it := range items {
+		ps.List = append(ps.List, it.pair)
+	}
+	return ps
+}
+
+func (mm *MultiMap[K, V]) encodeJSON(e *encodeState) error {
+	if mm == nil {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+	return mm.Pairs().encodeJSON(e)
+}
+
+// MarshalJSON implements [json.Marshaler] interface, the multimap is encoded
+// like its [MultiMap.Pairs], a JSON object with duplicated keys in global
+// insertion order.
+//
+// You should not call this directly, use [json.Marshal] instead.
+func (mm MultiMap[K, V]) MarshalJSON() ([]byte, error) {
+	return mm.Pairs().MarshalJSON()
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] interface, it decodes a JSON
+// object like [Pairs], and adds all its members into the multimap, in order.
+// Like a std map, existing values are kept.
+//
+// You shouldn't call this directly, use [json.Unmarshal]/[JSONUnmarshal]
+// instead.
+func (mm *MultiMap[K, V]) UnmarshalJSON(data []byte) error {
+	ps := NewPairs[K, V]()
+	if err := ps.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	for _, pair := range ps.List {
+		mm.Add(pair.Key, pair.Value)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func multiMapPairs[K comparable, V any](mm *geko.MultiMap[K, V]) []geko.Pair[K, V] {
+	return mm.Pairs().List
+}
+
+func TestMultiMap(t *testing.T) {
+	var mm geko.MultiMap[string, int]
+
+	mm.Add("b", 1)
+	mm.Add("a", 2)
+	mm.Add("b", 3)
+	mm.Add("c", 4)
+	mm.Add("a", 5)
+
+	if mm.Len() != 3 || mm.TotalLen() != 5 || !mm.Has("c") || mm.Has("d") {
+		t.Fatalf("MultiMap size not correct: %d, %d", mm.Len(), mm.TotalLen())
+	}
+
+	if !reflect.DeepEqual(mm.Keys(), []string{"b", "a", "c"}) {
+		t.Fatalf("Keys not in first appearance order: %v", mm.Keys())
+	}
+
+	if !reflect.DeepEqual(mm.Get("b"), []int{1, 3}) || mm.Get("d") != nil {
+		t.Fatalf("Get result not correct: %v", mm.Get("b"))
+	}
+
+	if v, ok := mm.GetFirst("a"); !ok || v != 2 {
+		t.Fatalf("GetFirst result not correct: %d, %v", v, ok)
+	}
+	if v, ok := mm.GetLast("a"); !ok || v != 5 {
+		t.Fatalf("GetLast result not correct: %d, %v", v, ok)
+	}
+	if v, ok := mm.GetFirst("d"); ok || v != 0 {
+		t.Fatalf("GetFirst of not exist key should fail: %d", v)
+	}
+	if v, ok := mm.GetLast("d"); ok || v != 0 {
+		t.Fatalf("GetLast of not exist key should fail: %d", v)
+	}
+
+	values := mm.Get("a")
+	values[0] = 100
+	if v, _ := mm.GetFirst("a"); v != 2 {
+		t.Fatalf("Get should return a copy")
+	}
+}
+
+func TestMultiMap_Delete(t *testing.T) {
+	mm := geko.NewMultiMapFrom(geko.NewPairsOf(
+		geko.P("a", 1), geko.P("b", 2), geko.P("a", 3), geko.P("c", 4), geko.P("a", 5), geko.P("b", 6),
+	))
+
+	mm.DeleteValue("a", 1)
+	mm.DeleteValue("c", 0)
+	mm.Delete("d")
+
+	excepted := []geko.Pair[string, int]{{"a", 1}, {"b", 2}, {"a", 5}, {"b", 6}}
+	if !reflect.DeepEqual(multiMapPairs(mm), excepted) || mm.Len() != 2 || mm.TotalLen() != 4 || mm.Has("c") {
+		t.Fatalf("DeleteValue result not correct: %v", multiMapPairs(mm))
+	}
+
+	mm.Delete("a")
+	excepted = []geko.Pair[string, int]{{"b", 2}, {"b", 6}}
+	if !reflect.DeepEqual(multiMapPairs(mm), excepted) || mm.TotalLen() != 2 {
+		t.Fatalf("Delete result not correct: %v", multiMapPairs(mm))
+	}
+
+	mm.Add("a", 7)
+	excepted = []geko.Pair[string, int]{{"b", 2}, {"b", 6}, {"a", 7}}
+	if !reflect.DeepEqual(multiMapPairs(mm), excepted) || mm.TotalLen() != 3 {
+		t.Fatalf("Add after Delete result not correct: %v", multiMapPairs(mm))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("DeleteValue out of bound should panic")
+		}
+	}()
+	mm.DeleteValue("b", 2)
+}
+
+func TestMultiMap_Pairs(t *testing.T) {
+	ps := geko.NewPairsOf(
+		geko.P(3, "x"), geko.P(1, "y"), geko.P(3, "z"), geko.P(2, "w"), geko.P(1, "v"), geko.P(3, "u"),
+	)
+
+	mm := geko.NewMultiMapFrom(ps)
+	if !reflect.DeepEqual(multiMapPairs(mm), ps.List) {
+		t.Fatalf("Pairs should keep global insertion order: %v", multiMapPairs(mm))
+	}
+
+	if empty := geko.NewMultiMapFrom[int, string](nil); empty.Len() != 0 || empty.Pairs().Len() != 0 {
+		t.Fatalf("MultiMap from nil pairs should be empty")
+	}
+}
+
+func TestMultiMap_JSON(t *testing.T) {
+	mm := geko.NewMultiMap[string, any]()
+	mm.Add("z", true)
+
+	data := []byte(`{"a": 1, "b": {"x": 1, "x": 2}, "a": [3], "z": null}`)
+	if err := json.Unmarshal(data, mm); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	excepted := `{"z":true,"a":1,"b":{"x":1,"x":2},"a":[3],"z":null}`
+
+	output, _ := json.Marshal(mm)
+	if string(output) != excepted {
+		t.Fatalf("Marshal result not correct: %s", string(output))
+	}
+
+	var nilMultiMap *geko.MultiMap[string, any]
+	output, _ = geko.JSONMarshal(geko.NewListOf[any](mm, nilMultiMap))
+	if string(output) != `[`+excepted+`,null]` {
+		t.Fatalf("JSONMarshal result not correct: %s", string(output))
+	}
+
+	if err := json.Unmarshal([]byte(`[1]`), mm); err == nil {
+		t.Fatalf("Unmarshal non-object should fail")
+	}
+}
@@ -0,0 +1,56 @@
+package geko
+
+import "fmt"
+
+// MustJSONUnmarshal is like [JSONUnmarshal] but panics if data can't be
+// decoded. It's intended for tests and initialization of global variables
+// with trusted input.
+func MustJSONUnmarshal(data []byte, option ...DecodeOption) any {
+	value, err := JSONUnmarshal(data, option...)
+	if err != nil {
+		panic(fmt.Errorf("geko: MustJSONUnmarshal: %w", err))
+	}
+	return value
+}
+
+// MustObject asserts v is an [Object], and returns it. It panics with a
+// message contains the dynamic type of v if not, an [ObjectItems] is not
+// converted.
+//
+//	object := geko.MustObject(geko.MustJSONUnmarshal(data, geko.UseObject()))
+func MustObject(v any) Object {
+	object, ok := v.(Object)
+	if !ok {
+		panic(fmt.Errorf("geko: MustObject: value of type %T is not an Object", v))
+	}
+	return object
+}
+
+// MustArray asserts v is an [Array], and returns it. It panics with a message
+// contains the dynamic type of v if not.
+func MustArray(v any) Array {
+	array, ok := v.(Array)
+	if !ok {
+		panic(fmt.Errorf("geko: MustArray: value of type %T is not an Array", v))
+	}
+	return array
+}
+
+// MustGet is like [Map.Get], but panics with a message contains the key if it
+// does not exist.
+func (m *Map[K, V]) MustGet(key K) V {
+	value, exist := m.Get(key)
+	if !exist {
+		panic(fmt.Errorf("geko: MustGet: key %#v does not exist in map", key))
+	}
+	return value
+}
+
+// MustGet is like [List.Get], but panics with a message contains the index
+// and length of list if index is out of range.
+func (l *List[T]) MustGet(index int) T {
+	if index < 0 || index >= l.Len() {
+		panic(fmt.Errorf("geko: MustGet: index %d out of range of list with length %d", index, l.Len()))
+	}
+	return l.List[index]
+}
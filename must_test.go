@@ -0,0 +1,102 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func assertPanicMessage(t *testing.T, excepted string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), excepted) {
+			t.Fatalf("Should panic with message contains %q: %#v", excepted, err)
+		}
+	}()
+
+	fn()
+}
+
+func TestMust(t *testing.T) {
+	object := geko.MustObject(geko.MustJSONUnmarshal([]byte(`{"b": 1, "a": [true]}`), geko.UseObject()))
+
+	array := geko.MustArray(object.MustGet("a"))
+	if object.MustGet("b") != 1.0 || array.MustGet(0) != true {
+		t.Fatalf("Must result not correct: %v", object)
+	}
+
+	var nilObject geko.Object
+	if geko.MustObject(nilObject) != nil {
+		t.Fatalf("MustObject of typed nil should be nil")
+	}
+}
+
+func TestMust_Panic(t *testing.T) {
+	object := geko.NewMapOf[string, any](geko.P[string, any]("a", 1))
+	ints := geko.NewMapOf(geko.P(1, "one"))
+	array := geko.NewListOf[any](1, 2)
+
+	for _, c := range []struct {
+		fn       func()
+		excepted string
+	}{
+		{
+			func() { geko.MustJSONUnmarshal([]byte(`{"a": `)) },
+			"geko: MustJSONUnmarshal: unexpected end of JSON input",
+		},
+		{
+			func() { geko.MustObject(geko.MustJSONUnmarshal([]byte(`{"a": 1}`))) },
+			"geko: MustObject: value of type *geko.Pairs[string,interface {}] is not an Object",
+		},
+		{
+			func() { geko.MustObject(nil) },
+			"geko: MustObject: value of type <nil> is not an Object",
+		},
+		{
+			func() { geko.MustArray(object) },
+			"geko: MustArray: value of type *geko.Map[string,interface {}] is not an Array",
+		},
+		{
+			func() { geko.MustArray([]any{1}) },
+			"geko: MustArray: value of type []interface {} is not an Array",
+		},
+		{
+			func() { object.MustGet("b") },
+			`geko: MustGet: key "b" does not exist in map`,
+		},
+		{
+			func() { ints.MustGet(2) },
+			`geko: MustGet: key 2 does not exist in map`,
+		},
+		{
+			func() { array.MustGet(2) },
+			"geko: MustGet: index 2 out of range of list with length 2",
+		},
+		{
+			func() { array.MustGet(-1) },
+			"geko: MustGet: index -1 out of range of list with length 2",
+		},
+	} {
+		assertPanicMessage(t, c.excepted, c.fn)
+	}
+}
+
+func TestMustJSONUnmarshal_Unwrap(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("MustJSONUnmarshal should panic with error wraps the original one: %#v", err)
+		}
+	}()
+
+	geko.MustJSONUnmarshal([]byte(`{"a": 1,}`))
+}
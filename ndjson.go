@@ -0,0 +1,42 @@
+package geko
+
+import "io"
+
+// NDJSONWriter writes values to an output stream as [newline delimited JSON],
+// one value per line.
+//
+// [newline delimited JSON]: https://github.com/ndjson/ndjson-spec
+type NDJSONWriter struct {
+	enc *Encoder
+}
+
+// NewNDJSONWriter creates a new [NDJSONWriter] that writes to w, with encode
+// options.
+//
+// Values are always written compactly, so [Indent] in option is ignored. Other
+// options, like [EscapeHTML], are honored.
+func NewNDJSONWriter(w io.Writer, option ...EncodeOption) *NDJSONWriter {
+	enc := NewEncoder(w, option...)
+	enc.SetIndent("", "")
+	return &NDJSONWriter{enc: enc}
+}
+
+// Write writes the JSON encoding of v as a single line, followed by exactly
+// one newline character. Newlines in strings are escaped, like other control
+// characters.
+//
+// If v can't be encoded, an error is returned and nothing is written.
+func (w *NDJSONWriter) Write(v any) error {
+	return w.enc.Encode(v)
+}
+
+// WriteAll writes each item of l as a line, in order, like [NDJSONWriter.Write].
+// It stops at the first error. A nil l writes nothing.
+func (w *NDJSONWriter) WriteAll(l *List[any]) error {
+	for i := 0; l != nil && i < l.Len(); i++ {
+		if err := w.Write(l.Get(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	records, _ := geko.JSONUnmarshalAll([]byte(`
+		{"b": "line1\nline2", "a": [1, {"x": "<tab>\t"}]}
+		{"c": {}, "d": []}
+		"\u2028"
+		null
+	`), geko.UseObject())
+	records.Append(json.RawMessage("{\n  \"raw\": true\n}"), map[string]any{"m": "\r\n"})
+
+	var buf bytes.Buffer
+	w := geko.NewNDJSONWriter(&buf, geko.Indent("", "  "), geko.EscapeHTML(false))
+	if err := w.WriteAll(records); err != nil {
+		t.Fatalf("WriteAll with error: %s", err.Error())
+	}
+
+	excepted := `{"b":"line1\nline2","a":[1,{"x":"<tab>\t"}]}` + "\n" +
+		`{"c":{},"d":[]}` + "\n" +
+		`"\u2028"` + "\n" +
+		`null` + "\n" +
+		`{"raw":true}` + "\n" +
+		`{"m":"\r\n"}` + "\n"
+	if buf.String() != excepted {
+		t.Fatalf("NDJSON output not correct: %s", buf.String())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != records.Len() {
+		t.Fatalf("NDJSON output should have %d lines, got %d", records.Len(), len(lines))
+	}
+
+	for i, line := range lines {
+		value, err := geko.JSONUnmarshal([]byte(line), geko.UseObject())
+		if err != nil {
+			t.Fatalf("Line %d can't be parsed: %s", i, err.Error())
+		}
+
+		excepted, _ := geko.JSONMarshal(records.Get(i), geko.EscapeHTML(false))
+		output, _ := geko.JSONMarshal(value, geko.EscapeHTML(false))
+		if string(output) != string(excepted) {
+			t.Fatalf("Line %d round trip not correct, excepted %s, got %s", i, excepted, output)
+		}
+	}
+}
+
+func TestNDJSONWriter_EscapeHTML(t *testing.T) {
+	var buf bytes.Buffer
+	w := geko.NewNDJSONWriter(&buf)
+
+	_ = w.Write(geko.NewMapOf(geko.P("a", "<b>")))
+	_ = w.Write("&")
+
+	if buf.String() != `{"a":"\u003cb\u003e"}`+"\n"+`"\u0026"`+"\n" {
+		t.Fatalf("NDJSON output should escape HTML by default: %s", buf.String())
+	}
+}
+
+func TestNDJSONWriter_Error(t *testing.T) {
+	var buf bytes.Buffer
+	w := geko.NewNDJSONWriter(&buf)
+
+	if err := w.WriteAll(nil); err != nil || buf.Len() != 0 {
+		t.Fatalf("WriteAll of nil list should write nothing: %s", buf.String())
+	}
+
+	err := w.WriteAll(geko.NewListOf[any](1, math.NaN(), 2))
+	if err == nil || buf.String() != "1\n" {
+		t.Fatalf("WriteAll should stop at the first error: %v, %s", err, buf.String())
+	}
+}
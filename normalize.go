@@ -0,0 +1,40 @@
+package geko
+
+// NormalizeOrder sorts members of every [Object] and [ObjectItems] in root by
+// their keys, recursively and in place. Keys are compared by less, nil less
+// means lexicographic order, like the [SortKeys] option.
+//
+// Unlike [SortKeys], which only affects marshal output, the structure itself
+// is reordered, so index-based access like [Map.GetByIndex] sees the sorted
+// order too.
+//
+// Members of an [ObjectItems] are sorted stably, so members with the same
+// key are kept adjacent, in their original relative order. Containers are
+// descended into like [Walk], so map[string]any and []any are leaves.
+func NormalizeOrder(root any, less func(a, b string) bool) {
+	if less == nil {
+		less = func(a, b string) bool { return a < b }
+	}
+
+	normalizeOrder(root, less)
+}
+
+func normalizeOrder(value any, less func(a, b string) bool) {
+	if isNull(value) {
+		return
+	}
+
+	switch x := value.(type) {
+	case Object:
+		x.Sort(func(a, b *Pair[string, any]) bool { return less(a.Key, b.Key) })
+	case ObjectItems:
+		x.Sort(func(a, b *Pair[string, any]) bool { return less(a.Key, b.Key) })
+	}
+
+	if c, isContainer := value.(container); isContainer {
+		_ = c.rangeChildren(func(_, child any) error {
+			normalizeOrder(child, less)
+			return nil
+		})
+	}
+}
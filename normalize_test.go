@@ -0,0 +1,56 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestNormalizeOrder(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{
+		"b": [{"z": 1, "y": 2}, {"d": 1, "c": 2, "d": 3}],
+		"a": {"n": {"2": null, "10": null}, "m": null},
+		"c": {"x": {"k": 1}}
+	}`))
+
+	inner := geko.NewMap[string, any]()
+	inner.Set("t", nil)
+	inner.Set("s", nil)
+
+	doc.(geko.ObjectItems).Add("a", "again")
+	// std types are leaves
+	doc.(geko.ObjectItems).Add("c", map[string]any{"list": []any{inner}})
+
+	geko.NormalizeOrder(doc, nil)
+
+	output, _ := json.Marshal(doc)
+	excepted := `{"a":{"m":null,"n":{"10":null,"2":null}},"a":"again",` +
+		`"b":[{"y":2,"z":1},{"c":2,"d":1,"d":3}],` +
+		`"c":{"x":{"k":1}},"c":{"list":[{"t":null,"s":null}]}}`
+	if string(output) != excepted {
+		t.Fatalf("NormalizeOrder result not correct: %s", string(output))
+	}
+
+	if doc.(geko.ObjectItems).GetByIndex(2).Key != "b" {
+		t.Fatalf("NormalizeOrder should reorder the structure itself")
+	}
+}
+
+func TestNormalizeOrder_Less(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"bb": 1, "a": {"ccc": 1, "dd": 2}, "eee": 3}`), geko.UseObject())
+
+	geko.NormalizeOrder(doc, func(a, b string) bool {
+		return len(a) > len(b) || (len(a) == len(b) && strings.Compare(a, b) < 0)
+	})
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"eee":3,"bb":1,"a":{"ccc":1,"dd":2}}` {
+		t.Fatalf("NormalizeOrder result not correct: %s", string(output))
+	}
+
+	var m geko.Object
+	geko.NormalizeOrder(m, nil)
+	geko.NormalizeOrder(nil, nil)
+}
@@ -11,6 +11,14 @@ func CreatePair[K, V any](key K, value V) Pair[K, V] {
 	return Pair[K, V]{key, value}
 }
 
+// P is a short alias of [CreatePair], to build containers in literal style,
+// like [NewMapOf]:
+//
+//	geko.NewMapOf(geko.P("b", 1), geko.P("a", 2))
+func P[K, V any](key K, value V) Pair[K, V] {
+	return CreatePair(key, value)
+}
+
 // PairLessFunc is the less func to sort a pair list.
 type PairLessFunc[K, V any] func(a, b *Pair[K, V]) bool
 
@@ -17,6 +17,11 @@ import "sort"
 // keep this in mind when using it.
 type Pairs[K comparable, V any] struct {
 	List []Pair[K, V]
+
+	lenient bool
+	frozen  bool
+	// mods counts structural modifications, see [Pairs.Range]
+	mods uint
 }
 
 // ObjectItems is [Pairs] whose type parameters are specialized as
@@ -34,6 +39,14 @@ func NewPairsWithCapacity[K comparable, V any](capacity int) *Pairs[K, V] {
 	return NewPairsFrom[K, V](make([]Pair[K, V], 0, capacity))
 }
 
+// NewPairsOf creates a new list contains a copy of pairs, all values of
+// duplicated keys are kept.
+//
+//	geko.NewPairsOf(geko.P("b", 1), geko.P("a", 2), geko.P("b", 3))
+func NewPairsOf[K comparable, V any](pairs ...Pair[K, V]) *Pairs[K, V] {
+	return NewPairsFrom(append([]Pair[K, V](nil), pairs...))
+}
+
 // NewPairsFrom create a List from a slice.
 func NewPairsFrom[K comparable, V any](list []Pair[K, V]) *Pairs[K, V] {
 	return &Pairs[K, V]{
@@ -41,6 +54,23 @@ func NewPairsFrom[K comparable, V any](list []Pair[K, V]) *Pairs[K, V] {
 	}
 }
 
+// Lenient reports whether unmarshal into this list skips values which fail to
+// decode.
+func (ps *Pairs[K, V]) Lenient() bool {
+	return ps.lenient
+}
+
+// SetLenient enables or disables skipping values which fail to decode, when
+// unmarshal JSON into this list. Successfully decoded members are still added,
+// and a [MultiDecodeError] is returned for the skipped ones.
+//
+// It only takes effect when V is not any, see [Lenient].
+func (ps *Pairs[K, V]) SetLenient(on bool) {
+	mustNotFrozen(ps.checkFrozen("SetLenient"))
+
+	ps.lenient = on
+}
+
 // Get values by key.
 //
 // Performance: O(n)
@@ -140,33 +170,47 @@ func (ps *Pairs[K, V]) GetValueByIndex(index int) V {
 
 // SetKeyByIndex changes key of item at index.
 func (ps *Pairs[K, V]) SetKeyByIndex(index int, key K) {
+	mustNotFrozen(ps.checkFrozen("SetKeyByIndex"))
+
 	ps.List[index].Key = key
 }
 
 // SetValueByIndex changes value of item at index.
 func (ps *Pairs[K, V]) SetValueByIndex(index int, value V) {
+	mustNotFrozen(ps.checkFrozen("SetValueByIndex"))
+
 	ps.List[index].Value = value
 }
 
 // SetByIndex key and value at index.
 func (ps *Pairs[K, V]) SetByIndex(index int, key K, value V) {
+	mustNotFrozen(ps.checkFrozen("SetByIndex"))
+
 	ps.List[index] = CreatePair(key, value)
 }
 
 // Add a key value pair to the end of list.
 func (ps *Pairs[K, V]) Add(key K, value V) {
+	mustNotFrozen(ps.checkFrozen("Add"))
+
 	ps.List = append(ps.List, CreatePair(key, value))
+	ps.mods++
 }
 
 // Append some key value pairs to the end of list.
 func (ps *Pairs[K, V]) Append(pairs ...Pair[K, V]) {
+	mustNotFrozen(ps.checkFrozen("Append"))
+
 	ps.List = append(ps.List, pairs...)
+	ps.mods++
 }
 
 // Delete all item whose key is same as provided.
 //
 // Performance: O(n)
 func (ps *Pairs[K, V]) Delete(key K) {
+	mustNotFrozen(ps.checkFrozen("Delete"))
+
 	ps.Filter(func(p *Pair[K, V]) bool {
 		return p.Key != key
 	})
@@ -176,12 +220,18 @@ func (ps *Pairs[K, V]) Delete(key K) {
 //
 // Performance: O(n)
 func (ps *Pairs[K, V]) DeleteByIndex(index int) {
+	mustNotFrozen(ps.checkFrozen("DeleteByIndex"))
+
 	ps.List = append(ps.List[:index], ps.List[index+1:]...)
+	ps.mods++
 }
 
 // Clear this list.
 func (ps *Pairs[K, V]) Clear() {
+	mustNotFrozen(ps.checkFrozen("Clear"))
+
 	ps.List = nil
+	ps.mods++
 }
 
 // Len returns the size of list.
@@ -223,14 +273,42 @@ func (ps *Pairs[K, V]) ToMap(strategy DuplicatedKeyStrategy) *Map[K, V] {
 //
 // Implemented as converting it to a [Map] and back.
 func (ps *Pairs[K, V]) Dedup(strategy DuplicatedKeyStrategy) {
+	mustNotFrozen(ps.checkFrozen("Dedup"))
+
 	ps.List = ps.ToMap(strategy).Pairs().List
+	ps.mods++
+}
+
+// GroupAdjacent reorders the list so all pairs with the same key are
+// adjacent. Groups are ordered by the first appearance of their key, and pairs
+// in a group keep their relative order. Unlike [Pairs.Dedup], no pair is
+// dropped, and unlike [Pairs.Sort], keys are not compared.
+//
+//	a:1, b:2, a:3, c:4, b:5 => a:1, a:3, b:2, b:5, c:4
+func (ps *Pairs[K, V]) GroupAdjacent() {
+	mustNotFrozen(ps.checkFrozen("GroupAdjacent"))
+
+	groups := make(map[K]int, ps.Len())
+	for i := range ps.List {
+		if _, exist := groups[ps.List[i].Key]; !exist {
+			groups[ps.List[i].Key] = len(groups)
+		}
+	}
+
+	sort.SliceStable(ps.List, func(i, j int) bool {
+		return groups[ps.List[i].Key] < groups[ps.List[j].Key]
+	})
+	ps.mods++
 }
 
 // Sort will reorder the list using the given less function.
 func (ps *Pairs[K, V]) Sort(lessFunc PairLessFunc[K, V]) {
+	mustNotFrozen(ps.checkFrozen("Sort"))
+
 	sort.SliceStable(ps.List, func(i, j int) bool {
 		return lessFunc(&ps.List[i], &ps.List[j])
 	})
+	ps.mods++
 }
 
 // Filter remove all item which make pred func return false.
@@ -238,16 +316,93 @@ func (ps *Pairs[K, V]) Sort(lessFunc PairLessFunc[K, V]) {
 // Performance: O(n). More efficient then [Pairs.GetByIndex] +
 // [Pairs.DeleteByIndex] in a loop, which is O(n^2).
 func (ps *Pairs[K, V]) Filter(pred PairFilterFunc[K, V]) {
+	mustNotFrozen(ps.checkFrozen("Filter"))
+
 	n := 0
 	for i, length := 0, ps.Len(); i < length; i++ {
 		if pred(&ps.List[i]) {
 			ps.List[n] = ps.List[i]
 			n++
+		} else {
+			ps.mods++
 		}
 	}
 	ps.List = ps.List[:n]
 }
 
+// rangeChildren calls fn for every kv pair in current order, see [Walk].
+func (ps *Pairs[K, V]) rangeChildren(fn func(key, value any) error) error {
+	if ps == nil {
+		return nil
+	}
+
+	for i := 0; i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+		if err := fn(pair.Key, pair.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformChildren replaces every value by fn in current order, or deletes
+// it if fn says so, see [Transform].
+func (ps *Pairs[K, V]) transformChildren(fn func(key, value any) (any, bool, error)) error {
+	if ps == nil {
+		return nil
+	}
+
+	kept := ps.List[:0]
+	for i, pair := range ps.List {
+		v, remove, err := fn(pair.Key, pair.Value)
+		if err == nil && !remove {
+			var ok bool
+			if pair.Value, ok = assignable[V](v); !ok {
+				err = &assignError{key: pair.Key, value: v, container: ps}
+			}
+		}
+
+		if err != nil {
+			ps.List = append(kept, ps.List[i:]...)
+			return err
+		}
+
+		if !remove {
+			kept = append(kept, pair)
+		} else {
+			ps.mods++
+		}
+	}
+	ps.List = kept
+
+	return nil
+}
+
+// toStdTypes converts the pairs into a map[string]any, last value wins for
+// duplicated key, see [ToStdTypes].
+func (ps *Pairs[K, V]) toStdTypes() any {
+	if ps == nil {
+		return nil
+	}
+
+	result := make(map[string]any, ps.Len())
+	for i := 0; i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+		result[stdKey(pair.Key)] = ToStdTypes(pair.Value)
+	}
+
+	return result
+}
+
+func (ps *Pairs[K, V]) encodeJSON(e *encodeState) error {
+	if ps == nil {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+	return encodeObject[K, V](e, ps)
+}
+
 // MarshalJSON implements json.Marshaler interface.
 // You should not call this directly, use json.Marshal(m) instead.
 func (ps Pairs[K, V]) MarshalJSON() ([]byte, error) {
@@ -257,5 +412,9 @@ func (ps Pairs[K, V]) MarshalJSON() ([]byte, error) {
 // UnmarshalJSON implements json.Unmarshaler interface.
 // You shouldn't call this directly, use json.Unmarshal(m) instead.
 func (ps *Pairs[K, V]) UnmarshalJSON(data []byte) error {
-	return unmarshalObject[K, V](data, ps, UseObjectItems())
+	if err := ps.checkFrozen("UnmarshalJSON"); err != nil {
+		return err
+	}
+
+	return unmarshalObject[K, V](data, ps, UseObjectItems(), Lenient(ps.lenient))
 }
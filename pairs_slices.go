@@ -0,0 +1,32 @@
+//go:build go1.21
+
+package geko
+
+import "slices"
+
+// SortFunc sorts the list by cmp, pairs which are equal keep their original
+// order, like [slices.SortStableFunc].
+//
+// The cmp func should return 0 if the two pairs are equal, a negative number
+// if a < b, and a positive number if a > b, the same convention as the
+// [slices] package and [cmp.Compare].
+//
+// Use it and [Pairs.BinarySearchFunc] instead of calling std algorithms on
+// [Pairs.List] directly, so the list knows it's modified.
+func (ps *Pairs[K, V]) SortFunc(cmp func(a, b Pair[K, V]) int) {
+	mustNotFrozen(ps.checkFrozen("SortFunc"))
+
+	slices.SortStableFunc(ps.List, cmp)
+	ps.mods++
+}
+
+// BinarySearchFunc searches for target in a list sorted by cmp, and returns
+// the index where target is found, or the index where it would be inserted to
+// keep the list sorted. The second return value tells if target is found.
+//
+// If there are multiple pairs equal to target, the index of the first one
+// is returned. The cmp func should be the one the list is sorted by, see
+// [Pairs.SortFunc].
+func (ps *Pairs[K, V]) BinarySearchFunc(target Pair[K, V], cmp func(a, b Pair[K, V]) int) (int, bool) {
+	return slices.BinarySearchFunc(ps.List, target, cmp)
+}
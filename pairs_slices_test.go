@@ -0,0 +1,62 @@
+//go:build go1.21
+
+package geko_test
+
+import (
+	"cmp"
+	"encoding/json"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func comparePairValue(a, b geko.Pair[string, int]) int {
+	return cmp.Compare(a.Value, b.Value)
+}
+
+func TestPairs_SortFunc(t *testing.T) {
+	ps := geko.NewPairsOf(geko.P("c", 3), geko.P("a", 1), geko.P("b", 3), geko.P("d", 2))
+
+	ps.SortFunc(comparePairValue)
+
+	// equal pairs keep their original order
+	output, _ := json.Marshal(ps)
+	if string(output) != `{"a":1,"d":2,"c":3,"b":3}` {
+		t.Fatalf("SortFunc result not correct: %s", string(output))
+	}
+
+	index, found := ps.BinarySearchFunc(geko.P("", 3), comparePairValue)
+	if index != 2 || !found {
+		t.Fatalf("BinarySearchFunc excepted (2, true), got (%d, %v)", index, found)
+	}
+
+	index, found = ps.BinarySearchFunc(geko.P("", 0), comparePairValue)
+	if index != 0 || found {
+		t.Fatalf("BinarySearchFunc excepted (0, false), got (%d, %v)", index, found)
+	}
+
+	// locate an inserted pair with the same comparator
+	ps.Add("e", 2)
+	ps.SortFunc(comparePairValue)
+
+	index, found = ps.BinarySearchFunc(geko.P("", 2), comparePairValue)
+	if !found || ps.GetKeyByIndex(index) != "d" || ps.GetKeyByIndex(index+1) != "e" {
+		t.Fatalf("BinarySearchFunc can't locate inserted pair: %d, %v, %v", index, found, ps.Keys())
+	}
+
+	index, found = ps.BinarySearchFunc(geko.P("", 4), comparePairValue)
+	if index != ps.Len() || found {
+		t.Fatalf("BinarySearchFunc excepted (%d, false), got (%d, %v)", ps.Len(), index, found)
+	}
+}
+
+func TestPairs_SortFunc_Frozen(t *testing.T) {
+	ps := geko.NewPairsOf(geko.P("a", 1), geko.P("b", 2))
+	ps.Freeze()
+
+	assertFrozenPanic(t, "SortFunc", func() { ps.SortFunc(comparePairValue) })
+
+	if index, found := ps.BinarySearchFunc(geko.P("", 2), comparePairValue); index != 1 || !found {
+		t.Fatalf("BinarySearchFunc on frozen list excepted (1, true), got (%d, %v)", index, found)
+	}
+}
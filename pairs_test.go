@@ -61,6 +61,25 @@ func TestPairs_New(t *testing.T) {
 	}
 }
 
+func TestPairs_NewOf(t *testing.T) {
+	list := []geko.Pair[string, any]{geko.P[string, any]("b", 1), geko.P[string, any]("a", "x")}
+	ps := geko.NewPairsOf(append(list, geko.P[string, any]("b", nil))...)
+
+	output, _ := json.Marshal(ps)
+	if string(output) != `{"b":1,"a":"x","b":null}` {
+		t.Fatalf("NewPairsOf result not correct: %s", string(output))
+	}
+
+	ps.SetValueByIndex(0, 2)
+	if list[0].Value != 1 {
+		t.Fatalf("NewPairsOf should copy pairs")
+	}
+
+	if ps = geko.NewPairsOf[string, any](); ps.List != nil {
+		t.Fatalf("NewPairsOf without pairs inner slice is not nil")
+	}
+}
+
 func TestPairs_NewWithCapacity(t *testing.T) {
 	ps := geko.NewPairsWithCapacity[string, int](12)
 
@@ -605,6 +624,27 @@ func TestPairs_Dedup(t *testing.T) {
 	}
 }
 
+func TestPairs_GroupAdjacent(t *testing.T) {
+	ps := geko.NewPairsOf(
+		geko.P("a", 1), geko.P("b", 2), geko.P("a", 3), geko.P("c", 4), geko.P("b", 5), geko.P("a", 6),
+	)
+	ps.GroupAdjacent()
+
+	if keys := ps.Keys(); !reflect.DeepEqual(keys, []string{"a", "a", "a", "b", "b", "c"}) {
+		t.Fatalf("GroupAdjacent keys not correct: %#v", keys)
+	}
+
+	if values := ps.Values(); !reflect.DeepEqual(values, []int{1, 3, 6, 2, 5, 4}) {
+		t.Fatalf("GroupAdjacent values not correct: %#v", values)
+	}
+
+	empty := geko.NewPairs[string, int]()
+	empty.GroupAdjacent()
+	if empty.Len() != 0 {
+		t.Fatalf("GroupAdjacent of empty pairs should be empty")
+	}
+}
+
 func TestPairs_Sort(t *testing.T) {
 	ps := geko.NewPairs[int, string]()
 	ps.Add(3, "three.2")
@@ -797,6 +837,26 @@ func TestPairs_UnmarshalJSON_ConcreteValueType(t *testing.T) {
 	}
 }
 
+func TestPairs_UnmarshalJSON_Lenient(t *testing.T) {
+	ps := geko.NewPairs[string, int]()
+	ps.SetLenient(true)
+	if !ps.Lenient() {
+		t.Fatalf("Lenient should be enabled")
+	}
+
+	err := json.Unmarshal([]byte(`{"a": 1, "a": "x", "b": 2, "a": 3.5}`), &ps)
+
+	excepted := []string{"/a", "/a"}
+	if paths := skippedPaths(t, err); !reflect.DeepEqual(paths, excepted) {
+		t.Fatalf("Skipped paths not correct: %#v", paths)
+	}
+
+	output, _ := json.Marshal(ps)
+	if string(output) != `{"a":1,"b":2}` {
+		t.Fatalf("Lenient unmarshal result not correct: %s", string(output))
+	}
+}
+
 func TestPairs_UnmarshalJSON_InitializedPairs(t *testing.T) {
 	ps := geko.NewPairs[string, any]()
 	ps.Add("old", "value")
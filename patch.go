@@ -0,0 +1,379 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatchOperation means an operation in JSON patch is malformed,
+	// like missing a required member or having an unknown op.
+	ErrInvalidPatchOperation = errors.New("geko: invalid patch operation")
+	// ErrPatchTestFailed means a "test" operation in JSON patch fails.
+	ErrPatchTestFailed = errors.New("geko: patch test failed")
+)
+
+// ApplyPatch applies an [RFC 6902] JSON patch to doc, and returns the patched
+// document. The patch is an array of operation objects, like what
+// [JSONUnmarshal] returns for a JSON patch document.
+//
+// All operations, "add", "remove", "replace", "move", "copy" and "test", are
+// supported, paths are evaluated like [PointerGet], over [Object],
+// [ObjectItems] and [Array].
+//
+// Adding an existing object member replaces its value and keeps its position,
+// new members are added to the end. For an [ObjectItems], the first member of
+// a duplicated key is used.
+//
+// Patch is applied atomically: operations are applied to a deep copy of doc,
+// so doc is never modified. If any operation fails, a [*PatchError] is
+// returned.
+//
+// [RFC 6902]: https://www.rfc-editor.org/rfc/rfc6902
+func ApplyPatch(doc any, patch Array) (any, error) {
+	result := cloneValue(doc)
+
+	if patch == nil {
+		return result, nil
+	}
+
+	for i, operation := range patch.List {
+		op, err := patchMemberString(operation, "op")
+		if err == nil {
+			result, err = applyPatchOperation(result, op, operation)
+		}
+
+		if err != nil {
+			return nil, &PatchError{Index: i, Op: op, Err: err}
+		}
+	}
+
+	return result, nil
+}
+
+func applyPatchOperation(doc any, op string, operation any) (any, error) {
+	path, err := patchMemberString(operation, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	var from string
+
+	switch op {
+	case "add", "replace", "test":
+		var exist bool
+		if value, exist = patchMember(operation, "value"); !exist {
+			return nil, fmt.Errorf("%w: missing member %q", ErrInvalidPatchOperation, "value")
+		}
+	case "move", "copy":
+		if from, err = patchMemberString(operation, "from"); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op {
+	case "add":
+		return patchAdd(doc, path, cloneValue(value))
+	case "remove":
+		_, err = PointerDelete(doc, path)
+		return doc, err
+	case "replace":
+		return patchReplace(doc, path, cloneValue(value))
+	case "move":
+		return patchMove(doc, from, path)
+	case "copy":
+		return patchCopy(doc, from, path)
+	case "test":
+		return doc, patchTest(doc, path, value)
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatchOperation, op)
+	}
+}
+
+// patchMember gets a member of operation object.
+func patchMember(operation any, key string) (any, bool) {
+	value, err := PointerGet(operation, "/"+key)
+	return value, err == nil
+}
+
+// patchMemberString gets a member of operation object, which must be a string.
+func patchMemberString(operation any, key string) (string, error) {
+	if err := checkPatchOperation(operation); err != nil {
+		return "", err
+	}
+
+	value, exist := patchMember(operation, key)
+	if !exist {
+		return "", fmt.Errorf("%w: missing member %q", ErrInvalidPatchOperation, key)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: member %q is not a string", ErrInvalidPatchOperation, key)
+	}
+
+	return s, nil
+}
+
+// checkPatchOperation checks operation is an object without duplicated keys.
+func checkPatchOperation(operation any) error {
+	switch o := operation.(type) {
+	case Object:
+		if o != nil {
+			return nil
+		}
+	case ObjectItems:
+		if o != nil {
+			for i := 0; i < o.Len(); i++ {
+				if firstIndexOfKey(o, o.GetKeyByIndex(i)) != i {
+					return fmt.Errorf("%w: duplicated member %q", ErrInvalidPatchOperation, o.GetKeyByIndex(i))
+				}
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: not an object", ErrInvalidPatchOperation)
+}
+
+func patchAdd(doc any, path string, value any) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	last := len(tokens) - 1
+
+	parent, err := pointerResolve(path, tokens[:last], doc)
+	if err != nil {
+		return nil, err
+	}
+
+	array, ok := parent.(Array)
+	if !ok || array == nil || tokens[last] == "-" {
+		return doc, pointerSetChild(path, last, parent, tokens[last], value)
+	}
+
+	index, ok := parseArrayIndex(tokens[last])
+	if !ok {
+		return nil, &PointerError{Pointer: path, Segment: last, Kind: PointerNotFound}
+	}
+
+	if index > array.Len() {
+		return nil, &PointerError{Pointer: path, Segment: last, Kind: PointerOutOfRange}
+	}
+
+	array.List = append(array.List, nil)
+	copy(array.List[index+1:], array.List[index:])
+	array.List[index] = value
+
+	return doc, nil
+}
+
+func patchReplace(doc any, path string, value any) (any, error) {
+	if _, err := PointerGet(doc, path); err != nil {
+		return nil, err
+	}
+
+	return PointerSet(doc, path, value, false)
+}
+
+func patchTest(doc any, path string, value any) error {
+	current, err := PointerGet(doc, path)
+	if err != nil {
+		return err
+	}
+
+	if !jsonEqual(current, value, false) {
+		return ErrPatchTestFailed
+	}
+
+	return nil
+}
+
+func patchMove(doc any, from, path string) (any, error) {
+	if from == path {
+		_, err := PointerGet(doc, from)
+		return doc, err
+	}
+
+	if strings.HasPrefix(path, from+"/") {
+		return nil, fmt.Errorf("%w: can't move %q into its child %q", ErrInvalidPatchOperation, from, path)
+	}
+
+	value, err := PointerDelete(doc, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return patchAdd(doc, path, value)
+}
+
+func patchCopy(doc any, from, path string) (any, error) {
+	value, err := PointerGet(doc, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return patchAdd(doc, path, cloneValue(value))
+}
+
+// cloneValue deep copies [Object], [ObjectItems] and [Array] in v, other
+// values are shared.
+func cloneValue(v any) any {
+	switch x := v.(type) {
+	case Object:
+		if x == nil {
+			return x
+		}
+		c := NewMapWithCapacity[string, any](x.Len())
+		c.duplicatedKeyStrategy = x.duplicatedKeyStrategy
+		for i := 0; i < x.Len(); i++ {
+			pair := x.GetByIndex(i)
+			c.Set(pair.Key, cloneValue(pair.Value))
+		}
+		return c
+	case ObjectItems:
+		if x == nil {
+			return x
+		}
+		c := NewPairsWithCapacity[string, any](x.Len())
+		for i := 0; i < x.Len(); i++ {
+			pair := x.GetByIndex(i)
+			c.Add(pair.Key, cloneValue(pair.Value))
+		}
+		return c
+	case Array:
+		if x == nil {
+			return x
+		}
+		c := *x
+		if x.List != nil {
+			c.List = make([]any, len(x.List))
+			for i, item := range x.List {
+				c.List[i] = cloneValue(item)
+			}
+		}
+		return &c
+	default:
+		return v
+	}
+}
+
+// jsonEqual tells if a and b are the same JSON value. Numbers are compared by
+// their decimal value, and order of object members is ignored unless ordered
+// is true.
+func jsonEqual(a, b any, ordered bool) bool {
+	if isNull(a) || isNull(b) {
+		return isNull(a) && isNull(b)
+	}
+
+	if x, isObject := objectMembers(a); isObject {
+		y, bothObject := objectMembers(b)
+		return bothObject && membersEqual(x, y, ordered)
+	}
+
+	if x, isArray := arrayItems(a); isArray {
+		y, bothArray := arrayItems(b)
+		if !bothArray || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i], ordered) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if x, isNumber, err := hashNumber(a); isNumber {
+		y, bothNumber, err2 := hashNumber(b)
+		return bothNumber && err == nil && err2 == nil && x == y
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func membersEqual(x, y []Pair[string, any], ordered bool) bool {
+	if len(x) != len(y) {
+		return false
+	}
+
+	if !ordered {
+		sortMembers(x)
+		sortMembers(y)
+	}
+
+	for i := range x {
+		if x[i].Key != y[i].Key || !jsonEqual(x[i].Value, y[i].Value, ordered) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortMembers(members []Pair[string, any]) {
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Key < members[j].Key
+	})
+}
+
+// isNull tells if v is nil, or a nil container.
+func isNull(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case Object:
+		return x == nil
+	case ObjectItems:
+		return x == nil
+	case Array:
+		return x == nil
+	case map[string]any:
+		return x == nil
+	case []any:
+		return x == nil
+	default:
+		return false
+	}
+}
+
+// objectMembers returns a copy of members of a non-nil object.
+func objectMembers(v any) ([]Pair[string, any], bool) {
+	switch x := v.(type) {
+	case Object:
+		return x.Pairs().List, true
+	case ObjectItems:
+		return append([]Pair[string, any](nil), x.List...), true
+	case map[string]any:
+		members := make([]Pair[string, any], 0, len(x))
+		for key, value := range x {
+			members = append(members, Pair[string, any]{Key: key, Value: value})
+		}
+		sortMembers(members)
+		return members, true
+	default:
+		return nil, false
+	}
+}
+
+// arrayItems returns items of a non-nil array.
+func arrayItems(v any) ([]any, bool) {
+	switch x := v.(type) {
+	case Array:
+		return x.List, true
+	case []any:
+		return x, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,280 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+// patchTestCase is the format of test cases in json-patch-tests suite.
+type patchTestCase struct {
+	Comment  string          `json:"comment"`
+	Doc      json.RawMessage `json:"doc"`
+	Patch    json.RawMessage `json:"patch"`
+	Expected json.RawMessage `json:"expected"`
+	Error    string          `json:"error"`
+	Disabled bool            `json:"disabled"`
+}
+
+func mustParsePatch(t *testing.T, data string) geko.Array {
+	t.Helper()
+
+	patch, err := geko.JSONUnmarshal([]byte(data))
+	if err != nil {
+		t.Fatalf("Unmarshal patch with error: %s", err.Error())
+	}
+
+	return patch.(geko.Array)
+}
+
+func runPatchTestSuite(t *testing.T, file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Read test suite with error: %s", err.Error())
+	}
+
+	var cases []patchTestCase
+	if err = json.Unmarshal(data, &cases); err != nil {
+		t.Fatalf("Unmarshal test suite with error: %s", err.Error())
+	}
+
+	for i, c := range cases {
+		if c.Disabled {
+			continue
+		}
+
+		doc, _ := geko.JSONUnmarshal(c.Doc, geko.UseObject())
+		before := mustSum64(t, doc)
+
+		result, err := geko.ApplyPatch(doc, mustParsePatch(t, string(c.Patch)))
+
+		if mustSum64(t, doc) != before {
+			t.Fatalf("Case %d (%s): ApplyPatch should not modify doc", i, c.Comment)
+		}
+
+		if c.Expected == nil {
+			if err == nil {
+				t.Fatalf("Case %d (%s): ApplyPatch should fail: %s", i, c.Comment, c.Error)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("Case %d (%s): ApplyPatch with error: %s", i, c.Comment, err.Error())
+		}
+
+		expected, _ := geko.JSONUnmarshal(c.Expected)
+		if mustSum64(t, result, geko.IgnoreKeyOrder(true)) != mustSum64(t, expected, geko.IgnoreKeyOrder(true)) {
+			output, _ := json.Marshal(result)
+			t.Fatalf("Case %d (%s): ApplyPatch result not correct: %s", i, c.Comment, string(output))
+		}
+	}
+}
+
+func TestApplyPatch_SpecTests(t *testing.T) {
+	runPatchTestSuite(t, "testdata/patch_spec_tests.json")
+}
+
+func TestApplyPatch_Tests(t *testing.T) {
+	runPatchTestSuite(t, "testdata/patch_tests.json")
+}
+
+func TestApplyPatch_KeyOrder(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"c": 1, "a": 2, "b": {"y": 1, "x": 2}}`), geko.UseObject())
+
+	result, err := geko.ApplyPatch(doc, mustParsePatch(t, `[
+		{"op": "add", "path": "/a", "value": 3},
+		{"op": "add", "path": "/0", "value": 4},
+		{"op": "replace", "path": "/c", "value": 5},
+		{"op": "move", "from": "/b/y", "path": "/b/z"},
+		{"op": "copy", "from": "/b", "path": "/a"}
+	]`))
+	if err != nil {
+		t.Fatalf("ApplyPatch with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"c":5,"a":{"x":2,"z":1},"b":{"x":2,"z":1},"0":4}` {
+		t.Fatalf("ApplyPatch should keep key order: %s", string(output))
+	}
+
+	// copied value is independent
+	_, _ = geko.PointerSet(result, "/a/x", 0, false)
+	if value, _ := geko.PointerGet(result, "/b/x"); value != 2.0 {
+		t.Fatalf("Copied value should not be shared")
+	}
+}
+
+func TestApplyPatch_ObjectItems(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "b": [true], "a": 2}`))
+
+	result, err := geko.ApplyPatch(doc, mustParsePatch(t, `[
+		{"op": "test", "path": "/a", "value": 1},
+		{"op": "add", "path": "/a", "value": 3},
+		{"op": "add", "path": "/b/0", "value": false},
+		{"op": "remove", "path": "/a"},
+		{"op": "test", "path": "/a", "value": 2}
+	]`))
+	if err != nil {
+		t.Fatalf("ApplyPatch with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"b":[false,true],"a":2}` {
+		t.Fatalf("ApplyPatch result not correct: %s", string(output))
+	}
+}
+
+func TestApplyPatch_Atomic(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [1, 2], "b": {"c": 3}}`), geko.UseObject())
+
+	result, err := geko.ApplyPatch(doc, mustParsePatch(t, `[
+		{"op": "add", "path": "/a/-", "value": 3},
+		{"op": "remove", "path": "/b/c"},
+		{"op": "test", "path": "/a/0", "value": 2}
+	]`))
+
+	var patchErr *geko.PatchError
+	if !errors.As(err, &patchErr) || !errors.Is(err, geko.ErrPatchTestFailed) ||
+		patchErr.Index != 2 || patchErr.Op != "test" || result != nil {
+		t.Fatalf("ApplyPatch should fail at test operation: %#v, %#v", result, err)
+	}
+
+	if err.Error() != "geko: apply patch operation 2 (test): geko: patch test failed" {
+		t.Fatalf("PatchError message not correct: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":[1,2],"b":{"c":3}}` {
+		t.Fatalf("ApplyPatch should not modify doc: %s", string(output))
+	}
+}
+
+func TestApplyPatch_Error(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": {"b": 1}, "l": [1]}`), geko.UseObject())
+
+	var m geko.Object
+	var ps geko.ObjectItems
+
+	for _, operation := range []any{m, ps} {
+		_, err := geko.ApplyPatch(doc, geko.NewListFrom([]any{operation}))
+		if !errors.Is(err, geko.ErrInvalidPatchOperation) {
+			t.Fatalf("ApplyPatch should fail on invalid operation: %#v", err)
+		}
+	}
+
+	tests := []struct {
+		patch string
+		op    string
+		err   error
+	}{
+		{`[1]`, "", geko.ErrInvalidPatchOperation},
+		{`[{"op": 1, "path": "/a"}]`, "", geko.ErrInvalidPatchOperation},
+		{`[{"op": "add", "path": "/a", "value": 1, "path": "/b"}]`, "", geko.ErrInvalidPatchOperation},
+		{`[{"op": "move", "from": "/a", "path": "/a/b/c"}]`, "move", geko.ErrInvalidPatchOperation},
+		{`[{"op": "move", "from": "/a/c", "path": "/a/c"}]`, "move", nil},
+		{`[{"op": "add", "path": "/l/x/y", "value": 1}]`, "add", nil},
+		{`[{"op": "copy", "from": "/a", "path": "x"}]`, "copy", nil},
+	}
+
+	for _, test := range tests {
+		_, err := geko.ApplyPatch(doc, mustParsePatch(t, test.patch))
+
+		var patchErr *geko.PatchError
+		if !errors.As(err, &patchErr) || patchErr.Op != test.op || (test.err != nil && !errors.Is(err, test.err)) {
+			t.Fatalf("ApplyPatch %s should fail: %#v", test.patch, err)
+		}
+	}
+}
+
+func TestApplyPatch_Nil(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [1, 2]}`), geko.UseObject())
+
+	result, err := geko.ApplyPatch(doc, nil)
+	if err != nil || result == doc {
+		t.Fatalf("ApplyPatch with nil patch should return a copy: %#v", err)
+	}
+
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+	empty := geko.NewList[any]()
+	empty.SetMarshalNilAsNull(true)
+
+	result, err = geko.ApplyPatch(geko.NewListFrom([]any{m, ps, l, empty}), mustParsePatch(t, `[
+		{"op": "test", "path": "/0", "value": null},
+		{"op": "test", "path": "/1", "value": null},
+		{"op": "test", "path": "/2", "value": null},
+		{"op": "test", "path": "/3", "value": []}
+	]`))
+	if err != nil {
+		t.Fatalf("ApplyPatch with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `[null,null,null,null]` {
+		t.Fatalf("ApplyPatch result not correct: %s", string(output))
+	}
+}
+
+func TestApplyPatch_TestEquality(t *testing.T) {
+	ps := geko.NewPairs[string, any]()
+	ps.Add("x", 1)
+	ps.Add("x", 2)
+
+	doc := geko.NewMap[string, any]()
+	doc.Set("m", map[string]any{"b": []any{1, "s"}, "a": nil})
+	doc.Set("s", []any{1, "s"})
+	doc.Set("n", json.Number("1.50"))
+	doc.Set("p", ps)
+	doc.Set("v", []int{1})
+
+	tests := []struct {
+		path  string
+		value string
+		equal bool
+	}{
+		{"/m", `{"a": null, "b": [1.0, "s"]}`, true},
+		{"/m", `{"a": null, "c": [1.0, "s"]}`, false},
+		{"/m", `{"a": null, "b": [1.0, "t"]}`, false},
+		{"/m", `{"a": null}`, false},
+		{"/m", `[null]`, false},
+		{"/s", `[1, "s"]`, true},
+		{"/s", `[1]`, false},
+		{"/s", `{"0": 1}`, false},
+		{"/s", `null`, false},
+		{"/n", `1.5`, true},
+		{"/n", `15e-1`, true},
+		{"/n", `"1.5"`, false},
+		{"/n", `1.6`, false},
+		{"/p", `{"x": 1, "x": 2}`, true},
+		{"/p", `{"x": 2, "x": 1}`, false},
+		{"/v", `[1]`, false},
+	}
+
+	for _, test := range tests {
+		patch := mustParsePatch(t, `[{"op": "test", "path": "`+test.path+`", "value": `+test.value+`}]`)
+
+		_, err := geko.ApplyPatch(doc, patch)
+		if (err == nil) != test.equal {
+			t.Fatalf("ApplyPatch test %s with %s not correct: %#v", test.path, test.value, err)
+		}
+	}
+}
+
+func TestApplyPatch_ObjectOperation(t *testing.T) {
+	patch, _ := geko.JSONUnmarshal([]byte(`[{"op": "add", "path": "/a", "value": 1}]`), geko.UseObject())
+
+	result, err := geko.ApplyPatch(geko.NewMap[string, any](), patch.(geko.Array))
+	if err != nil {
+		t.Fatalf("ApplyPatch with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"a":1}` {
+		t.Fatalf("ApplyPatch result not correct: %s", string(output))
+	}
+}
@@ -0,0 +1,54 @@
+package geko
+
+// GetPath gets the value at path inside root, like root["data"]["items"][3].
+//
+// Elements of path are string keys of [Object] and [ObjectItems], or int
+// indexes of [Array]. For an [ObjectItems], the first value of a duplicated
+// key is used. The second return value is false if any step is missing, or
+// does not match type of current value, like an int index for an object, or a
+// negative index.
+//
+// An empty path returns root itself.
+//
+// See also: [GetPathOr], [PointerGet].
+func GetPath(root any, path ...any) (any, bool) {
+	current := root
+
+	for _, step := range path {
+		var exist bool
+		if current, exist = getPathChild(current, step); !exist {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// GetPathOr likes [GetPath], but returns def if the path does not exist.
+func GetPathOr(root, def any, path ...any) any {
+	if value, exist := GetPath(root, path...); exist {
+		return value
+	}
+	return def
+}
+
+func getPathChild(current, step any) (any, bool) {
+	switch c := current.(type) {
+	case Object:
+		if key, ok := step.(string); ok && c != nil {
+			return c.Get(key)
+		}
+	case ObjectItems:
+		if key, ok := step.(string); ok {
+			if index := firstIndexOfKey(c, key); index >= 0 {
+				return c.GetValueByIndex(index), true
+			}
+		}
+	case Array:
+		if index, ok := step.(int); ok && c != nil && index >= 0 && index < c.Len() {
+			return c.Get(index), true
+		}
+	}
+
+	return nil, false
+}
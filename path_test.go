@@ -0,0 +1,71 @@
+package geko_test
+
+import (
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestGetPath(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{
+		"data": {"items": [{"id": 1}, {"id": 2}, null], "id": "x", "id": "y"},
+		"list": [[true]]
+	}`))
+
+	var m geko.Object
+	var l geko.Array
+	doc.(geko.ObjectItems).Add("m", m)
+	doc.(geko.ObjectItems).Add("l", l)
+
+	tests := []struct {
+		path  []any
+		value any
+		exist bool
+	}{
+		{[]any{"data", "items", 1, "id"}, 2.0, true},
+		{[]any{"data", "id"}, "x", true},
+		{[]any{"list", 0, 0}, true, true},
+		{[]any{"data", "items", 2}, nil, true},
+		{[]any{"data", "items", 2, "id"}, nil, false},
+		{[]any{"data", "items", 3}, nil, false},
+		{[]any{"data", "items", -1}, nil, false},
+		{[]any{"data", "items", "0"}, nil, false},
+		{[]any{"data", 0}, nil, false},
+		{[]any{"data", "items", 0, "name"}, nil, false},
+		{[]any{"data", "id", 0}, nil, false},
+		{[]any{"missing"}, nil, false},
+		{[]any{"m", "a"}, nil, false},
+		{[]any{"l", 0}, nil, false},
+	}
+
+	for _, test := range tests {
+		value, exist := geko.GetPath(doc, test.path...)
+		if exist != test.exist || value != test.value {
+			t.Fatalf("GetPath %v result not correct: %#v, %v", test.path, value, exist)
+		}
+	}
+
+	if value, exist := geko.GetPath(doc); !exist || value != doc {
+		t.Fatalf("GetPath with empty path should return root")
+	}
+
+	object, _ := geko.JSONUnmarshal([]byte(`{"a": {"b": 1}}`), geko.UseObject())
+	if value, exist := geko.GetPath(object, "a", "b"); !exist || value != 1.0 {
+		t.Fatalf("GetPath through object not correct: %#v, %v", value, exist)
+	}
+	if value, exist := geko.GetPath(object, "a", 0); exist {
+		t.Fatalf("GetPath with int index on object should fail: %#v", value)
+	}
+}
+
+func TestGetPathOr(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [1, 2]}`))
+
+	if value := geko.GetPathOr(doc, 0.0, "a", 1); value != 2.0 {
+		t.Fatalf("GetPathOr should return existing value: %#v", value)
+	}
+
+	if value := geko.GetPathOr(doc, "default", "a", 2); value != "default" {
+		t.Fatalf("GetPathOr should return default value: %#v", value)
+	}
+}
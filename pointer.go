@@ -0,0 +1,337 @@
+package geko
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PointerGet returns the value referenced by an [RFC 6901] JSON pointer, like
+// "/spec/containers/0/image", in root. The empty pointer "" references root
+// itself. Escapes "~0" and "~1" in segments mean "~" and "/".
+//
+// Values of [Object], [ObjectItems] and [Array] can be stepped into. For an
+// [ObjectItems], the first member of a duplicated key is used.
+//
+// The returned error is a [*PointerError] if the pointer can't be resolved.
+//
+// [RFC 6901]: https://www.rfc-editor.org/rfc/rfc6901
+func PointerGet(root any, pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return pointerResolve(pointer, tokens, root)
+}
+
+// pointerResolve returns the value referenced by tokens of pointer in root.
+func pointerResolve(pointer string, tokens []string, root any) (any, error) {
+	current := root
+	for i, token := range tokens {
+		var err error
+		if current, err = pointerChild(pointer, i, current, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return current, nil
+}
+
+// parsePointer splits a JSON pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, &PointerError{Pointer: pointer, Segment: 0, Kind: PointerInvalid}
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if !strings.Contains(token, "~") {
+			continue
+		}
+
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, &PointerError{Pointer: pointer, Segment: i, Kind: PointerInvalid}
+			}
+		}
+
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// pointerChild returns the child of current referenced by the i-th token of
+// pointer.
+func pointerChild(pointer string, i int, current any, token string) (any, error) {
+	switch c := current.(type) {
+	case Object:
+		if c != nil {
+			if value, exist := c.Get(token); exist {
+				return value, nil
+			}
+		}
+	case ObjectItems:
+		if index := firstIndexOfKey(c, token); index >= 0 {
+			return c.GetValueByIndex(index), nil
+		}
+	case Array:
+		if index, ok := parseArrayIndex(token); ok && c != nil && index < c.Len() {
+			return c.Get(index), nil
+		}
+	default:
+		return nil, &PointerError{Pointer: pointer, Segment: i, Kind: PointerWrongType}
+	}
+
+	return nil, &PointerError{Pointer: pointer, Segment: i, Kind: PointerNotFound}
+}
+
+// firstIndexOfKey returns the index of the first pair with the key in ps, or -1
+// if not found.
+func firstIndexOfKey(ps ObjectItems, key string) int {
+	if ps == nil {
+		return -1
+	}
+
+	for i := 0; i < ps.Len(); i++ {
+		if ps.GetKeyByIndex(i) == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseArrayIndex parses an array index token, which is "0" or digits without
+// leading zero.
+func parseArrayIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || !isDecimalDigits(token) {
+		return 0, false
+	}
+
+	index, err := strconv.Atoi(token)
+	return index, err == nil
+}
+
+// PointerSet sets value at the place referenced by an [RFC 6901] JSON pointer
+// in root, and returns the new root. Containers are modified in place, so the
+// returned root is root itself, unless pointer is "" which replaces the whole
+// root by value.
+//
+// If the last segment references an existing object member, its value is
+// replaced and it keeps its position, otherwise a new member is added to the
+// end. For an [ObjectItems], the first member of a duplicated key is replaced.
+// If the last segment references an array item, it's replaced, or value is
+// appended if the segment is "-". An index out of range is an error.
+//
+// If createMissing is true, missing object members in the middle of the path
+// are created as empty [Object]. Missing array items are never created.
+//
+// The returned error is a [*PointerError] if the pointer can't be resolved,
+// root is not modified in this case.
+//
+// [RFC 6901]: https://www.rfc-editor.org/rfc/rfc6901
+func PointerSet(root any, pointer string, value any, createMissing bool) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	last := len(tokens) - 1
+
+	current := root
+	for i, token := range tokens[:last] {
+		var child any
+		if child, err = pointerChild(pointer, i, current, token); err != nil {
+			if !createMissing || !isPointerObject(current) {
+				return nil, err
+			}
+
+			child = NewMap[string, any]()
+			if err = pointerSetChild(pointer, i, current, token, child); err != nil {
+				return nil, err
+			}
+		}
+		current = child
+	}
+
+	if err = pointerSetChild(pointer, last, current, tokens[last], value); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// pointerSetChild sets the child of current referenced by the i-th token of
+// pointer to value.
+func pointerSetChild(pointer string, i int, current any, token string, value any) error {
+	switch c := current.(type) {
+	case Object:
+		if c != nil {
+			c.Set(token, value)
+			return nil
+		}
+	case ObjectItems:
+		if c != nil {
+			if index := firstIndexOfKey(c, token); index >= 0 {
+				c.SetValueByIndex(index, value)
+			} else {
+				c.Add(token, value)
+			}
+			return nil
+		}
+	case Array:
+		if c != nil {
+			return pointerSetItem(pointer, i, c, token, value)
+		}
+	}
+
+	return &PointerError{Pointer: pointer, Segment: i, Kind: PointerWrongType}
+}
+
+func pointerSetItem(pointer string, i int, array Array, token string, value any) error {
+	if token == "-" {
+		array.Append(value)
+		return nil
+	}
+
+	index, ok := parseArrayIndex(token)
+	if !ok {
+		return &PointerError{Pointer: pointer, Segment: i, Kind: PointerNotFound}
+	}
+
+	if index >= array.Len() {
+		return &PointerError{Pointer: pointer, Segment: i, Kind: PointerOutOfRange}
+	}
+
+	array.Set(index, value)
+
+	return nil
+}
+
+func isPointerObject(v any) bool {
+	switch v.(type) {
+	case Object, ObjectItems:
+		return true
+	default:
+		return false
+	}
+}
+
+// PointerOptions are options for controlling the behavior of [PointerDelete].
+//
+// Default value (created by [CreatePointerOptions]) of it is:
+//
+//   - Only delete the first member of a duplicated key in [ObjectItems].
+//
+// See also: [CreatePointerOptions], [DeleteAllDuplicates].
+type PointerOptions struct {
+	deleteAll bool
+}
+
+// PointerOption is atom/modifier of [PointerOptions].
+type PointerOption func(opts *PointerOptions)
+
+// CreatePointerOptions creates a [PointerOptions] by apply all option to the
+// default pointer option.
+func CreatePointerOptions(option ...PointerOption) PointerOptions {
+	opts := PointerOptions{}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *PointerOptions) Apply(option ...PointerOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// DeleteAllDuplicates specifies whether [PointerDelete] should delete all
+// members of a duplicated key in [ObjectItems], instead of the first one.
+func DeleteAllDuplicates(v bool) PointerOption {
+	return func(opts *PointerOptions) {
+		opts.deleteAll = v
+	}
+}
+
+// PointerDelete removes the object member or array item referenced by an
+// [RFC 6901] JSON pointer from root, and returns the removed value. Items
+// after a removed array item are shifted.
+//
+// For an [ObjectItems], only the first member of a duplicated key is removed,
+// unless [DeleteAllDuplicates] is applied, the value of the first removed
+// member is returned in this case.
+//
+// The returned error is a [*PointerError] if the pointer can't be resolved,
+// or it references root.
+//
+// [RFC 6901]: https://www.rfc-editor.org/rfc/rfc6901
+func PointerDelete(root any, pointer string, option ...PointerOption) (deleted any, err error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, &PointerError{Pointer: pointer, Segment: 0, Kind: PointerRoot}
+	}
+
+	last := len(tokens) - 1
+
+	current, err := pointerResolve(pointer, tokens[:last], root)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted, err = pointerChild(pointer, last, current, tokens[last]); err != nil {
+		return nil, err
+	}
+
+	opts := CreatePointerOptions(option...)
+	token := tokens[last]
+
+	// current must be a non-nil container which contains the child here
+	switch c := current.(type) {
+	case Object:
+		c.Delete(token)
+	case ObjectItems:
+		if opts.deleteAll {
+			c.Delete(token)
+		} else {
+			c.DeleteByIndex(firstIndexOfKey(c, token))
+		}
+	case Array:
+		index, _ := parseArrayIndex(token)
+		c.Delete(index)
+	}
+
+	return deleted, nil
+}
+
+// escapePointerToken escapes "~" and "/" in a reference token of JSON pointer.
+func escapePointerToken(token string) string {
+	if !strings.ContainsAny(token, "~/") {
+		return token
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// formatPointer formats path, whose elements are string keys of object and
+// int indexes of array, into a JSON pointer.
+func formatPointer(path []any) string {
+	var sb strings.Builder
+	for _, key := range path {
+		_ = sb.WriteByte('/')
+		_, _ = sb.WriteString(escapePointerToken(stdKey(key)))
+	}
+	return sb.String()
+}
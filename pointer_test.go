@@ -0,0 +1,358 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+// Example document from RFC 6901, Section 5.
+const pointerDocument = `{
+	"foo": ["bar", "baz"],
+	"": 0,
+	"a/b": 1,
+	"c%d": 2,
+	"e^f": 3,
+	"g|h": 4,
+	"i\\j": 5,
+	"k\"l": 6,
+	" ": 7,
+	"m~n": 8
+}`
+
+func TestPointerGet(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		excepted any
+	}{
+		{"/foo/0", "bar"},
+		{"/", 0.0},
+		{"/a~1b", 1.0},
+		{"/c%d", 2.0},
+		{"/e^f", 3.0},
+		{"/g|h", 4.0},
+		{"/i\\j", 5.0},
+		{"/k\"l", 6.0},
+		{"/ ", 7.0},
+		{"/m~0n", 8.0},
+	}
+
+	for _, option := range []geko.DecodeOption{geko.UseObject(), geko.UseObjectItems()} {
+		doc, _ := geko.JSONUnmarshal([]byte(pointerDocument), option)
+
+		for _, test := range tests {
+			value, err := geko.PointerGet(doc, test.pointer)
+			if err != nil {
+				t.Fatalf("PointerGet %q with error: %s", test.pointer, err.Error())
+			}
+			if value != test.excepted {
+				t.Fatalf("PointerGet %q result not correct: %#v", test.pointer, value)
+			}
+		}
+
+		if value, _ := geko.PointerGet(doc, ""); value != doc {
+			t.Fatalf("PointerGet empty pointer should return root")
+		}
+
+		value, _ := geko.PointerGet(doc, "/foo")
+		if !reflect.DeepEqual(value.(geko.Array).List, []any{"bar", "baz"}) {
+			t.Fatalf("PointerGet /foo result not correct: %#v", value)
+		}
+	}
+}
+
+func TestPointerGet_DuplicatedKey(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": {"b": 1}, "a": {"b": 2}, "c": [{"d": 3, "d": 4}]}`))
+
+	if _, err := geko.PointerGet(doc, "/a/c"); err == nil {
+		t.Fatalf("PointerGet should fail on missing key")
+	}
+
+	if value, _ := geko.PointerGet(doc, "/a/b"); value != 1.0 {
+		t.Fatalf("PointerGet should use first member of duplicated key: %#v", value)
+	}
+
+	if value, _ := geko.PointerGet(doc, "/c/0/d"); value != 3.0 {
+		t.Fatalf("PointerGet should use first member of duplicated key: %#v", value)
+	}
+}
+
+func TestPointerGet_Error(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [1, {"b": null}], "c": "s"}`), geko.UseObject())
+
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	tests := []struct {
+		root    any
+		pointer string
+		segment int
+		kind    geko.PointerErrorKind
+	}{
+		{doc, "a", 0, geko.PointerInvalid},
+		{doc, "/a/~2", 1, geko.PointerInvalid},
+		{doc, "/a/1/b~", 2, geko.PointerInvalid},
+		{doc, "/x", 0, geko.PointerNotFound},
+		{doc, "/a/2", 1, geko.PointerNotFound},
+		{doc, "/a/-", 1, geko.PointerNotFound},
+		{doc, "/a/01", 1, geko.PointerNotFound},
+		{doc, "/a/+1", 1, geko.PointerNotFound},
+		{doc, "/a/", 1, geko.PointerNotFound},
+		{doc, "/a/99999999999999999999", 1, geko.PointerNotFound},
+		{doc, "/a/1/c", 2, geko.PointerNotFound},
+		{doc, "/c/0", 1, geko.PointerWrongType},
+		{doc, "/a/1/b/0", 3, geko.PointerWrongType},
+		{doc, "/a/0/x", 2, geko.PointerWrongType},
+		{m, "/a", 0, geko.PointerNotFound},
+		{ps, "/a", 0, geko.PointerNotFound},
+		{l, "/0", 0, geko.PointerNotFound},
+		{map[string]any{"a": 1}, "/a", 0, geko.PointerWrongType},
+	}
+
+	for _, test := range tests {
+		value, err := geko.PointerGet(test.root, test.pointer)
+
+		var pointerErr *geko.PointerError
+		if !errors.As(err, &pointerErr) || value != nil {
+			t.Fatalf("PointerGet %q should fail: %#v, %#v", test.pointer, value, err)
+		}
+
+		if pointerErr.Pointer != test.pointer || pointerErr.Segment != test.segment || pointerErr.Kind != test.kind {
+			t.Fatalf("PointerGet %q error not correct: %#v", test.pointer, pointerErr)
+		}
+	}
+
+	_, err := geko.PointerGet(doc, "/c/0")
+	if err.Error() != `geko: json pointer "/c/0": wrong container type at segment 1` {
+		t.Fatalf("PointerError message not correct: %s", err.Error())
+	}
+}
+
+func TestPointerSet(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "b": {"c": [1, 2]}, "d": 2}`), geko.UseObject())
+
+	sets := []struct {
+		pointer string
+		value   any
+	}{
+		{"/a", "x"},
+		{"/e", "y"},
+		{"/b/c/0", 0},
+		{"/b/c/-", 3},
+		{"/b/c/-", 4},
+		{"/b/f", nil},
+	}
+
+	for _, set := range sets {
+		result, err := geko.PointerSet(doc, set.pointer, set.value, false)
+		if err != nil {
+			t.Fatalf("PointerSet %q with error: %s", set.pointer, err.Error())
+		}
+		if result != doc {
+			t.Fatalf("PointerSet should return root")
+		}
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":"x","b":{"c":[0,2,3,4],"f":null},"d":2,"e":"y"}` {
+		t.Fatalf("PointerSet result not correct: %s", string(output))
+	}
+
+	if result, err := geko.PointerSet(doc, "", 1, false); result != 1 || err != nil {
+		t.Fatalf("PointerSet empty pointer should replace root: %#v, %#v", result, err)
+	}
+}
+
+func TestPointerSet_CreateMissing(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": {"b": 1}, "l": []}`), geko.UseObject())
+
+	_, err := geko.PointerSet(doc, "/a/c/d/e", 2, false)
+
+	var pointerErr *geko.PointerError
+	if !errors.As(err, &pointerErr) || pointerErr.Kind != geko.PointerNotFound || pointerErr.Segment != 1 {
+		t.Fatalf("PointerSet should fail on missing member: %#v", err)
+	}
+
+	if _, err = geko.PointerSet(doc, "/a/c/d/e", 2, true); err != nil {
+		t.Fatalf("PointerSet with error: %s", err.Error())
+	}
+
+	if _, err = geko.PointerSet(doc, "/l/0/x", 2, true); err == nil {
+		t.Fatalf("PointerSet should not create array items")
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":{"b":1,"c":{"d":{"e":2}}},"l":[]}` {
+		t.Fatalf("PointerSet result not correct: %s", string(output))
+	}
+
+	if _, ok := doc.(geko.Object).GetOrZeroValue("a").(geko.Object).GetOrZeroValue("c").(geko.Object); !ok {
+		t.Fatalf("PointerSet should create Object")
+	}
+}
+
+func TestPointerSet_ObjectItems(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": {"b": 1, "b": 2}, "a": 3}`))
+
+	for _, pointer := range []string{"/a/b", "/a/c", "/a/x/y"} {
+		if _, err := geko.PointerSet(doc, pointer, 0, true); err != nil {
+			t.Fatalf("PointerSet %q with error: %s", pointer, err.Error())
+		}
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":{"b":0,"b":2,"c":0,"x":{"y":0}},"a":3}` {
+		t.Fatalf("PointerSet result not correct: %s", string(output))
+	}
+}
+
+func TestPointerSet_Error(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [1, 2], "s": "x"}`), geko.UseObject())
+
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	tests := []struct {
+		root    any
+		pointer string
+		segment int
+		kind    geko.PointerErrorKind
+	}{
+		{doc, "a", 0, geko.PointerInvalid},
+		{doc, "/a/2", 1, geko.PointerOutOfRange},
+		{doc, "/a/01", 1, geko.PointerNotFound},
+		{doc, "/a/x/y", 1, geko.PointerNotFound},
+		{doc, "/s/0", 1, geko.PointerWrongType},
+		{doc, "/s/x/y", 1, geko.PointerWrongType},
+		{m, "/a", 0, geko.PointerWrongType},
+		{m, "/a/b", 0, geko.PointerWrongType},
+		{ps, "/a", 0, geko.PointerWrongType},
+		{l, "/-", 0, geko.PointerWrongType},
+	}
+
+	for _, test := range tests {
+		result, err := geko.PointerSet(test.root, test.pointer, 0, true)
+
+		var pointerErr *geko.PointerError
+		if !errors.As(err, &pointerErr) || result != nil {
+			t.Fatalf("PointerSet %q should fail: %#v, %#v", test.pointer, result, err)
+		}
+
+		if pointerErr.Segment != test.segment || pointerErr.Kind != test.kind {
+			t.Fatalf("PointerSet %q error not correct: %#v", test.pointer, pointerErr)
+		}
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":[1,2],"s":"x"}` {
+		t.Fatalf("PointerSet should not modify root on error: %s", string(output))
+	}
+
+	_, err := geko.PointerSet(doc, "/a/5", 0, false)
+	if err.Error() != `geko: json pointer "/a/5": index out of range at segment 1` {
+		t.Fatalf("PointerError message not correct: %s", err.Error())
+	}
+}
+
+func TestPointerDelete(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [[1, 2, 3], [4, 5]], "b": {"c": 1, "d": 2}}`), geko.UseObject())
+
+	tests := []struct {
+		pointer  string
+		excepted any
+	}{
+		{"/a/0/1", 2.0},
+		{"/a/0/0", 1.0},
+		{"/b/c", 1.0},
+	}
+
+	for _, test := range tests {
+		deleted, err := geko.PointerDelete(doc, test.pointer)
+		if err != nil {
+			t.Fatalf("PointerDelete %q with error: %s", test.pointer, err.Error())
+		}
+		if deleted != test.excepted {
+			t.Fatalf("PointerDelete %q result not correct: %#v", test.pointer, deleted)
+		}
+	}
+
+	deleted, _ := geko.PointerDelete(doc, "/a/1")
+	if !reflect.DeepEqual(deleted.(geko.Array).List, []any{4.0, 5.0}) {
+		t.Fatalf("PointerDelete result not correct: %#v", deleted)
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":[[3]],"b":{"d":2}}` {
+		t.Fatalf("PointerDelete result not correct: %s", string(output))
+	}
+}
+
+func TestPointerDelete_ObjectItems(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "b": 2, "a": 3, "a": 4}`))
+
+	deleted, err := geko.PointerDelete(doc, "/a")
+	if deleted != 1.0 || err != nil {
+		t.Fatalf("PointerDelete result not correct: %#v, %#v", deleted, err)
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"b":2,"a":3,"a":4}` {
+		t.Fatalf("PointerDelete should delete first member: %s", string(output))
+	}
+
+	deleted, err = geko.PointerDelete(doc, "/a", geko.DeleteAllDuplicates(true))
+	if deleted != 3.0 || err != nil {
+		t.Fatalf("PointerDelete result not correct: %#v, %#v", deleted, err)
+	}
+
+	output, _ = json.Marshal(doc)
+	if string(output) != `{"b":2}` {
+		t.Fatalf("PointerDelete should delete all members: %s", string(output))
+	}
+}
+
+func TestPointerDelete_Error(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": [1, 2], "s": "x"}`), geko.UseObject())
+
+	tests := []struct {
+		pointer string
+		segment int
+		kind    geko.PointerErrorKind
+	}{
+		{"", 0, geko.PointerRoot},
+		{"a", 0, geko.PointerInvalid},
+		{"/x", 0, geko.PointerNotFound},
+		{"/a/2", 1, geko.PointerNotFound},
+		{"/a/-", 1, geko.PointerNotFound},
+		{"/x/0", 0, geko.PointerNotFound},
+		{"/s/0", 1, geko.PointerWrongType},
+	}
+
+	for _, test := range tests {
+		deleted, err := geko.PointerDelete(doc, test.pointer)
+
+		var pointerErr *geko.PointerError
+		if !errors.As(err, &pointerErr) || deleted != nil {
+			t.Fatalf("PointerDelete %q should fail: %#v, %#v", test.pointer, deleted, err)
+		}
+
+		if pointerErr.Segment != test.segment || pointerErr.Kind != test.kind {
+			t.Fatalf("PointerDelete %q error not correct: %#v", test.pointer, pointerErr)
+		}
+	}
+
+	output, _ := json.Marshal(doc)
+	if string(output) != `{"a":[1,2],"s":"x"}` {
+		t.Fatalf("PointerDelete should not modify root on error: %s", string(output))
+	}
+
+	_, err := geko.PointerDelete(doc, "")
+	if err.Error() != `geko: json pointer "": root can't be deleted` {
+		t.Fatalf("PointerError message not correct: %s", err.Error())
+	}
+}
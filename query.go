@@ -0,0 +1,597 @@
+package geko
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Query selects values in root by a JSONPath expression, and returns them in
+// order. A subset of [RFC 9535] is supported:
+//
+//   - $ is the root, it must be the start of the query.
+//   - .name and ['name'] select a member of object, names in shorthand form
+//     must be letters, digits and underscores, not starting with a digit.
+//     String literals can be quoted by ' or ", with JSON escapes.
+//   - .* and [*] select all members of object, or all items of array.
+//   - [1] and [-1] select an array item by index, a negative index counts
+//     from the end.
+//   - [start:end:step] selects array items by a slice, every part is
+//     optional, like [1:], [:-1] and [::-1].
+//   - [?@.price < 10] and [?(@.active == true)] select members or items which
+//     match a filter. A filter is a path relative to current value @, which
+//     contains only names and indexes, optionally followed by a comparison
+//     with a literal: ==, !=, <, <=, > or >=. Literals are numbers, strings,
+//     true, false and null. A filter without comparison tests existence of the
+//     path, and comparing with a missing path is always false.
+//   - ..name, ..*, ..[...] are recursive descent, which applies the selector
+//     to current value and all its descendants.
+//   - Selectors in brackets can be combined by comma, like [0, 2, 'a'].
+//
+// [Object], [ObjectItems], [Array], map[string]any and []any are supported,
+// members of map[string]any are visited in sorted order. For [ObjectItems],
+// all members with a duplicated key are selected.
+//
+// Values are returned in document order, except selectors combined by comma,
+// which are applied in turn. An empty list is returned if nothing matches.
+//
+// If path is not valid, a [*QueryError] is returned.
+//
+// [RFC 9535]: https://www.rfc-editor.org/rfc/rfc9535
+func Query(root any, path string) (*List[any], error) {
+	segments, err := parseQuery(path)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []any{root}
+	for _, segment := range segments {
+		next := make([]any, 0, len(nodes))
+		for _, node := range nodes {
+			next = segment.apply(node, next)
+		}
+		nodes = next
+	}
+
+	return NewListFrom(nodes), nil
+}
+
+type querySegment struct {
+	descendant bool
+	selectors  []querySelector
+}
+
+func (s *querySegment) apply(node any, result []any) []any {
+	for _, selector := range s.selectors {
+		result = selector.apply(node, result)
+	}
+
+	if s.descendant {
+		for _, child := range queryChildren(node) {
+			result = s.apply(child, result)
+		}
+	}
+
+	return result
+}
+
+// querySelector selects children of a value, and appends them to result.
+type querySelector interface {
+	apply(node any, result []any) []any
+}
+
+type queryName string
+
+func (name queryName) apply(node any, result []any) []any {
+	if object, ok := node.(Object); ok {
+		if object != nil {
+			if value, exist := object.Get(string(name)); exist {
+				result = append(result, value)
+			}
+		}
+		return result
+	}
+
+	members, _ := queryMembers(node)
+	for _, pair := range members {
+		if pair.Key == string(name) {
+			result = append(result, pair.Value)
+		}
+	}
+
+	return result
+}
+
+type queryWildcard struct{}
+
+func (queryWildcard) apply(node any, result []any) []any {
+	return append(result, queryChildren(node)...)
+}
+
+type queryIndex int
+
+func (index queryIndex) apply(node any, result []any) []any {
+	items, _ := queryItems(node)
+
+	i := int(index)
+	if i < 0 {
+		i += len(items)
+	}
+
+	if i >= 0 && i < len(items) {
+		result = append(result, items[i])
+	}
+
+	return result
+}
+
+type querySlice struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+func (s *querySlice) apply(node any, result []any) []any {
+	items, _ := queryItems(node)
+	n := len(items)
+
+	if s.step == 0 {
+		return result
+	}
+
+	// see RFC 9535 section 2.3.4.2.2
+	normalize := func(i, low, high int) int {
+		if i < 0 {
+			i += n
+		}
+		if i < low {
+			return low
+		}
+		if i > high {
+			return high
+		}
+		return i
+	}
+
+	if s.step > 0 {
+		start, end := 0, n
+		if s.hasStart {
+			start = normalize(s.start, 0, n)
+		}
+		if s.hasEnd {
+			end = normalize(s.end, 0, n)
+		}
+		for i := start; i < end; i += s.step {
+			result = append(result, items[i])
+		}
+	} else {
+		start, end := n-1, -1
+		if s.hasStart {
+			start = normalize(s.start, -1, n-1)
+		}
+		if s.hasEnd {
+			end = normalize(s.end, -1, n-1)
+		}
+		for i := start; i > end; i += s.step {
+			result = append(result, items[i])
+		}
+	}
+
+	return result
+}
+
+type queryFilter struct {
+	path    []querySelector
+	op      string
+	literal any
+}
+
+func (f *queryFilter) apply(node any, result []any) []any {
+	for _, child := range queryChildren(node) {
+		if f.match(child) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+func (f *queryFilter) match(v any) bool {
+	for _, selector := range f.path {
+		values := selector.apply(v, nil)
+		if len(values) == 0 {
+			return false
+		}
+		v = values[0]
+	}
+
+	switch f.op {
+	case "":
+		return true
+	case "==":
+		return jsonEqual(v, f.literal, false)
+	case "!=":
+		return !jsonEqual(v, f.literal, false)
+	}
+
+	c, ok := queryCompare(v, f.literal)
+	if !ok {
+		return false
+	}
+
+	switch f.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+// queryCompare compares two numbers or two strings, it returns false if they
+// are not comparable.
+func queryCompare(a, b any) (int, bool) {
+	if x, isNumber := valueFloat64(a); isNumber {
+		y, bothNumber := valueFloat64(b)
+		switch {
+		case !bothNumber:
+			return 0, false
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	x, isString := a.(string)
+	y, bothString := b.(string)
+	return strings.Compare(x, y), isString && bothString
+}
+
+// queryMembers returns members of an object, or false if v is not an object.
+func queryMembers(v any) ([]Pair[string, any], bool) {
+	if isNull(v) {
+		return nil, false
+	}
+	return objectMembers(v)
+}
+
+// queryItems returns items of an array, or false if v is not an array.
+func queryItems(v any) ([]any, bool) {
+	if isNull(v) {
+		return nil, false
+	}
+	return arrayItems(v)
+}
+
+// queryChildren returns values of all members of an object, or all items of
+// an array.
+func queryChildren(v any) []any {
+	if members, isObject := queryMembers(v); isObject {
+		children := make([]any, len(members))
+		for i, pair := range members {
+			children[i] = pair.Value
+		}
+		return children
+	}
+
+	items, _ := queryItems(v)
+	return items
+}
+
+type queryParser struct {
+	query string
+	pos   int
+}
+
+func parseQuery(query string) ([]*querySegment, error) {
+	p := &queryParser{query: query}
+
+	if !p.consume("$") {
+		return nil, p.syntaxError("query must start with $")
+	}
+
+	var segments []*querySegment
+	for p.pos < len(p.query) {
+		segment, err := p.segment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+func (p *queryParser) syntaxError(msg string) error {
+	return &QueryError{Query: p.query, Offset: p.pos, Msg: msg}
+}
+
+func (p *queryParser) consume(s string) bool {
+	if strings.HasPrefix(p.query[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.query) && strings.IndexByte(" \t\n\r", p.query[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *queryParser) segment() (*querySegment, error) {
+	segment := &querySegment{descendant: p.consume("..")}
+
+	if segment.descendant || p.consume(".") {
+		if p.consume("*") {
+			segment.selectors = []querySelector{queryWildcard{}}
+			return segment, nil
+		}
+		if name := p.name(); name != "" {
+			segment.selectors = []querySelector{queryName(name)}
+			return segment, nil
+		}
+		if !segment.descendant || !strings.HasPrefix(p.query[p.pos:], "[") {
+			return nil, p.syntaxError("expect name or *")
+		}
+	}
+
+	if !p.consume("[") {
+		return nil, p.syntaxError("expect . or [")
+	}
+
+	for {
+		p.skipSpaces()
+
+		selector, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		segment.selectors = append(segment.selectors, selector)
+
+		p.skipSpaces()
+		if p.consume("]") {
+			return segment, nil
+		}
+		if !p.consume(",") {
+			return nil, p.syntaxError("expect , or ]")
+		}
+	}
+}
+
+func isQueryNameChar(c byte, first bool) bool {
+	return c == '_' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || (!first && '0' <= c && c <= '9')
+}
+
+func (p *queryParser) name() string {
+	start := p.pos
+	for p.pos < len(p.query) && isQueryNameChar(p.query[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.query[start:p.pos]
+}
+
+func (p *queryParser) selector() (querySelector, error) {
+	switch {
+	case p.consume("*"):
+		return queryWildcard{}, nil
+	case p.consume("?"):
+		return p.filter()
+	case p.pos < len(p.query) && (p.query[p.pos] == '\'' || p.query[p.pos] == '"'):
+		name, err := p.string()
+		return queryName(name), err
+	default:
+		return p.indexOrSlice()
+	}
+}
+
+func (p *queryParser) integer() (int, bool, error) {
+	start := p.pos
+	p.consume("-")
+	for p.pos < len(p.query) && '0' <= p.query[p.pos] && p.query[p.pos] <= '9' {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return 0, false, nil
+	}
+
+	i, err := strconv.Atoi(p.query[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false, p.syntaxError("invalid integer")
+	}
+
+	return i, true, nil
+}
+
+func (p *queryParser) indexOrSlice() (querySelector, error) {
+	var s querySlice
+	var err error
+
+	if s.start, s.hasStart, err = p.integer(); err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if !p.consume(":") {
+		if !s.hasStart {
+			return nil, p.syntaxError("expect selector")
+		}
+		return queryIndex(s.start), nil
+	}
+
+	p.skipSpaces()
+	if s.end, s.hasEnd, err = p.integer(); err != nil {
+		return nil, err
+	}
+
+	s.step = 1
+	p.skipSpaces()
+	if p.consume(":") {
+		p.skipSpaces()
+		var hasStep bool
+		if s.step, hasStep, err = p.integer(); err != nil {
+			return nil, err
+		}
+		if !hasStep {
+			s.step = 1
+		}
+	}
+
+	return &s, nil
+}
+
+func (p *queryParser) string() (string, error) {
+	start := p.pos
+	quote := p.query[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	_ = sb.WriteByte('"')
+
+	for {
+		if p.pos >= len(p.query) {
+			p.pos = start
+			return "", p.syntaxError("unterminated string")
+		}
+
+		c := p.query[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote:
+			_ = sb.WriteByte('"')
+			var s string
+			if err := json.Unmarshal([]byte(sb.String()), &s); err != nil {
+				p.pos = start
+				return "", p.syntaxError("invalid string")
+			}
+			return s, nil
+		case c == '\\' && p.pos < len(p.query) && p.query[p.pos] == '\'':
+			_ = sb.WriteByte('\'')
+			p.pos++
+		case c == '\\' && p.pos < len(p.query):
+			_ = sb.WriteByte(c)
+			_ = sb.WriteByte(p.query[p.pos])
+			p.pos++
+		case c == '"':
+			_, _ = sb.WriteString(`\"`)
+		default:
+			_ = sb.WriteByte(c)
+		}
+	}
+}
+
+func (p *queryParser) filter() (querySelector, error) {
+	p.skipSpaces()
+	paren := p.consume("(")
+	p.skipSpaces()
+
+	if !p.consume("@") {
+		return nil, p.syntaxError("expect @")
+	}
+
+	f := &queryFilter{}
+	for p.pos < len(p.query) && (p.query[p.pos] == '.' || p.query[p.pos] == '[') {
+		selector, err := p.filterPathSelector()
+		if err != nil {
+			return nil, err
+		}
+		f.path = append(f.path, selector)
+	}
+
+	p.skipSpaces()
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			f.op = op
+			break
+		}
+	}
+
+	if f.op != "" {
+		p.skipSpaces()
+		var err error
+		if f.literal, err = p.literal(); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+	}
+
+	if paren && !p.consume(")") {
+		return nil, p.syntaxError("expect )")
+	}
+
+	return f, nil
+}
+
+// filterPathSelector parses a name or index segment in filter path.
+func (p *queryParser) filterPathSelector() (querySelector, error) {
+	if p.consume(".") {
+		if name := p.name(); name != "" {
+			return queryName(name), nil
+		}
+		return nil, p.syntaxError("expect name")
+	}
+
+	p.consume("[")
+	p.skipSpaces()
+
+	var selector querySelector
+	if p.pos < len(p.query) && (p.query[p.pos] == '\'' || p.query[p.pos] == '"') {
+		name, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		selector = queryName(name)
+	} else {
+		index, ok, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, p.syntaxError("expect name or index")
+		}
+		selector = queryIndex(index)
+	}
+
+	p.skipSpaces()
+	if !p.consume("]") {
+		return nil, p.syntaxError("expect ]")
+	}
+
+	return selector, nil
+}
+
+func (p *queryParser) literal() (any, error) {
+	if p.pos < len(p.query) && (p.query[p.pos] == '\'' || p.query[p.pos] == '"') {
+		return p.string()
+	}
+
+	for _, keyword := range []struct {
+		text  string
+		value any
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if p.consume(keyword.text) {
+			return keyword.value, nil
+		}
+	}
+
+	start := p.pos
+	for p.pos < len(p.query) && strings.IndexByte("+-.0123456789eE", p.query[p.pos]) >= 0 {
+		p.pos++
+	}
+
+	f, err := strconv.ParseFloat(p.query[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return nil, p.syntaxError("invalid literal")
+	}
+
+	return f, nil
+}
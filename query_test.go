@@ -0,0 +1,202 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const queryDocument = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3",
+				"price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings",
+				"isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 399}
+	},
+	"items": [
+		{"id": 1, "active": true, "tags": ["a", "b"]},
+		{"id": 2, "active": false},
+		{"id": 3, "active": true, "name": null}
+	],
+	"dup": {"k": 1, "x": 2, "k": 3},
+	"a'b": "quote",
+	"0": "zero"
+}`
+
+func TestQuery(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(queryDocument))
+
+	tests := []struct {
+		query    string
+		excepted string
+	}{
+		{`$.store.book[*].author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$..author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$.store.*`, `[[{"category":"reference","author":"Nigel Rees","title":"Sayings of the Century",` +
+			`"price":8.95},` +
+			`{"category":"fiction","author":"Evelyn Waugh","title":"Sword of Honour","price":12.99},` +
+			`{"category":"fiction","author":"Herman Melville","title":"Moby Dick","isbn":"0-553-21311-3",` +
+			`"price":8.99},{"category":"fiction","author":"J. R. R. Tolkien","title":"The Lord of the Rings",` +
+			`"isbn":"0-395-19395-8","price":22.99}],{"color":"red","price":399}]`},
+		{`$.store..price`, `[8.95,12.99,8.99,22.99,399]`},
+		{`$..book[2].title`, `["Moby Dick"]`},
+		{`$..book[-1].title`, `["The Lord of the Rings"]`},
+		{`$..book[0,1].title`, `["Sayings of the Century","Sword of Honour"]`},
+		{`$..book[:2].title`, `["Sayings of the Century","Sword of Honour"]`},
+		{`$..book[1:3].price`, `[12.99,8.99]`},
+		{`$..book[-2:].price`, `[8.99,22.99]`},
+		{`$..book[::2].price`, `[8.95,8.99]`},
+		{`$..book[::-1].price`, `[22.99,8.99,12.99,8.95]`},
+		{`$..book[3:0:-2].price`, `[22.99,12.99]`},
+		{`$..book[-10:10:3].price`, `[8.95,22.99]`},
+		{`$..book[10:-10:-3].price`, `[22.99,8.95]`},
+		{`$..book[0:4:0].price`, `[]`},
+		{`$..book[4]`, `[]`},
+		{`$..book[-5]`, `[]`},
+		{`$..book[?(@.isbn)].title`, `["Moby Dick","The Lord of the Rings"]`},
+		{`$..book[?@.price < 10].title`, `["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?@.price<=8.99].price`, `[8.95,8.99]`},
+		{`$..book[?@.price > 12.99].price`, `[22.99]`},
+		{`$..book[?@.price >= 12.99].price`, `[12.99,22.99]`},
+		{`$..book[?@.category != 'fiction'].author`, `["Nigel Rees"]`},
+		{`$..book[?@.author > "J"].author`, `["Nigel Rees","J. R. R. Tolkien"]`},
+		{`$..book[?@.author < 1].author`, `[]`},
+		{`$..book[?@.price < "a"].author`, `[]`},
+		{`$..book[?@.isbn == null].author`, `[]`},
+		{`$.items[?(@.active==true)].id`, `[1,3]`},
+		{`$.items[?(@.active == false)].id`, `[2]`},
+		{`$.items[?@.name == null].id`, `[3]`},
+		{`$.items[?@.tags[1] == 'b'].id`, `[1]`},
+		{`$.items[?@['tags'][0]].id`, `[1]`},
+		{`$.items[?@.active < true].id`, `[]`},
+		{`$.items[?@ == 1]`, `[]`},
+		{`$.items[*].tags[*]`, `["a","b"]`},
+		{`$.dup.k`, `[1,3]`},
+		{`$.dup[*]`, `[1,2,3]`},
+		{`$..k`, `[1,3]`},
+		{`$['a\'b']`, `["quote"]`},
+		{`$["a'b", '0']`, `["quote","zero"]`},
+		{`$[ 'store' ][ "bicycle" ][ 'color' ]`, `["red"]`},
+		{`$..[?@ == 'red']`, `["red"]`},
+		{`$..["color"]`, `["red"]`},
+		{`$.store.bicycle[0]`, `[]`},
+		{`$.store.book.title`, `[]`},
+		{`$.missing`, `[]`},
+	}
+
+	for _, test := range tests {
+		result, err := geko.Query(doc, test.query)
+		if err != nil {
+			t.Fatalf("Query %s with error: %s", test.query, err.Error())
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != test.excepted {
+			t.Fatalf("Query %s result not correct: %s", test.query, string(output))
+		}
+	}
+
+	if result, _ := geko.Query(doc, "$"); result.Len() != 1 || result.Get(0) != doc {
+		t.Fatalf("Query of root should return root")
+	}
+}
+
+func TestQuery_Types(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	object, _ := geko.JSONUnmarshal([]byte(`{"x": 3, "y": [4, 5]}`), geko.UseObject())
+
+	doc := map[string]any{
+		"b":   []any{1, map[string]any{"x": 2}},
+		"a":   geko.NewListFrom([]any{m, ps, l, nil}),
+		"c":   object,
+		`d"e`: 6,
+	}
+
+	tests := []struct {
+		query    string
+		excepted string
+	}{
+		{`$.*`, `[[null,null,null,null],[1,{"x":2}],{"x":3,"y":[4,5]},6]`},
+		{`$..x`, `[2,3]`},
+		{`$.c.y[1::]`, `[5]`},
+		{`$['d"e']`, `[6]`},
+		{`$.b[1].x`, `[2]`},
+		{`$.a[*].x`, `[]`},
+		{`$.a[*][0]`, `[]`},
+		{`$.a[*].*`, `[]`},
+		{`$.a[?@ == null]`, `[null,null,null,null]`},
+	}
+
+	for _, test := range tests {
+		result, err := geko.Query(doc, test.query)
+		if err != nil {
+			t.Fatalf("Query %s with error: %s", test.query, err.Error())
+		}
+
+		output, _ := json.Marshal(result)
+		if string(output) != test.excepted {
+			t.Fatalf("Query %s result not correct: %s", test.query, string(output))
+		}
+	}
+
+	if result, _ := geko.Query(doc, "$"); result.Len() != 1 {
+		t.Fatalf("Query of root should return root")
+	}
+}
+
+func TestQuery_Error(t *testing.T) {
+	tests := []struct {
+		query  string
+		offset int
+	}{
+		{``, 0},
+		{`a`, 0},
+		{`$a`, 1},
+		{`$.`, 2},
+		{`$.1`, 2},
+		{`$..`, 3},
+		{`$[`, 2},
+		{`$[]`, 2},
+		{`$[0`, 3},
+		{`$[0 1]`, 4},
+		{`$[99999999999999999999]`, 2},
+		{`$[1:99999999999999999999]`, 4},
+		{`$[1:2:99999999999999999999]`, 6},
+		{`$['a`, 2},
+		{`$['\x']`, 2},
+		{`$[?]`, 3},
+		{`$[?(@.a]`, 7},
+		{`$[?@.]`, 5},
+		{`$[?@[*]]`, 5},
+		{`$[?@['a]`, 5},
+		{`$[?@[99999999999999999999]]`, 5},
+		{`$[?@[0}]`, 6},
+		{`$[?@.a == ]`, 10},
+		{`$[?@.a == 'b]`, 10},
+		{`$[?@.a == 1e]`, 10},
+	}
+
+	for _, test := range tests {
+		result, err := geko.Query(nil, test.query)
+
+		var queryErr *geko.QueryError
+		if !errors.As(err, &queryErr) || queryErr.Offset != test.offset || queryErr.Query != test.query {
+			t.Fatalf("Query %s should fail at offset %d: %#v, %#v", test.query, test.offset, result, err)
+		}
+	}
+
+	_, err := geko.Query(nil, "$.")
+	if err.Error() != `geko: invalid query "$.": expect name or * at offset 2` {
+		t.Fatalf("QueryError message not correct: %s", err.Error())
+	}
+}
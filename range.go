@@ -0,0 +1,143 @@
+package geko
+
+import "errors"
+
+// ErrConcurrentModification means a container is structurally modified while
+// it's iterated by Range methods, like [Map.Range]. They panic with it, to
+// fail fast instead of silently skipping or repeating items.
+var ErrConcurrentModification = errors.New("geko: concurrent/iterative modification detected")
+
+// Range calls fn for every kv pair in current order, until fn returns false.
+//
+// fn can replace the value of an existing key by [Map.Set], which is seen by
+// later steps. But if the map is structurally modified before the iteration
+// ends, like adding or deleting a key, or reordering by [Map.Sort], by fn or
+// by other code, Range panics with [ErrConcurrentModification]. The check is
+// made on a best-effort basis, it's not a replacement of synchronization
+// between goroutines, see [SyncMap] for that.
+//
+// Range a nil map does nothing.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	if m == nil {
+		return
+	}
+
+	mods := m.mods
+
+	for i := 0; i < len(m.order); i++ {
+		key := m.order[i]
+		if !fn(key, m.inner[key]) {
+			return
+		}
+
+		if m.mods != mods {
+			panic(ErrConcurrentModification)
+		}
+	}
+}
+
+// RangeIndex is like [Map.Range], but only visits kv pairs whose index is in
+// [i, j) of current order, and fn also gets the index. No sub map is created.
+//
+// It panics if the range is invalid, like slicing a slice with i and j. A nil
+// map is treated as an empty one.
+func (m *Map[K, V]) RangeIndex(i, j int, fn func(index int, key K, value V) bool) {
+	var order []K
+	if m != nil {
+		order = m.order
+	}
+
+	window := order[i:j]
+	if len(window) == 0 {
+		return
+	}
+
+	mods := m.mods
+
+	for k, key := range window {
+		if !fn(i+k, key, m.inner[key]) {
+			return
+		}
+
+		if m.mods != mods {
+			panic(ErrConcurrentModification)
+		}
+	}
+}
+
+// Range calls fn for every kv pair in current order, until fn returns false.
+//
+// Like [Map.Range], replacing an item by [Pairs.SetValueByIndex] or
+// [Pairs.SetByIndex] is allowed, but a structural modification, like
+// [Pairs.Add] and [Pairs.Delete], panics with [ErrConcurrentModification].
+// Changing length of the List field directly is detected too.
+func (ps *Pairs[K, V]) Range(fn func(key K, value V) bool) {
+	if ps == nil {
+		return
+	}
+
+	mods, length := ps.mods, len(ps.List)
+
+	for i := 0; i < length; i++ {
+		if !fn(ps.List[i].Key, ps.List[i].Value) {
+			return
+		}
+
+		if ps.mods != mods || len(ps.List) != length {
+			panic(ErrConcurrentModification)
+		}
+	}
+}
+
+// RangeIndex is like [Pairs.Range], but only visits kv pairs whose index is
+// in [i, j), and fn also gets the index.
+//
+// It panics if the range is invalid, like slicing a slice with i and j. A nil
+// list is treated as an empty one.
+func (ps *Pairs[K, V]) RangeIndex(i, j int, fn func(index int, key K, value V) bool) {
+	var list []Pair[K, V]
+	if ps != nil {
+		list = ps.List
+	}
+
+	if len(list[i:j]) == 0 {
+		return
+	}
+
+	mods, length := ps.mods, len(ps.List)
+
+	for k := i; k < j; k++ {
+		if !fn(k, ps.List[k].Key, ps.List[k].Value) {
+			return
+		}
+
+		if ps.mods != mods || len(ps.List) != length {
+			panic(ErrConcurrentModification)
+		}
+	}
+}
+
+// Range calls fn for every item in order with its index, until fn returns
+// false.
+//
+// Like [Map.Range], replacing an item by [List.Set] is allowed, but a
+// structural modification, like [List.Append] and [List.Delete], panics with
+// [ErrConcurrentModification]. Changing length of the List field directly is
+// detected too.
+func (l *List[T]) Range(fn func(index int, item T) bool) {
+	if l == nil {
+		return
+	}
+
+	mods, length := l.mods, len(l.List)
+
+	for i := 0; i < length; i++ {
+		if !fn(i, l.List[i]) {
+			return
+		}
+
+		if l.mods != mods || len(l.List) != length {
+			panic(ErrConcurrentModification)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+type (
+	rangeTestMap   = geko.Map[string, int]
+	rangeTestPairs = geko.Pairs[string, int]
+	rangeTestList  = geko.List[int]
+	rangeTestPair  = geko.Pair[string, int]
+)
+
+func assertConcurrentModification(t *testing.T, name string, fn func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		r := recover()
+		if err, _ := r.(error); !errors.Is(err, geko.ErrConcurrentModification) {
+			t.Fatalf("%s inside Range should panic with ErrConcurrentModification: %#v", name, r)
+		}
+	}()
+
+	fn()
+}
+
+func TestMap_Range(t *testing.T) {
+	m := geko.NewMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+
+	var keys []string
+	m.Range(func(key string, value int) bool {
+		keys = append(keys, key)
+		// replace value of existing key is allowed
+		m.Set(key, value*10)
+		m.Set("c", 30)
+		m.Add("a", 100)
+		return key != "b"
+	})
+
+	output, _ := json.Marshal(m)
+	if len(keys) != 2 || string(output) != `{"a":100,"b":20,"c":30}` {
+		t.Fatalf("Range result not correct: %v, %s", keys, string(output))
+	}
+
+	for name, fn := range map[string]func(m *rangeTestMap){
+		"Set new key": func(m *rangeTestMap) { m.Set("d", 4) },
+		"Add new key": func(m *rangeTestMap) { m.Add("d", 4) },
+		"Add move key": func(m *rangeTestMap) {
+			m.SetDuplicatedKeyStrategy(geko.UpdateValueUpdateOrder)
+			m.Add("a", 4)
+		},
+		"Append":        func(m *rangeTestMap) { m.Append(rangeTestPair{Key: "d", Value: 4}) },
+		"Delete":        func(m *rangeTestMap) { m.Delete("c") },
+		"DeleteByIndex": func(m *rangeTestMap) { m.DeleteByIndex(2) },
+		"Clear":         func(m *rangeTestMap) { m.Clear() },
+		"Sort":          func(m *rangeTestMap) { m.Sort(func(a, b *rangeTestPair) bool { return a.Key > b.Key }) },
+		"Filter":        func(m *rangeTestMap) { m.Filter(func(p *rangeTestPair) bool { return p.Value < 3 }) },
+		"UnmarshalJSON": func(m *rangeTestMap) { _ = json.Unmarshal([]byte(`{"d": 4}`), m) },
+		"Transform":     func(m *rangeTestMap) { _, _ = geko.Transform(m, deleteInt(3)) },
+	} {
+		m := geko.NewMap[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+
+		assertConcurrentModification(t, name, func() {
+			m.Range(func(_ string, _ int) bool {
+				fn(m)
+				return true
+			})
+		})
+	}
+
+	// modification is allowed if iteration stops at once
+	m.Range(func(key string, _ int) bool {
+		m.Delete(key)
+		return false
+	})
+
+	// nil map does nothing
+	var nilMap geko.Object
+	nilMap.Range(func(_ string, _ any) bool {
+		t.Fatalf("Range nil map should not call fn")
+		return true
+	})
+}
+
+func deleteInt(target int) geko.TransformFunc {
+	return func(_ []any, value any) (any, error) {
+		if value == target {
+			return nil, geko.ErrDeleteValue
+		}
+		return value, nil
+	}
+}
+
+type rangeIndexTestItem struct {
+	index int
+	key   string
+	value int
+}
+
+func TestMap_RangeIndex(t *testing.T) {
+	m := geko.NewMapOf(geko.P("a", 1), geko.P("b", 2), geko.P("c", 3), geko.P("d", 4))
+
+	var items []rangeIndexTestItem
+	m.RangeIndex(1, 4, func(index int, key string, value int) bool {
+		items = append(items, rangeIndexTestItem{index, key, value})
+		return key != "c"
+	})
+	if !reflect.DeepEqual(items, []rangeIndexTestItem{{1, "b", 2}, {2, "c", 3}}) {
+		t.Fatalf("RangeIndex result not correct: %v", items)
+	}
+
+	for _, r := range [][2]int{{-1, 1}, {2, 1}, {0, 5}} {
+		if !willPanic(func() { m.RangeIndex(r[0], r[1], func(int, string, int) bool { return true }) }) {
+			t.Fatalf("RangeIndex with invalid range %v should panic", r)
+		}
+	}
+
+	var nilMap *rangeTestMap
+	for _, x := range []*rangeTestMap{m, nilMap} {
+		x.RangeIndex(0, 0, func(int, string, int) bool {
+			t.Fatalf("RangeIndex of empty range should not call fn")
+			return true
+		})
+	}
+
+	assertConcurrentModification(t, "Delete", func() {
+		m.RangeIndex(0, 2, func(_ int, key string, _ int) bool {
+			m.Delete(key)
+			return true
+		})
+	})
+}
+
+func TestPairs_RangeIndex(t *testing.T) {
+	ps := geko.NewPairsOf(geko.P("a", 1), geko.P("b", 2), geko.P("a", 3), geko.P("d", 4))
+
+	var items []rangeIndexTestItem
+	ps.RangeIndex(1, 4, func(index int, key string, value int) bool {
+		ps.SetValueByIndex(index+1, value*10)
+		items = append(items, rangeIndexTestItem{index, key, value})
+		return key != "a"
+	})
+	if !reflect.DeepEqual(items, []rangeIndexTestItem{{1, "b", 2}, {2, "a", 20}}) {
+		t.Fatalf("RangeIndex result not correct: %v", items)
+	}
+
+	for _, r := range [][2]int{{-1, 1}, {2, 1}, {0, 5}} {
+		if !willPanic(func() { ps.RangeIndex(r[0], r[1], func(int, string, int) bool { return true }) }) {
+			t.Fatalf("RangeIndex with invalid range %v should panic", r)
+		}
+	}
+
+	var nilPairs *rangeTestPairs
+	for _, x := range []*rangeTestPairs{ps, nilPairs} {
+		x.RangeIndex(0, 0, func(int, string, int) bool {
+			t.Fatalf("RangeIndex of empty range should not call fn")
+			return true
+		})
+	}
+
+	assertConcurrentModification(t, "Add", func() {
+		ps.RangeIndex(0, 2, func(_ int, key string, _ int) bool {
+			ps.Add(key, 0)
+			return true
+		})
+	})
+
+	assertConcurrentModification(t, "Shrink List", func() {
+		ps.RangeIndex(0, 2, func(int, string, int) bool {
+			ps.List = ps.List[:1]
+			return true
+		})
+	})
+
+	items = nil
+	ps = geko.NewPairsOf(geko.P("a", 1), geko.P("b", 2), geko.P("c", 3))
+	ps.RangeIndex(0, 3, func(index int, key string, value int) bool {
+		// replacing the List field is seen by later steps, like Range
+		ps.List = []rangeTestPair{geko.P("x", 10), geko.P("y", 20), geko.P("z", 30)}
+		items = append(items, rangeIndexTestItem{index, key, value})
+		return true
+	})
+	if !reflect.DeepEqual(items, []rangeIndexTestItem{{0, "a", 1}, {1, "y", 20}, {2, "z", 30}}) {
+		t.Fatalf("RangeIndex result after replacing List not correct: %v", items)
+	}
+}
+
+func TestPairs_Range(t *testing.T) {
+	ps := geko.NewPairs[string, int]()
+	ps.Add("a", 1)
+	ps.Add("b", 2)
+	ps.Add("a", 3)
+
+	var keys []string
+	ps.Range(func(key string, value int) bool {
+		keys = append(keys, key)
+		ps.SetValueByIndex(len(keys)-1, value*10)
+		ps.SetByIndex(0, "x", 0)
+		return true
+	})
+
+	output, _ := json.Marshal(ps)
+	if len(keys) != 3 || string(output) != `{"x":0,"b":20,"a":30}` {
+		t.Fatalf("Range result not correct: %v, %s", keys, string(output))
+	}
+
+	keys = nil
+	ps.Range(func(key string, _ int) bool {
+		keys = append(keys, key)
+		return false
+	})
+
+	if len(keys) != 1 {
+		t.Fatalf("Range should stop when fn returns false: %v", keys)
+	}
+
+	for name, fn := range map[string]func(ps *rangeTestPairs){
+		"Add":           func(ps *rangeTestPairs) { ps.Add("a", 4) },
+		"Append":        func(ps *rangeTestPairs) { ps.Append(rangeTestPair{Key: "d", Value: 4}) },
+		"Delete":        func(ps *rangeTestPairs) { ps.Delete("b") },
+		"DeleteByIndex": func(ps *rangeTestPairs) { ps.DeleteByIndex(2) },
+		"Clear":         func(ps *rangeTestPairs) { ps.Clear() },
+		"Dedup":         func(ps *rangeTestPairs) { ps.Dedup(geko.Ignore) },
+		"Sort": func(ps *rangeTestPairs) {
+			ps.Sort(func(a, b *rangeTestPair) bool { return a.Key < b.Key })
+		},
+		"Filter": func(ps *rangeTestPairs) {
+			ps.Filter(func(p *rangeTestPair) bool { return p.Value < 3 })
+		},
+		"Transform": func(ps *rangeTestPairs) { _, _ = geko.Transform(ps, deleteInt(2)) },
+		"Field":     func(ps *rangeTestPairs) { ps.List = ps.List[:1] },
+	} {
+		ps := geko.NewPairs[string, int]()
+		ps.Add("a", 1)
+		ps.Add("b", 2)
+		ps.Add("a", 3)
+
+		assertConcurrentModification(t, name, func() {
+			ps.Range(func(_ string, _ int) bool {
+				fn(ps)
+				return true
+			})
+		})
+	}
+
+	var nilPairs geko.ObjectItems
+	nilPairs.Range(func(_ string, _ any) bool {
+		t.Fatalf("Range nil pairs should not call fn")
+		return true
+	})
+}
+
+func TestList_Range(t *testing.T) {
+	l := geko.NewListFrom([]int{1, 2, 3})
+
+	var indexes []int
+	l.Range(func(index, item int) bool {
+		indexes = append(indexes, index)
+		l.Set(index, item*10)
+		return index < 1
+	})
+
+	if len(indexes) != 2 || l.Get(0) != 10 || l.Get(1) != 20 || l.Get(2) != 3 {
+		t.Fatalf("Range result not correct: %v, %v", indexes, l.List)
+	}
+
+	for name, fn := range map[string]func(l *rangeTestList){
+		"Append":          func(l *rangeTestList) { l.Append(4) },
+		"Delete":          func(l *rangeTestList) { l.Delete(0) },
+		"Delete+Append":   func(l *rangeTestList) { l.Delete(0); l.Append(4) },
+		"Transform":       func(l *rangeTestList) { _, _ = geko.Transform(l, deleteInt(2)) },
+		"UnmarshalJSON":   func(l *rangeTestList) { _ = json.Unmarshal([]byte(`[4, 5, 6]`), l) },
+		"GobDecode":       func(l *rangeTestList) { data, _ := l.GobEncode(); _ = l.GobDecode(data) },
+		"UnmarshalBinary": func(l *rangeTestList) { data, _ := l.MarshalBinary(); _ = l.UnmarshalBinary(data) },
+		"Field":           func(l *rangeTestList) { l.List = append(l.List, 4) },
+	} {
+		l := geko.NewListFrom([]int{1, 2, 3})
+
+		assertConcurrentModification(t, name, func() {
+			l.Range(func(_, _ int) bool {
+				fn(l)
+				return true
+			})
+		})
+	}
+
+	var nilList geko.Array
+	nilList.Range(func(_ int, _ any) bool {
+		t.Fatalf("Range nil list should not call fn")
+		return true
+	})
+}
@@ -0,0 +1,73 @@
+package geko
+
+import "strings"
+
+// RedactFunc is the type of the function called by [Redact] for each object
+// member, to decide whether its value should be redacted.
+//
+// The path is the location of the object which contains the member, its
+// elements are string keys of object, and int indexes of array, like
+// [WalkFunc]. The path slice is reused between calls, copy it if you need to
+// keep it.
+type RedactFunc func(path []any, key string) bool
+
+// RedactKeys creates a [RedactFunc] which matches members whose key is one of
+// keys, case-insensitively, at any depth.
+func RedactKeys(keys ...string) RedactFunc {
+	return func(_ []any, key string) bool {
+		for _, k := range keys {
+			if strings.EqualFold(k, key) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Redact returns a deep copy of root, in which values of object members
+// matched by shouldRedact are replaced by replacement, at any depth. It's
+// useful to mask secrets before logging.
+//
+// [Object], [ObjectItems] and [Array] are descended into, every member of a
+// duplicated key in an [ObjectItems] is checked. Values of redacted members
+// are not descended into. Other values, including map[string]any and []any,
+// are leaves and shared with root.
+//
+// root is never modified.
+func Redact(root any, shouldRedact RedactFunc, replacement any) any {
+	r := redactor{shouldRedact: shouldRedact, replacement: replacement}
+	return r.redact(make([]any, 0, 8), cloneValue(root))
+}
+
+type redactor struct {
+	shouldRedact RedactFunc
+	replacement  any
+}
+
+// redact redacts value in place, which is a copy.
+func (r *redactor) redact(path []any, value any) any {
+	switch x := value.(type) {
+	case Object:
+		for i := 0; x != nil && i < x.Len(); i++ {
+			pair := x.GetByIndex(i)
+			x.Set(pair.Key, r.member(path, pair.Key, pair.Value))
+		}
+	case ObjectItems:
+		for i := 0; x != nil && i < x.Len(); i++ {
+			x.List[i].Value = r.member(path, x.List[i].Key, x.List[i].Value)
+		}
+	case Array:
+		for i := 0; x != nil && i < x.Len(); i++ {
+			x.List[i] = r.redact(append(path, i), x.List[i])
+		}
+	}
+
+	return value
+}
+
+func (r *redactor) member(path []any, key string, value any) any {
+	if r.shouldRedact(path, key) {
+		return r.replacement
+	}
+	return r.redact(append(path, key), value)
+}
@@ -0,0 +1,73 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestRedact(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{
+		"user": "alice",
+		"Password": "p1",
+		"db": {"host": "h", "password": "p2", "token": {"id": 1}},
+		"list": [{"TOKEN": "t1", "x": 1}, [{"password": "p3"}]],
+		"dup": {"password": "p4", "n": 1, "password": "p5"}
+	}`))
+
+	result := geko.Redact(doc, geko.RedactKeys("password", "token"), "***")
+
+	output, _ := json.Marshal(result)
+	excepted := `{"user":"alice","Password":"***","db":{"host":"h","password":"***","token":"***"},` +
+		`"list":[{"TOKEN":"***","x":1},[{"password":"***"}]],"dup":{"password":"***","n":1,"password":"***"}}`
+	if string(output) != excepted {
+		t.Fatalf("Redact result not correct: %s", string(output))
+	}
+
+	output, _ = json.Marshal(doc)
+	if string(output) != `{"user":"alice","Password":"p1","db":{"host":"h","password":"p2","token":{"id":1}},`+
+		`"list":[{"TOKEN":"t1","x":1},[{"password":"p3"}]],"dup":{"password":"p4","n":1,"password":"p5"}}` {
+		t.Fatalf("Redact should not modify root: %s", string(output))
+	}
+}
+
+func TestRedact_Path(t *testing.T) {
+	doc, _ := geko.JSONUnmarshal([]byte(`{"a": {"secret": 1}, "l": [{"secret": 2}], "secret": 3}`), geko.UseObject())
+
+	var paths []string
+	result := geko.Redact(doc, func(path []any, key string) bool {
+		paths = append(paths, fmt.Sprintf("%v:%s", path, key))
+		return key == "secret" && len(path) > 0
+	}, nil)
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"a":{"secret":null},"l":[{"secret":null}],"secret":3}` {
+		t.Fatalf("Redact result not correct: %s", string(output))
+	}
+
+	if fmt.Sprint(paths) != `[[]:a [a]:secret []:l [l 0]:secret []:secret]` {
+		t.Fatalf("Redact paths not correct: %v", paths)
+	}
+}
+
+func TestRedact_Values(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	std := map[string]any{"password": 1}
+	doc := geko.NewListFrom([]any{m, ps, l, std, "password"})
+
+	result := geko.Redact(doc, geko.RedactKeys("password"), "***")
+
+	output, _ := json.Marshal(result)
+	if string(output) != `[null,null,null,{"password":1},"password"]` {
+		t.Fatalf("Redact result not correct: %s", string(output))
+	}
+
+	if value := geko.Redact(1, geko.RedactKeys("password"), "***"); value != 1 {
+		t.Fatalf("Redact of scalar should return itself: %#v", value)
+	}
+}
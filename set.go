@@ -0,0 +1,151 @@
+package geko
+
+// Set is a set of unique keys, which keeps their insertion order.
+//
+// It's backed by a [Map] whose values are empty structs, so adding an
+// existing key does not change its position, and [Set.Values] returns keys in
+// the order they are first added.
+//
+// The zero value is an empty set ready to use.
+type Set[K comparable] struct {
+	m Map[K, struct{}]
+}
+
+// NewSet creates a new empty [Set].
+func NewSet[K comparable]() *Set[K] {
+	return &Set[K]{}
+}
+
+// NewSetOf creates a [Set] contains keys, duplicated ones are added only once
+// at the position they first appear.
+//
+//	geko.NewSetOf("b", "a", "b") // ["b", "a"]
+func NewSetOf[K comparable](keys ...K) *Set[K] {
+	s := NewSet[K]()
+	for _, key := range keys {
+		_ = s.Add(key)
+	}
+	return s
+}
+
+// Add a key into the set, at the end. If the key already exists, its position
+// is not changed.
+//
+// The return value tells if the key is newly added.
+func (s *Set[K]) Add(key K) bool {
+	if s.m.Has(key) {
+		return false
+	}
+	s.m.Set(key, struct{}{})
+	return true
+}
+
+// Has checks if key exist in the set.
+func (s *Set[K]) Has(key K) bool {
+	return s.m.Has(key)
+}
+
+// Delete a key from the set. The return value tells if the key existed.
+//
+// Performance: O(n), like [Map.Delete].
+func (s *Set[K]) Delete(key K) bool {
+	if !s.m.Has(key) {
+		return false
+	}
+	s.m.Delete(key)
+	return true
+}
+
+// Len returns the size of set.
+func (s *Set[K]) Len() int {
+	return s.m.Len()
+}
+
+// Values returns a copy of all keys in the set, in insertion order.
+func (s *Set[K]) Values() []K {
+	return s.m.Keys()
+}
+
+// Range calls fn for every key in insertion order, until fn returns false.
+//
+// Like [Map.Range], if the set is modified before the iteration ends, Range
+// panics with [ErrConcurrentModification].
+func (s *Set[K]) Range(fn func(key K) bool) {
+	s.m.Range(func(key K, _ struct{}) bool {
+		return fn(key)
+	})
+}
+
+// Union returns a new [Set] contains keys in s, followed by keys in other but
+// not in s, both in their insertion order. A nil other is an empty set.
+func (s *Set[K]) Union(other *Set[K]) *Set[K] {
+	result := NewSetOf(s.Values()...)
+	if other != nil {
+		for _, key := range other.m.order {
+			_ = result.Add(key)
+		}
+	}
+	return result
+}
+
+// Intersect returns a new [Set] contains keys in both s and other, in
+// insertion order of s. A nil other is an empty set.
+func (s *Set[K]) Intersect(other *Set[K]) *Set[K] {
+	return s.filter(func(key K) bool {
+		return other != nil && other.Has(key)
+	})
+}
+
+// Difference returns a new [Set] contains keys in s but not in other, in
+// insertion order of s. A nil other is an empty set.
+func (s *Set[K]) Difference(other *Set[K]) *Set[K] {
+	return s.filter(func(key K) bool {
+		return other == nil || !other.Has(key)
+	})
+}
+
+func (s *Set[K]) filter(pred func(key K) bool) *Set[K] {
+	result := NewSet[K]()
+	for _, key := range s.m.order {
+		if pred(key) {
+			_ = result.Add(key)
+		}
+	}
+	return result
+}
+
+func (s *Set[K]) encodeJSON(e *encodeState) error {
+	if s == nil {
+		_, _ = e.WriteString("null")
+		return nil
+	}
+	return NewListFrom(s.m.order).encodeJSON(e)
+}
+
+// MarshalJSON implements [json.Marshaler] interface, the set is encoded as a
+// JSON array of its keys, in insertion order.
+//
+// You should not call this directly, use [json.Marshal] instead.
+func (s Set[K]) MarshalJSON() ([]byte, error) {
+	return NewListFrom(s.m.order).MarshalJSON()
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] interface, it decodes a JSON
+// array like [List], and adds its items into the set, in order. Duplicated
+// items are added only once, at the position they first appear. Like a std
+// map, existing keys are kept.
+//
+// You shouldn't call this directly, use [json.Unmarshal]/[JSONUnmarshal]
+// instead.
+func (s *Set[K]) UnmarshalJSON(data []byte) error {
+	var items List[K]
+	if err := items.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	for _, key := range items.List {
+		_ = s.Add(key)
+	}
+
+	return nil
+}
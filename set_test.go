@@ -0,0 +1,120 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestSet(t *testing.T) {
+	var s geko.Set[string]
+
+	for _, c := range []struct {
+		key      string
+		excepted bool
+	}{
+		{"b", true},
+		{"a", true},
+		{"b", false},
+		{"c", true},
+	} {
+		if s.Add(c.key) != c.excepted {
+			t.Fatalf("Add %s should return %v", c.key, c.excepted)
+		}
+	}
+
+	if !s.Has("a") || s.Has("d") || s.Len() != 3 {
+		t.Fatalf("Set content not correct: %v", s.Values())
+	}
+
+	if !s.Delete("a") || s.Delete("a") || s.Has("a") {
+		t.Fatalf("Delete result not correct: %v", s.Values())
+	}
+
+	_ = s.Add("a")
+	if !reflect.DeepEqual(s.Values(), []string{"b", "c", "a"}) {
+		t.Fatalf("Values not in insertion order: %v", s.Values())
+	}
+}
+
+func TestSet_Range(t *testing.T) {
+	s := geko.NewSetOf(3, 1, 2, 1)
+
+	var keys []int
+	s.Range(func(key int) bool {
+		keys = append(keys, key)
+		return key != 1
+	})
+	if !reflect.DeepEqual(keys, []int{3, 1}) {
+		t.Fatalf("Range result not correct: %v", keys)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, geko.ErrConcurrentModification) {
+			t.Fatalf("Range should panic when set is modified: %v", err)
+		}
+	}()
+
+	s.Range(func(key int) bool {
+		_ = s.Add(key + 10)
+		return true
+	})
+}
+
+func TestSet_Algebra(t *testing.T) {
+	a := geko.NewSetOf("x", "b", "a", "y")
+	b := geko.NewSetOf("c", "a", "b", "d")
+
+	for _, c := range []struct {
+		name     string
+		result   *geko.Set[string]
+		excepted []string
+	}{
+		{"Union", a.Union(b), []string{"x", "b", "a", "y", "c", "d"}},
+		{"Union reversed", b.Union(a), []string{"c", "a", "b", "d", "x", "y"}},
+		{"Intersect", a.Intersect(b), []string{"b", "a"}},
+		{"Intersect reversed", b.Intersect(a), []string{"a", "b"}},
+		{"Difference", a.Difference(b), []string{"x", "y"}},
+		{"Difference reversed", b.Difference(a), []string{"c", "d"}},
+		{"Union nil", a.Union(nil), []string{"x", "b", "a", "y"}},
+		{"Intersect nil", a.Intersect(nil), []string{}},
+		{"Difference nil", a.Difference(nil), []string{"x", "b", "a", "y"}},
+	} {
+		if !reflect.DeepEqual(c.result.Values(), c.excepted) {
+			t.Fatalf("%s result not correct: %v", c.name, c.result.Values())
+		}
+	}
+
+	if a.Len() != 4 || b.Len() != 4 {
+		t.Fatalf("Set algebra should not modify operands")
+	}
+}
+
+func TestSet_JSON(t *testing.T) {
+	s := geko.NewSetOf("z")
+	if err := json.Unmarshal([]byte(`["b", "a", "b", "z", "c", "a"]`), s); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(s.Values(), []string{"z", "b", "a", "c"}) {
+		t.Fatalf("Unmarshal result not correct: %v", s.Values())
+	}
+
+	output, _ := json.Marshal(s)
+	if string(output) != `["z","b","a","c"]` {
+		t.Fatalf("Marshal result not correct: %s", string(output))
+	}
+
+	var empty *geko.Set[int]
+	output, _ = geko.JSONMarshal(geko.NewListOf[any](geko.NewSet[int](), empty, geko.NewSetOf(1, 2)))
+	if string(output) != `[[],null,[1,2]]` {
+		t.Fatalf("JSONMarshal result not correct: %s", string(output))
+	}
+
+	if err := json.Unmarshal([]byte(`[1, "a"]`), geko.NewSet[int]()); err == nil {
+		t.Fatalf("Unmarshal item of wrong type should fail")
+	}
+}
@@ -0,0 +1,86 @@
+//go:build go1.21
+
+package geko
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+// slogValue converts v into [slog.Value], containers are resolved eagerly.
+func slogValue(v any) slog.Value {
+	if isNull(v) {
+		return slog.AnyValue(nil)
+	}
+
+	switch x := v.(type) {
+	case slog.LogValuer:
+		return x.LogValue()
+	case map[string]any:
+		members, _ := objectMembers(x)
+		attrs := make([]slog.Attr, len(members))
+		for i, pair := range members {
+			attrs[i] = slog.Attr{Key: pair.Key, Value: slogValue(pair.Value)}
+		}
+		return slog.GroupValue(attrs...)
+	case []any:
+		return slogItems(x)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+func slogItems[T any](items []T) slog.Value {
+	attrs := make([]slog.Attr, len(items))
+	for i, item := range items {
+		attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: slogValue(item)}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [slog.LogValuer] interface. The map is logged as a
+// group, whose attributes are members in insertion order. Keys of other types
+// are formatted like [ToStdTypes] does.
+//
+// Nested [Map], [Pairs], [List], map[string]any and []any values are
+// resolved into groups too, members of map[string]any are sorted. Notice that
+// most handlers omit empty groups, like an empty object.
+func (m *Map[K, V]) LogValue() slog.Value {
+	if m == nil {
+		return slog.AnyValue(nil)
+	}
+
+	attrs := make([]slog.Attr, len(m.order))
+	for i, key := range m.order {
+		attrs[i] = slog.Attr{Key: stdKey(key), Value: slogValue(m.inner[key])}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [slog.LogValuer] interface. The pairs are logged as a
+// group, duplicated keys are all kept. See [Map.LogValue] for detail.
+func (ps *Pairs[K, V]) LogValue() slog.Value {
+	if ps == nil {
+		return slog.AnyValue(nil)
+	}
+
+	attrs := make([]slog.Attr, len(ps.List))
+	for i, pair := range ps.List {
+		attrs[i] = slog.Attr{Key: stdKey(pair.Key), Value: slogValue(pair.Value)}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements [slog.LogValuer] interface. The list is logged as a
+// group, whose attributes are items keyed by their index, like "0", "1" and
+// so on. So handlers can process items one by one, like they do for objects.
+// See [Map.LogValue] for detail.
+func (l *List[T]) LogValue() slog.Value {
+	if l == nil {
+		return slog.AnyValue(nil)
+	}
+
+	return slogItems(l.List)
+}
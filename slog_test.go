@@ -0,0 +1,134 @@
+//go:build go1.21
+
+package geko_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+// slogTestHandler records keys of all attributes, nested keys are joined by
+// dot.
+type slogTestHandler struct {
+	keys []string
+}
+
+func (h *slogTestHandler) Enabled(_ context.Context, _ slog.Level) bool {
+	return true
+}
+
+func (h *slogTestHandler) record(prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		h.keys = append(h.keys, prefix+attr.Key+"="+value.String())
+		return
+	}
+
+	for _, child := range value.Group() {
+		h.record(prefix+attr.Key+".", child)
+	}
+}
+
+func (h *slogTestHandler) Handle(_ context.Context, r slog.Record) error {
+	r.Attrs(func(attr slog.Attr) bool {
+		h.record("", attr)
+		return true
+	})
+	return nil
+}
+
+func (h *slogTestHandler) WithAttrs(_ []slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *slogTestHandler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+func TestLogValue(t *testing.T) {
+	v, _ := geko.JSONUnmarshal(
+		[]byte(`{"z": 1, "a": [true, {"y": "s", "x": null}], "m": {"c": 1, "b": 2}}`),
+		geko.UseObject(),
+	)
+	object := v.(geko.Object)
+	object.Set("std", map[string]any{"b": []any{1}, "a": nil})
+
+	h := &slogTestHandler{}
+	slog.New(h).Info("msg", "object", object)
+
+	excepted := "object.z=1,object.a.0=true,object.a.1.y=s,object.a.1.x=<nil>," +
+		"object.m.c=1,object.m.b=2,object.std.a=<nil>,object.std.b.0=1"
+	if output := strings.Join(h.keys, ","); output != excepted {
+		t.Fatalf("LogValue result not correct: %s", output)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})).Info("msg", "object", object)
+
+	excepted = `{"level":"INFO","msg":"msg","object":{"z":1,"a":{"0":true,"1":{"y":"s","x":null}},` +
+		`"m":{"c":1,"b":2},"std":{"a":null,"b":{"0":1}}}}` + "\n"
+	if buf.String() != excepted {
+		t.Fatalf("LogValue JSON output not correct: %s", buf.String())
+	}
+}
+
+func TestLogValue_Typed(t *testing.T) {
+	items, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "a": 2}`))
+
+	m := geko.NewMap[int, *geko.List[string]]()
+	m.Set(2, geko.NewListFrom([]string{"x"}))
+	m.Set(1, nil)
+
+	var nilArray geko.Array
+
+	h := &slogTestHandler{}
+	slog.New(h).Info("msg", "items", items, "m", m, "nil", nilArray)
+
+	if output := strings.Join(h.keys, ","); output != "items.a=1,items.a=2,m.2.0=x,m.1=<nil>,nil=<nil>" {
+		t.Fatalf("LogValue result not correct: %s", output)
+	}
+
+	var nilObject geko.Object
+	var nilItems geko.ObjectItems
+	for _, v := range []slog.LogValuer{nilObject, nilItems} {
+		if value := v.LogValue(); value.Any() != nil {
+			t.Fatalf("LogValue of nil should be nil: %#v", value)
+		}
+	}
+}
+
+func BenchmarkLogValue(b *testing.B) {
+	v, _ := geko.JSONUnmarshal(
+		[]byte(`{"id": 1, "name": "geko", "tags": ["a", "b"], "owner": {"id": 2, "active": true}}`),
+		geko.UseObject(),
+	)
+	object := v.(geko.Object)
+	logger := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	b.Run("LogValue", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Info("request", "body", object)
+		}
+	})
+
+	b.Run("ToStdTypes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Info("request", "body", geko.ToStdTypes(object))
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package geko
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Value implements [driver.Valuer] interface, so the map can be stored into a
+// JSON or text column of a database. The value is its JSON text as string, or
+// nil (SQL NULL) if the map is nil.
+//
+// Notice: PostgreSQL jsonb columns don't preserve key order, use json or text
+// columns if the order matters.
+func (m *Map[K, V]) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return sqlValue(m)
+}
+
+// Scan implements [sql.Scanner] interface, so the map can be read from a
+// JSON or text column of a database. src can be []byte, string or nil (SQL
+// NULL), current content of the map is replaced, and a NULL makes the map
+// empty.
+//
+// Like [Map.UnmarshalJSON], duplicated keys are dealt with the
+// [DuplicatedKeyStrategy] of the map.
+func (m *Map[K, V]) Scan(src any) error {
+	if err := m.checkFrozen("Scan"); err != nil {
+		return err
+	}
+
+	data, err := sqlScan(src, m)
+	if err != nil {
+		return err
+	}
+
+	m.Clear()
+	if data == nil {
+		return nil
+	}
+
+	return m.UnmarshalJSON(data)
+}
+
+// Value implements [driver.Valuer] interface, see [Map.Value] for detail.
+func (ps *Pairs[K, V]) Value() (driver.Value, error) {
+	if ps == nil {
+		return nil, nil
+	}
+	return sqlValue(ps)
+}
+
+// Scan implements [sql.Scanner] interface, see [Map.Scan] for detail. All
+// values of duplicated keys are kept.
+func (ps *Pairs[K, V]) Scan(src any) error {
+	if err := ps.checkFrozen("Scan"); err != nil {
+		return err
+	}
+
+	data, err := sqlScan(src, ps)
+	if err != nil {
+		return err
+	}
+
+	ps.Clear()
+	if data == nil {
+		return nil
+	}
+
+	return ps.UnmarshalJSON(data)
+}
+
+// Value implements [driver.Valuer] interface, see [Map.Value] for detail.
+func (l *List[T]) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return sqlValue(l)
+}
+
+// Scan implements [sql.Scanner] interface, src can be []byte, string or nil
+// (SQL NULL).
+//
+// Like [List.UnmarshalJSON], the decode options of the list are used, the
+// inner slice is replaced unless [List.AppendOnUnmarshal] is enabled, and a
+// NULL makes the inner slice nil.
+func (l *List[T]) Scan(src any) error {
+	if err := l.checkFrozen("Scan"); err != nil {
+		return err
+	}
+
+	data, err := sqlScan(src, l)
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		data = []byte("null")
+	}
+
+	return l.UnmarshalJSON(data)
+}
+
+func sqlValue(v any) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// sqlScan returns JSON data in src, or nil if src is NULL.
+func sqlScan(src any, dest any) ([]byte, error) {
+	switch x := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return x, nil
+	case string:
+		return []byte(x), nil
+	default:
+		return nil, fmt.Errorf("geko: can't scan %T into %T", src, dest)
+	}
+}
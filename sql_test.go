@@ -0,0 +1,201 @@
+package geko_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+// sqlTestDriver is a fake database with one column, which stores values
+// inserted by Exec, and returns them by Query, in order.
+type sqlTestDriver struct {
+	values []driver.Value
+}
+
+func (d *sqlTestDriver) Open(_ string) (driver.Conn, error) {
+	return d, nil
+}
+
+func (d *sqlTestDriver) Prepare(_ string) (driver.Stmt, error) {
+	return d, nil
+}
+
+func (d *sqlTestDriver) Close() error {
+	return nil
+}
+
+func (d *sqlTestDriver) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (d *sqlTestDriver) NumInput() int {
+	return -1
+}
+
+func (d *sqlTestDriver) Exec(args []driver.Value) (driver.Result, error) {
+	d.values = append(d.values, args...)
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (d *sqlTestDriver) Query(_ []driver.Value) (driver.Rows, error) {
+	return &sqlTestRows{values: d.values}, nil
+}
+
+type sqlTestRows struct {
+	values []driver.Value
+}
+
+func (r *sqlTestRows) Columns() []string {
+	return []string{"data"}
+}
+
+func (r *sqlTestRows) Close() error {
+	return nil
+}
+
+func (r *sqlTestRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0], r.values = r.values[0], r.values[1:]
+	return nil
+}
+
+var sqlTestDB = &sqlTestDriver{}
+
+func init() {
+	sql.Register("geko-test", sqlTestDB)
+}
+
+func TestSQL_RoundTrip(t *testing.T) {
+	db, err := sql.Open("geko-test", "")
+	if err != nil {
+		t.Fatalf("Open with error: %s", err.Error())
+	}
+	defer db.Close()
+
+	v, _ := geko.JSONUnmarshal([]byte(`{"z": 1, "a": [true, {"y": null, "x": "s"}]}`), geko.UseObject())
+	object := v.(geko.Object)
+
+	items := geko.NewPairsFrom([]geko.Pair[string, any]{{Key: "a", Value: 1.0}, {Key: "a", Value: 2.0}})
+	array := geko.NewListFrom([]any{3.0, "x"})
+
+	var nilObject geko.Object
+
+	sqlTestDB.values = nil
+	if _, err = db.Exec("INSERT", object, items, array, nilObject, []byte(`{"b": 1}`)); err != nil {
+		t.Fatalf("Exec with error: %s", err.Error())
+	}
+
+	if value, _ := object.Value(); sqlTestDB.values[0] != value || sqlTestDB.values[3] != nil {
+		t.Fatalf("Stored values not correct: %#v", sqlTestDB.values)
+	}
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatalf("Query with error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	decodedObject := geko.NewMap[string, any]()
+	decodedObject.Set("old", 1)
+	decodedItems := geko.NewPairs[string, any]()
+	decodedArray := geko.NewList[any]()
+	nullObject := geko.NewMap[string, any]()
+	nullObject.Set("old", 1)
+	bytesObject := geko.NewMap[string, any]()
+
+	for _, dest := range []any{decodedObject, decodedItems, decodedArray, nullObject, bytesObject} {
+		if !rows.Next() {
+			t.Fatalf("Query returns too few rows")
+		}
+		if err = rows.Scan(dest); err != nil {
+			t.Fatalf("Scan with error: %s", err.Error())
+		}
+	}
+
+	for _, c := range []struct {
+		v        any
+		excepted string
+	}{
+		{decodedObject, `{"z":1,"a":[true,{"y":null,"x":"s"}]}`},
+		{decodedItems, `{"a":1,"a":2}`},
+		{decodedArray, `[3,"x"]`},
+		{nullObject, `{}`},
+		{bytesObject, `{"b":1}`},
+	} {
+		output, _ := json.Marshal(c.v)
+		if string(output) != c.excepted {
+			t.Fatalf("Scan result not correct: %s", string(output))
+		}
+	}
+}
+
+func TestSQL_Value(t *testing.T) {
+	var object geko.Object
+	var items geko.ObjectItems
+	var array geko.Array
+
+	for _, v := range []driver.Valuer{object, items, array} {
+		if value, err := v.Value(); value != nil || err != nil {
+			t.Fatalf("Value of nil should be NULL: %#v, %#v", value, err)
+		}
+	}
+
+	m := geko.NewMap[string, any]()
+	m.Set("a", make(chan int))
+	if value, err := m.Value(); err == nil {
+		t.Fatalf("Value should fail: %#v", value)
+	}
+
+	value, err := geko.NewPairs[string, int]().Value()
+	if err != nil || value != "{}" {
+		t.Fatalf("Value of empty pairs not correct: %#v, %#v", value, err)
+	}
+
+	value, err = geko.NewListFrom([]int{1}).Value()
+	if err != nil || value != "[1]" {
+		t.Fatalf("Value of list not correct: %#v, %#v", value, err)
+	}
+}
+
+func TestSQL_Scan(t *testing.T) {
+	items := geko.NewPairsFrom([]geko.Pair[string, int]{{Key: "old", Value: 1}})
+	if err := items.Scan(nil); err != nil || items.Len() != 0 {
+		t.Fatalf("Scan NULL result not correct: %#v, %#v", items, err)
+	}
+
+	l := geko.NewListFrom([]int{1})
+	l.SetAppendOnUnmarshal(true)
+	if err := l.Scan("[2]"); err != nil || l.Len() != 2 {
+		t.Fatalf("Scan with append result not correct: %#v, %#v", l, err)
+	}
+	if err := l.Scan(nil); err != nil || l.Len() != 2 {
+		t.Fatalf("Scan NULL with append result not correct: %#v, %#v", l, err)
+	}
+
+	l.SetAppendOnUnmarshal(false)
+	if err := l.Scan(nil); err != nil || l.List != nil {
+		t.Fatalf("Scan NULL result not correct: %#v, %#v", l, err)
+	}
+
+	anys := geko.NewList[any]()
+	anys.SetDecodeOptions(geko.UseObject())
+	if err := anys.Scan([]byte(`[{"a": 1}]`)); err != nil {
+		t.Fatalf("Scan with error: %s", err.Error())
+	}
+	if _, ok := anys.Get(0).(geko.Object); !ok {
+		t.Fatalf("Scan should use decode options of list: %#v", anys)
+	}
+
+	for _, dest := range []sql.Scanner{geko.NewMap[string, any](), geko.NewPairs[string, any](), geko.NewList[any]()} {
+		if err := dest.Scan(1); err == nil {
+			t.Fatalf("Scan unsupported type should fail: %#v", dest)
+		}
+	}
+}
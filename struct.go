@@ -0,0 +1,581 @@
+package geko
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FromStruct converts a struct, or a pointer to it, into an [Object] directly,
+// without the marshal and unmarshal round trip. Members are in the order of
+// field declaration, so the result can be modified and marshaled with a
+// deterministic output.
+//
+// Fields are selected like [json.Marshal] does: json tags are honored,
+// including renaming, "omitempty", "string" and "-", and fields of embedded
+// structs are promoted into the outer object. A bool, number or string field
+// with the "string" option is converted into a string of its JSON encoding.
+// Values are converted recursively:
+//
+//   - Structs are converted into [Object] like above.
+//   - Maps with string, integer or [encoding.TextMarshaler] keys are converted
+//     into [Object], with sorted keys.
+//   - Slices and arrays are converted into [Array], except []byte, which is
+//     converted into a base64 string.
+//   - Numbers are converted into json.Number, like the [UseNumber] option.
+//   - Values implementing [json.Marshaler] are marshaled and then decoded
+//     with [UseObject] and [UseNumber] options. Values implementing
+//     [encoding.TextMarshaler] are converted into string.
+//   - Nil pointers, interfaces, maps and slices are converted into nil.
+//
+// Members in the extras field, see [UnmarshalStruct], are added after known
+// fields, in their order. A member whose key is already used by a known field
+// is skipped.
+//
+// An [UnsupportedTypeError] is returned if a value can't be represented in
+// JSON, like a chan or func. Like json.Marshal, a [json.UnsupportedValueError]
+// is returned if v contains cycles.
+func FromStruct(v any) (*Map[string, any], error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("geko: FromStruct of non-struct type %T", v)
+	}
+
+	result, err := fromReflectValue(rv)
+	if err != nil {
+		return nil, err
+	}
+
+	object, isObject := result.(Object)
+	if !isObject {
+		return nil, fmt.Errorf("geko: FromStruct of type %T: not converted into an object", v)
+	}
+
+	return object, nil
+}
+
+// MarshalStruct returns the JSON encoding of struct v, which is converted by
+// [FromStruct] then encoded by [JSONMarshal] with provided option applied.
+//
+// It's the reverse of [UnmarshalStruct]. Known fields are emitted in order
+// of declaration, then unknown members in the extras field. So unknown
+// members which appear before or between known ones in the original data are
+// moved after them, but their keys, values and relative order are kept.
+func MarshalStruct(v any, option ...EncodeOption) ([]byte, error) {
+	object, err := FromStruct(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return JSONMarshal(object, option...)
+}
+
+var (
+	objectType        = reflect.TypeOf(Object(nil))
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func isMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// startDetectingCyclesAfter is the nesting depth of pointers, maps and slices
+// after which cycles are checked, like std lib does, so common values pay
+// nothing for it.
+const startDetectingCyclesAfter = 1000
+
+// converter converts Go values into JSON values, see [FromStruct].
+type converter struct {
+	ptrLevel int
+	ptrSeen  map[converterSeen]struct{}
+}
+
+// converterSeen identifies a pointer, map or slice. Type is needed because a
+// struct and its first field have the same address, and length is needed
+// because slices of different length can share the same array.
+type converterSeen struct {
+	typ    reflect.Type
+	ptr    uintptr
+	length int
+}
+
+func fromReflectValue(v reflect.Value) (any, error) {
+	c := converter{}
+	return c.value(v)
+}
+
+func (c *converter) value(v reflect.Value) (any, error) {
+	if result, isMarshaled, err := fromMarshaler(v); isMarshaled {
+		return result, err
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return c.value(v.Elem())
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return c.nested(v, func() (any, error) { return c.value(v.Elem()) })
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		var number any = v.Float()
+		if v.Kind() == reflect.Float32 {
+			number = float32(v.Float())
+		}
+		data, err := json.Marshal(number)
+		if err != nil {
+			return nil, fmt.Errorf("geko: convert %s: %w", v.Type(), err)
+		}
+		return json.Number(data), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Struct:
+		return c.structFields(v)
+	case reflect.Map:
+		return c.mapValue(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && !isMarshaler(reflect.PointerTo(v.Type().Elem())) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		return c.nested(v, func() (any, error) { return c.array(v) })
+	case reflect.Array:
+		return c.array(v)
+	default:
+		return nil, &UnsupportedTypeError{Type: v.Type()}
+	}
+}
+
+func fromMarshaler(v reflect.Value) (any, bool, error) {
+	m := v
+	if !isMarshaler(m.Type()) {
+		if m.Kind() == reflect.Pointer || !m.CanAddr() || !isMarshaler(reflect.PointerTo(m.Type())) {
+			return nil, false, nil
+		}
+		m = m.Addr()
+	}
+
+	if (m.Kind() == reflect.Pointer || m.Kind() == reflect.Interface) && m.IsNil() {
+		return nil, true, nil
+	}
+
+	if marshaler, ok := m.Interface().(json.Marshaler); ok {
+		data, err := marshaler.MarshalJSON()
+		if err != nil {
+			return nil, true, fmt.Errorf("geko: call MarshalJSON of type %s: %w", m.Type(), err)
+		}
+
+		var result any
+		if result, err = JSONUnmarshal(data, UseObject(), UseNumber(true)); err != nil {
+			return nil, true, fmt.Errorf("geko: decode MarshalJSON output of type %s: %w", m.Type(), err)
+		}
+		return result, true, nil
+	}
+
+	text, err := marshalText(m)
+	if err != nil {
+		return nil, true, err
+	}
+	return text, true, nil
+}
+
+func marshalText(v reflect.Value) (string, error) {
+	marshaler, _ := v.Interface().(encoding.TextMarshaler)
+
+	text, err := marshaler.MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("geko: call MarshalText of type %s: %w", v.Type(), err)
+	}
+
+	return string(text), nil
+}
+
+// nested converts elements of a pointer, map or slice v by f, and reports an
+// error if v is already being converted.
+func (c *converter) nested(v reflect.Value, f func() (any, error)) (any, error) {
+	c.ptrLevel++
+	defer func() { c.ptrLevel-- }()
+
+	if c.ptrLevel > startDetectingCyclesAfter {
+		seen := converterSeen{typ: v.Type(), ptr: v.Pointer()}
+		if v.Kind() == reflect.Slice {
+			seen.length = v.Len()
+		}
+
+		if _, exist := c.ptrSeen[seen]; exist {
+			return nil, &json.UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+		}
+
+		if c.ptrSeen == nil {
+			c.ptrSeen = make(map[converterSeen]struct{})
+		}
+		c.ptrSeen[seen] = struct{}{}
+		defer delete(c.ptrSeen, seen)
+	}
+
+	return f()
+}
+
+func (c *converter) structFields(v reflect.Value) (any, error) {
+	info := cachedStructInfo(v.Type())
+	result := NewMapWithCapacity[string, any](len(info.fields))
+
+	for i := range info.fields {
+		field := &info.fields[i]
+
+		fv, exist := fieldByIndex(v, field.index)
+		if !exist || (field.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		convert := c.value
+		if field.quoted {
+			convert = c.quotedValue
+		}
+
+		value, err := convert(fv)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Set(field.name, value)
+	}
+
+	// unknown members, known ones take precedence
+	if extras, exist := info.extrasField(v); exist {
+		object, _ := extras.Interface().(Object)
+		for i := 0; object != nil && i < object.Len(); i++ {
+			pair := object.GetByIndex(i)
+			if !result.Has(pair.Key) {
+				result.Set(pair.Key, cloneValue(pair.Value))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// quotedValue converts v like c.value, but a bool, number or string is
+// encoded into a string, for fields with the "string" tag option. Values of
+// marshalers and nil pointers are kept, like std lib does.
+func (c *converter) quotedValue(v reflect.Value) (any, error) {
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if result, isMarshaled, err := fromMarshaler(v); isMarshaled {
+		return result, err
+	}
+
+	value, err := c.value(v)
+
+	switch x := value.(type) {
+	case bool:
+		return strconv.FormatBool(x), err
+	case json.Number:
+		return string(x), err
+	case string:
+		data, _ := json.Marshal(x) // never fails for a string
+		return string(data), err
+	default:
+		return value, err
+	}
+}
+
+func (c *converter) mapValue(v reflect.Value) (any, error) {
+	switch v.Type().Key().Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		if !v.Type().Key().Implements(textMarshalerType) {
+			return nil, &UnsupportedTypeError{Type: v.Type()}
+		}
+	}
+
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	return c.nested(v, func() (any, error) { return c.mapMembers(v) })
+}
+
+func (c *converter) mapMembers(v reflect.Value) (any, error) {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKeyString(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+
+	sort.Strings(keys)
+
+	result := NewMapWithCapacity[string, any](len(keys))
+	for _, key := range keys {
+		value, err := c.value(values[key])
+		if err != nil {
+			return nil, err
+		}
+		result.Set(key, value)
+	}
+
+	return result, nil
+}
+
+func mapKeyString(k reflect.Value) (string, error) {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	default:
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		return marshalText(k)
+	}
+}
+
+func (c *converter) array(v reflect.Value) (any, error) {
+	result := NewListWithCapacity[any](v.Len())
+
+	for i := 0; i < v.Len(); i++ {
+		item, err := c.value(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		result.Append(item)
+	}
+
+	return result, nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// structInfo is JSON related information of a struct type.
+type structInfo struct {
+	// fields are JSON members, in order of declaration.
+	fields []structField
+	// extras is the index of the field tagged by `geko:"extras"`, which
+	// stores unknown members. It's nil if there is no such field.
+	extras []int
+}
+
+// extrasField returns the extras field of struct v, reports false if it
+// does not exist, or is in a nil embedded pointer.
+func (info *structInfo) extrasField(v reflect.Value) (reflect.Value, bool) {
+	if info.extras == nil {
+		return reflect.Value{}, false
+	}
+	return fieldByIndex(v, info.extras)
+}
+
+// structField is a field of struct which is a member in JSON.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+	// quoted is set by the "string" tag option, for bool, number and string
+	// fields.
+	quoted bool
+}
+
+// fieldByIndex is like [reflect.Value.FieldByIndex], but reports false if it
+// steps into a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	field := v
+
+	for i, x := range index {
+		if i > 0 && field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				return reflect.Value{}, false
+			}
+			field = field.Elem()
+		}
+		field = field.Field(x)
+	}
+
+	return field, true
+}
+
+var structInfoCache sync.Map // map[reflect.Type]*structInfo
+
+func cachedStructInfo(t reflect.Type) *structInfo {
+	if cached, exist := structInfoCache.Load(t); exist {
+		info, _ := cached.(*structInfo)
+		return info
+	}
+
+	info := collectStructInfo(t)
+	structInfoCache.Store(t, info)
+	return info
+}
+
+// collectStructInfo collects JSON members of struct type t, in order of
+// declaration, like encoding/json does.
+func collectStructInfo(t reflect.Type) *structInfo {
+	var candidates structInfo
+	collectStructFieldCandidates(t, nil, map[reflect.Type]bool{}, &candidates)
+
+	groups := make(map[string][]int, len(candidates.fields))
+	for i := range candidates.fields {
+		groups[candidates.fields[i].name] = append(groups[candidates.fields[i].name], i)
+	}
+
+	info := &structInfo{
+		fields: make([]structField, 0, len(groups)),
+		extras: candidates.extras,
+	}
+	for i := range candidates.fields {
+		if dominantField(candidates.fields, groups[candidates.fields[i].name]) == i {
+			info.fields = append(info.fields, candidates.fields[i])
+		}
+	}
+
+	return info
+}
+
+// dominantField finds the field which dominates others with the same name,
+// by Go's rules for embedded fields: the shallowest one wins, then the tagged
+// one. Returns -1 if there is no such field, so all of them are dropped.
+func dominantField(candidates []structField, group []int) int {
+	depth := len(candidates[group[0]].index)
+	for _, i := range group {
+		if len(candidates[i].index) < depth {
+			depth = len(candidates[i].index)
+		}
+	}
+
+	dominant, count, taggedCount := -1, 0, 0
+	for _, i := range group {
+		if len(candidates[i].index) != depth {
+			continue
+		}
+		count++
+		if candidates[i].tagged {
+			taggedCount++
+			dominant = i
+		} else if taggedCount == 0 {
+			dominant = i
+		}
+	}
+
+	if count == 1 || taggedCount == 1 {
+		return dominant
+	}
+
+	return -1
+}
+
+func collectStructFieldCandidates(
+	t reflect.Type, index []int, visiting map[reflect.Type]bool, result *structInfo,
+) {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		ft := sf.Type
+		if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+
+		if sf.IsExported() && sf.Type == objectType && sf.Tag.Get("geko") == "extras" {
+			// the shallowest one wins
+			if result.extras == nil || len(fieldIndex) < len(result.extras) {
+				result.extras = fieldIndex
+			}
+			continue
+		}
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" || (!sf.IsExported() && (!sf.Anonymous || ft.Kind() != reflect.Struct)) {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+			if !visiting[ft] {
+				collectStructFieldCandidates(ft, fieldIndex, visiting, result)
+			}
+			continue
+		}
+
+		field := structField{name: name, index: fieldIndex, tagged: name != ""}
+		if name == "" {
+			field.name = sf.Name
+		}
+		for _, option := range strings.Split(options, ",") {
+			field.omitEmpty = field.omitEmpty || option == "omitempty"
+			field.quoted = field.quoted || (option == "string" && isQuotableKind(ft.Kind()))
+		}
+
+		result.fields = append(result.fields, field)
+	}
+}
+
+// isQuotableKind reports whether the "string" tag option applies to a field
+// of kind k.
+func isQuotableKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,468 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+type structTestBase struct {
+	ID      int    `json:"id"`
+	Created string `json:"created,omitempty"`
+}
+
+type structTestItem struct {
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type structTestOrder struct {
+	structTestBase
+	Customer string `json:"customer"`
+	Note     string `json:"-"`
+	Dash     int    `json:"-,"`
+	Items    []structTestItem
+	Paid     bool `json:"paid,omitempty"`
+	internal int
+}
+
+func TestFromStruct(t *testing.T) {
+	order := structTestOrder{
+		structTestBase: structTestBase{ID: 1},
+		Customer:       "alice",
+		Note:           "secret",
+		Dash:           2,
+		Items: []structTestItem{
+			{Name: "apple", Price: 1.5, Tags: []string{"fruit"}},
+			{Name: "pen", Price: 3},
+		},
+		internal: 3,
+	}
+
+	object, err := geko.FromStruct(&order)
+	if err != nil {
+		t.Fatalf("FromStruct with error: %s", err.Error())
+	}
+
+	if keys := strings.Join(object.Keys(), ","); keys != "id,customer,-,Items" {
+		t.Fatalf("FromStruct keys not correct: %s", keys)
+	}
+
+	output, _ := json.Marshal(object)
+	excepted := `{"id":1,"customer":"alice","-":2,"Items":[` +
+		`{"name":"apple","price":1.5,"tags":["fruit"]},{"name":"pen","price":3}]}`
+	if string(output) != excepted {
+		t.Fatalf("FromStruct result not correct: %s", string(output))
+	}
+
+	stdOutput, _ := json.Marshal(order)
+	if string(output) != string(stdOutput) {
+		t.Fatalf("FromStruct result not same as encoding/json: %s", string(stdOutput))
+	}
+
+	object.Set("extra", true)
+	if _, isNumber := object.GetOrZeroValue("id").(json.Number); !isNumber {
+		t.Fatalf("FromStruct should convert number into json.Number: %#v", object.GetOrZeroValue("id"))
+	}
+}
+
+type structTestText string
+
+func (s structTestText) MarshalText() ([]byte, error) {
+	if s == "bad" {
+		return nil, errors.New("bad text")
+	}
+	return []byte("text:" + string(s)), nil
+}
+
+type structTestPointerText struct{}
+
+func (*structTestPointerText) MarshalText() ([]byte, error) {
+	return []byte("pointer"), nil
+}
+
+type structTestJSON struct {
+	Output string
+}
+
+func (s *structTestJSON) MarshalJSON() ([]byte, error) {
+	if s.Output == "" {
+		return nil, errors.New("bad json")
+	}
+	return []byte(s.Output), nil
+}
+
+type structTestKey struct {
+	s string
+}
+
+func (k structTestKey) MarshalText() ([]byte, error) {
+	return structTestText(k.s).MarshalText()
+}
+
+type structTestBytes []structTestText
+
+type structTestInt int
+
+type structTestTypes struct {
+	Bool          bool
+	Int8          int8
+	Uint          uint64
+	Float32       float32
+	Float64       float64
+	Bytes         []byte
+	Texts         structTestBytes
+	Array         [2]int
+	IntMap        map[int]string
+	UintMap       map[uint8]bool
+	TextMap       map[structTestText]int
+	KeyMap        map[structTestKey]int
+	PointerMap    map[*structTestPointerText]int
+	NilMap        map[string]any
+	NilSlice      []int
+	NilPointer    *int
+	Pointer       *string
+	Any           any
+	Time          time.Time
+	Raw           json.RawMessage
+	JSON          structTestJSON
+	NilJSON       *structTestJSON
+	Text          structTestText
+	NilText       *structTestPointerText
+	structTestInt // unexported non-struct, ignored
+}
+
+func TestFromStruct_Types(t *testing.T) {
+	s := "s"
+	v := structTestTypes{
+		Bool:          true,
+		Int8:          -8,
+		Uint:          math.MaxUint64,
+		Float32:       0.1,
+		Float64:       1e21,
+		Bytes:         []byte("hello"),
+		Texts:         structTestBytes{"a"},
+		Array:         [2]int{1, 2},
+		IntMap:        map[int]string{10: "a", 2: "b"},
+		UintMap:       map[uint8]bool{1: true},
+		TextMap:       map[structTestText]int{"k": 1},
+		KeyMap:        map[structTestKey]int{{"k"}: 1},
+		PointerMap:    map[*structTestPointerText]int{nil: 1},
+		Pointer:       &s,
+		Any:           time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC),
+		Time:          time.Date(2001, 1, 2, 3, 4, 5, 0, time.UTC),
+		Raw:           json.RawMessage(`{"b": 1, "a": [2]}`),
+		JSON:          structTestJSON{Output: `{"y": 1, "x": 2}`},
+		Text:          "t",
+		structTestInt: 5,
+	}
+
+	object, err := geko.FromStruct(&v)
+	if err != nil {
+		t.Fatalf("FromStruct with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(object)
+	excepted := `{"Bool":true,"Int8":-8,"Uint":18446744073709551615,"Float32":0.1,"Float64":1e+21,` +
+		`"Bytes":"aGVsbG8=","Texts":["text:a"],"Array":[1,2],"IntMap":{"10":"a","2":"b"},` +
+		`"UintMap":{"1":true},` +
+		`"TextMap":{"k":1},"KeyMap":{"text:k":1},"PointerMap":{"":1},"NilMap":null,"NilSlice":null,"NilPointer":null,` +
+		`"Pointer":"s","Any":"2000-01-02T03:04:05Z","Time":"2001-01-02T03:04:05Z",` +
+		`"Raw":{"b":1,"a":[2]},"JSON":{"y":1,"x":2},"NilJSON":null,"Text":"text:t","NilText":null}`
+	if string(output) != excepted {
+		t.Fatalf("FromStruct result not correct: %s", string(output))
+	}
+
+	// not addressable, so pointer receiver MarshalJSON is not used
+	v.JSON.Output = ""
+	object, _ = geko.FromStruct(v)
+	if output, _ = json.Marshal(object.GetOrZeroValue("JSON")); string(output) != `{"Output":""}` {
+		t.Fatalf("FromStruct result not correct: %s", string(output))
+	}
+}
+
+type structTestEmpty struct {
+	Array     [0]int         `json:",omitempty"`
+	Map       map[string]int `json:",omitempty"`
+	Slice     []int          `json:",omitempty"`
+	String    string         `json:",omitempty"`
+	Bool      bool           `json:",omitempty"`
+	Int       int            `json:",omitempty"`
+	Uint      uint           `json:",omitempty"`
+	Float     float64        `json:",omitempty"`
+	Interface any            `json:",omitempty"`
+	Pointer   *int           `json:",omitempty"`
+	Struct    struct{}       `json:",omitempty"`
+}
+
+func TestFromStruct_OmitEmpty(t *testing.T) {
+	object, _ := geko.FromStruct(structTestEmpty{})
+	if output, _ := json.Marshal(object); string(output) != `{"Struct":{}}` {
+		t.Fatalf("FromStruct result not correct: %s", string(output))
+	}
+
+	one := 1
+	object, _ = geko.FromStruct(structTestEmpty{
+		Map: map[string]int{"a": 1}, Slice: []int{1}, String: "a", Bool: true, Int: 1, Uint: 1, Float: 1,
+		Interface: 1, Pointer: &one,
+	})
+	excepted := "Map,Slice,String,Bool,Int,Uint,Float,Interface,Pointer,Struct"
+	if keys := strings.Join(object.Keys(), ","); keys != excepted {
+		t.Fatalf("FromStruct keys not correct: %s", keys)
+	}
+}
+
+type structTestQuoted struct {
+	Int     int64          `json:"int,string"`
+	Uint    uint8          `json:",string"`
+	Float   float64        `json:"float,string"`
+	Bool    bool           `json:"bool,omitempty,string"`
+	String  string         `json:"string,string"`
+	Pointer *int           `json:"pointer,string"`
+	Nil     *int           `json:"nil,string"`
+	Text    structTestText `json:"text,string"`
+	Slice   []int          `json:"slice,string"`
+	Any     any            `json:"any,string"`
+}
+
+func TestFromStruct_Quoted(t *testing.T) {
+	five := 5
+	v := structTestQuoted{
+		Int: -5, Uint: 5, Float: 1.5, Bool: true, String: `a "b" <c>`, Pointer: &five,
+		Text: "t", Slice: []int{1}, Any: 1,
+	}
+
+	object, err := geko.FromStruct(v)
+	if err != nil {
+		t.Fatalf("FromStruct with error: %s", err.Error())
+	}
+
+	excepted, _ := json.Marshal(v)
+	if output, _ := json.Marshal(object); string(output) != string(excepted) {
+		t.Fatalf("FromStruct result not same as json.Marshal: %s, %s", string(output), string(excepted))
+	}
+
+	if value, _ := object.Get("int"); value != "-5" {
+		t.Fatalf("FromStruct should convert quoted field into string, got %#v", value)
+	}
+
+	if _, err = geko.FromStruct(struct {
+		F float64 `json:",string"`
+	}{math.NaN()}); err == nil {
+		t.Fatalf("FromStruct of quoted NaN should fail")
+	}
+}
+
+type structTestA struct {
+	Same   int
+	Tagged int `json:"Tagged"`
+	Deep   int
+}
+
+type structTestB struct {
+	Same   int
+	Tagged int
+	Inner  structTestC
+}
+
+type structTestC struct {
+	Deep int
+}
+
+type structTestNested struct {
+	structTestC
+}
+
+type structTestConflict struct {
+	structTestNested
+	structTestA
+	*structTestB
+	Named structTestC `json:"named"`
+	*structTestConflict
+}
+
+func TestFromStruct_Embedded(t *testing.T) {
+	v := structTestConflict{
+		structTestA:      structTestA{Same: 1, Tagged: 2, Deep: 3},
+		structTestB:      &structTestB{Same: 4, Tagged: 5, Inner: structTestC{Deep: 6}},
+		structTestNested: structTestNested{structTestC{Deep: 7}},
+		Named:            structTestC{Deep: 8},
+	}
+
+	object, _ := geko.FromStruct(v)
+	output, _ := json.Marshal(object)
+	excepted := `{"Tagged":2,"Deep":3,"Inner":{"Deep":6},"named":{"Deep":8}}`
+	if string(output) != excepted {
+		t.Fatalf("FromStruct result not correct: %s", string(output))
+	}
+
+	stdOutput, _ := json.Marshal(v)
+	if string(output) != string(stdOutput) {
+		t.Fatalf("FromStruct result not same as encoding/json: %s", string(stdOutput))
+	}
+
+	// nil embedded pointer
+	v.structTestB = nil
+	object, _ = geko.FromStruct(v)
+	if output, _ = json.Marshal(object); string(output) != `{"Tagged":2,"Deep":3,"named":{"Deep":8}}` {
+		t.Fatalf("FromStruct result not correct: %s", string(output))
+	}
+}
+
+func TestFromStruct_Error(t *testing.T) {
+	var unsupported *geko.UnsupportedTypeError
+
+	for _, v := range []any{
+		struct{ C chan int }{},
+		struct{ F []any }{F: []any{func() {}}},
+		struct{ M map[string]any }{M: map[string]any{"c": complex(1, 2)}},
+		struct{ M map[[2]int]int }{},
+		struct{ S struct{ C chan int } }{},
+	} {
+		if _, err := geko.FromStruct(v); !errors.As(err, &unsupported) {
+			t.Fatalf("FromStruct should fail with unsupported type: %#v", err)
+		}
+	}
+
+	if unsupported.Error() != "geko: unsupported type chan int" {
+		t.Fatalf("UnsupportedTypeError message not correct: %s", unsupported.Error())
+	}
+
+	for _, v := range []any{
+		nil,
+		1,
+		(*structTestOrder)(nil),
+		struct{ F float64 }{F: math.NaN()},
+		&struct{ J structTestJSON }{},
+		&struct{ J structTestJSON }{J: structTestJSON{Output: "{"}},
+		struct{ T structTestText }{T: "bad"},
+		struct{ M map[structTestKey]int }{M: map[structTestKey]int{{"bad"}: 1}},
+		&structTestJSON{Output: "[1]"},
+	} {
+		if result, err := geko.FromStruct(v); err == nil {
+			t.Fatalf("FromStruct of %#v should fail: %#v", v, result)
+		}
+	}
+}
+
+type structTestNode struct {
+	Next     *structTestNode `json:"next"`
+	Children []any           `json:"children,omitempty"`
+	Meta     map[string]any  `json:"meta,omitempty"`
+}
+
+func TestFromStruct_Cycle(t *testing.T) {
+	node := &structTestNode{}
+	node.Next = node
+
+	slice := []any{nil}
+	slice[0] = slice
+
+	meta := map[string]any{}
+	meta["self"] = meta
+
+	for _, v := range []any{node, &structTestNode{Children: slice}, &structTestNode{Meta: meta}} {
+		_, err := geko.FromStruct(v)
+
+		var valueErr *json.UnsupportedValueError
+		if !errors.As(err, &valueErr) || !strings.Contains(err.Error(), "encountered a cycle") {
+			t.Fatalf("FromStruct of cyclic value should fail: %#v", err)
+		}
+
+		if _, err = geko.MarshalStruct(v); !errors.As(err, &valueErr) {
+			t.Fatalf("MarshalStruct of cyclic value should fail: %#v", err)
+		}
+	}
+
+	// a deep value without cycle, with a value shared by siblings, is fine
+	shared := &structTestNode{}
+	deep := &structTestNode{Children: []any{shared, shared, nil}}
+	for i := 0; i < 2000; i++ {
+		deep = &structTestNode{Next: deep}
+	}
+
+	if _, err := geko.FromStruct(deep); err != nil {
+		t.Fatalf("FromStruct of deep value with error: %s", err.Error())
+	}
+}
+
+type structTestConfig struct {
+	Name   string            `json:"name"`
+	Port   int               `json:"port"`
+	Inner  *structTestConfig `json:"inner,omitempty"`
+	Extras geko.Object       `json:"-" geko:"extras"`
+}
+
+func TestMarshalStruct_Extras(t *testing.T) {
+	// unknown members before, between and after known ones
+	data := `{"$schema":"s.json","name":"app","x-note":"keep é","port":80,` +
+		`"inner":{"name":"sub","port":1,"flag":true},"tags":["a","b"],"name2":null}`
+
+	var config structTestConfig
+	if err := geko.UnmarshalStruct([]byte(data), &config); err != nil {
+		t.Fatalf("UnmarshalStruct with error: %s", err.Error())
+	}
+
+	if keys := strings.Join(config.Extras.Keys(), ","); keys != "$schema,x-note,tags,name2" {
+		t.Fatalf("UnmarshalStruct extras keys not correct: %s", keys)
+	}
+
+	config.Port = 8080
+
+	output, err := geko.MarshalStruct(&config)
+	if err != nil {
+		t.Fatalf("MarshalStruct with error: %s", err.Error())
+	}
+
+	excepted := `{"name":"app","port":8080,"inner":{"name":"sub","port":1,"flag":true},` +
+		`"$schema":"s.json","x-note":"keep é","tags":["a","b"],"name2":null}`
+	if string(output) != excepted {
+		t.Fatalf("MarshalStruct result not correct: %s", string(output))
+	}
+
+	// known members first, round trip is exact
+	var again structTestConfig
+	_ = geko.UnmarshalStruct(output, &again)
+	if output2, _ := geko.MarshalStruct(again); string(output2) != excepted {
+		t.Fatalf("MarshalStruct round trip not correct: %s", string(output2))
+	}
+
+	// known fields take precedence
+	config.Extras.Set("name", "ignored")
+	if output, _ = geko.MarshalStruct(config, geko.SortKeys(true)); !strings.HasPrefix(string(output), `{"$schema"`) ||
+		strings.Contains(string(output), "ignored") {
+		t.Fatalf("MarshalStruct result not correct: %s", string(output))
+	}
+
+	if _, err = geko.MarshalStruct(1); err == nil {
+		t.Fatalf("MarshalStruct of non-struct should fail")
+	}
+}
+
+type structTestEmbeddedExtras struct {
+	structTestConfig
+	Extras geko.Object `geko:"extras"`
+	ID     int         `json:"id"`
+}
+
+func TestMarshalStruct_EmbeddedExtras(t *testing.T) {
+	var v structTestEmbeddedExtras
+	if err := geko.UnmarshalStruct([]byte(`{"id":1,"name":"a","other":2}`), &v); err != nil {
+		t.Fatalf("UnmarshalStruct with error: %s", err.Error())
+	}
+
+	if v.Name != "a" || v.structTestConfig.Extras != nil || v.Extras.Len() != 1 {
+		t.Fatalf("UnmarshalStruct should use the shallowest extras field: %#v", v)
+	}
+
+	output, _ := geko.MarshalStruct(v)
+	if string(output) != `{"name":"a","port":0,"id":1,"other":2}` {
+		t.Fatalf("MarshalStruct result not correct: %s", string(output))
+	}
+}
@@ -0,0 +1,283 @@
+package geko
+
+import "sync"
+
+// SyncMap is a [Map] protected by a [sync.RWMutex], so it can be read and
+// updated by multiple goroutines concurrently.
+//
+// Read operations like [SyncMap.Get] take the read lock, write operations
+// like [SyncMap.Set] take the write lock. Every method is atomic by itself,
+// but a sequence of them is not, for example, an index got from
+// [SyncMap.Len] may be out of bound when calling [SyncMap.GetByIndex], if
+// another goroutine deletes items between them. Use [SyncMap.LoadOrStore],
+// [SyncMap.CompareAndSwap] and other Load-style methods, or [SyncMap.Update]
+// for a compound operation.
+//
+// The zero value is an empty map ready to use. A SyncMap must not be copied
+// after first use.
+type SyncMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  Map[K, V]
+}
+
+// NewSyncMap creates a new empty [SyncMap].
+func NewSyncMap[K comparable, V any]() *SyncMap[K, V] {
+	return &SyncMap[K, V]{}
+}
+
+// NewSyncMapFrom creates a [SyncMap] which takes over the content and
+// duplicated key strategy of m. m should not be used after this call, use
+// [SyncMap.Snapshot] to get a copy instead.
+//
+// A nil m results an empty map.
+func NewSyncMapFrom[K comparable, V any](m *Map[K, V]) *SyncMap[K, V] {
+	s := NewSyncMap[K, V]()
+	if m != nil {
+		s.m = *m
+	}
+	return s
+}
+
+// DuplicatedKeyStrategy get current strategy when [SyncMap.Add] with a
+// duplicated key.
+func (s *SyncMap[K, V]) DuplicatedKeyStrategy() DuplicatedKeyStrategy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.DuplicatedKeyStrategy()
+}
+
+// SetDuplicatedKeyStrategy set strategy when [SyncMap.Add] with a duplicated
+// key.
+func (s *SyncMap[K, V]) SetDuplicatedKeyStrategy(strategy DuplicatedKeyStrategy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.SetDuplicatedKeyStrategy(strategy)
+}
+
+// Get a value by key, see [Map.Get].
+func (s *SyncMap[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Get(key)
+}
+
+// Has checks if key exist in the map.
+func (s *SyncMap[K, V]) Has(key K) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Has(key)
+}
+
+// GetOrZeroValue return value by key, or the zero value of type V if key not
+// exist.
+func (s *SyncMap[K, V]) GetOrZeroValue(key K) V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.GetOrZeroValue(key)
+}
+
+// GetByIndex get the key and value by index of key order.
+//
+// Panic if out of bound. [SyncMap.Range] is a safer choice to iterate over
+// the map.
+func (s *SyncMap[K, V]) GetByIndex(index int) Pair[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.GetByIndex(index)
+}
+
+// Len returns the size of map.
+func (s *SyncMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Len()
+}
+
+// Keys returns a copy of all keys of the map, in current order.
+func (s *SyncMap[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Keys()
+}
+
+// Values returns a copy of all values of the map, in current order.
+func (s *SyncMap[K, V]) Values() []V {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Values()
+}
+
+// Pairs returns a copy of all data the map stored as a list of pair, in
+// current order.
+func (s *SyncMap[K, V]) Pairs() *Pairs[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.Pairs()
+}
+
+// Snapshot returns a copy of the map as a normal [Map], with the same order
+// and duplicated key strategy. Values are not deep copied.
+func (s *SyncMap[K, V]) Snapshot() *Map[K, V] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := NewMapWithCapacity[K, V](s.m.Len())
+	m.SetDuplicatedKeyStrategy(s.m.DuplicatedKeyStrategy())
+	for i, length := 0, s.m.Len(); i < length; i++ {
+		pair := s.m.GetByIndex(i)
+		m.Set(pair.Key, pair.Value)
+	}
+
+	return m
+}
+
+// Range calls fn for every kv pair in order, until fn returns false.
+//
+// It iterates over a snapshot taken when it's called, no lock is held when
+// calling fn, so fn can read or modify the map, and changes made by fn or
+// other goroutines are not seen by the iteration.
+func (s *SyncMap[K, V]) Range(fn func(key K, value V) bool) {
+	for _, pair := range s.Pairs().List {
+		if !fn(pair.Key, pair.Value) {
+			return
+		}
+	}
+}
+
+// Set a value by key, see [Map.Set].
+func (s *SyncMap[K, V]) Set(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Set(key, value)
+}
+
+// Add a key value pair, see [Map.Add].
+func (s *SyncMap[K, V]) Add(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Add(key, value)
+}
+
+// Append a series of kv pairs into map atomically, see [Map.Append].
+func (s *SyncMap[K, V]) Append(pairs ...Pair[K, V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Append(pairs...)
+}
+
+// Delete a item by key.
+func (s *SyncMap[K, V]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Delete(key)
+}
+
+// Clear this map.
+func (s *SyncMap[K, V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Clear()
+}
+
+// Sort will reorder the map using the given less function. The write lock is
+// held when calling lessFunc, so it must not call methods of the map.
+func (s *SyncMap[K, V]) Sort(lessFunc PairLessFunc[K, V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Sort(lessFunc)
+}
+
+// Filter remove all item which make pred func return false. The write lock is
+// held when calling pred, so it must not call methods of the map.
+func (s *SyncMap[K, V]) Filter(pred PairFilterFunc[K, V]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m.Filter(pred)
+}
+
+// Update calls fn with the inner map while holding the write lock, so a
+// compound operation can be done atomically. fn must not keep the inner map
+// after it returns, or call methods of the SyncMap.
+func (s *SyncMap[K, V]) Update(fn func(m *Map[K, V])) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.m)
+}
+
+// LoadOrStore returns the existing value for the key if present. Otherwise,
+// it adds the pair to the end and returns the given value. The loaded result
+// is true if the value was loaded, false if stored.
+func (s *SyncMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if actual, loaded = s.m.Get(key); loaded {
+		return actual, true
+	}
+
+	s.m.Set(key, value)
+	return value, false
+}
+
+// LoadAndDelete deletes the value for a key, returning the previous value if
+// any. The loaded result reports whether the key was present.
+func (s *SyncMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value, loaded = s.m.Get(key); loaded {
+		s.m.Delete(key)
+	}
+
+	return value, loaded
+}
+
+// CompareAndSwap sets value for key if the value stored in the map is equal
+// to old, the key keeps its position. The swapped result reports whether the
+// swap was performed.
+//
+// Values are compared like [sync.Map.CompareAndSwap] does, it panics if the
+// value is not comparable.
+func (s *SyncMap[K, V]) CompareAndSwap(key K, old, value V) (swapped bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exist := s.m.Get(key); exist && any(current) == any(old) {
+		s.m.Set(key, value)
+		return true
+	}
+
+	return false
+}
+
+// CompareAndDelete deletes the entry for key if its value is equal to old.
+// The deleted result reports whether the entry was deleted.
+//
+// Values are compared like [SyncMap.CompareAndSwap] does.
+func (s *SyncMap[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exist := s.m.Get(key); exist && any(current) == any(old) {
+		s.m.Delete(key)
+		return true
+	}
+
+	return false
+}
+
+// MarshalJSON implements [json.Marshaler] interface, it marshals the map
+// while holding the read lock.
+func (s *SyncMap[K, V]) MarshalJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.m.MarshalJSON()
+}
+
+// UnmarshalJSON implements [json.Unmarshaler] interface, see
+// [Map.UnmarshalJSON]. It holds the write lock while decoding.
+func (s *SyncMap[K, V]) UnmarshalJSON(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m.UnmarshalJSON(data)
+}
@@ -0,0 +1,207 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestSyncMap(t *testing.T) {
+	var s geko.SyncMap[string, int]
+
+	s.Set("b", 1)
+	s.Add("a", 2)
+	s.Append(geko.Pair[string, int]{Key: "c", Value: 3}, geko.Pair[string, int]{Key: "b", Value: 4})
+
+	if s.Len() != 3 || !s.Has("a") || s.Has("d") {
+		t.Fatalf("SyncMap length or keys not correct: %v", s.Keys())
+	}
+
+	if v, exist := s.Get("b"); !exist || v != 4 || s.GetOrZeroValue("d") != 0 {
+		t.Fatalf("SyncMap Get result not correct: %d, %v", v, exist)
+	}
+
+	if pair := s.GetByIndex(1); pair.Key != "a" || pair.Value != 2 {
+		t.Fatalf("SyncMap GetByIndex result not correct: %#v", pair)
+	}
+
+	output, _ := json.Marshal(&s)
+	if string(output) != `{"b":4,"a":2,"c":3}` {
+		t.Fatalf("SyncMap marshal result not correct: %s", string(output))
+	}
+
+	s.Sort(func(a, b *geko.Pair[string, int]) bool { return a.Key < b.Key })
+	s.Filter(func(p *geko.Pair[string, int]) bool { return p.Value != 3 })
+	s.Delete("x")
+
+	if values := s.Values(); len(values) != 2 || values[0] != 2 || values[1] != 4 {
+		t.Fatalf("SyncMap Sort and Filter result not correct: %v", values)
+	}
+
+	s.SetDuplicatedKeyStrategy(geko.UpdateValueUpdateOrder)
+	s.Add("a", 5)
+	if keys := s.Pairs().Keys(); len(keys) != 2 || keys[0] != "b" || keys[1] != "a" {
+		t.Fatalf("SyncMap strategy not effect: %v", keys)
+	}
+
+	s.Update(func(m *geko.Map[string, int]) {
+		m.Set("c", m.GetOrZeroValue("a")+1)
+	})
+
+	snapshot := s.Snapshot()
+	s.Delete("b")
+
+	if snapshot.DuplicatedKeyStrategy() != geko.UpdateValueUpdateOrder || snapshot.Len() != 3 {
+		t.Fatalf("SyncMap snapshot not correct: %v", snapshot.Keys())
+	}
+
+	if s.DuplicatedKeyStrategy() != geko.UpdateValueUpdateOrder || s.Len() != 2 {
+		t.Fatalf("SyncMap should not be affected by snapshot: %v", s.Keys())
+	}
+
+	s.Clear()
+	if s.Len() != 0 {
+		t.Fatalf("SyncMap Clear not work: %v", s.Keys())
+	}
+}
+
+func TestSyncMap_Load(t *testing.T) {
+	s := geko.NewSyncMap[string, any]()
+
+	if actual, loaded := s.LoadOrStore("a", 1); loaded || actual != 1 {
+		t.Fatalf("LoadOrStore of new key not correct: %v, %v", actual, loaded)
+	}
+
+	if actual, loaded := s.LoadOrStore("a", 2); !loaded || actual != 1 {
+		t.Fatalf("LoadOrStore of exist key not correct: %v, %v", actual, loaded)
+	}
+
+	s.Set("b", "x")
+
+	if s.CompareAndSwap("a", 2, 3) || s.CompareAndSwap("c", nil, 3) {
+		t.Fatalf("CompareAndSwap should fail if value not equal or key not exist")
+	}
+
+	if !s.CompareAndSwap("a", 1, 3) || s.GetOrZeroValue("a") != 3 {
+		t.Fatalf("CompareAndSwap should success if value equal: %v", s.GetOrZeroValue("a"))
+	}
+
+	if s.CompareAndDelete("b", "y") || s.CompareAndDelete("c", nil) || !s.CompareAndDelete("b", "x") {
+		t.Fatalf("CompareAndDelete result not correct: %v", s.Keys())
+	}
+
+	if value, loaded := s.LoadAndDelete("a"); !loaded || value != 3 || s.Len() != 0 {
+		t.Fatalf("LoadAndDelete of exist key not correct: %v, %v", value, loaded)
+	}
+
+	if value, loaded := s.LoadAndDelete("a"); loaded || value != nil {
+		t.Fatalf("LoadAndDelete of not exist key not correct: %v, %v", value, loaded)
+	}
+}
+
+func TestSyncMap_Range(t *testing.T) {
+	s := geko.NewSyncMap[string, int]()
+	s.Set("a", 1)
+	s.Set("b", 2)
+	s.Set("c", 3)
+
+	var keys []string
+	s.Range(func(key string, value int) bool {
+		keys = append(keys, key)
+		// modify the map inside fn is allowed, and not seen by the iteration
+		s.Delete(key)
+		s.Set(key+key, value)
+		return value < 2
+	})
+
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Range result not correct: %v", keys)
+	}
+
+	output, _ := json.Marshal(s)
+	if string(output) != `{"c":3,"aa":1,"bb":2}` {
+		t.Fatalf("Range modification result not correct: %s", string(output))
+	}
+}
+
+func TestSyncMap_From(t *testing.T) {
+	m := geko.NewMap[string, any]()
+	m.SetDuplicatedKeyStrategy(geko.Ignore)
+	m.Set("a", 1)
+
+	s := geko.NewSyncMapFrom(m)
+	s.Add("a", 2)
+
+	if s.DuplicatedKeyStrategy() != geko.Ignore || s.GetOrZeroValue("a") != 1 {
+		t.Fatalf("NewSyncMapFrom should keep content and strategy: %v", s.Snapshot())
+	}
+
+	if err := json.Unmarshal([]byte(`{"b": 1, "a": 3, "b": 2}`), s); err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(s)
+	if string(output) != `{"a":1,"b":1}` {
+		t.Fatalf("Unmarshal result not correct: %s", string(output))
+	}
+
+	if s = geko.NewSyncMapFrom[string, any](nil); s.Len() != 0 {
+		t.Fatalf("NewSyncMapFrom nil should be empty: %v", s.Keys())
+	}
+}
+
+func TestSyncMap_Concurrent(t *testing.T) {
+	s := geko.NewSyncMap[string, int]()
+
+	const goroutines = 8
+	const operations = 1000
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < operations; i++ {
+				key := strconv.Itoa(i % 50)
+				switch (g + i) % 8 {
+				case 0:
+					s.Set(key, i)
+				case 1:
+					s.LoadOrStore(key, i)
+				case 2:
+					s.CompareAndSwap(key, i-1, i)
+				case 3:
+					s.Delete(key)
+				case 4:
+					s.Range(func(_ string, _ int) bool { return true })
+				case 5:
+					_, _ = json.Marshal(s)
+				case 6:
+					s.Update(func(m *geko.Map[string, int]) { m.Set(key, m.GetOrZeroValue(key)+1) })
+				default:
+					for _, k := range s.Keys() {
+						_, _ = s.Get(k)
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// every key in order should have its value
+	snapshot := s.Snapshot()
+	for i := 0; i < snapshot.Len(); i++ {
+		pair := snapshot.GetByIndex(i)
+		if value, exist := s.Get(pair.Key); !exist || value != pair.Value {
+			t.Fatalf("SyncMap inner state broken at key %s", pair.Key)
+		}
+	}
+
+	if len(snapshot.Keys()) != s.Len() || s.Len() > 50 {
+		t.Fatalf("SyncMap length not correct: %d", s.Len())
+	}
+}
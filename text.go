@@ -0,0 +1,52 @@
+package geko
+
+// MarshalText implements [encoding.TextMarshaler] interface, the text is the
+// compact JSON of the map, same as [Map.MarshalJSON]. A nil map is "null".
+//
+// It's useful for libraries which only know how to deal with text, like
+// environment variable loaders or [encoding/xml] attributes.
+func (m *Map[K, V]) MarshalText() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	return m.MarshalJSON()
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] interface, text is
+// decoded as JSON, same as [Map.UnmarshalJSON].
+//
+// So an [Object] struct field can be populated from an environment variable
+// or a default value in struct tag which contains JSON.
+func (m *Map[K, V]) UnmarshalText(text []byte) error {
+	return m.UnmarshalJSON(text)
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, see
+// [Map.MarshalText] for detail.
+func (ps *Pairs[K, V]) MarshalText() ([]byte, error) {
+	if ps == nil {
+		return []byte("null"), nil
+	}
+	return ps.MarshalJSON()
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] interface, same as
+// [Pairs.UnmarshalJSON].
+func (ps *Pairs[K, V]) UnmarshalText(text []byte) error {
+	return ps.UnmarshalJSON(text)
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, see
+// [Map.MarshalText] for detail.
+func (l *List[T]) MarshalText() ([]byte, error) {
+	if l == nil {
+		return []byte("null"), nil
+	}
+	return l.MarshalJSON()
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] interface, same as
+// [List.UnmarshalJSON], so decode options of the list are used.
+func (l *List[T]) UnmarshalText(text []byte) error {
+	return l.UnmarshalJSON(text)
+}
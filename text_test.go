@@ -0,0 +1,147 @@
+package geko_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+// loadEnv is a minimal environment variable loader, which sets fields of the
+// struct pointed by v by [encoding.TextUnmarshaler], from variable named by
+// "env" tag, or default value in "default" tag.
+func loadEnv(v any) error {
+	rv := reflect.ValueOf(v).Elem()
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+
+		text, exist := os.LookupEnv(field.Tag.Get("env"))
+		if !exist {
+			text = field.Tag.Get("default")
+		}
+
+		if rv.Field(i).Kind() == reflect.Pointer {
+			rv.Field(i).Set(reflect.New(field.Type.Elem()))
+		}
+
+		u, ok := rv.Field(i).Interface().(encoding.TextUnmarshaler)
+		if !ok {
+			return fmt.Errorf("field %s is not a text unmarshaler", field.Name)
+		}
+
+		if err := u.UnmarshalText([]byte(text)); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func TestText_Env(t *testing.T) {
+	t.Setenv("GEKO_TEST_LABELS", `{"team": "core", "env": "prod", "team": "infra"}`)
+	t.Setenv("GEKO_TEST_PORTS", `[8080, {"b": 1, "a": 2}]`)
+
+	var config struct {
+		Labels   geko.Object      `env:"GEKO_TEST_LABELS"`
+		Items    geko.ObjectItems `env:"GEKO_TEST_LABELS"`
+		Ports    geko.Array       `env:"GEKO_TEST_PORTS"`
+		Defaults geko.Object      `env:"GEKO_TEST_NOT_EXIST" default:"{\"b\": [1], \"a\": null}"`
+	}
+
+	if err := loadEnv(&config); err != nil {
+		t.Fatalf("Load env with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(config)
+	excepted := `{"Labels":{"team":"infra","env":"prod"},"Items":{"team":"core","env":"prod","team":"infra"},` +
+		`"Ports":[8080,{"b":1,"a":2}],"Defaults":{"b":[1],"a":null}}`
+	if string(output) != excepted {
+		t.Fatalf("Load env result not correct: %s", string(output))
+	}
+
+	t.Setenv("GEKO_TEST_PORTS", `[1,`)
+	if err := loadEnv(&config); err == nil {
+		t.Fatalf("Load env should fail on invalid JSON")
+	}
+}
+
+func TestText_RoundTrip(t *testing.T) {
+	input := `{"b":1,"a":[true,{"y":null,"x":"s"}],"b":2}`
+
+	m := geko.NewMap[string, any]()
+	ps := geko.NewPairs[string, any]()
+	l := geko.NewList[any]()
+	l.SetDecodeOptions(geko.UseObject())
+
+	for _, c := range []struct {
+		value interface {
+			encoding.TextMarshaler
+			encoding.TextUnmarshaler
+		}
+		input    string
+		excepted string
+	}{
+		{m, input, `{"b":2,"a":[true,{"y":null,"x":"s"}]}`},
+		{ps, input, `{"b":1,"a":[true,{"y":null,"x":"s"}],"b":2}`},
+		{l, "[" + input + "]", `[{"b":2,"a":[true,{"y":null,"x":"s"}]}]`},
+	} {
+		if err := c.value.UnmarshalText([]byte(c.input)); err != nil {
+			t.Fatalf("UnmarshalText with error: %s", err.Error())
+		}
+
+		text, err := c.value.MarshalText()
+		if err != nil || string(text) != c.excepted {
+			t.Fatalf("MarshalText result not correct: %s, %#v", string(text), err)
+		}
+
+		jsonOutput, _ := json.Marshal(c.value)
+		if string(jsonOutput) != string(text) {
+			t.Fatalf("MarshalText result should be same as JSON: %s", string(jsonOutput))
+		}
+	}
+
+	if _, isObject := l.Get(0).(geko.Object); !isObject {
+		t.Fatalf("UnmarshalText should use decode options of list: %#v", l.Get(0))
+	}
+}
+
+func TestText_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	for _, value := range []encoding.TextMarshaler{m, ps, l} {
+		text, err := value.MarshalText()
+		if err != nil || string(text) != "null" {
+			t.Fatalf("MarshalText of nil should be null: %s, %#v", string(text), err)
+		}
+	}
+}
+
+type textXMLNode struct {
+	XMLName xml.Name    `xml:"node"`
+	Labels  geko.Object `xml:"labels,attr"`
+}
+
+func TestText_XMLAttr(t *testing.T) {
+	var node textXMLNode
+
+	if err := xml.Unmarshal([]byte(`<node labels='{"b": 1, "a": 2}'/>`), &node); err != nil {
+		t.Fatalf("XML unmarshal with error: %s", err.Error())
+	}
+
+	if keys := node.Labels.Keys(); !reflect.DeepEqual(keys, []string{"b", "a"}) {
+		t.Fatalf("XML attr result not correct: %v", keys)
+	}
+
+	output, err := xml.Marshal(node)
+	if err != nil || string(output) != `<node labels="{&#34;b&#34;:1,&#34;a&#34;:2}"></node>` {
+		t.Fatalf("XML marshal result not correct: %s, %#v", string(output), err)
+	}
+}
@@ -0,0 +1,92 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// EmitTokens walks root in document order, and calls fn with each JSON token
+// of it, like tokens returned by [json.Decoder.Token] when decoding the JSON
+// encoding of root:
+//
+//   - An object emits [json.Delim] '{', then key string and value tokens of
+//     each member, then [json.Delim] '}'. All members of an [ObjectItems] are
+//     emitted, including duplicated keys. Members of a map[string]any are
+//     emitted in sorted order.
+//   - An array emits [json.Delim] '[', tokens of each item, then
+//     [json.Delim] ']'.
+//   - null, including nil containers, emits nil.
+//   - bool, float64, [json.Number] and string are emitted as is.
+//
+// Other values, like an int, a struct, or a [Map] with concrete type
+// parameters, are encoded by [JSONMarshal], and tokens of the result are
+// emitted, with numbers as [json.Number].
+//
+// If fn returns an error, or a value can't be encoded, EmitTokens stops and
+// returns the error.
+func EmitTokens(root any, fn func(token json.Token) error) error {
+	if isNull(root) {
+		return fn(nil)
+	}
+
+	if members, isObject := objectMembers(root); isObject {
+		if err := fn(json.Delim('{')); err != nil {
+			return err
+		}
+		for _, pair := range members {
+			err := fn(pair.Key)
+			if err == nil {
+				err = EmitTokens(pair.Value, fn)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return fn(json.Delim('}'))
+	}
+
+	if items, isArray := arrayItems(root); isArray {
+		if err := fn(json.Delim('[')); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := EmitTokens(item, fn); err != nil {
+				return err
+			}
+		}
+		return fn(json.Delim(']'))
+	}
+
+	switch root.(type) {
+	case bool, float64, json.Number, string:
+		return fn(root)
+	default:
+		return emitEncodedTokens(root, fn)
+	}
+}
+
+func emitEncodedTokens(v any, fn func(token json.Token) error) error {
+	data, err := JSONMarshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	for {
+		var token json.Token
+		token, err = dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err == nil {
+			err = fn(token)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
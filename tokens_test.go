@@ -0,0 +1,125 @@
+package geko_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func collectTokens(t *testing.T, root any) []json.Token {
+	t.Helper()
+
+	var tokens []json.Token
+	if err := geko.EmitTokens(root, func(token json.Token) error {
+		tokens = append(tokens, token)
+		return nil
+	}); err != nil {
+		t.Fatalf("EmitTokens with error: %s", err.Error())
+	}
+
+	return tokens
+}
+
+func decodeTokens(t *testing.T, data []byte) []json.Token {
+	t.Helper()
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var tokens []json.Token
+	for {
+		token, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return tokens
+		}
+		if err != nil {
+			t.Fatalf("Token with error: %s", err.Error())
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestEmitTokens(t *testing.T) {
+	for _, data := range []string{
+		`{"b": [1, "s", true, null, {}, []], "a": {"c": {"d": [[0.5]]}}, "b": false}`,
+		`[{"x": 1, "x": 2}, -1e10]`,
+		`"s"`,
+		`null`,
+	} {
+		for _, option := range []geko.DecodeOption{geko.UseObject(), geko.UseObjectItems()} {
+			root, _ := geko.JSONUnmarshal([]byte(data), option, geko.UseNumber(true))
+
+			output, _ := json.Marshal(root)
+			excepted := decodeTokens(t, output)
+			tokens := collectTokens(t, root)
+			if !reflect.DeepEqual(tokens, excepted) {
+				t.Fatalf("EmitTokens of %s not correct, excepted %v, got %v", data, excepted, tokens)
+			}
+		}
+	}
+}
+
+func TestEmitTokens_OtherTypes(t *testing.T) {
+	var nilObject geko.Object
+
+	root := geko.NewListOf[any](
+		1.5,
+		nilObject,
+		map[string]any{"b": 1, "a": []any{2}},
+		geko.NewMapOf(geko.P("k", 3)),
+		struct {
+			X int `json:"x"`
+		}{4},
+	)
+
+	tokens := collectTokens(t, root)
+	excepted := []json.Token{
+		json.Delim('['),
+		1.5,
+		nil,
+		json.Delim('{'),
+		"a", json.Delim('['), json.Number("2"), json.Delim(']'),
+		"b", json.Number("1"),
+		json.Delim('}'),
+		json.Delim('{'), "k", json.Number("3"), json.Delim('}'),
+		json.Delim('{'), "x", json.Number("4"), json.Delim('}'),
+		json.Delim(']'),
+	}
+	if !reflect.DeepEqual(tokens, excepted) {
+		t.Fatalf("EmitTokens result not correct: %v", tokens)
+	}
+}
+
+func TestEmitTokens_Error(t *testing.T) {
+	root, _ := geko.JSONUnmarshal([]byte(`{"a": [1, {"b": 2}]}`), geko.UseObject())
+	root.(geko.Object).Set("c", geko.NewMapOf(geko.P("k", 3)))
+	tokens := collectTokens(t, root)
+
+	errStop := errors.New("stop")
+	for stop := range tokens {
+		count := 0
+		err := geko.EmitTokens(root, func(json.Token) error {
+			if count == stop {
+				return errStop
+			}
+			count++
+			return nil
+		})
+		if !errors.Is(err, errStop) || count != stop {
+			t.Fatalf("EmitTokens should stop at token %d: %v, %d", stop, err, count)
+		}
+	}
+
+	err := geko.EmitTokens(geko.NewListOf[any](math.Inf(1), geko.NewListOf(math.NaN())), func(json.Token) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("EmitTokens should fail on value can't be encoded")
+	}
+}
@@ -0,0 +1,932 @@
+package geko
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// tomlMaxDepth limits nesting of arrays and tables in a value, to avoid stack
+// overflow on malicious input, like the std lib JSON decoder does.
+const tomlMaxDepth = 10000
+
+const (
+	tomlLocalDateTimeLayout = "2006-01-02T15:04:05.999999999"
+	tomlLocalDateLayout     = "2006-01-02"
+	tomlLocalTimeLayout     = "15:04:05.999999999"
+)
+
+// TOMLLocalDateTime is a TOML local date-time, like 1979-05-27T07:32:00,
+// which has no time zone offset. The location of the time is UTC, and should
+// be ignored.
+type TOMLLocalDateTime time.Time
+
+// String formats the local date-time in TOML format.
+func (t TOMLLocalDateTime) String() string {
+	return time.Time(t).Format(tomlLocalDateTimeLayout)
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, so it's a
+// string in JSON.
+func (t TOMLLocalDateTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// TOMLLocalDate is a TOML local date, like 1979-05-27. The time and location
+// of it is zero, and should be ignored.
+type TOMLLocalDate time.Time
+
+// String formats the local date in TOML format.
+func (t TOMLLocalDate) String() string {
+	return time.Time(t).Format(tomlLocalDateLayout)
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, so it's a
+// string in JSON.
+func (t TOMLLocalDate) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// TOMLLocalTime is a TOML local time, like 07:32:00. The date and location of
+// it is zero, and should be ignored.
+type TOMLLocalTime time.Time
+
+// String formats the local time in TOML format.
+func (t TOMLLocalTime) String() string {
+	return time.Time(t).Format(tomlLocalTimeLayout)
+}
+
+// MarshalText implements [encoding.TextMarshaler] interface, so it's a
+// string in JSON.
+func (t TOMLLocalTime) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// TOMLUnmarshal decodes a [TOML v1.0.0] document. The result is always an
+// [Object], keys of tables are in the order they are defined in data:
+//
+//   - Tables, including inline tables, are decoded into [Object].
+//   - Arrays, including arrays of tables, are decoded into [Array].
+//   - Integers are int64, floats are float64.
+//   - Offset date-times are time.Time. Local date-times, local dates and
+//     local times are [TOMLLocalDateTime], [TOMLLocalDate] and
+//     [TOMLLocalTime].
+//
+// Keys can't be duplicated in TOML, a [*TOMLError] is returned for them, and
+// for any other invalid input, including values nested deeper than 10000
+// levels.
+//
+// [TOML v1.0.0]: https://toml.io/en/v1.0.0
+func TOMLUnmarshal(data []byte) (any, error) {
+	p := tomlParser{
+		data:   data,
+		root:   NewMap[string, any](),
+		tables: make(map[Object]tomlTableKind),
+		arrays: make(map[Array]bool),
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+
+	return p.root, nil
+}
+
+// tomlTableKind tells how a table is created, which decides whether it can be
+// defined or extended later.
+type tomlTableKind uint8
+
+const (
+	// tomlImplicit is created as a parent of a table header, it can be
+	// defined by a header once.
+	tomlImplicit tomlTableKind = iota + 1
+	// tomlExplicit is defined by a table header.
+	tomlExplicit
+	// tomlDotted is created by dotted keys, it can be extended by dotted keys.
+	tomlDotted
+	// tomlInline is an inline table, which can't be extended.
+	tomlInline
+)
+
+type tomlParser struct {
+	data    []byte
+	offset  int
+	root    Object
+	current Object
+	// tables records kind of all tables, except root.
+	tables map[Object]tomlTableKind
+	// arrays records arrays of tables, other arrays are static.
+	arrays map[Array]bool
+	// depth is the nesting depth of arrays and inline tables being parsed.
+	depth int
+}
+
+func (p *tomlParser) syntaxError(offset int, format string, args ...any) error {
+	line := bytes.Count(p.data[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(p.data[:offset], '\n')
+	return &TOMLError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// enter should be called when meet the start of an array or inline table, it
+// checks the nesting depth.
+func (p *tomlParser) enter() error {
+	if p.depth >= tomlMaxDepth {
+		return p.syntaxError(p.offset, "exceeded max depth %d", tomlMaxDepth)
+	}
+	p.depth++
+	return nil
+}
+
+// leave should be called when an array or inline table is finished.
+func (p *tomlParser) leave() {
+	p.depth--
+}
+
+func (p *tomlParser) eof() bool {
+	return p.offset >= len(p.data)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.offset]
+}
+
+func (p *tomlParser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.data[p.offset:], []byte(prefix))
+}
+
+func (p *tomlParser) parse() error {
+	if !utf8.Valid(p.data) {
+		valid := 0
+		for r, size := utf8.DecodeRune(p.data); r != utf8.RuneError || size > 1; {
+			valid += size
+			r, size = utf8.DecodeRune(p.data[valid:])
+		}
+		return p.syntaxError(valid, "invalid UTF-8")
+	}
+
+	if p.hasPrefix("\xEF\xBB\xBF") {
+		p.offset += len("\xEF\xBB\xBF")
+	}
+
+	p.current = p.root
+
+	for {
+		p.skipSpaces()
+
+		var err error
+		switch c := p.peek(); {
+		case p.eof():
+			return nil
+		case c == '#' || c == '\n' || c == '\r':
+			err = p.lineEnd()
+		case c == '[':
+			err = p.header()
+		default:
+			if err = p.keyValue(p.current); err == nil {
+				err = p.lineEnd()
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tomlParser) skipSpaces() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.offset++
+	}
+}
+
+func isTOMLControl(c byte) bool {
+	return (c < 0x20 && c != '\t') || c == 0x7f
+}
+
+func (p *tomlParser) skipComment() error {
+	if p.peek() != '#' {
+		return nil
+	}
+
+	for !p.eof() && p.peek() != '\n' && !p.hasPrefix("\r\n") {
+		if isTOMLControl(p.peek()) {
+			return p.syntaxError(p.offset, "control character in comment")
+		}
+		p.offset++
+	}
+
+	return nil
+}
+
+// newline consumes a newline, reports false if there is not.
+func (p *tomlParser) newline() bool {
+	switch {
+	case p.peek() == '\n':
+		p.offset++
+	case p.hasPrefix("\r\n"):
+		p.offset += 2
+	default:
+		return false
+	}
+	return true
+}
+
+// lineEnd consumes spaces, comment, and the newline.
+func (p *tomlParser) lineEnd() error {
+	p.skipSpaces()
+
+	if err := p.skipComment(); err != nil {
+		return err
+	}
+
+	if !p.eof() && !p.newline() {
+		return p.syntaxError(p.offset, "expect newline")
+	}
+
+	return nil
+}
+
+// skipBlank skips spaces, comments and newlines, in arrays.
+func (p *tomlParser) skipBlank() error {
+	for {
+		p.skipSpaces()
+		if err := p.skipComment(); err != nil {
+			return err
+		}
+		if !p.newline() {
+			return nil
+		}
+	}
+}
+
+func (p *tomlParser) header() error {
+	start := p.offset
+
+	isArray := p.hasPrefix("[[")
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	p.offset += len(closing)
+
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+
+	if !p.hasPrefix(closing) {
+		return p.syntaxError(p.offset, "expect %q", closing)
+	}
+	p.offset += len(closing)
+
+	if err = p.lineEnd(); err != nil {
+		return err
+	}
+
+	table := p.root
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.headerParent(start, table, key); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	child, exist := table.Get(last)
+
+	if isArray {
+		array, isArrayOfTables := child.(Array)
+		if !exist {
+			array = NewList[any]()
+			p.arrays[array] = true
+			table.Set(last, array)
+		} else if !isArrayOfTables || !p.arrays[array] {
+			return p.syntaxError(start, "key %q is already defined", last)
+		}
+
+		p.current = p.newTable(tomlExplicit)
+		array.Append(p.current)
+		return nil
+	}
+
+	if !exist {
+		p.current = p.newTable(tomlExplicit)
+		table.Set(last, p.current)
+		return nil
+	}
+
+	if object, isObject := child.(Object); isObject && p.tables[object] == tomlImplicit {
+		p.tables[object] = tomlExplicit
+		p.current = object
+		return nil
+	}
+
+	return p.syntaxError(start, "table %q is already defined", last)
+}
+
+// headerParent steps into a parent table in a header, creating it if needed.
+func (p *tomlParser) headerParent(start int, table Object, key string) (Object, error) {
+	child, exist := table.Get(key)
+	if !exist {
+		object := p.newTable(tomlImplicit)
+		table.Set(key, object)
+		return object, nil
+	}
+
+	switch x := child.(type) {
+	case Object:
+		if p.tables[x] != tomlInline {
+			return x, nil
+		}
+	case Array:
+		if p.arrays[x] {
+			last, _ := x.Get(x.Len() - 1).(Object)
+			return last, nil
+		}
+	}
+
+	return nil, p.syntaxError(start, "key %q is not a table", key)
+}
+
+func (p *tomlParser) newTable(kind tomlTableKind) Object {
+	table := NewMap[string, any]()
+	p.tables[table] = kind
+	return table
+}
+
+// keyValue parses a key/value pair, and sets it into table.
+func (p *tomlParser) keyValue(table Object) error {
+	start := p.offset
+
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+
+	if p.peek() != '=' {
+		return p.syntaxError(p.offset, "expect '='")
+	}
+	p.offset++
+	p.skipSpaces()
+
+	// tables created by dotted keys are nested too
+	if p.depth+len(keys)-1 > tomlMaxDepth {
+		return p.syntaxError(start, "exceeded max depth %d", tomlMaxDepth)
+	}
+
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys[:len(keys)-1] {
+		child, exist := table.Get(key)
+		if !exist {
+			object := p.newTable(tomlDotted)
+			table.Set(key, object)
+			table = object
+			continue
+		}
+
+		object, isObject := child.(Object)
+		if !isObject || p.tables[object] != tomlDotted {
+			return p.syntaxError(start, "key %q can't be extended", key)
+		}
+		table = object
+	}
+
+	last := keys[len(keys)-1]
+	if table.Has(last) {
+		return p.syntaxError(start, "key %q is already defined", last)
+	}
+	table.Set(last, value)
+
+	return nil
+}
+
+// key parses a dotted key, and spaces around it.
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+
+	for {
+		p.skipSpaces()
+
+		var key string
+		var err error
+
+		switch c := p.peek(); {
+		case c == '"':
+			key, err = p.basicString()
+		case c == '\'':
+			key, err = p.literalString()
+		default:
+			start := p.offset
+			for c = p.peek(); isTOMLBareKeyChar(c); c = p.peek() {
+				p.offset++
+			}
+			if p.offset == start {
+				return nil, p.syntaxError(p.offset, "invalid key")
+			}
+			key = string(p.data[start:p.offset])
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.offset++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) value() (any, error) {
+	switch c := p.peek(); {
+	case p.hasPrefix(`"""`):
+		return p.multilineString('"')
+	case c == '"':
+		return p.basicString()
+	case p.hasPrefix("'''"):
+		return p.multilineString('\'')
+	case c == '\'':
+		return p.literalString()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	case p.hasPrefix("true"):
+		p.offset += len("true")
+		return true, nil
+	case p.hasPrefix("false"):
+		p.offset += len("false")
+		return false, nil
+	default:
+		return p.scalar()
+	}
+}
+
+func (p *tomlParser) basicString() (string, error) {
+	start := p.offset
+	p.offset++
+
+	var sb strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.eof() || c == '\n' || c == '\r':
+			return "", p.syntaxError(start, "unterminated string")
+		case c == '"':
+			p.offset++
+			return sb.String(), nil
+		case c == '\\':
+			if err := p.escape(&sb); err != nil {
+				return "", err
+			}
+		case isTOMLControl(c):
+			return "", p.syntaxError(p.offset, "control character in string")
+		default:
+			_ = sb.WriteByte(c)
+			p.offset++
+		}
+	}
+}
+
+var tomlEscapes = map[byte]byte{
+	'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', '"': '"', '\\': '\\',
+}
+
+func (p *tomlParser) escape(sb *strings.Builder) error {
+	start := p.offset
+	p.offset++
+
+	c := p.peek()
+	p.offset++
+
+	if escaped, ok := tomlEscapes[c]; ok {
+		_ = sb.WriteByte(escaped)
+		return nil
+	}
+
+	size := 0
+	switch c {
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return p.syntaxError(start, "invalid escape")
+	}
+
+	if p.offset+size > len(p.data) {
+		return p.syntaxError(start, "invalid escape")
+	}
+
+	code, err := strconv.ParseUint(string(p.data[p.offset:p.offset+size]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.syntaxError(start, "invalid escape")
+	}
+
+	_, _ = sb.WriteRune(rune(code))
+	p.offset += size
+
+	return nil
+}
+
+func (p *tomlParser) literalString() (string, error) {
+	start := p.offset
+	p.offset++
+
+	for {
+		switch c := p.peek(); {
+		case p.eof() || c == '\n' || c == '\r':
+			return "", p.syntaxError(start, "unterminated string")
+		case c == '\'':
+			p.offset++
+			return string(p.data[start+1 : p.offset-1]), nil
+		case isTOMLControl(c):
+			return "", p.syntaxError(p.offset, "control character in string")
+		default:
+			p.offset++
+		}
+	}
+}
+
+// multilineString parses multi-line basic string if quote is '"', otherwise
+// multi-line literal string.
+func (p *tomlParser) multilineString(quote byte) (string, error) {
+	start := p.offset
+	p.offset += 3
+
+	// a newline immediately following the opening delimiter is trimmed
+	p.newline()
+
+	var sb strings.Builder
+	for {
+		switch c := p.peek(); {
+		case p.eof():
+			return "", p.syntaxError(start, "unterminated string")
+		case c == quote && p.hasPrefix(strings.Repeat(string(quote), 3)):
+			// at most 2 quotes are allowed before closing delimiter
+			count := 3
+			for count < 6 && p.offset+count < len(p.data) && p.data[p.offset+count] == quote {
+				count++
+			}
+			if count == 6 {
+				return "", p.syntaxError(p.offset, "too many quotes")
+			}
+			_, _ = sb.WriteString(strings.Repeat(string(quote), count-3))
+			p.offset += count
+			return sb.String(), nil
+		case c == '\\' && quote == '"':
+			if err := p.multilineEscape(&sb); err != nil {
+				return "", err
+			}
+		case p.newline():
+			_ = sb.WriteByte('\n')
+		case isTOMLControl(c):
+			return "", p.syntaxError(p.offset, "control character in string")
+		default:
+			_ = sb.WriteByte(c)
+			p.offset++
+		}
+	}
+}
+
+// multilineEscape deals with escapes in multi-line basic string, including
+// line ending backslash, which trims all whitespaces and newlines after it.
+func (p *tomlParser) multilineEscape(sb *strings.Builder) error {
+	i := p.offset + 1
+	for i < len(p.data) && (p.data[i] == ' ' || p.data[i] == '\t') {
+		i++
+	}
+
+	if i < len(p.data) && (p.data[i] == '\n' || p.data[i] == '\r') {
+		p.offset = i
+		for p.newline() {
+			p.skipSpaces()
+		}
+		return nil
+	}
+
+	return p.escape(sb)
+}
+
+func (p *tomlParser) array() (Array, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.offset++
+	array := NewList[any]()
+
+	for {
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+
+		if p.peek() == ']' {
+			p.offset++
+			return array, nil
+		}
+
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		array.Append(item)
+
+		if err = p.skipBlank(); err != nil {
+			return nil, err
+		}
+
+		switch p.peek() {
+		case ',':
+			p.offset++
+		case ']':
+			p.offset++
+			return array, nil
+		default:
+			return nil, p.syntaxError(p.offset, "expect ',' or ']'")
+		}
+	}
+}
+
+func (p *tomlParser) inlineTable() (Object, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.offset++
+	table := p.newTable(tomlDotted)
+
+	p.skipSpaces()
+	if p.peek() == '}' {
+		p.offset++
+		p.freeze(table)
+		return table, nil
+	}
+
+	for {
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+
+		switch p.peek() {
+		case ',':
+			p.offset++
+		case '}':
+			p.offset++
+			p.freeze(table)
+			return table, nil
+		default:
+			return nil, p.syntaxError(p.offset, "expect ',' or '}'")
+		}
+	}
+}
+
+// freeze marks table, and tables created by dotted keys in it, as inline.
+func (p *tomlParser) freeze(table Object) {
+	p.tables[table] = tomlInline
+
+	for i := 0; i < table.Len(); i++ {
+		// nested inline tables are frozen already
+		if object, isObject := table.GetValueByIndex(i).(Object); isObject && p.tables[object] != tomlInline {
+			p.freeze(object)
+		}
+	}
+}
+
+// scalar parses numbers and date-times.
+func (p *tomlParser) scalar() (any, error) {
+	start := p.offset
+	for c := p.peek(); isTOMLBareKeyChar(c) || c == '+' || c == '.' || c == ':'; c = p.peek() {
+		p.offset++
+	}
+
+	// a space can be used as delimiter between date and time
+	if p.offset-start == len("2006-01-02") && p.peek() == ' ' &&
+		p.offset+3 < len(p.data) && p.data[p.offset+3] == ':' {
+		p.offset++
+		for c := p.peek(); isTOMLBareKeyChar(c) || c == '+' || c == '.' || c == ':'; c = p.peek() {
+			p.offset++
+		}
+	}
+
+	token := string(p.data[start:p.offset])
+
+	var value any
+	var ok bool
+	if strings.Contains(token, ":") || (len(token) >= len("2006-01-02") && token[4] == '-') {
+		value, ok = parseTOMLDateTime(token)
+	} else {
+		value, ok = parseTOMLNumber(token)
+	}
+
+	if !ok {
+		return nil, p.syntaxError(start, "invalid value")
+	}
+
+	return value, nil
+}
+
+// tomlDateTimeShape checks the format of a date-time, which time.Parse is not
+// strict about, and reports what parts it has.
+func tomlDateTimeShape(s string) (hasDate, hasTime, hasOffset, ok bool) {
+	// s has the same length as pattern, where 0 is for any digit
+	matches := func(s, pattern string) bool {
+		for i := range pattern {
+			if pattern[i] == '0' && (s[i] < '0' || s[i] > '9') || pattern[i] != '0' && s[i] != pattern[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	rest := s
+	if len(rest) >= 10 && matches(rest[:10], "0000-00-00") {
+		hasDate, rest = true, rest[10:]
+		if rest == "" {
+			return hasDate, false, false, true
+		}
+		if rest[0] != 'T' {
+			return false, false, false, false
+		}
+		rest = rest[1:]
+	}
+
+	if len(rest) < 8 || !matches(rest[:8], "00:00:00") {
+		return false, false, false, false
+	}
+	rest = rest[8:]
+
+	if rest != "" && rest[0] == '.' {
+		i := 1
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 1 {
+			return false, false, false, false
+		}
+		rest = rest[i:]
+	}
+
+	hasOffset = rest == "Z" || len(rest) == 6 && (rest[0] == '+' || rest[0] == '-') && matches(rest[1:], "00:00")
+
+	return hasDate, true, hasOffset, rest == "" || hasDate && hasOffset
+}
+
+func parseTOMLDateTime(token string) (any, bool) {
+	s := strings.ToUpper(token)
+	if len(s) > 10 && s[10] == ' ' {
+		s = s[:10] + "T" + s[11:]
+	}
+
+	hasDate, hasTime, hasOffset, ok := tomlDateTimeShape(s)
+	if !ok {
+		return nil, false
+	}
+
+	var layout string
+	switch {
+	case hasOffset:
+		layout = time.RFC3339Nano
+	case hasDate && hasTime:
+		layout = tomlLocalDateTimeLayout
+	case hasDate:
+		layout = tomlLocalDateLayout
+	default:
+		layout = tomlLocalTimeLayout
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return nil, false
+	}
+
+	switch {
+	case hasOffset:
+		return t, true
+	case hasDate && hasTime:
+		return TOMLLocalDateTime(t), true
+	case hasDate:
+		return TOMLLocalDate(t), true
+	default:
+		return TOMLLocalTime(t), true
+	}
+}
+
+// validTOMLDigits checks s is digits, underscores are only allowed between
+// digits.
+func validTOMLDigits(s string, isDigit func(c byte) bool) bool {
+	if s == "" || s[0] == '_' || s[len(s)-1] == '_' || strings.Contains(s, "__") {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' && !isDigit(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isDecimalDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func parseTOMLNumber(token string) (any, bool) {
+	switch token {
+	case "inf", "+inf":
+		return math.Inf(1), true
+	case "-inf":
+		return math.Inf(-1), true
+	case "nan", "+nan", "-nan":
+		return math.NaN(), true
+	}
+
+	if len(token) > 2 && token[0] == '0' && strings.IndexByte("xob", token[1]) >= 0 {
+		return parseTOMLPrefixedInteger(token)
+	}
+
+	body := strings.TrimPrefix(strings.TrimPrefix(token, "+"), "-")
+
+	intPart := body
+	if i := strings.IndexAny(body, ".eE"); i >= 0 {
+		intPart = body[:i]
+	}
+
+	if !validTOMLDigits(intPart, isDecimalDigit) || (len(intPart) > 1 && intPart[0] == '0') {
+		return nil, false
+	}
+
+	cleaned := strings.ReplaceAll(token, "_", "")
+
+	if intPart == body {
+		n, err := strconv.ParseInt(cleaned, 10, 64)
+		return n, err == nil
+	}
+
+	rest := body[len(intPart):]
+	if rest[0] == '.' {
+		frac := rest[1:]
+		if i := strings.IndexAny(frac, "eE"); i >= 0 {
+			frac = frac[:i]
+		}
+		if !validTOMLDigits(frac, isDecimalDigit) {
+			return nil, false
+		}
+		rest = rest[1+len(frac):]
+	}
+
+	if rest != "" {
+		exponent := strings.TrimPrefix(strings.TrimPrefix(rest[1:], "+"), "-")
+		if !validTOMLDigits(exponent, isDecimalDigit) {
+			return nil, false
+		}
+	}
+
+	f, err := strconv.ParseFloat(cleaned, 64)
+	return f, err == nil
+}
+
+func parseTOMLPrefixedInteger(token string) (any, bool) {
+	var base int
+	var isDigit func(c byte) bool
+
+	switch token[1] {
+	case 'x':
+		base, isDigit = 16, func(c byte) bool {
+			return isDecimalDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+		}
+	case 'o':
+		base, isDigit = 8, func(c byte) bool { return c >= '0' && c <= '7' }
+	default:
+		base, isDigit = 2, func(c byte) bool { return c == '0' || c == '1' }
+	}
+
+	digits := token[2:]
+	if !validTOMLDigits(digits, isDigit) {
+		return nil, false
+	}
+
+	n, err := strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+	return n, err == nil
+}
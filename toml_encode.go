@@ -0,0 +1,319 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TOMLMarshal encodes v into a TOML document. v must be an object, which can
+// be [Object], [ObjectItems] or map[string]any, like values in it. Members are
+// written in their order, members of a map[string]any are sorted.
+//
+// Objects are written as tables, and non-empty arrays whose items are all
+// objects are written as arrays of tables. But in TOML, key/value pairs of a
+// table must come before its sub-tables, so an object or array of objects
+// followed by other members is written inline instead, to keep the order.
+//
+// Numbers can be float64, json.Number and Go integer types. Date-times can be
+// time.Time, [TOMLLocalDateTime], [TOMLLocalDate] and [TOMLLocalTime].
+//
+// An error is returned for values TOML can't represent, like null, duplicated
+// keys of [ObjectItems], integers out of int64 range, and unsupported types.
+func TOMLMarshal(v any) ([]byte, error) {
+	if !isTOMLTable(v) {
+		return nil, fmt.Errorf("geko: toml document must be an object, got %T", v)
+	}
+
+	members, _ := objectMembers(v)
+
+	e := tomlEncoder{}
+	if err := e.table(nil, members); err != nil {
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+type tomlEncoder struct {
+	buf bytes.Buffer
+}
+
+func tomlKeyPath(path []string) string {
+	keys := make([]string, 0, len(path))
+	for _, key := range path {
+		keys = append(keys, tomlKey(key))
+	}
+	return strings.Join(keys, ".")
+}
+
+func tomlKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isTOMLBareKeyChar(key[i]) {
+			return tomlString(key)
+		}
+	}
+
+	if key == "" {
+		return `""`
+	}
+
+	return key
+}
+
+// isTOMLTable tells if v is written as a table.
+func isTOMLTable(v any) bool {
+	if isNull(v) {
+		return false
+	}
+
+	_, isObject := objectMembers(v)
+	return isObject
+}
+
+// isTOMLArrayOfTables tells if v is written as an array of tables.
+func isTOMLArrayOfTables(v any) bool {
+	items, isArray := arrayItems(v)
+	if !isArray || len(items) == 0 {
+		return false
+	}
+
+	for _, item := range items {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tomlSubTables returns index of the first member written as sub-table, they
+// are members after the last key/value pair.
+func tomlSubTables(members []Pair[string, any]) int {
+	split := len(members)
+	for split > 0 && (isTOMLTable(members[split-1].Value) || isTOMLArrayOfTables(members[split-1].Value)) {
+		split--
+	}
+	return split
+}
+
+func (e *tomlEncoder) header(path []string, isArray bool) {
+	if e.buf.Len() > 0 {
+		_ = e.buf.WriteByte('\n')
+	}
+
+	open, closing := "[", "]\n"
+	if isArray {
+		open, closing = "[[", "]]\n"
+	}
+
+	_, _ = e.buf.WriteString(open)
+	_, _ = e.buf.WriteString(tomlKeyPath(path))
+	_, _ = e.buf.WriteString(closing)
+}
+
+func (e *tomlEncoder) table(path []string, members []Pair[string, any]) error {
+	if hasDuplicateKeys(members) {
+		return fmt.Errorf("geko: duplicated keys in toml table %q", tomlKeyPath(path))
+	}
+
+	split := tomlSubTables(members)
+
+	for _, pair := range members[:split] {
+		_, _ = e.buf.WriteString(tomlKey(pair.Key))
+		_, _ = e.buf.WriteString(" = ")
+		if err := e.value(append(path[:len(path):len(path)], pair.Key), pair.Value); err != nil {
+			return err
+		}
+		_ = e.buf.WriteByte('\n')
+	}
+
+	for _, pair := range members[split:] {
+		childPath := append(path[:len(path):len(path)], pair.Key)
+
+		if items, isArray := arrayItems(pair.Value); isArray {
+			for _, item := range items {
+				e.header(childPath, true)
+				itemMembers, _ := objectMembers(item)
+				if err := e.table(childPath, itemMembers); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		childMembers, _ := objectMembers(pair.Value)
+
+		// the header of a table which only contains sub-tables can be omitted
+		if len(childMembers) == 0 || tomlSubTables(childMembers) > 0 {
+			e.header(childPath, false)
+		}
+
+		if err := e.table(childPath, childMembers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// value writes an inline value.
+func (e *tomlEncoder) value(path []string, v any) error {
+	if isNull(v) {
+		return fmt.Errorf("geko: null at %q can't be encoded to toml", tomlKeyPath(path))
+	}
+
+	switch x := v.(type) {
+	case string:
+		_, _ = e.buf.WriteString(tomlString(x))
+	case bool:
+		_, _ = e.buf.WriteString(strconv.FormatBool(x))
+	case float64:
+		_, _ = e.buf.WriteString(tomlFloat(x, 64))
+	case json.Number:
+		return e.number(path, x)
+	case time.Time:
+		_, _ = e.buf.WriteString(x.Format(time.RFC3339Nano))
+	case TOMLLocalDateTime:
+		_, _ = e.buf.WriteString(x.String())
+	case TOMLLocalDate:
+		_, _ = e.buf.WriteString(x.String())
+	case TOMLLocalTime:
+		_, _ = e.buf.WriteString(x.String())
+	default:
+		if members, isObject := objectMembers(v); isObject {
+			return e.inlineTable(path, members)
+		}
+		if items, isArray := arrayItems(v); isArray {
+			return e.array(path, items)
+		}
+		return e.reflectNumber(path, v)
+	}
+
+	return nil
+}
+
+func (e *tomlEncoder) inlineTable(path []string, members []Pair[string, any]) error {
+	if hasDuplicateKeys(members) {
+		return fmt.Errorf("geko: duplicated keys in toml table %q", tomlKeyPath(path))
+	}
+
+	_ = e.buf.WriteByte('{')
+	for i, pair := range members {
+		if i > 0 {
+			_ = e.buf.WriteByte(',')
+		}
+		_ = e.buf.WriteByte(' ')
+		_, _ = e.buf.WriteString(tomlKey(pair.Key))
+		_, _ = e.buf.WriteString(" = ")
+		if err := e.value(append(path[:len(path):len(path)], pair.Key), pair.Value); err != nil {
+			return err
+		}
+	}
+	if len(members) > 0 {
+		_ = e.buf.WriteByte(' ')
+	}
+	_ = e.buf.WriteByte('}')
+
+	return nil
+}
+
+func (e *tomlEncoder) array(path []string, items []any) error {
+	_ = e.buf.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			_, _ = e.buf.WriteString(", ")
+		}
+		if err := e.value(append(path[:len(path):len(path)], strconv.Itoa(i)), item); err != nil {
+			return err
+		}
+	}
+	_ = e.buf.WriteByte(']')
+
+	return nil
+}
+
+func (e *tomlEncoder) number(path []string, n json.Number) error {
+	s := string(n)
+
+	if !strings.ContainsAny(s, ".eE") {
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return fmt.Errorf("geko: integer %s at %q can't be encoded to toml", s, tomlKeyPath(path))
+		}
+	}
+
+	_, _ = e.buf.WriteString(s)
+	return nil
+}
+
+// reflectNumber writes Go integer and float32 values, which are not common.
+func (e *tomlEncoder) reflectNumber(path []string, v any) error {
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, _ = e.buf.WriteString(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.number(path, json.Number(strconv.FormatUint(rv.Uint(), 10)))
+	case reflect.Float32:
+		_, _ = e.buf.WriteString(tomlFloat(rv.Float(), 32))
+	default:
+		return fmt.Errorf("geko: value of type %T at %q can't be encoded to toml", v, tomlKeyPath(path))
+	}
+
+	return nil
+}
+
+func tomlFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+
+	return s
+}
+
+func tomlString(s string) string {
+	var sb strings.Builder
+
+	_ = sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			_, _ = sb.WriteString(`\"`)
+		case r == '\\':
+			_, _ = sb.WriteString(`\\`)
+		case r == '\n':
+			_, _ = sb.WriteString(`\n`)
+		case r == '\t':
+			_, _ = sb.WriteString(`\t`)
+		case r == '\r':
+			_, _ = sb.WriteString(`\r`)
+		case r == '\b':
+			_, _ = sb.WriteString(`\b`)
+		case r == '\f':
+			_, _ = sb.WriteString(`\f`)
+		case r < 0x20 || r == 0x7f:
+			_, _ = fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			_, _ = sb.WriteRune(r)
+		}
+	}
+	_ = sb.WriteByte('"')
+
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+func TestTOMLMarshal(t *testing.T) {
+	v, _ := geko.TOMLUnmarshal([]byte(tomlTestDocument))
+
+	output, err := geko.TOMLMarshal(v)
+	if err != nil {
+		t.Fatalf("TOMLMarshal with error: %s", err.Error())
+	}
+
+	excepted := `title = "TOML \"Example\"é😀\b\t\n\f\r\\"
+"quoted key" = "C:\\path"
+site = { "google.com" = true }
+multi = "Roses are red\n\"\" \""
+crlf = "a\nb"
+lit = "raw \\n '"
+ints = [99, -17, 0, 1000, 3735928559, 493, 13]
+floats = [1.0, 3.1415, -0.01, 5e+22, 1e+06, -0.02, 6.626e-34, 224617.445991]
+dates = [1979-05-27T07:32:00Z, 1979-05-27T00:32:00.999999-07:00, 1979-05-27T07:32:00, 1979-05-27, 07:32:00.5]
+nested = [[1, 2], ["a", "b"], [{ x = 1 }, {}]]
+
+[point]
+x = 1
+
+[point.y]
+z = 2
+
+[point.w]
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[servers.beta]
+ip = "10.0.0.2"
+
+[fruit.apple.color]
+name = "red"
+
+[fruit.apple.color.texture]
+smooth = true
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+
+[[products]]
+name = "Nail"
+
+[products.meta]
+color = "gray"
+
+[[products.variants]]
+size = 1
+`
+	if string(output) != excepted {
+		t.Fatalf("TOMLMarshal result not correct: %s", string(output))
+	}
+
+	// decode again results the same value, including order
+	again, err := geko.TOMLUnmarshal(output)
+	if err != nil {
+		t.Fatalf("TOMLUnmarshal with error: %s", err.Error())
+	}
+
+	before, _ := json.Marshal(v)
+	after, _ := json.Marshal(again)
+	if string(before) != string(after) {
+		t.Fatalf("TOML round trip result not correct: %s", string(after))
+	}
+}
+
+func TestTOMLMarshal_Order(t *testing.T) {
+	object := geko.NewMap[string, any]()
+	object.Set("table", map[string]any{"b": 1, "a": []any{}})
+	object.Set("tables", []any{map[string]any{"x": 1}})
+	object.Set("key", "value")
+	object.Set("last", geko.NewMap[string, any]())
+
+	output, err := geko.TOMLMarshal(object)
+	if err != nil {
+		t.Fatalf("TOMLMarshal with error: %s", err.Error())
+	}
+
+	excepted := "table = { a = [], b = 1 }\ntables = [{ x = 1 }]\nkey = \"value\"\n\n[last]\n"
+	if string(output) != excepted {
+		t.Fatalf("TOMLMarshal result not correct: %s", string(output))
+	}
+}
+
+func TestTOMLMarshal_Values(t *testing.T) {
+	items := geko.NewPairs[string, any]()
+	items.Add("", "\x01\x7f")
+	items.Add("a b", []any{
+		math.NaN(), math.Inf(1), math.Inf(-1), 3.0, 1e21, float32(0.1),
+		int8(-3), uint(5), json.Number("1e3"), json.Number("-12"),
+		time.Date(2023, 1, 2, 3, 4, 5, 600, time.UTC),
+	})
+
+	output, err := geko.TOMLMarshal(items)
+	if err != nil {
+		t.Fatalf("TOMLMarshal with error: %s", err.Error())
+	}
+
+	excepted := `"" = "\u0001\u007F"` + "\n" +
+		`"a b" = [nan, inf, -inf, 3.0, 1e+21, 0.1, -3, 5, 1e3, -12, 2023-01-02T03:04:05.0000006Z]` + "\n"
+	if string(output) != excepted {
+		t.Fatalf("TOMLMarshal result not correct: %s", string(output))
+	}
+}
+
+func TestTOMLMarshal_Error(t *testing.T) {
+	var nilObject geko.Object
+
+	duplicated := geko.NewPairs[string, any]()
+	duplicated.Add("a", 1)
+	duplicated.Add("a", 2)
+
+	for _, v := range []any{
+		1,
+		nilObject,
+		duplicated,
+		map[string]any{"a": []any{duplicated}, "b": 1},
+		map[string]any{"a": nil},
+		map[string]any{"a": []any{nil}},
+		map[string]any{"a": map[string]any{"b": nil}, "c": 1},
+		map[string]any{"a": map[string]any{"b": nil}},
+		map[string]any{"a": []any{map[string]any{"b": nil}}},
+		map[string]any{"a": json.Number("123456789012345678901")},
+		map[string]any{"a": uint64(math.MaxUint64)},
+		map[string]any{"a": struct{}{}},
+	} {
+		if output, err := geko.TOMLMarshal(v); err == nil {
+			t.Fatalf("TOMLMarshal should fail: %s", string(output))
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/7sDream/geko"
+)
+
+const tomlTestDocument = "\xEF\xBB\xBF" + `# This is a TOML document
+title = "TOML \"Example\"\u00e9\U0001F600\b\t\n\f\r\\"
+"quoted key" = 'C:\path'
+site."google.com" = true
+multi = """
+Roses \
+   are red
+"" """"
+crlf = """` + "\r\na\r\nb" + `"""
+lit = '''
+raw \n ''''
+ints = [ +99, -17, 0, 1_000, 0xDEAD_beef, 0o755, 0b1101 ] # comment
+floats = [1.0, 3.1415, -0.01, 5e+22, 1e06, -2E-2, 6.626e-34, 224_617.445_991]
+dates = [
+	1979-05-27T07:32:00Z,
+	1979-05-27 00:32:00.999999-07:00, # space delimiter
+	1979-05-27t07:32:00,
+	1979-05-27,
+	07:32:00.5,
+]
+nested = [ [1, 2], ["a", 'b'], [ { x = 1 }, {} ], ]
+point = { x = 1, y.z = 2, "w" = {} }
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[servers]
+beta.ip = "10.0.0.2"
+
+[ fruit . apple ]
+color.name = "red"
+
+[fruit.apple.color.texture]
+smooth = true
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+
+[[ products ]]
+name = "Nail"
+[products.meta]
+color = "gray"
+[[products.variants]]
+size = 1
+`
+
+func TestTOMLUnmarshal(t *testing.T) {
+	doc := []byte(tomlTestDocument)
+	doc = append(doc[:len(doc)-1], "\r\n"...)
+
+	v, err := geko.TOMLUnmarshal(doc)
+	if err != nil {
+		t.Fatalf("TOMLUnmarshal with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(v)
+	excepted := `{"title":"TOML \"Example\"é😀\b\t\n\f\r\\","quoted key":"C:\\path","site":{"google.com":true},` +
+		`"multi":"Roses are red\n\"\" \"","crlf":"a\nb","lit":"raw \\n '",` +
+		`"ints":[99,-17,0,1000,3735928559,493,13],` +
+		`"floats":[1,3.1415,-0.01,5e+22,1000000,-0.02,6.626e-34,224617.445991],` +
+		`"dates":["1979-05-27T07:32:00Z","1979-05-27T00:32:00.999999-07:00","1979-05-27T07:32:00",` +
+		`"1979-05-27","07:32:00.5"],` +
+		`"nested":[[1,2],["a","b"],[{"x":1},{}]],"point":{"x":1,"y":{"z":2},"w":{}},` +
+		`"servers":{"alpha":{"ip":"10.0.0.1"},"beta":{"ip":"10.0.0.2"}},` +
+		`"fruit":{"apple":{"color":{"name":"red","texture":{"smooth":true}}}},` +
+		`"products":[{"name":"Hammer"},{},{"name":"Nail","meta":{"color":"gray"},"variants":[{"size":1}]}]}`
+	if string(output) != excepted {
+		t.Fatalf("TOMLUnmarshal result not correct: %s", string(output))
+	}
+
+	object := v.(geko.Object)
+	if _, ok := object.GetOrZeroValue("ints").(geko.Array).Get(0).(int64); !ok {
+		t.Fatalf("TOML integer should be int64")
+	}
+}
+
+func TestTOMLUnmarshal_DottedKeys(t *testing.T) {
+	v, err := geko.TOMLUnmarshal([]byte("a.b = 1\na.c.d = false\n'a'.\"e\" = \"\"\"x\\  \n  y\"\"\"\n"))
+	if err != nil {
+		t.Fatalf("TOMLUnmarshal with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(v)
+	if string(output) != `{"a":{"b":1,"c":{"d":false},"e":"xy"}}` {
+		t.Fatalf("TOMLUnmarshal result not correct: %s", string(output))
+	}
+}
+
+func TestTOMLUnmarshal_DateTime(t *testing.T) {
+	v, err := geko.TOMLUnmarshal([]byte(`a = [1979-05-27T07:32:00-08:00, 1979-05-27T07:32:00, 1979-05-27, 07:32:00]`))
+	if err != nil {
+		t.Fatalf("TOMLUnmarshal with error: %s", err.Error())
+	}
+
+	items := v.(geko.Object).GetOrZeroValue("a").(geko.Array).List
+
+	offset, ok := items[0].(time.Time)
+	if !ok || !offset.Equal(time.Date(1979, 5, 27, 15, 32, 0, 0, time.UTC)) {
+		t.Fatalf("Offset date-time not correct: %#v", items[0])
+	}
+
+	local, ok := items[1].(geko.TOMLLocalDateTime)
+	if !ok || !time.Time(local).Equal(time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)) {
+		t.Fatalf("Local date-time not correct: %#v", items[1])
+	}
+
+	date, ok := items[2].(geko.TOMLLocalDate)
+	if !ok || !time.Time(date).Equal(time.Date(1979, 5, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Local date not correct: %#v", items[2])
+	}
+
+	clock, ok := items[3].(geko.TOMLLocalTime)
+	if !ok || clock.String() != "07:32:00" {
+		t.Fatalf("Local time not correct: %#v", items[3])
+	}
+}
+
+func TestTOMLUnmarshal_SpecialFloat(t *testing.T) {
+	v, err := geko.TOMLUnmarshal([]byte(`a = [inf, +inf, -inf, nan, +nan, -nan]`))
+	if err != nil {
+		t.Fatalf("TOMLUnmarshal with error: %s", err.Error())
+	}
+
+	items := v.(geko.Object).GetOrZeroValue("a").(geko.Array).List
+	if !math.IsInf(items[0].(float64), 1) || !math.IsInf(items[1].(float64), 1) ||
+		!math.IsInf(items[2].(float64), -1) || !math.IsNaN(items[3].(float64)) ||
+		!math.IsNaN(items[4].(float64)) || !math.IsNaN(items[5].(float64)) {
+		t.Fatalf("Special floats not correct: %#v", items)
+	}
+}
+
+func TestTOMLUnmarshal_Invalid(t *testing.T) {
+	for _, doc := range []string{
+		// encoding and structure
+		"a = 1\n\xff", "a = 1 # \x01", "# \r x", "\r", "a = 1 b = 2",
+		"[a", "[[a]", "[[a] ]", "[a] x", "[]", "a", "a = ", "a = true1", "=1", "a.=1",
+		// redefinition
+		"a = 1\n[a]", "[a]\n[a]", "[a.b]\n[a]\n[a]", "a = 1\n[a.b]", "a = {}\n[a.b]", "a = { b.c = 1 }\n[a.b]",
+		"a = []\n[[a]]", "[a]\n[[a]]", "a = [{}]\n[a.b]", "a = 1\na = 2", "a = 1\na.b = 2",
+		"[a.b]\n[a]\nb.c = 1", "a = {b = 1}\na.c = 2", "a = {b.c = 1}\na.b.d = 2", "a.b.c = 1\n[a.b]",
+		"[[a]]\n[a]", "a = {b = 1, b = 2}", `"a = 1`,
+		// strings
+		`a = "abc`, "a = \"a\nb\"", "a = \"\x01\"", `a = "\q"`, `a = "\u12"`, `a = "\uD800"`, `a = "\u`,
+		`a = 'abc`, "a = '\x01'", `a = """abc`, `a = """a""""""`, "a = \"\"\"\x01\"\"\"", `a = """\q"""`,
+		"a = '''\x01'''",
+		// arrays and inline tables
+		"a = [1 2]", "a = [1,,]", "a = [# \x01\n]", "a = [1 # \x01\n]", "a = [", "a = [1",
+		"a = {b = 1,}", "a = {b = 1 c = 2}", "a = {b = }", "a = {\nb = 1}",
+		// numbers
+		"a = 01", "a = 1__0", "a = _1", "a = 1_", "a = 0x", "a = 0xG", "a = 0b2", "a = 0o8", "a = 1.", "a = .1",
+		"a = 1e", "a = 1.e5", "a = 1e_5", "a = +0x1", "a = 9223372036854775808", "a = 1e999", "a = inf1",
+		"a = 0x8000000000000000",
+		// date-times
+		"a = 1979-05-27T", "a = 1979-05-27X07:32:00", "a = 07:32", "a = 07:32:00.", "a = 07:32:00Z",
+		"a = 1979-05-27T07:32:00+0700", "a = 1979-13-27", "a = 25:00:00", "a = 1979-05-27T07:32:00Zx",
+	} {
+		v, err := geko.TOMLUnmarshal([]byte(doc))
+		if err == nil {
+			t.Fatalf("TOMLUnmarshal should fail on %q: %#v", doc, v)
+		}
+
+		var tomlErr *geko.TOMLError
+		if !errors.As(err, &tomlErr) {
+			t.Fatalf("TOMLUnmarshal should return TOMLError: %#v", err)
+		}
+	}
+}
+
+func TestTOMLUnmarshal_MaxDepth(t *testing.T) {
+	for _, doc := range []string{
+		"a = " + strings.Repeat("[", 10000) + strings.Repeat("]", 10000),
+		"a = " + strings.Repeat("{b = ", 10000) + "1" + strings.Repeat("}", 10000),
+		"a = " + strings.Repeat("[", 9000) + "{" + strings.Repeat("b.", 999) + "c = 1}" + strings.Repeat("]", 9000),
+	} {
+		if _, err := geko.TOMLUnmarshal([]byte(doc)); err != nil {
+			t.Fatalf("TOMLUnmarshal with error: %s", err.Error())
+		}
+	}
+
+	for _, doc := range []string{
+		"a = " + strings.Repeat("[", 20_000_000) + strings.Repeat("]", 20_000_000),
+		"a = " + strings.Repeat("{b = ", 10001) + "1" + strings.Repeat("}", 10001),
+		"a = {" + strings.Repeat("b.", 10000) + "c = 1}",
+	} {
+		_, err := geko.TOMLUnmarshal([]byte(doc))
+		if err == nil || !strings.Contains(err.Error(), "exceeded max depth 10000") {
+			t.Fatalf("TOMLUnmarshal should fail on deep data: %#v", err)
+		}
+	}
+}
+
+func TestTOMLError(t *testing.T) {
+	_, err := geko.TOMLUnmarshal([]byte("a = 1\nb = 2\na = 3"))
+
+	excepted := `geko: invalid toml: key "a" is already defined at line 3, column 1`
+	if err == nil || err.Error() != excepted {
+		t.Fatalf("TOMLError message not correct: %#v", err)
+	}
+}
+
+func TestTOMLLocalDateTime_MarshalText(t *testing.T) {
+	v, _ := geko.TOMLUnmarshal([]byte(`a = [1979-05-27T07:32:00.123, 1979-05-27, 07:32:00]`))
+
+	output, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal with error: %s", err.Error())
+	}
+
+	if string(output) != `{"a":["1979-05-27T07:32:00.123","1979-05-27","07:32:00"]}` {
+		t.Fatalf("Marshal result not correct: %s", string(output))
+	}
+}
@@ -0,0 +1,113 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDeleteValue can be returned by a [TransformFunc] to delete the value from
+// its parent container. It's not an error, [Transform] will not return it.
+var ErrDeleteValue = errors.New("geko: delete value")
+
+// TransformFunc is the type of the function called by [Transform] for each
+// value. The returned value replaces the value passed in.
+//
+// The path is the same as [WalkFunc]. Return [ErrDeleteValue] to delete the
+// value, other errors stop the transform.
+type TransformFunc func(path []any, value any) (any, error)
+
+// TransformError is returned by [Transform] when a [TransformFunc] fails, or
+// the returned value can't be stored in the container.
+type TransformError struct {
+	// Path is the location of value from root, see [WalkFunc].
+	Path []any
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements [error] interface.
+func (e *TransformError) Error() string {
+	return fmt.Sprintf("geko: transform value at %v: %s", e.Path, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *TransformError) Unwrap() error {
+	return e.Err
+}
+
+// Transform rewrites root and all values inside it bottom-up by fn: children
+// of [Map], [Pairs] and [List] are transformed before the container itself,
+// and results are stored back into the container at the same key or index,
+// so order and duplicated keys are kept. It returns the transformed root.
+//
+// Containers are modified in place, not copied. Values stored in a container
+// whose value type is not any must have that type, or be nil for zero value.
+// A frozen container, see [Map.Freeze], can't be transformed, a
+// [TransformError] wraps [ErrFrozen] is returned.
+//
+// If fn returns [ErrDeleteValue], the value is deleted from its container, and
+// nil is returned if it's the root. If fn fails, the transform stops and a
+// [TransformError] is returned, values already transformed are kept.
+func Transform(root any, fn TransformFunc) (any, error) {
+	v, err := transformValue(make([]any, 0, 8), root, fn)
+	if err == ErrDeleteValue {
+		return nil, nil
+	}
+	return v, err
+}
+
+func transformValue(path []any, value any, fn TransformFunc) (any, error) {
+	if c, ok := value.(container); ok {
+		if c.Frozen() {
+			return nil, &TransformError{Path: copyPath(path), Err: ErrFrozen}
+		}
+
+		err := c.transformChildren(func(key, child any) (any, bool, error) {
+			v, err := transformValue(append(path, key), child, fn)
+			if err == ErrDeleteValue {
+				return nil, true, nil
+			}
+			return v, false, err
+		})
+
+		// path of errors from fn is complete, only assign error needs to be completed here
+		if assignErr, ok := err.(*assignError); ok {
+			return nil, &TransformError{Path: copyPath(path, assignErr.key), Err: assignErr}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	v, err := fn(path, value)
+	if err != nil && err != ErrDeleteValue {
+		return nil, &TransformError{Path: copyPath(path), Err: err}
+	}
+
+	return v, err
+}
+
+func copyPath(path []any, extra ...any) []any {
+	result := make([]any, 0, len(path)+len(extra))
+	result = append(result, path...)
+	return append(result, extra...)
+}
+
+// assignable converts v into type T, nil is converted into zero value.
+func assignable[T any](v any) (T, bool) {
+	value, ok := v.(T)
+	if !ok && v == nil {
+		return value, true
+	}
+	return value, ok
+}
+
+// assignError is reported when a value can't be stored in a container.
+type assignError struct {
+	key       any
+	value     any
+	container any
+}
+
+func (e *assignError) Error() string {
+	return fmt.Sprintf("geko: can't store %T in %T at key %v", e.value, e.container, e.key)
+}
@@ -0,0 +1,184 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestTransform_ReplaceNumbers(t *testing.T) {
+	v, _ := geko.JSONUnmarshal(
+		[]byte(`{"a": [1, {"b": 2}], "c": "s", "a": 3, "d": {"e": [4.5]}}`),
+		geko.UseNumber(true),
+	)
+	v.(geko.ObjectItems).SetValueByIndex(3, geko.NewMap[string, any]())
+	v.(geko.ObjectItems).GetValueByIndex(3).(geko.Object).Set("e", geko.NewListFrom([]any{json.Number("4.5")}))
+
+	var visits []string
+	result, err := geko.Transform(v, func(path []any, value any) (any, error) {
+		visits = append(visits, fmt.Sprint(path))
+		if n, ok := value.(json.Number); ok {
+			return n.Float64()
+		}
+		return value, nil
+	})
+	if err != nil {
+		t.Fatalf("Transform with error: %s", err.Error())
+	}
+
+	if result != v {
+		t.Fatalf("Transform should modify containers in place")
+	}
+
+	excepted := []string{"[a 0]", "[a 1 b]", "[a 1]", "[a]", "[c]", "[a]", "[d e 0]", "[d e]", "[d]", "[]"}
+	if !reflect.DeepEqual(visits, excepted) {
+		t.Fatalf("Transform visit order not correct: %#v", visits)
+	}
+
+	found := false
+	_ = geko.Walk(result, func(_ []any, value any) (bool, error) {
+		_, isNumber := value.(json.Number)
+		found = found || isNumber
+		return true, nil
+	})
+	if found {
+		t.Fatalf("All numbers should be replaced")
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"a":[1,{"b":2}],"c":"s","a":3,"d":{"e":[4.5]}}` {
+		t.Fatalf("Transform result not correct: %s", string(output))
+	}
+}
+
+func TestTransform_Delete(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(
+		`{"secret": 1, "a": [{"secret": 2, "b": 3}, "secret", 4], "secret": {"x": 1}, "c": {"secret": [], "d": 5}}`,
+	))
+	c := geko.NewMap[string, any]()
+	c.Set("secret", 1)
+	c.Set("d", 5)
+	v.(geko.ObjectItems).SetValueByIndex(3, c)
+
+	result, err := geko.Transform(v, func(path []any, value any) (any, error) {
+		if value == "secret" || (len(path) > 0 && path[len(path)-1] == "secret") {
+			return nil, geko.ErrDeleteValue
+		}
+		return value, nil
+	})
+	if err != nil {
+		t.Fatalf("Transform with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(result)
+	if string(output) != `{"a":[{"b":3},4],"c":{"d":5}}` {
+		t.Fatalf("Transform result not correct: %s", string(output))
+	}
+
+	result, err = geko.Transform(v, func(_ []any, _ any) (any, error) {
+		return nil, geko.ErrDeleteValue
+	})
+	if result != nil || err != nil {
+		t.Fatalf("Transform delete root should return nil: %#v, %#v", result, err)
+	}
+}
+
+func TestTransform_Error(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"a": 1, "b": [true, {"c": null}], "d": 2}`), geko.UseObject())
+
+	bad := errors.New("bad")
+	_, err := geko.Transform(v, func(_ []any, value any) (any, error) {
+		if value == nil {
+			return nil, bad
+		}
+		if value == 1.0 {
+			return nil, geko.ErrDeleteValue
+		}
+		return value, nil
+	})
+
+	var transformErr *geko.TransformError
+	if !errors.As(err, &transformErr) || !errors.Is(err, bad) ||
+		!reflect.DeepEqual(transformErr.Path, []any{"b", 1, "c"}) {
+		t.Fatalf("Transform should fail with path: %#v", err)
+	}
+
+	if err.Error() != "geko: transform value at [b 1 c]: bad" {
+		t.Fatalf("TransformError message not correct: %s", err.Error())
+	}
+
+	// transformed values are kept, containers are consistent
+	object := v.(geko.Object)
+	if !reflect.DeepEqual(object.Keys(), []string{"b", "d"}) || object.Len() != 2 {
+		t.Fatalf("Map should be consistent after error: %#v", object.Keys())
+	}
+}
+
+func TestTransform_TypedContainer(t *testing.T) {
+	m := geko.NewMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+
+	ps := geko.NewPairs[string, int]()
+	ps.Add("a", 1)
+	ps.Add("b", 2)
+	ps.Add("b", 3)
+
+	l := geko.NewListFrom([]int{1, 2, 3})
+
+	for _, root := range []any{m, ps, l} {
+		_, err := geko.Transform(root, func(_ []any, value any) (any, error) {
+			switch value {
+			case 1:
+				return nil, geko.ErrDeleteValue
+			case 2:
+				return nil, nil
+			case 3:
+				return "3", nil
+			}
+			return value, nil
+		})
+
+		var transformErr *geko.TransformError
+		if !errors.As(err, &transformErr) || len(transformErr.Path) != 1 {
+			t.Fatalf("Transform should fail when store a value with wrong type: %#v", err)
+		}
+
+		output, _ := json.Marshal(root)
+		if string(output) != `{"b":0,"c":3}` && string(output) != `{"b":0,"b":3}` && string(output) != `[0,3]` {
+			t.Fatalf("Transform result not correct: %s", string(output))
+		}
+	}
+
+	_, err := geko.Transform(m, func(_ []any, _ any) (any, error) {
+		return "x", nil
+	})
+	if err.Error() != "geko: transform value at [b]: geko: can't store string in *geko.Map[string,int] at key b" {
+		t.Fatalf("TransformError message not correct: %s", err.Error())
+	}
+
+	if m.Len() != 2 || m.GetOrZeroValue("c") != 3 || m.Has("a") {
+		t.Fatalf("Map should be consistent after error: %#v", m.Keys())
+	}
+}
+
+func TestTransform_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	count := 0
+	_, err := geko.Transform(geko.NewListFrom([]any{m, ps, l}), func(_ []any, value any) (any, error) {
+		count++
+		return value, nil
+	})
+
+	if err != nil || count != 4 {
+		t.Fatalf("Transform with nil containers not correct: %d, %#v", count, err)
+	}
+}
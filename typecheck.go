@@ -15,3 +15,18 @@ func isString[T any]() bool {
 
 	return checkerTyp == reflect.TypeOf("")
 }
+
+// isByteSlice reports whether std lib encodes a []T as a base64 string, which
+// is when T is a byte type and *T has no marshal method.
+func isByteSlice[T any]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return t.Kind() == reflect.Uint8 && !isMarshaler(reflect.PointerTo(t))
+}
+
+// marshalByAddress reports whether items of a []T should be encoded by their
+// address, like std lib does for addressable values, because only *T has the
+// marshal method.
+func marshalByAddress[T any]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return t.Kind() != reflect.Interface && !isMarshaler(t) && isMarshaler(reflect.PointerTo(t))
+}
@@ -0,0 +1,96 @@
+package geko
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidQuery means a query string given to [ParseQuery] is malformed.
+var ErrInvalidQuery = errors.New("geko: invalid query")
+
+// EncodeQuery encodes ps into URL query string form, like "k1=v1&k2=v2&k1=v3",
+// in order of ps. Duplicated keys are all kept, at their own position.
+//
+// Keys and values are escaped like [url.QueryEscape] does, so the result is
+// the same as [url.Values.Encode], except pairs are not sorted by key. An
+// empty or nil ps results an empty string.
+func EncodeQuery(ps *Pairs[string, string]) string {
+	var sb strings.Builder
+
+	for i := 0; ps != nil && i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+		if i > 0 {
+			_ = sb.WriteByte('&')
+		}
+		_, _ = sb.WriteString(url.QueryEscape(pair.Key))
+		_ = sb.WriteByte('=')
+		_, _ = sb.WriteString(url.QueryEscape(pair.Value))
+	}
+
+	return sb.String()
+}
+
+// ParseQuery parses a URL query string, or a form body, into pairs, like
+// [url.ParseQuery]. But pairs are kept in order of appearance, including all
+// values of duplicated keys at their own position.
+//
+// Keys and values are unescaped like [url.QueryUnescape] does, so "+" is a
+// space. A key without "=" has an empty value, and empty segments, like the
+// one in "a=1&&b=2", are skipped. It's the inverse of [EncodeQuery], so
+// EncodeQuery(ParseQuery(s)) only differs from s in escaping, and "=" added to
+// keys without it.
+//
+// If a segment contains a malformed escape or a semicolon, which is rejected
+// like [url.ParseQuery] does, an error wrapping [ErrInvalidQuery] is returned.
+func ParseQuery(query string) (*Pairs[string, string], error) {
+	ps := NewPairs[string, string]()
+
+	for _, segment := range strings.Split(query, "&") {
+		if segment == "" {
+			continue
+		}
+
+		if strings.Contains(segment, ";") {
+			return nil, fmt.Errorf("%w segment %q: semicolon separator", ErrInvalidQuery, segment)
+		}
+
+		rawKey, rawValue, _ := strings.Cut(segment, "=")
+
+		key, err := url.QueryUnescape(rawKey)
+		if err == nil {
+			var value string
+			if value, err = url.QueryUnescape(rawValue); err == nil {
+				ps.Add(key, value)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w segment %q: %s", ErrInvalidQuery, segment, err.Error())
+		}
+	}
+
+	return ps, nil
+}
+
+// ToURLValues converts the pairs into a [url.Values]. Keys and values which
+// are not strings are formatted by [fmt.Sprint].
+//
+// Values of the same key are kept in order, but order between different keys
+// is lost, because [url.Values] is a map. Use [EncodeQuery] if it matters.
+//
+// A nil pairs results a nil [url.Values].
+func (ps *Pairs[K, V]) ToURLValues() url.Values {
+	if ps == nil {
+		return nil
+	}
+
+	values := make(url.Values, ps.Len())
+	for i := 0; i < ps.Len(); i++ {
+		pair := ps.GetByIndex(i)
+		values.Add(stdKey(pair.Key), stdKey(pair.Value))
+	}
+
+	return values
+}
@@ -0,0 +1,139 @@
+package geko_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestEncodeQuery(t *testing.T) {
+	ps := geko.NewPairs[string, string]()
+	ps.Add("b", "1")
+	ps.Add("a b", "x&y=z")
+	ps.Add("b", "")
+	ps.Add("", "?")
+	ps.Add("键", "值/+")
+	ps.Add("b", "3")
+
+	excepted := "b=1&a+b=x%26y%3Dz&b=&=%3F&%E9%94%AE=%E5%80%BC%2F%2B&b=3"
+	if output := geko.EncodeQuery(ps); output != excepted {
+		t.Fatalf("EncodeQuery result not correct: %s", output)
+	}
+
+	values, err := url.ParseQuery(excepted)
+	if err != nil {
+		t.Fatalf("EncodeQuery result can't be parsed: %s", err.Error())
+	}
+
+	if !reflect.DeepEqual(values, ps.ToURLValues()) {
+		t.Fatalf("EncodeQuery result not match ToURLValues: %#v", values)
+	}
+}
+
+func TestEncodeQuery_Empty(t *testing.T) {
+	if output := geko.EncodeQuery(nil); output != "" {
+		t.Fatalf("EncodeQuery of nil should be empty: %s", output)
+	}
+
+	if output := geko.EncodeQuery(geko.NewPairs[string, string]()); output != "" {
+		t.Fatalf("EncodeQuery of empty pairs should be empty: %s", output)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	ps, err := geko.ParseQuery("b=1&a+b=x%26y%3Dz&b=&=%3F&%E9%94%AE=%E5%80%BC%2F%2B&&c&b=3")
+	if err != nil {
+		t.Fatalf("ParseQuery with error: %s", err.Error())
+	}
+
+	excepted := geko.NewPairsOf(
+		geko.P("b", "1"), geko.P("a b", "x&y=z"), geko.P("b", ""), geko.P("", "?"),
+		geko.P("键", "值/+"), geko.P("c", ""), geko.P("b", "3"),
+	)
+	if !reflect.DeepEqual(ps.List, excepted.List) {
+		t.Fatalf("ParseQuery result not correct: %#v", ps.List)
+	}
+
+	ps, err = geko.ParseQuery("")
+	if err != nil || ps.Len() != 0 {
+		t.Fatalf("ParseQuery of empty string should be empty: %#v, %#v", ps, err)
+	}
+}
+
+func TestParseQuery_RoundTrip(t *testing.T) {
+	for _, c := range []struct {
+		query    string
+		excepted string
+	}{
+		{"z=1&a=2&z=3&m=4", "z=1&a=2&z=3&m=4"},
+		{"a=%41&b=%2b&c=+x", "a=A&b=%2B&c=+x"},
+		{"key&empty=&=v", "key=&empty=&=v"},
+		{"&a=1&&b=2&", "a=1&b=2"},
+		{"a=b=c", "a=b%3Dc"},
+		{"oauth_token=x%2Fy&oauth_nonce=%20n&oauth_token=z", "oauth_token=x%2Fy&oauth_nonce=+n&oauth_token=z"},
+	} {
+		ps, err := geko.ParseQuery(c.query)
+		if err != nil {
+			t.Fatalf("ParseQuery %q with error: %s", c.query, err.Error())
+		}
+
+		if output := geko.EncodeQuery(ps); output != c.excepted {
+			t.Fatalf("ParseQuery %q round trip result not correct: %s", c.query, output)
+		}
+
+		values, _ := url.ParseQuery(c.query)
+		if !reflect.DeepEqual(values, ps.ToURLValues()) {
+			t.Fatalf("ParseQuery %q result not match url.ParseQuery: %#v", c.query, values)
+		}
+	}
+}
+
+func TestParseQuery_Error(t *testing.T) {
+	for _, c := range []struct {
+		query    string
+		excepted string
+	}{
+		{"a=1&b%zz=2", `geko: invalid query segment "b%zz=2": invalid URL escape "%zz"`},
+		{"a=1&b=%4", `geko: invalid query segment "b=%4": invalid URL escape "%4"`},
+		{"a=1;b=2", `geko: invalid query segment "a=1;b=2": semicolon separator`},
+	} {
+		ps, err := geko.ParseQuery(c.query)
+		if err == nil {
+			t.Fatalf("ParseQuery %q should fail: %#v", c.query, ps)
+		}
+
+		if !errors.Is(err, geko.ErrInvalidQuery) || err.Error() != c.excepted {
+			t.Fatalf("ParseQuery %q error not correct: %s", c.query, err.Error())
+		}
+	}
+}
+
+func TestPairs_ToURLValues(t *testing.T) {
+	ps := geko.NewPairs[string, any]()
+	ps.Add("b", "1")
+	ps.Add("a", 2)
+	ps.Add("b", true)
+	ps.Add("c", nil)
+
+	values := ps.ToURLValues()
+	excepted := url.Values{
+		"a": {"2"},
+		"b": {"1", "true"},
+		"c": {"<nil>"},
+	}
+	if !reflect.DeepEqual(values, excepted) {
+		t.Fatalf("ToURLValues result not correct: %#v", values)
+	}
+
+	if output := values.Encode(); output != "a=2&b=1&b=true&c=%3Cnil%3E" {
+		t.Fatalf("ToURLValues encode result not correct: %s", output)
+	}
+
+	var nilPairs *geko.Pairs[int, int]
+	if values = nilPairs.ToURLValues(); values != nil {
+		t.Fatalf("ToURLValues of nil pairs should be nil: %#v", values)
+	}
+}
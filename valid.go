@@ -0,0 +1,179 @@
+package geko
+
+import (
+	"encoding/json"
+)
+
+// Issue is a problem found by [ValidReport].
+type Issue struct {
+	// Offset is the input offset where the issue is found.
+	Offset int64
+	// Err describes the issue. It's a [*DuplicateKeyError] for a duplicated
+	// key. Other errors, like a [*SyntaxError], stop the validation, so they
+	// are always the last issue.
+	Err error
+}
+
+// Valid reports whether data is a valid JSON value under provided option, by
+// walking through its tokens without building any container. It returns the
+// first problem found, or nil.
+//
+// Options about structure are honored, like [MaxDepth], [MaxItems],
+// [MaxBytes], [AllowTrailingData], [AllowComments], [AllowTrailingCommas] and
+// [KeyTransform]. Duplicated keys are reported as [*DuplicateKeyError] when
+// [DisallowDuplicateKeys] is applied. Options about value conversion, like
+// [NumberFunc], have no effect.
+func Valid(data []byte, option ...DecodeOption) error {
+	opts := CreateDecodeOptions(option...)
+
+	// fast path, std lib is enough when there is no structure option, walking
+	// through tokens is only needed to locate the problem
+	if opts.plain() && json.Valid(data) {
+		return nil
+	}
+
+	d := acquireDecoder(data, opts)
+	defer releaseDecoder(d)
+
+	var onDuplicate func(err *DuplicateKeyError) error
+	if d.opts.disallowDuplicateKeys {
+		onDuplicate = func(err *DuplicateKeyError) error {
+			return err
+		}
+	}
+
+	d.reset()
+
+	if err := d.walk(onDuplicate); err != nil {
+		return d.unexpectedEOF(err)
+	}
+
+	return d.end()
+}
+
+// ValidReport likes [Valid], but collects all duplicated keys in data in one
+// pass, regardless of the [DisallowDuplicateKeys] option. It returns nil if
+// there is no issue.
+func ValidReport(data []byte, option ...DecodeOption) []Issue {
+	d := acquireDecoder(data, CreateDecodeOptions(option...))
+	defer releaseDecoder(d)
+
+	var issues []Issue
+	onDuplicate := func(err *DuplicateKeyError) error {
+		issues = append(issues, Issue{Offset: err.Offset, Err: err})
+		return nil
+	}
+
+	d.reset()
+
+	err := d.walk(onDuplicate)
+	if err != nil {
+		err = d.unexpectedEOF(err)
+	} else {
+		err = d.end()
+	}
+
+	if err != nil {
+		issues = append(issues, Issue{Offset: d.decoder.InputOffset(), Err: err})
+	}
+
+	return issues
+}
+
+// plain reports whether there is no option affects structure validation.
+func (opts *DecodeOptions) plain() bool {
+	return !opts.disallowDuplicateKeys && opts.maxDepth <= 0 && opts.maxItems <= 0 && opts.maxBytes <= 0 &&
+		!opts.allowTrailingData && !opts.allowComments && !opts.allowTrailingCommas
+}
+
+type walkFrame struct {
+	object    bool
+	expectKey bool
+	index     int
+	seen      map[string]struct{}
+}
+
+// walk reads tokens of one JSON value, checks limits and duplicated keys, but
+// do not build any value. If onDuplicate is nil, duplicated keys are not
+// checked.
+func (d *Decoder) walk(onDuplicate func(err *DuplicateKeyError) error) error {
+	var stack []walkFrame
+
+	for {
+		token, err := d.token()
+		if err != nil {
+			return err
+		}
+
+		var top *walkFrame
+		if len(stack) > 0 {
+			top = &stack[len(stack)-1]
+		}
+
+		delim, isDelim := token.(json.Delim)
+
+		switch {
+		case isDelim && (delim == '}' || delim == ']'):
+			stack = stack[:len(stack)-1]
+			d.leave()
+		case top != nil && top.expectKey:
+			key, _ := token.(string) // std decoder makes sure it's a string
+			if err = d.walkKey(top, key, onDuplicate); err != nil {
+				return err
+			}
+			continue
+		default:
+			if top != nil && !top.object { // array element, object member is counted by its key
+				if err = d.addItem(); err != nil {
+					return err
+				}
+			}
+
+			if isDelim { // start of object or array
+				if err = d.enter(); err != nil {
+					return err
+				}
+
+				frame := walkFrame{object: delim == '{', expectKey: delim == '{'}
+				if frame.object && onDuplicate != nil {
+					frame.seen = make(map[string]struct{})
+				}
+				stack = append(stack, frame)
+				continue
+			}
+		}
+
+		// a value is finished
+		if len(stack) == 0 {
+			return nil
+		}
+
+		top = &stack[len(stack)-1]
+		top.expectKey = top.object
+	}
+}
+
+func (d *Decoder) walkKey(frame *walkFrame, key string, onDuplicate func(err *DuplicateKeyError) error) error {
+	if d.opts.keyTransform != nil {
+		key = d.opts.keyTransform(key)
+	}
+
+	if err := d.addItem(); err != nil {
+		return err
+	}
+
+	if frame.seen != nil {
+		if _, dup := frame.seen[key]; dup {
+			err := onDuplicate(&DuplicateKeyError{Key: key, Index: frame.index, Offset: d.decoder.InputOffset()})
+			if err != nil {
+				return err
+			}
+		}
+		frame.seen[key] = struct{}{}
+	}
+
+	frame.index++
+	frame.expectKey = false
+
+	return nil
+}
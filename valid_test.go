@@ -0,0 +1,177 @@
+package geko_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func TestValid(t *testing.T) {
+	for _, data := range []string{
+		`1`, ` "s" `, `null`, `[]`, `{}`, `[1, [2, {}], {"a": [true]}]`,
+		`{"a": {"b": [1, {"c": null}]}, "d": "e", "a": 1}`,
+	} {
+		if err := geko.Valid([]byte(data)); err != nil {
+			t.Fatalf("Valid %s should pass, got %s", data, err.Error())
+		}
+	}
+
+	var syntaxErr *geko.SyntaxError
+	for _, data := range []string{``, ` `, `[1, 2`, `{"a": `, `[1] 2`, `{"a": 1}}`} {
+		if err := geko.Valid([]byte(data)); !errors.As(err, &syntaxErr) {
+			t.Fatalf("Valid %q should fail with syntax error, got %#v", data, err)
+		}
+	}
+
+	for _, data := range []string{`[1,]`, `{"a" 1}`, `{1: 2}`, `[1 2]`, `tru`} {
+		if err := geko.Valid([]byte(data)); err == nil {
+			t.Fatalf("Valid %q should fail", data)
+		}
+	}
+}
+
+func TestValid_Options(t *testing.T) {
+	if err := geko.Valid([]byte(`[[1]]`), geko.MaxDepth(1)); err == nil {
+		t.Fatalf("Valid should fail when exceeds max depth")
+	}
+
+	var limitErr *geko.LimitExceededError
+	if err := geko.Valid([]byte(`[1, 2, 3]`), geko.MaxItems(2)); !errors.As(err, &limitErr) {
+		t.Fatalf("Valid should fail when exceeds max items, got %#v", err)
+	}
+
+	if err := geko.Valid([]byte(`{"a": 1, "b": 2, "c": 3}`), geko.MaxItems(2)); !errors.As(err, &limitErr) {
+		t.Fatalf("Valid should fail when exceeds max items, got %#v", err)
+	}
+
+	if err := geko.Valid([]byte(`[1, 2, 3]`), geko.MaxBytes(5)); !errors.As(err, &limitErr) {
+		t.Fatalf("Valid should fail when exceeds max bytes, got %#v", err)
+	}
+
+	jsonc := []byte("[1, /* c */ 2,] // c")
+	if err := geko.Valid(jsonc, geko.AllowComments(true), geko.AllowTrailingCommas(true)); err != nil {
+		t.Fatalf("Valid JSONC with error: %s", err.Error())
+	}
+
+	if err := geko.Valid([]byte(`[1] 2`), geko.AllowTrailingData(true)); err != nil {
+		t.Fatalf("Valid with trailing data allowed error: %s", err.Error())
+	}
+}
+
+func TestValid_LimitsSameAsDecode(t *testing.T) {
+	for _, data := range []string{
+		`{"a": 1, "b": 2}`, `[1, 2]`, `{"a": [1, 2], "b": {"c": 3}}`, `[{"a": 1}, [2, 3], 4]`,
+	} {
+		for limit := 1; limit <= len(data)+1; limit++ {
+			for _, option := range []geko.DecodeOption{geko.MaxItems(limit), geko.MaxBytes(int64(limit))} {
+				_, errDecode := geko.JSONUnmarshal([]byte(data), option)
+				err := geko.Valid([]byte(data), option)
+				issues := geko.ValidReport([]byte(data), option)
+
+				if (err == nil) != (errDecode == nil) || (err == nil) != (issues == nil) {
+					t.Fatalf("Valid %s with limit %d not same as decode: %v, %v, %v",
+						data, limit, err, issues, errDecode)
+				}
+
+				if err != nil && (err.Error() != errDecode.Error() || issues[0].Err.Error() != err.Error()) {
+					t.Fatalf("Valid %s with limit %d error not same as decode: %s, %s", data, limit, err, errDecode)
+				}
+			}
+		}
+	}
+
+	if err := geko.Valid([]byte(`{"a":1,"b":2}`), geko.MaxItems(2)); err != nil {
+		t.Fatalf("Valid should pass when members count equals max items, got %s", err.Error())
+	}
+}
+
+func TestValid_DisallowDuplicateKeys(t *testing.T) {
+	data := `[{"outer": {"a": 1, "b": 2, "a": 3}}]`
+
+	if err := geko.Valid([]byte(data)); err != nil {
+		t.Fatalf("Valid without DisallowDuplicateKeys error: %s", err.Error())
+	}
+
+	err := geko.Valid([]byte(data), geko.DisallowDuplicateKeys(true))
+
+	var dupErr *geko.DuplicateKeyError
+	if !errors.As(err, &dupErr) ||
+		dupErr.Key != "a" || dupErr.Index != 2 || dupErr.Offset != int64(strings.LastIndex(data, `"a"`)+3) {
+		t.Fatalf("Valid should fail with DuplicateKeyError, got %#v", err)
+	}
+
+	_, errDecode := geko.JSONUnmarshal([]byte(data), geko.DisallowDuplicateKeys(true))
+	if errDecode.Error() != err.Error() {
+		t.Fatalf("Valid error not same as decode: %s, %s", err.Error(), errDecode.Error())
+	}
+
+	err = geko.Valid([]byte(`{"A": 1, "a": 2}`), geko.DisallowDuplicateKeys(true), geko.KeyTransform(strings.ToLower))
+	if !errors.As(err, &dupErr) || dupErr.Key != "a" {
+		t.Fatalf("Valid should fail with DuplicateKeyError after key transform, got %#v", err)
+	}
+}
+
+func TestValidReport(t *testing.T) {
+	if issues := geko.ValidReport([]byte(`{"a": {"a": 1}, "b": [{"a": 1}, {"a": 2}]}`)); issues != nil {
+		t.Fatalf("ValidReport should report no issue, got %#v", issues)
+	}
+
+	data := `{"a": 1, "b": {"c": 1, "c": 2, "c": 3}, "a": [{"d": 1, "d": 2}]}`
+	issues := geko.ValidReport([]byte(data))
+
+	excepted := []struct {
+		key   string
+		index int
+	}{{"c", 1}, {"c", 2}, {"a", 2}, {"d", 1}}
+
+	if len(issues) != len(excepted) {
+		t.Fatalf("ValidReport issues count not correct: %#v", issues)
+	}
+
+	for i, issue := range issues {
+		var dupErr *geko.DuplicateKeyError
+		if !errors.As(issue.Err, &dupErr) || dupErr.Key != excepted[i].key ||
+			dupErr.Index != excepted[i].index || dupErr.Offset != issue.Offset {
+			t.Fatalf("ValidReport issue %d not correct: %#v", i, issue.Err)
+		}
+	}
+
+	issues = geko.ValidReport([]byte(`{"a": 1, "a": 2, "b": [`))
+
+	var syntaxErr *geko.SyntaxError
+	if len(issues) != 2 || !errors.As(issues[1].Err, &syntaxErr) {
+		t.Fatalf("ValidReport should report syntax error as last issue, got %#v", issues)
+	}
+
+	issues = geko.ValidReport([]byte(`{"a": 1} {}`))
+	if len(issues) != 1 || !errors.As(issues[0].Err, &syntaxErr) || issues[0].Offset != syntaxErr.Offset {
+		t.Fatalf("ValidReport should report trailing data, got %#v", issues)
+	}
+}
+
+var validBenchmarkData = []byte(`{"users": [` +
+	strings.Repeat(`{"id": 1, "name": "name", "tags": ["a", "b", "c"], "profile": {"age": 10, "score": 1.5}},`, 100) +
+	`{}], "total": 101}`)
+
+func BenchmarkValid(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = geko.Valid(validBenchmarkData, geko.DisallowDuplicateKeys(true))
+	}
+}
+
+func BenchmarkValid_Plain(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = geko.Valid(validBenchmarkData)
+	}
+}
+
+func BenchmarkValid_JSONUnmarshal(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = geko.JSONUnmarshal(validBenchmarkData, geko.DisallowDuplicateKeys(true))
+	}
+}
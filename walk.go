@@ -0,0 +1,47 @@
+package geko
+
+// container is implemented by [Map], [Pairs] and [List], to visit their
+// children without knowing type parameters.
+type container interface {
+	rangeChildren(fn func(key, value any) error) error
+	transformChildren(fn func(key, value any) (newValue any, remove bool, err error)) error
+	toStdTypes() any
+	Frozen() bool
+}
+
+// WalkFunc is the type of the function called by [Walk] to visit each value.
+//
+// The path is the location of value from root, whose elements are string keys
+// of object, and int indexes of array. The path of root is empty. The path
+// slice is reused between calls, copy it if you need to keep it.
+//
+// If descend is false, children of value will not be visited. If err is not
+// nil, [Walk] stops and returns it.
+type WalkFunc func(path []any, value any) (descend bool, err error)
+
+// Walk visits root and all values inside it depth-first, in document order,
+// by calling fn. Values of [Map], [Pairs] and [List] are descended into, all
+// other values, like json.Number or map[string]any, are leaves.
+//
+// For [Pairs], a duplicated key appears in paths of all its values.
+//
+// Containers must not be modified during the walk.
+func Walk(root any, fn WalkFunc) error {
+	return walkValue(make([]any, 0, 8), root, fn)
+}
+
+func walkValue(path []any, value any, fn WalkFunc) error {
+	descend, err := fn(path, value)
+	if err != nil || !descend {
+		return err
+	}
+
+	c, ok := value.(container)
+	if !ok {
+		return nil
+	}
+
+	return c.rangeChildren(func(key, child any) error {
+		return walkValue(append(path, key), child, fn)
+	})
+}
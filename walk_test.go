@@ -0,0 +1,142 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+func walkFixture(t *testing.T) any {
+	t.Helper()
+
+	v, err := geko.JSONUnmarshal(
+		[]byte(`{"a": [1, {"b": null}], "c": "s", "a": {"d": [true]}}`),
+		geko.UseNumber(true),
+	)
+	if err != nil {
+		t.Fatalf("Unmarshal with error: %s", err.Error())
+	}
+
+	// replace the last value with a Object
+	m := geko.NewMap[string, any]()
+	m.Set("d", geko.NewListFrom([]any{true}))
+	m.Set("e", map[string]any{"f": 1})
+	v.(geko.ObjectItems).SetValueByIndex(2, m)
+
+	return v
+}
+
+func describeWalkValue(value any) string {
+	switch value.(type) {
+	case geko.Object:
+		return "Object"
+	case geko.ObjectItems:
+		return "ObjectItems"
+	case geko.Array:
+		return "Array"
+	default:
+		return fmt.Sprintf("%T(%v)", value, value)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	var visits []string
+	err := geko.Walk(walkFixture(t), func(path []any, value any) (bool, error) {
+		visits = append(visits, fmt.Sprintf("%v %s", path, describeWalkValue(value)))
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("Walk with error: %s", err.Error())
+	}
+
+	excepted := []string{
+		"[] ObjectItems",
+		"[a] Array",
+		"[a 0] json.Number(1)",
+		"[a 1] ObjectItems",
+		"[a 1 b] <nil>(<nil>)",
+		"[c] string(s)",
+		"[a] Object",
+		"[a d] Array",
+		"[a d 0] bool(true)",
+		"[a e] map[string]interface {}(map[f:1])",
+	}
+
+	if !reflect.DeepEqual(visits, excepted) {
+		t.Fatalf("Walk visit order not correct: %#v", visits)
+	}
+}
+
+func TestWalk_PathTypes(t *testing.T) {
+	var paths [][]any
+	_ = geko.Walk(walkFixture(t), func(path []any, value any) (bool, error) {
+		if _, ok := value.(json.Number); ok {
+			paths = append(paths, append([]any(nil), path...))
+		}
+		return true, nil
+	})
+
+	if !reflect.DeepEqual(paths, [][]any{{"a", 0}}) {
+		t.Fatalf("Walk path not correct: %#v", paths)
+	}
+}
+
+func TestWalk_SkipChildren(t *testing.T) {
+	var visits []string
+	_ = geko.Walk(walkFixture(t), func(path []any, _ any) (bool, error) {
+		visits = append(visits, fmt.Sprint(path))
+		return len(path) == 0, nil
+	})
+
+	if !reflect.DeepEqual(visits, []string{"[]", "[a]", "[c]", "[a]"}) {
+		t.Fatalf("Walk should skip children: %#v", visits)
+	}
+}
+
+func TestWalk_Error(t *testing.T) {
+	stop := errors.New("stop")
+
+	count := 0
+	err := geko.Walk(walkFixture(t), func(_ []any, value any) (bool, error) {
+		count++
+		if value == nil {
+			return false, stop
+		}
+		return true, nil
+	})
+
+	if err != stop || count != 5 {
+		t.Fatalf("Walk should stop with error: %d, %#v", count, err)
+	}
+
+	err = geko.Walk(walkFixture(t), func(_ []any, value any) (bool, error) {
+		if value == true {
+			return false, stop
+		}
+		return true, nil
+	})
+
+	if err != stop {
+		t.Fatalf("Walk should stop with error inside Object: %#v", err)
+	}
+}
+
+func TestWalk_Nil(t *testing.T) {
+	var m geko.Object
+	var ps geko.ObjectItems
+	var l geko.Array
+
+	count := 0
+	err := geko.Walk(geko.NewListFrom([]any{m, ps, l}), func(_ []any, _ any) (bool, error) {
+		count++
+		return true, nil
+	})
+
+	if err != nil || count != 4 {
+		t.Fatalf("Walk with nil containers not correct: %d, %#v", count, err)
+	}
+}
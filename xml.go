@@ -0,0 +1,315 @@
+package geko
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// XMLOptions are options for controlling the behavior of [XMLMarshal].
+//
+// Default value (created by [CreateXMLOptions]) of it is:
+//
+//   - Array items not inside an object are named "item".
+//   - Members whose keys start with "@" are attributes.
+//   - Member with key "#text" is the character data.
+//   - No indent.
+//
+// See also: [CreateXMLOptions], [XMLItemName], [XMLAttributePrefix],
+// [XMLTextKey], [XMLIndent].
+type XMLOptions struct {
+	itemName        string
+	attributePrefix string
+	textKey         string
+	prefix          string
+	indent          string
+}
+
+// XMLOption is atom/modifier of [XMLOptions].
+type XMLOption func(opts *XMLOptions)
+
+// CreateXMLOptions creates a [XMLOptions] by apply all option to the default
+// XML option.
+func CreateXMLOptions(option ...XMLOption) XMLOptions {
+	opts := XMLOptions{
+		itemName:        "item",
+		attributePrefix: "@",
+		textKey:         "#text",
+	}
+	opts.Apply(option...)
+	return opts
+}
+
+// Apply option to current options.
+func (opts *XMLOptions) Apply(option ...XMLOption) {
+	for _, opt := range option {
+		opt(opts)
+	}
+}
+
+// XMLItemName specifies element name of array items which are not values of
+// object members, like items of the root array, or of a nested array.
+func XMLItemName(name string) XMLOption {
+	return func(opts *XMLOptions) {
+		opts.itemName = name
+	}
+}
+
+// XMLAttributePrefix specifies the prefix of keys which are attributes of the
+// element, instead of child elements. For example, {"@id": 1} is written as
+// id="1" attribute with "@" prefix. An empty prefix disables attributes.
+func XMLAttributePrefix(prefix string) XMLOption {
+	return func(opts *XMLOptions) {
+		opts.attributePrefix = prefix
+	}
+}
+
+// XMLTextKey specifies the key of member which is the character data of the
+// element, instead of a child element. An empty key disables it.
+func XMLTextKey(key string) XMLOption {
+	return func(opts *XMLOptions) {
+		opts.textKey = key
+	}
+}
+
+// XMLIndent specifies indent of output, like [xml.Encoder.Indent]. Empty
+// prefix and indent, which is the default, make output compact.
+func XMLIndent(prefix, indent string) XMLOption {
+	return func(opts *XMLOptions) {
+		opts.prefix = prefix
+		opts.indent = indent
+	}
+}
+
+// XMLMarshal encodes root into an XML element named rootElement, keeping
+// order of members:
+//
+//   - Members of an object are child elements named by their keys, in order,
+//     members of a map[string]any are sorted. Duplicated keys of an
+//     [ObjectItems] are repeated elements. See [XMLAttributePrefix] and
+//     [XMLTextKey] for members which are attributes and character data.
+//   - A member whose value is an array is written as repeated elements, one
+//     for each item. Items of other arrays, like the root or a nested array,
+//     are elements named by [XMLItemName].
+//   - Strings are character data as is, numbers and booleans are written like
+//     JSON, null is an empty element.
+//   - Other values are converted like [FromStruct] does, so structs and
+//     containers of other types are objects.
+//
+// No XML declaration is written, prepend [xml.Header] if needed.
+//
+// An error is returned if an element or attribute name is not a valid XML
+// name, an attribute or character data member is not a scalar, or a value
+// can't be encoded.
+func XMLMarshal(root any, rootElement string, option ...XMLOption) ([]byte, error) {
+	var buf bytes.Buffer
+
+	e := xmlEncoder{opts: CreateXMLOptions(option...), enc: xml.NewEncoder(&buf)}
+	e.enc.Indent(e.opts.prefix, e.opts.indent)
+
+	err := e.element(rootElement, root)
+	if err == nil {
+		err = e.enc.Flush()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+type xmlEncoder struct {
+	opts XMLOptions
+	enc  *xml.Encoder
+}
+
+func isXMLName(name string) bool {
+	for i, c := range name {
+		isFirst := c == '_' || c == ':' || unicode.IsLetter(c)
+		if !isFirst && (i == 0 || !(c == '-' || c == '.' || unicode.IsDigit(c))) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func xmlName(name string) (xml.Name, error) {
+	if !isXMLName(name) {
+		return xml.Name{}, fmt.Errorf("geko: invalid xml name %q", name)
+	}
+	return xml.Name{Local: name}, nil
+}
+
+// xmlText returns the character data of a scalar value, or false if v is not
+// a scalar.
+func xmlText(v any) (string, bool, error) {
+	if isNull(v) {
+		return "", true, nil
+	}
+
+	if s, isString := v.(string); isString {
+		return s, true, nil
+	}
+
+	if _, isNumber := v.(json.Number); !isNumber {
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+		default:
+			return "", false, nil
+		}
+	}
+
+	data, err := JSONMarshal(v)
+	return string(data), true, err
+}
+
+// xmlValue converts values of unknown types like [FromStruct] does.
+func xmlValue(v any) (any, error) {
+	if _, isScalar, _ := xmlText(v); isScalar {
+		return v, nil
+	}
+
+	if _, isObject := objectMembers(v); isObject {
+		return v, nil
+	}
+
+	if _, isArray := arrayItems(v); isArray {
+		return v, nil
+	}
+
+	return fromReflectValue(reflect.ValueOf(v))
+}
+
+// elements writes v as elements named name, an array is repeated elements.
+func (e *xmlEncoder) elements(name string, v any) error {
+	v, err := xmlValue(v)
+	if err != nil {
+		return err
+	}
+
+	if isNull(v) {
+		return e.element(name, v)
+	}
+
+	if items, isArray := arrayItems(v); isArray {
+		for _, item := range items {
+			if err = e.element(name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return e.element(name, v)
+}
+
+// element writes v as a single element named name.
+func (e *xmlEncoder) element(name string, v any) error {
+	v, err := xmlValue(v)
+	if err != nil {
+		return err
+	}
+
+	start := xml.StartElement{}
+	if start.Name, err = xmlName(name); err != nil {
+		return err
+	}
+
+	var members []Pair[string, any]
+	if !isNull(v) {
+		members, _ = objectMembers(v)
+	}
+
+	children := members[:0:0]
+	for _, pair := range members {
+		if e.opts.attributePrefix == "" || !strings.HasPrefix(pair.Key, e.opts.attributePrefix) {
+			children = append(children, pair)
+			continue
+		}
+
+		attr := xml.Attr{}
+		if attr.Name, err = xmlName(strings.TrimPrefix(pair.Key, e.opts.attributePrefix)); err != nil {
+			return err
+		}
+		if attr.Value, err = e.text(pair.Key, pair.Value); err != nil {
+			return err
+		}
+		start.Attr = append(start.Attr, attr)
+	}
+
+	if err = e.enc.EncodeToken(start); err == nil {
+		err = e.content(v, children)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return e.enc.EncodeToken(start.End())
+}
+
+// content writes children of an object, items of an array, or character data
+// of a scalar.
+func (e *xmlEncoder) content(v any, children []Pair[string, any]) error {
+	if isNull(v) {
+		return nil
+	}
+
+	if items, isArray := arrayItems(v); isArray {
+		for _, item := range items {
+			if err := e.element(e.opts.itemName, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, isObject := objectMembers(v); !isObject {
+		text, _, err := xmlText(v)
+		if err == nil && text != "" {
+			err = e.enc.EncodeToken(xml.CharData(text))
+		}
+		return err
+	}
+
+	for _, pair := range children {
+		var err error
+		if e.opts.textKey != "" && pair.Key == e.opts.textKey {
+			var text string
+			if text, err = e.text(pair.Key, pair.Value); err == nil {
+				err = e.enc.EncodeToken(xml.CharData(text))
+			}
+		} else {
+			err = e.elements(pair.Key, pair.Value)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// text returns character data of a scalar member.
+func (e *xmlEncoder) text(key string, v any) (string, error) {
+	v, err := xmlValue(v)
+	if err != nil {
+		return "", err
+	}
+
+	text, isScalar, err := xmlText(v)
+	if !isScalar {
+		return "", fmt.Errorf("geko: xml member %q must be a scalar, got %T", key, v)
+	}
+
+	return text, err
+}
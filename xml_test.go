@@ -0,0 +1,138 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/7sDream/geko"
+)
+
+const xmlTestData = `{
+	"@id": "o-1",
+	"@version": 2,
+	"customer": {"name": "A & B", "vip": true, "email": null},
+	"item": [
+		{"@sku": "x", "#text": "first", "qty": 1},
+		{"@sku": "y", "qty": 2.5}
+	],
+	"tags": ["a", ["b", "c"], {}],
+	"note": "<hi>",
+	"empty": []
+}`
+
+func TestXMLMarshal(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(xmlTestData), geko.UseObject(), geko.UseNumber(true))
+
+	output, err := geko.XMLMarshal(v, "order")
+	if err != nil {
+		t.Fatalf("XMLMarshal with error: %s", err.Error())
+	}
+
+	excepted := `<order id="o-1" version="2">` +
+		`<customer><name>A &amp; B</name><vip>true</vip><email></email></customer>` +
+		`<item sku="x">first<qty>1</qty></item><item sku="y"><qty>2.5</qty></item>` +
+		`<tags>a</tags><tags><item>b</item><item>c</item></tags><tags></tags>` +
+		`<note>&lt;hi&gt;</note>` +
+		`</order>`
+	if string(output) != excepted {
+		t.Fatalf("XMLMarshal result not correct: %s", string(output))
+	}
+}
+
+func TestXMLMarshal_Options(t *testing.T) {
+	v, _ := geko.JSONUnmarshal([]byte(`{"_id": 1, "a": "x", "b": [1, {"$": "t", "c": null}], "$": 0}`))
+
+	output, err := geko.XMLMarshal(v, "root", geko.XMLAttributePrefix("_"), geko.XMLTextKey("$"))
+	if err != nil {
+		t.Fatalf("XMLMarshal with error: %s", err.Error())
+	}
+
+	if string(output) != `<root id="1"><a>x</a><b>1</b><b>t<c></c></b>0</root>` {
+		t.Fatalf("XMLMarshal result not correct: %s", string(output))
+	}
+
+	for _, data := range []string{`{"@id": 1}`, `{"#text": 1}`} {
+		v, _ = geko.JSONUnmarshal([]byte(data))
+		if output, err = geko.XMLMarshal(v, "root", geko.XMLAttributePrefix(""), geko.XMLTextKey("")); err == nil {
+			t.Fatalf("XMLMarshal should fail when options are disabled: %s", string(output))
+		}
+	}
+}
+
+func TestXMLMarshal_Array(t *testing.T) {
+	v := []any{1, []any{2}, map[string]any{"k": "v"}, nil}
+
+	output, err := geko.XMLMarshal(v, "list", geko.XMLItemName("v"), geko.XMLIndent("", "  "))
+	if err != nil {
+		t.Fatalf("XMLMarshal with error: %s", err.Error())
+	}
+
+	excepted := `<list>
+  <v>1</v>
+  <v>
+    <v>2</v>
+  </v>
+  <v>
+    <k>v</k>
+  </v>
+  <v></v>
+</list>`
+	if string(output) != excepted {
+		t.Fatalf("XMLMarshal result not correct: %s", string(output))
+	}
+}
+
+type xmlTestStruct struct {
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Count uint8    `json:"count"`
+}
+
+func TestXMLMarshal_Types(t *testing.T) {
+	m := geko.NewMap[string, int]()
+	m.Set("z", 1)
+	m.Set("a", 2)
+
+	var nilArray geko.Array
+
+	output, err := geko.XMLMarshal(map[string]any{
+		"s": xmlTestStruct{Name: "n", Tags: []string{"x", "y"}, Count: 3},
+		"m": m,
+		"f": float32(0.5),
+		"n": nilArray,
+	}, "root")
+	if err != nil {
+		t.Fatalf("XMLMarshal with error: %s", err.Error())
+	}
+
+	excepted := `<root><f>0.5</f><m><z>1</z><a>2</a></m><n></n>` +
+		`<s><name>n</name><tags>x</tags><tags>y</tags><count>3</count></s></root>`
+	if string(output) != excepted {
+		t.Fatalf("XMLMarshal result not correct: %s", string(output))
+	}
+}
+
+func TestXMLMarshal_Error(t *testing.T) {
+	for _, c := range []struct {
+		v    any
+		root string
+	}{
+		{nil, ""},
+		{nil, "1a"},
+		{map[string]any{"a b": 1}, "root"},
+		{map[string]any{"@": 1}, "root"},
+		{map[string]any{"@a": []any{1}}, "root"},
+		{map[string]any{"@a": make(chan int)}, "root"},
+		{map[string]any{"#text": map[string]any{}}, "root"},
+		{map[string]any{"a": math.NaN()}, "root"},
+		{map[string]any{"a": make(chan int)}, "root"},
+		{map[string]any{"a": []any{make(chan int)}}, "root"},
+		{[]any{json.Number("x")}, "root"},
+		{make(chan int), "root"},
+	} {
+		if output, err := geko.XMLMarshal(c.v, c.root); err == nil {
+			t.Fatalf("XMLMarshal should fail: %s", string(output))
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package geko
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlEncodable is implemented by types in this package, to encode themselves
+// into YAML node directly, without the text round trip of [yaml.Node.Encode].
+type yamlEncodable interface {
+	yamlNode() (*yaml.Node, error)
+}
+
+func yamlValueNode(v any) (*yaml.Node, error) {
+	switch x := v.(type) {
+	case yamlEncodable:
+		return x.yamlNode()
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case json.Number:
+		// tag is resolved from value, so it's a plain number
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: string(x)}, nil
+	default:
+		node := &yaml.Node{}
+		if err := node.Encode(v); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+}
+
+func appendYAMLMember(node *yaml.Node, key, value any) error {
+	keyNode, err := yamlValueNode(key)
+	if err != nil {
+		return err
+	}
+
+	valueNode, err := yamlValueNode(value)
+	if err != nil {
+		return err
+	}
+
+	node.Content = append(node.Content, keyNode, valueNode)
+	return nil
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	n := node
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// yamlDecoder decodes YAML nodes into values, like [Decoder] does for JSON.
+type yamlDecoder struct {
+	opts DecodeOptions
+}
+
+func (d *yamlDecoder) value(node *yaml.Node) (any, error) {
+	n := resolveYAMLAlias(node)
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		if d.opts.useObject {
+			object := NewMap[string, any]()
+			object.SetDuplicatedKeyStrategy(d.opts.duplicatedKeyStrategy)
+			object.SetRecordDuplicates(d.opts.recordDuplicates)
+			return object, decodeYAMLMapping[string, any](d, n, object)
+		}
+
+		items := NewPairs[string, any]()
+		return items, decodeYAMLMapping[string, any](d, n, items)
+	case yaml.SequenceNode:
+		array := NewListWithCapacity[any](len(n.Content))
+		for _, item := range n.Content {
+			v, err := d.value(item)
+			if err != nil {
+				return nil, err
+			}
+			array.Append(v)
+		}
+		return array, nil
+	default:
+		return d.scalar(n)
+	}
+}
+
+func (d *yamlDecoder) scalar(n *yaml.Node) (any, error) {
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	switch x := v.(type) {
+	case int:
+		switch {
+		case d.opts.useNumber:
+			return json.Number(strconv.Itoa(x)), nil
+		case d.opts.useInt64:
+			return int64(x), nil
+		default:
+			return float64(x), nil
+		}
+	case uint64:
+		if d.opts.useNumber {
+			return json.Number(strconv.FormatUint(x, 10)), nil
+		}
+		return float64(x), nil
+	case float64:
+		if d.opts.useNumber && !math.IsInf(x, 0) && !math.IsNaN(x) {
+			return json.Number(strconv.FormatFloat(x, 'g', -1, 64)), nil
+		}
+		return x, nil
+	default:
+		return v, nil
+	}
+}
+
+// memberAdder is implemented by [Map] and [Pairs], to add decoded members in
+// document order.
+type memberAdder[K comparable, V any] interface {
+	Add(key K, value V)
+}
+
+func decodeYAMLMapping[K comparable, V any](d *yamlDecoder, node *yaml.Node, object memberAdder[K, V]) error {
+	n := resolveYAMLAlias(node)
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("geko: cannot unmarshal YAML %s into %T at line %d", n.ShortTag(), object, n.Line)
+	}
+
+	valueIsAny := isEmptyInterface[V]()
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		var key K
+		if err := n.Content[i].Decode(&key); err != nil {
+			return err
+		}
+
+		var value V
+		if valueIsAny {
+			v, err := d.value(n.Content[i+1])
+			if err != nil {
+				return err
+			}
+			value, _ = v.(V)
+		} else if err := n.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+
+		object.Add(key, value)
+	}
+
+	return nil
+}
+
+// MarshalYAML implements Marshaler interface of [gopkg.in/yaml.v3]. The map
+// is encoded as a mapping, in the insertion order.
+//
+// You should not call this directly, use yaml.Marshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (m *Map[K, V]) MarshalYAML() (any, error) {
+	return m.yamlNode()
+}
+
+func (m *Map[K, V]) yamlNode() (*yaml.Node, error) {
+	if m == nil {
+		return yamlValueNode(nil)
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i, length := 0, m.Len(); i < length; i++ {
+		pair := m.GetByIndex(i)
+		if err := appendYAMLMember(node, pair.Key, pair.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// UnmarshalYAML implements Unmarshaler interface of [gopkg.in/yaml.v3]. The
+// input must be a mapping, its members are added in document order, and
+// duplicated keys are dealt with the [DuplicatedKeyStrategy] of the map.
+//
+// If the value type is any, nested mappings and sequences are decoded into
+// [Object] and [Array], like [Map.UnmarshalJSON]. Numbers are float64, and
+// timestamps are time.Time. Aliases are resolved into the anchored values.
+//
+// You shouldn't call this directly, use yaml.Unmarshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (m *Map[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	if err := m.checkFrozen("UnmarshalYAML"); err != nil {
+		return err
+	}
+
+	d := yamlDecoder{opts: CreateDecodeOptions(
+		UseObject(),
+		ObjectOnDuplicatedKey(m.duplicatedKeyStrategy),
+		RecordDuplicates(m.RecordDuplicates()),
+	)}
+	return decodeYAMLMapping[K, V](&d, node, m)
+}
+
+// MarshalYAML implements Marshaler interface of [gopkg.in/yaml.v3]. The pairs
+// are encoded as a mapping, in their order, duplicated keys are all kept.
+//
+// You should not call this directly, use yaml.Marshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (ps *Pairs[K, V]) MarshalYAML() (any, error) {
+	return ps.yamlNode()
+}
+
+func (ps *Pairs[K, V]) yamlNode() (*yaml.Node, error) {
+	if ps == nil {
+		return yamlValueNode(nil)
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, pair := range ps.List {
+		if err := appendYAMLMember(node, pair.Key, pair.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
+// UnmarshalYAML implements Unmarshaler interface of [gopkg.in/yaml.v3]. The
+// input must be a mapping, its members are added in document order, all
+// values of duplicated keys are kept.
+//
+// If the value type is any, nested mappings and sequences are decoded into
+// [ObjectItems] and [Array], like [Pairs.UnmarshalJSON]. See
+// [Map.UnmarshalYAML] for other values.
+//
+// You shouldn't call this directly, use yaml.Unmarshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (ps *Pairs[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	if err := ps.checkFrozen("UnmarshalYAML"); err != nil {
+		return err
+	}
+
+	d := yamlDecoder{opts: CreateDecodeOptions(UseObjectItems())}
+	return decodeYAMLMapping[K, V](&d, node, ps)
+}
+
+// MarshalYAML implements Marshaler interface of [gopkg.in/yaml.v3]. The list
+// is encoded as a sequence.
+//
+// You should not call this directly, use yaml.Marshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (l *List[T]) MarshalYAML() (any, error) {
+	return l.yamlNode()
+}
+
+func (l *List[T]) yamlNode() (*yaml.Node, error) {
+	if l == nil {
+		return yamlValueNode(nil)
+	}
+
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range l.List {
+		itemNode, err := yamlValueNode(item)
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, itemNode)
+	}
+
+	return node, nil
+}
+
+// UnmarshalYAML implements Unmarshaler interface of [gopkg.in/yaml.v3]. The
+// input must be a sequence.
+//
+// If the item type is any, nested mappings and sequences are decoded with
+// the decode options of the list, like [List.UnmarshalJSON]. Options about
+// numbers are [UseNumber] and [UseInt64], others are ignored. See
+// [Map.UnmarshalYAML] for other values.
+//
+// The inner slice is replaced, unless [List.AppendOnUnmarshal] is enabled.
+//
+// You shouldn't call this directly, use yaml.Unmarshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (l *List[T]) UnmarshalYAML(node *yaml.Node) error {
+	if err := l.checkFrozen("UnmarshalYAML"); err != nil {
+		return err
+	}
+
+	n := resolveYAMLAlias(node)
+	if n.Kind != yaml.SequenceNode {
+		return fmt.Errorf("geko: cannot unmarshal YAML %s into %T at line %d", n.ShortTag(), l, n.Line)
+	}
+
+	d := yamlDecoder{opts: l.decodeOptions}
+	valueIsAny := isEmptyInterface[T]()
+
+	items := make([]T, 0, len(n.Content))
+	for _, itemNode := range n.Content {
+		var item T
+		if valueIsAny {
+			v, err := d.value(itemNode)
+			if err != nil {
+				return err
+			}
+			item, _ = v.(T)
+		} else if err := itemNode.Decode(&item); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	l.storeDecoded(items)
+
+	return nil
+}
+
+// MarshalYAML implements Marshaler interface of [gopkg.in/yaml.v3].
+//
+// You should not call this directly, use yaml.Marshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (v Any) MarshalYAML() (any, error) {
+	return yamlValueNode(v.Value)
+}
+
+// UnmarshalYAML implements Unmarshaler interface of [gopkg.in/yaml.v3].
+// Mappings and sequences are decoded with Any.Opts, like [Any.UnmarshalJSON].
+// Options about numbers are [UseNumber] and [UseInt64], others are ignored.
+//
+// You shouldn't call this directly, use yaml.Unmarshal instead.
+//
+// [gopkg.in/yaml.v3]: https://pkg.go.dev/gopkg.in/yaml.v3
+func (v *Any) UnmarshalYAML(node *yaml.Node) error {
+	d := yamlDecoder{opts: v.Opts}
+
+	value, err := d.value(node)
+	if err == nil {
+		v.Value = value
+	}
+	return err
+}
@@ -0,0 +1,262 @@
+package geko_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/7sDream/geko"
+)
+
+const yamlTestData = `name: app
+version: 2
+defaults: &defaults
+  timeout: 1.5
+  retry: 3
+servers:
+  - host: b.example.com
+    ports: [80, 443]
+    options: *defaults
+  - host: a.example.com
+    ports: []
+    options: {}
+enabled: true
+created: 2024-01-02T03:04:05Z
+empty: null
+`
+
+func TestMap_YAML(t *testing.T) {
+	object := geko.NewMap[string, any]()
+	if err := yaml.Unmarshal([]byte(yamlTestData), &object); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	if keys := strings.Join(object.Keys(), ","); keys != "name,version,defaults,servers,enabled,created,empty" {
+		t.Fatalf("Unmarshal YAML keys not correct: %s", keys)
+	}
+
+	if created, isTime := object.GetOrZeroValue("created").(time.Time); !isTime || created.Year() != 2024 {
+		t.Fatalf("Unmarshal YAML timestamp not correct: %#v", object.GetOrZeroValue("created"))
+	}
+
+	// nested values are decoded like JSON, alias is resolved
+	output, _ := json.Marshal(object)
+	excepted := `{"name":"app","version":2,"defaults":{"timeout":1.5,"retry":3},"servers":[` +
+		`{"host":"b.example.com","ports":[80,443],"options":{"timeout":1.5,"retry":3}},` +
+		`{"host":"a.example.com","ports":[],"options":{}}],` +
+		`"enabled":true,"created":"2024-01-02T03:04:05Z","empty":null}`
+	if string(output) != excepted {
+		t.Fatalf("Unmarshal YAML result not correct: %s", string(output))
+	}
+
+	servers, _ := object.GetOrZeroValue("servers").(geko.Array)
+	if _, isObject := servers.Get(0).(geko.Object); !isObject {
+		t.Fatalf("Unmarshal YAML should use Object for nested mapping: %#v", servers.Get(0))
+	}
+
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		t.Fatalf("Marshal YAML with error: %s", err.Error())
+	}
+
+	exceptedYAML := `name: app
+version: 2
+defaults:
+    timeout: 1.5
+    retry: 3
+servers:
+    - host: b.example.com
+      ports:
+        - 80
+        - 443
+      options:
+        timeout: 1.5
+        retry: 3
+    - host: a.example.com
+      ports: []
+      options: {}
+enabled: true
+created: 2024-01-02T03:04:05Z
+empty: null
+`
+	if string(data) != exceptedYAML {
+		t.Fatalf("Marshal YAML result not correct: %s", string(data))
+	}
+
+	// round trip
+	again := geko.NewMap[string, any]()
+	_ = yaml.Unmarshal(data, &again)
+	if output2, _ := json.Marshal(again); string(output2) != excepted {
+		t.Fatalf("YAML round trip result not correct: %s", string(output2))
+	}
+}
+
+func TestMap_YAML_Duplicated(t *testing.T) {
+	data := []byte("b: 1\na: 2\nb: 3\nc: {x: 1, x: 2}\n")
+
+	object := geko.NewMap[string, any]()
+	object.SetDuplicatedKeyStrategy(geko.UpdateValueUpdateOrder)
+	if err := yaml.Unmarshal(data, &object); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(object)
+	if string(output) != `{"a":2,"b":3,"c":{"x":2}}` {
+		t.Fatalf("Unmarshal YAML result not correct: %s", string(output))
+	}
+
+	items := geko.NewPairs[string, any]()
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	output, _ = json.Marshal(items)
+	if string(output) != `{"b":1,"a":2,"b":3,"c":{"x":1,"x":2}}` {
+		t.Fatalf("Unmarshal YAML result not correct: %s", string(output))
+	}
+
+	if output, _ = yaml.Marshal(items); string(output) != "b: 1\na: 2\nb: 3\nc:\n    x: 1\n    x: 2\n" {
+		t.Fatalf("Marshal YAML result not correct: %s", string(output))
+	}
+}
+
+func TestMap_YAML_Typed(t *testing.T) {
+	m := geko.NewMap[int, []string]()
+	if err := yaml.Unmarshal([]byte("3: [a]\n1: [b, c]\n"), &m); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	if output, _ := yaml.Marshal(m); string(output) != "3:\n    - a\n1:\n    - b\n    - c\n" {
+		t.Fatalf("Marshal YAML result not correct: %s", string(output))
+	}
+
+	for _, data := range []string{"[1]", "a: [1]", "[a]: [1]", "1: {a: 1}"} {
+		if err := yaml.Unmarshal([]byte(data), &m); err == nil {
+			t.Fatalf("Unmarshal YAML %s should fail", data)
+		}
+	}
+
+	var object geko.Object
+	for _, data := range []string{"a: [!!int x]", "a: {b: !!int x}"} {
+		if err := yaml.Unmarshal([]byte(data), &object); err == nil {
+			t.Fatalf("Unmarshal YAML %s should fail", data)
+		}
+	}
+
+	var nilObject geko.Object
+	var nilItems geko.ObjectItems
+	if output, _ := yaml.Marshal([]any{nilObject, nilItems}); string(output) != "- null\n- null\n" {
+		t.Fatalf("Marshal YAML of nil map not correct: %s", string(output))
+	}
+}
+
+func TestList_YAML(t *testing.T) {
+	data := []byte("- {b: 1, a: 2}\n- [x, 1]\n- 9007199254740993\n- 18446744073709551615\n- 1.5\n- .inf\n- s\n")
+
+	list := geko.NewList[any]()
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	output, _ := json.Marshal(list.List[:5])
+	if string(output) != `[{"b":1,"a":2},["x",1],9007199254740992,18446744073709552000,1.5]` {
+		t.Fatalf("Unmarshal YAML result not correct: %s", string(output))
+	}
+
+	if _, isItems := list.Get(0).(geko.ObjectItems); !isItems {
+		t.Fatalf("Unmarshal YAML should use ObjectItems by default: %#v", list.Get(0))
+	}
+
+	list.SetDecodeOptions(geko.UseObject(), geko.UseNumber(true))
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	if _, isObject := list.Get(0).(geko.Object); !isObject || list.Get(2) != json.Number("9007199254740993") ||
+		list.Get(3) != json.Number("18446744073709551615") || list.Get(4) != json.Number("1.5") ||
+		list.Get(5) != math.Inf(1) {
+		t.Fatalf("Unmarshal YAML result not correct: %#v", list.List)
+	}
+
+	output, _ = yaml.Marshal(list)
+	excepted := "- b: 1\n  a: 2\n- - x\n  - 1\n- 9007199254740993\n- 18446744073709551615\n- 1.5\n- .inf\n- s\n"
+	if string(output) != excepted {
+		t.Fatalf("Marshal YAML result not correct: %s", string(output))
+	}
+
+	list.SetDecodeOptions(geko.UseInt64(true))
+	list.SetAppendOnUnmarshal(true)
+	_ = yaml.Unmarshal([]byte("[1]"), &list)
+	if list.Len() != 8 || list.Get(7) != int64(1) {
+		t.Fatalf("Unmarshal YAML should append: %#v", list.List)
+	}
+}
+
+func TestList_YAML_Typed(t *testing.T) {
+	list := geko.NewList[int]()
+	if err := yaml.Unmarshal([]byte("[3, 1, 2]"), &list); err != nil || list.Len() != 3 || list.Get(0) != 3 {
+		t.Fatalf("Unmarshal YAML result not correct: %#v, %#v", list.List, err)
+	}
+
+	for _, data := range []string{"a: 1", "[a]"} {
+		if err := yaml.Unmarshal([]byte(data), &list); err == nil {
+			t.Fatalf("Unmarshal YAML %s should fail", data)
+		}
+	}
+
+	array := geko.NewList[any]()
+	if err := yaml.Unmarshal([]byte("[!!int x]"), &array); err == nil {
+		t.Fatalf("Unmarshal YAML should fail on invalid scalar")
+	}
+
+	var nilList geko.Array
+	if output, err := yaml.Marshal([]any{nilList, yamlTestBad{}}); err == nil {
+		t.Fatalf("Marshal YAML should fail: %s", string(output))
+	}
+
+	if output, err := (geko.NewListFrom([]any{yamlTestBad{}})).MarshalYAML(); err == nil {
+		t.Fatalf("Marshal YAML should fail: %#v", output)
+	}
+}
+
+type yamlTestBad struct{}
+
+func (yamlTestBad) MarshalYAML() (any, error) {
+	return nil, errors.New("bad")
+}
+
+func TestAny_YAML(t *testing.T) {
+	v := geko.Any{Opts: geko.CreateDecodeOptions(geko.UseObject())}
+	if err := yaml.Unmarshal([]byte("b: [1, {x: 2}]\na: 1\n"), &v); err != nil {
+		t.Fatalf("Unmarshal YAML with error: %s", err.Error())
+	}
+
+	if _, isObject := v.Value.(geko.Object); !isObject {
+		t.Fatalf("Unmarshal YAML result not correct: %#v", v.Value)
+	}
+
+	output, _ := yaml.Marshal(v)
+	if string(output) != "b:\n    - 1\n    - x: 2\na: 1\n" {
+		t.Fatalf("Marshal YAML result not correct: %s", string(output))
+	}
+
+	if err := yaml.Unmarshal([]byte("[!!int x]"), &v); err == nil {
+		t.Fatalf("Unmarshal YAML should fail on invalid scalar")
+	}
+
+	// error of key and value
+	items := geko.NewPairs[string, any]()
+	items.Add("a", yamlTestBad{})
+	m := geko.NewMap[yamlTestBad, int]()
+	m.Set(yamlTestBad{}, 1)
+	for _, value := range []any{items, m} {
+		if output, err := (geko.Any{Value: value}).MarshalYAML(); err == nil {
+			t.Fatalf("Marshal YAML of func should fail: %#v", output)
+		}
+	}
+}